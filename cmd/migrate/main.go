@@ -8,6 +8,8 @@ import (
 
 	"tiris-backend/internal/config"
 	"tiris-backend/internal/database"
+	"tiris-backend/internal/models"
+	"tiris-backend/pkg/security"
 )
 
 func main() {
@@ -39,6 +41,20 @@ func main() {
 		}
 		fmt.Println("Migrations completed successfully")
 
+		// Encrypt the exchange binding credentials stored before encryption at rest
+		encrypted, err := database.EncryptExchangeBindingCredentials(db.DB, newCredentialManager(cfg))
+		if err != nil {
+			log.Fatalf("Credential encryption failed: %v", err)
+		}
+		fmt.Printf("Encrypted credentials of %d exchange binding(s)\n", encrypted)
+
+	case "decrypt-credentials":
+		decrypted, err := database.DecryptExchangeBindingCredentials(db.DB, newCredentialManager(cfg))
+		if err != nil {
+			log.Fatalf("Credential decryption failed: %v", err)
+		}
+		fmt.Printf("Decrypted credentials of %d exchange binding(s)\n", decrypted)
+
 	case "down":
 		steps := 1
 		if len(os.Args) > 2 {
@@ -86,14 +102,23 @@ func main() {
 	}
 }
 
+func newCredentialManager(cfg *config.Config) *models.ExchangeBindingManager {
+	keyring, err := security.NewKeyring(cfg.Security.MasterKeys, cfg.Security.ActiveMasterKeyID)
+	if err != nil {
+		log.Fatalf("Failed to initialize encryption keyring: %v", err)
+	}
+	return models.NewExchangeBindingManager(keyring, cfg.Security.SigningKey)
+}
+
 func printUsage() {
 	fmt.Println("Usage: migrate <command> [options]")
 	fmt.Println()
 	fmt.Println("Commands:")
-	fmt.Println("  up                 Run all pending migrations")
+	fmt.Println("  up                 Run all pending migrations and encrypt plaintext exchange credentials")
 	fmt.Println("  down [steps]       Rollback migrations (default: 1 step)")
 	fmt.Println("  version            Show current migration version")
 	fmt.Println("  force <version>    Force migration version (resolves dirty state)")
+	fmt.Println("  decrypt-credentials  Decrypt exchange credentials (run before rolling back migration 15)")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  migrate up")
@@ -101,4 +126,5 @@ func printUsage() {
 	fmt.Println("  migrate down 3")
 	fmt.Println("  migrate version")
 	fmt.Println("  migrate force 0")
+	fmt.Println("  migrate decrypt-credentials && migrate down")
 }
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"tiris-backend/internal/config"
+	"tiris-backend/internal/database"
+	"tiris-backend/pkg/security"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	keyring, err := security.NewKeyring(cfg.Security.MasterKeys, cfg.Security.ActiveMasterKeyID)
+	if err != nil {
+		log.Fatalf("Failed to initialize encryption keyring: %v", err)
+	}
+
+	// Initialize database connection
+	db, err := database.Initialize(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.Close(db)
+
+	command := os.Args[1]
+
+	switch command {
+	case "run":
+		batchSize := 100
+		if len(os.Args) > 2 {
+			batchSize, err = strconv.Atoi(os.Args[2])
+			if err != nil || batchSize <= 0 {
+				log.Fatalf("Invalid batch size argument: %s", os.Args[2])
+			}
+		}
+
+		fmt.Printf("Re-encrypting secrets under key %d in batches of %d\n", keyring.ActiveKeyID(), batchSize)
+
+		bindings, err := database.ReencryptExchangeBindingCredentials(db.DB, keyring, batchSize, func(done int) {
+			fmt.Printf("  exchange bindings: %d re-encrypted\n", done)
+		})
+		if err != nil {
+			log.Fatalf("Rotation of exchange binding credentials failed after %d binding(s), run again to resume: %v", bindings, err)
+		}
+
+		tokens, err := database.ReencryptOAuthTokens(db.DB, keyring, batchSize, func(done int) {
+			fmt.Printf("  OAuth tokens: %d re-encrypted\n", done)
+		})
+		if err != nil {
+			log.Fatalf("Rotation of OAuth tokens failed after %d token(s), run again to resume: %v", tokens, err)
+		}
+
+		fmt.Printf("Rotation completed: %d exchange binding(s) and %d OAuth token(s) re-encrypted\n", bindings, tokens)
+
+	case "status":
+		bindings, tokens, err := database.CountStaleSecrets(db.DB, keyring)
+		if err != nil {
+			log.Fatalf("Failed to get rotation status: %v", err)
+		}
+		fmt.Printf("Active key: %d (configured keys: %v)\n", keyring.ActiveKeyID(), keyring.KeyIDs())
+		fmt.Printf("Exchange bindings not under the active key: %d\n", bindings)
+		fmt.Printf("OAuth tokens not under the active key: %d\n", tokens)
+		if bindings == 0 && tokens == 0 {
+			fmt.Println("All secrets use the active key; retired keys can be removed")
+		}
+
+	default:
+		fmt.Printf("Unknown command: %s\n", command)
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Println("Usage: rotate-keys <command> [options]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  run [batch-size]   Re-encrypt exchange binding and OAuth token secrets under the active key (default: 100 rows per batch)")
+	fmt.Println("  status             Show how many secrets are not encrypted with the active key yet")
+	fmt.Println()
+	fmt.Println("Rotation:")
+	fmt.Println("  1. Add the new key to MASTER_KEYS (e.g. MASTER_KEYS=2:<new key>) and keep the old ones")
+	fmt.Println("  2. Deploy; new secrets are encrypted with the highest key id or ACTIVE_MASTER_KEY_ID")
+	fmt.Println("  3. Run rotate-keys run; an interrupted run resumes when started again")
+	fmt.Println("  4. Remove the old keys once rotate-keys status reports no remaining secrets")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  rotate-keys status")
+	fmt.Println("  rotate-keys run")
+	fmt.Println("  rotate-keys run 500")
+}
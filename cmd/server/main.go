@@ -15,6 +15,7 @@ import (
 	"tiris-backend/internal/metrics"
 	"tiris-backend/internal/nats"
 	"tiris-backend/internal/repositories"
+	"tiris-backend/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -62,7 +63,7 @@ func main() {
 	var natsManager *nats.Manager
 	if cfg.NATS.Enabled {
 		var err error
-		natsManager, err = nats.NewManager(cfg.NATS, repos)
+		natsManager, err = nats.NewManager(cfg.NATS, repos, db.DB)
 		if err != nil {
 			log.Fatalf("Failed to initialize NATS: %v", err)
 		}
@@ -88,6 +89,27 @@ func main() {
 	metricsUpdater.Start()
 	defer metricsUpdater.Stop()
 
+	// Start scheduled ledger reconciliation
+	if cfg.Reconciliation.Enabled {
+		reconciliationJob := services.NewReconciliationJob(
+			services.NewReconciliationService(repos, db.DB),
+			time.Duration(cfg.Reconciliation.Interval)*time.Second,
+			cfg.Reconciliation.Adjust,
+		)
+		reconciliationJob.Start()
+		defer reconciliationJob.Stop()
+	}
+
+	// Start scheduled comparison of real tradings with their exchange accounts
+	if cfg.BalanceSync.Enabled {
+		balanceSyncJob := services.NewBalanceSyncJob(
+			apiServer.GetBalanceSyncService(),
+			time.Duration(cfg.BalanceSync.Interval)*time.Second,
+		)
+		balanceSyncJob.Start()
+		defer balanceSyncJob.Stop()
+	}
+
 	// Create HTTP server
 	srv := &http.Server{
 		Addr:         ":" + cfg.Server.Port,
@@ -96,6 +96,8 @@ if [[ ! -f ".env.prod" ]]; then
     # Generate secure secrets
     JWT_SECRET=$(openssl rand -base64 32)
     REFRESH_SECRET=$(openssl rand -base64 32)
+    MASTER_KEY=$(openssl rand -base64 32)
+    SIGNING_KEY=$(openssl rand -base64 32)
     DB_PASSWORD=$(openssl rand -base64 24)
     REDIS_PASSWORD=$(openssl rand -base64 24)
     NATS_PASSWORD=$(openssl rand -base64 24)
@@ -106,6 +108,8 @@ if [[ ! -f ".env.prod" ]]; then
     sed -i "s/CHANGE_ME_STRONG_NATS_PASSWORD/$NATS_PASSWORD/g" .env.prod
     sed -i "s/CHANGE_ME_VERY_STRONG_JWT_SECRET_AT_LEAST_32_CHARS/$JWT_SECRET/g" .env.prod
     sed -i "s/CHANGE_ME_VERY_STRONG_REFRESH_SECRET_AT_LEAST_32_CHARS/$REFRESH_SECRET/g" .env.prod
+    sed -i "s|CHANGE_ME_VERY_STRONG_MASTER_KEY_AT_LEAST_32_CHARS|$MASTER_KEY|g" .env.prod
+    sed -i "s|CHANGE_ME_VERY_STRONG_SIGNING_KEY_AT_LEAST_32_CHARS|$SIGNING_KEY|g" .env.prod
     sed -i "s/tiris.ai/$DOMAIN/g" .env.prod
     
     log "Environment file created with secure secrets"
@@ -84,6 +84,8 @@ fi
 log "Generating secure secrets..."
 JWT_SECRET=$(openssl rand -base64 32)
 REFRESH_SECRET=$(openssl rand -base64 32)
+MASTER_KEY=$(openssl rand -base64 32)
+SIGNING_KEY=$(openssl rand -base64 32)
 DB_PASSWORD=$(openssl rand -base64 24 | tr -d "=+/" | cut -c1-16)
 REDIS_PASSWORD=$(openssl rand -base64 24 | tr -d "=+/" | cut -c1-16)
 NATS_PASSWORD=$(openssl rand -base64 24 | tr -d "=+/" | cut -c1-16)
@@ -147,6 +149,12 @@ REFRESH_SECRET=$REFRESH_SECRET
 JWT_EXPIRATION=3600
 REFRESH_EXPIRATION=604800
 
+# ============================================================================
+# Credential Encryption
+# ============================================================================
+MASTER_KEY=$MASTER_KEY
+SIGNING_KEY=$SIGNING_KEY
+
 # ============================================================================
 # OAuth Configuration
 # ============================================================================
@@ -245,6 +253,8 @@ cat > .env.secrets.txt << EOF
 Domain: $DOMAIN
 JWT Secret: $JWT_SECRET
 Refresh Secret: $REFRESH_SECRET
+Master Key: $MASTER_KEY
+Signing Key: $SIGNING_KEY
 Database Password: $DB_PASSWORD
 Redis Password: $REDIS_PASSWORD
 NATS Password: $NATS_PASSWORD
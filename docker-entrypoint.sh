@@ -54,9 +54,14 @@ run_migrations() {
 
 # Function to validate required environment variables
 validate_environment() {
-    required_vars="DB_HOST DB_PORT DB_NAME DB_USER DB_PASSWORD JWT_SECRET REFRESH_SECRET"
+    required_vars="DB_HOST DB_PORT DB_NAME DB_USER DB_PASSWORD JWT_SECRET REFRESH_SECRET SIGNING_KEY"
     missing_vars=""
     
+    # MASTER_KEYS replaces MASTER_KEY once the master key has been rotated
+    if [ -z "$MASTER_KEYS" ]; then
+        required_vars="$required_vars MASTER_KEY"
+    fi
+    
     # Add NATS_URL to required vars only if NATS is enabled
     if [ "${NATS_ENABLED:-true}" = "true" ]; then
         required_vars="$required_vars NATS_URL"
@@ -123,6 +128,12 @@ case "$COMMAND" in
         exec ./migrate version
         ;;
         
+    "rotate-keys")
+        echo "🔑 Rotating master keys..."
+        wait_for_database
+        exec ./rotate-keys "${@:2}"
+        ;;
+        
     "health-check")
         echo "🏥 Running health check..."
         if [ -f "./server" ]; then
@@ -149,6 +160,7 @@ case "$COMMAND" in
         echo "  migrate-up      - Run all pending migrations"
         echo "  migrate-down    - Rollback last migration"
         echo "  migrate-status  - Show current migration version"
+        echo "  rotate-keys     - Re-encrypt secrets under the active master key"
         echo "  health-check    - Verify container health"
         echo "  shell           - Start interactive shell"
         echo "  help            - Show this help message"
@@ -161,7 +173,7 @@ case "$COMMAND" in
         echo ""
         echo "Required environment variables:"
         echo "  DB_HOST, DB_PORT, DB_NAME, DB_USER, DB_PASSWORD"
-        echo "  NATS_URL, JWT_SECRET, REFRESH_SECRET"
+        echo "  NATS_URL, JWT_SECRET, REFRESH_SECRET, MASTER_KEY (or MASTER_KEYS), SIGNING_KEY"
         ;;
         
     *)
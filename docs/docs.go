@@ -24,6 +24,74 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/reconciliation": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Recomputes sub-account balances from their transactions and reports every sub-account whose balance differs from the sum of its transactions or whose closing balances do not chain. With adjust, an adjustment transaction is posted for each drift (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Reconcile ledger",
+                "parameters": [
+                    {
+                        "description": "Reconciliation scope and options",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/services.ReconciliationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.ReconciliationReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/admin/trading-logs": {
             "get": {
                 "security": [
@@ -733,7 +801,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Creates a new exchange binding for the authenticated user",
+                "description": "Creates a new exchange binding for the authenticated user\nAPI key and secret are stored encrypted and are never returned; an API key or secret already bound by the user is rejected with 409.\nPrivate bindings to real exchanges are verified with the exchange: a binding whose credentials fail the check is created with status \"error\" and the reason in info.verification.",
                 "consumes": [
                     "application/json"
                 ],
@@ -924,7 +992,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Updates an existing exchange binding (must be owned by user)",
+                "description": "Updates an existing exchange binding (must be owned by user)\nA new API key or secret is stored encrypted. New credentials and a change of the status to \"active\" are verified with the exchange; the result is stored in info.verification and a failed check sets status \"error\".",
                 "consumes": [
                     "application/json"
                 ],
@@ -1139,6 +1207,106 @@ const docTemplate = `{
                 }
             }
         },
+        "/orders": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves the exchange orders of the authenticated user with filtering and pagination, newest first.\nOrders are created and updated by order events: fills are aggregated into the filled amount and the average price.\nStatus moves from open to partially_filled, filled, cancelled or failed; a partially filled order can still be filled or cancelled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Get user orders",
+                "parameters": [
+                    {
+                        "enum": [
+                            "open",
+                            "partially_filled",
+                            "filled",
+                            "cancelled",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Filter by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "buy",
+                            "sell"
+                        ],
+                        "type": "string",
+                        "description": "Filter by side",
+                        "name": "side",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by symbol",
+                        "name": "symbol",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (RFC3339 format)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (RFC3339 format)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Number of orders to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of orders to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.OrderQueryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/sub-accounts": {
             "get": {
                 "security": [
@@ -1420,6 +1588,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1504,23 +1678,20 @@ const docTemplate = `{
             }
         },
         "/sub-accounts/{id}/balance": {
-            "put": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Updates sub-account balance with proper logging (must belong to authenticated user)",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Resolves the balance of a sub-account from the closing balance of its latest transaction at or before the given time (must belong to authenticated user)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "SubAccounts"
                 ],
-                "summary": "Update sub-account balance",
+                "summary": "Get sub-account balance at a point in time",
                 "parameters": [
                     {
                         "type": "string",
@@ -1530,20 +1701,17 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Update balance request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/services.UpdateBalanceRequest"
-                        }
+                        "type": "string",
+                        "description": "Point in time (RFC3339 format), defaults to now",
+                        "name": "at",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/services.SubAccountResponse"
+                            "$ref": "#/definitions/services.SubAccountBalanceAtResponse"
                         }
                     },
                     "400": {
@@ -1571,72 +1739,47 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/trading-logs": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves trading log history for the authenticated user with filtering and pagination",
+                "description": "Updates sub-account balance with proper logging (must belong to authenticated user).\nOnly sub-accounts of simulation and backtest tradings can be updated manually; the balances of a real trading change through trading logs",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "TradingLogs"
+                    "SubAccounts"
                 ],
-                "summary": "Get user trading logs",
+                "summary": "Update sub-account balance",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Filter by log type",
-                        "name": "type",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "manual",
-                            "bot"
-                        ],
-                        "type": "string",
-                        "description": "Filter by source",
-                        "name": "source",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Start date (RFC3339 format)",
-                        "name": "start_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "End date (RFC3339 format)",
-                        "name": "end_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 100,
-                        "description": "Number of logs to return",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Sub-account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Number of logs to skip",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "Update balance request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.UpdateBalanceRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/services.TradingLogQueryResponse"
+                            "$ref": "#/definitions/services.SubAccountResponse"
                         }
                     },
                     "400": {
@@ -1651,6 +1794,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1658,14 +1813,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/sub-accounts/{id}/lock": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Creates a new trading log entry for the authenticated user.\n\n**Important**: The 'info' field structure must match the 'type' field:\n\n**Business Logic Types** (trigger automatic financial calculations):\n\n**For long/short/stop_loss types** - Required fields in 'info':\n- stock_account_id (string): Sub-account UUID for the asset (e.g., ETH account)\n- currency_account_id (string): Sub-account UUID for the currency (e.g., USDT account)\n- price (number): Price per unit (must be positive, up to 8 decimal places)\n- volume (number): Quantity traded (must be positive, up to 8 decimal places)\n- stock (string): Asset symbol, 1-20 characters (e.g., \"ETH\")\n- currency (string): Currency symbol, 1-20 characters (e.g., \"USDT\")\n- fee (number): Trading fee (must be non-negative, up to 8 decimal places)\n\n**For deposit/withdraw types** - Required fields in 'info':\n- account_id (string): Target sub-account UUID for the operation\n- amount (number): Amount to deposit/withdraw (must be positive, up to 8 decimal places)\n- currency (string): Currency symbol, 1-20 characters (e.g., \"USDT\")\n\n**Request Examples**:\n\n**Long Position Example:**\n\u003cpre\u003e\u003ccode\u003e{\n⠀⠀\"trading_id\": \"453f0347-3959-49de-8e3f-1cf7c8e0827c\",\n⠀⠀\"type\": \"long\",\n⠀⠀\"source\": \"bot\",\n⠀⠀\"message\": \"ETH long position opened\",\n⠀⠀\"info\": {\n⠀⠀⠀⠀\"stock_account_id\": \"eth-account-uuid\",\n⠀⠀⠀⠀\"currency_account_id\": \"usdt-account-uuid\",\n⠀⠀⠀⠀\"price\": 3000.00,\n⠀⠀⠀⠀\"volume\": 2.0,\n⠀⠀⠀⠀\"stock\": \"ETH\",\n⠀⠀⠀⠀\"currency\": \"USDT\",\n⠀⠀⠀⠀\"fee\": 12.00\n⠀⠀}\n}\u003c/code\u003e\u003c/pre\u003e\n\n**Deposit Example:**\n\u003cpre\u003e\u003ccode\u003e{\n⠀⠀\"trading_id\": \"453f0347-3959-49de-8e3f-1cf7c8e0827c\",\n⠀⠀\"type\": \"deposit\",\n⠀⠀\"source\": \"api\",\n⠀⠀\"message\": \"USDT deposit to account\",\n⠀⠀\"info\": {\n⠀⠀⠀⠀\"account_id\": \"usdt-account-uuid\",\n⠀⠀⠀⠀\"amount\": 1000.00,\n⠀⠀⠀⠀\"currency\": \"USDT\"\n⠀⠀}\n}\u003c/code\u003e\u003c/pre\u003e\n\n**Other Types**: Can use any object structure in the 'info' field",
+                "description": "Moves an amount of the available balance to the locked balance, e.g. for an open order. Locked funds cannot be spent until unlocked (must belong to authenticated user)",
                 "consumes": [
                     "application/json"
                 ],
@@ -1673,29 +1830,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "TradingLogs"
+                    "SubAccounts"
                 ],
-                "summary": "Create trading log",
+                "summary": "Lock sub-account balance",
                 "parameters": [
                     {
-                        "description": "Create trading log request",
+                        "type": "string",
+                        "description": "Sub-account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Lock balance request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/services.CreateTradingLogRequest"
+                            "$ref": "#/definitions/services.LockBalanceRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/services.TradingLogResponse"
+                            "$ref": "#/definitions/services.SubAccountResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request - Invalid request format, missing required fields, or incorrect 'info' structure for the specified 'type'. Common validation errors: Missing required 'info' fields for business logic types, Invalid data types or values in 'info' fields, Non-existent sub-account IDs referenced in 'info' fields",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
@@ -1707,13 +1871,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Not Found - Trading ID or sub-account IDs referenced in 'info' field do not exist",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    },
-                    "422": {
-                        "description": "Unprocessable Entity - Business logic validation failed (e.g., insufficient balance for withdraw operations)",
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
@@ -1727,29 +1885,92 @@ const docTemplate = `{
                 }
             }
         },
-        "/trading-logs/sub-account/{sub_account_id}": {
-            "get": {
+        "/sub-accounts/{id}/unlock": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves trading log history for a specific sub-account (must belong to authenticated user)",
+                "description": "Moves an amount of the locked balance back to the available balance (must belong to authenticated user)",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "TradingLogs"
+                    "SubAccounts"
                 ],
-                "summary": "Get sub-account trading logs",
+                "summary": "Unlock sub-account balance",
                 "parameters": [
                     {
                         "type": "string",
                         "description": "Sub-account ID",
-                        "name": "sub_account_id",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "description": "Unlock balance request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.LockBalanceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.SubAccountResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/trading-logs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves trading log history for the authenticated user with filtering and pagination",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "TradingLogs"
+                ],
+                "summary": "Get user trading logs",
+                "parameters": [
                     {
                         "type": "string",
                         "description": "Filter by log type",
@@ -1812,12 +2033,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1825,74 +2040,121 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/trading-logs/time-range": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves trading logs within a specific time range for the authenticated user",
+                "description": "Creates a new trading log entry for the authenticated user.\n\n**Important**: The 'info' field structure must match the 'type' field:\n\n**Business Logic Types** (trigger automatic financial calculations):\n\n**For long/short/stop_loss types** - Required fields in 'info':\n- stock_account_id (string): Sub-account UUID for the asset (e.g., ETH account)\n- currency_account_id (string): Sub-account UUID for the currency (e.g., USDT account)\n- price (number): Price per unit (must be positive, up to 8 decimal places)\n- volume (number): Quantity traded (must be positive, up to 8 decimal places)\n- stock (string): Asset symbol, 1-20 characters (e.g., \"ETH\")\n- currency (string): Currency symbol, 1-20 characters (e.g., \"USDT\")\n- fee (number): Trading fee (must be non-negative, up to 8 decimal places)\n- fee_account_id (string, optional): UUID of a sub-account of the trading charged with the fee as a separate 'fee' transaction (e.g., a BNB account, or the stock account for fees in the base asset)\n- fee_asset (string, optional): Symbol of the fee account, required with fee_account_id (e.g., \"BNB\")\n\n**For deposit/withdraw types** - Required fields in 'info':\n- account_id (string): Target sub-account UUID for the operation\n- amount (number): Amount to deposit/withdraw (must be positive, up to 8 decimal places)\n- currency (string): Currency symbol, 1-20 characters (e.g., \"USDT\")\n\n**For transfer type** - Moves funds between two sub-accounts of the trading holding the same symbol. Required fields in 'info':\n- from_account_id (string): Sub-account UUID the funds are taken from\n- to_account_id (string): Sub-account UUID the funds are moved to\n- amount (number): Amount to transfer (must be positive, up to 8 decimal places)\n- currency (string): Symbol held by both sub-accounts, 1-20 characters (e.g., \"USDT\")\n\n**For borrow/repay types** - Margin mode only. Records an amount borrowed from or repaid to the exchange; no funds are moved,\nthe balance of the sub-account may go down to minus its borrowed amount. Same 'info' fields as deposit/withdraw.\nIn margin mode long/short/stop_loss trades are rejected when the margin level would fall below the collateral ratio of the trading.\n\n**Request Examples**:\n\n**Long Position Example:**\n\u003cpre\u003e\u003ccode\u003e{\n⠀⠀\"trading_id\": \"453f0347-3959-49de-8e3f-1cf7c8e0827c\",\n⠀⠀\"type\": \"long\",\n⠀⠀\"source\": \"bot\",\n⠀⠀\"message\": \"ETH long position opened\",\n⠀⠀\"info\": {\n⠀⠀⠀⠀\"stock_account_id\": \"eth-account-uuid\",\n⠀⠀⠀⠀\"currency_account_id\": \"usdt-account-uuid\",\n⠀⠀⠀⠀\"price\": 3000.00,\n⠀⠀⠀⠀\"volume\": 2.0,\n⠀⠀⠀⠀\"stock\": \"ETH\",\n⠀⠀⠀⠀\"currency\": \"USDT\",\n⠀⠀⠀⠀\"fee\": 12.00\n⠀⠀}\n}\u003c/code\u003e\u003c/pre\u003e\n\n**Deposit Example:**\n\u003cpre\u003e\u003ccode\u003e{\n⠀⠀\"trading_id\": \"453f0347-3959-49de-8e3f-1cf7c8e0827c\",\n⠀⠀\"type\": \"deposit\",\n⠀⠀\"source\": \"api\",\n⠀⠀\"message\": \"USDT deposit to account\",\n⠀⠀\"info\": {\n⠀⠀⠀⠀\"account_id\": \"usdt-account-uuid\",\n⠀⠀⠀⠀\"amount\": 1000.00,\n⠀⠀⠀⠀\"currency\": \"USDT\"\n⠀⠀}\n}\u003c/code\u003e\u003c/pre\u003e\n\n**Other Types**: Can use any object structure in the 'info' field\n\n**Retries**: Send an Idempotency-Key header to make retries safe. A retry with the same key and body returns the original response\nwith the Idempotent-Replayed header set and does not create another trading log. Reusing a key with a different body is rejected with 409.\n\n**Backdating**: A business logic log with an ` + "`" + `event_time` + "`" + ` in the past places its transactions at that time and recomputes the closing balances\nof the later transactions of the affected sub-accounts. It is rejected with 422 if a historical balance would become negative.\nSimulation and backtest tradings accept any ` + "`" + `event_time` + "`" + `; for a real trading an ` + "`" + `event_time` + "`" + ` in the future is rejected with 400.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "TradingLogs"
                 ],
-                "summary": "Get trading logs by time range",
+                "summary": "Create trading log",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Start time (RFC3339 format)",
-                        "name": "start_time",
-                        "in": "query",
-                        "required": true
+                        "description": "Client-generated key (max 255 characters) identifying this request for safe retries",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     },
                     {
-                        "type": "string",
-                        "description": "End time (RFC3339 format)",
-                        "name": "end_time",
-                        "in": "query",
-                        "required": true
+                        "description": "Create trading log request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.CreateTradingLogRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/services.TradingLogResponse"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "Filter by log type",
-                        "name": "type",
-                        "in": "query"
+                    "400": {
+                        "description": "Bad Request - Invalid request format, missing required fields, or incorrect 'info' structure for the specified 'type'. Common validation errors: Missing required 'info' fields for business logic types, Invalid data types or values in 'info' fields, Non-existent sub-account IDs referenced in 'info' fields",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     },
-                    {
-                        "enum": [
-                            "manual",
-                            "bot"
-                        ],
-                        "type": "string",
-                        "description": "Filter by source",
-                        "name": "source",
-                        "in": "query"
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     },
-                    {
-                        "type": "integer",
-                        "default": 100,
-                        "description": "Number of logs to return",
-                        "name": "limit",
-                        "in": "query"
+                    "404": {
+                        "description": "Not Found - Trading ID or sub-account IDs referenced in 'info' field do not exist",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - Idempotency key already used with a different request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity - Business logic validation failed (e.g., insufficient balance for withdraw operations, or a backdated trade making a historical balance negative)",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/trading-logs/batch": {
+            "post": {
+                "security": [
                     {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Number of logs to skip",
-                        "name": "offset",
-                        "in": "query"
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Processes up to 500 trading log entries in the given order within a single database transaction.\nEach entry follows the same rules as POST /trading-logs.\n\nBy default the batch is all-or-nothing: if any entry fails, nothing is committed and the response reports the failing entry.\nWith continue_on_error set, each failed entry is rolled back on its own and reported in the results while the other entries are committed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "TradingLogs"
+                ],
+                "summary": "Create trading logs in batch",
+                "parameters": [
+                    {
+                        "description": "Batch create trading logs request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.BatchCreateTradingLogsRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/services.TradingLogQueryResponse"
+                            "$ref": "#/definitions/services.BatchCreateTradingLogsResponse"
                         }
                     },
                     "400": {
@@ -1907,6 +2169,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "422": {
+                        "description": "Unprocessable Entity - An entry failed and the whole batch was rolled back",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1916,26 +2184,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/trading-logs/trading/{trading_id}": {
+        "/trading-logs/sub-account/{sub_account_id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves trading log history for a specific trading (must belong to authenticated user)",
+                "description": "Retrieves trading log history for a specific sub-account (must belong to authenticated user)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "TradingLogs"
                 ],
-                "summary": "Get trading logs",
+                "summary": "Get sub-account trading logs",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Trading ID",
-                        "name": "trading_id",
+                        "description": "Sub-account ID",
+                        "name": "sub_account_id",
                         "in": "path",
                         "required": true
                     },
@@ -2016,91 +2284,72 @@ const docTemplate = `{
                 }
             }
         },
-        "/trading-logs/{id}": {
+        "/trading-logs/time-range": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves a specific trading log by ID (must belong to authenticated user)",
+                "description": "Retrieves trading logs within a specific time range for the authenticated user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "TradingLogs"
                 ],
-                "summary": "Get trading log by ID",
+                "summary": "Get trading logs by time range",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Trading Log ID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "Start time (RFC3339 format)",
+                        "name": "start_time",
+                        "in": "query",
                         "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/services.TradingLogResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
+                    {
+                        "type": "string",
+                        "description": "End time (RFC3339 format)",
+                        "name": "end_time",
+                        "in": "query",
+                        "required": true
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    }
-                }
-            },
-            "delete": {
-                "security": [
                     {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Deletes a trading log entry (must belong to authenticated user and be manual)",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "TradingLogs"
-                ],
-                "summary": "Delete trading log",
-                "parameters": [
+                        "type": "string",
+                        "description": "Filter by log type",
+                        "name": "type",
+                        "in": "query"
+                    },
                     {
+                        "enum": [
+                            "manual",
+                            "bot"
+                        ],
                         "type": "string",
-                        "description": "Trading Log ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Filter by source",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Number of logs to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of logs to skip",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
+                            "$ref": "#/definitions/services.TradingLogQueryResponse"
                         }
                     },
                     "400": {
@@ -2115,18 +2364,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    },
-                    "409": {
-                        "description": "Conflict",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2136,78 +2373,77 @@ const docTemplate = `{
                 }
             }
         },
-        "/tradings": {
+        "/trading-logs/trading/{trading_id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves all trading configurations for the authenticated user",
+                "description": "Retrieves trading log history for a specific trading (must belong to authenticated user)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tradings"
+                    "TradingLogs"
                 ],
-                "summary": "Get user tradings",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/services.TradingResponse"
-                            }
-                        }
+                "summary": "Get trading logs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Trading ID",
+                        "name": "trading_id",
+                        "in": "path",
+                        "required": true
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
+                    {
+                        "type": "string",
+                        "description": "Filter by log type",
+                        "name": "type",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    }
-                }
-            },
-            "post": {
-                "security": [
                     {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Creates a new trading configuration for the authenticated user",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Tradings"
-                ],
-                "summary": "Create new trading",
-                "parameters": [
+                        "enum": [
+                            "manual",
+                            "bot"
+                        ],
+                        "type": "string",
+                        "description": "Filter by source",
+                        "name": "source",
+                        "in": "query"
+                    },
                     {
-                        "description": "Create trading request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/services.CreateTradingRequest"
-                        }
+                        "type": "string",
+                        "description": "Start date (RFC3339 format)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (RFC3339 format)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Number of logs to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of logs to skip",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/services.TradingResponse"
+                            "$ref": "#/definitions/services.TradingLogQueryResponse"
                         }
                     },
                     "400": {
@@ -2222,8 +2458,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
@@ -2237,25 +2473,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/tradings/{id}": {
+        "/trading-logs/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves a specific trading configuration by ID (must belong to authenticated user)",
+                "description": "Retrieves a specific trading log by ID (must belong to authenticated user)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tradings"
+                    "TradingLogs"
                 ],
-                "summary": "Get trading by ID",
+                "summary": "Get trading log by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Trading ID",
+                        "description": "Trading Log ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2265,7 +2501,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/services.TradingResponse"
+                            "$ref": "#/definitions/services.TradingLogResponse"
                         }
                     },
                     "400": {
@@ -2294,46 +2530,34 @@ const docTemplate = `{
                     }
                 }
             },
-            "put": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Updates an existing trading configuration (must belong to authenticated user)",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Deletes a trading log entry (must belong to authenticated user and be manual).\nFor business logic types (long, short, stop_loss, deposit, withdraw, transfer, borrow, repay) compensating transactions\nare posted to restore the affected sub-account balances. Deletion is refused with 409 if a later\ntrading log has already spent the balance the reversal would take back, or if the log has been amended.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tradings"
+                    "TradingLogs"
                 ],
-                "summary": "Update trading",
+                "summary": "Delete trading log",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Trading ID",
+                        "description": "Trading Log ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Update trading request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/services.UpdateTradingRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/services.TradingResponse"
+                            "$ref": "#/definitions/api.SuccessResponse"
                         }
                     },
                     "400": {
@@ -2367,35 +2591,49 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/trading-logs/{id}/amend": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Deletes a trading configuration (must belong to authenticated user)",
+                "description": "Corrects a trading log (manual or bot) without deleting it. For business logic types (long, short, stop_loss, deposit, withdraw, transfer, borrow, repay)\nthe transactions of the original log are compensated with reversal transactions and the corrected info is processed as a new trading log,\nin one database transaction. The new log references the original through amends and the original the new log through amended_by.\nA trading log can be amended once; amend the correcting log to correct it again.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tradings"
+                    "TradingLogs"
                 ],
-                "summary": "Delete trading",
+                "summary": "Amend trading log",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Trading ID",
+                        "description": "Trading Log ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Corrected trading log info",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.AmendTradingLogRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
+                            "$ref": "#/definitions/services.TradingLogResponse"
                         }
                     },
                     "400": {
@@ -2431,88 +2669,29 @@ const docTemplate = `{
                 }
             }
         },
-        "/transactions": {
+        "/tradings": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves transaction history for the authenticated user with filtering and pagination",
+                "description": "Retrieves all trading configurations for the authenticated user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Transactions"
-                ],
-                "summary": "Get user transactions",
-                "parameters": [
-                    {
-                        "enum": [
-                            "debit",
-                            "credit"
-                        ],
-                        "type": "string",
-                        "description": "Filter by direction",
-                        "name": "direction",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by reason",
-                        "name": "reason",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Start date (RFC3339 format)",
-                        "name": "start_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "End date (RFC3339 format)",
-                        "name": "end_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "number",
-                        "description": "Minimum amount filter",
-                        "name": "min_amount",
-                        "in": "query"
-                    },
-                    {
-                        "type": "number",
-                        "description": "Maximum amount filter",
-                        "name": "max_amount",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 100,
-                        "description": "Number of transactions to return",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Number of transactions to skip",
-                        "name": "offset",
-                        "in": "query"
-                    }
+                    "Tradings"
                 ],
+                "summary": "Get user tradings",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/services.TransactionQueryResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.TradingResponse"
+                            }
                         }
                     },
                     "401": {
@@ -2528,91 +2707,40 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/transactions/sub-account/{sub_account_id}": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves transaction history for a specific sub-account (must belong to authenticated user)",
+                "description": "Creates a new trading configuration for the authenticated user.\nIn margin mode sub-accounts can borrow with borrow/repay trading logs and sell short down to minus the borrowed amount;\ntrades that would bring the margin level (value of positive balances / value of negative balances) below the collateral ratio are rejected.\nThe type is real, simulation or backtest. Simulation and backtest tradings must use a virtual exchange binding (the virtual exchange or a public binding),\naccept trading logs with any event time and are left out of the total balance of the user; real tradings reject event times in the future and manual balance updates.\nSimulation and backtest tradings can set the fee and slippage models of the virtual exchange with virtual_exchange.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Transactions"
+                    "Tradings"
                 ],
-                "summary": "Get sub-account transactions",
+                "summary": "Create new trading",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Sub-account ID",
-                        "name": "sub_account_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "enum": [
-                            "debit",
-                            "credit"
-                        ],
-                        "type": "string",
-                        "description": "Filter by direction",
-                        "name": "direction",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by reason",
-                        "name": "reason",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Start date (RFC3339 format)",
-                        "name": "start_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "End date (RFC3339 format)",
-                        "name": "end_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "number",
-                        "description": "Minimum amount filter",
-                        "name": "min_amount",
-                        "in": "query"
-                    },
-                    {
-                        "type": "number",
-                        "description": "Maximum amount filter",
-                        "name": "max_amount",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 100,
-                        "description": "Number of transactions to return",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Number of transactions to skip",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "Create trading request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.CreateTradingRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/services.TransactionQueryResponse"
+                            "$ref": "#/definitions/services.TradingResponse"
                         }
                     },
                     "400": {
@@ -2627,8 +2755,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
@@ -2642,84 +2770,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/transactions/time-range": {
+        "/tradings/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves transactions within a specific time range for the authenticated user",
+                "description": "Retrieves a specific trading configuration by ID (must belong to authenticated user)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Transactions"
+                    "Tradings"
                 ],
-                "summary": "Get transactions by time range",
+                "summary": "Get trading by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Start time (RFC3339 format)",
-                        "name": "start_time",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "End time (RFC3339 format)",
-                        "name": "end_time",
-                        "in": "query",
+                        "description": "Trading ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
-                    },
-                    {
-                        "enum": [
-                            "debit",
-                            "credit"
-                        ],
-                        "type": "string",
-                        "description": "Filter by direction",
-                        "name": "direction",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by reason",
-                        "name": "reason",
-                        "in": "query"
-                    },
-                    {
-                        "type": "number",
-                        "description": "Minimum amount filter",
-                        "name": "min_amount",
-                        "in": "query"
-                    },
-                    {
-                        "type": "number",
-                        "description": "Maximum amount filter",
-                        "name": "max_amount",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 100,
-                        "description": "Number of transactions to return",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Number of transactions to skip",
-                        "name": "offset",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/services.TransactionQueryResponse"
+                            "$ref": "#/definitions/services.TradingResponse"
                         }
                     },
                     "400": {
@@ -2734,6 +2813,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2741,91 +2826,47 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/transactions/trading/{trading_id}": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves transaction history for a specific trading (must belong to authenticated user)",
+                "description": "Updates an existing trading configuration (must belong to authenticated user).\nMargin mode cannot be disabled while a sub-account borrows or has a negative balance.\nSimulation and backtest tradings can only be moved to another virtual exchange binding, and can change the fee and slippage models of the virtual exchange.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Transactions"
+                    "Tradings"
                 ],
-                "summary": "Get trading transactions",
+                "summary": "Update trading",
                 "parameters": [
                     {
                         "type": "string",
                         "description": "Trading ID",
-                        "name": "trading_id",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "enum": [
-                            "debit",
-                            "credit"
-                        ],
-                        "type": "string",
-                        "description": "Filter by direction",
-                        "name": "direction",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by reason",
-                        "name": "reason",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Start date (RFC3339 format)",
-                        "name": "start_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "End date (RFC3339 format)",
-                        "name": "end_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "number",
-                        "description": "Minimum amount filter",
-                        "name": "min_amount",
-                        "in": "query"
-                    },
-                    {
-                        "type": "number",
-                        "description": "Maximum amount filter",
-                        "name": "max_amount",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 100,
-                        "description": "Number of transactions to return",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Number of transactions to skip",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "Update trading request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.UpdateTradingRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/services.TransactionQueryResponse"
+                            "$ref": "#/definitions/services.TradingResponse"
                         }
                     },
                     "400": {
@@ -2846,6 +2887,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2853,27 +2900,25 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/transactions/{id}": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves a specific transaction by ID (must belong to authenticated user)",
+                "description": "Deletes a trading configuration (must belong to authenticated user)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Transactions"
+                    "Tradings"
                 ],
-                "summary": "Get transaction by ID",
+                "summary": "Delete trading",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Transaction ID",
+                        "description": "Trading ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2883,7 +2928,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/services.TransactionResponse"
+                            "$ref": "#/definitions/api.SuccessResponse"
                         }
                     },
                     "400": {
@@ -2904,6 +2949,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2913,34 +2964,33 @@ const docTemplate = `{
                 }
             }
         },
-        "/users": {
+        "/tradings/{id}/balance": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Lists all users with pagination (admin only)",
+                "description": "Resolves the balance of every sub-account of a trading from the closing balance of its latest transaction at or before the given time. Transactions created by a trading log with an event time are placed at that event time (must belong to authenticated user)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Tradings"
                 ],
-                "summary": "List all users",
+                "summary": "Get trading balances at a point in time",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 100,
-                        "description": "Number of users to return",
-                        "name": "limit",
-                        "in": "query"
+                        "type": "string",
+                        "description": "Trading ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Number of users to skip",
-                        "name": "offset",
+                        "type": "string",
+                        "description": "Point in time (RFC3339 format), defaults to now",
+                        "name": "at",
                         "in": "query"
                     }
                 ],
@@ -2948,7 +2998,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.PaginatedResponse"
+                            "$ref": "#/definitions/services.TradingBalanceAtResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
                     "401": {
@@ -2957,8 +3013,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
@@ -2972,26 +3028,41 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/me": {
+        "/tradings/{id}/balance-sync": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves the profile of the currently authenticated user",
+                "description": "Reads the balances of the exchange account of a real trading (must belong to authenticated user) and compares them with its sub-accounts by symbol.\nNothing is changed: approve the differences with POST /tradings/{id}/balance-sync. Exchange balances without a sub-account, symbols held by several sub-accounts and sub-accounts that borrow in margin mode are listed but not compared. A trading whose exchange binding is shared with other tradings cannot be synced.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Tradings"
+                ],
+                "summary": "Preview exchange balance sync",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Trading ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get current user profile",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/services.UserResponse"
+                            "$ref": "#/definitions/services.BalanceSyncReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
                     "401": {
@@ -3000,21 +3071,33 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     }
                 }
             },
-            "put": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Updates the profile of the currently authenticated user",
+                "description": "Posts an adjustment transaction for each approved difference of a preview, so the sub-account balance becomes its exchange balance (must belong to authenticated user).\nThe exchange is read again: if a ledger or exchange balance no longer matches its approval, nothing is posted and 409 is returned.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3022,17 +3105,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Tradings"
                 ],
-                "summary": "Update current user profile",
+                "summary": "Apply exchange balance sync",
                 "parameters": [
                     {
-                        "description": "Update user request",
+                        "type": "string",
+                        "description": "Trading ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Approved adjustments",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/services.UpdateUserRequest"
+                            "$ref": "#/definitions/services.ApplyBalanceSyncRequest"
                         }
                     }
                 ],
@@ -3040,7 +3130,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/services.UserResponse"
+                            "$ref": "#/definitions/services.BalanceSyncReport"
                         }
                     },
                     "400": {
@@ -3055,52 +3145,26 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/users/me/stats": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Retrieves statistics for the currently authenticated user",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Users"
-                ],
-                "summary": "Get current user statistics",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "502": {
+                        "description": "Bad Gateway",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
@@ -3108,35 +3172,66 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}": {
+        "/tradings/{id}/equity": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves a user by their ID (admin only)",
+                "description": "Returns the balance of every sub-account of a trading at the end of each time bucket, carrying balances forward across buckets without transactions. With a quote currency, the equity of the trading is valued at the last trade price of each symbol (must belong to authenticated user)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Tradings"
                 ],
-                "summary": "Get user by ID",
+                "summary": "Get trading equity curve",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Trading ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "1d",
+                        "description": "Bucket width: 1h, 1d or 1w",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start time (RFC3339 format), defaults to 30 intervals before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End time (RFC3339 format), defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Restrict the curve to one sub-account",
+                        "name": "sub_account_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote currency to value the equity of the trading in, e.g. USDT",
+                        "name": "quote",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/services.UserResponse"
+                            "$ref": "#/definitions/services.EquityCurveResponse"
                         }
                     },
                     "400": {
@@ -3151,12 +3246,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -3172,51 +3261,318 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}/disable": {
-            "put": {
+        "/tradings/{id}/orders": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Disables a user account by ID (admin only)",
+                "description": "Retrieves the exchange orders of a specific trading with filtering and pagination, newest first (must belong to authenticated user)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Orders"
                 ],
-                "summary": "Disable user account",
+                "summary": "Get trading orders",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Trading ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
-                        }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
+                    {
+                        "enum": [
+                            "open",
+                            "partially_filled",
+                            "filled",
+                            "cancelled",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Filter by status",
+                        "name": "status",
+                        "in": "query"
                     },
-                    "401": {
+                    {
+                        "enum": [
+                            "buy",
+                            "sell"
+                        ],
+                        "type": "string",
+                        "description": "Filter by side",
+                        "name": "side",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by symbol",
+                        "name": "symbol",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (RFC3339 format)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (RFC3339 format)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Number of orders to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of orders to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.OrderQueryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
                         "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tradings/{id}/performance": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns strategy statistics of a trading over a date range: time-weighted total return, maximum drawdown, annualized Sharpe and Sortino ratios from the daily equity in the quote currency, and round trip statistics (win rate, average win and loss, profit factor) from the long, short and stop_loss trades. Results are cached for a few minutes (must belong to authenticated user)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tradings"
+                ],
+                "summary": "Get trading performance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Trading ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote currency to value the equity in, e.g. USDT",
+                        "name": "quote",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start time (RFC3339 format), defaults to the creation of the trading",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End time (RFC3339 format), defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.TradingPerformanceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tradings/{id}/valuation": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Values every sub-account of a trading in the quote currency, using the supplied prices or else the most recent trade price of each symbol against the quote currency. Returns the per-asset breakdown, the total equity and the time of the prices used (must belong to authenticated user)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tradings"
+                ],
+                "summary": "Get trading valuation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Trading ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote currency, e.g. USDT",
+                        "name": "quote",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "object",
+                        "description": "Prices in the quote currency by symbol, e.g. prices[ETH]=3000",
+                        "name": "prices",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.TradingValuationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tradings/{id}/virtual/orders": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Places a market or limit order on the virtual exchange of a simulation or backtest trading (must belong to authenticated user).\nThe order trades the stock account against the currency account, e.g. ETH/USDT, and stays open until a price tick of its symbol is pushed.\nFunds are checked when the order fills; a fill the ledger rejects fails the order.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "VirtualExchange"
+                ],
+                "summary": "Place virtual order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Trading ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Place order request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.PlaceVirtualOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/services.OrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
@@ -3227,6 +3583,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -3235,88 +3597,1741 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.ErrorDetail": {
+        },
+        "/tradings/{id}/virtual/orders/{order_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancels an open or partially filled order of the virtual exchange (must belong to authenticated user). Filled amounts stay in the ledger.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "VirtualExchange"
+                ],
+                "summary": "Cancel virtual order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Trading ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "order_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.OrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tradings/{id}/virtual/prices": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Pushes a price of the feed of a simulation or backtest trading (must belong to authenticated user). The open orders of the symbol are matched oldest first:\nmarket orders fill at the tick price with the slippage of the trading, limit orders fill once the price reaches their limit and never at a worse price.\nLimit orders resting from an earlier tick pay the maker fee; market orders and limit orders crossing the first tick after they were placed pay the taker fee. With a volume the fills of the tick never exceed it, so orders can fill partially.\nEach fill is recorded as a long (buy) or short (sell) trading log with the tick timestamp as its event time; fills are posted to the ledger in the order they happen. Price ticks can also be published on the NATS subject trading.prices.updated.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "VirtualExchange"
+                ],
+                "summary": "Push virtual price tick",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Trading ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Price tick",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.PriceTickRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.PriceTickResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves transaction history for the authenticated user with filtering and pagination",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Get user transactions",
+                "parameters": [
+                    {
+                        "enum": [
+                            "debit",
+                            "credit"
+                        ],
+                        "type": "string",
+                        "description": "Filter by direction",
+                        "name": "direction",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by reason",
+                        "name": "reason",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (RFC3339 format)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (RFC3339 format)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum amount filter",
+                        "name": "min_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum amount filter",
+                        "name": "max_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Number of transactions to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of transactions to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.TransactionQueryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/sub-account/{sub_account_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves transaction history for a specific sub-account (must belong to authenticated user)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Get sub-account transactions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sub-account ID",
+                        "name": "sub_account_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "debit",
+                            "credit"
+                        ],
+                        "type": "string",
+                        "description": "Filter by direction",
+                        "name": "direction",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by reason",
+                        "name": "reason",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (RFC3339 format)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (RFC3339 format)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum amount filter",
+                        "name": "min_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum amount filter",
+                        "name": "max_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Number of transactions to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of transactions to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.TransactionQueryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/time-range": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves transactions within a specific time range for the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Get transactions by time range",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start time (RFC3339 format)",
+                        "name": "start_time",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End time (RFC3339 format)",
+                        "name": "end_time",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "debit",
+                            "credit"
+                        ],
+                        "type": "string",
+                        "description": "Filter by direction",
+                        "name": "direction",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by reason",
+                        "name": "reason",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum amount filter",
+                        "name": "min_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum amount filter",
+                        "name": "max_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Number of transactions to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of transactions to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.TransactionQueryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/trading/{trading_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves transaction history for a specific trading (must belong to authenticated user)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Get trading transactions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Trading ID",
+                        "name": "trading_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "debit",
+                            "credit"
+                        ],
+                        "type": "string",
+                        "description": "Filter by direction",
+                        "name": "direction",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by reason",
+                        "name": "reason",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (RFC3339 format)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (RFC3339 format)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum amount filter",
+                        "name": "min_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum amount filter",
+                        "name": "max_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Number of transactions to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of transactions to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.TransactionQueryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a specific transaction by ID (must belong to authenticated user)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Get transaction by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.TransactionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists all users with pagination (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "List all users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Number of users to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of users to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.PaginatedResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves the profile of the currently authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get current user profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.UserResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates the profile of the currently authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Update current user profile",
+                "parameters": [
+                    {
+                        "description": "Update user request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/stats": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves statistics for the currently authenticated user. The total balance only counts the sub-accounts of real tradings",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get current user statistics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a user by their ID (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get user by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/disable": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Disables a user account by ID (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Disable user account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.ErrorDetail": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/api.ErrorDetail"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/api.ResponseMetadata"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "dependencies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/api.HealthStatus"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.HealthStatus": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.PaginatedResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "metadata": {
+                    "$ref": "#/definitions/api.ResponseMetadata"
+                },
+                "pagination": {
+                    "$ref": "#/definitions/api.PaginationMetadata"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.PaginationMetadata": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_offset": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.ResponseMetadata": {
+            "type": "object",
+            "properties": {
+                "timestamp": {
+                    "type": "string"
+                },
+                "trace_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.SuccessResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "metadata": {
+                    "$ref": "#/definitions/api.ResponseMetadata"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.CreateExchangeBindingRequest": {
+            "type": "object",
+            "required": [
+                "exchange",
+                "name",
+                "type"
+            ],
+            "properties": {
+                "api_key": {
+                    "type": "string"
+                },
+                "api_secret": {
+                    "type": "string"
+                },
+                "exchange": {
+                    "type": "string"
+                },
+                "info": {
+                    "$ref": "#/definitions/models.JSON"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ExchangeBinding": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "exchange": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "info": {
+                    "$ref": "#/definitions/models.JSON"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.JSON": {
+            "type": "object",
+            "additionalProperties": true
+        },
+        "models.UpdateExchangeBindingRequest": {
+            "type": "object",
+            "properties": {
+                "api_key": {
+                    "type": "string"
+                },
+                "api_secret": {
+                    "type": "string"
+                },
+                "info": {
+                    "$ref": "#/definitions/models.JSON"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.AmendTradingLogRequest": {
+            "description": "Corrected info of a trading log. The type, trading, sub-account and source of the original log are kept.",
+            "type": "object",
+            "required": [
+                "info"
+            ],
+            "properties": {
+                "event_time": {
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                },
+                "info": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "message": {
+                    "type": "string",
+                    "example": "ETH long position opened (corrected price)"
+                }
+            }
+        },
+        "services.ApplyBalanceSyncRequest": {
+            "type": "object",
+            "required": [
+                "approvals"
+            ],
+            "properties": {
+                "approvals": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/services.BalanceSyncApproval"
+                    }
+                }
+            }
+        },
+        "services.AssetValuation": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "string",
+                    "example": "2.50000000"
+                },
+                "price": {
+                    "description": "Price, Value and PriceSource are empty when no price is known for the symbol",
+                    "type": "string",
+                    "example": "3000.00000000"
+                },
+                "price_at": {
+                    "type": "string"
+                },
+                "price_source": {
+                    "type": "string",
+                    "example": "transaction"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "ETH"
+                },
+                "transaction_id": {
+                    "description": "TransactionID is the trade the price was taken from, for transaction prices",
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "7500.00000000"
+                }
+            }
+        },
+        "services.AuthResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.eyJ1c2VyX2lkIjoiN2U2YTg5ZTYtM2U0Ni00MWQzLWFlYzYtMjg1ZmQ5Mjg5ODNiIiwidXNlcm5hbWUiOiJqb2huZG9lIiwiZXhwIjoxNzg2OTk5NjQyfQ.abcd1234"
+                },
+                "expires_in": {
+                    "type": "integer",
+                    "example": 3600
+                },
+                "refresh_token": {
+                    "type": "string",
+                    "example": "rt_1234567890abcdef"
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
+                },
+                "user": {
+                    "$ref": "#/definitions/services.UserInfo"
+                }
+            }
+        },
+        "services.BalancePoint": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "string",
+                    "example": "1250.75"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.BalanceSyncApproval": {
+            "type": "object",
+            "required": [
+                "sub_account_id"
+            ],
+            "properties": {
+                "exchange_balance": {
+                    "type": "string",
+                    "example": "0.6"
+                },
+                "ledger_balance": {
+                    "type": "string",
+                    "example": "0.5"
+                },
+                "sub_account_id": {
+                    "type": "string",
+                    "example": "a1b2c3d4-e5f6-7890-abcd-ef1234567890"
+                }
+            }
+        },
+        "services.BalanceSyncAsset": {
+            "type": "object",
+            "properties": {
+                "asset": {
+                    "type": "string",
+                    "example": "ETH"
+                },
+                "balance": {
+                    "type": "string",
+                    "example": "2.00000000"
+                }
+            }
+        },
+        "services.BalanceSyncDiff": {
+            "type": "object",
+            "properties": {
+                "adjustment_transaction_id": {
+                    "description": "AdjustmentTransactionID is the adjustment posted for the difference, if any",
+                    "type": "string"
+                },
+                "difference": {
+                    "description": "Difference is the exchange balance minus the ledger balance",
+                    "type": "string",
+                    "example": "0.10000000"
+                },
+                "exchange_balance": {
+                    "type": "string",
+                    "example": "0.60000000"
+                },
+                "ledger_balance": {
+                    "type": "string",
+                    "example": "0.50000000"
+                },
+                "sub_account_id": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                }
+            }
+        },
+        "services.BalanceSyncReport": {
+            "type": "object",
+            "properties": {
+                "accounts_checked": {
+                    "type": "integer"
+                },
+                "accounts_with_diff": {
+                    "type": "integer"
+                },
+                "adjustments_posted": {
+                    "type": "integer"
+                },
+                "borrowing_sub_accounts": {
+                    "description": "BorrowingSubAccounts borrow funds or hold a short position in margin mode. Exchange balances do\nnot include the debt, so these sub-accounts are neither compared nor adjusted.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "checked_at": {
+                    "type": "string"
+                },
+                "diffs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.BalanceSyncDiff"
+                    }
+                },
+                "shared_symbols": {
+                    "description": "SharedSymbols are held by several sub-accounts; their exchange balance cannot be mapped to one\nof them, so they are neither compared nor adjusted",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "trading_id": {
+                    "type": "string"
+                },
+                "unmatched_assets": {
+                    "description": "UnmatchedAssets are exchange balances of symbols without a sub-account",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.BalanceSyncAsset"
+                    }
+                }
+            }
+        },
+        "services.BatchCreateTradingLogsRequest": {
+            "description": "Request for creating several trading log entries at once. Entries are processed in order within a single database transaction.",
+            "type": "object",
+            "required": [
+                "logs"
+            ],
+            "properties": {
+                "continue_on_error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "logs": {
+                    "type": "array",
+                    "maxItems": 500,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/services.CreateTradingLogRequest"
+                    }
+                }
+            }
+        },
+        "services.BatchCreateTradingLogsResponse": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "string"
+                "failed": {
+                    "type": "integer",
+                    "example": 0
                 },
-                "details": {
-                    "type": "string"
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.BatchTradingLogResult"
+                    }
                 },
-                "message": {
-                    "type": "string"
+                "succeeded": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 10
                 }
             }
         },
-        "api.ErrorResponse": {
+        "services.BatchTradingLogResult": {
             "type": "object",
             "properties": {
                 "error": {
-                    "$ref": "#/definitions/api.ErrorDetail"
+                    "type": "string"
                 },
-                "metadata": {
-                    "$ref": "#/definitions/api.ResponseMetadata"
+                "index": {
+                    "type": "integer",
+                    "example": 0
                 },
                 "success": {
-                    "type": "boolean"
+                    "type": "boolean",
+                    "example": true
+                },
+                "trading_log": {
+                    "$ref": "#/definitions/services.TradingLogResponse"
                 }
             }
         },
-        "api.HealthResponse": {
+        "services.CallbackRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "provider",
+                "state"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "4/0AX4XfWjQK9rO8tA1bCdE9..."
+                },
+                "provider": {
+                    "type": "string",
+                    "enum": [
+                        "google",
+                        "wechat"
+                    ],
+                    "example": "google"
+                },
+                "state": {
+                    "type": "string",
+                    "example": "abc123def456ghi789"
+                }
+            }
+        },
+        "services.ChainBreak": {
             "type": "object",
             "properties": {
-                "dependencies": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/api.HealthStatus"
-                    }
+                "closing_balance": {
+                    "type": "string",
+                    "example": "1200.00000000"
                 },
-                "status": {
-                    "type": "string"
+                "expected_closing_balance": {
+                    "type": "string",
+                    "example": "1000.00000000"
                 },
                 "timestamp": {
                     "type": "string"
                 },
-                "version": {
+                "transaction_id": {
                     "type": "string"
                 }
             }
         },
-        "api.HealthStatus": {
+        "services.CreateSubAccountRequest": {
             "type": "object",
+            "required": [
+                "name",
+                "symbol",
+                "trading_id"
+            ],
             "properties": {
-                "details": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1,
+                    "example": "BTC Trading Account"
+                },
+                "symbol": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "minLength": 1,
+                    "example": "BTC/USDT"
+                },
+                "trading_id": {
+                    "type": "string",
+                    "example": "453f0347-3959-49de-8e3f-1cf7c8e0827c"
+                }
+            }
+        },
+        "services.CreateTradingLogRequest": {
+            "description": "Request for creating a new trading log entry. The 'info' field structure depends on the 'type' value: - For types 'long', 'short', 'stop_loss': Must use TradingLogInfo structure - For types 'deposit', 'withdraw', 'borrow', 'repay': Must use DepositWithdrawInfo structure - For type 'transfer': Must use TransferInfo structure - For other types: Can use any object structure",
+            "type": "object",
+            "required": [
+                "message",
+                "source",
+                "trading_id",
+                "type"
+            ],
+            "properties": {
+                "event_time": {
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                },
+                "info": {
                     "type": "object",
                     "additionalProperties": true
                 },
                 "message": {
+                    "type": "string",
+                    "minLength": 1,
+                    "example": "Successfully executed BUY order for 0.5 BTC at $42,500"
+                },
+                "source": {
+                    "type": "string",
+                    "enum": [
+                        "manual",
+                        "bot"
+                    ],
+                    "example": "bot"
+                },
+                "sub_account_id": {
+                    "type": "string",
+                    "example": "b4e006d0-1069-4ef4-b33f-7690af4929f4"
+                },
+                "trading_id": {
+                    "type": "string",
+                    "example": "453f0347-3959-49de-8e3f-1cf7c8e0827c"
+                },
+                "transaction_id": {
+                    "type": "string",
+                    "example": "1a098613-e738-447d-b921-74c3594df3a5"
+                },
+                "type": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 1,
+                    "enum": [
+                        "long",
+                        "short",
+                        "stop_loss",
+                        "deposit",
+                        "withdraw",
+                        "transfer",
+                        "borrow",
+                        "repay",
+                        "trade_execution",
+                        "api_call",
+                        "system_event",
+                        "error",
+                        "custom"
+                    ],
+                    "example": "long"
+                }
+            }
+        },
+        "services.CreateTradingRequest": {
+            "type": "object",
+            "required": [
+                "exchange_binding_id",
+                "name",
+                "type"
+            ],
+            "properties": {
+                "collateral_ratio": {
+                    "description": "CollateralRatio is the minimum margin level in margin mode, 1.5 by default",
+                    "type": "string",
+                    "example": "1.5"
+                },
+                "exchange_binding_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "margin_mode": {
+                    "description": "MarginMode lets sub-accounts borrow and sell short",
+                    "type": "boolean",
+                    "example": false
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1,
+                    "example": "My Trading Account"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "real",
+                        "simulation",
+                        "backtest"
+                    ],
+                    "example": "real"
+                },
+                "virtual_exchange": {
+                    "description": "VirtualExchange configures the fees and slippage of the virtual exchange (simulation and backtest only)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.VirtualExchangeConfig"
+                        }
+                    ]
+                }
+            }
+        },
+        "services.EquityCurveResponse": {
+            "type": "object",
+            "properties": {
+                "equity": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.EquityPoint"
+                    }
+                },
+                "from": {
+                    "type": "string"
+                },
+                "interval": {
+                    "type": "string",
+                    "example": "1d"
+                },
+                "quote_symbol": {
+                    "type": "string",
+                    "example": "USDT"
+                },
+                "sub_accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.SubAccountBalanceCurve"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                },
+                "trading_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.EquityPoint": {
+            "type": "object",
+            "properties": {
+                "equity": {
+                    "type": "string",
+                    "example": "10000.00000000"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "unpriced_symbols": {
+                    "description": "UnpricedSymbols lists the symbols with a balance but no trade price yet, left out of the equity",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "services.ExchangeBindingInfo": {
+            "type": "object",
+            "properties": {
+                "exchange": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "string"
                 },
-                "status": {
-                    "type": "string"
+                "masked_api_key": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.LockBalanceRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "250.00"
+                }
+            }
+        },
+        "services.LoginRequest": {
+            "type": "object",
+            "required": [
+                "provider",
+                "redirect_uri"
+            ],
+            "properties": {
+                "provider": {
+                    "type": "string",
+                    "enum": [
+                        "google",
+                        "wechat"
+                    ],
+                    "example": "google"
+                },
+                "redirect_uri": {
+                    "type": "string",
+                    "example": "https://yourapp.com/auth/callback"
                 }
             }
         },
-        "api.PaginatedResponse": {
+        "services.LoginResponse": {
             "type": "object",
             "properties": {
-                "data": {},
-                "metadata": {
-                    "$ref": "#/definitions/api.ResponseMetadata"
-                },
-                "pagination": {
-                    "$ref": "#/definitions/api.PaginationMetadata"
+                "auth_url": {
+                    "type": "string",
+                    "example": "https://accounts.google.com/oauth/authorize?client_id=...\u0026redirect_uri=...\u0026state=abc123"
                 },
-                "success": {
-                    "type": "boolean"
+                "state": {
+                    "type": "string",
+                    "example": "abc123def456ghi789"
                 }
             }
         },
-        "api.PaginationMetadata": {
+        "services.OrderQueryResponse": {
             "type": "object",
             "properties": {
                 "has_more": {
@@ -3325,94 +5340,74 @@ const docTemplate = `{
                 "limit": {
                     "type": "integer"
                 },
-                "next_offset": {
-                    "type": "integer"
-                },
                 "offset": {
                     "type": "integer"
                 },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.OrderResponse"
+                    }
+                },
                 "total": {
                     "type": "integer"
                 }
             }
         },
-        "api.ResponseMetadata": {
+        "services.OrderResponse": {
             "type": "object",
             "properties": {
-                "timestamp": {
-                    "type": "string"
+                "amount": {
+                    "type": "string",
+                    "example": "1.5"
                 },
-                "trace_id": {
-                    "type": "string"
-                }
-            }
-        },
-        "api.SuccessResponse": {
-            "type": "object",
-            "properties": {
-                "data": {},
-                "metadata": {
-                    "$ref": "#/definitions/api.ResponseMetadata"
+                "avg_price": {
+                    "type": "string",
+                    "example": "2999.5"
                 },
-                "success": {
-                    "type": "boolean"
-                }
-            }
-        },
-        "models.CreateExchangeBindingRequest": {
-            "type": "object",
-            "required": [
-                "exchange",
-                "name",
-                "type"
-            ],
-            "properties": {
-                "api_key": {
+                "created_at": {
                     "type": "string"
                 },
-                "api_secret": {
-                    "type": "string"
+                "filled_amount": {
+                    "type": "string",
+                    "example": "0.5"
                 },
-                "exchange": {
+                "id": {
                     "type": "string"
                 },
                 "info": {
-                    "$ref": "#/definitions/models.JSON"
+                    "type": "object",
+                    "additionalProperties": true
                 },
-                "name": {
-                    "type": "string"
+                "order_id": {
+                    "type": "string",
+                    "example": "8389765493"
                 },
-                "type": {
-                    "type": "string"
+                "price": {
+                    "type": "string",
+                    "example": "3000"
                 },
-                "user_id": {
-                    "type": "string"
-                }
-            }
-        },
-        "models.ExchangeBinding": {
-            "type": "object",
-            "properties": {
-                "created_at": {
-                    "type": "string"
+                "side": {
+                    "type": "string",
+                    "example": "buy"
                 },
-                "exchange": {
-                    "type": "string"
+                "status": {
+                    "type": "string",
+                    "example": "partially_filled"
                 },
-                "id": {
+                "sub_account_id": {
                     "type": "string"
                 },
-                "info": {
-                    "$ref": "#/definitions/models.JSON"
-                },
-                "name": {
-                    "type": "string"
+                "symbol": {
+                    "type": "string",
+                    "example": "ETH/USDT"
                 },
-                "status": {
+                "trading_id": {
                     "type": "string"
                 },
                 "type": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "limit"
                 },
                 "updated_at": {
                     "type": "string"
@@ -3422,264 +5417,272 @@ const docTemplate = `{
                 }
             }
         },
-        "models.JSON": {
-            "type": "object",
-            "additionalProperties": true
-        },
-        "models.UpdateExchangeBindingRequest": {
-            "type": "object",
-            "properties": {
-                "api_key": {
-                    "type": "string"
-                },
-                "api_secret": {
-                    "type": "string"
-                },
-                "info": {
-                    "$ref": "#/definitions/models.JSON"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "status": {
-                    "type": "string"
-                }
-            }
-        },
-        "services.AuthResponse": {
+        "services.PlaceVirtualOrderRequest": {
             "type": "object",
+            "required": [
+                "currency_account_id",
+                "side",
+                "stock_account_id",
+                "type"
+            ],
             "properties": {
-                "access_token": {
+                "amount": {
                     "type": "string",
-                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.eyJ1c2VyX2lkIjoiN2U2YTg5ZTYtM2U0Ni00MWQzLWFlYzYtMjg1ZmQ5Mjg5ODNiIiwidXNlcm5hbWUiOiJqb2huZG9lIiwiZXhwIjoxNzg2OTk5NjQyfQ.abcd1234"
+                    "example": "0.5"
                 },
-                "expires_in": {
-                    "type": "integer",
-                    "example": 3600
-                },
-                "refresh_token": {
+                "client_order_id": {
+                    "description": "ClientOrderID is used as the order ID when given, so a bot can retry without placing the order twice",
                     "type": "string",
-                    "example": "rt_1234567890abcdef"
+                    "maxLength": 255,
+                    "example": "bot-42-0001"
                 },
-                "token_type": {
+                "currency_account_id": {
                     "type": "string",
-                    "example": "Bearer"
+                    "example": "0e5a8c1f-3f4c-4c43-9f8f-6c2b5e1f2a10"
                 },
-                "user": {
-                    "$ref": "#/definitions/services.UserInfo"
-                }
-            }
-        },
-        "services.CallbackRequest": {
-            "type": "object",
-            "required": [
-                "code",
-                "provider",
-                "state"
-            ],
-            "properties": {
-                "code": {
+                "price": {
                     "type": "string",
-                    "example": "4/0AX4XfWjQK9rO8tA1bCdE9..."
+                    "example": "3000"
                 },
-                "provider": {
+                "side": {
                     "type": "string",
                     "enum": [
-                        "google",
-                        "wechat"
+                        "buy",
+                        "sell"
                     ],
-                    "example": "google"
+                    "example": "buy"
                 },
-                "state": {
+                "stock_account_id": {
                     "type": "string",
-                    "example": "abc123def456ghi789"
+                    "example": "b4e006d0-1069-4ef4-b33f-7690af4929f4"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "market",
+                        "limit"
+                    ],
+                    "example": "limit"
                 }
             }
         },
-        "services.CreateSubAccountRequest": {
+        "services.PriceTickRequest": {
             "type": "object",
             "required": [
-                "name",
-                "symbol",
-                "trading_id"
+                "symbol"
             ],
             "properties": {
-                "name": {
+                "price": {
                     "type": "string",
-                    "maxLength": 100,
-                    "minLength": 1,
-                    "example": "BTC Trading Account"
+                    "example": "3000.5"
                 },
                 "symbol": {
                     "type": "string",
-                    "maxLength": 20,
-                    "minLength": 1,
-                    "example": "BTC/USDT"
+                    "maxLength": 50,
+                    "example": "ETH/USDT"
                 },
-                "trading_id": {
+                "timestamp": {
+                    "description": "Timestamp of the tick, used as the event time of its fills (e.g. the historical time in a backtest)",
                     "type": "string",
-                    "example": "453f0347-3959-49de-8e3f-1cf7c8e0827c"
+                    "example": "2024-01-15T10:30:00Z"
+                },
+                "volume": {
+                    "description": "Volume traded at the tick; when given, the fills of the tick never exceed it",
+                    "type": "string",
+                    "example": "12.5"
                 }
             }
         },
-        "services.CreateTradingLogRequest": {
-            "description": "Request for creating a new trading log entry. The 'info' field structure depends on the 'type' value: - For types 'long', 'short', 'stop_loss': Must use TradingLogInfo structure - For types 'deposit', 'withdraw': Must use DepositWithdrawInfo structure - For other types: Can use any object structure",
+        "services.PriceTickResponse": {
             "type": "object",
-            "required": [
-                "message",
-                "source",
-                "trading_id",
-                "type"
-            ],
             "properties": {
-                "event_time": {
-                    "type": "string",
-                    "example": "2024-01-15T10:30:00Z"
-                },
-                "info": {
-                    "type": "object",
-                    "additionalProperties": true
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.OrderResponse"
+                    }
                 },
-                "message": {
+                "price": {
                     "type": "string",
-                    "minLength": 1,
-                    "example": "Successfully executed BUY order for 0.5 BTC at $42,500"
+                    "example": "3000.5"
                 },
-                "source": {
+                "symbol": {
                     "type": "string",
-                    "enum": [
-                        "manual",
-                        "bot"
-                    ],
-                    "example": "bot"
+                    "example": "ETH/USDT"
+                }
+            }
+        },
+        "services.ReconciliationReport": {
+            "type": "object",
+            "properties": {
+                "accounts_checked": {
+                    "type": "integer"
                 },
-                "sub_account_id": {
-                    "type": "string",
-                    "example": "b4e006d0-1069-4ef4-b33f-7690af4929f4"
+                "accounts_with_chain_breaks": {
+                    "type": "integer"
                 },
-                "trading_id": {
-                    "type": "string",
-                    "example": "453f0347-3959-49de-8e3f-1cf7c8e0827c"
+                "accounts_with_drift": {
+                    "type": "integer"
                 },
-                "transaction_id": {
-                    "type": "string",
-                    "example": "1a098613-e738-447d-b921-74c3594df3a5"
+                "adjustments_posted": {
+                    "type": "integer"
                 },
-                "type": {
-                    "type": "string",
-                    "maxLength": 50,
-                    "minLength": 1,
-                    "enum": [
-                        "long",
-                        "short",
-                        "stop_loss",
-                        "deposit",
-                        "withdraw",
-                        "trade_execution",
-                        "api_call",
-                        "system_event",
-                        "error",
-                        "custom"
-                    ],
-                    "example": "long"
+                "completed_at": {
+                    "type": "string"
+                },
+                "drifts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.SubAccountDrift"
+                    }
+                },
+                "started_at": {
+                    "type": "string"
                 }
             }
         },
-        "services.CreateTradingRequest": {
+        "services.ReconciliationRequest": {
             "type": "object",
-            "required": [
-                "exchange_binding_id",
-                "name",
-                "type"
-            ],
             "properties": {
-                "exchange_binding_id": {
-                    "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                "adjust": {
+                    "description": "Adjust posts an adjustment transaction for each drifting sub-account so its transactions sum up to its balance",
+                    "type": "boolean",
+                    "example": false
                 },
-                "name": {
+                "sub_account_id": {
                     "type": "string",
-                    "maxLength": 100,
-                    "minLength": 1,
-                    "example": "My Trading Account"
+                    "example": "a1b2c3d4-e5f6-7890-abcd-ef1234567890"
                 },
-                "type": {
+                "trading_id": {
                     "type": "string",
-                    "example": "real"
+                    "example": "453f0347-3959-49de-8e3f-1cf7c8e0827c"
                 }
             }
         },
-        "services.ExchangeBindingInfo": {
+        "services.RefreshRequest": {
             "type": "object",
+            "required": [
+                "refresh_token"
+            ],
             "properties": {
-                "exchange": {
+                "refresh_token": {
+                    "type": "string",
+                    "example": "rt_1234567890abcdef"
+                }
+            }
+        },
+        "services.SubAccountBalanceAtResponse": {
+            "type": "object",
+            "properties": {
+                "at": {
                     "type": "string"
                 },
-                "id": {
+                "balance": {
+                    "type": "string",
+                    "example": "1250.75"
+                },
+                "name": {
                     "type": "string"
                 },
-                "masked_api_key": {
+                "sub_account_id": {
                     "type": "string"
                 },
-                "name": {
+                "symbol": {
                     "type": "string"
                 },
-                "type": {
+                "trading_id": {
+                    "type": "string"
+                },
+                "transaction_at": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "description": "TransactionID is the transaction whose closing balance is the balance; empty when the\nsub-account had no transactions yet",
                     "type": "string"
                 }
             }
         },
-        "services.LoginRequest": {
+        "services.SubAccountBalanceCurve": {
             "type": "object",
-            "required": [
-                "provider",
-                "redirect_uri"
-            ],
             "properties": {
-                "provider": {
-                    "type": "string",
-                    "enum": [
-                        "google",
-                        "wechat"
-                    ],
-                    "example": "google"
+                "name": {
+                    "type": "string"
                 },
-                "redirect_uri": {
-                    "type": "string",
-                    "example": "https://yourapp.com/auth/callback"
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.BalancePoint"
+                    }
+                },
+                "sub_account_id": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
                 }
             }
         },
-        "services.LoginResponse": {
+        "services.SubAccountDrift": {
             "type": "object",
             "properties": {
-                "auth_url": {
+                "adjustment_transaction_id": {
+                    "description": "AdjustmentTransactionID is the correcting transaction posted for the drift, if any",
+                    "type": "string"
+                },
+                "chain_breaks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.ChainBreak"
+                    }
+                },
+                "computed_balance": {
                     "type": "string",
-                    "example": "https://accounts.google.com/oauth/authorize?client_id=...\u0026redirect_uri=...\u0026state=abc123"
+                    "example": "1000.00000000"
                 },
-                "state": {
+                "drift": {
+                    "description": "Drift is the recorded balance minus the balance computed from the transactions",
                     "type": "string",
-                    "example": "abc123def456ghi789"
-                }
-            }
-        },
-        "services.RefreshRequest": {
-            "type": "object",
-            "required": [
-                "refresh_token"
-            ],
-            "properties": {
-                "refresh_token": {
+                    "example": "200.00000000"
+                },
+                "recorded_balance": {
                     "type": "string",
-                    "example": "rt_1234567890abcdef"
+                    "example": "1200.00000000"
+                },
+                "sub_account_id": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "trading_id": {
+                    "type": "string"
+                },
+                "transaction_count": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "string"
                 }
             }
         },
         "services.SubAccountResponse": {
             "type": "object",
             "properties": {
+                "available_balance": {
+                    "type": "string",
+                    "example": "1000.75"
+                },
+                "avg_cost": {
+                    "type": "string",
+                    "example": "3006.00"
+                },
                 "balance": {
-                    "type": "number"
+                    "type": "string",
+                    "example": "1250.75"
+                },
+                "borrowed": {
+                    "type": "string",
+                    "example": "0.00"
                 },
                 "created_at": {
                     "type": "string"
@@ -3691,9 +5694,17 @@ const docTemplate = `{
                     "type": "object",
                     "additionalProperties": true
                 },
+                "locked_balance": {
+                    "type": "string",
+                    "example": "250.00"
+                },
                 "name": {
                     "type": "string"
                 },
+                "realized_pnl": {
+                    "type": "string",
+                    "example": "120.50"
+                },
                 "symbol": {
                     "type": "string"
                 },
@@ -3708,6 +5719,23 @@ const docTemplate = `{
                 }
             }
         },
+        "services.TradingBalanceAtResponse": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "sub_accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.SubAccountBalanceAtResponse"
+                    }
+                },
+                "trading_id": {
+                    "type": "string"
+                }
+            }
+        },
         "services.TradingLogQueryResponse": {
             "type": "object",
             "properties": {
@@ -3734,6 +5762,12 @@ const docTemplate = `{
         "services.TradingLogResponse": {
             "type": "object",
             "properties": {
+                "amended_by": {
+                    "type": "string"
+                },
+                "amends": {
+                    "type": "string"
+                },
                 "event_time": {
                     "type": "string"
                 },
@@ -3770,9 +5804,108 @@ const docTemplate = `{
                 }
             }
         },
+        "services.TradingPerformanceResponse": {
+            "type": "object",
+            "properties": {
+                "average_loss": {
+                    "type": "string",
+                    "example": "-187.50000000"
+                },
+                "average_win": {
+                    "type": "string",
+                    "example": "250.00000000"
+                },
+                "calculated_at": {
+                    "type": "string"
+                },
+                "ending_equity": {
+                    "type": "string",
+                    "example": "11250.00000000"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "interval": {
+                    "description": "Interval is the resolution of the equity series the return statistics are computed from",
+                    "type": "string",
+                    "example": "1d"
+                },
+                "losing_trips": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "max_drawdown": {
+                    "type": "string",
+                    "example": "0.04200000"
+                },
+                "net_deposits": {
+                    "type": "string",
+                    "example": "0.00000000"
+                },
+                "profit_factor": {
+                    "type": "string",
+                    "example": "2.66666667"
+                },
+                "quote_symbol": {
+                    "type": "string",
+                    "example": "USDT"
+                },
+                "realized_pnl": {
+                    "type": "string",
+                    "example": "1250.00000000"
+                },
+                "round_trips": {
+                    "description": "RoundTrips counts the positions that were opened and closed back to a zero balance",
+                    "type": "integer",
+                    "example": 12
+                },
+                "sharpe_ratio": {
+                    "description": "SharpeRatio and SortinoRatio are annualized with a risk-free rate of zero",
+                    "type": "string",
+                    "example": "1.85000000"
+                },
+                "sortino_ratio": {
+                    "type": "string",
+                    "example": "2.60000000"
+                },
+                "starting_equity": {
+                    "type": "string",
+                    "example": "10000.00000000"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "total_return": {
+                    "type": "string",
+                    "example": "0.12500000"
+                },
+                "trading_id": {
+                    "type": "string"
+                },
+                "unpriced_symbols": {
+                    "description": "UnpricedSymbols lists the symbols held at the end without a trade price, left out of the equity",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "win_rate": {
+                    "type": "string",
+                    "example": "0.66666667"
+                },
+                "winning_trips": {
+                    "type": "integer",
+                    "example": 8
+                }
+            }
+        },
         "services.TradingResponse": {
             "type": "object",
             "properties": {
+                "collateral_ratio": {
+                    "type": "string",
+                    "example": "1.5"
+                },
                 "created_at": {
                     "type": "string"
                 },
@@ -3786,9 +5919,16 @@ const docTemplate = `{
                     "type": "object",
                     "additionalProperties": true
                 },
+                "margin_mode": {
+                    "type": "boolean"
+                },
                 "name": {
                     "type": "string"
                 },
+                "realized_pnl": {
+                    "type": "string",
+                    "example": "120.50"
+                },
                 "status": {
                     "type": "string"
                 },
@@ -3803,6 +5943,39 @@ const docTemplate = `{
                 }
             }
         },
+        "services.TradingValuationResponse": {
+            "type": "object",
+            "properties": {
+                "assets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.AssetValuation"
+                    }
+                },
+                "prices_at": {
+                    "description": "PricesAt is the time of the oldest price used",
+                    "type": "string"
+                },
+                "quote_symbol": {
+                    "type": "string",
+                    "example": "USDT"
+                },
+                "total_equity": {
+                    "type": "string",
+                    "example": "10000.00000000"
+                },
+                "trading_id": {
+                    "type": "string"
+                },
+                "unpriced_symbols": {
+                    "description": "UnpricedSymbols lists the symbols with a balance that could not be valued and are left out of the total equity",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "services.TransactionQueryResponse": {
             "type": "object",
             "properties": {
@@ -3830,10 +6003,10 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "amount": {
-                    "type": "number"
+                    "type": "string"
                 },
                 "closing_balance": {
-                    "type": "number"
+                    "type": "string"
                 },
                 "direction": {
                     "type": "string"
@@ -3846,11 +6019,14 @@ const docTemplate = `{
                     "additionalProperties": true
                 },
                 "price": {
-                    "type": "number"
+                    "type": "string"
                 },
                 "quote_symbol": {
                     "type": "string"
                 },
+                "realized_pnl": {
+                    "type": "string"
+                },
                 "reason": {
                     "type": "string"
                 },
@@ -3871,14 +6047,13 @@ const docTemplate = `{
         "services.UpdateBalanceRequest": {
             "type": "object",
             "required": [
-                "amount",
                 "direction",
                 "reason"
             ],
             "properties": {
                 "amount": {
-                    "type": "number",
-                    "example": 500.25
+                    "type": "string",
+                    "example": "500.25"
                 },
                 "direction": {
                     "type": "string",
@@ -3904,9 +6079,8 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "balance": {
-                    "type": "number",
-                    "minimum": 0,
-                    "example": 1250.75
+                    "type": "string",
+                    "example": "1250.75"
                 },
                 "name": {
                     "type": "string",
@@ -3925,10 +6099,18 @@ const docTemplate = `{
         "services.UpdateTradingRequest": {
             "type": "object",
             "properties": {
+                "collateral_ratio": {
+                    "type": "string",
+                    "example": "1.5"
+                },
                 "exchange_binding_id": {
                     "type": "string",
                     "example": "550e8400-e29b-41d4-a716-446655440000"
                 },
+                "margin_mode": {
+                    "type": "boolean",
+                    "example": true
+                },
                 "name": {
                     "type": "string",
                     "maxLength": 100,
@@ -3942,6 +6124,9 @@ const docTemplate = `{
                         "inactive"
                     ],
                     "example": "active"
+                },
+                "virtual_exchange": {
+                    "$ref": "#/definitions/services.VirtualExchangeConfig"
                 }
             }
         },
@@ -4019,6 +6204,47 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "services.VirtualExchangeConfig": {
+            "type": "object",
+            "properties": {
+                "fee_model": {
+                    "description": "FeeModel is none, percentage (maker and taker rates of the traded value) or fixed (per fill)",
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "percentage",
+                        "fixed"
+                    ],
+                    "example": "percentage"
+                },
+                "fixed_fee": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "maker_fee_rate": {
+                    "type": "string",
+                    "example": "0.001"
+                },
+                "slippage_bps": {
+                    "type": "string",
+                    "example": "5"
+                },
+                "slippage_model": {
+                    "description": "SlippageModel is none, fixed (basis points) or volume (basis points per tick volume consumed)",
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "fixed",
+                        "volume"
+                    ],
+                    "example": "fixed"
+                },
+                "taker_fee_rate": {
+                    "type": "string",
+                    "example": "0.002"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"tiris-backend/internal/middleware"
+	"tiris-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// BalanceSyncHandler handles the sync of sub-account balances with the exchange
+type BalanceSyncHandler struct {
+	balanceSyncService *services.BalanceSyncService
+}
+
+// NewBalanceSyncHandler creates a new balance sync handler
+func NewBalanceSyncHandler(balanceSyncService *services.BalanceSyncService) *BalanceSyncHandler {
+	return &BalanceSyncHandler{
+		balanceSyncService: balanceSyncService,
+	}
+}
+
+// PreviewBalanceSync compares the sub-accounts of a trading with its exchange account
+// @Summary Preview exchange balance sync
+// @Description Reads the balances of the exchange account of a real trading (must belong to authenticated user) and compares them with its sub-accounts by symbol.
+// @Description Nothing is changed: approve the differences with POST /tradings/{id}/balance-sync. Exchange balances without a sub-account, symbols held by several sub-accounts and sub-accounts that borrow in margin mode are listed but not compared. A trading whose exchange binding is shared with other tradings cannot be synced.
+// @Tags Tradings
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Trading ID"
+// @Success 200 {object} services.BalanceSyncReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Router /tradings/{id}/balance-sync [get]
+func (h *BalanceSyncHandler) PreviewBalanceSync(c *gin.Context) {
+	userID, tradingID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.balanceSyncService.PreviewSync(c.Request.Context(), userID, tradingID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreateSuccessResponse(report, getTraceID(c)))
+}
+
+// ApplyBalanceSync posts adjustments for approved differences
+// @Summary Apply exchange balance sync
+// @Description Posts an adjustment transaction for each approved difference of a preview, so the sub-account balance becomes its exchange balance (must belong to authenticated user).
+// @Description The exchange is read again: if a ledger or exchange balance no longer matches its approval, nothing is posted and 409 is returned.
+// @Tags Tradings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Trading ID"
+// @Param request body services.ApplyBalanceSyncRequest true "Approved adjustments"
+// @Success 200 {object} services.BalanceSyncReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Router /tradings/{id}/balance-sync [post]
+func (h *BalanceSyncHandler) ApplyBalanceSync(c *gin.Context) {
+	userID, tradingID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	var req services.ApplyBalanceSyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_REQUEST",
+			"Invalid request format",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	report, err := h.balanceSyncService.ApplySync(c.Request.Context(), userID, tradingID, &req)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreateSuccessResponse(report, getTraceID(c)))
+}
+
+// writeError writes the response of a balance sync error
+func (h *BalanceSyncHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "trading not found":
+		c.JSON(http.StatusNotFound, CreateErrorResponse(
+			"TRADING_NOT_FOUND",
+			"Trading not found",
+			err.Error(),
+			getTraceID(c),
+		))
+	case err.Error() == "balance sync requires a real trading",
+		err.Error() == "exchange binding has no exchange account",
+		strings.HasPrefix(err.Error(), "exchange binding is shared by"),
+		strings.HasSuffix(err.Error(), "is not supported"):
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"BALANCE_SYNC_UNAVAILABLE",
+			"Trading has no exchange account to sync with",
+			err.Error(),
+			getTraceID(c),
+		))
+	case err.Error() == "no adjustments approved",
+		strings.HasSuffix(err.Error(), "is approved twice"),
+		strings.HasSuffix(err.Error(), "has no difference to adjust"):
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_APPROVAL",
+			"Invalid approval",
+			err.Error(),
+			getTraceID(c),
+		))
+	case strings.HasSuffix(err.Error(), "changed since the preview"),
+		strings.HasSuffix(err.Error(), "is below its locked balance"):
+		c.JSON(http.StatusConflict, CreateErrorResponse(
+			"BALANCE_CHANGED",
+			"Balances changed since the preview",
+			err.Error(),
+			getTraceID(c),
+		))
+	case strings.HasPrefix(err.Error(), "failed to fetch exchange balances"):
+		c.JSON(http.StatusBadGateway, CreateErrorResponse(
+			"EXCHANGE_UNAVAILABLE",
+			"Failed to read the exchange account",
+			err.Error(),
+			getTraceID(c),
+		))
+	default:
+		c.JSON(http.StatusInternalServerError, CreateErrorResponse(
+			"BALANCE_SYNC_FAILED",
+			"Failed to sync balances",
+			err.Error(),
+			getTraceID(c),
+		))
+	}
+}
+
+// parseRequest returns the authenticated user and the trading of the path, or writes the error response
+func (h *BalanceSyncHandler) parseRequest(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, CreateErrorResponse(
+			"AUTH_REQUIRED",
+			"Authentication required",
+			"",
+			getTraceID(c),
+		))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	tradingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_TRADING_ID",
+			"Invalid trading ID format",
+			err.Error(),
+			getTraceID(c),
+		))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, tradingID, true
+}
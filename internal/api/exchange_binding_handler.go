@@ -43,6 +43,8 @@ func NewExchangeBindingHandler(exchangeBindingService services.ExchangeBindingSe
 // CreateExchangeBinding creates a new exchange binding
 // @Summary Create new exchange binding
 // @Description Creates a new exchange binding for the authenticated user
+// @Description API key and secret are stored encrypted and are never returned; an API key or secret already bound by the user is rejected with 409.
+// @Description Private bindings to real exchanges are verified with the exchange: a binding whose credentials fail the check is created with status "error" and the reason in info.verification.
 // @Tags Exchange Bindings
 // @Accept json
 // @Produce json
@@ -266,6 +268,7 @@ func (h *ExchangeBindingHandler) GetExchangeBinding(c *gin.Context) {
 // UpdateExchangeBinding updates an existing exchange binding
 // @Summary Update exchange binding
 // @Description Updates an existing exchange binding (must be owned by user)
+// @Description A new API key or secret is stored encrypted. New credentials and a change of the status to "active" are verified with the exchange; the result is stored in info.verification and a failed check sets status "error".
 // @Tags Exchange Bindings
 // @Accept json
 // @Produce json
@@ -2,10 +2,12 @@ package api
 
 import (
 	"context"
+	"time"
 
 	"tiris-backend/internal/services"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // TradingServiceInterface defines the interface for trading service operations
@@ -17,6 +19,10 @@ type TradingServiceInterface interface {
 	DeleteTrading(ctx context.Context, userID, tradingID uuid.UUID) error
 	ListTradings(ctx context.Context, limit, offset int) ([]*services.TradingResponse, int64, error)
 	GetTradingByID(ctx context.Context, tradingID uuid.UUID) (*services.TradingResponse, error)
+	GetTradingBalanceAt(ctx context.Context, userID, tradingID uuid.UUID, at time.Time) (*services.TradingBalanceAtResponse, error)
+	GetEquityCurve(ctx context.Context, userID, tradingID uuid.UUID, req *services.EquityCurveRequest) (*services.EquityCurveResponse, error)
+	GetTradingPerformance(ctx context.Context, userID, tradingID uuid.UUID, req *services.PerformanceRequest) (*services.TradingPerformanceResponse, error)
+	GetTradingValuation(ctx context.Context, userID, tradingID uuid.UUID, quoteSymbol string, prices map[string]decimal.Decimal) (*services.TradingValuationResponse, error)
 }
 
 // SubAccountServiceInterface defines the interface for sub-account service operations
@@ -26,6 +32,9 @@ type SubAccountServiceInterface interface {
 	GetSubAccount(ctx context.Context, userID, subAccountID uuid.UUID) (*services.SubAccountResponse, error)
 	UpdateSubAccount(ctx context.Context, userID, subAccountID uuid.UUID, req *services.UpdateSubAccountRequest) (*services.SubAccountResponse, error)
 	UpdateBalance(ctx context.Context, userID, subAccountID uuid.UUID, req *services.UpdateBalanceRequest) (*services.SubAccountResponse, error)
+	GetBalanceAt(ctx context.Context, userID, subAccountID uuid.UUID, at time.Time) (*services.SubAccountBalanceAtResponse, error)
+	LockBalance(ctx context.Context, userID, subAccountID uuid.UUID, req *services.LockBalanceRequest) (*services.SubAccountResponse, error)
+	UnlockBalance(ctx context.Context, userID, subAccountID uuid.UUID, req *services.LockBalanceRequest) (*services.SubAccountResponse, error)
 	DeleteSubAccount(ctx context.Context, userID, subAccountID uuid.UUID) error
 	GetSubAccountsBySymbol(ctx context.Context, userID uuid.UUID, symbol string) ([]*services.SubAccountResponse, error)
 }
\ No newline at end of file
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"net/http"
+
+	"tiris-backend/internal/middleware"
+	"tiris-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// OrderHandler handles order query endpoints
+type OrderHandler struct {
+	orderService *services.OrderService
+}
+
+// NewOrderHandler creates a new order handler
+func NewOrderHandler(orderService *services.OrderService) *OrderHandler {
+	return &OrderHandler{
+		orderService: orderService,
+	}
+}
+
+// GetUserOrders retrieves all orders of the current user
+// @Summary Get user orders
+// @Description Retrieves the exchange orders of the authenticated user with filtering and pagination, newest first.
+// @Description Orders are created and updated by order events: fills are aggregated into the filled amount and the average price.
+// @Description Status moves from open to partially_filled, filled, cancelled or failed; a partially filled order can still be filled or cancelled.
+// @Tags Orders
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Filter by status" Enums(open, partially_filled, filled, cancelled, failed)
+// @Param side query string false "Filter by side" Enums(buy, sell)
+// @Param symbol query string false "Filter by symbol"
+// @Param start_date query string false "Start date (RFC3339 format)"
+// @Param end_date query string false "End date (RFC3339 format)"
+// @Param limit query int false "Number of orders to return" default(100)
+// @Param offset query int false "Number of orders to skip" default(0)
+// @Success 200 {object} services.OrderQueryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /orders [get]
+func (h *OrderHandler) GetUserOrders(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, CreateErrorResponse(
+			"AUTH_REQUIRED",
+			"Authentication required",
+			"",
+			getTraceID(c),
+		))
+		return
+	}
+
+	var req services.OrderQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_QUERY_PARAMS",
+			"Invalid query parameters",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	orders, err := h.orderService.GetUserOrders(c.Request.Context(), userID, &req)
+	if err != nil {
+		if err.Error() == "start date cannot be after end date" {
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INVALID_FILTER_RANGE",
+				"Invalid filter range",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, CreateErrorResponse(
+			"ORDERS_QUERY_FAILED",
+			"Failed to query orders",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, CreateSuccessResponse(orders, getTraceID(c)))
+}
+
+// GetTradingOrders retrieves the orders of a specific trading
+// @Summary Get trading orders
+// @Description Retrieves the exchange orders of a specific trading with filtering and pagination, newest first (must belong to authenticated user)
+// @Tags Orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Trading ID"
+// @Param status query string false "Filter by status" Enums(open, partially_filled, filled, cancelled, failed)
+// @Param side query string false "Filter by side" Enums(buy, sell)
+// @Param symbol query string false "Filter by symbol"
+// @Param start_date query string false "Start date (RFC3339 format)"
+// @Param end_date query string false "End date (RFC3339 format)"
+// @Param limit query int false "Number of orders to return" default(100)
+// @Param offset query int false "Number of orders to skip" default(0)
+// @Success 200 {object} services.OrderQueryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tradings/{id}/orders [get]
+func (h *OrderHandler) GetTradingOrders(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, CreateErrorResponse(
+			"AUTH_REQUIRED",
+			"Authentication required",
+			"",
+			getTraceID(c),
+		))
+		return
+	}
+
+	tradingIDStr := c.Param("id")
+	tradingID, err := uuid.Parse(tradingIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_TRADING_ID",
+			"Invalid trading ID format",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	var req services.OrderQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_QUERY_PARAMS",
+			"Invalid query parameters",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	orders, err := h.orderService.GetTradingOrders(c.Request.Context(), userID, tradingID, &req)
+	if err != nil {
+		if err.Error() == "trading not found" {
+			c.JSON(http.StatusNotFound, CreateErrorResponse(
+				"TRADING_NOT_FOUND",
+				"Trading not found",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+		if err.Error() == "start date cannot be after end date" {
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INVALID_FILTER_RANGE",
+				"Invalid filter range",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, CreateErrorResponse(
+			"ORDERS_QUERY_FAILED",
+			"Failed to query trading orders",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, CreateSuccessResponse(orders, getTraceID(c)))
+}
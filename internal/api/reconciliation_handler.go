@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+
+	"tiris-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReconciliationHandler handles ledger reconciliation endpoints
+type ReconciliationHandler struct {
+	reconciliationService *services.ReconciliationService
+}
+
+// NewReconciliationHandler creates a new reconciliation handler
+func NewReconciliationHandler(reconciliationService *services.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		reconciliationService: reconciliationService,
+	}
+}
+
+// Reconcile runs a ledger reconciliation (admin only)
+// @Summary Reconcile ledger
+// @Description Recomputes sub-account balances from their transactions and reports every sub-account whose balance differs from the sum of its transactions or whose closing balances do not chain. With adjust, an adjustment transaction is posted for each drift (admin only)
+// @Tags Transactions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.ReconciliationRequest false "Reconciliation scope and options"
+// @Success 200 {object} services.ReconciliationReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/reconciliation [post]
+func (h *ReconciliationHandler) Reconcile(c *gin.Context) {
+	var req services.ReconciliationRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INVALID_REQUEST",
+				"Invalid request format",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+	}
+
+	report, err := h.reconciliationService.Reconcile(c.Request.Context(), &req)
+	if err != nil {
+		if err.Error() == "sub-account not found" {
+			c.JSON(http.StatusNotFound, CreateErrorResponse(
+				"SUBACCOUNT_NOT_FOUND",
+				"Sub-account not found",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, CreateErrorResponse(
+			"RECONCILIATION_FAILED",
+			"Failed to reconcile ledger",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, CreateSuccessResponse(report, getTraceID(c)))
+}
@@ -1,19 +1,24 @@
 package api
 
 import (
+	"fmt"
 	"time"
 
 	_ "tiris-backend/docs"
 	"tiris-backend/internal/config"
 	"tiris-backend/internal/database"
+	"tiris-backend/internal/exchange"
 	"tiris-backend/internal/metrics"
 	"tiris-backend/internal/middleware"
+	"tiris-backend/internal/models"
 	"tiris-backend/internal/nats"
 	"tiris-backend/internal/repositories"
 	"tiris-backend/internal/services"
 	"tiris-backend/pkg/auth"
+	"tiris-backend/pkg/security"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -34,6 +39,10 @@ type Server struct {
 	subAccountService    *services.SubAccountService
 	transactionService   *services.TransactionService
 	tradingLogService    *services.TradingLogService
+	orderService         *services.OrderService
+	virtualExchangeService *services.VirtualExchangeService
+	reconciliationService *services.ReconciliationService
+	balanceSyncService   *services.BalanceSyncService
 	metrics              *metrics.Metrics
 }
 
@@ -62,17 +71,31 @@ func NewServer(cfg *config.Config, repos *repositories.Repositories, db *databas
 	}
 	oauthManager := auth.NewOAuthManager(oauthConfig)
 
+	// Initialize the encryption of secrets at rest; the keys are validated on load
+	keyring, err := security.NewKeyring(cfg.Security.MasterKeys, cfg.Security.ActiveMasterKeyID)
+	if err != nil {
+		panic(fmt.Sprintf("failed to initialize encryption keyring: %v", err))
+	}
+	credentialManager := models.NewExchangeBindingManager(keyring, cfg.Security.SigningKey)
+
+	// Initialize the exchange connectors that verify exchange bindings
+	connectors := newExchangeConnectors(cfg.Exchange)
+
 	// Initialize metrics
 	metricsInstance := metrics.NewMetrics()
 
 	// Initialize services
-	authService := services.NewAuthService(repos, jwtManager, oauthManager)
+	authService := services.NewAuthService(repos, jwtManager, oauthManager, keyring)
 	userService := services.NewUserService(repos)
-	exchangeBindingService := services.NewExchangeBindingService(repos.ExchangeBinding)
+	exchangeBindingService := services.NewExchangeBindingService(repos.ExchangeBinding, credentialManager, connectors)
 	tradingService := services.NewTradingService(repos, exchangeBindingService)
 	subAccountService := services.NewSubAccountService(repos)
 	transactionService := services.NewTransactionService(repos)
 	tradingLogService := services.NewTradingLogService(repos, db.DB)
+	orderService := services.NewOrderService(repos)
+	virtualExchangeService := services.NewVirtualExchangeService(repos, db.DB)
+	reconciliationService := services.NewReconciliationService(repos, db.DB)
+	balanceSyncService := services.NewBalanceSyncService(repos, db.DB, exchangeBindingService)
 
 	return &Server{
 		config:               cfg,
@@ -87,12 +110,28 @@ func NewServer(cfg *config.Config, repos *repositories.Repositories, db *databas
 		subAccountService:    subAccountService,
 		transactionService:   transactionService,
 		tradingLogService:    tradingLogService,
+		orderService:         orderService,
+		virtualExchangeService: virtualExchangeService,
+		reconciliationService: reconciliationService,
+		balanceSyncService:   balanceSyncService,
 		metrics:              metricsInstance,
 	}
 }
 
+// newExchangeConnectors creates the exchange connectors of the configured mode
+func newExchangeConnectors(cfg config.ExchangeConfig) *exchange.Registry {
+	if cfg.Connectors == config.ExchangeConnectorsFake {
+		return exchange.NewFakeRegistry(exchange.NewFakeConnector())
+	}
+	return exchange.NewLiveRegistry(time.Duration(cfg.Timeout) * time.Second)
+}
+
 // SetupRoutes sets up all API routes
 func (s *Server) SetupRoutes() *gin.Engine {
+	// Decode JSON numbers in free-form request maps (e.g. trading log info) as json.Number
+	// so monetary values keep their exact decimal representation
+	binding.EnableDecoderUseNumber = true
+
 	router := gin.New()
 
 	// Determine CORS origins based on environment
@@ -149,6 +188,18 @@ func (s *Server) SetupRoutes() *gin.Engine {
 	// Trading log management routes
 	s.setupTradingLogRoutes(protected)
 
+	// Order query routes
+	s.setupOrderRoutes(protected)
+
+	// Virtual exchange routes
+	s.setupVirtualExchangeRoutes(protected)
+
+	// Exchange balance sync routes
+	s.setupBalanceSyncRoutes(protected)
+
+	// Ledger reconciliation routes
+	s.setupReconciliationRoutes(protected)
+
 	s.router = router
 	return router
 }
@@ -228,6 +279,10 @@ func (s *Server) setupTradingRoutes(protected *gin.RouterGroup) {
 	tradings.POST("", tradingHandler.CreateTrading)
 	tradings.GET("", tradingHandler.GetUserTradings)
 	tradings.GET("/:id", tradingHandler.GetTrading)
+	tradings.GET("/:id/balance", tradingHandler.GetTradingBalanceAt)
+	tradings.GET("/:id/valuation", tradingHandler.GetTradingValuation)
+	tradings.GET("/:id/equity", tradingHandler.GetEquityCurve)
+	tradings.GET("/:id/performance", tradingHandler.GetTradingPerformance)
 	tradings.PUT("/:id", tradingHandler.UpdateTrading)
 	tradings.DELETE("/:id", tradingHandler.DeleteTrading)
 
@@ -250,7 +305,10 @@ func (s *Server) setupSubAccountRoutes(protected *gin.RouterGroup) {
 	subAccounts.GET("", subAccountHandler.GetUserSubAccounts)
 	subAccounts.GET("/:id", subAccountHandler.GetSubAccount)
 	subAccounts.PUT("/:id", subAccountHandler.UpdateSubAccount)
+	subAccounts.GET("/:id/balance", subAccountHandler.GetBalanceAt)
 	subAccounts.PUT("/:id/balance", subAccountHandler.UpdateBalance)
+	subAccounts.POST("/:id/lock", subAccountHandler.LockBalance)
+	subAccounts.POST("/:id/unlock", subAccountHandler.UnlockBalance)
 	subAccounts.DELETE("/:id", subAccountHandler.DeleteSubAccount)
 
 	// Symbol-based queries
@@ -288,6 +346,8 @@ func (s *Server) setupTradingLogRoutes(protected *gin.RouterGroup) {
 	tradingLogsCreation := tradingLogs.Group("")
 	tradingLogsCreation.Use(middleware.TradingRateLimitMiddleware())
 	tradingLogsCreation.POST("", tradingLogHandler.CreateTradingLog)
+	tradingLogsCreation.POST("/batch", tradingLogHandler.BatchCreateTradingLogs)
+	tradingLogsCreation.POST("/:id/amend", tradingLogHandler.AmendTradingLog)
 	tradingLogs.GET("", tradingLogHandler.GetUserTradingLogs)
 	tradingLogs.GET("/:id", tradingLogHandler.GetTradingLog)
 	tradingLogs.DELETE("/:id", tradingLogHandler.DeleteTradingLog)
@@ -303,6 +363,57 @@ func (s *Server) setupTradingLogRoutes(protected *gin.RouterGroup) {
 	adminTradingLogs.GET("/:id", tradingLogHandler.GetTradingLogByID)
 }
 
+// setupOrderRoutes sets up order query routes
+func (s *Server) setupOrderRoutes(protected *gin.RouterGroup) {
+	orderHandler := NewOrderHandler(s.orderService)
+
+	orders := protected.Group("/orders")
+
+	// User order routes
+	orders.GET("", orderHandler.GetUserOrders)
+
+	// Orders of a trading
+	protected.GET("/tradings/:id/orders", orderHandler.GetTradingOrders)
+}
+
+// setupVirtualExchangeRoutes sets up the paper trading routes of the virtual exchange
+func (s *Server) setupVirtualExchangeRoutes(protected *gin.RouterGroup) {
+	virtualExchangeHandler := NewVirtualExchangeHandler(s.virtualExchangeService)
+
+	virtual := protected.Group("/tradings/:id/virtual")
+
+	// Order placement is rate limited like trading log creation
+	virtualOrders := virtual.Group("/orders")
+	virtualOrders.Use(middleware.TradingRateLimitMiddleware())
+	virtualOrders.POST("", virtualExchangeHandler.PlaceOrder)
+	virtualOrders.DELETE("/:order_id", virtualExchangeHandler.CancelOrder)
+
+	// Price ticks of backtests come in bulk; high-rate feeds should use NATS
+	virtual.POST("/prices", virtualExchangeHandler.PushPrice)
+}
+
+// setupBalanceSyncRoutes sets up the routes that sync sub-account balances with the exchange
+func (s *Server) setupBalanceSyncRoutes(protected *gin.RouterGroup) {
+	balanceSyncHandler := NewBalanceSyncHandler(s.balanceSyncService)
+
+	// Both routes call the exchange API
+	balanceSync := protected.Group("/tradings/:id/balance-sync")
+	balanceSync.Use(middleware.TradingRateLimitMiddleware())
+	balanceSync.GET("", balanceSyncHandler.PreviewBalanceSync)
+	balanceSync.POST("", balanceSyncHandler.ApplyBalanceSync)
+}
+
+// setupReconciliationRoutes sets up ledger reconciliation routes
+func (s *Server) setupReconciliationRoutes(protected *gin.RouterGroup) {
+	reconciliationHandler := NewReconciliationHandler(s.reconciliationService)
+
+	// Admin reconciliation routes
+	adminReconciliation := protected.Group("/admin/reconciliation")
+	adminReconciliation.Use(middleware.AdminMiddleware())
+
+	adminReconciliation.POST("", reconciliationHandler.Reconcile)
+}
+
 // setupMetricsRoutes sets up Prometheus metrics endpoints
 func (s *Server) setupMetricsRoutes(router *gin.Engine) {
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
@@ -336,6 +447,11 @@ func (s *Server) GetMetrics() *metrics.Metrics {
 	return s.metrics
 }
 
+// GetBalanceSyncService returns the balance sync service instance
+func (s *Server) GetBalanceSyncService() *services.BalanceSyncService {
+	return s.balanceSyncService
+}
+
 // GetJWTManager returns the JWT manager instance
 func (s *Server) GetJWTManager() *auth.JWTManager {
 	return s.jwtManager
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	"tiris-backend/internal/middleware"
 	"tiris-backend/internal/services"
@@ -234,6 +236,7 @@ func (h *SubAccountHandler) GetSubAccount(c *gin.Context) {
 // @Success 200 {object} services.SubAccountResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Failure 409 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -275,6 +278,24 @@ func (h *SubAccountHandler) UpdateSubAccount(c *gin.Context) {
 
 	subAccount, err := h.subAccountService.UpdateSubAccount(c.Request.Context(), userID, subAccountID, &req)
 	if err != nil {
+		if err.Error() == "balance must not be negative" || err.Error() == "balance must not be below locked balance" {
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INVALID_REQUEST",
+				"Invalid request format",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+		if err.Error() == "manual balance updates are not allowed for real tradings" {
+			c.JSON(http.StatusForbidden, CreateErrorResponse(
+				"MANUAL_BALANCE_UPDATE_FORBIDDEN",
+				"Manual balance updates are not allowed for real tradings",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
 		if err.Error() == "sub-account not found" {
 			c.JSON(http.StatusNotFound, CreateErrorResponse(
 				"SUBACCOUNT_NOT_FOUND",
@@ -306,9 +327,88 @@ func (h *SubAccountHandler) UpdateSubAccount(c *gin.Context) {
 	c.JSON(http.StatusOK, CreateSuccessResponse(subAccount, getTraceID(c)))
 }
 
+// GetBalanceAt retrieves the balance of a sub-account at a point in time
+// @Summary Get sub-account balance at a point in time
+// @Description Resolves the balance of a sub-account from the closing balance of its latest transaction at or before the given time (must belong to authenticated user)
+// @Tags SubAccounts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Sub-account ID"
+// @Param at query string false "Point in time (RFC3339 format), defaults to now"
+// @Success 200 {object} services.SubAccountBalanceAtResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sub-accounts/{id}/balance [get]
+func (h *SubAccountHandler) GetBalanceAt(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, CreateErrorResponse(
+			"AUTH_REQUIRED",
+			"Authentication required",
+			"",
+			getTraceID(c),
+		))
+		return
+	}
+
+	subAccountIDStr := c.Param("id")
+	subAccountID, err := uuid.Parse(subAccountIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_SUBACCOUNT_ID",
+			"Invalid sub-account ID format",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	var req services.BalanceAtRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_QUERY_PARAMS",
+			"Invalid query parameters",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	at := time.Now()
+	if req.At != nil {
+		at = *req.At
+	}
+
+	balance, err := h.subAccountService.GetBalanceAt(c.Request.Context(), userID, subAccountID, at)
+	if err != nil {
+		if err.Error() == "sub-account not found" {
+			c.JSON(http.StatusNotFound, CreateErrorResponse(
+				"SUBACCOUNT_NOT_FOUND",
+				"Sub-account not found",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, CreateErrorResponse(
+			"BALANCE_GET_FAILED",
+			"Failed to get balance",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, CreateSuccessResponse(balance, getTraceID(c)))
+}
+
 // UpdateBalance updates sub-account balance
 // @Summary Update sub-account balance
-// @Description Updates sub-account balance with proper logging (must belong to authenticated user)
+// @Description Updates sub-account balance with proper logging (must belong to authenticated user).
+// @Description Only sub-accounts of simulation and backtest tradings can be updated manually; the balances of a real trading change through trading logs
 // @Tags SubAccounts
 // @Accept json
 // @Produce json
@@ -318,6 +418,7 @@ func (h *SubAccountHandler) UpdateSubAccount(c *gin.Context) {
 // @Success 200 {object} services.SubAccountResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /sub-accounts/{id}/balance [put]
@@ -358,6 +459,15 @@ func (h *SubAccountHandler) UpdateBalance(c *gin.Context) {
 
 	subAccount, err := h.subAccountService.UpdateBalance(c.Request.Context(), userID, subAccountID, &req)
 	if err != nil {
+		if err.Error() == "amount must be positive" {
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INVALID_REQUEST",
+				"Invalid request format",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
 		if err.Error() == "sub-account not found" {
 			c.JSON(http.StatusNotFound, CreateErrorResponse(
 				"SUBACCOUNT_NOT_FOUND",
@@ -367,6 +477,15 @@ func (h *SubAccountHandler) UpdateBalance(c *gin.Context) {
 			))
 			return
 		}
+		if err.Error() == "manual balance updates are not allowed for real tradings" {
+			c.JSON(http.StatusForbidden, CreateErrorResponse(
+				"MANUAL_BALANCE_UPDATE_FORBIDDEN",
+				"Manual balance updates are not allowed for real tradings",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
 		if err.Error() == "insufficient balance" {
 			c.JSON(http.StatusBadRequest, CreateErrorResponse(
 				"INSUFFICIENT_BALANCE",
@@ -389,6 +508,126 @@ func (h *SubAccountHandler) UpdateBalance(c *gin.Context) {
 	c.JSON(http.StatusOK, CreateSuccessResponse(subAccount, getTraceID(c)))
 }
 
+// LockBalance locks part of a sub-account balance
+// @Summary Lock sub-account balance
+// @Description Moves an amount of the available balance to the locked balance, e.g. for an open order. Locked funds cannot be spent until unlocked (must belong to authenticated user)
+// @Tags SubAccounts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Sub-account ID"
+// @Param request body services.LockBalanceRequest true "Lock balance request"
+// @Success 200 {object} services.SubAccountResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sub-accounts/{id}/lock [post]
+func (h *SubAccountHandler) LockBalance(c *gin.Context) {
+	h.adjustLockedBalance(c, h.subAccountService.LockBalance)
+}
+
+// UnlockBalance releases part of a sub-account locked balance
+// @Summary Unlock sub-account balance
+// @Description Moves an amount of the locked balance back to the available balance (must belong to authenticated user)
+// @Tags SubAccounts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Sub-account ID"
+// @Param request body services.LockBalanceRequest true "Unlock balance request"
+// @Success 200 {object} services.SubAccountResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sub-accounts/{id}/unlock [post]
+func (h *SubAccountHandler) UnlockBalance(c *gin.Context) {
+	h.adjustLockedBalance(c, h.subAccountService.UnlockBalance)
+}
+
+// adjustLockedBalance handles lock and unlock requests
+func (h *SubAccountHandler) adjustLockedBalance(c *gin.Context,
+	adjust func(ctx context.Context, userID, subAccountID uuid.UUID, req *services.LockBalanceRequest) (*services.SubAccountResponse, error)) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, CreateErrorResponse(
+			"AUTH_REQUIRED",
+			"Authentication required",
+			"",
+			getTraceID(c),
+		))
+		return
+	}
+
+	subAccountIDStr := c.Param("id")
+	subAccountID, err := uuid.Parse(subAccountIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_SUBACCOUNT_ID",
+			"Invalid sub-account ID format",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	var req services.LockBalanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_REQUEST",
+			"Invalid request format",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	subAccount, err := adjust(c.Request.Context(), userID, subAccountID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "amount must be positive":
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INVALID_REQUEST",
+				"Invalid request format",
+				err.Error(),
+				getTraceID(c),
+			))
+		case "sub-account not found":
+			c.JSON(http.StatusNotFound, CreateErrorResponse(
+				"SUBACCOUNT_NOT_FOUND",
+				"Sub-account not found",
+				err.Error(),
+				getTraceID(c),
+			))
+		case "insufficient available balance":
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INSUFFICIENT_BALANCE",
+				"Insufficient available balance",
+				err.Error(),
+				getTraceID(c),
+			))
+		case "insufficient locked balance":
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INSUFFICIENT_LOCKED_BALANCE",
+				"Insufficient locked balance",
+				err.Error(),
+				getTraceID(c),
+			))
+		default:
+			c.JSON(http.StatusInternalServerError, CreateErrorResponse(
+				"BALANCE_UPDATE_FAILED",
+				"Failed to update locked balance",
+				err.Error(),
+				getTraceID(c),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, CreateSuccessResponse(subAccount, getTraceID(c)))
+}
+
 // DeleteSubAccount deletes a sub-account
 // @Summary Delete sub-account
 // @Description Deletes a sub-account configuration (must belong to authenticated user)
@@ -3,12 +3,15 @@ package api
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"tiris-backend/internal/middleware"
 	"tiris-backend/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // TradingHandler handles trading management endpoints
@@ -25,7 +28,12 @@ func NewTradingHandler(tradingService TradingServiceInterface) *TradingHandler {
 
 // CreateTrading creates a new trading configuration
 // @Summary Create new trading
-// @Description Creates a new trading configuration for the authenticated user
+// @Description Creates a new trading configuration for the authenticated user.
+// @Description In margin mode sub-accounts can borrow with borrow/repay trading logs and sell short down to minus the borrowed amount;
+// @Description trades that would bring the margin level (value of positive balances / value of negative balances) below the collateral ratio are rejected.
+// @Description The type is real, simulation or backtest. Simulation and backtest tradings must use a virtual exchange binding (the virtual exchange or a public binding),
+// @Description accept trading logs with any event time and are left out of the total balance of the user; real tradings reject event times in the future and manual balance updates.
+// @Description Simulation and backtest tradings can set the fee and slippage models of the virtual exchange with virtual_exchange.
 // @Tags Tradings
 // @Accept json
 // @Produce json
@@ -62,6 +70,34 @@ func (h *TradingHandler) CreateTrading(c *gin.Context) {
 
 	trading, err := h.tradingService.CreateTrading(c.Request.Context(), userID, &req)
 	if err != nil {
+		if err.Error() == "simulation and backtest tradings must use a virtual exchange binding" {
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"VIRTUAL_BINDING_REQUIRED",
+				"Simulation and backtest tradings must use a virtual exchange binding",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+		if err.Error() == "virtual exchange settings require a simulation or backtest trading" ||
+			strings.HasPrefix(err.Error(), "invalid virtual exchange configuration") {
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INVALID_VIRTUAL_EXCHANGE",
+				"Invalid virtual exchange settings",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+		if err.Error() == "collateral ratio must be at least 1" {
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INVALID_COLLATERAL_RATIO",
+				"Invalid collateral ratio",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
 		if err.Error() == "trading name already exists" {
 			c.JSON(http.StatusConflict, CreateErrorResponse(
 				"TRADING_NAME_EXISTS",
@@ -203,9 +239,361 @@ func (h *TradingHandler) GetTrading(c *gin.Context) {
 	c.JSON(http.StatusOK, CreateSuccessResponse(trading, getTraceID(c)))
 }
 
+// GetTradingBalanceAt retrieves the balances of a trading at a point in time
+// @Summary Get trading balances at a point in time
+// @Description Resolves the balance of every sub-account of a trading from the closing balance of its latest transaction at or before the given time. Transactions created by a trading log with an event time are placed at that event time (must belong to authenticated user)
+// @Tags Tradings
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Trading ID"
+// @Param at query string false "Point in time (RFC3339 format), defaults to now"
+// @Success 200 {object} services.TradingBalanceAtResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tradings/{id}/balance [get]
+func (h *TradingHandler) GetTradingBalanceAt(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, CreateErrorResponse(
+			"AUTH_REQUIRED",
+			"Authentication required",
+			"",
+			getTraceID(c),
+		))
+		return
+	}
+
+	tradingIDStr := c.Param("id")
+	tradingID, err := uuid.Parse(tradingIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_TRADING_ID",
+			"Invalid trading ID format",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	var req services.BalanceAtRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_QUERY_PARAMS",
+			"Invalid query parameters",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	at := time.Now()
+	if req.At != nil {
+		at = *req.At
+	}
+
+	balance, err := h.tradingService.GetTradingBalanceAt(c.Request.Context(), userID, tradingID, at)
+	if err != nil {
+		if err.Error() == "trading not found" {
+			c.JSON(http.StatusNotFound, CreateErrorResponse(
+				"TRADING_NOT_FOUND",
+				"Trading not found",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, CreateErrorResponse(
+			"BALANCE_GET_FAILED",
+			"Failed to get trading balance",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, CreateSuccessResponse(balance, getTraceID(c)))
+}
+
+// GetTradingValuation values a trading in a quote currency
+// @Summary Get trading valuation
+// @Description Values every sub-account of a trading in the quote currency, using the supplied prices or else the most recent trade price of each symbol against the quote currency. Returns the per-asset breakdown, the total equity and the time of the prices used (must belong to authenticated user)
+// @Tags Tradings
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Trading ID"
+// @Param quote query string true "Quote currency, e.g. USDT"
+// @Param prices query object false "Prices in the quote currency by symbol, e.g. prices[ETH]=3000"
+// @Success 200 {object} services.TradingValuationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tradings/{id}/valuation [get]
+func (h *TradingHandler) GetTradingValuation(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, CreateErrorResponse(
+			"AUTH_REQUIRED",
+			"Authentication required",
+			"",
+			getTraceID(c),
+		))
+		return
+	}
+
+	tradingIDStr := c.Param("id")
+	tradingID, err := uuid.Parse(tradingIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_TRADING_ID",
+			"Invalid trading ID format",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	prices := make(map[string]decimal.Decimal)
+	for symbol, value := range c.QueryMap("prices") {
+		price, err := decimal.NewFromString(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INVALID_QUERY_PARAMS",
+				"Invalid query parameters",
+				"invalid price of "+symbol+": "+err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+		prices[symbol] = price
+	}
+
+	valuation, err := h.tradingService.GetTradingValuation(c.Request.Context(), userID, tradingID, c.Query("quote"), prices)
+	if err != nil {
+		if err.Error() == "trading not found" {
+			c.JSON(http.StatusNotFound, CreateErrorResponse(
+				"TRADING_NOT_FOUND",
+				"Trading not found",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+
+		if err.Error() == "quote currency is required" || strings.HasSuffix(err.Error(), "must be positive") {
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INVALID_QUERY_PARAMS",
+				"Invalid query parameters",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, CreateErrorResponse(
+			"VALUATION_FAILED",
+			"Failed to value trading",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, CreateSuccessResponse(valuation, getTraceID(c)))
+}
+
+// GetEquityCurve retrieves the equity curve of a trading
+// @Summary Get trading equity curve
+// @Description Returns the balance of every sub-account of a trading at the end of each time bucket, carrying balances forward across buckets without transactions. With a quote currency, the equity of the trading is valued at the last trade price of each symbol (must belong to authenticated user)
+// @Tags Tradings
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Trading ID"
+// @Param interval query string false "Bucket width: 1h, 1d or 1w" default(1d)
+// @Param from query string false "Start time (RFC3339 format), defaults to 30 intervals before to"
+// @Param to query string false "End time (RFC3339 format), defaults to now"
+// @Param sub_account_id query string false "Restrict the curve to one sub-account"
+// @Param quote query string false "Quote currency to value the equity of the trading in, e.g. USDT"
+// @Success 200 {object} services.EquityCurveResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tradings/{id}/equity [get]
+func (h *TradingHandler) GetEquityCurve(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, CreateErrorResponse(
+			"AUTH_REQUIRED",
+			"Authentication required",
+			"",
+			getTraceID(c),
+		))
+		return
+	}
+
+	tradingIDStr := c.Param("id")
+	tradingID, err := uuid.Parse(tradingIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_TRADING_ID",
+			"Invalid trading ID format",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	var req services.EquityCurveRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_QUERY_PARAMS",
+			"Invalid query parameters",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	curve, err := h.tradingService.GetEquityCurve(c.Request.Context(), userID, tradingID, &req)
+	if err != nil {
+		if err.Error() == "trading not found" {
+			c.JSON(http.StatusNotFound, CreateErrorResponse(
+				"TRADING_NOT_FOUND",
+				"Trading not found",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+
+		if err.Error() == "sub-account not found" {
+			c.JSON(http.StatusNotFound, CreateErrorResponse(
+				"SUBACCOUNT_NOT_FOUND",
+				"Sub-account not found",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+
+		if strings.HasPrefix(err.Error(), "invalid interval") ||
+			err.Error() == "from must be before to" ||
+			strings.HasPrefix(err.Error(), "too many points") {
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INVALID_QUERY_PARAMS",
+				"Invalid query parameters",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, CreateErrorResponse(
+			"EQUITY_GET_FAILED",
+			"Failed to get equity curve",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, CreateSuccessResponse(curve, getTraceID(c)))
+}
+
+// GetTradingPerformance retrieves the performance statistics of a trading
+// @Summary Get trading performance
+// @Description Returns strategy statistics of a trading over a date range: time-weighted total return, maximum drawdown, annualized Sharpe and Sortino ratios from the daily equity in the quote currency, and round trip statistics (win rate, average win and loss, profit factor) from the long, short and stop_loss trades. Results are cached for a few minutes (must belong to authenticated user)
+// @Tags Tradings
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Trading ID"
+// @Param quote query string true "Quote currency to value the equity in, e.g. USDT"
+// @Param from query string false "Start time (RFC3339 format), defaults to the creation of the trading"
+// @Param to query string false "End time (RFC3339 format), defaults to now"
+// @Success 200 {object} services.TradingPerformanceResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tradings/{id}/performance [get]
+func (h *TradingHandler) GetTradingPerformance(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, CreateErrorResponse(
+			"AUTH_REQUIRED",
+			"Authentication required",
+			"",
+			getTraceID(c),
+		))
+		return
+	}
+
+	tradingIDStr := c.Param("id")
+	tradingID, err := uuid.Parse(tradingIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_TRADING_ID",
+			"Invalid trading ID format",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	var req services.PerformanceRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_QUERY_PARAMS",
+			"Invalid query parameters",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	performance, err := h.tradingService.GetTradingPerformance(c.Request.Context(), userID, tradingID, &req)
+	if err != nil {
+		if err.Error() == "trading not found" {
+			c.JSON(http.StatusNotFound, CreateErrorResponse(
+				"TRADING_NOT_FOUND",
+				"Trading not found",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+
+		if err.Error() == "quote currency is required" || err.Error() == "from must be before to" {
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INVALID_QUERY_PARAMS",
+				"Invalid query parameters",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, CreateErrorResponse(
+			"PERFORMANCE_GET_FAILED",
+			"Failed to get trading performance",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, CreateSuccessResponse(performance, getTraceID(c)))
+}
+
 // UpdateTrading updates an existing trading
 // @Summary Update trading
-// @Description Updates an existing trading configuration (must belong to authenticated user)
+// @Description Updates an existing trading configuration (must belong to authenticated user).
+// @Description Margin mode cannot be disabled while a sub-account borrows or has a negative balance.
+// @Description Simulation and backtest tradings can only be moved to another virtual exchange binding, and can change the fee and slippage models of the virtual exchange.
 // @Tags Tradings
 // @Accept json
 // @Produce json
@@ -265,6 +653,43 @@ func (h *TradingHandler) UpdateTrading(c *gin.Context) {
 			))
 			return
 		}
+		if err.Error() == "virtual exchange settings require a simulation or backtest trading" ||
+			strings.HasPrefix(err.Error(), "invalid virtual exchange configuration") {
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INVALID_VIRTUAL_EXCHANGE",
+				"Invalid virtual exchange settings",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+		if err.Error() == "collateral ratio must be at least 1" {
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INVALID_COLLATERAL_RATIO",
+				"Invalid collateral ratio",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+		if err.Error() == "simulation and backtest tradings must use a virtual exchange binding" {
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"VIRTUAL_BINDING_REQUIRED",
+				"Simulation and backtest tradings must use a virtual exchange binding",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+		if err.Error() == "cannot disable margin mode with borrowed or negative balances" {
+			c.JSON(http.StatusConflict, CreateErrorResponse(
+				"MARGIN_IN_USE",
+				"Cannot disable margin mode with borrowed or negative balances",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
 		if err.Error() == "trading name already exists" {
 			c.JSON(http.StatusConflict, CreateErrorResponse(
 				"TRADING_NAME_EXISTS",
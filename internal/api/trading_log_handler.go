@@ -2,6 +2,7 @@ package api
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
 	"tiris-backend/internal/middleware"
@@ -39,12 +40,24 @@ func NewTradingLogHandler(tradingLogService *services.TradingLogService) *Tradin
 // @Description - stock (string): Asset symbol, 1-20 characters (e.g., "ETH")
 // @Description - currency (string): Currency symbol, 1-20 characters (e.g., "USDT")
 // @Description - fee (number): Trading fee (must be non-negative, up to 8 decimal places)
+// @Description - fee_account_id (string, optional): UUID of a sub-account of the trading charged with the fee as a separate 'fee' transaction (e.g., a BNB account, or the stock account for fees in the base asset)
+// @Description - fee_asset (string, optional): Symbol of the fee account, required with fee_account_id (e.g., "BNB")
 // @Description 
 // @Description **For deposit/withdraw types** - Required fields in 'info':
 // @Description - account_id (string): Target sub-account UUID for the operation
 // @Description - amount (number): Amount to deposit/withdraw (must be positive, up to 8 decimal places)
 // @Description - currency (string): Currency symbol, 1-20 characters (e.g., "USDT")
 // @Description 
+// @Description **For transfer type** - Moves funds between two sub-accounts of the trading holding the same symbol. Required fields in 'info':
+// @Description - from_account_id (string): Sub-account UUID the funds are taken from
+// @Description - to_account_id (string): Sub-account UUID the funds are moved to
+// @Description - amount (number): Amount to transfer (must be positive, up to 8 decimal places)
+// @Description - currency (string): Symbol held by both sub-accounts, 1-20 characters (e.g., "USDT")
+// @Description 
+// @Description **For borrow/repay types** - Margin mode only. Records an amount borrowed from or repaid to the exchange; no funds are moved,
+// @Description the balance of the sub-account may go down to minus its borrowed amount. Same 'info' fields as deposit/withdraw.
+// @Description In margin mode long/short/stop_loss trades are rejected when the margin level would fall below the collateral ratio of the trading.
+// @Description 
 // @Description **Request Examples**:
 // @Description 
 // @Description **Long Position Example:**
@@ -78,16 +91,25 @@ func NewTradingLogHandler(tradingLogService *services.TradingLogService) *Tradin
 // @Description }</code></pre>
 // @Description 
 // @Description **Other Types**: Can use any object structure in the 'info' field
+// @Description
+// @Description **Retries**: Send an Idempotency-Key header to make retries safe. A retry with the same key and body returns the original response
+// @Description with the Idempotent-Replayed header set and does not create another trading log. Reusing a key with a different body is rejected with 409.
+// @Description
+// @Description **Backdating**: A business logic log with an `event_time` in the past places its transactions at that time and recomputes the closing balances
+// @Description of the later transactions of the affected sub-accounts. It is rejected with 422 if a historical balance would become negative.
+// @Description Simulation and backtest tradings accept any `event_time`; for a real trading an `event_time` in the future is rejected with 400.
 // @Tags TradingLogs
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param Idempotency-Key header string false "Client-generated key (max 255 characters) identifying this request for safe retries"
 // @Param request body services.CreateTradingLogRequest true "Create trading log request"
 // @Success 201 {object} services.TradingLogResponse
 // @Failure 400 {object} ErrorResponse "Bad Request - Invalid request format, missing required fields, or incorrect 'info' structure for the specified 'type'. Common validation errors: Missing required 'info' fields for business logic types, Invalid data types or values in 'info' fields, Non-existent sub-account IDs referenced in 'info' fields"
 // @Failure 401 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse "Not Found - Trading ID or sub-account IDs referenced in 'info' field do not exist"
-// @Failure 422 {object} ErrorResponse "Unprocessable Entity - Business logic validation failed (e.g., insufficient balance for withdraw operations)"
+// @Failure 409 {object} ErrorResponse "Conflict - Idempotency key already used with a different request"
+// @Failure 422 {object} ErrorResponse "Unprocessable Entity - Business logic validation failed (e.g., insufficient balance for withdraw operations, or a backdated trade making a historical balance negative)"
 // @Failure 500 {object} ErrorResponse
 // @Router /trading-logs [post]
 func (h *TradingLogHandler) CreateTradingLog(c *gin.Context) {
@@ -113,8 +135,36 @@ func (h *TradingLogHandler) CreateTradingLog(c *gin.Context) {
 		return
 	}
 
-	tradingLog, err := h.tradingLogService.CreateTradingLog(c.Request.Context(), userID, &req)
+	var tradingLog *services.TradingLogResponse
+	var err error
+	if idempotencyKey := c.GetHeader("Idempotency-Key"); idempotencyKey != "" {
+		var replayed bool
+		tradingLog, replayed, err = h.tradingLogService.CreateTradingLogIdempotent(c.Request.Context(), userID, idempotencyKey, &req)
+		if replayed {
+			c.Header("Idempotent-Replayed", "true")
+		}
+	} else {
+		tradingLog, err = h.tradingLogService.CreateTradingLog(c.Request.Context(), userID, &req)
+	}
 	if err != nil {
+		if err.Error() == "idempotency key already used with a different request" {
+			c.JSON(http.StatusConflict, CreateErrorResponse(
+				"IDEMPOTENCY_KEY_CONFLICT",
+				"Idempotency key already used with a different request",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+		if strings.HasPrefix(err.Error(), "idempotency key must be") {
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INVALID_IDEMPOTENCY_KEY",
+				"Invalid idempotency key",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
 		if err.Error() == "trading not found" {
 			c.JSON(http.StatusNotFound, CreateErrorResponse(
 				"TRADING_NOT_FOUND",
@@ -142,6 +192,42 @@ func (h *TradingLogHandler) CreateTradingLog(c *gin.Context) {
 			))
 			return
 		}
+		if strings.Contains(err.Error(), "margin mode is not enabled") {
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"MARGIN_MODE_DISABLED",
+				"Margin mode is not enabled for this trading",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+		if strings.Contains(err.Error(), "would fall below the collateral ratio") || strings.Contains(err.Error(), "cannot repay") {
+			c.JSON(http.StatusUnprocessableEntity, CreateErrorResponse(
+				"MARGIN_LIMIT_BREACHED",
+				"Trading log would breach the margin limits of the trading",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+		if strings.Contains(err.Error(), "event time cannot be in the future") {
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INVALID_EVENT_TIME",
+				"Invalid event time",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+		if strings.Contains(err.Error(), "backdated trading log would make the balance") {
+			c.JSON(http.StatusUnprocessableEntity, CreateErrorResponse(
+				"BACKDATED_BALANCE_NEGATIVE",
+				"Backdated trading log would make a historical balance negative",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
 
 		c.JSON(http.StatusInternalServerError, CreateErrorResponse(
 			"TRADING_LOG_CREATE_FAILED",
@@ -155,6 +241,80 @@ func (h *TradingLogHandler) CreateTradingLog(c *gin.Context) {
 	c.JSON(http.StatusCreated, CreateSuccessResponse(tradingLog, getTraceID(c)))
 }
 
+// BatchCreateTradingLogs creates several trading log entries in one request
+// @Summary Create trading logs in batch
+// @Description Processes up to 500 trading log entries in the given order within a single database transaction.
+// @Description Each entry follows the same rules as POST /trading-logs.
+// @Description
+// @Description By default the batch is all-or-nothing: if any entry fails, nothing is committed and the response reports the failing entry.
+// @Description With continue_on_error set, each failed entry is rolled back on its own and reported in the results while the other entries are committed.
+// @Tags TradingLogs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.BatchCreateTradingLogsRequest true "Batch create trading logs request"
+// @Success 201 {object} services.BatchCreateTradingLogsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse "Unprocessable Entity - An entry failed and the whole batch was rolled back"
+// @Failure 500 {object} ErrorResponse
+// @Router /trading-logs/batch [post]
+func (h *TradingLogHandler) BatchCreateTradingLogs(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, CreateErrorResponse(
+			"AUTH_REQUIRED",
+			"Authentication required",
+			"",
+			getTraceID(c),
+		))
+		return
+	}
+
+	var req services.BatchCreateTradingLogsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_REQUEST",
+			"Invalid request format",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	result, err := h.tradingLogService.BatchCreateTradingLogs(c.Request.Context(), userID, &req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "batch entry ") {
+			c.JSON(http.StatusUnprocessableEntity, CreateErrorResponse(
+				"BATCH_ENTRY_FAILED",
+				"Trading log batch rolled back",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+		if strings.HasPrefix(err.Error(), "batch must contain") || strings.HasPrefix(err.Error(), "batch cannot contain") {
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INVALID_REQUEST",
+				"Invalid request format",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, CreateErrorResponse(
+			"TRADING_LOG_BATCH_FAILED",
+			"Failed to create trading logs",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateSuccessResponse(result, getTraceID(c)))
+}
+
 // GetUserTradingLogs retrieves all trading logs for the current user
 // @Summary Get user trading logs
 // @Description Retrieves trading log history for the authenticated user with filtering and pagination
@@ -556,7 +716,10 @@ func (h *TradingLogHandler) GetTradingLogsByTimeRange(c *gin.Context) {
 
 // DeleteTradingLog deletes a trading log
 // @Summary Delete trading log
-// @Description Deletes a trading log entry (must belong to authenticated user and be manual)
+// @Description Deletes a trading log entry (must belong to authenticated user and be manual).
+// @Description For business logic types (long, short, stop_loss, deposit, withdraw, transfer, borrow, repay) compensating transactions
+// @Description are posted to restore the affected sub-account balances. Deletion is refused with 409 if a later
+// @Description trading log has already spent the balance the reversal would take back, or if the log has been amended.
 // @Tags TradingLogs
 // @Produce json
 // @Security BearerAuth
@@ -603,6 +766,15 @@ func (h *TradingLogHandler) DeleteTradingLog(c *gin.Context) {
 			))
 			return
 		}
+		if err.Error() == "cannot delete an amended trading log" {
+			c.JSON(http.StatusConflict, CreateErrorResponse(
+				"CANNOT_DELETE_AMENDED_LOG",
+				"Cannot delete an amended trading log",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
 		if err.Error() == "cannot delete bot-generated trading logs" {
 			c.JSON(http.StatusConflict, CreateErrorResponse(
 				"CANNOT_DELETE_BOT_LOG",
@@ -612,6 +784,15 @@ func (h *TradingLogHandler) DeleteTradingLog(c *gin.Context) {
 			))
 			return
 		}
+		if strings.Contains(err.Error(), "insufficient balance to reverse trading log") {
+			c.JSON(http.StatusConflict, CreateErrorResponse(
+				"TRADING_LOG_REVERSAL_CONFLICT",
+				"Cannot reverse trading log because its balance has already been spent",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
 
 		c.JSON(http.StatusInternalServerError, CreateErrorResponse(
 			"TRADING_LOG_DELETE_FAILED",
@@ -629,6 +810,111 @@ func (h *TradingLogHandler) DeleteTradingLog(c *gin.Context) {
 	c.JSON(http.StatusOK, CreateSuccessResponse(response, getTraceID(c)))
 }
 
+// AmendTradingLog corrects a trading log
+// @Summary Amend trading log
+// @Description Corrects a trading log (manual or bot) without deleting it. For business logic types (long, short, stop_loss, deposit, withdraw, transfer, borrow, repay)
+// @Description the transactions of the original log are compensated with reversal transactions and the corrected info is processed as a new trading log,
+// @Description in one database transaction. The new log references the original through amends and the original the new log through amended_by.
+// @Description A trading log can be amended once; amend the correcting log to correct it again.
+// @Tags TradingLogs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Trading Log ID"
+// @Param request body services.AmendTradingLogRequest true "Corrected trading log info"
+// @Success 201 {object} services.TradingLogResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /trading-logs/{id}/amend [post]
+func (h *TradingLogHandler) AmendTradingLog(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, CreateErrorResponse(
+			"AUTH_REQUIRED",
+			"Authentication required",
+			"",
+			getTraceID(c),
+		))
+		return
+	}
+
+	tradingLogIDStr := c.Param("id")
+	tradingLogID, err := uuid.Parse(tradingLogIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_TRADING_LOG_ID",
+			"Invalid trading log ID format",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	var req services.AmendTradingLogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_REQUEST",
+			"Invalid request format",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	tradingLog, err := h.tradingLogService.AmendTradingLog(c.Request.Context(), userID, tradingLogID, &req)
+	if err != nil {
+		if err.Error() == "trading log not found" {
+			c.JSON(http.StatusNotFound, CreateErrorResponse(
+				"TRADING_LOG_NOT_FOUND",
+				"Trading log not found",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+		if err.Error() == "trading log already amended" {
+			c.JSON(http.StatusConflict, CreateErrorResponse(
+				"TRADING_LOG_ALREADY_AMENDED",
+				"Trading log has already been amended",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+		if strings.Contains(err.Error(), "insufficient balance to reverse trading log") {
+			c.JSON(http.StatusConflict, CreateErrorResponse(
+				"TRADING_LOG_REVERSAL_CONFLICT",
+				"Cannot reverse trading log because its balance has already been spent",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+		if strings.HasPrefix(err.Error(), "info validation failed") {
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INVALID_TRADING_LOG_INFO",
+				"Invalid trading log info",
+				err.Error(),
+				getTraceID(c),
+			))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, CreateErrorResponse(
+			"TRADING_LOG_AMEND_FAILED",
+			"Failed to amend trading log",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateSuccessResponse(tradingLog, getTraceID(c)))
+}
+
 // ListAllTradingLogs lists all trading logs with filtering (admin only)
 // @Summary List all trading logs
 // @Description Lists all trading logs with filtering and pagination (admin only)
@@ -68,7 +68,8 @@ func (h *TransactionHandler) GetUserTransactions(c *gin.Context) {
 	transactions, err := h.transactionService.GetUserTransactions(c.Request.Context(), userID, &req)
 	if err != nil {
 		if err.Error() == "start date cannot be after end date" ||
-			err.Error() == "min amount cannot be greater than max amount" {
+			err.Error() == "min amount cannot be greater than max amount" ||
+			err.Error() == "amount filters cannot be negative" {
 			c.JSON(http.StatusBadRequest, CreateErrorResponse(
 				"INVALID_FILTER_RANGE",
 				"Invalid filter range",
@@ -158,7 +159,8 @@ func (h *TransactionHandler) GetSubAccountTransactions(c *gin.Context) {
 			return
 		}
 		if err.Error() == "start date cannot be after end date" ||
-			err.Error() == "min amount cannot be greater than max amount" {
+			err.Error() == "min amount cannot be greater than max amount" ||
+			err.Error() == "amount filters cannot be negative" {
 			c.JSON(http.StatusBadRequest, CreateErrorResponse(
 				"INVALID_FILTER_RANGE",
 				"Invalid filter range",
@@ -248,7 +250,8 @@ func (h *TransactionHandler) GetTradingTransactions(c *gin.Context) {
 			return
 		}
 		if err.Error() == "start date cannot be after end date" ||
-			err.Error() == "min amount cannot be greater than max amount" {
+			err.Error() == "min amount cannot be greater than max amount" ||
+			err.Error() == "amount filters cannot be negative" {
 			c.JSON(http.StatusBadRequest, CreateErrorResponse(
 				"INVALID_FILTER_RANGE",
 				"Invalid filter range",
@@ -412,7 +415,8 @@ func (h *TransactionHandler) GetTransactionsByTimeRange(c *gin.Context) {
 	transactions, err := h.transactionService.GetTransactionsByTimeRange(c.Request.Context(), userID, startTime, endTime, &req)
 	if err != nil {
 		if err.Error() == "start time cannot be after end time" ||
-			err.Error() == "min amount cannot be greater than max amount" {
+			err.Error() == "min amount cannot be greater than max amount" ||
+			err.Error() == "amount filters cannot be negative" {
 			c.JSON(http.StatusBadRequest, CreateErrorResponse(
 				"INVALID_FILTER_RANGE",
 				"Invalid filter range",
@@ -469,7 +473,8 @@ func (h *TransactionHandler) ListAllTransactions(c *gin.Context) {
 	transactions, err := h.transactionService.ListAllTransactions(c.Request.Context(), &req)
 	if err != nil {
 		if err.Error() == "start date cannot be after end date" ||
-			err.Error() == "min amount cannot be greater than max amount" {
+			err.Error() == "min amount cannot be greater than max amount" ||
+			err.Error() == "amount filters cannot be negative" {
 			c.JSON(http.StatusBadRequest, CreateErrorResponse(
 				"INVALID_FILTER_RANGE",
 				"Invalid filter range",
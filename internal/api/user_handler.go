@@ -122,7 +122,7 @@ func (h *UserHandler) UpdateCurrentUser(c *gin.Context) {
 
 // GetUserStats retrieves current user statistics
 // @Summary Get current user statistics
-// @Description Retrieves statistics for the currently authenticated user
+// @Description Retrieves statistics for the currently authenticated user. The total balance only counts the sub-accounts of real tradings
 // @Tags Users
 // @Produce json
 // @Security BearerAuth
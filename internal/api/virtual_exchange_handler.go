@@ -0,0 +1,267 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"tiris-backend/internal/middleware"
+	"tiris-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// VirtualExchangeHandler handles the paper trading endpoints of the virtual exchange
+type VirtualExchangeHandler struct {
+	virtualExchangeService *services.VirtualExchangeService
+}
+
+// NewVirtualExchangeHandler creates a new virtual exchange handler
+func NewVirtualExchangeHandler(virtualExchangeService *services.VirtualExchangeService) *VirtualExchangeHandler {
+	return &VirtualExchangeHandler{
+		virtualExchangeService: virtualExchangeService,
+	}
+}
+
+// PlaceOrder places an order on the virtual exchange
+// @Summary Place virtual order
+// @Description Places a market or limit order on the virtual exchange of a simulation or backtest trading (must belong to authenticated user).
+// @Description The order trades the stock account against the currency account, e.g. ETH/USDT, and stays open until a price tick of its symbol is pushed.
+// @Description Funds are checked when the order fills; a fill the ledger rejects fails the order.
+// @Tags VirtualExchange
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Trading ID"
+// @Param request body services.PlaceVirtualOrderRequest true "Place order request"
+// @Success 201 {object} services.OrderResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tradings/{id}/virtual/orders [post]
+func (h *VirtualExchangeHandler) PlaceOrder(c *gin.Context) {
+	userID, tradingID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	var req services.PlaceVirtualOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_REQUEST",
+			"Invalid request format",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	order, err := h.virtualExchangeService.PlaceOrder(c.Request.Context(), userID, tradingID, &req)
+	if err != nil {
+		switch {
+		case err.Error() == "trading not found", err.Error() == "stock account not found", err.Error() == "currency account not found":
+			c.JSON(http.StatusNotFound, CreateErrorResponse(
+				"NOT_FOUND",
+				"Trading or sub-account not found",
+				err.Error(),
+				getTraceID(c),
+			))
+		case err.Error() == "order already exists":
+			c.JSON(http.StatusConflict, CreateErrorResponse(
+				"ORDER_EXISTS",
+				"Order already exists",
+				err.Error(),
+				getTraceID(c),
+			))
+		case isVirtualOrderError(err):
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INVALID_ORDER",
+				"Invalid order",
+				err.Error(),
+				getTraceID(c),
+			))
+		default:
+			c.JSON(http.StatusInternalServerError, CreateErrorResponse(
+				"ORDER_PLACE_FAILED",
+				"Failed to place order",
+				err.Error(),
+				getTraceID(c),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateSuccessResponse(order, getTraceID(c)))
+}
+
+// CancelOrder cancels an order of the virtual exchange
+// @Summary Cancel virtual order
+// @Description Cancels an open or partially filled order of the virtual exchange (must belong to authenticated user). Filled amounts stay in the ledger.
+// @Tags VirtualExchange
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Trading ID"
+// @Param order_id path string true "Order ID"
+// @Success 200 {object} services.OrderResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tradings/{id}/virtual/orders/{order_id} [delete]
+func (h *VirtualExchangeHandler) CancelOrder(c *gin.Context) {
+	userID, tradingID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.virtualExchangeService.CancelOrder(c.Request.Context(), userID, tradingID, c.Param("order_id"))
+	if err != nil {
+		switch {
+		case err.Error() == "trading not found", err.Error() == "order not found":
+			c.JSON(http.StatusNotFound, CreateErrorResponse(
+				"NOT_FOUND",
+				"Trading or order not found",
+				err.Error(),
+				getTraceID(c),
+			))
+		case err.Error() == "virtual exchange requires a simulation or backtest trading":
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"SIMULATED_TRADING_REQUIRED",
+				"Virtual exchange requires a simulation or backtest trading",
+				err.Error(),
+				getTraceID(c),
+			))
+		case strings.HasSuffix(err.Error(), "cannot be cancelled"):
+			c.JSON(http.StatusConflict, CreateErrorResponse(
+				"ORDER_NOT_CANCELLABLE",
+				"Order cannot be cancelled",
+				err.Error(),
+				getTraceID(c),
+			))
+		default:
+			c.JSON(http.StatusInternalServerError, CreateErrorResponse(
+				"ORDER_CANCEL_FAILED",
+				"Failed to cancel order",
+				err.Error(),
+				getTraceID(c),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, CreateSuccessResponse(order, getTraceID(c)))
+}
+
+// PushPrice pushes a price tick to the virtual exchange
+// @Summary Push virtual price tick
+// @Description Pushes a price of the feed of a simulation or backtest trading (must belong to authenticated user). The open orders of the symbol are matched oldest first:
+// @Description market orders fill at the tick price with the slippage of the trading, limit orders fill once the price reaches their limit and never at a worse price.
+// @Description Limit orders resting from an earlier tick pay the maker fee; market orders and limit orders crossing the first tick after they were placed pay the taker fee. With a volume the fills of the tick never exceed it, so orders can fill partially.
+// @Description Each fill is recorded as a long (buy) or short (sell) trading log with the tick timestamp as its event time; fills are posted to the ledger in the order they happen. Price ticks can also be published on the NATS subject trading.prices.updated.
+// @Tags VirtualExchange
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Trading ID"
+// @Param request body services.PriceTickRequest true "Price tick"
+// @Success 200 {object} services.PriceTickResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tradings/{id}/virtual/prices [post]
+func (h *VirtualExchangeHandler) PushPrice(c *gin.Context) {
+	userID, tradingID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	var req services.PriceTickRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_REQUEST",
+			"Invalid request format",
+			err.Error(),
+			getTraceID(c),
+		))
+		return
+	}
+
+	result, err := h.virtualExchangeService.PushPrice(c.Request.Context(), userID, tradingID, &req)
+	if err != nil {
+		switch {
+		case err.Error() == "trading not found":
+			c.JSON(http.StatusNotFound, CreateErrorResponse(
+				"TRADING_NOT_FOUND",
+				"Trading not found",
+				err.Error(),
+				getTraceID(c),
+			))
+		case err.Error() == "virtual exchange requires a simulation or backtest trading",
+			strings.HasPrefix(err.Error(), "invalid virtual exchange configuration"),
+			strings.HasPrefix(err.Error(), "price must"), strings.HasPrefix(err.Error(), "volume"),
+			err.Error() == "symbol is required":
+			c.JSON(http.StatusBadRequest, CreateErrorResponse(
+				"INVALID_PRICE_TICK",
+				"Invalid price tick",
+				err.Error(),
+				getTraceID(c),
+			))
+		default:
+			c.JSON(http.StatusInternalServerError, CreateErrorResponse(
+				"PRICE_TICK_FAILED",
+				"Failed to process price tick",
+				err.Error(),
+				getTraceID(c),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, CreateSuccessResponse(result, getTraceID(c)))
+}
+
+// parseRequest returns the authenticated user and the trading of the path, or writes the error response
+func (h *VirtualExchangeHandler) parseRequest(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, CreateErrorResponse(
+			"AUTH_REQUIRED",
+			"Authentication required",
+			"",
+			getTraceID(c),
+		))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	tradingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreateErrorResponse(
+			"INVALID_TRADING_ID",
+			"Invalid trading ID format",
+			err.Error(),
+			getTraceID(c),
+		))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, tradingID, true
+}
+
+// isVirtualOrderError returns true for the errors of an order the virtual exchange does not accept
+func isVirtualOrderError(err error) bool {
+	switch err.Error() {
+	case "virtual exchange requires a simulation or backtest trading",
+		"stock and currency accounts must be different",
+		"order side must be buy or sell",
+		"order type must be market or limit",
+		"amount must be positive",
+		"market orders cannot have a price",
+		"limit orders require a positive price":
+		return true
+	}
+	return strings.HasSuffix(err.Error(), "decimal places")
+}
@@ -4,17 +4,22 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Environment string
-	Server      ServerConfig
-	Database    DatabaseConfig
-	Auth        AuthConfig
-	NATS        NATSConfig
-	OAuth       OAuthConfig
+	Environment    string
+	Server         ServerConfig
+	Database       DatabaseConfig
+	Auth           AuthConfig
+	Security       SecurityConfig
+	Exchange       ExchangeConfig
+	NATS           NATSConfig
+	OAuth          OAuthConfig
+	Reconciliation ReconciliationConfig
+	BalanceSync    BalanceSyncConfig
 }
 
 type ServerConfig struct {
@@ -43,6 +48,23 @@ type AuthConfig struct {
 	RefreshExpiration int
 }
 
+type SecurityConfig struct {
+	MasterKeys        map[int]string // versioned keys encrypting secrets at rest, by key id
+	ActiveMasterKeyID int            // key id new secrets are encrypted with
+	SigningKey        string         // keys the credential hashes used for lookups; never rotated
+}
+
+// Exchange connector modes
+const (
+	ExchangeConnectorsLive = "live" // connect to the real exchange APIs
+	ExchangeConnectorsFake = "fake" // verify bindings against an offline fake exchange
+)
+
+type ExchangeConfig struct {
+	Connectors string // ExchangeConnectorsLive or ExchangeConnectorsFake
+	Timeout    int    // seconds per exchange API request
+}
+
 type NATSConfig struct {
 	Enabled     bool
 	URL         string
@@ -51,6 +73,17 @@ type NATSConfig struct {
 	DurableName string
 }
 
+type ReconciliationConfig struct {
+	Enabled  bool
+	Interval int // seconds between runs
+	Adjust   bool
+}
+
+type BalanceSyncConfig struct {
+	Enabled  bool
+	Interval int // seconds between runs
+}
+
 type OAuthConfig struct {
 	Google GoogleOAuthConfig
 	WeChat WeChatOAuthConfig
@@ -99,6 +132,13 @@ func Load() (*Config, error) {
 			RefreshSecret:     getRequiredEnv("REFRESH_SECRET"),
 			RefreshExpiration: getEnvAsIntOrDefault("REFRESH_EXPIRATION", 604800),
 		},
+		Security: SecurityConfig{
+			SigningKey: getRequiredEnv("SIGNING_KEY"),
+		},
+		Exchange: ExchangeConfig{
+			Connectors: getEnvOrDefault("EXCHANGE_CONNECTORS", ExchangeConnectorsLive),
+			Timeout:    getEnvAsIntOrDefault("EXCHANGE_TIMEOUT", 10),
+		},
 		NATS: NATSConfig{
 			Enabled:     getEnvAsBoolOrDefault("NATS_ENABLED", true),
 			URL:         getEnvOrDefault("NATS_URL", "nats://localhost:4222"),
@@ -118,11 +158,87 @@ func Load() (*Config, error) {
 				RedirectURL: getEnvOrDefault("WECHAT_REDIRECT_URL", ""),
 			},
 		},
+		Reconciliation: ReconciliationConfig{
+			Enabled:  getEnvAsBoolOrDefault("RECONCILIATION_ENABLED", false),
+			Interval: getEnvAsIntOrDefault("RECONCILIATION_INTERVAL", 3600),
+			Adjust:   getEnvAsBoolOrDefault("RECONCILIATION_ADJUST", false),
+		},
+		BalanceSync: BalanceSyncConfig{
+			Enabled:  getEnvAsBoolOrDefault("BALANCE_SYNC_ENABLED", false),
+			Interval: getEnvAsIntOrDefault("BALANCE_SYNC_INTERVAL", 3600),
+		},
+	}
+
+	masterKeys, activeID, err := loadMasterKeys()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Security.MasterKeys = masterKeys
+	cfg.Security.ActiveMasterKeyID = activeID
+
+	// Secrets cannot be encrypted or hashed with weak keys
+	if len(cfg.Security.SigningKey) < 32 {
+		return nil, fmt.Errorf("SIGNING_KEY must be at least 32 characters")
+	}
+
+	if cfg.Exchange.Connectors != ExchangeConnectorsLive && cfg.Exchange.Connectors != ExchangeConnectorsFake {
+		return nil, fmt.Errorf("EXCHANGE_CONNECTORS must be %q or %q", ExchangeConnectorsLive, ExchangeConnectorsFake)
 	}
 
 	return cfg, nil
 }
 
+// loadMasterKeys loads the versioned master keys. MASTER_KEYS lists "<id>:<key>" pairs separated by
+// commas; MASTER_KEY is key 1, the key of ciphertexts written before keys were versioned. New
+// secrets are encrypted with ACTIVE_MASTER_KEY_ID, by default the highest key id.
+func loadMasterKeys() (map[int]string, int, error) {
+	masterKeys := make(map[int]string)
+	if value := os.Getenv("MASTER_KEYS"); value != "" {
+		for _, entry := range strings.Split(value, ",") {
+			idPart, key, found := strings.Cut(strings.TrimSpace(entry), ":")
+			id, err := strconv.Atoi(idPart)
+			if !found || err != nil || id <= 0 {
+				return nil, 0, fmt.Errorf("MASTER_KEYS entries must have the form <id>:<key> with a positive id")
+			}
+			if _, exists := masterKeys[id]; exists {
+				return nil, 0, fmt.Errorf("MASTER_KEYS contains key id %d twice", id)
+			}
+			masterKeys[id] = key
+		}
+	}
+	if value := os.Getenv("MASTER_KEY"); value != "" {
+		if _, exists := masterKeys[1]; exists {
+			return nil, 0, fmt.Errorf("MASTER_KEY and MASTER_KEYS both define key id 1")
+		}
+		masterKeys[1] = value
+	}
+	if len(masterKeys) == 0 {
+		return nil, 0, fmt.Errorf("MASTER_KEY or MASTER_KEYS must be set")
+	}
+
+	activeID := 0
+	for id, key := range masterKeys {
+		if len(key) < 32 {
+			return nil, 0, fmt.Errorf("master key %d must be at least 32 characters", id)
+		}
+		if id > activeID {
+			activeID = id
+		}
+	}
+	if value := os.Getenv("ACTIVE_MASTER_KEY_ID"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, 0, fmt.Errorf("ACTIVE_MASTER_KEY_ID must be a number")
+		}
+		if _, exists := masterKeys[id]; !exists {
+			return nil, 0, fmt.Errorf("ACTIVE_MASTER_KEY_ID %d is not a configured master key", id)
+		}
+		activeID = id
+	}
+
+	return masterKeys, activeID, nil
+}
+
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
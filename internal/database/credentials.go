@@ -0,0 +1,260 @@
+package database
+
+import (
+	"fmt"
+
+	"tiris-backend/internal/models"
+	"tiris-backend/pkg/security"
+
+	"gorm.io/gorm"
+)
+
+// credentialBatchSize is the default number of rows encrypted, decrypted or re-encrypted per
+// transaction
+const credentialBatchSize = 100
+
+// credentialRow is the credential part of an exchange binding as stored in the database
+type credentialRow struct {
+	ID        string
+	APIKey    string
+	APISecret string
+}
+
+// EncryptExchangeBindingCredentials encrypts the plaintext credentials of existing exchange bindings
+// in place. A binding without credential hashes still holds plaintext; each one is encrypted and
+// hashed in a single update, so an interrupted run can simply be repeated.
+func EncryptExchangeBindingCredentials(db *gorm.DB, credentials *models.ExchangeBindingManager) (int, error) {
+	encrypted := 0
+	for {
+		var rows []credentialRow
+		err := db.Table("exchange_bindings").
+			Select("id, COALESCE(api_key, '') AS api_key, COALESCE(api_secret, '') AS api_secret").
+			Where("api_key_hash = '' AND api_secret_hash = ''").
+			Where("COALESCE(api_key, '') <> '' OR COALESCE(api_secret, '') <> ''").
+			Order("id").
+			Limit(credentialBatchSize).
+			Scan(&rows).Error
+		if err != nil {
+			return encrypted, fmt.Errorf("failed to load plaintext credentials: %w", err)
+		}
+		if len(rows) == 0 {
+			return encrypted, nil
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			for _, row := range rows {
+				var binding models.ExchangeBinding
+				if err := credentials.SetAPICredentials(&binding, row.APIKey, row.APISecret); err != nil {
+					return fmt.Errorf("failed to encrypt credentials of exchange binding %s: %w", row.ID, err)
+				}
+
+				err := tx.Table("exchange_bindings").
+					Where("id = ? AND api_key_hash = '' AND api_secret_hash = ''", row.ID).
+					Updates(map[string]interface{}{
+						"api_key":         binding.APIKey,
+						"api_secret":      binding.APISecret,
+						"api_key_hash":    binding.APIKeyHash,
+						"api_secret_hash": binding.APISecretHash,
+						"masked_api_key":  binding.MaskedAPIKey,
+					}).Error
+				if err != nil {
+					return fmt.Errorf("failed to store encrypted credentials of exchange binding %s: %w", row.ID, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return encrypted, err
+		}
+		encrypted += len(rows)
+	}
+}
+
+// DecryptExchangeBindingCredentials restores the plaintext credentials of all exchange bindings and
+// clears their hashes. It is the data part of rolling back the credential encryption migration.
+func DecryptExchangeBindingCredentials(db *gorm.DB, credentials *models.ExchangeBindingManager) (int, error) {
+	decrypted := 0
+	for {
+		var rows []credentialRow
+		err := db.Table("exchange_bindings").
+			Select("id, COALESCE(api_key, '') AS api_key, COALESCE(api_secret, '') AS api_secret").
+			Where("api_key_hash <> '' OR api_secret_hash <> ''").
+			Order("id").
+			Limit(credentialBatchSize).
+			Scan(&rows).Error
+		if err != nil {
+			return decrypted, fmt.Errorf("failed to load encrypted credentials: %w", err)
+		}
+		if len(rows) == 0 {
+			return decrypted, nil
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			for _, row := range rows {
+				apiKey, apiSecret, err := credentials.GetAPICredentials(&models.ExchangeBinding{APIKey: row.APIKey, APISecret: row.APISecret})
+				if err != nil {
+					return fmt.Errorf("failed to decrypt credentials of exchange binding %s: %w", row.ID, err)
+				}
+
+				err = tx.Table("exchange_bindings").
+					Where("id = ?", row.ID).
+					Updates(map[string]interface{}{
+						"api_key":         apiKey,
+						"api_secret":      apiSecret,
+						"api_key_hash":    "",
+						"api_secret_hash": "",
+						"masked_api_key":  "",
+					}).Error
+				if err != nil {
+					return fmt.Errorf("failed to store decrypted credentials of exchange binding %s: %w", row.ID, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return decrypted, err
+		}
+		decrypted += len(rows)
+	}
+}
+
+// secretTable describes the encrypted columns of a table for key rotation
+type secretTable struct {
+	name    string
+	columns []string
+	// scope limits the rotation to rows whose secrets are encrypted
+	scope string
+	// plaintext marks tables whose values without a key id are plaintext rather than ciphertexts of
+	// the legacy key
+	plaintext bool
+}
+
+var (
+	exchangeBindingSecrets = secretTable{
+		name:    "exchange_bindings",
+		columns: []string{"api_key", "api_secret"},
+		// Bindings without hashes still wait for EncryptExchangeBindingCredentials
+		scope: "api_key_hash <> '' OR api_secret_hash <> ''",
+	}
+	oauthTokenSecrets = secretTable{
+		name:    "oauth_tokens",
+		columns: []string{"access_token", "refresh_token"},
+		// OAuth tokens were stored in plaintext before they were encrypted with the keyring
+		plaintext: true,
+	}
+)
+
+// ReencryptExchangeBindingCredentials re-encrypts the exchange binding credentials that are not
+// encrypted with the active key of the keyring. See reencryptSecrets.
+func ReencryptExchangeBindingCredentials(db *gorm.DB, keyring *security.Keyring, batchSize int, progress func(done int)) (int, error) {
+	return reencryptSecrets(db, keyring, exchangeBindingSecrets, batchSize, progress)
+}
+
+// ReencryptOAuthTokens encrypts the OAuth tokens that are not encrypted with the active key of the
+// keyring, including tokens still stored in plaintext. See reencryptSecrets.
+func ReencryptOAuthTokens(db *gorm.DB, keyring *security.Keyring, batchSize int, progress func(done int)) (int, error) {
+	return reencryptSecrets(db, keyring, oauthTokenSecrets, batchSize, progress)
+}
+
+// CountStaleSecrets returns the number of exchange bindings and OAuth tokens with secrets that are
+// not encrypted with the active key of the keyring
+func CountStaleSecrets(db *gorm.DB, keyring *security.Keyring) (bindings int64, tokens int64, err error) {
+	if err := staleSecretsQuery(db, keyring, exchangeBindingSecrets).Count(&bindings).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to count exchange bindings: %w", err)
+	}
+	if err := staleSecretsQuery(db, keyring, oauthTokenSecrets).Count(&tokens).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to count OAuth tokens: %w", err)
+	}
+	return bindings, tokens, nil
+}
+
+// reencryptSecrets re-encrypts the secrets of a table under the active key in batches. Every batch
+// is committed on its own and rows under the active key are skipped, so an interrupted rotation
+// resumes where it stopped when it is run again. A row is only updated if its secrets did not change
+// in the meantime; rows written by the application use the active key anyway.
+func reencryptSecrets(db *gorm.DB, keyring *security.Keyring, table secretTable, batchSize int, progress func(done int)) (int, error) {
+	if batchSize <= 0 {
+		batchSize = credentialBatchSize
+	}
+
+	columns := "id"
+	for _, column := range table.columns {
+		columns += fmt.Sprintf(", COALESCE(%s, '') AS %s", column, column)
+	}
+
+	done := 0
+	for {
+		var rows []map[string]interface{}
+		err := staleSecretsQuery(db, keyring, table).
+			Select(columns).
+			Order("id").
+			Limit(batchSize).
+			Find(&rows).Error
+		if err != nil {
+			return done, fmt.Errorf("failed to load %s: %w", table.name, err)
+		}
+		if len(rows) == 0 {
+			return done, nil
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			for _, row := range rows {
+				query := tx.Table(table.name).Where("id = ?", row["id"])
+				updates := make(map[string]interface{}, len(table.columns))
+				for _, column := range table.columns {
+					value, _ := row[column].(string)
+					query = query.Where(fmt.Sprintf("COALESCE(%s, '') = ?", column), value)
+
+					rotated, err := reencryptSecret(keyring, value, table.plaintext)
+					if err != nil {
+						return fmt.Errorf("failed to re-encrypt %s of %s %v: %w", column, table.name, row["id"], err)
+					}
+					updates[column] = rotated
+				}
+
+				if err := query.Updates(updates).Error; err != nil {
+					return fmt.Errorf("failed to store re-encrypted secrets of %s %v: %w", table.name, row["id"], err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return done, err
+		}
+
+		done += len(rows)
+		if progress != nil {
+			progress(done)
+		}
+	}
+}
+
+// staleSecretsQuery selects the rows of a table with a secret that is not encrypted with the active
+// key, recognized by the key id prefix of the ciphertexts
+func staleSecretsQuery(db *gorm.DB, keyring *security.Keyring, table secretTable) *gorm.DB {
+	activePrefix := security.CiphertextPrefix(keyring.ActiveKeyID()) + "%"
+
+	query := db.Table(table.name)
+	if table.scope != "" {
+		query = query.Where(table.scope)
+	}
+
+	var stale *gorm.DB
+	for i, column := range table.columns {
+		condition := fmt.Sprintf("COALESCE(%s, '') <> '' AND %s NOT LIKE ?", column, column)
+		if i == 0 {
+			stale = db.Where(condition, activePrefix)
+		} else {
+			stale = stale.Or(condition, activePrefix)
+		}
+	}
+
+	return query.Where(stale)
+}
+
+func reencryptSecret(keyring *security.Keyring, value string, plaintext bool) (string, error) {
+	if plaintext && !security.HasKeyID(value) {
+		return keyring.Encrypt(value)
+	}
+	return keyring.Reencrypt(value)
+}
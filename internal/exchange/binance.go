@@ -0,0 +1,127 @@
+package exchange
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const binanceBaseURL = "https://api.binance.com"
+
+// Binance error codes of rejected API keys and signatures
+var binanceCredentialErrors = map[int]bool{
+	-1022: true, // invalid signature
+	-2014: true, // API key format invalid
+	-2015: true, // invalid API key, IP or permissions
+}
+
+// BinanceConnector connects to the Binance spot API
+type BinanceConnector struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewBinanceConnector creates a new Binance connector
+func NewBinanceConnector(client *http.Client) *BinanceConnector {
+	return &BinanceConnector{
+		client:  client,
+		baseURL: binanceBaseURL,
+	}
+}
+
+type binanceAccount struct {
+	CanTrade    bool `json:"canTrade"`
+	CanWithdraw bool `json:"canWithdraw"`
+	Balances    []struct {
+		Asset  string          `json:"asset"`
+		Free   decimal.Decimal `json:"free"`
+		Locked decimal.Decimal `json:"locked"`
+	} `json:"balances"`
+}
+
+// Ping checks that the Binance API is reachable
+func (c *BinanceConnector) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v3/ping", nil)
+	if err != nil {
+		return err
+	}
+	return doJSON(c.client, req, nil)
+}
+
+// GetBalances returns the non-zero spot balances of the account
+func (c *BinanceConnector) GetBalances(ctx context.Context, credentials Credentials) ([]Balance, error) {
+	account, err := c.getAccount(ctx, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]Balance, 0, len(account.Balances))
+	for _, balance := range account.Balances {
+		if balance.Free.IsZero() && balance.Locked.IsZero() {
+			continue
+		}
+		balances = append(balances, Balance{Asset: balance.Asset, Free: balance.Free, Locked: balance.Locked})
+	}
+	return balances, nil
+}
+
+// GetPermissions returns the permissions of the API key as reported by the account
+func (c *BinanceConnector) GetPermissions(ctx context.Context, credentials Credentials) (*Permissions, error) {
+	account, err := c.getAccount(ctx, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Permissions{
+		Read:     true,
+		Trade:    account.CanTrade,
+		Withdraw: account.CanWithdraw,
+	}, nil
+}
+
+func (c *BinanceConnector) getAccount(ctx context.Context, credentials Credentials) (*binanceAccount, error) {
+	query := url.Values{}
+	query.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli(), 10))
+	query.Set("recvWindow", "5000")
+	// The signature covers the query string exactly as sent, so it is appended last
+	payload := query.Encode()
+	signature := hex.EncodeToString(hmacSHA256([]byte(credentials.APISecret), []byte(payload)))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v3/account?"+payload+"&signature="+signature, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-MBX-APIKEY", credentials.APIKey)
+
+	var account binanceAccount
+	if err := doJSON(c.client, req, &account); err != nil {
+		return nil, binanceError(err)
+	}
+	return &account, nil
+}
+
+// binanceError reports rejected keys and signatures as ErrInvalidCredentials, which Binance answers
+// with 400 and an error code
+func binanceError(err error) error {
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	var body struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if json.Unmarshal(apiErr.body, &body) == nil && binanceCredentialErrors[body.Code] {
+		return fmt.Errorf("%w: %s", ErrInvalidCredentials, body.Msg)
+	}
+	return err
+}
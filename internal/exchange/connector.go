@@ -0,0 +1,182 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"tiris-backend/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrUnsupportedExchange = errors.New("unsupported exchange")
+	ErrInvalidCredentials  = errors.New("invalid API credentials")
+)
+
+// Credentials are the plaintext API credentials of an exchange binding
+type Credentials struct {
+	APIKey    string
+	APISecret string
+}
+
+// Balance is the balance of one asset of an exchange account
+type Balance struct {
+	Asset  string          `json:"asset"`
+	Free   decimal.Decimal `json:"free"`
+	Locked decimal.Decimal `json:"locked"`
+}
+
+// Total returns the free and locked balance
+func (b Balance) Total() decimal.Decimal {
+	return b.Free.Add(b.Locked)
+}
+
+// Permissions are the permissions of an API key. Exchanges that cannot report a permission leave it
+// false; a key that can read balances always has Read.
+type Permissions struct {
+	Read     bool `json:"read"`
+	Trade    bool `json:"trade"`
+	Withdraw bool `json:"withdraw"`
+}
+
+// ExchangeConnector talks to the API of one exchange
+type ExchangeConnector interface {
+	// Ping checks that the exchange is reachable. It needs no credentials.
+	Ping(ctx context.Context) error
+	// GetBalances returns the non-zero balances of the account of the credentials
+	GetBalances(ctx context.Context, credentials Credentials) ([]Balance, error)
+	// GetPermissions returns the permissions of the API key. It returns ErrInvalidCredentials if the
+	// exchange rejects the key.
+	GetPermissions(ctx context.Context, credentials Credentials) (*Permissions, error)
+}
+
+// Registry holds the connector of every supported exchange
+type Registry struct {
+	connectors map[string]ExchangeConnector
+}
+
+// NewRegistry creates an empty connector registry
+func NewRegistry() *Registry {
+	return &Registry{
+		connectors: make(map[string]ExchangeConnector),
+	}
+}
+
+// NewLiveRegistry creates a registry of the connectors to the real exchange APIs
+func NewLiveRegistry(timeout time.Duration) *Registry {
+	client := newHTTPClient(timeout)
+
+	registry := NewRegistry()
+	registry.Register(models.ExchangeBinance, NewBinanceConnector(client))
+	registry.Register(models.ExchangeKraken, NewKrakenConnector(client))
+	registry.Register(models.ExchangeGate, NewGateConnector(client))
+	registry.Register(models.ExchangeCoinbase, NewCoinbaseConnector(client))
+	return registry
+}
+
+// NewFakeRegistry creates a registry that serves every real exchange with the given fake connector,
+// so bindings can be created and verified offline
+func NewFakeRegistry(fake *FakeConnector) *Registry {
+	registry := NewRegistry()
+	for _, exchange := range []string{models.ExchangeBinance, models.ExchangeKraken, models.ExchangeGate, models.ExchangeCoinbase} {
+		registry.Register(exchange, fake)
+	}
+	return registry
+}
+
+// Register sets the connector of an exchange, replacing a registered one
+func (r *Registry) Register(exchange string, connector ExchangeConnector) {
+	r.connectors[exchange] = connector
+}
+
+// Get returns the connector of an exchange
+func (r *Registry) Get(exchange string) (ExchangeConnector, error) {
+	connector, ok := r.connectors[exchange]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedExchange, exchange)
+	}
+	return connector, nil
+}
+
+// Exchanges returns the exchanges with a registered connector in alphabetical order
+func (r *Registry) Exchanges() []string {
+	exchanges := make([]string, 0, len(r.connectors))
+	for exchange := range r.connectors {
+		exchanges = append(exchanges, exchange)
+	}
+	sort.Strings(exchanges)
+	return exchanges
+}
+
+// Verification is the result of checking API credentials against an exchange
+type Verification struct {
+	Verified    bool
+	Error       string
+	Permissions Permissions
+	Assets      int
+	CheckedAt   time.Time
+}
+
+// Verify checks that the exchange is reachable, that it accepts the credentials and that the key can
+// read the account. Failures are reported in the verification rather than as an error, so the caller
+// can store them with the binding.
+func Verify(ctx context.Context, connector ExchangeConnector, credentials Credentials) *Verification {
+	verification := &Verification{CheckedAt: time.Now().UTC()}
+
+	if err := connector.Ping(ctx); err != nil {
+		verification.Error = fmt.Sprintf("exchange unreachable: %v", err)
+		return verification
+	}
+
+	permissions, err := connector.GetPermissions(ctx, credentials)
+	if err != nil {
+		verification.Error = err.Error()
+		return verification
+	}
+	verification.Permissions = *permissions
+	if !permissions.Read {
+		verification.Error = "API key cannot read the account"
+		return verification
+	}
+
+	balances, err := connector.GetBalances(ctx, credentials)
+	if err != nil {
+		verification.Error = fmt.Sprintf("failed to read balances: %v", err)
+		return verification
+	}
+	verification.Assets = len(balances)
+	verification.Verified = true
+
+	return verification
+}
+
+// Status returns the exchange binding status matching the verification
+func (v *Verification) Status() string {
+	if v.Verified {
+		return models.ExchangeBindingStatusActive
+	}
+	return models.ExchangeBindingStatusError
+}
+
+// ToInfo returns the verification as it is stored in the info of an exchange binding
+func (v *Verification) ToInfo() map[string]interface{} {
+	info := map[string]interface{}{
+		"verified":   v.Verified,
+		"checked_at": v.CheckedAt.Format(time.RFC3339),
+		"permissions": map[string]interface{}{
+			"read":     v.Permissions.Read,
+			"trade":    v.Permissions.Trade,
+			"withdraw": v.Permissions.Withdraw,
+		},
+	}
+	if v.Verified {
+		info["assets"] = v.Assets
+	} else {
+		info["error"] = v.Error
+	}
+	return info
+}
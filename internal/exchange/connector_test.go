@@ -0,0 +1,253 @@
+package exchange
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"tiris-backend/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testCredentials = Credentials{APIKey: "test_api_key", APISecret: "test_api_secret"}
+
+func TestRegistry(t *testing.T) {
+	registry := NewFakeRegistry(NewFakeConnector())
+
+	connector, err := registry.Get(models.ExchangeBinance)
+	require.NoError(t, err)
+	assert.IsType(t, &FakeConnector{}, connector)
+
+	_, err = registry.Get(models.ExchangeVirtual)
+	assert.ErrorIs(t, err, ErrUnsupportedExchange)
+
+	assert.Equal(t, []string{"binance", "coinbase", "gate", "kraken"}, registry.Exchanges())
+	assert.Equal(t, []string{"binance", "coinbase", "gate", "kraken"}, NewLiveRegistry(0).Exchanges())
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("verified", func(t *testing.T) {
+		fake := NewFakeConnector()
+		fake.SetBalances([]Balance{{Asset: "BTC", Free: decimal.NewFromInt(1)}})
+
+		verification := Verify(ctx, fake, testCredentials)
+
+		assert.True(t, verification.Verified)
+		assert.Equal(t, models.ExchangeBindingStatusActive, verification.Status())
+		assert.Equal(t, Permissions{Read: true, Trade: true}, verification.Permissions)
+		assert.Equal(t, 1, verification.ToInfo()["assets"])
+	})
+
+	t.Run("rejected_key", func(t *testing.T) {
+		verification := Verify(ctx, NewFakeConnector(), Credentials{APIKey: FakeInvalidKeyPrefix + "_key", APISecret: "secret"})
+
+		assert.False(t, verification.Verified)
+		assert.Equal(t, models.ExchangeBindingStatusError, verification.Status())
+		assert.Contains(t, verification.ToInfo()["error"], "invalid API credentials")
+	})
+
+	t.Run("key_without_read_permission", func(t *testing.T) {
+		fake := NewFakeConnector()
+		fake.SetPermissions(Permissions{Trade: true})
+
+		verification := Verify(ctx, fake, testCredentials)
+
+		assert.False(t, verification.Verified)
+		assert.Equal(t, "API key cannot read the account", verification.Error)
+	})
+
+	t.Run("exchange_unreachable", func(t *testing.T) {
+		fake := NewFakeConnector()
+		fake.SetUnreachable(true)
+
+		verification := Verify(ctx, fake, testCredentials)
+
+		assert.False(t, verification.Verified)
+		assert.Equal(t, "exchange unreachable: fake exchange is unreachable", verification.Error)
+	})
+}
+
+func TestBinanceConnector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/ping":
+			w.Write([]byte(`{}`))
+		case "/api/v3/account":
+			if r.Header.Get("X-MBX-APIKEY") != testCredentials.APIKey {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"code":-2015,"msg":"Invalid API-key, IP, or permissions for action."}`))
+				return
+			}
+			payload, signature, _ := strings.Cut(r.URL.RawQuery, "&signature=")
+			if signature != hex.EncodeToString(hmacSHA256([]byte(testCredentials.APISecret), []byte(payload))) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"code":-1022,"msg":"Signature for this request is not valid."}`))
+				return
+			}
+			w.Write([]byte(`{"canTrade":true,"canWithdraw":false,"balances":[
+				{"asset":"BTC","free":"0.5","locked":"0.1"},
+				{"asset":"ETH","free":"0","locked":"0"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	connector := NewBinanceConnector(server.Client())
+	connector.baseURL = server.URL
+	ctx := context.Background()
+
+	require.NoError(t, connector.Ping(ctx))
+
+	permissions, err := connector.GetPermissions(ctx, testCredentials)
+	require.NoError(t, err)
+	assert.Equal(t, Permissions{Read: true, Trade: true}, *permissions)
+
+	balances, err := connector.GetBalances(ctx, testCredentials)
+	require.NoError(t, err)
+	require.Len(t, balances, 1)
+	assert.Equal(t, "BTC", balances[0].Asset)
+	assert.True(t, balances[0].Total().Equal(decimal.RequireFromString("0.6")))
+
+	_, err = connector.GetBalances(ctx, Credentials{APIKey: testCredentials.APIKey, APISecret: "wrong_secret"})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = connector.GetBalances(ctx, Credentials{APIKey: "unknown_key", APISecret: "secret"})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestKrakenConnector(t *testing.T) {
+	secret := base64.StdEncoding.EncodeToString([]byte("kraken-test-secret"))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/0/public/SystemStatus":
+			w.Write([]byte(`{"error":[],"result":{"status":"online"}}`))
+		case "/0/private/Balance":
+			body, _ := io.ReadAll(r.Body)
+			nonce := strings.TrimPrefix(string(body), "nonce=")
+			digest := sha256.Sum256([]byte(nonce + string(body)))
+			expected := base64.StdEncoding.EncodeToString(hmacSHA512([]byte("kraken-test-secret"), append([]byte(r.URL.Path), digest[:]...)))
+			if r.Header.Get("API-Key") != testCredentials.APIKey || r.Header.Get("API-Sign") != expected {
+				w.Write([]byte(`{"error":["EAPI:Invalid key"]}`))
+				return
+			}
+			w.Write([]byte(`{"error":[],"result":{"XXBT":"0.25","ZUSD":"100.5","SOL":"0"}}`))
+		}
+	}))
+	defer server.Close()
+
+	connector := NewKrakenConnector(server.Client())
+	connector.baseURL = server.URL
+	ctx := context.Background()
+	credentials := Credentials{APIKey: testCredentials.APIKey, APISecret: secret}
+
+	require.NoError(t, connector.Ping(ctx))
+
+	permissions, err := connector.GetPermissions(ctx, credentials)
+	require.NoError(t, err)
+	assert.Equal(t, Permissions{Read: true}, *permissions)
+
+	balances, err := connector.GetBalances(ctx, credentials)
+	require.NoError(t, err)
+	amounts := make(map[string]string)
+	for _, balance := range balances {
+		amounts[balance.Asset] = balance.Total().String()
+	}
+	assert.Equal(t, map[string]string{"BTC": "0.25", "USD": "100.5"}, amounts)
+
+	_, err = connector.GetBalances(ctx, Credentials{APIKey: "unknown_key", APISecret: secret})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = connector.GetBalances(ctx, Credentials{APIKey: testCredentials.APIKey, APISecret: "not base64!"})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestGateConnector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("KEY") == "read_only_key" {
+			w.Write([]byte(`[{"currency":"USDT","available":"250","locked":"50"}]`))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"label":"FORBIDDEN","message":"Forbidden"}`))
+	}))
+	defer server.Close()
+
+	connector := NewGateConnector(server.Client())
+	connector.baseURL = server.URL
+	ctx := context.Background()
+
+	permissions, err := connector.GetPermissions(ctx, Credentials{APIKey: "trade_only_key", APISecret: "secret"})
+	require.NoError(t, err)
+	assert.False(t, permissions.Read)
+
+	balances, err := connector.GetBalances(ctx, Credentials{APIKey: "read_only_key", APISecret: "secret"})
+	require.NoError(t, err)
+	require.Len(t, balances, 1)
+	assert.True(t, balances[0].Total().Equal(decimal.NewFromInt(300)))
+}
+
+func TestCoinbaseConnector(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalECPrivateKey(privateKey)
+	require.NoError(t, err)
+	credentials := Credentials{
+		APIKey:    "organizations/org/apiKeys/key",
+		APISecret: string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})),
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims := jwt.MapClaims{}
+		_, err := jwt.ParseWithClaims(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), claims, func(token *jwt.Token) (interface{}, error) {
+			return &privateKey.PublicKey, nil
+		}, jwt.WithValidMethods([]string{"ES256"}))
+		if err != nil || claims["sub"] != credentials.APIKey || claims["uri"] != "GET "+r.Host+r.URL.Path {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/v3/brokerage/key_permissions":
+			w.Write([]byte(`{"can_view":true,"can_trade":true,"can_transfer":true}`))
+		case "/api/v3/brokerage/accounts":
+			if r.URL.Query().Get("cursor") == "" {
+				w.Write([]byte(`{"accounts":[{"currency":"BTC","available_balance":{"value":"1"},"hold":{"value":"0"}}],"has_next":true,"cursor":"next"}`))
+				return
+			}
+			w.Write([]byte(`{"accounts":[{"currency":"USDC","available_balance":{"value":"10"},"hold":{"value":"5"}}],"has_next":false}`))
+		}
+	}))
+	defer server.Close()
+
+	connector := NewCoinbaseConnector(server.Client())
+	connector.baseURL = server.URL
+	ctx := context.Background()
+
+	permissions, err := connector.GetPermissions(ctx, credentials)
+	require.NoError(t, err)
+	assert.Equal(t, Permissions{Read: true, Trade: true, Withdraw: true}, *permissions)
+
+	balances, err := connector.GetBalances(ctx, credentials)
+	require.NoError(t, err)
+	require.Len(t, balances, 2)
+	assert.Equal(t, "USDC", balances[1].Asset)
+	assert.True(t, balances[1].Locked.Equal(decimal.NewFromInt(5)))
+
+	_, err = connector.GetPermissions(ctx, Credentials{APIKey: credentials.APIKey, APISecret: "not a key"})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
@@ -0,0 +1,107 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// FakeInvalidKeyPrefix marks API keys the fake connector rejects, so failed verifications can be
+// reproduced through the API without a real exchange
+const FakeInvalidKeyPrefix = "invalid"
+
+// FakeConnector is an offline exchange for development and tests. By default it is reachable,
+// accepts every API key that does not start with FakeInvalidKeyPrefix, grants read and trade
+// permissions and reports no balances.
+type FakeConnector struct {
+	mu          sync.RWMutex
+	unreachable bool
+	rejected    map[string]bool
+	permissions Permissions
+	balances    []Balance
+}
+
+// NewFakeConnector creates a new fake connector
+func NewFakeConnector() *FakeConnector {
+	return &FakeConnector{
+		rejected:    make(map[string]bool),
+		permissions: Permissions{Read: true, Trade: true},
+	}
+}
+
+// SetUnreachable makes Ping fail as if the exchange was down
+func (f *FakeConnector) SetUnreachable(unreachable bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unreachable = unreachable
+}
+
+// RejectAPIKey makes the exchange reject an API key
+func (f *FakeConnector) RejectAPIKey(apiKey string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rejected[apiKey] = true
+}
+
+// SetPermissions sets the permissions reported for accepted API keys
+func (f *FakeConnector) SetPermissions(permissions Permissions) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.permissions = permissions
+}
+
+// SetBalances sets the balances reported for accepted API keys
+func (f *FakeConnector) SetBalances(balances []Balance) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.balances = append([]Balance(nil), balances...)
+}
+
+// Ping fails if the fake exchange is unreachable
+func (f *FakeConnector) Ping(ctx context.Context) error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.unreachable {
+		return errors.New("fake exchange is unreachable")
+	}
+	return nil
+}
+
+// GetBalances returns the configured balances
+func (f *FakeConnector) GetBalances(ctx context.Context, credentials Credentials) ([]Balance, error) {
+	if err := f.authenticate(credentials); err != nil {
+		return nil, err
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return append([]Balance(nil), f.balances...), nil
+}
+
+// GetPermissions returns the configured permissions
+func (f *FakeConnector) GetPermissions(ctx context.Context, credentials Credentials) (*Permissions, error) {
+	if err := f.authenticate(credentials); err != nil {
+		return nil, err
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	permissions := f.permissions
+	return &permissions, nil
+}
+
+func (f *FakeConnector) authenticate(credentials Credentials) error {
+	if err := f.Ping(context.Background()); err != nil {
+		return err
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if credentials.APIKey == "" || credentials.APISecret == "" ||
+		strings.HasPrefix(credentials.APIKey, FakeInvalidKeyPrefix) || f.rejected[credentials.APIKey] {
+		return fmt.Errorf("%w: API key rejected by the fake exchange", ErrInvalidCredentials)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package exchange
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const gateBaseURL = "https://api.gateio.ws"
+
+// GateConnector connects to the Gate.io spot API. Gate does not report the permissions of a key,
+// so only Read is known: it is granted when the key can query the spot accounts.
+type GateConnector struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewGateConnector creates a new Gate.io connector
+func NewGateConnector(client *http.Client) *GateConnector {
+	return &GateConnector{
+		client:  client,
+		baseURL: gateBaseURL,
+	}
+}
+
+type gateAccount struct {
+	Currency  string          `json:"currency"`
+	Available decimal.Decimal `json:"available"`
+	Locked    decimal.Decimal `json:"locked"`
+}
+
+// Ping checks that the Gate.io API is reachable
+func (c *GateConnector) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v4/spot/time", nil)
+	if err != nil {
+		return err
+	}
+	return doJSON(c.client, req, nil)
+}
+
+// GetBalances returns the non-zero spot balances of the account
+func (c *GateConnector) GetBalances(ctx context.Context, credentials Credentials) ([]Balance, error) {
+	accounts, err := c.getAccounts(ctx, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]Balance, 0, len(accounts))
+	for _, account := range accounts {
+		if account.Available.IsZero() && account.Locked.IsZero() {
+			continue
+		}
+		balances = append(balances, Balance{Asset: account.Currency, Free: account.Available, Locked: account.Locked})
+	}
+	return balances, nil
+}
+
+// GetPermissions returns whether the key can read the spot accounts
+func (c *GateConnector) GetPermissions(ctx context.Context, credentials Credentials) (*Permissions, error) {
+	_, err := c.getAccounts(ctx, credentials)
+	if isGateForbidden(err) {
+		return &Permissions{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Permissions{Read: true}, nil
+}
+
+// getAccounts sends a signed request: SIGN is the hex HMAC-SHA512 of the method, path, query, body
+// hash and timestamp, keyed with the secret
+func (c *GateConnector) getAccounts(ctx context.Context, credentials Credentials) ([]gateAccount, error) {
+	const path = "/api/v4/spot/accounts"
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	bodyHash := sha512.Sum512(nil)
+	payload := http.MethodGet + "\n" + path + "\n\n" + hex.EncodeToString(bodyHash[:]) + "\n" + timestamp
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("KEY", credentials.APIKey)
+	req.Header.Set("Timestamp", timestamp)
+	req.Header.Set("SIGN", hex.EncodeToString(hmacSHA512([]byte(credentials.APISecret), []byte(payload))))
+
+	var accounts []gateAccount
+	if err := doJSON(c.client, req, &accounts); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// isGateForbidden returns true for the error of a valid key that lacks the permission for a call
+func isGateForbidden(err error) bool {
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) || apiErr.status != http.StatusForbidden {
+		return false
+	}
+
+	var body struct {
+		Label string `json:"label"`
+	}
+	return json.Unmarshal(apiErr.body, &body) == nil && body.Label == "FORBIDDEN"
+}
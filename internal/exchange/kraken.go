@@ -0,0 +1,173 @@
+package exchange
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const krakenBaseURL = "https://api.kraken.com"
+
+// errKrakenPermissionDenied is the error of a valid key that lacks the permission for a call
+var errKrakenPermissionDenied = errors.New("EGeneral:Permission denied")
+
+// krakenAssets maps the legacy X- and Z-prefixed Kraken asset codes to the common symbols
+var krakenAssets = map[string]string{
+	"XXBT": "BTC",
+	"XBT":  "BTC",
+	"XETH": "ETH",
+	"XETC": "ETC",
+	"XLTC": "LTC",
+	"XXRP": "XRP",
+	"XXLM": "XLM",
+	"XXMR": "XMR",
+	"XZEC": "ZEC",
+	"XREP": "REP",
+	"XMLN": "MLN",
+	"XXDG": "DOGE",
+	"ZUSD": "USD",
+	"ZEUR": "EUR",
+	"ZGBP": "GBP",
+	"ZJPY": "JPY",
+	"ZCAD": "CAD",
+	"ZAUD": "AUD",
+}
+
+// KrakenConnector connects to the Kraken spot API. Kraken does not report the permissions of a
+// key, so only Read is known: it is granted when the key can query the balance.
+type KrakenConnector struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewKrakenConnector creates a new Kraken connector
+func NewKrakenConnector(client *http.Client) *KrakenConnector {
+	return &KrakenConnector{
+		client:  client,
+		baseURL: krakenBaseURL,
+	}
+}
+
+// krakenResponse is the envelope of every Kraken response; errors come with status 200
+type krakenResponse struct {
+	Error  []string        `json:"error"`
+	Result json.RawMessage `json:"result"`
+}
+
+// Ping checks that the Kraken API is reachable and not in maintenance
+func (c *KrakenConnector) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/0/public/SystemStatus", nil)
+	if err != nil {
+		return err
+	}
+
+	var status struct {
+		Status string `json:"status"`
+	}
+	if err := c.do(req, &status); err != nil {
+		return err
+	}
+	if status.Status == "maintenance" {
+		return fmt.Errorf("exchange is in maintenance")
+	}
+	return nil
+}
+
+// GetBalances returns the non-zero balances of the account. Kraken reports total balances only.
+func (c *KrakenConnector) GetBalances(ctx context.Context, credentials Credentials) ([]Balance, error) {
+	var result map[string]decimal.Decimal
+	if err := c.private(ctx, credentials, "/0/private/Balance", &result); err != nil {
+		return nil, err
+	}
+
+	balances := make([]Balance, 0, len(result))
+	for asset, amount := range result {
+		if amount.IsZero() {
+			continue
+		}
+		balances = append(balances, Balance{Asset: normalizeKrakenAsset(asset), Free: amount})
+	}
+	return balances, nil
+}
+
+// GetPermissions returns whether the key can read the account
+func (c *KrakenConnector) GetPermissions(ctx context.Context, credentials Credentials) (*Permissions, error) {
+	var result map[string]decimal.Decimal
+	err := c.private(ctx, credentials, "/0/private/Balance", &result)
+	if errors.Is(err, errKrakenPermissionDenied) {
+		return &Permissions{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Permissions{Read: true}, nil
+}
+
+// private sends a signed request: API-Sign is the HMAC-SHA512 of the path and the SHA-256 of the
+// nonce and form data, keyed with the decoded secret
+func (c *KrakenConnector) private(ctx context.Context, credentials Credentials, path string, out interface{}) error {
+	secret, err := base64.StdEncoding.DecodeString(credentials.APISecret)
+	if err != nil {
+		return fmt.Errorf("%w: API secret is not base64", ErrInvalidCredentials)
+	}
+
+	nonce := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	form := url.Values{}
+	form.Set("nonce", nonce)
+	postData := form.Encode()
+
+	digest := sha256.Sum256([]byte(nonce + postData))
+	signature := hmacSHA512(secret, append([]byte(path), digest[:]...))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, strings.NewReader(postData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("API-Key", credentials.APIKey)
+	req.Header.Set("API-Sign", base64.StdEncoding.EncodeToString(signature))
+
+	return c.do(req, out)
+}
+
+func (c *KrakenConnector) do(req *http.Request, out interface{}) error {
+	var resp krakenResponse
+	if err := doJSON(c.client, req, &resp); err != nil {
+		return err
+	}
+
+	if len(resp.Error) > 0 {
+		message := strings.Join(resp.Error, ", ")
+		switch {
+		case strings.HasPrefix(resp.Error[0], "EAPI:Invalid key"), strings.HasPrefix(resp.Error[0], "EAPI:Invalid signature"):
+			return fmt.Errorf("%w: %s", ErrInvalidCredentials, message)
+		case resp.Error[0] == errKrakenPermissionDenied.Error():
+			return errKrakenPermissionDenied
+		default:
+			return fmt.Errorf("kraken error: %s", message)
+		}
+	}
+
+	if err := json.Unmarshal(resp.Result, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// normalizeKrakenAsset maps Kraken asset codes such as XXBT or ZUSD to the common symbols
+func normalizeKrakenAsset(asset string) string {
+	if symbol, ok := krakenAssets[asset]; ok {
+		return symbol
+	}
+	return asset
+}
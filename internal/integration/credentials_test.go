@@ -0,0 +1,400 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"tiris-backend/internal/database"
+	"tiris-backend/internal/models"
+	"tiris-backend/pkg/security"
+	testconfig "tiris-backend/test/config"
+	"tiris-backend/test/helpers"
+
+	"github.com/golang-migrate/migrate/v4"
+	migrationpostgres "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+// credentialMigrationVersion is the migration that adds the credential hash columns
+const credentialMigrationVersion = 15
+
+// CredentialsTestSuite runs the credential encryption and key rotation of the migrate and
+// rotate-keys commands against a database built by the SQL migrations
+type CredentialsTestSuite struct {
+	suite.Suite
+	cfg         *testconfig.TestConfig
+	helper      *helpers.DatabaseTestHelper
+	db          *gorm.DB
+	keyring     *security.Keyring
+	credentials *models.ExchangeBindingManager
+	userID      uuid.UUID
+}
+
+// SetupSuite creates and migrates a database of its own
+func (suite *CredentialsTestSuite) SetupSuite() {
+	if testing.Short() {
+		suite.T().Skip("Skipping credential tests in short mode")
+	}
+
+	suite.cfg = testconfig.LoadTestConfig()
+	suite.cfg.Database.DBName = fmt.Sprintf("tiris_credentials_test_%d", time.Now().UnixNano())
+	// The database is dropped when the suite ends
+	suite.cfg.Test.DatabaseCleanup = false
+	require.NoError(suite.T(), helpers.CreateTestDatabase(suite.cfg, suite.cfg.Database.DBName))
+
+	suite.helper = helpers.NewDatabaseTestHelper(suite.T(), suite.cfg)
+	require.NoError(suite.T(), suite.helper.RunMigrations(suite.T()), "Failed to run migrations")
+	suite.db = suite.helper.DB
+
+	keyring, err := security.NewKeyring(map[int]string{1: suite.cfg.Security.MasterKey}, 1)
+	require.NoError(suite.T(), err)
+	suite.keyring = keyring
+	suite.credentials = models.NewExchangeBindingManager(keyring, suite.cfg.Security.SigningKey)
+
+	user := helpers.NewUserFactory().Build()
+	require.NoError(suite.T(), suite.db.Create(user).Error)
+	suite.userID = user.ID
+}
+
+// SetupTest removes the exchange bindings and OAuth tokens of the previous test
+func (suite *CredentialsTestSuite) SetupTest() {
+	require.NoError(suite.T(), suite.db.Exec("DELETE FROM exchange_bindings WHERE user_id = ?", suite.userID).Error)
+	require.NoError(suite.T(), suite.db.Exec("DELETE FROM oauth_tokens WHERE user_id = ?", suite.userID).Error)
+}
+
+// TearDownSuite drops the database of the suite
+func (suite *CredentialsTestSuite) TearDownSuite() {
+	if suite.db == nil {
+		return
+	}
+	if sqlDB, err := suite.db.DB(); err == nil {
+		sqlDB.Close()
+	}
+	helpers.DropTestDatabase(suite.cfg, suite.cfg.Database.DBName)
+}
+
+// migrateTo migrates the database up or down to a version
+func (suite *CredentialsTestSuite) migrateTo(version uint) {
+	driver, err := migrationpostgres.WithInstance(suite.helper.SqlDB, &migrationpostgres.Config{})
+	require.NoError(suite.T(), err)
+	// Closing the migrate instance would close the database of the suite
+	m, err := migrate.NewWithDatabaseInstance("file://../../migrations", "postgres", driver)
+	require.NoError(suite.T(), err)
+
+	if err := m.Migrate(version); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		require.NoError(suite.T(), err)
+	}
+}
+
+// migrateUp applies all migrations
+func (suite *CredentialsTestSuite) migrateUp() {
+	require.NoError(suite.T(), suite.helper.RunMigrations(suite.T()))
+}
+
+// insertBinding stores a private exchange binding of the user with the credentials as given,
+// bypassing the encryption of the repository
+func (suite *CredentialsTestSuite) insertBinding(apiKey, apiSecret string) string {
+	var id string
+	err := suite.db.Raw(`
+		INSERT INTO exchange_bindings (user_id, name, exchange, type, api_key, api_secret, status)
+		VALUES (?, ?, 'binance', 'private', ?, ?, 'active')
+		RETURNING id`, suite.userID, "binding-"+uuid.NewString()[:8], apiKey, apiSecret).
+		Scan(&id).Error
+	require.NoError(suite.T(), err)
+	return id
+}
+
+// storedCredentials is the credential part of an exchange binding as stored
+type storedCredentials struct {
+	APIKey        string
+	APISecret     string
+	APIKeyHash    string
+	APISecretHash string
+	MaskedAPIKey  string
+}
+
+// credentialsOf reads the stored credentials of an exchange binding
+func (suite *CredentialsTestSuite) credentialsOf(id string) storedCredentials {
+	var stored storedCredentials
+	err := suite.db.Raw(`
+		SELECT COALESCE(api_key, '') AS api_key, COALESCE(api_secret, '') AS api_secret,
+			api_key_hash, api_secret_hash, masked_api_key
+		FROM exchange_bindings WHERE id = ?`, id).
+		Scan(&stored).Error
+	require.NoError(suite.T(), err)
+	return stored
+}
+
+// Test the encryption of plaintext credentials by `migrate up` and its rollback
+func (suite *CredentialsTestSuite) TestExchangeBindingCredentials() {
+	plaintextID := suite.insertBinding("plain-api-key-0001", "plain-api-secret-0001")
+	emptyID := suite.insertBinding("", "")
+	var encrypted storedCredentials
+
+	suite.T().Run("encrypts_plaintext_rows", func(t *testing.T) {
+		count, err := database.EncryptExchangeBindingCredentials(suite.db, suite.credentials)
+
+		require.NoError(t, err)
+		// The public bindings seeded by the migrations and the binding without credentials are skipped
+		assert.Equal(t, 1, count)
+
+		encrypted = suite.credentialsOf(plaintextID)
+		assert.True(t, suite.keyring.IsActive(encrypted.APIKey), encrypted.APIKey)
+		assert.True(t, suite.keyring.IsActive(encrypted.APISecret), encrypted.APISecret)
+		assert.Equal(t, suite.credentials.HashCredential("plain-api-key-0001"), encrypted.APIKeyHash)
+		assert.Equal(t, suite.credentials.HashCredential("plain-api-secret-0001"), encrypted.APISecretHash)
+		assert.Equal(t, models.MaskAPIKey("plain-api-key-0001"), encrypted.MaskedAPIKey)
+
+		apiKey, apiSecret, err := suite.credentials.GetAPICredentials(&models.ExchangeBinding{APIKey: encrypted.APIKey, APISecret: encrypted.APISecret})
+		require.NoError(t, err)
+		assert.Equal(t, "plain-api-key-0001", apiKey)
+		assert.Equal(t, "plain-api-secret-0001", apiSecret)
+
+		assert.Equal(t, storedCredentials{}, suite.credentialsOf(emptyID))
+	})
+
+	suite.T().Run("second_run_keeps_ciphertexts", func(t *testing.T) {
+		count, err := database.EncryptExchangeBindingCredentials(suite.db, suite.credentials)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+		assert.Equal(t, encrypted, suite.credentialsOf(plaintextID))
+		assert.Equal(t, storedCredentials{}, suite.credentialsOf(emptyID))
+	})
+
+	suite.T().Run("decrypt_before_rollback", func(t *testing.T) {
+		count, err := database.DecryptExchangeBindingCredentials(suite.db, suite.credentials)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, storedCredentials{APIKey: "plain-api-key-0001", APISecret: "plain-api-secret-0001"}, suite.credentialsOf(plaintextID))
+		assert.Equal(t, storedCredentials{}, suite.credentialsOf(emptyID))
+
+		// Rolling back the migration drops the hash columns and keeps the plaintext
+		suite.migrateTo(credentialMigrationVersion - 1)
+		var hashColumns int64
+		require.NoError(t, suite.db.Raw(`
+			SELECT COUNT(*) FROM information_schema.columns
+			WHERE table_name = 'exchange_bindings' AND column_name IN ('api_key_hash', 'api_secret_hash', 'masked_api_key')`).
+			Scan(&hashColumns).Error)
+		assert.Equal(t, int64(0), hashColumns)
+
+		var apiKey, apiSecret string
+		row := suite.db.Raw("SELECT api_key, api_secret FROM exchange_bindings WHERE id = ?", plaintextID).Row()
+		require.NoError(t, row.Scan(&apiKey, &apiSecret))
+		assert.Equal(t, "plain-api-key-0001", apiKey)
+		assert.Equal(t, "plain-api-secret-0001", apiSecret)
+
+		// Migrating up again encrypts the credentials once more
+		suite.migrateUp()
+		count, err = database.EncryptExchangeBindingCredentials(suite.db, suite.credentials)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.True(t, suite.keyring.IsActive(suite.credentialsOf(plaintextID).APIKey))
+	})
+}
+
+// insertOAuthToken stores an OAuth token of the user with the tokens as given
+func (suite *CredentialsTestSuite) insertOAuthToken(accessToken string, refreshToken *string) string {
+	var id string
+	err := suite.db.Raw(`
+		INSERT INTO oauth_tokens (user_id, provider, provider_user_id, access_token, refresh_token)
+		VALUES (?, 'google', ?, ?, ?)
+		RETURNING id`, suite.userID, uuid.NewString(), accessToken, refreshToken).
+		Scan(&id).Error
+	require.NoError(suite.T(), err)
+	return id
+}
+
+// oauthTokensOf reads the stored tokens of an OAuth token
+func (suite *CredentialsTestSuite) oauthTokensOf(id string) (accessToken, refreshToken string) {
+	row := suite.db.Raw("SELECT access_token, COALESCE(refresh_token, '') FROM oauth_tokens WHERE id = ?", id).Row()
+	require.NoError(suite.T(), row.Scan(&accessToken, &refreshToken))
+	return accessToken, refreshToken
+}
+
+// legacyCiphertext encrypts a value with the first master key the way it was stored before
+// ciphertexts carried a key id
+func (suite *CredentialsTestSuite) legacyCiphertext(plaintext string) string {
+	em, err := security.NewEncryptionManager(suite.cfg.Security.MasterKey)
+	require.NoError(suite.T(), err)
+	ciphertext, err := em.Encrypt(plaintext)
+	require.NoError(suite.T(), err)
+	require.False(suite.T(), security.HasKeyID(ciphertext))
+	return ciphertext
+}
+
+// encrypt encrypts a value with the active key of a keyring
+func (suite *CredentialsTestSuite) encrypt(keyring *security.Keyring, plaintext string) string {
+	ciphertext, err := keyring.Encrypt(plaintext)
+	require.NoError(suite.T(), err)
+	return ciphertext
+}
+
+// decrypt decrypts a ciphertext with a keyring
+func (suite *CredentialsTestSuite) decrypt(keyring *security.Keyring, ciphertext string) string {
+	plaintext, err := keyring.Decrypt(ciphertext)
+	require.NoError(suite.T(), err)
+	return plaintext
+}
+
+// beforeUpdate runs fn before every update of a table until the test ends
+func (suite *CredentialsTestSuite) beforeUpdate(t *testing.T, table string, fn func(db *gorm.DB)) {
+	name := "test:" + t.Name()
+	err := suite.db.Callback().Update().Before("gorm:update").Register(name, func(db *gorm.DB) {
+		if db.Statement.Table == table {
+			fn(db)
+		}
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		suite.db.Callback().Update().Remove(name)
+	})
+}
+
+// Test the key rotation of the rotate-keys command
+func (suite *CredentialsTestSuite) TestReencryptSecrets() {
+	rotated, err := security.NewKeyring(map[int]string{
+		1: suite.cfg.Security.MasterKey,
+		2: "integration-test-rotated-master-key-32-chars",
+	}, 2)
+	require.NoError(suite.T(), err)
+
+	suite.T().Run("legacy_binding_ciphertexts", func(t *testing.T) {
+		legacyID := suite.insertBinding(suite.legacyCiphertext("legacy-api-key"), suite.legacyCiphertext("legacy-api-secret"))
+		require.NoError(t, suite.db.Exec("UPDATE exchange_bindings SET api_key_hash = ?, api_secret_hash = ? WHERE id = ?",
+			suite.credentials.HashCredential("legacy-api-key"), suite.credentials.HashCredential("legacy-api-secret"), legacyID).Error)
+		// A binding without hashes still holds plaintext and is left to the credential encryption
+		plaintextID := suite.insertBinding("plain-api-key-0002", "plain-api-secret-0002")
+
+		bindings, _, err := database.CountStaleSecrets(suite.db, rotated)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), bindings)
+
+		count, err := database.ReencryptExchangeBindingCredentials(suite.db, rotated, 0, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		stored := suite.credentialsOf(legacyID)
+		assert.True(t, rotated.IsActive(stored.APIKey), stored.APIKey)
+		assert.True(t, rotated.IsActive(stored.APISecret), stored.APISecret)
+		assert.Equal(t, "legacy-api-key", suite.decrypt(rotated, stored.APIKey))
+		assert.Equal(t, "legacy-api-secret", suite.decrypt(rotated, stored.APISecret))
+		assert.Equal(t, "plain-api-key-0002", suite.credentialsOf(plaintextID).APIKey)
+	})
+
+	suite.T().Run("plaintext_oauth_tokens", func(t *testing.T) {
+		plaintextID := suite.insertOAuthToken("plain-access-token", nil)
+		refreshToken := suite.encrypt(suite.keyring, "old-refresh-token")
+		encryptedID := suite.insertOAuthToken(suite.encrypt(suite.keyring, "old-access-token"), &refreshToken)
+
+		_, tokens, err := database.CountStaleSecrets(suite.db, rotated)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), tokens)
+
+		count, err := database.ReencryptOAuthTokens(suite.db, rotated, 0, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+		accessToken, refreshToken := suite.oauthTokensOf(plaintextID)
+		assert.True(t, rotated.IsActive(accessToken), accessToken)
+		assert.Equal(t, "plain-access-token", suite.decrypt(rotated, accessToken))
+		assert.Empty(t, refreshToken)
+
+		accessToken, refreshToken = suite.oauthTokensOf(encryptedID)
+		assert.Equal(t, "old-access-token", suite.decrypt(rotated, accessToken))
+		assert.True(t, rotated.IsActive(refreshToken), refreshToken)
+		assert.Equal(t, "old-refresh-token", suite.decrypt(rotated, refreshToken))
+	})
+
+	suite.T().Run("row_ids_scan_as_strings", func(t *testing.T) {
+		id := suite.insertOAuthToken("plain-access-token", nil)
+
+		// The rotation loads its rows into maps and matches them by the scanned id
+		var rows []map[string]interface{}
+		require.NoError(t, suite.db.Table("oauth_tokens").Select("id").Where("id = ?", id).Find(&rows).Error)
+		require.Len(t, rows, 1)
+		assert.Equal(t, id, rows[0]["id"])
+
+		count, err := database.ReencryptOAuthTokens(suite.db, rotated, 0, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		accessToken, _ := suite.oauthTokensOf(id)
+		assert.True(t, rotated.IsActive(accessToken), accessToken)
+	})
+
+	suite.T().Run("concurrent_change_is_kept", func(t *testing.T) {
+		id := suite.insertOAuthToken(suite.encrypt(suite.keyring, "stale-access-token"), nil)
+		changed := suite.encrypt(rotated, "changed-access-token")
+
+		written := false
+		suite.beforeUpdate(t, "oauth_tokens", func(db *gorm.DB) {
+			if written {
+				return
+			}
+			written = true
+			// The application stores a new token between the read and the update of the rotation
+			db.AddError(db.Session(&gorm.Session{NewDB: true}).
+				Exec("UPDATE oauth_tokens SET access_token = ? WHERE id = ?", changed, id).Error)
+		})
+
+		count, err := database.ReencryptOAuthTokens(suite.db, rotated, 0, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		accessToken, _ := suite.oauthTokensOf(id)
+		assert.Equal(t, changed, accessToken)
+	})
+
+	suite.T().Run("resumes_after_interrupted_batch", func(t *testing.T) {
+		ids := []string{
+			suite.insertOAuthToken(suite.encrypt(suite.keyring, "access-token-1"), nil),
+			suite.insertOAuthToken(suite.encrypt(suite.keyring, "access-token-2"), nil),
+			suite.insertOAuthToken(suite.encrypt(suite.keyring, "access-token-3"), nil),
+		}
+
+		t.Run("interrupted", func(t *testing.T) {
+			updates := 0
+			suite.beforeUpdate(t, "oauth_tokens", func(db *gorm.DB) {
+				updates++
+				if updates == 2 {
+					db.AddError(errors.New("rotation interrupted"))
+				}
+			})
+
+			var progress []int
+			count, err := database.ReencryptOAuthTokens(suite.db, rotated, 1, func(done int) {
+				progress = append(progress, done)
+			})
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "rotation interrupted")
+			// The first batch is committed, the second rolled back
+			assert.Equal(t, 1, count)
+			assert.Equal(t, []int{1}, progress)
+		})
+
+		_, tokens, err := database.CountStaleSecrets(suite.db, rotated)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), tokens)
+
+		count, err := database.ReencryptOAuthTokens(suite.db, rotated, 1, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+		for i, id := range ids {
+			accessToken, _ := suite.oauthTokensOf(id)
+			assert.Equal(t, fmt.Sprintf("access-token-%d", i+1), suite.decrypt(rotated, accessToken))
+		}
+	})
+}
+
+func TestCredentialsSuite(t *testing.T) {
+	suite.Run(t, new(CredentialsTestSuite))
+}
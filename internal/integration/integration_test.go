@@ -66,6 +66,14 @@ func (suite *IntegrationTestSuite) SetupSuite() {
 			JWTExpiration:     3600,  // 1 hour
 			RefreshExpiration: 86400, // 24 hours
 		},
+		Security: config.SecurityConfig{
+			MasterKeys:        map[int]string{1: "test-master-key-integration-testing-32-chars"},
+			ActiveMasterKeyID: 1,
+			SigningKey:        "test-signing-key-integration-testing-32-chars",
+		},
+		Exchange: config.ExchangeConfig{
+			Connectors: config.ExchangeConnectorsFake,
+		},
 		OAuth: config.OAuthConfig{
 			Google: config.GoogleOAuthConfig{
 				ClientID:     "test-google-client-id",
@@ -109,7 +117,7 @@ func (suite *IntegrationTestSuite) SetupSuite() {
 	suite.repos = repositories.NewRepositories(suite.db.DB)
 
 	// Initialize NATS (allow failure in test environment)
-	suite.nats, _ = nats.NewManager(suite.cfg.NATS, suite.repos)
+	suite.nats, _ = nats.NewManager(suite.cfg.NATS, suite.repos, suite.db.DB)
 
 	// Initialize API server
 	suite.server = api.NewServer(suite.cfg, suite.repos, suite.db, suite.nats)
@@ -657,7 +665,7 @@ func (suite *IntegrationTestSuite) TestSubAccountManagement() {
 		subAccountID = subAccountData["id"].(string)
 		assert.Equal(t, "Main Trading Account", subAccountData["name"])
 		assert.Equal(t, "BTC", subAccountData["symbol"])
-		assert.Equal(t, float64(0), subAccountData["balance"])
+		assert.Equal(t, "0", subAccountData["balance"])
 	})
 
 	suite.T().Run("get_user_sub_accounts", func(t *testing.T) {
@@ -702,7 +710,7 @@ func (suite *IntegrationTestSuite) TestSubAccountManagement() {
 		// assert.True(t, response.Success)
 
 		// subAccountData := response.Data.(map[string]interface{})
-		// assert.Equal(t, 1500.75, subAccountData["balance"])
+		// assert.Equal(t, "1500.75", subAccountData["balance"])
 	// })
 
 	suite.T().Run("get_sub_accounts_by_symbol", func(t *testing.T) {
@@ -0,0 +1,420 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"tiris-backend/internal/models"
+	"tiris-backend/internal/repositories"
+	"tiris-backend/internal/services"
+	testconfig "tiris-backend/test/config"
+	"tiris-backend/test/helpers"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+// LedgerTestSuite runs the trading log services against a database built by the SQL migrations, so
+// balance updates go through update_sub_account_balance and rollbacks behave as in production
+type LedgerTestSuite struct {
+	suite.Suite
+	cfg               *testconfig.TestConfig
+	db                *gorm.DB
+	repos             *repositories.Repositories
+	tradingLogService *services.TradingLogService
+	userID            uuid.UUID
+}
+
+// SetupSuite creates and migrates a database of its own
+func (suite *LedgerTestSuite) SetupSuite() {
+	if testing.Short() {
+		suite.T().Skip("Skipping ledger tests in short mode")
+	}
+
+	suite.cfg = testconfig.LoadTestConfig()
+	suite.cfg.Database.DBName = fmt.Sprintf("tiris_ledger_test_%d", time.Now().UnixNano())
+	// The database is dropped when the suite ends
+	suite.cfg.Test.DatabaseCleanup = false
+	require.NoError(suite.T(), helpers.CreateTestDatabase(suite.cfg, suite.cfg.Database.DBName))
+
+	helper := helpers.NewDatabaseTestHelper(suite.T(), suite.cfg)
+	require.NoError(suite.T(), helper.RunMigrations(suite.T()), "Failed to run migrations")
+
+	suite.db = helper.DB
+	suite.repos = repositories.NewRepositories(suite.db)
+	suite.tradingLogService = services.NewTradingLogService(suite.repos, suite.db)
+
+	user := helpers.NewUserFactory().Build()
+	require.NoError(suite.T(), suite.repos.User.Create(context.Background(), user))
+	suite.userID = user.ID
+}
+
+// TearDownSuite drops the database of the suite
+func (suite *LedgerTestSuite) TearDownSuite() {
+	if suite.db == nil {
+		return
+	}
+	if sqlDB, err := suite.db.DB(); err == nil {
+		sqlDB.Close()
+	}
+	helpers.DropTestDatabase(suite.cfg, suite.cfg.Database.DBName)
+}
+
+// createTrading creates a trading of the given type on a new exchange binding of the user
+func (suite *LedgerTestSuite) createTrading(tradingType string) *models.Trading {
+	binding := helpers.NewExchangeBindingFactory().WithUserID(suite.userID)
+	require.NoError(suite.T(), suite.repos.ExchangeBinding.Create(context.Background(), binding))
+
+	trading := helpers.NewTradingFactory().WithUserAndBinding(suite.userID, binding.ID)
+	trading.Type = tradingType
+	require.NoError(suite.T(), suite.repos.Trading.Create(context.Background(), trading))
+	return trading
+}
+
+// createSubAccount creates an empty sub-account of the trading
+func (suite *LedgerTestSuite) createSubAccount(trading *models.Trading, symbol string) *models.SubAccount {
+	subAccount := helpers.NewSubAccountFactory().WithUserAndTrading(suite.userID, trading.ID)
+	subAccount.Symbol = symbol
+	subAccount.Balance = decimal.Zero
+	require.NoError(suite.T(), suite.repos.SubAccount.Create(context.Background(), subAccount))
+	return subAccount
+}
+
+// fundRequest builds a deposit or withdraw trading log request
+func fundRequest(trading *models.Trading, logType string, subAccount *models.SubAccount, amount string) services.CreateTradingLogRequest {
+	return services.CreateTradingLogRequest{
+		TradingID: trading.ID,
+		Type:      logType,
+		Source:    "manual",
+		Message:   logType + " " + amount + " " + subAccount.Symbol,
+		Info: map[string]interface{}{
+			"account_id": subAccount.ID.String(),
+			"amount":     amount,
+			"currency":   subAccount.Symbol,
+		},
+	}
+}
+
+// createTradingLog creates a trading log that must succeed
+func (suite *LedgerTestSuite) createTradingLog(req services.CreateTradingLogRequest) *services.TradingLogResponse {
+	response, err := suite.tradingLogService.CreateTradingLog(context.Background(), suite.userID, &req)
+	require.NoError(suite.T(), err)
+	return response
+}
+
+// deposit funds a sub-account through a deposit trading log
+func (suite *LedgerTestSuite) deposit(trading *models.Trading, subAccount *models.SubAccount, amount string) *services.TradingLogResponse {
+	return suite.createTradingLog(fundRequest(trading, "deposit", subAccount, amount))
+}
+
+// tradeRequest builds a long or short trading log request trading ETH for USDT
+func tradeRequest(trading *models.Trading, logType string, eth, usdt *models.SubAccount, volume, price string, eventTime *time.Time) services.CreateTradingLogRequest {
+	return services.CreateTradingLogRequest{
+		TradingID: trading.ID,
+		EventTime: eventTime,
+		Type:      logType,
+		Source:    "bot",
+		Message:   logType + " " + volume + " ETH @ " + price,
+		Info: map[string]interface{}{
+			"stock_account_id":    eth.ID.String(),
+			"currency_account_id": usdt.ID.String(),
+			"price":               price,
+			"volume":              volume,
+			"stock":               "ETH",
+			"currency":            "USDT",
+			"fee":                 "0",
+		},
+	}
+}
+
+// subAccountOf reloads a sub-account
+func (suite *LedgerTestSuite) subAccountOf(subAccount *models.SubAccount) *models.SubAccount {
+	stored, err := suite.repos.SubAccount.GetByID(context.Background(), subAccount.ID)
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), stored)
+	return stored
+}
+
+// balanceOf reloads the balance of a sub-account
+func (suite *LedgerTestSuite) balanceOf(subAccount *models.SubAccount) decimal.Decimal {
+	stored, err := suite.repos.SubAccount.GetByID(context.Background(), subAccount.ID)
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), stored)
+	return stored.Balance
+}
+
+// countRows counts the rows of a table that belong to the trading
+func (suite *LedgerTestSuite) countRows(table string, trading *models.Trading) int64 {
+	var count int64
+	require.NoError(suite.T(), suite.db.Table(table).Where("trading_id = ?", trading.ID).Count(&count).Error)
+	return count
+}
+
+// Test that a failing entry rolls back the whole batch unless ContinueOnError is set
+func (suite *LedgerTestSuite) TestBatchCreateTradingLogs() {
+	suite.T().Run("all_or_nothing", func(t *testing.T) {
+		trading := suite.createTrading(models.TradingTypeReal)
+		usdt := suite.createSubAccount(trading, "USDT")
+		suite.deposit(trading, usdt, "1000")
+
+		req := &services.BatchCreateTradingLogsRequest{Logs: []services.CreateTradingLogRequest{
+			fundRequest(trading, "deposit", usdt, "100"),
+			fundRequest(trading, "withdraw", usdt, "5000"),
+			fundRequest(trading, "deposit", usdt, "50"),
+		}}
+
+		response, err := suite.tradingLogService.BatchCreateTradingLogs(context.Background(), suite.userID, req)
+
+		require.Error(t, err)
+		assert.Nil(t, response)
+		assert.Contains(t, err.Error(), "batch entry 1 failed")
+		assert.True(t, suite.balanceOf(usdt).Equal(decimal.NewFromInt(1000)), suite.balanceOf(usdt).String())
+		assert.Equal(t, int64(1), suite.countRows("transactions", trading))
+		assert.Equal(t, int64(1), suite.countRows("trading_logs", trading))
+	})
+
+	suite.T().Run("continue_on_error", func(t *testing.T) {
+		trading := suite.createTrading(models.TradingTypeReal)
+		usdt := suite.createSubAccount(trading, "USDT")
+		suite.deposit(trading, usdt, "1000")
+
+		req := &services.BatchCreateTradingLogsRequest{
+			Logs: []services.CreateTradingLogRequest{
+				fundRequest(trading, "deposit", usdt, "100"),
+				fundRequest(trading, "withdraw", usdt, "5000"),
+				fundRequest(trading, "deposit", usdt, "50"),
+			},
+			ContinueOnError: true,
+		}
+
+		response, err := suite.tradingLogService.BatchCreateTradingLogs(context.Background(), suite.userID, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, response.Total)
+		assert.Equal(t, 2, response.Succeeded)
+		assert.Equal(t, 1, response.Failed)
+		require.Len(t, response.Results, 3)
+		assert.True(t, response.Results[0].Success)
+		assert.False(t, response.Results[1].Success)
+		assert.Contains(t, response.Results[1].Error, "insufficient balance")
+		assert.True(t, response.Results[2].Success)
+
+		// The entries before and after the failed one are committed, the failed one left nothing behind
+		assert.True(t, suite.balanceOf(usdt).Equal(decimal.NewFromInt(1150)), suite.balanceOf(usdt).String())
+		assert.Equal(t, int64(3), suite.countRows("transactions", trading))
+		assert.Equal(t, int64(3), suite.countRows("trading_logs", trading))
+	})
+}
+
+// Test that an amendment reverses the original log and posts its correction in one transaction
+func (suite *LedgerTestSuite) TestAmendTradingLog() {
+	suite.T().Run("reverse_and_post_correction", func(t *testing.T) {
+		trading := suite.createTrading(models.TradingTypeReal)
+		usdt := suite.createSubAccount(trading, "USDT")
+		original := suite.deposit(trading, usdt, "1000")
+
+		req := &services.AmendTradingLogRequest{Info: map[string]interface{}{
+			"account_id": usdt.ID.String(),
+			"amount":     "400",
+			"currency":   "USDT",
+		}}
+
+		correction, err := suite.tradingLogService.AmendTradingLog(context.Background(), suite.userID, original.ID, req)
+
+		require.NoError(t, err)
+		assert.True(t, suite.balanceOf(usdt).Equal(decimal.NewFromInt(400)), suite.balanceOf(usdt).String())
+
+		// The original deposit, its reversal and the corrected deposit; the last two share the
+		// timestamp of the amendment transaction
+		var transactions []models.Transaction
+		require.NoError(t, suite.db.Where("sub_account_id = ?", usdt.ID).Order("timestamp ASC, closing_balance ASC").Find(&transactions).Error)
+		require.Len(t, transactions, 3)
+		assert.Equal(t, "deposit", transactions[0].Reason)
+		assert.Equal(t, services.ReversalReason, transactions[1].Reason)
+		assert.Equal(t, "debit", transactions[1].Direction)
+		assert.True(t, transactions[1].ClosingBalance.IsZero())
+		assert.Equal(t, "deposit", transactions[2].Reason)
+		assert.True(t, transactions[2].ClosingBalance.Equal(decimal.NewFromInt(400)))
+
+		// The original and its correction are linked both ways
+		require.NotNil(t, correction.Amends)
+		assert.Equal(t, original.ID, *correction.Amends)
+		amended, err := suite.tradingLogService.GetTradingLog(context.Background(), suite.userID, original.ID)
+		require.NoError(t, err)
+		require.NotNil(t, amended.AmendedBy)
+		assert.Equal(t, correction.ID, *amended.AmendedBy)
+	})
+
+	suite.T().Run("backdated_buy", func(t *testing.T) {
+		trading := suite.createTrading(models.TradingTypeReal)
+		eth := suite.createSubAccount(trading, "ETH")
+		usdt := suite.createSubAccount(trading, "USDT")
+		fundedAt := time.Now().UTC().Add(-2 * time.Hour).Truncate(time.Microsecond)
+		funding := fundRequest(trading, "deposit", usdt, "10000")
+		funding.EventTime = &fundedAt
+		suite.createTradingLog(funding)
+		eventTime := fundedAt.Add(time.Hour)
+		original := suite.createTradingLog(tradeRequest(trading, "long", eth, usdt, "2", "3000", &eventTime))
+
+		req := &services.AmendTradingLogRequest{Info: map[string]interface{}{
+			"stock_account_id":    eth.ID.String(),
+			"currency_account_id": usdt.ID.String(),
+			"price":               "3100",
+			"volume":              "2",
+			"stock":               "ETH",
+			"currency":            "USDT",
+			"fee":                 "0",
+		}}
+
+		// Replaying the original and its correction together would take 12200 out of 10000
+		correction, err := suite.tradingLogService.AmendTradingLog(context.Background(), suite.userID, original.ID, req)
+
+		require.NoError(t, err)
+		require.NotNil(t, correction.Amends)
+		assert.True(t, suite.balanceOf(usdt).Equal(decimal.NewFromInt(3800)), suite.balanceOf(usdt).String())
+		storedETH := suite.subAccountOf(eth)
+		assert.True(t, storedETH.Balance.Equal(decimal.NewFromInt(2)), storedETH.Balance.String())
+		assert.True(t, storedETH.AvgCost.Equal(decimal.NewFromInt(3100)), storedETH.AvgCost.String())
+
+		// The original, its reversal and the correction are all placed at the event time of the buy
+		var transactions []models.Transaction
+		require.NoError(t, suite.db.Where("sub_account_id = ?", usdt.ID).Order("timestamp ASC").Find(&transactions).Error)
+		require.Len(t, transactions, 4)
+		assert.True(t, transactions[0].Timestamp.Equal(fundedAt), transactions[0].Timestamp.String())
+		closings := make([]string, 0, len(transactions))
+		for _, transaction := range transactions[1:] {
+			assert.True(t, transaction.Timestamp.Equal(eventTime), transaction.Timestamp.String())
+			closings = append(closings, transaction.ClosingBalance.StringFixed(0))
+		}
+		assert.ElementsMatch(t, []string{"4000", "10000", "3800"}, closings)
+	})
+
+	suite.T().Run("already_amended", func(t *testing.T) {
+		trading := suite.createTrading(models.TradingTypeReal)
+		usdt := suite.createSubAccount(trading, "USDT")
+		original := suite.deposit(trading, usdt, "1000")
+		req := &services.AmendTradingLogRequest{Info: map[string]interface{}{
+			"account_id": usdt.ID.String(),
+			"amount":     "400",
+			"currency":   "USDT",
+		}}
+		_, err := suite.tradingLogService.AmendTradingLog(context.Background(), suite.userID, original.ID, req)
+		require.NoError(t, err)
+
+		// The handler answers 409 Conflict to this error
+		_, err = suite.tradingLogService.AmendTradingLog(context.Background(), suite.userID, original.ID, req)
+
+		require.Error(t, err)
+		assert.Equal(t, "trading log already amended", err.Error())
+		assert.True(t, suite.balanceOf(usdt).Equal(decimal.NewFromInt(400)), suite.balanceOf(usdt).String())
+		assert.Equal(t, int64(3), suite.countRows("transactions", trading))
+	})
+
+	suite.T().Run("insufficient_balance_to_reverse", func(t *testing.T) {
+		trading := suite.createTrading(models.TradingTypeReal)
+		usdt := suite.createSubAccount(trading, "USDT")
+		original := suite.deposit(trading, usdt, "1000")
+		suite.createTradingLog(fundRequest(trading, "withdraw", usdt, "800"))
+
+		req := &services.AmendTradingLogRequest{Info: map[string]interface{}{
+			"account_id": usdt.ID.String(),
+			"amount":     "500",
+			"currency":   "USDT",
+		}}
+
+		_, err := suite.tradingLogService.AmendTradingLog(context.Background(), suite.userID, original.ID, req)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "insufficient balance to reverse trading log")
+		assert.True(t, suite.balanceOf(usdt).Equal(decimal.NewFromInt(200)), suite.balanceOf(usdt).String())
+		assert.Equal(t, int64(2), suite.countRows("transactions", trading))
+		assert.Equal(t, int64(2), suite.countRows("trading_logs", trading))
+
+		unchanged, err := suite.tradingLogService.GetTradingLog(context.Background(), suite.userID, original.ID)
+		require.NoError(t, err)
+		assert.Nil(t, unchanged.AmendedBy)
+	})
+}
+
+// Test that a trading log is reversed once however its deletions and amendments overlap
+func (suite *LedgerTestSuite) TestDeleteTradingLog() {
+	suite.T().Run("concurrent_deletions", func(t *testing.T) {
+		trading := suite.createTrading(models.TradingTypeReal)
+		usdt := suite.createSubAccount(trading, "USDT")
+		suite.deposit(trading, usdt, "1000")
+		deposit := suite.deposit(trading, usdt, "300")
+
+		errs := make(chan error, 2)
+		for i := 0; i < 2; i++ {
+			go func() {
+				errs <- suite.tradingLogService.DeleteTradingLog(context.Background(), suite.userID, deposit.ID)
+			}()
+		}
+		first, second := <-errs, <-errs
+
+		// One deletion reverses the deposit, the other finds it gone
+		if first != nil {
+			first, second = second, first
+		}
+		require.NoError(t, first)
+		require.Error(t, second)
+		assert.Equal(t, "trading log not found", second.Error())
+		assert.True(t, suite.balanceOf(usdt).Equal(decimal.NewFromInt(1000)), suite.balanceOf(usdt).String())
+		assert.Equal(t, int64(3), suite.countRows("transactions", trading))
+	})
+
+	suite.T().Run("amended_log", func(t *testing.T) {
+		trading := suite.createTrading(models.TradingTypeReal)
+		usdt := suite.createSubAccount(trading, "USDT")
+		original := suite.deposit(trading, usdt, "1000")
+		req := &services.AmendTradingLogRequest{Info: map[string]interface{}{
+			"account_id": usdt.ID.String(),
+			"amount":     "400",
+			"currency":   "USDT",
+		}}
+		_, err := suite.tradingLogService.AmendTradingLog(context.Background(), suite.userID, original.ID, req)
+		require.NoError(t, err)
+
+		err = suite.tradingLogService.DeleteTradingLog(context.Background(), suite.userID, original.ID)
+
+		require.Error(t, err)
+		assert.Equal(t, "cannot delete an amended trading log", err.Error())
+		assert.True(t, suite.balanceOf(usdt).Equal(decimal.NewFromInt(400)), suite.balanceOf(usdt).String())
+		assert.Equal(t, int64(3), suite.countRows("transactions", trading))
+	})
+}
+
+// Test that a backdated trade refused by the balance replay leaves the ledger untouched
+func (suite *LedgerTestSuite) TestRejectedBackdateRollsBack() {
+	trading := suite.createTrading(models.TradingTypeReal)
+	eth := suite.createSubAccount(trading, "ETH")
+	usdt := suite.createSubAccount(trading, "USDT")
+	suite.deposit(trading, usdt, "1000")
+
+	// The buy is dated before the deposit, when the USDT account was still empty
+	eventTime := time.Now().UTC().Add(-time.Hour)
+	req := tradeRequest(trading, "long", eth, usdt, "0.2", "3000", &eventTime)
+
+	_, err := suite.tradingLogService.CreateTradingLog(context.Background(), suite.userID, &req)
+
+	require.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "would make the balance of sub-account "+usdt.ID.String()+" negative")
+
+	assert.True(suite.T(), suite.balanceOf(usdt).Equal(decimal.NewFromInt(1000)), suite.balanceOf(usdt).String())
+	storedETH := suite.subAccountOf(eth)
+	assert.True(suite.T(), storedETH.Balance.IsZero(), storedETH.Balance.String())
+	assert.True(suite.T(), storedETH.AvgCost.IsZero(), storedETH.AvgCost.String())
+	assert.Equal(suite.T(), int64(1), suite.countRows("transactions", trading))
+	assert.Equal(suite.T(), int64(1), suite.countRows("trading_logs", trading))
+}
+
+// TestLedgerSuite runs the ledger test suite
+func TestLedgerSuite(t *testing.T) {
+	suite.Run(t, new(LedgerTestSuite))
+}
@@ -176,6 +176,46 @@ func TestExchangeBinding_IsPublic(t *testing.T) {
 	}
 }
 
+func TestExchangeBinding_IsVirtual(t *testing.T) {
+	tests := []struct {
+		name     string
+		binding  ExchangeBinding
+		expected bool
+	}{
+		{
+			name: "private_exchange_binding",
+			binding: ExchangeBinding{
+				Exchange: "binance",
+				Type:     "private",
+			},
+			expected: false,
+		},
+		{
+			name: "private_virtual_binding",
+			binding: ExchangeBinding{
+				Exchange: "virtual",
+				Type:     "private",
+			},
+			expected: true,
+		},
+		{
+			name: "public_binding",
+			binding: ExchangeBinding{
+				Exchange: "binance",
+				Type:     "public",
+			},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.binding.IsVirtual()
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
 func TestExchangeBinding_HasCredentials(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -261,6 +301,14 @@ func TestExchangeBinding_GetMaskedAPIKey(t *testing.T) {
 			assert.Equal(t, tt.expected, result)
 		})
 	}
+
+	t.Run("stored_mask_of_encrypted_key", func(t *testing.T) {
+		binding := ExchangeBinding{
+			APIKey:       "c2VjcmV0LWNpcGhlcnRleHQ=",
+			MaskedAPIKey: "abcd...mnop",
+		}
+		assert.Equal(t, "abcd...mnop", binding.GetMaskedAPIKey())
+	})
 }
 
 func TestExchangeBinding_ToResponse(t *testing.T) {
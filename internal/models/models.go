@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
@@ -112,6 +113,12 @@ type ExchangeBinding struct {
 	Status    string     `gorm:"type:varchar(20);default:'active';index" json:"status"`
 	Info      JSON       `gorm:"type:jsonb" json:"info"`
 
+	// Credentials are stored encrypted; the hashes back the duplicate checks and the masked key
+	// is kept for display, so neither needs a decryption.
+	APIKeyHash    string `gorm:"type:varchar(64);not null;default:''" json:"-"`
+	APISecretHash string `gorm:"type:varchar(64);not null;default:''" json:"-"`
+	MaskedAPIKey  string `gorm:"type:varchar(20);not null;default:''" json:"-"`
+
 	CreatedAt time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
@@ -183,20 +190,35 @@ func (eb *ExchangeBinding) IsPublic() bool {
 	return eb.Type == ExchangeBindingTypePublic
 }
 
+// IsVirtual returns true if the binding trades on a virtual exchange. Public bindings are virtual
+// exchanges for simulation and backtesting.
+func (eb *ExchangeBinding) IsVirtual() bool {
+	return eb.Exchange == ExchangeVirtual || eb.IsPublic()
+}
+
 // HasCredentials returns true if the binding has API credentials
 func (eb *ExchangeBinding) HasCredentials() bool {
 	return eb.APIKey != "" && eb.APISecret != ""
 }
 
-// GetMaskedAPIKey returns a masked version of the API key for display
+// GetMaskedAPIKey returns a masked version of the API key for display. Bindings whose credentials
+// are not encrypted yet are masked from the stored API key.
 func (eb *ExchangeBinding) GetMaskedAPIKey() string {
-	if eb.APIKey == "" {
+	if eb.MaskedAPIKey != "" {
+		return eb.MaskedAPIKey
+	}
+	return MaskAPIKey(eb.APIKey)
+}
+
+// MaskAPIKey masks a plaintext API key for display
+func MaskAPIKey(apiKey string) string {
+	if apiKey == "" {
 		return ""
 	}
-	if len(eb.APIKey) <= 8 {
+	if len(apiKey) <= 8 {
 		return "***"
 	}
-	return eb.APIKey[:4] + "..." + eb.APIKey[len(eb.APIKey)-4:]
+	return apiKey[:4] + "..." + apiKey[len(apiKey)-4:]
 }
 
 // ToResponse converts ExchangeBinding to ExchangeBindingResponse
@@ -237,6 +259,9 @@ type Trading struct {
 	Name              string    `gorm:"type:varchar(100);not null" json:"name"`
 	Type              string    `gorm:"type:varchar(50);not null;index" json:"type"`
 	Status            string    `gorm:"type:varchar(20);default:'active';index" json:"status"`
+	// MarginMode lets sub-accounts borrow and go negative; trades must keep the collateral ratio
+	MarginMode        bool            `gorm:"not null;default:false" json:"margin_mode"`
+	CollateralRatio   decimal.Decimal `gorm:"type:decimal(20,8);not null;default:1.5" json:"collateral_ratio" swaggertype:"string"`
 	Info              JSON      `gorm:"type:jsonb" json:"info"`
 
 	CreatedAt time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
@@ -268,7 +293,7 @@ func (t *Trading) Validate() error {
 		return errors.New("exchange binding ID cannot be nil")
 	}
 
-	validTypes := []string{TradingTypeReal, TradingTypeVirtual, TradingTypeBacktest}
+	validTypes := []string{TradingTypeReal, TradingTypeSimulation, TradingTypeBacktest}
 	isValidType := false
 	for _, tradingType := range validTypes {
 		if t.Type == tradingType {
@@ -300,9 +325,9 @@ func (t *Trading) IsReal() bool {
 	return t.Type == TradingTypeReal
 }
 
-// IsVirtual returns true if the trading is virtual
-func (t *Trading) IsVirtual() bool {
-	return t.Type == TradingTypeVirtual
+// IsSimulation returns true if the trading is a simulation
+func (t *Trading) IsSimulation() bool {
+	return t.Type == TradingTypeSimulation
 }
 
 // IsBacktest returns true if the trading is backtest
@@ -310,6 +335,17 @@ func (t *Trading) IsBacktest() bool {
 	return t.Type == TradingTypeBacktest
 }
 
+// IsSimulated returns true if the trading does not trade real money
+func (t *Trading) IsSimulated() bool {
+	return IsSimulatedTradingType(t.Type)
+}
+
+// IsSimulatedTradingType returns true for the trading types that do not trade real money. Simulated
+// tradings run on a virtual exchange binding, accept any event time and are left out of real-money aggregates.
+func IsSimulatedTradingType(tradingType string) bool {
+	return tradingType == TradingTypeSimulation || tradingType == TradingTypeBacktest
+}
+
 // IsActive returns true if the trading is active
 func (t *Trading) IsActive() bool {
 	return t.Status == TradingStatusActive
@@ -341,39 +377,56 @@ func (t *Trading) ToResponse() *TradingResponse {
 
 // SubAccount represents a trading sub-account
 type SubAccount struct {
-	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
-	TradingID uuid.UUID `gorm:"type:uuid;not null;index" json:"trading_id"`
-	Name       string    `gorm:"type:varchar(100);not null" json:"name"`
-	Symbol     string    `gorm:"type:varchar(20);not null;index" json:"symbol"`
-	Balance    float64   `gorm:"type:decimal(20,8);default:0" json:"balance"`
-	Info       JSON      `gorm:"type:jsonb" json:"info"`
+	ID          uuid.UUID       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID      uuid.UUID       `gorm:"type:uuid;not null;index" json:"user_id"`
+	TradingID   uuid.UUID       `gorm:"type:uuid;not null;index" json:"trading_id"`
+	Name        string          `gorm:"type:varchar(100);not null" json:"name"`
+	Symbol      string          `gorm:"type:varchar(20);not null;index" json:"symbol"`
+	Balance       decimal.Decimal `gorm:"type:decimal(20,8);default:0" json:"balance" swaggertype:"string"`
+	LockedBalance decimal.Decimal `gorm:"type:decimal(20,8);not null;default:0" json:"locked_balance" swaggertype:"string"`
+	AvgCost       decimal.Decimal `gorm:"type:decimal(20,8);not null;default:0" json:"avg_cost" swaggertype:"string"`
+	RealizedPnL   decimal.Decimal `gorm:"column:realized_pnl;type:decimal(20,8);not null;default:0" json:"realized_pnl" swaggertype:"string"`
+	Borrowed      decimal.Decimal `gorm:"type:decimal(20,8);not null;default:0" json:"borrowed" swaggertype:"string"`
+	Info          JSON            `gorm:"type:jsonb" json:"info"`
 
 	CreatedAt time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relationships
-	User        User         `gorm:"foreignKey:UserID" json:"-"`
-	Trading     Trading      `gorm:"foreignKey:TradingID" json:"-"`
+	User         User          `gorm:"foreignKey:UserID" json:"-"`
+	Trading      Trading       `gorm:"foreignKey:TradingID" json:"-"`
 	Transactions []Transaction `json:"-"`
 	TradingLogs  []TradingLog  `json:"-"`
 }
 
+// AvailableBalance returns the part of the balance that is not locked and can be spent. Borrowed funds
+// are not part of it: they can be traded or repaid but never leave the sub-account.
+func (s *SubAccount) AvailableBalance() decimal.Decimal {
+	return s.Balance.Sub(s.LockedBalance)
+}
+
+// SpendableBalance returns what a trade can take from the sub-account: the available balance plus the
+// borrowed amount, so a margin account can go down to minus what it borrowed
+func (s *SubAccount) SpendableBalance() decimal.Decimal {
+	return s.AvailableBalance().Add(s.Borrowed)
+}
+
 // Transaction represents a financial transaction
 type Transaction struct {
-	ID            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	UserID        uuid.UUID `gorm:"type:uuid;not null;index:idx_transactions_user_timestamp" json:"user_id"`
-	TradingID     uuid.UUID `gorm:"type:uuid;not null;index:idx_transactions_trading_timestamp" json:"trading_id"`
-	SubAccountID  uuid.UUID `gorm:"type:uuid;not null;index:idx_transactions_sub_account_timestamp" json:"sub_account_id"`
-	Timestamp     time.Time `gorm:"not null;default:CURRENT_TIMESTAMP;index:idx_transactions_user_timestamp,sort:desc;index:idx_transactions_trading_timestamp,sort:desc;index:idx_transactions_sub_account_timestamp,sort:desc" json:"timestamp"`
-	Direction      string    `gorm:"type:varchar(10);not null;check:direction IN ('debit', 'credit');index" json:"direction"`
-	Reason         string    `gorm:"type:varchar(50);not null;index" json:"reason"`
-	Amount         float64   `gorm:"type:decimal(20,8);not null" json:"amount"`
-	ClosingBalance float64   `gorm:"type:decimal(20,8);not null" json:"closing_balance"`
-	Price          *float64  `gorm:"type:decimal(20,8)" json:"price,omitempty"`
-	QuoteSymbol    *string   `gorm:"type:varchar(20)" json:"quote_symbol,omitempty"`
-	Info           JSON      `gorm:"type:jsonb" json:"info"`
+	ID             uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID         uuid.UUID        `gorm:"type:uuid;not null;index:idx_transactions_user_timestamp" json:"user_id"`
+	TradingID      uuid.UUID        `gorm:"type:uuid;not null;index:idx_transactions_trading_timestamp" json:"trading_id"`
+	SubAccountID   uuid.UUID        `gorm:"type:uuid;not null;index:idx_transactions_sub_account_timestamp" json:"sub_account_id"`
+	Timestamp      time.Time        `gorm:"not null;default:CURRENT_TIMESTAMP;index:idx_transactions_user_timestamp,sort:desc;index:idx_transactions_trading_timestamp,sort:desc;index:idx_transactions_sub_account_timestamp,sort:desc" json:"timestamp"`
+	Direction      string           `gorm:"type:varchar(10);not null;check:direction IN ('debit', 'credit');index" json:"direction"`
+	Reason         string           `gorm:"type:varchar(50);not null;index" json:"reason"`
+	Amount         decimal.Decimal  `gorm:"type:decimal(20,8);not null" json:"amount" swaggertype:"string"`
+	ClosingBalance decimal.Decimal  `gorm:"type:decimal(20,8);not null" json:"closing_balance" swaggertype:"string"`
+	Price          *decimal.Decimal `gorm:"type:decimal(20,8)" json:"price,omitempty" swaggertype:"string"`
+	QuoteSymbol    *string          `gorm:"type:varchar(20)" json:"quote_symbol,omitempty"`
+	RealizedPnL    *decimal.Decimal `gorm:"column:realized_pnl;type:decimal(20,8)" json:"realized_pnl,omitempty" swaggertype:"string"`
+	Info           JSON             `gorm:"type:jsonb" json:"info"`
 
 	// Relationships (no DeletedAt for time-series data)
 	User       User        `gorm:"foreignKey:UserID" json:"-"`
@@ -395,6 +448,8 @@ type TradingLog struct {
 	Source        string     `gorm:"type:varchar(20);not null;check:source IN ('manual', 'bot');index" json:"source"`
 	Message       string     `gorm:"type:text;not null" json:"message"`
 	Info          JSON       `gorm:"type:jsonb" json:"info"`
+	Amends        *uuid.UUID `gorm:"type:uuid" json:"amends,omitempty"`
+	AmendedBy     *uuid.UUID `gorm:"type:uuid" json:"amended_by,omitempty"`
 
 	// Relationships (no DeletedAt for time-series data)
 	User        User         `gorm:"foreignKey:UserID" json:"-"`
@@ -421,6 +476,19 @@ type EventProcessing struct {
 	SubAccount *SubAccount `gorm:"foreignKey:SubAccountID" json:"-"`
 }
 
+// IdempotencyKey records the outcome of a request sent with an Idempotency-Key header
+type IdempotencyKey struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idempotency_keys_user_key_unique" json:"user_id"`
+	Key         string    `gorm:"column:idempotency_key;type:varchar(255);not null;uniqueIndex:idempotency_keys_user_key_unique" json:"idempotency_key"`
+	RequestHash string    `gorm:"type:varchar(64);not null" json:"request_hash"`
+	Response    JSON      `gorm:"type:jsonb;not null" json:"response"`
+	CreatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP;index" json:"created_at"`
+
+	// Relationships
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
 // TableName overrides for TimescaleDB hypertables
 func (Transaction) TableName() string {
 	return "transactions"
@@ -560,9 +628,9 @@ func (r *UpdateExchangeBindingRequest) ToUpdateMap() map[string]interface{} {
 // Trading constants
 const (
 	// Trading types
-	TradingTypeReal     = "real"
-	TradingTypeVirtual  = "virtual"
-	TradingTypeBacktest = "backtest"
+	TradingTypeReal       = "real"
+	TradingTypeSimulation = "simulation"
+	TradingTypeBacktest   = "backtest"
 
 	// Trading statuses
 	TradingStatusActive   = "active"
@@ -589,7 +657,7 @@ func (r *CreateTradingRequest) Validate() error {
 		return errors.New("type is required")
 	}
 
-	validTypes := []string{TradingTypeReal, TradingTypeVirtual, TradingTypeBacktest}
+	validTypes := []string{TradingTypeReal, TradingTypeSimulation, TradingTypeBacktest}
 	isValidType := false
 	for _, tradingType := range validTypes {
 		if r.Type == tradingType {
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Order statuses
+const (
+	OrderStatusOpen            = "open"
+	OrderStatusPartiallyFilled = "partially_filled"
+	OrderStatusFilled          = "filled"
+	OrderStatusCancelled       = "cancelled"
+	OrderStatusFailed          = "failed"
+)
+
+// orderTransitions lists the statuses an order may move to from each status. Filled, cancelled and
+// failed orders are final.
+var orderTransitions = map[string][]string{
+	OrderStatusOpen:            {OrderStatusPartiallyFilled, OrderStatusFilled, OrderStatusCancelled, OrderStatusFailed},
+	OrderStatusPartiallyFilled: {OrderStatusPartiallyFilled, OrderStatusFilled, OrderStatusCancelled},
+}
+
+// Order represents an order placed on the exchange, identified by the exchange order ID within a trading
+type Order struct {
+	ID           uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID       uuid.UUID        `gorm:"type:uuid;not null;index" json:"user_id"`
+	TradingID    uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:orders_trading_order_unique" json:"trading_id"`
+	SubAccountID uuid.UUID        `gorm:"type:uuid;not null;index" json:"sub_account_id"`
+	OrderID      string           `gorm:"type:varchar(255);not null;uniqueIndex:orders_trading_order_unique" json:"order_id"`
+	Symbol       string           `gorm:"type:varchar(50);not null" json:"symbol"`
+	Side         string           `gorm:"type:varchar(10);not null;check:side IN ('buy', 'sell')" json:"side"`
+	Type         string           `gorm:"type:varchar(20);not null" json:"type"`
+	Amount       decimal.Decimal  `gorm:"type:decimal(20,8);not null" json:"amount" swaggertype:"string"`
+	Price        *decimal.Decimal `gorm:"type:decimal(20,8)" json:"price,omitempty" swaggertype:"string"`
+	FilledAmount decimal.Decimal  `gorm:"type:decimal(20,8);not null;default:0" json:"filled_amount" swaggertype:"string"`
+	AvgPrice     *decimal.Decimal `gorm:"type:decimal(20,8)" json:"avg_price,omitempty" swaggertype:"string"`
+	Status       string           `gorm:"type:varchar(20);not null;default:'open';index" json:"status"`
+	Info         JSON             `gorm:"type:jsonb" json:"info"`
+
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	// Relationships
+	User       User       `gorm:"foreignKey:UserID" json:"-"`
+	Trading    Trading    `gorm:"foreignKey:TradingID" json:"-"`
+	SubAccount SubAccount `gorm:"foreignKey:SubAccountID" json:"-"`
+}
+
+// TableName returns the table name for Order
+func (Order) TableName() string {
+	return "orders"
+}
+
+// CanTransitionTo returns true if the order may move from its current status to status
+func (o *Order) CanTransitionTo(status string) bool {
+	for _, allowed := range orderTransitions[o.Status] {
+		if allowed == status {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionTo moves the order to status, refusing transitions the lifecycle does not allow
+func (o *Order) TransitionTo(status string) error {
+	if !o.CanTransitionTo(status) {
+		return fmt.Errorf("invalid order status transition from %s to %s", o.Status, status)
+	}
+	o.Status = status
+	return nil
+}
+
+// ApplyFill adds a fill of amount at price to the order. The average price is weighted by the filled
+// amounts, and the order becomes filled once the filled amount reaches the order amount.
+func (o *Order) ApplyFill(amount, price decimal.Decimal) error {
+	if !amount.IsPositive() {
+		return fmt.Errorf("fill amount must be positive")
+	}
+	if !price.IsPositive() {
+		return fmt.Errorf("fill price must be positive")
+	}
+
+	filled := o.FilledAmount.Add(amount)
+	if filled.GreaterThan(o.Amount) {
+		return fmt.Errorf("fill of %s would exceed the order amount %s (already filled %s)",
+			amount.String(), o.Amount.String(), o.FilledAmount.String())
+	}
+
+	status := OrderStatusPartiallyFilled
+	if filled.Equal(o.Amount) {
+		status = OrderStatusFilled
+	}
+	if err := o.TransitionTo(status); err != nil {
+		return err
+	}
+
+	cost := price.Mul(amount)
+	if o.AvgPrice != nil {
+		cost = cost.Add(o.AvgPrice.Mul(o.FilledAmount))
+	}
+	avgPrice := cost.DivRound(filled, 8)
+	o.AvgPrice = &avgPrice
+	o.FilledAmount = filled
+	return nil
+}
+
+// IsFinal returns true if the order can no longer change
+func (o *Order) IsFinal() bool {
+	return len(orderTransitions[o.Status]) == 0
+}
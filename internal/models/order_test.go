@@ -0,0 +1,92 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrder_TransitionTo(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		allowed bool
+	}{
+		{"open_to_partially_filled", OrderStatusOpen, OrderStatusPartiallyFilled, true},
+		{"open_to_filled", OrderStatusOpen, OrderStatusFilled, true},
+		{"open_to_cancelled", OrderStatusOpen, OrderStatusCancelled, true},
+		{"open_to_failed", OrderStatusOpen, OrderStatusFailed, true},
+		{"partially_filled_to_partially_filled", OrderStatusPartiallyFilled, OrderStatusPartiallyFilled, true},
+		{"partially_filled_to_filled", OrderStatusPartiallyFilled, OrderStatusFilled, true},
+		{"partially_filled_to_cancelled", OrderStatusPartiallyFilled, OrderStatusCancelled, true},
+		{"partially_filled_to_failed", OrderStatusPartiallyFilled, OrderStatusFailed, false},
+		{"partially_filled_to_open", OrderStatusPartiallyFilled, OrderStatusOpen, false},
+		{"filled_to_cancelled", OrderStatusFilled, OrderStatusCancelled, false},
+		{"cancelled_to_filled", OrderStatusCancelled, OrderStatusFilled, false},
+		{"failed_to_open", OrderStatusFailed, OrderStatusOpen, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &Order{Status: tt.from}
+			err := order.TransitionTo(tt.to)
+			if tt.allowed {
+				require.NoError(t, err)
+				assert.Equal(t, tt.to, order.Status)
+			} else {
+				require.Error(t, err)
+				assert.Equal(t, "invalid order status transition from "+tt.from+" to "+tt.to, err.Error())
+				assert.Equal(t, tt.from, order.Status)
+			}
+		})
+	}
+}
+
+func TestOrder_ApplyFill(t *testing.T) {
+	t.Run("aggregate_partial_fills", func(t *testing.T) {
+		order := &Order{Amount: decimal.NewFromInt(3), Status: OrderStatusOpen}
+
+		require.NoError(t, order.ApplyFill(decimal.NewFromInt(1), decimal.NewFromInt(100)))
+		assert.Equal(t, OrderStatusPartiallyFilled, order.Status)
+		assert.True(t, order.FilledAmount.Equal(decimal.NewFromInt(1)))
+		assert.True(t, order.AvgPrice.Equal(decimal.NewFromInt(100)))
+
+		require.NoError(t, order.ApplyFill(decimal.NewFromInt(2), decimal.NewFromInt(106)))
+		assert.Equal(t, OrderStatusFilled, order.Status)
+		assert.True(t, order.FilledAmount.Equal(decimal.NewFromInt(3)))
+		assert.True(t, order.AvgPrice.Equal(decimal.NewFromInt(104)), order.AvgPrice.String())
+		assert.True(t, order.IsFinal())
+	})
+
+	t.Run("refuse_overfill", func(t *testing.T) {
+		order := &Order{Amount: decimal.NewFromInt(1), Status: OrderStatusOpen}
+
+		err := order.ApplyFill(decimal.RequireFromString("1.5"), decimal.NewFromInt(100))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "would exceed the order amount")
+		assert.Equal(t, OrderStatusOpen, order.Status)
+		assert.True(t, order.FilledAmount.IsZero())
+		assert.Nil(t, order.AvgPrice)
+	})
+
+	t.Run("refuse_fill_of_cancelled_order", func(t *testing.T) {
+		order := &Order{Amount: decimal.NewFromInt(1), Status: OrderStatusCancelled}
+
+		err := order.ApplyFill(decimal.NewFromInt(1), decimal.NewFromInt(100))
+
+		require.Error(t, err)
+		assert.Equal(t, "invalid order status transition from cancelled to filled", err.Error())
+		assert.True(t, order.FilledAmount.IsZero())
+	})
+
+	t.Run("refuse_non_positive_fill", func(t *testing.T) {
+		order := &Order{Amount: decimal.NewFromInt(1), Status: OrderStatusOpen}
+
+		assert.EqualError(t, order.ApplyFill(decimal.Zero, decimal.NewFromInt(100)), "fill amount must be positive")
+		assert.EqualError(t, order.ApplyFill(decimal.NewFromInt(1), decimal.Zero), "fill price must be positive")
+	})
+}
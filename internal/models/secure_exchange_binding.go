@@ -0,0 +1,93 @@
+package models
+
+import (
+	"tiris-backend/pkg/security"
+)
+
+// ExchangeBindingManager encrypts exchange binding credentials at rest. The API key and secret are
+// stored encrypted next to keyed hashes, which are used to look up duplicates without decrypting.
+type ExchangeBindingManager struct {
+	keyring *security.Keyring
+	hasher  *security.CredentialHasher
+}
+
+// NewExchangeBindingManager creates a new exchange binding manager. Credentials are encrypted with
+// the active key of the keyring and hashed with the signing key, which must not change.
+func NewExchangeBindingManager(keyring *security.Keyring, signingKey string) *ExchangeBindingManager {
+	return &ExchangeBindingManager{
+		keyring: keyring,
+		hasher:  security.NewCredentialHasher(signingKey),
+	}
+}
+
+// HashCredential returns the keyed hash of a plaintext credential, or an empty string when there is
+// no credential
+func (em *ExchangeBindingManager) HashCredential(credential string) string {
+	if credential == "" {
+		return ""
+	}
+	return em.hasher.Hash(credential)
+}
+
+// SetAPICredentials encrypts the API credentials into the binding together with their hashes and
+// the masked API key
+func (em *ExchangeBindingManager) SetAPICredentials(binding *ExchangeBinding, apiKey, apiSecret string) error {
+	encryptedKey, err := em.keyring.Encrypt(apiKey)
+	if err != nil {
+		return err
+	}
+
+	encryptedSecret, err := em.keyring.Encrypt(apiSecret)
+	if err != nil {
+		return err
+	}
+
+	binding.APIKey = encryptedKey
+	binding.APISecret = encryptedSecret
+	binding.APIKeyHash = em.HashCredential(apiKey)
+	binding.APISecretHash = em.HashCredential(apiSecret)
+	binding.MaskedAPIKey = MaskAPIKey(apiKey)
+
+	return nil
+}
+
+// GetAPICredentials decrypts and returns the API credentials of a binding. Only internal consumers
+// that talk to the exchange may call it; the credentials are never returned by the API.
+func (em *ExchangeBindingManager) GetAPICredentials(binding *ExchangeBinding) (apiKey, apiSecret string, err error) {
+	apiKey, err = em.keyring.Decrypt(binding.APIKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	apiSecret, err = em.keyring.Decrypt(binding.APISecret)
+	if err != nil {
+		return "", "", err
+	}
+
+	return apiKey, apiSecret, nil
+}
+
+// EncryptUpdates replaces the plaintext "api_key" and "api_secret" of an update map with their
+// encrypted values and adds the matching hash and mask columns
+func (em *ExchangeBindingManager) EncryptUpdates(updates map[string]interface{}) error {
+	if apiKey, ok := updates["api_key"].(string); ok {
+		encryptedKey, err := em.keyring.Encrypt(apiKey)
+		if err != nil {
+			return err
+		}
+		updates["api_key"] = encryptedKey
+		updates["api_key_hash"] = em.HashCredential(apiKey)
+		updates["masked_api_key"] = MaskAPIKey(apiKey)
+	}
+
+	if apiSecret, ok := updates["api_secret"].(string); ok {
+		encryptedSecret, err := em.keyring.Encrypt(apiSecret)
+		if err != nil {
+			return err
+		}
+		updates["api_secret"] = encryptedSecret
+		updates["api_secret_hash"] = em.HashCredential(apiSecret)
+	}
+
+	return nil
+}
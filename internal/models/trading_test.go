@@ -31,12 +31,12 @@ func TestTrading_Validation(t *testing.T) {
 			shouldError: false,
 		},
 		{
-			name: "valid_virtual_trading",
+			name: "valid_simulation_trading",
 			trading: Trading{
 				UserID:            userID,
 				ExchangeBindingID: exchangeBindingID,
-				Name:              "Virtual Trading",
-				Type:              "virtual",
+				Name:              "Simulation Trading",
+				Type:              "simulation",
 				Status:            "active",
 			},
 			shouldError: false,
@@ -142,9 +142,9 @@ func TestTrading_IsReal(t *testing.T) {
 			expected: true,
 		},
 		{
-			name: "virtual_trading",
+			name: "simulation_trading",
 			trading: Trading{
-				Type: "virtual",
+				Type: "simulation",
 			},
 			expected: false,
 		},
@@ -165,7 +165,7 @@ func TestTrading_IsReal(t *testing.T) {
 	}
 }
 
-func TestTrading_IsVirtual(t *testing.T) {
+func TestTrading_IsSimulation(t *testing.T) {
 	tests := []struct {
 		name     string
 		trading  Trading
@@ -179,9 +179,9 @@ func TestTrading_IsVirtual(t *testing.T) {
 			expected: false,
 		},
 		{
-			name: "virtual_trading",
+			name: "simulation_trading",
 			trading: Trading{
-				Type: "virtual",
+				Type: "simulation",
 			},
 			expected: true,
 		},
@@ -196,7 +196,7 @@ func TestTrading_IsVirtual(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := tt.trading.IsVirtual()
+			result := tt.trading.IsSimulation()
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -216,9 +216,9 @@ func TestTrading_IsBacktest(t *testing.T) {
 			expected: false,
 		},
 		{
-			name: "virtual_trading",
+			name: "simulation_trading",
 			trading: Trading{
-				Type: "virtual",
+				Type: "simulation",
 			},
 			expected: false,
 		},
@@ -239,6 +239,43 @@ func TestTrading_IsBacktest(t *testing.T) {
 	}
 }
 
+func TestTrading_IsSimulated(t *testing.T) {
+	tests := []struct {
+		name     string
+		trading  Trading
+		expected bool
+	}{
+		{
+			name: "real_trading",
+			trading: Trading{
+				Type: "real",
+			},
+			expected: false,
+		},
+		{
+			name: "simulation_trading",
+			trading: Trading{
+				Type: "simulation",
+			},
+			expected: true,
+		},
+		{
+			name: "backtest_trading",
+			trading: Trading{
+				Type: "backtest",
+			},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.trading.IsSimulated()
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
 func TestTrading_IsActive(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -331,7 +368,7 @@ func TestTradingConstants(t *testing.T) {
 	// Test valid types
 	validTypes := []string{
 		TradingTypeReal,
-		TradingTypeVirtual,
+		TradingTypeSimulation,
 		TradingTypeBacktest,
 	}
 
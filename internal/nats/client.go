@@ -158,7 +158,7 @@ func (c *Client) initializeStreams() error {
 		{
 			Name:        "TRADING",
 			Description: "Trading events stream",
-			Subjects:    []string{"trading.orders.*", "trading.balance.*", "trading.signals.*"},
+			Subjects:    []string{"trading.orders.*", "trading.balance.*", "trading.prices.*", "trading.signals.*"},
 			MaxAge:      24 * time.Hour * 30, // 30 days
 			Storage:     nats.FileStorage,
 			Replicas:    1,
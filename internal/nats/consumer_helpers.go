@@ -2,11 +2,15 @@ package nats
 
 import (
 	"fmt"
+	"log"
 	"time"
 
 	"tiris-backend/internal/models"
+	"tiris-backend/internal/services"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 // isEventProcessed checks if an event has already been processed
@@ -59,6 +63,187 @@ func (ec *EventConsumer) createTradingLogFromOrderEvent(event *OrderEvent) error
 	return ec.repos.TradingLog.Create(ec.ctx, log)
 }
 
+// applyOrderEvent records an order event on the order it refers to. A created event places the
+// order, filled events add their amount and price as a fill, and cancelled and failed events close
+// it. An order rejected before being placed is recorded from its failed event.
+//
+// Fills and cancels of an order whose created event was never received, such as an order placed
+// before the consumer started, are skipped with a warning: their payload does not carry the order
+// amount the order would need. A fill still moves the balances through its trading log.
+func (ec *EventConsumer) applyOrderEvent(event *OrderEvent) error {
+	order, err := ec.repos.Order.GetByTradingIDAndOrderID(ec.ctx, event.TradingID, event.OrderID)
+	if err != nil {
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+
+	if order == nil {
+		if event.EventType != EventOrderCreated && event.EventType != EventOrderFailed {
+			log.Printf("Warning: skipping %s event %s of unknown order %s in trading %s",
+				event.EventType, event.EventID, event.OrderID, event.TradingID)
+			return nil
+		}
+		order = newOrderFromEvent(event)
+		if event.EventType == EventOrderFailed {
+			order.Status = models.OrderStatusFailed
+		}
+		return ec.repos.Order.Create(ec.ctx, order)
+	}
+
+	switch event.EventType {
+	case EventOrderCreated:
+		return fmt.Errorf("order %s already exists", event.OrderID)
+	case EventOrderFilled:
+		if event.Price == nil {
+			return fmt.Errorf("fill of order %s has no price", event.OrderID)
+		}
+		err = order.ApplyFill(event.Amount, *event.Price)
+	case EventOrderCancelled:
+		err = order.TransitionTo(models.OrderStatusCancelled)
+	case EventOrderFailed:
+		err = order.TransitionTo(models.OrderStatusFailed)
+	default:
+		return fmt.Errorf("unknown order event type: %s", event.EventType)
+	}
+	if err != nil {
+		return fmt.Errorf("order %s: %w", event.OrderID, err)
+	}
+
+	return ec.repos.Order.Update(ec.ctx, order)
+}
+
+// newOrderFromEvent builds an open order from the event that placed it
+func newOrderFromEvent(event *OrderEvent) *models.Order {
+	return &models.Order{
+		UserID:       event.UserID,
+		TradingID:    event.TradingID,
+		SubAccountID: event.SubAccountID,
+		OrderID:      event.OrderID,
+		Symbol:       event.Symbol,
+		Side:         event.Side,
+		Type:         event.Type,
+		Amount:       event.Amount,
+		Price:        event.Price,
+		Status:       models.OrderStatusOpen,
+		Info:         models.JSON(event.Metadata),
+		CreatedAt:    event.Timestamp,
+	}
+}
+
+// isLedgerFill returns true if an order event is a fill that carries the sub-accounts whose balances it moves
+func isLedgerFill(event *OrderEvent) bool {
+	return event.EventType == EventOrderFilled && event.StockAccountID != nil && event.CurrencyAccountID != nil
+}
+
+// applyFillToLedger processes a fill as a long (buy) or short (sell) trading log so the balances move
+// with the fill. It must be called within the database transaction of the event.
+func (ec *EventConsumer) applyFillToLedger(tx *gorm.DB, event *OrderEvent) error {
+	req, err := ec.tradingLogRequestFromFill(event)
+	if err != nil {
+		return err
+	}
+
+	processor := services.NewTradingLogProcessor(ec.repos)
+	if _, err := processor.ProcessTradingLog(ec.ctx, tx, event.UserID, req); err != nil {
+		return err
+	}
+	return nil
+}
+
+// tradingLogRequestFromFill builds the long or short trading log request of a fill
+func (ec *EventConsumer) tradingLogRequestFromFill(event *OrderEvent) (*services.CreateTradingLogRequest, error) {
+	var logType string
+	switch event.Side {
+	case "buy":
+		logType = "long"
+	case "sell":
+		logType = "short"
+	default:
+		return nil, fmt.Errorf("unsupported order side: %s", event.Side)
+	}
+	if event.Price == nil {
+		return nil, fmt.Errorf("fill of order %s has no price", event.OrderID)
+	}
+
+	stockAccount, err := ec.repos.SubAccount.GetByID(ec.ctx, *event.StockAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock account: %w", err)
+	}
+	if stockAccount == nil {
+		return nil, fmt.Errorf("stock account not found")
+	}
+	currencyAccount, err := ec.repos.SubAccount.GetByID(ec.ctx, *event.CurrencyAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get currency account: %w", err)
+	}
+	if currencyAccount == nil {
+		return nil, fmt.Errorf("currency account not found")
+	}
+
+	fee := decimal.Zero
+	if event.Fee != nil {
+		fee = *event.Fee
+	}
+
+	message := event.Message
+	if message == "" {
+		message = fmt.Sprintf("Order %s filled: %s %s %s at %s",
+			event.OrderID, event.Side, event.Amount, stockAccount.Symbol, event.Price)
+	}
+
+	req := &services.CreateTradingLogRequest{
+		TradingID:    event.TradingID,
+		SubAccountID: &event.SubAccountID,
+		Type:         logType,
+		Source:       "bot",
+		Message:      message,
+		Info: map[string]interface{}{
+			"stock_account_id":    event.StockAccountID.String(),
+			"currency_account_id": event.CurrencyAccountID.String(),
+			"price":               event.Price.String(),
+			"volume":              event.Amount.String(),
+			"stock":               stockAccount.Symbol,
+			"currency":            currencyAccount.Symbol,
+			"fee":                 fee.String(),
+			"order_id":            event.OrderID,
+			"event_id":            event.EventID,
+		},
+	}
+	if !event.Timestamp.IsZero() {
+		eventTime := event.Timestamp
+		req.EventTime = &eventTime
+	}
+	return req, nil
+}
+
+// priceTickRequestFromEvent builds the virtual exchange price tick of a price event
+func priceTickRequestFromEvent(event *PriceEvent) *services.PriceTickRequest {
+	req := &services.PriceTickRequest{
+		Symbol: event.Symbol,
+		Price:  event.Price,
+		Volume: event.Volume,
+	}
+	if !event.Timestamp.IsZero() {
+		timestamp := event.Timestamp
+		req.Timestamp = &timestamp
+	}
+	return req
+}
+
+// applyPriceEvent matches the open virtual exchange orders of the trading against a price tick. It
+// must be called within the database transaction of the event.
+func (ec *EventConsumer) applyPriceEvent(tx *gorm.DB, event *PriceEvent) error {
+	service := services.NewVirtualExchangeService(ec.repos, tx)
+	result, err := service.PushPrice(ec.ctx, event.UserID, event.TradingID, priceTickRequestFromEvent(event))
+	if err != nil {
+		return err
+	}
+
+	if len(result.Orders) > 0 {
+		log.Printf("Price tick %s %s matched %d virtual orders", event.Symbol, event.Price, len(result.Orders))
+	}
+	return nil
+}
+
 // createTradingLogFromBalanceEvent creates a trading log entry from a balance event
 func (ec *EventConsumer) createTradingLogFromBalanceEvent(event *BalanceEvent, transactionID *uuid.UUID) error {
 	metadataMap := map[string]interface{}{
@@ -73,7 +258,7 @@ func (ec *EventConsumer) createTradingLogFromBalanceEvent(event *BalanceEvent, t
 		"original_metadata": event.Metadata,
 	}
 
-	message := fmt.Sprintf("Balance updated: %s %f %s (was %f, now %f)",
+	message := fmt.Sprintf("Balance updated: %s %s %s (was %s, now %s)",
 		event.Direction, event.Amount, event.Symbol, event.PreviousBalance, event.NewBalance)
 
 	log := &models.TradingLog{
@@ -91,6 +276,103 @@ func (ec *EventConsumer) createTradingLogFromBalanceEvent(event *BalanceEvent, t
 	return ec.repos.TradingLog.Create(ec.ctx, log)
 }
 
+// processBalanceEvent applies a balance event and marks it as processed. It must be called on a
+// consumer bound to a database transaction: a redelivery that races the first delivery fails on the
+// processed mark and rolls back its balance change.
+func (ec *EventConsumer) processBalanceEvent(event *BalanceEvent) error {
+	switch event.EventType {
+	case EventBalanceLocked, EventBalanceUnlocked:
+		// Locks move funds between available and locked balance without a transaction
+		if err := ec.applyBalanceLockEvent(event); err != nil {
+			return err
+		}
+	default:
+		// Update balance and create transaction
+		transactionID, err := ec.repos.SubAccount.UpdateBalance(
+			ec.ctx,
+			event.SubAccountID,
+			event.NewBalance,
+			event.Amount,
+			event.Direction,
+			event.Reason,
+			event.Metadata,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update balance: %w", err)
+		}
+
+		// Create trading log entry
+		if err := ec.createTradingLogFromBalanceEvent(event, transactionID); err != nil {
+			return fmt.Errorf("failed to create trading log: %w", err)
+		}
+	}
+
+	// Mark event as processed
+	if err := ec.markEventAsProcessed(event.EventID, string(event.EventType), &event.UserID, &event.SubAccountID); err != nil {
+		return fmt.Errorf("failed to mark event as processed: %w", err)
+	}
+
+	return nil
+}
+
+// applyBalanceLockEvent locks or unlocks the event amount on the sub-account and logs it
+func (ec *EventConsumer) applyBalanceLockEvent(event *BalanceEvent) error {
+	if !event.Amount.IsPositive() {
+		return fmt.Errorf("invalid balance lock amount: %s", event.Amount)
+	}
+
+	var subAccount *models.SubAccount
+	var err error
+	if event.EventType == EventBalanceLocked {
+		subAccount, err = ec.repos.SubAccount.LockBalance(ec.ctx, event.SubAccountID, event.Amount)
+	} else {
+		subAccount, err = ec.repos.SubAccount.UnlockBalance(ec.ctx, event.SubAccountID, event.Amount)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update locked balance: %w", err)
+	}
+
+	if err := ec.createTradingLogFromBalanceLockEvent(event, subAccount); err != nil {
+		return fmt.Errorf("failed to create trading log: %w", err)
+	}
+	return nil
+}
+
+// createTradingLogFromBalanceLockEvent creates a trading log entry from a balance lock or unlock event
+func (ec *EventConsumer) createTradingLogFromBalanceLockEvent(event *BalanceEvent, subAccount *models.SubAccount) error {
+	action := "locked"
+	if event.EventType == EventBalanceUnlocked {
+		action = "unlocked"
+	}
+
+	metadataMap := map[string]interface{}{
+		"symbol":            event.Symbol,
+		"amount":            event.Amount,
+		"locked_balance":    subAccount.LockedBalance,
+		"available_balance": subAccount.AvailableBalance(),
+		"reason":            event.Reason,
+		"related_order_id":  event.RelatedOrderID,
+		"event_id":          event.EventID,
+		"original_metadata": event.Metadata,
+	}
+
+	message := fmt.Sprintf("Balance %s: %s %s (locked %s, available %s)",
+		action, event.Amount, event.Symbol, subAccount.LockedBalance, subAccount.AvailableBalance())
+
+	log := &models.TradingLog{
+		UserID:       event.UserID,
+		TradingID:    event.TradingID,
+		SubAccountID: &event.SubAccountID,
+		Timestamp:    event.Timestamp,
+		Type:         "balance_" + action,
+		Source:       "bot",
+		Message:      message,
+		Info:         models.JSON(metadataMap),
+	}
+
+	return ec.repos.TradingLog.Create(ec.ctx, log)
+}
+
 // createTradingLogFromErrorEvent creates a trading log entry from an error event
 func (ec *EventConsumer) createTradingLogFromErrorEvent(event *ErrorEvent) error {
 	metadataMap := map[string]interface{}{
@@ -0,0 +1,252 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"tiris-backend/internal/models"
+	"tiris-backend/internal/repositories"
+	"tiris-backend/test/mocks"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOrderEvent(eventType EventType) *OrderEvent {
+	price := decimal.NewFromInt(3000)
+	return &OrderEvent{
+		BaseEvent: BaseEvent{
+			EventID:   uuid.New().String(),
+			EventType: eventType,
+			Timestamp: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+			UserID:    uuid.New(),
+			TradingID: uuid.New(),
+		},
+		SubAccountID: uuid.New(),
+		OrderID:      "8389765493",
+		Symbol:       "ETH/USDT",
+		Side:         "buy",
+		Type:         "limit",
+		Amount:       decimal.NewFromInt(2),
+		Price:        &price,
+	}
+}
+
+func TestApplyOrderEvent(t *testing.T) {
+	t.Run("create_order", func(t *testing.T) {
+		orderRepo := &mocks.MockOrderRepository{}
+		ec := &EventConsumer{repos: &repositories.Repositories{Order: orderRepo}, ctx: context.Background()}
+		event := newTestOrderEvent(EventOrderCreated)
+
+		orderRepo.On("GetByTradingIDAndOrderID", mock.Anything, event.TradingID, event.OrderID).Return(nil, nil).Once()
+		orderRepo.On("Create", mock.Anything, mock.MatchedBy(func(order *models.Order) bool {
+			return order.Status == models.OrderStatusOpen && order.Amount.Equal(event.Amount) && order.FilledAmount.IsZero()
+		})).Return(nil).Once()
+
+		require.NoError(t, ec.applyOrderEvent(event))
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("aggregate_fill", func(t *testing.T) {
+		orderRepo := &mocks.MockOrderRepository{}
+		ec := &EventConsumer{repos: &repositories.Repositories{Order: orderRepo}, ctx: context.Background()}
+		event := newTestOrderEvent(EventOrderFilled)
+		event.Amount = decimal.NewFromInt(1)
+		order := &models.Order{OrderID: event.OrderID, Amount: decimal.NewFromInt(2), Status: models.OrderStatusOpen}
+
+		orderRepo.On("GetByTradingIDAndOrderID", mock.Anything, event.TradingID, event.OrderID).Return(order, nil).Once()
+		orderRepo.On("Update", mock.Anything, order).Return(nil).Once()
+
+		require.NoError(t, ec.applyOrderEvent(event))
+		assert.Equal(t, models.OrderStatusPartiallyFilled, order.Status)
+		assert.True(t, order.FilledAmount.Equal(decimal.NewFromInt(1)))
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("skip_fill_of_unknown_order", func(t *testing.T) {
+		orderRepo := &mocks.MockOrderRepository{}
+		ec := &EventConsumer{repos: &repositories.Repositories{Order: orderRepo}, ctx: context.Background()}
+		event := newTestOrderEvent(EventOrderFilled)
+
+		orderRepo.On("GetByTradingIDAndOrderID", mock.Anything, event.TradingID, event.OrderID).Return(nil, nil).Once()
+
+		// The created event was never received: the fill is not an error that blocks the stream
+		require.NoError(t, ec.applyOrderEvent(event))
+		orderRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		orderRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("skip_cancel_of_unknown_order", func(t *testing.T) {
+		orderRepo := &mocks.MockOrderRepository{}
+		ec := &EventConsumer{repos: &repositories.Repositories{Order: orderRepo}, ctx: context.Background()}
+		event := newTestOrderEvent(EventOrderCancelled)
+
+		orderRepo.On("GetByTradingIDAndOrderID", mock.Anything, event.TradingID, event.OrderID).Return(nil, nil).Once()
+
+		require.NoError(t, ec.applyOrderEvent(event))
+		orderRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		orderRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("refuse_cancel_of_filled_order", func(t *testing.T) {
+		orderRepo := &mocks.MockOrderRepository{}
+		ec := &EventConsumer{repos: &repositories.Repositories{Order: orderRepo}, ctx: context.Background()}
+		event := newTestOrderEvent(EventOrderCancelled)
+		order := &models.Order{OrderID: event.OrderID, Amount: decimal.NewFromInt(2), FilledAmount: decimal.NewFromInt(2), Status: models.OrderStatusFilled}
+
+		orderRepo.On("GetByTradingIDAndOrderID", mock.Anything, event.TradingID, event.OrderID).Return(order, nil).Once()
+
+		err := ec.applyOrderEvent(event)
+
+		require.Error(t, err)
+		assert.Equal(t, "order 8389765493: invalid order status transition from filled to cancelled", err.Error())
+		orderRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTradingLogRequestFromFill(t *testing.T) {
+	stockAccount := &models.SubAccount{ID: uuid.New(), Symbol: "ETH"}
+	currencyAccount := &models.SubAccount{ID: uuid.New(), Symbol: "USDT"}
+
+	newConsumer := func() *EventConsumer {
+		subAccountRepo := &mocks.MockSubAccountRepository{}
+		subAccountRepo.On("GetByID", mock.Anything, stockAccount.ID).Return(stockAccount, nil)
+		subAccountRepo.On("GetByID", mock.Anything, currencyAccount.ID).Return(currencyAccount, nil)
+		return &EventConsumer{repos: &repositories.Repositories{SubAccount: subAccountRepo}, ctx: context.Background()}
+	}
+
+	t.Run("buy_fill_is_long", func(t *testing.T) {
+		event := newTestOrderEvent(EventOrderFilled)
+		fee := decimal.RequireFromString("1.5")
+		event.Fee = &fee
+		event.StockAccountID = &stockAccount.ID
+		event.CurrencyAccountID = &currencyAccount.ID
+		require.True(t, isLedgerFill(event))
+
+		req, err := newConsumer().tradingLogRequestFromFill(event)
+
+		require.NoError(t, err)
+		assert.Equal(t, "long", req.Type)
+		assert.Equal(t, "bot", req.Source)
+		assert.Equal(t, event.TradingID, req.TradingID)
+		require.NotNil(t, req.EventTime)
+		assert.True(t, req.EventTime.Equal(event.Timestamp))
+		assert.Equal(t, "Order 8389765493 filled: buy 2 ETH at 3000", req.Message)
+		assert.Equal(t, stockAccount.ID.String(), req.Info["stock_account_id"])
+		assert.Equal(t, currencyAccount.ID.String(), req.Info["currency_account_id"])
+		assert.Equal(t, "3000", req.Info["price"])
+		assert.Equal(t, "2", req.Info["volume"])
+		assert.Equal(t, "1.5", req.Info["fee"])
+		assert.Equal(t, "ETH", req.Info["stock"])
+		assert.Equal(t, "USDT", req.Info["currency"])
+		assert.Equal(t, "8389765493", req.Info["order_id"])
+	})
+
+	t.Run("sell_fill_is_short", func(t *testing.T) {
+		event := newTestOrderEvent(EventOrderFilled)
+		event.Side = "sell"
+		event.StockAccountID = &stockAccount.ID
+		event.CurrencyAccountID = &currencyAccount.ID
+
+		req, err := newConsumer().tradingLogRequestFromFill(event)
+
+		require.NoError(t, err)
+		assert.Equal(t, "short", req.Type)
+		assert.Equal(t, "0", req.Info["fee"])
+	})
+
+	t.Run("fill_without_sub_accounts_stays_a_log", func(t *testing.T) {
+		event := newTestOrderEvent(EventOrderFilled)
+		event.StockAccountID = &stockAccount.ID
+
+		assert.False(t, isLedgerFill(event))
+	})
+}
+
+func TestPriceTickRequestFromEvent(t *testing.T) {
+	event := NewPriceEvent(uuid.New(), uuid.New(), "backtest", "ETH/USDT", decimal.NewFromInt(3000))
+	event.Timestamp = time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	volume := decimal.RequireFromString("12.5")
+	event.Volume = &volume
+
+	req := priceTickRequestFromEvent(event)
+
+	assert.Equal(t, "ETH/USDT", req.Symbol)
+	assert.True(t, req.Price.Equal(decimal.NewFromInt(3000)))
+	assert.Equal(t, &volume, req.Volume)
+	require.NotNil(t, req.Timestamp)
+	assert.True(t, req.Timestamp.Equal(event.Timestamp))
+	require.NoError(t, ValidateEvent(event))
+}
+
+func TestBalanceEventRedelivery(t *testing.T) {
+	newLockEvent := func() *BalanceEvent {
+		return &BalanceEvent{
+			BaseEvent: BaseEvent{
+				EventID:   uuid.New().String(),
+				EventType: EventBalanceLocked,
+				Timestamp: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+				UserID:    uuid.New(),
+				TradingID: uuid.New(),
+			},
+			SubAccountID: uuid.New(),
+			Symbol:       "USDT",
+			Amount:       decimal.NewFromInt(500),
+			Reason:       "order_placed",
+		}
+	}
+
+	t.Run("redelivery_after_commit_is_skipped", func(t *testing.T) {
+		subAccountRepo := &mocks.MockSubAccountRepository{}
+		eventRepo := &mocks.MockEventProcessingRepository{}
+		// The processed mark is found before any database transaction is opened
+		ec := &EventConsumer{
+			repos: &repositories.Repositories{SubAccount: subAccountRepo, EventProcessing: eventRepo},
+			ctx:   context.Background(),
+		}
+		event := newLockEvent()
+		data, err := json.Marshal(event)
+		require.NoError(t, err)
+
+		eventRepo.On("GetByEventID", mock.Anything, event.EventID).
+			Return(&models.EventProcessing{EventID: event.EventID, Status: "processed"}, nil).Once()
+
+		require.NoError(t, ec.handleBalanceEvent(&nats.Msg{Data: data}))
+		subAccountRepo.AssertNotCalled(t, "LockBalance", mock.Anything, mock.Anything, mock.Anything)
+		eventRepo.AssertExpectations(t)
+	})
+
+	t.Run("concurrent_redelivery_fails_with_its_lock", func(t *testing.T) {
+		subAccountRepo := &mocks.MockSubAccountRepository{}
+		tradingLogRepo := &mocks.MockTradingLogRepository{}
+		eventRepo := &mocks.MockEventProcessingRepository{}
+		ec := &EventConsumer{
+			repos: &repositories.Repositories{SubAccount: subAccountRepo, TradingLog: tradingLogRepo, EventProcessing: eventRepo},
+			ctx:   context.Background(),
+		}
+		event := newLockEvent()
+		subAccount := &models.SubAccount{ID: event.SubAccountID, Balance: decimal.NewFromInt(1000), LockedBalance: decimal.NewFromInt(500)}
+
+		subAccountRepo.On("LockBalance", mock.Anything, event.SubAccountID, event.Amount).Return(subAccount, nil).Once()
+		tradingLogRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.TradingLog")).Return(nil).Once()
+		// The first delivery committed its mark while this one was locking
+		eventRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.EventProcessing")).
+			Return(errors.New("duplicate key value violates unique constraint")).Once()
+
+		err := ec.processBalanceEvent(event)
+
+		// The error rolls back the transaction, and the lock with it
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to mark event as processed")
+		subAccountRepo.AssertExpectations(t)
+		eventRepo.AssertExpectations(t)
+	})
+}
@@ -10,27 +10,42 @@ import (
 	"tiris-backend/internal/repositories"
 
 	"github.com/nats-io/nats.go"
+	"gorm.io/gorm"
 )
 
 // EventConsumer manages event consumption from NATS streams
 type EventConsumer struct {
 	client *Client
 	repos  *repositories.Repositories
+	db     *gorm.DB
 	ctx    context.Context
 	cancel context.CancelFunc
 }
 
 // NewEventConsumer creates a new event consumer
-func NewEventConsumer(client *Client, repos *repositories.Repositories) *EventConsumer {
+func NewEventConsumer(client *Client, repos *repositories.Repositories, db *gorm.DB) *EventConsumer {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &EventConsumer{
 		client: client,
 		repos:  repos,
+		db:     db,
 		ctx:    ctx,
 		cancel: cancel,
 	}
 }
 
+// withRepositories returns a consumer sharing the context of ec that uses the given repositories,
+// e.g. repositories bound to a database transaction
+func (ec *EventConsumer) withRepositories(repos *repositories.Repositories) *EventConsumer {
+	return &EventConsumer{
+		client: ec.client,
+		repos:  repos,
+		db:     ec.db,
+		ctx:    ec.ctx,
+		cancel: ec.cancel,
+	}
+}
+
 // Start starts consuming events from all streams
 func (ec *EventConsumer) Start() error {
 	log.Println("Starting NATS event consumers...")
@@ -45,6 +60,11 @@ func (ec *EventConsumer) Start() error {
 		return fmt.Errorf("failed to start balance event consumer: %w", err)
 	}
 
+	// Start price event consumer
+	if err := ec.startPriceEventConsumer(); err != nil {
+		return fmt.Errorf("failed to start price event consumer: %w", err)
+	}
+
 	// Start error event consumer
 	if err := ec.startErrorEventConsumer(); err != nil {
 		return fmt.Errorf("failed to start error event consumer: %w", err)
@@ -182,6 +202,63 @@ func (ec *EventConsumer) startBalanceEventConsumer() error {
 	return nil
 }
 
+// startPriceEventConsumer starts consuming the price ticks of the virtual exchange
+func (ec *EventConsumer) startPriceEventConsumer() error {
+	// Create a durable pull consumer for price events
+	consumerConfig := &nats.ConsumerConfig{
+		Durable:       "price-processor",
+		FilterSubject: "trading.prices.*",
+		AckPolicy:     nats.AckExplicitPolicy,
+		MaxDeliver:    3,
+		AckWait:       30 * time.Second,
+	}
+
+	// Create or update the consumer
+	_, err := ec.client.js.AddConsumer("TRADING", consumerConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create price consumer: %w", err)
+	}
+
+	// Subscribe to the consumer
+	sub, err := ec.client.js.PullSubscribe("trading.prices.*", "price-processor", nats.Bind("TRADING", "price-processor"))
+	if err != nil {
+		return fmt.Errorf("failed to pull subscribe to price events: %w", err)
+	}
+
+	// Start consuming messages in background. Ticks are handled in order so the orders of a
+	// trading fill in the sequence of its price feed.
+	go func() {
+		for {
+			select {
+			case <-ec.ctx.Done():
+				sub.Unsubscribe()
+				return
+			default:
+				msgs, err := sub.Fetch(10, nats.MaxWait(5*time.Second))
+				if err != nil {
+					if err == nats.ErrTimeout {
+						continue
+					}
+					log.Printf("Error fetching price messages: %v", err)
+					continue
+				}
+
+				for _, msg := range msgs {
+					if err := ec.handlePriceEvent(msg); err != nil {
+						log.Printf("Error handling price event: %v", err)
+						msg.Nak()
+					} else {
+						msg.Ack()
+					}
+				}
+			}
+		}
+	}()
+
+	log.Printf("Subscribed to price events: trading.prices.*")
+	return nil
+}
+
 // startErrorEventConsumer starts consuming error events
 func (ec *EventConsumer) startErrorEventConsumer() error {
 	// Create a durable pull consumer for error events
@@ -368,17 +445,35 @@ func (ec *EventConsumer) handleOrderEvent(msg *nats.Msg) error {
 	// Process the order event
 	log.Printf("Processing order event: %s - %s - %s", event.EventType, event.OrderID, event.Status)
 
-	// Create trading log entry
-	if err := ec.createTradingLogFromOrderEvent(&event); err != nil {
-		return fmt.Errorf("failed to create trading log: %w", err)
-	}
+	// The order, its trading log, the balances of a fill and the processed mark are written in
+	// one database transaction so a redelivered event can never apply twice
+	return ec.db.WithContext(ec.ctx).Transaction(func(tx *gorm.DB) error {
+		txConsumer := ec.withRepositories(repositories.NewRepositories(tx))
 
-	// Mark event as processed
-	if err := ec.markEventAsProcessed(event.EventID, string(event.EventType), &event.UserID, &event.SubAccountID); err != nil {
-		return fmt.Errorf("failed to mark event as processed: %w", err)
-	}
+		// Apply the event to the order lifecycle
+		if err := txConsumer.applyOrderEvent(&event); err != nil {
+			return fmt.Errorf("failed to apply order event: %w", err)
+		}
 
-	return nil
+		if isLedgerFill(&event) {
+			// Move the balances of the fill through a long or short trading log
+			if err := txConsumer.applyFillToLedger(tx, &event); err != nil {
+				return fmt.Errorf("failed to apply fill to ledger: %w", err)
+			}
+		} else {
+			// Create trading log entry
+			if err := txConsumer.createTradingLogFromOrderEvent(&event); err != nil {
+				return fmt.Errorf("failed to create trading log: %w", err)
+			}
+		}
+
+		// Mark event as processed
+		if err := txConsumer.markEventAsProcessed(event.EventID, string(event.EventType), &event.UserID, &event.SubAccountID); err != nil {
+			return fmt.Errorf("failed to mark event as processed: %w", err)
+		}
+
+		return nil
+	})
 }
 
 // handleBalanceEvent processes balance events
@@ -397,34 +492,51 @@ func (ec *EventConsumer) handleBalanceEvent(msg *nats.Msg) error {
 	}
 
 	// Process the balance event
-	log.Printf("Processing balance event: %s - %s - %f -> %f",
+	log.Printf("Processing balance event: %s - %s - %s -> %s",
 		event.EventType, event.Symbol, event.PreviousBalance, event.NewBalance)
 
-	// Update balance and create transaction
-	transactionID, err := ec.repos.SubAccount.UpdateBalance(
-		ec.ctx,
-		event.SubAccountID,
-		event.NewBalance,
-		event.Amount,
-		event.Direction,
-		event.Reason,
-		event.Metadata,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to update balance: %w", err)
-	}
+	// The balance change, its trading log and the processed mark are written in one database
+	// transaction so a redelivered event can never apply twice
+	return ec.db.WithContext(ec.ctx).Transaction(func(tx *gorm.DB) error {
+		txConsumer := ec.withRepositories(repositories.NewRepositories(tx))
+		return txConsumer.processBalanceEvent(&event)
+	})
+}
 
-	// Create trading log entry
-	if err := ec.createTradingLogFromBalanceEvent(&event, transactionID); err != nil {
-		return fmt.Errorf("failed to create trading log: %w", err)
+// handlePriceEvent processes the price ticks of the virtual exchange
+func (ec *EventConsumer) handlePriceEvent(msg *nats.Msg) error {
+	var event PriceEvent
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		return fmt.Errorf("failed to unmarshal price event: %w", err)
 	}
 
-	// Mark event as processed
-	if err := ec.markEventAsProcessed(event.EventID, string(event.EventType), &event.UserID, &event.SubAccountID); err != nil {
-		return fmt.Errorf("failed to mark event as processed: %w", err)
+	// Check for duplicate event
+	if exists, err := ec.isEventProcessed(event.EventID); err != nil {
+		return fmt.Errorf("failed to check event deduplication: %w", err)
+	} else if exists {
+		log.Printf("Skipping duplicate price event: %s", event.EventID)
+		return nil
 	}
 
-	return nil
+	// Process the price event
+	log.Printf("Processing price event: %s - %s - %s", event.TradingID, event.Symbol, event.Price)
+
+	// The fills of the tick and the processed mark are written in one database transaction so a
+	// redelivered tick can never fill twice
+	return ec.db.WithContext(ec.ctx).Transaction(func(tx *gorm.DB) error {
+		txConsumer := ec.withRepositories(repositories.NewRepositories(tx))
+
+		if err := txConsumer.applyPriceEvent(tx, &event); err != nil {
+			return fmt.Errorf("failed to apply price event: %w", err)
+		}
+
+		// Mark event as processed
+		if err := txConsumer.markEventAsProcessed(event.EventID, string(event.EventType), &event.UserID, nil); err != nil {
+			return fmt.Errorf("failed to mark event as processed: %w", err)
+		}
+
+		return nil
+	})
 }
 
 // handleErrorEvent processes error events
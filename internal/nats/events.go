@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // EventType represents the type of trading event
@@ -23,6 +24,9 @@ const (
 	EventBalanceLocked   EventType = "trading.balance.locked"
 	EventBalanceUnlocked EventType = "trading.balance.unlocked"
 
+	// Price Events
+	EventPriceUpdated EventType = "trading.prices.updated"
+
 	// System Events
 	EventSystemError     EventType = "trading.errors"
 	EventSignalGenerated EventType = "trading.signals"
@@ -40,19 +44,25 @@ type BaseEvent struct {
 	Version    string    `json:"version"`
 }
 
-// OrderEvent represents order-related events
+// OrderEvent represents order-related events. For a filled event Amount and Price are the amount
+// and price of that fill; an order may be filled in several parts. A filled event that also carries
+// the stock and currency sub-accounts moves the balances of the fill as a long (buy) or short (sell)
+// trading log.
 type OrderEvent struct {
 	BaseEvent
-	SubAccountID uuid.UUID              `json:"sub_account_id"`
-	OrderID      string                 `json:"order_id"`
-	Symbol       string                 `json:"symbol"`
-	Side         string                 `json:"side"` // "buy", "sell"
-	Type         string                 `json:"type"` // "market", "limit", etc.
-	Amount       float64                `json:"amount"`
-	Price        *float64               `json:"price,omitempty"`
-	Status       string                 `json:"status"`
-	Message      string                 `json:"message"`
-	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	SubAccountID      uuid.UUID              `json:"sub_account_id"`
+	OrderID           string                 `json:"order_id"`
+	Symbol            string                 `json:"symbol"`
+	Side              string                 `json:"side"` // "buy", "sell"
+	Type              string                 `json:"type"` // "market", "limit", etc.
+	Amount            decimal.Decimal        `json:"amount"`
+	Price             *decimal.Decimal       `json:"price,omitempty"`
+	Fee               *decimal.Decimal       `json:"fee,omitempty"`
+	StockAccountID    *uuid.UUID             `json:"stock_account_id,omitempty"`
+	CurrencyAccountID *uuid.UUID             `json:"currency_account_id,omitempty"`
+	Status            string                 `json:"status"`
+	Message           string                 `json:"message"`
+	Metadata          map[string]interface{} `json:"metadata,omitempty"`
 }
 
 // BalanceEvent represents balance update events
@@ -60,15 +70,25 @@ type BalanceEvent struct {
 	BaseEvent
 	SubAccountID    uuid.UUID              `json:"sub_account_id"`
 	Symbol          string                 `json:"symbol"`
-	PreviousBalance float64                `json:"previous_balance"`
-	NewBalance      float64                `json:"new_balance"`
-	Amount          float64                `json:"amount"`
+	PreviousBalance decimal.Decimal        `json:"previous_balance"`
+	NewBalance      decimal.Decimal        `json:"new_balance"`
+	Amount          decimal.Decimal        `json:"amount"`
 	Direction       string                 `json:"direction"` // "debit", "credit"
 	Reason          string                 `json:"reason"`
 	RelatedOrderID  *string                `json:"related_order_id,omitempty"`
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// PriceEvent represents a price tick of the feed of a simulation or backtest trading. The tick fills
+// the open virtual exchange orders of the trading for the symbol; its timestamp is the event time of
+// the fills.
+type PriceEvent struct {
+	BaseEvent
+	Symbol string           `json:"symbol"`
+	Price  decimal.Decimal  `json:"price"`
+	Volume *decimal.Decimal `json:"volume,omitempty"`
+}
+
 // ErrorEvent represents error events
 type ErrorEvent struct {
 	BaseEvent
@@ -88,7 +108,7 @@ type SignalEvent struct {
 	SignalType   string                 `json:"signal_type"` // "buy", "sell", "hold"
 	Symbol       string                 `json:"symbol"`
 	Confidence   float64                `json:"confidence"` // 0.0 to 1.0
-	Price        *float64               `json:"price,omitempty"`
+	Price        *decimal.Decimal       `json:"price,omitempty"`
 	Strategy     string                 `json:"strategy"`
 	Reasoning    string                 `json:"reasoning"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
@@ -131,6 +151,15 @@ func NewBalanceEvent(userID, tradingID, subAccountID uuid.UUID, source string) *
 	}
 }
 
+// NewPriceEvent creates a new price event
+func NewPriceEvent(userID, tradingID uuid.UUID, source, symbol string, price decimal.Decimal) *PriceEvent {
+	return &PriceEvent{
+		BaseEvent: NewBaseEvent(EventPriceUpdated, userID, tradingID, source),
+		Symbol:    symbol,
+		Price:     price,
+	}
+}
+
 // NewErrorEvent creates a new error event
 func NewErrorEvent(userID, tradingID uuid.UUID, source string) *ErrorEvent {
 	return &ErrorEvent{
@@ -171,6 +200,11 @@ func UnmarshalEvent(data []byte, eventType EventType) (interface{}, error) {
 		err := json.Unmarshal(data, &event)
 		return &event, err
 
+	case EventPriceUpdated:
+		var event PriceEvent
+		err := json.Unmarshal(data, &event)
+		return &event, err
+
 	case EventSystemError:
 		var event ErrorEvent
 		err := json.Unmarshal(data, &event)
@@ -209,6 +243,10 @@ func ValidateEvent(event interface{}) error {
 		if e.EventID == "" || e.UserID == uuid.Nil || e.TradingID == uuid.Nil || e.SubAccountID == uuid.Nil {
 			return fmt.Errorf("missing required fields in BalanceEvent")
 		}
+	case *PriceEvent:
+		if e.EventID == "" || e.UserID == uuid.Nil || e.TradingID == uuid.Nil || e.Symbol == "" {
+			return fmt.Errorf("missing required fields in PriceEvent")
+		}
 	case *ErrorEvent:
 		if e.EventID == "" || e.UserID == uuid.Nil || e.TradingID == uuid.Nil {
 			return fmt.Errorf("missing required fields in ErrorEvent")
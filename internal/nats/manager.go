@@ -6,6 +6,8 @@ import (
 
 	"tiris-backend/internal/config"
 	"tiris-backend/internal/repositories"
+
+	"gorm.io/gorm"
 )
 
 // Manager manages NATS client and event consumers
@@ -16,7 +18,7 @@ type Manager struct {
 }
 
 // NewManager creates a new NATS manager
-func NewManager(cfg config.NATSConfig, repos *repositories.Repositories) (*Manager, error) {
+func NewManager(cfg config.NATSConfig, repos *repositories.Repositories, db *gorm.DB) (*Manager, error) {
 	// Create NATS client
 	client, err := NewClient(cfg)
 	if err != nil {
@@ -24,7 +26,7 @@ func NewManager(cfg config.NATSConfig, repos *repositories.Repositories) (*Manag
 	}
 
 	// Create event consumer
-	consumer := NewEventConsumer(client, repos)
+	consumer := NewEventConsumer(client, repos, db)
 
 	return &Manager{
 		client:   client,
@@ -131,6 +133,8 @@ func (m *Manager) PublishEvent(event interface{}) error {
 		subject = GetSubject(e.EventType)
 	case *BalanceEvent:
 		subject = GetSubject(e.EventType)
+	case *PriceEvent:
+		subject = GetSubject(e.EventType)
 	case *ErrorEvent:
 		subject = GetSubject(e.EventType)
 	case *SignalEvent:
@@ -169,6 +173,8 @@ func (m *Manager) PublishEventAsync(event interface{}) error {
 		subject = GetSubject(e.EventType)
 	case *BalanceEvent:
 		subject = GetSubject(e.EventType)
+	case *PriceEvent:
+		subject = GetSubject(e.EventType)
 	case *ErrorEvent:
 		subject = GetSubject(e.EventType)
 	case *SignalEvent:
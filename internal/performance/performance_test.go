@@ -22,6 +22,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -66,6 +67,14 @@ func (suite *PerformanceTestSuite) SetupSuite() {
 			JWTExpiration:     3600,
 			RefreshExpiration: 86400,
 		},
+		Security: config.SecurityConfig{
+			MasterKeys:        map[int]string{1: "test-master-key-performance-testing-32-chars"},
+			ActiveMasterKeyID: 1,
+			SigningKey:        "test-signing-key-performance-testing-32-chars",
+		},
+		Exchange: config.ExchangeConfig{
+			Connectors: config.ExchangeConnectorsFake,
+		},
 		OAuth: config.OAuthConfig{
 			Google: config.GoogleOAuthConfig{
 				ClientID:     "test-google-client-id",
@@ -109,7 +118,7 @@ func (suite *PerformanceTestSuite) SetupSuite() {
 	suite.repos = repositories.NewRepositories(suite.db.DB)
 
 	// Initialize NATS (allow failure in test environment)
-	suite.nats, _ = nats.NewManager(suite.cfg.NATS, suite.repos)
+	suite.nats, _ = nats.NewManager(suite.cfg.NATS, suite.repos, suite.db.DB)
 
 	// Initialize API server
 	suite.server = api.NewServer(suite.cfg, suite.repos, suite.db, suite.nats)
@@ -595,7 +604,7 @@ func (suite *PerformanceTestSuite) createTestTradings(count int) {
 			UserID:            suite.userIDs[userIndex],
 			ExchangeBindingID: uuid.New(), // Use random binding for performance test
 			Name:              fmt.Sprintf("PerfTestTrading_%d", i),
-			Type:              "virtual",
+			Type:              "simulation",
 			Status:            "active",
 		}
 		
@@ -611,7 +620,7 @@ func (suite *PerformanceTestSuite) createTestDataWithRelationships(tradingsPerUs
 				UserID:            userID,
 				ExchangeBindingID: uuid.New(), // Use random binding for performance test
 				Name:              fmt.Sprintf("ComplexTrading_%d_%d", i, j),
-				Type:              "virtual",
+				Type:              "simulation",
 				Status:            "active",
 			}
 			
@@ -627,7 +636,7 @@ func (suite *PerformanceTestSuite) createTestDataWithRelationships(tradingsPerUs
 					TradingID: trading.ID,
 					Name:       fmt.Sprintf("SubAccount_%d_%d_%d", i, j, k),
 					Symbol:     fmt.Sprintf("SYM%d%d%d", i, j, k),
-					Balance:    float64(rand.Intn(10000)),
+					Balance:    decimal.NewFromInt(int64(rand.Intn(10000))),
 				}
 				
 				suite.repos.SubAccount.Create(context.Background(), subAccount)
@@ -657,6 +666,14 @@ func BenchmarkHealthEndpoint(b *testing.B) {
 			JWTExpiration:     3600,
 			RefreshExpiration: 86400,
 		},
+		Security: config.SecurityConfig{
+			MasterKeys:        map[int]string{1: "test-master-key-performance-testing-32-chars"},
+			ActiveMasterKeyID: 1,
+			SigningKey:        "test-signing-key-performance-testing-32-chars",
+		},
+		Exchange: config.ExchangeConfig{
+			Connectors: config.ExchangeConnectorsFake,
+		},
 		Environment: "test",
 	}
 
@@ -701,6 +718,14 @@ func BenchmarkAuthenticatedRequest(b *testing.B) {
 			JWTExpiration:     3600,
 			RefreshExpiration: 86400,
 		},
+		Security: config.SecurityConfig{
+			MasterKeys:        map[int]string{1: "test-master-key-performance-testing-32-chars"},
+			ActiveMasterKeyID: 1,
+			SigningKey:        "test-signing-key-performance-testing-32-chars",
+		},
+		Exchange: config.ExchangeConfig{
+			Connectors: config.ExchangeConnectorsFake,
+		},
 		Environment: "test",
 	}
 
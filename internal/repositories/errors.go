@@ -18,6 +18,8 @@ var (
 	ErrSubAccountNotFound      = errors.New("sub-account not found")
 	ErrSubAccountAlreadyExists = errors.New("sub-account already exists")
 	ErrInvalidSubAccount       = errors.New("invalid sub-account data")
+	ErrInsufficientAvailable   = errors.New("insufficient available balance")
+	ErrInsufficientLocked      = errors.New("insufficient locked balance")
 
 	// Transaction errors
 	ErrTransactionNotFound = errors.New("transaction not found")
@@ -20,8 +20,8 @@ type ExchangeBindingRepository interface {
 	Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	GetByNameAndUser(ctx context.Context, name string, userID *uuid.UUID) (*models.ExchangeBinding, error)
-	GetByAPIKey(ctx context.Context, apiKey string, userID *uuid.UUID) (*models.ExchangeBinding, error)
-	GetByAPISecret(ctx context.Context, apiSecret string, userID *uuid.UUID) (*models.ExchangeBinding, error)
+	GetByAPIKey(ctx context.Context, apiKeyHash string, userID *uuid.UUID) (*models.ExchangeBinding, error)
+	GetByAPISecret(ctx context.Context, apiSecretHash string, userID *uuid.UUID) (*models.ExchangeBinding, error)
 }
 
 // exchangeBindingRepository implements ExchangeBindingRepository
@@ -211,11 +211,11 @@ func (r *exchangeBindingRepository) GetByNameAndUser(ctx context.Context, name s
 	return &binding, nil
 }
 
-// GetByAPIKey retrieves an exchange binding by API key and user
-func (r *exchangeBindingRepository) GetByAPIKey(ctx context.Context, apiKey string, userID *uuid.UUID) (*models.ExchangeBinding, error) {
+// GetByAPIKey retrieves an exchange binding by the hash of its API key and user
+func (r *exchangeBindingRepository) GetByAPIKey(ctx context.Context, apiKeyHash string, userID *uuid.UUID) (*models.ExchangeBinding, error) {
 	var binding models.ExchangeBinding
 	
-	query := r.db.WithContext(ctx).Where("api_key = ? AND deleted_at IS NULL", apiKey)
+	query := r.db.WithContext(ctx).Where("api_key_hash = ? AND deleted_at IS NULL", apiKeyHash)
 	
 	if userID != nil {
 		query = query.Where("user_id = ?", *userID)
@@ -232,11 +232,11 @@ func (r *exchangeBindingRepository) GetByAPIKey(ctx context.Context, apiKey stri
 	return &binding, nil
 }
 
-// GetByAPISecret retrieves an exchange binding by API secret and user
-func (r *exchangeBindingRepository) GetByAPISecret(ctx context.Context, apiSecret string, userID *uuid.UUID) (*models.ExchangeBinding, error) {
+// GetByAPISecret retrieves an exchange binding by the hash of its API secret and user
+func (r *exchangeBindingRepository) GetByAPISecret(ctx context.Context, apiSecretHash string, userID *uuid.UUID) (*models.ExchangeBinding, error) {
 	var binding models.ExchangeBinding
 	
-	query := r.db.WithContext(ctx).Where("api_secret = ? AND deleted_at IS NULL", apiSecret)
+	query := r.db.WithContext(ctx).Where("api_secret_hash = ? AND deleted_at IS NULL", apiSecretHash)
 	
 	if userID != nil {
 		query = query.Where("user_id = ?", *userID)
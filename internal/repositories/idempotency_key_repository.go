@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"tiris-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type idempotencyKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyKeyRepository creates a new idempotency key repository instance
+func NewIdempotencyKeyRepository(db *gorm.DB) IdempotencyKeyRepository {
+	return &idempotencyKeyRepository{db: db}
+}
+
+func (r *idempotencyKeyRepository) Create(ctx context.Context, key *models.IdempotencyKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+func (r *idempotencyKeyRepository) GetByUserIDAndKey(ctx context.Context, userID uuid.UUID, key string) (*models.IdempotencyKey, error) {
+	var idempotencyKey models.IdempotencyKey
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND idempotency_key = ?", userID, key).
+		First(&idempotencyKey).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &idempotencyKey, nil
+}
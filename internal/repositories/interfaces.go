@@ -7,6 +7,7 @@ import (
 	"tiris-backend/internal/models"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // UserRepository defines the interface for user data operations
@@ -47,8 +48,10 @@ type SubAccountRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*models.SubAccount, error)
 	GetByUserID(ctx context.Context, userID uuid.UUID, tradingID *uuid.UUID) ([]*models.SubAccount, error)
 	GetByTradingID(ctx context.Context, tradingID uuid.UUID) ([]*models.SubAccount, error)
-	Update(ctx context.Context, subAccount *models.SubAccount) error
-	UpdateBalance(ctx context.Context, subAccountID uuid.UUID, newBalance float64, amount float64, direction, reason string, info interface{}) (*uuid.UUID, error)
+	Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error
+	UpdateBalance(ctx context.Context, subAccountID uuid.UUID, newBalance, amount decimal.Decimal, direction, reason string, info interface{}) (*uuid.UUID, error)
+	LockBalance(ctx context.Context, subAccountID uuid.UUID, amount decimal.Decimal) (*models.SubAccount, error)
+	UnlockBalance(ctx context.Context, subAccountID uuid.UUID, amount decimal.Decimal) (*models.SubAccount, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 	GetBySymbol(ctx context.Context, userID uuid.UUID, symbol string) ([]*models.SubAccount, error)
 }
@@ -61,6 +64,12 @@ type TransactionRepository interface {
 	GetBySubAccountID(ctx context.Context, subAccountID uuid.UUID, filters TransactionFilters) ([]*models.Transaction, int64, error)
 	GetByTradingID(ctx context.Context, tradingID uuid.UUID, filters TransactionFilters) ([]*models.Transaction, int64, error)
 	GetByTimeRange(ctx context.Context, startTime, endTime time.Time, filters TransactionFilters) ([]*models.Transaction, int64, error)
+	GetLatestBySubAccountIDAt(ctx context.Context, subAccountID uuid.UUID, at time.Time) ([]*models.Transaction, error)
+	GetLatestByTradingIDAt(ctx context.Context, tradingID uuid.UUID, at time.Time) ([]*models.Transaction, error)
+	SetPrice(ctx context.Context, id uuid.UUID, price decimal.Decimal, quoteSymbol string) error
+	GetLatestPricesByTradingID(ctx context.Context, tradingID uuid.UUID, quoteSymbol string) ([]*SymbolPrice, error)
+	GetBalanceChangesByTradingID(ctx context.Context, tradingID uuid.UUID, width time.Duration, from, to time.Time) ([]*BalanceChange, error)
+	GetBucketPricesByTradingID(ctx context.Context, tradingID uuid.UUID, quoteSymbol string, width time.Duration, from, to time.Time) ([]*BucketPrice, error)
 }
 
 // TradingLogRepository defines the interface for trading log operations
@@ -86,14 +95,31 @@ type EventProcessingRepository interface {
 	DeleteOldEvents(ctx context.Context, olderThan time.Time) error
 }
 
+// IdempotencyKeyRepository defines the interface for idempotency key operations
+type IdempotencyKeyRepository interface {
+	Create(ctx context.Context, key *models.IdempotencyKey) error
+	GetByUserIDAndKey(ctx context.Context, userID uuid.UUID, key string) (*models.IdempotencyKey, error)
+}
+
+// OrderRepository defines the interface for order operations
+type OrderRepository interface {
+	Create(ctx context.Context, order *models.Order) error
+	GetByTradingIDAndOrderID(ctx context.Context, tradingID uuid.UUID, orderID string) (*models.Order, error)
+	Update(ctx context.Context, order *models.Order) error
+	Cancel(ctx context.Context, id uuid.UUID) (*models.Order, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID, filters OrderFilters) ([]*models.Order, int64, error)
+	GetByTradingID(ctx context.Context, tradingID uuid.UUID, filters OrderFilters) ([]*models.Order, int64, error)
+	GetOpenForUpdate(ctx context.Context, tradingID uuid.UUID, symbol string) ([]*models.Order, error)
+}
+
 // Filter structs for complex queries
 type TransactionFilters struct {
 	Direction *string
 	Reason    *string
 	StartDate *time.Time
 	EndDate   *time.Time
-	MinAmount *float64
-	MaxAmount *float64
+	MinAmount *decimal.Decimal
+	MaxAmount *decimal.Decimal
 	Limit     int
 	Offset    int
 }
@@ -107,6 +133,16 @@ type TradingLogFilters struct {
 	Offset    int
 }
 
+type OrderFilters struct {
+	Status    *string
+	Side      *string
+	Symbol    *string
+	StartDate *time.Time
+	EndDate   *time.Time
+	Limit     int
+	Offset    int
+}
+
 type EventProcessingFilters struct {
 	Status    *string
 	StartDate *time.Time
@@ -114,3 +150,30 @@ type EventProcessingFilters struct {
 	Limit     int
 	Offset    int
 }
+
+// SymbolPrice is the latest trade price of a symbol in a quote currency
+type SymbolPrice struct {
+	Symbol        string
+	Price         decimal.Decimal
+	Timestamp     time.Time
+	TransactionID uuid.UUID
+}
+
+// BalanceChange is the net change of a sub-account balance by transactions of one reason within a
+// time bucket. A nil Bucket holds the change before the start of the requested range.
+type BalanceChange struct {
+	SubAccountID uuid.UUID
+	Bucket       *time.Time
+	Reason       string
+	// ReversedReason is the reason of the transactions a reversal takes back, empty for other reasons
+	ReversedReason string
+	Change         decimal.Decimal
+}
+
+// BucketPrice is the last trade price of a symbol within a time bucket. A nil Bucket holds the last
+// price before the start of the requested range.
+type BucketPrice struct {
+	Symbol string
+	Bucket *time.Time
+	Price  decimal.Decimal
+}
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"tiris-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type orderRepository struct {
+	db *gorm.DB
+}
+
+// NewOrderRepository creates a new order repository instance
+func NewOrderRepository(db *gorm.DB) OrderRepository {
+	return &orderRepository{db: db}
+}
+
+func (r *orderRepository) Create(ctx context.Context, order *models.Order) error {
+	return r.db.WithContext(ctx).Create(order).Error
+}
+
+func (r *orderRepository) GetByTradingIDAndOrderID(ctx context.Context, tradingID uuid.UUID, orderID string) (*models.Order, error) {
+	var order models.Order
+	err := r.db.WithContext(ctx).
+		Where("trading_id = ? AND order_id = ?", tradingID, orderID).
+		First(&order).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *orderRepository) Update(ctx context.Context, order *models.Order) error {
+	return r.db.WithContext(ctx).Save(order).Error
+}
+
+// Cancel cancels an order that is still open or partially filled, writing only its status. It returns
+// the cancelled order, or nil when the order was no longer cancellable.
+func (r *orderRepository) Cancel(ctx context.Context, id uuid.UUID) (*models.Order, error) {
+	var order models.Order
+	result := r.db.WithContext(ctx).
+		Model(&order).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND status IN ?", id, []string{models.OrderStatusOpen, models.OrderStatusPartiallyFilled}).
+		Update("status", models.OrderStatusCancelled)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &order, nil
+}
+
+func (r *orderRepository) GetByUserID(ctx context.Context, userID uuid.UUID, filters OrderFilters) ([]*models.Order, int64, error) {
+	return r.getOrders(ctx, filters, "user_id = ?", userID)
+}
+
+func (r *orderRepository) GetByTradingID(ctx context.Context, tradingID uuid.UUID, filters OrderFilters) ([]*models.Order, int64, error) {
+	return r.getOrders(ctx, filters, "trading_id = ?", tradingID)
+}
+
+// GetOpenForUpdate returns the open and partially filled orders of a trading for a symbol, oldest
+// first, locking them until the end of the transaction
+func (r *orderRepository) GetOpenForUpdate(ctx context.Context, tradingID uuid.UUID, symbol string) ([]*models.Order, error) {
+	var orders []*models.Order
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("trading_id = ? AND symbol = ? AND status IN ?", tradingID, symbol,
+			[]string{models.OrderStatusOpen, models.OrderStatusPartiallyFilled}).
+		Order("created_at ASC, id ASC").
+		Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (r *orderRepository) getOrders(ctx context.Context, filters OrderFilters, whereClause string, whereArgs ...interface{}) ([]*models.Order, int64, error) {
+	var orders []*models.Order
+	var total int64
+
+	// Build base query
+	query := r.db.WithContext(ctx).Model(&models.Order{}).Where(whereClause, whereArgs...)
+
+	// Apply filters
+	if filters.Status != nil {
+		query = query.Where("status = ?", *filters.Status)
+	}
+	if filters.Side != nil {
+		query = query.Where("side = ?", *filters.Side)
+	}
+	if filters.Symbol != nil {
+		query = query.Where("symbol = ?", *filters.Symbol)
+	}
+	if filters.StartDate != nil {
+		query = query.Where("created_at >= ?", *filters.StartDate)
+	}
+	if filters.EndDate != nil {
+		query = query.Where("created_at <= ?", *filters.EndDate)
+	}
+
+	// Get total count
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Apply pagination and ordering
+	if filters.Limit > 0 {
+		query = query.Limit(filters.Limit)
+	}
+	if filters.Offset > 0 {
+		query = query.Offset(filters.Offset)
+	}
+
+	err := query.Order("created_at DESC, id DESC").Find(&orders).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return orders, total, nil
+}
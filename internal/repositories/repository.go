@@ -14,6 +14,8 @@ type Repositories struct {
 	Transaction     TransactionRepository
 	TradingLog      TradingLogRepository
 	EventProcessing EventProcessingRepository
+	IdempotencyKey  IdempotencyKeyRepository
+	Order           OrderRepository
 }
 
 // NewRepositories creates a new repository container with all repositories
@@ -27,5 +29,7 @@ func NewRepositories(db *gorm.DB) *Repositories {
 		Transaction:     NewTransactionRepository(db),
 		TradingLog:      NewTradingLogRepository(db),
 		EventProcessing: NewEventProcessingRepository(db),
+		IdempotencyKey:  NewIdempotencyKeyRepository(db),
+		Order:           NewOrderRepository(db),
 	}
 }
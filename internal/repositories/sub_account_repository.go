@@ -9,7 +9,9 @@ import (
 	"tiris-backend/internal/models"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type subAccountRepository struct {
@@ -76,11 +78,22 @@ func (r *subAccountRepository) GetBySymbol(ctx context.Context, userID uuid.UUID
 	return subAccounts, nil
 }
 
-func (r *subAccountRepository) Update(ctx context.Context, subAccount *models.SubAccount) error {
-	return r.db.WithContext(ctx).Save(subAccount).Error
+// Update writes only the given columns so concurrent balance, lock and borrow changes are kept
+func (r *subAccountRepository) Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.SubAccount{}).
+		Where("id = ?", id).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("sub-account not found")
+	}
+	return nil
 }
 
-func (r *subAccountRepository) UpdateBalance(ctx context.Context, subAccountID uuid.UUID, newBalance float64, amount float64, direction, reason string, info interface{}) (*uuid.UUID, error) {
+func (r *subAccountRepository) UpdateBalance(ctx context.Context, subAccountID uuid.UUID, newBalance, amount decimal.Decimal, direction, reason string, info interface{}) (*uuid.UUID, error) {
 	// Convert info to JSON string if it's not already
 	var infoJSON string
 	if info != nil {
@@ -117,6 +130,47 @@ func (r *subAccountRepository) UpdateBalance(ctx context.Context, subAccountID u
 	return &transactionID, nil
 }
 
+// LockBalance moves amount of the available balance of a sub-account to its locked balance.
+// The check and the update are a single statement, so concurrent locks cannot overdraw the account.
+func (r *subAccountRepository) LockBalance(ctx context.Context, subAccountID uuid.UUID, amount decimal.Decimal) (*models.SubAccount, error) {
+	return r.adjustLockedBalance(ctx, subAccountID,
+		gorm.Expr("locked_balance + ?", amount), "balance - locked_balance >= ?", amount, ErrInsufficientAvailable)
+}
+
+// UnlockBalance releases amount of the locked balance of a sub-account
+func (r *subAccountRepository) UnlockBalance(ctx context.Context, subAccountID uuid.UUID, amount decimal.Decimal) (*models.SubAccount, error) {
+	return r.adjustLockedBalance(ctx, subAccountID,
+		gorm.Expr("locked_balance - ?", amount), "locked_balance >= ?", amount, ErrInsufficientLocked)
+}
+
+// adjustLockedBalance sets the locked balance if the condition holds and returns the updated sub-account
+func (r *subAccountRepository) adjustLockedBalance(ctx context.Context, subAccountID uuid.UUID, lockedBalance clause.Expr, condition string, amount decimal.Decimal, conditionErr error) (*models.SubAccount, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.SubAccount{}).
+		Where("id = ?", subAccountID).
+		Where(condition, amount).
+		Updates(map[string]interface{}{
+			"locked_balance": lockedBalance,
+			"updated_at":     gorm.Expr("NOW()"),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	subAccount, err := r.GetByID(ctx, subAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if subAccount == nil {
+		return nil, ErrSubAccountNotFound
+	}
+	if result.RowsAffected == 0 {
+		return nil, conditionErr
+	}
+
+	return subAccount, nil
+}
+
 func (r *subAccountRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	// First check if balance is zero
 	var subAccount models.SubAccount
@@ -124,7 +178,7 @@ func (r *subAccountRepository) Delete(ctx context.Context, id uuid.UUID) error {
 		return err
 	}
 
-	if subAccount.Balance != 0 {
+	if !subAccount.Balance.IsZero() {
 		return errors.New("cannot delete sub-account with non-zero balance")
 	}
 
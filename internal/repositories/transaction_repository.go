@@ -3,11 +3,13 @@ package repositories
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"tiris-backend/internal/models"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
@@ -52,6 +54,164 @@ func (r *transactionRepository) GetByTimeRange(ctx context.Context, startTime, e
 	return r.getTransactions(ctx, filters, "timestamp BETWEEN ? AND ?", startTime, endTime)
 }
 
+// GetLatestBySubAccountIDAt returns the transactions of a sub-account with the latest timestamp at or
+// before the given time. Transactions posted in the same database transaction share their timestamp,
+// so more than one transaction can be returned.
+func (r *transactionRepository) GetLatestBySubAccountIDAt(ctx context.Context, subAccountID uuid.UUID, at time.Time) ([]*models.Transaction, error) {
+	var transactions []*models.Transaction
+	err := r.db.WithContext(ctx).
+		Where("sub_account_id = ?", subAccountID).
+		Where("timestamp = (?)", r.db.Model(&models.Transaction{}).
+			Select("MAX(timestamp)").
+			Where("sub_account_id = ? AND timestamp <= ?", subAccountID, at)).
+		Order("id").
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// GetLatestByTradingIDAt returns, for every sub-account of a trading, the transactions with the latest
+// effective time at or before the given time. The effective time of a transaction is the event time
+// of the trading log that created it when set, and the transaction timestamp otherwise.
+func (r *transactionRepository) GetLatestByTradingIDAt(ctx context.Context, tradingID uuid.UUID, at time.Time) ([]*models.Transaction, error) {
+	var transactions []*models.Transaction
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT * FROM (
+			SELECT t.*, RANK() OVER (
+				PARTITION BY t.sub_account_id
+				ORDER BY COALESCE(l.event_time, t.timestamp) DESC, t.timestamp DESC
+			) AS effective_rank
+			FROM transactions t
+			LEFT JOIN trading_logs l ON l.id::text = t.info->>'id' AND l.trading_id = t.trading_id
+			WHERE t.trading_id = ? AND COALESCE(l.event_time, t.timestamp) <= ?
+		) ranked
+		WHERE effective_rank = 1
+		ORDER BY sub_account_id, id`, tradingID, at).
+		Scan(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// SetPrice stores the trade price and its quote currency on a transaction
+func (r *transactionRepository) SetPrice(ctx context.Context, id uuid.UUID, price decimal.Decimal, quoteSymbol string) error {
+	return r.db.WithContext(ctx).Model(&models.Transaction{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"price": price, "quote_symbol": quoteSymbol}).Error
+}
+
+// GetLatestPricesByTradingID returns the most recent trade price of every symbol traded in a trading
+// against the given quote currency. Transactions of the quote currency itself carry the price of the
+// other leg of the trade and are skipped.
+func (r *transactionRepository) GetLatestPricesByTradingID(ctx context.Context, tradingID uuid.UUID, quoteSymbol string) ([]*SymbolPrice, error) {
+	var prices []*SymbolPrice
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT DISTINCT ON (s.symbol) s.symbol, t.price, t.timestamp, t.id AS transaction_id
+		FROM transactions t
+		JOIN sub_accounts s ON s.id = t.sub_account_id
+		WHERE t.trading_id = ? AND t.price IS NOT NULL AND t.quote_symbol = ? AND s.symbol <> t.quote_symbol
+		ORDER BY s.symbol, t.timestamp DESC, t.id`, tradingID, quoteSymbol).
+		Scan(&prices).Error
+	if err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// GetBalanceChangesByTradingID returns the net balance change of every sub-account of a trading per
+// transaction reason and time bucket of the given width between from and to, plus the change before from. Transactions are
+// placed at the event time of the trading log that created them when set, like GetLatestByTradingIDAt.
+func (r *transactionRepository) GetBalanceChangesByTradingID(ctx context.Context, tradingID uuid.UUID, width time.Duration, from, to time.Time) ([]*BalanceChange, error) {
+	bucket, err := r.timeBucket(ctx, "effective_time")
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []*BalanceChange
+	err = r.db.WithContext(ctx).Raw(`
+		SELECT sub_account_id,
+			CASE WHEN effective_time < @from THEN NULL ELSE `+bucket+` END AS bucket,
+			reason,
+			reversed_reason,
+			SUM(CASE WHEN direction = 'debit' THEN -amount ELSE amount END) AS change
+		FROM (
+			SELECT t.sub_account_id, t.reason, COALESCE(t.info->>'reversed_reason', '') AS reversed_reason,
+				t.direction, t.amount, COALESCE(l.event_time, t.timestamp) AS effective_time
+			FROM transactions t
+			LEFT JOIN trading_logs l ON l.id::text = t.info->>'id' AND l.trading_id = t.trading_id
+			WHERE t.trading_id = @trading_id
+		) effective
+		WHERE effective_time <= @to
+		GROUP BY 1, 2, 3, 4
+		ORDER BY 1, 2 NULLS FIRST, 3, 4`, bucketArgs(tradingID, width, from, to)).
+		Scan(&changes).Error
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// GetBucketPricesByTradingID returns the last trade price against the quote currency of every symbol
+// traded in a trading per time bucket of the given width between from and to, plus the last price before from
+func (r *transactionRepository) GetBucketPricesByTradingID(ctx context.Context, tradingID uuid.UUID, quoteSymbol string, width time.Duration, from, to time.Time) ([]*BucketPrice, error) {
+	bucket, err := r.timeBucket(ctx, "t.timestamp")
+	if err != nil {
+		return nil, err
+	}
+
+	args := bucketArgs(tradingID, width, from, to)
+	args["quote_symbol"] = quoteSymbol
+
+	var prices []*BucketPrice
+	err = r.db.WithContext(ctx).Raw(`
+		SELECT DISTINCT ON (symbol, bucket) symbol, bucket, price
+		FROM (
+			SELECT s.symbol, t.price, t.timestamp,
+				CASE WHEN t.timestamp < @from THEN NULL ELSE `+bucket+` END AS bucket
+			FROM transactions t
+			JOIN sub_accounts s ON s.id = t.sub_account_id
+			WHERE t.trading_id = @trading_id AND t.price IS NOT NULL AND t.quote_symbol = @quote_symbol
+				AND s.symbol <> t.quote_symbol AND t.timestamp <= @to
+		) priced
+		ORDER BY symbol, bucket NULLS FIRST, timestamp DESC`, args).
+		Scan(&prices).Error
+	if err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// timeBucket returns the SQL expression truncating a timestamp column to the start of its bucket.
+// TimescaleDB's time_bucket is used when the extension is installed, and date_bin with the same
+// origin (Monday 2000-01-03 UTC) otherwise, so both give the same buckets.
+func (r *transactionRepository) timeBucket(ctx context.Context, column string) (string, error) {
+	var timescale bool
+	err := r.db.WithContext(ctx).
+		Raw("SELECT EXISTS(SELECT 1 FROM pg_extension WHERE extname = 'timescaledb')").
+		Scan(&timescale).Error
+	if err != nil {
+		return "", err
+	}
+
+	if timescale {
+		return "time_bucket(CAST(@width AS INTERVAL), " + column + ")", nil
+	}
+	return "date_bin(CAST(@width AS INTERVAL), " + column + ", TIMESTAMPTZ '2000-01-03 00:00:00+00')", nil
+}
+
+// bucketArgs returns the named arguments of the time bucket queries
+func bucketArgs(tradingID uuid.UUID, width time.Duration, from, to time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"trading_id": tradingID,
+		"width":      fmt.Sprintf("%d seconds", int64(width/time.Second)),
+		"from":       from,
+		"to":         to,
+	}
+}
+
 func (r *transactionRepository) getTransactions(ctx context.Context, filters TransactionFilters, whereClause string, whereArgs ...interface{}) ([]*models.Transaction, int64, error) {
 	var transactions []*models.Transaction
 	var total int64
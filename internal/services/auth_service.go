@@ -9,6 +9,7 @@ import (
 	"tiris-backend/internal/models"
 	"tiris-backend/internal/repositories"
 	"tiris-backend/pkg/auth"
+	"tiris-backend/pkg/security"
 
 	"github.com/google/uuid"
 	"golang.org/x/oauth2"
@@ -19,14 +20,17 @@ type AuthService struct {
 	repos        *repositories.Repositories
 	jwtManager   auth.JWTManagerInterface
 	oauthManager auth.OAuthManagerInterface
+	keyring      *security.Keyring
 }
 
-// NewAuthService creates a new authentication service
-func NewAuthService(repos *repositories.Repositories, jwtManager auth.JWTManagerInterface, oauthManager auth.OAuthManagerInterface) *AuthService {
+// NewAuthService creates a new authentication service. OAuth provider tokens are stored encrypted
+// with the keyring.
+func NewAuthService(repos *repositories.Repositories, jwtManager auth.JWTManagerInterface, oauthManager auth.OAuthManagerInterface, keyring *security.Keyring) *AuthService {
 	return &AuthService{
 		repos:        repos,
 		jwtManager:   jwtManager,
 		oauthManager: oauthManager,
+		keyring:      keyring,
 	}
 }
 
@@ -213,6 +217,16 @@ func (s *AuthService) Logout(ctx context.Context, userID uuid.UUID) error {
 
 // findOrCreateUser finds existing user or creates new one from OAuth info
 func (s *AuthService) findOrCreateUser(ctx context.Context, oauthUser *auth.OAuthUser, token *oauth2.Token) (*models.User, error) {
+	// Provider tokens are only stored encrypted
+	accessToken, err := s.keyring.Encrypt(token.AccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt OAuth access token: %w", err)
+	}
+	refreshToken, err := s.keyring.Encrypt(token.RefreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt OAuth refresh token: %w", err)
+	}
+
 	// Check if OAuth token already exists
 	existingToken, err := s.repos.OAuthToken.GetByProviderUserID(ctx, oauthUser.Provider, oauthUser.ID)
 	if err != nil {
@@ -221,9 +235,9 @@ func (s *AuthService) findOrCreateUser(ctx context.Context, oauthUser *auth.OAut
 
 	if existingToken != nil {
 		// User exists, update OAuth token and return user
-		existingToken.AccessToken = token.AccessToken
+		existingToken.AccessToken = accessToken
 		if token.RefreshToken != "" {
-			existingToken.RefreshToken = &token.RefreshToken
+			existingToken.RefreshToken = &refreshToken
 		}
 		if !token.Expiry.IsZero() {
 			existingToken.ExpiresAt = &token.Expiry
@@ -298,8 +312,8 @@ func (s *AuthService) findOrCreateUser(ctx context.Context, oauthUser *auth.OAut
 		UserID:         user.ID,
 		Provider:       oauthUser.Provider,
 		ProviderUserID: oauthUser.ID,
-		AccessToken:    token.AccessToken,
-		RefreshToken:   &token.RefreshToken,
+		AccessToken:    accessToken,
+		RefreshToken:   &refreshToken,
 		ExpiresAt:      &token.Expiry,
 		Info: func() models.JSON {
 			infoMap := map[string]interface{}{
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tiris-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// BalanceAtRequest represents a point-in-time balance query; the current time is used when At is not set
+type BalanceAtRequest struct {
+	At *time.Time `form:"at" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// SubAccountBalanceAtResponse represents the balance of a sub-account at a point in time
+type SubAccountBalanceAtResponse struct {
+	SubAccountID uuid.UUID       `json:"sub_account_id"`
+	TradingID    uuid.UUID       `json:"trading_id"`
+	Name         string          `json:"name"`
+	Symbol       string          `json:"symbol"`
+	At           string          `json:"at"`
+	Balance      decimal.Decimal `json:"balance" swaggertype:"string" example:"1250.75"`
+	// TransactionID is the transaction whose closing balance is the balance; empty when the
+	// sub-account had no transactions yet
+	TransactionID *uuid.UUID `json:"transaction_id,omitempty"`
+	TransactionAt *string    `json:"transaction_at,omitempty"`
+}
+
+// TradingBalanceAtResponse represents the balances of all sub-accounts of a trading at a point in time
+type TradingBalanceAtResponse struct {
+	TradingID   uuid.UUID                      `json:"trading_id"`
+	At          string                         `json:"at"`
+	SubAccounts []*SubAccountBalanceAtResponse `json:"sub_accounts"`
+}
+
+// GetBalanceAt returns the balance of a sub-account at the given time, resolved from the closing
+// balance of its latest transaction at or before that time
+func (s *SubAccountService) GetBalanceAt(ctx context.Context, userID, subAccountID uuid.UUID, at time.Time) (*SubAccountBalanceAtResponse, error) {
+	subAccount, err := s.repos.SubAccount.GetByID(ctx, subAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sub-account: %w", err)
+	}
+	if subAccount == nil || subAccount.UserID != userID {
+		return nil, fmt.Errorf("sub-account not found")
+	}
+
+	transactions, err := s.repos.Transaction.GetLatestBySubAccountIDAt(ctx, subAccountID, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	return newSubAccountBalanceAt(subAccount, at, lastInChain(transactions)), nil
+}
+
+// GetTradingBalanceAt returns the balances of all sub-accounts of a trading at the given time.
+// Transactions created by a trading log with an event time are placed at that event time.
+func (s *TradingService) GetTradingBalanceAt(ctx context.Context, userID, tradingID uuid.UUID, at time.Time) (*TradingBalanceAtResponse, error) {
+	trading, err := s.repos.Trading.GetByID(ctx, tradingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trading: %w", err)
+	}
+	if trading == nil || trading.UserID != userID {
+		return nil, fmt.Errorf("trading not found")
+	}
+
+	subAccounts, err := s.repos.SubAccount.GetByTradingID(ctx, tradingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sub-accounts: %w", err)
+	}
+
+	transactions, err := s.repos.Transaction.GetLatestByTradingIDAt(ctx, tradingID, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	latest := make(map[uuid.UUID][]*models.Transaction)
+	for _, transaction := range transactions {
+		latest[transaction.SubAccountID] = append(latest[transaction.SubAccountID], transaction)
+	}
+
+	response := &TradingBalanceAtResponse{
+		TradingID:   tradingID,
+		At:          at.Format(time.RFC3339),
+		SubAccounts: []*SubAccountBalanceAtResponse{},
+	}
+	for _, subAccount := range subAccounts {
+		// Sub-accounts created later did not exist yet
+		if subAccount.CreatedAt.After(at) && len(latest[subAccount.ID]) == 0 {
+			continue
+		}
+		response.SubAccounts = append(response.SubAccounts,
+			newSubAccountBalanceAt(subAccount, at, lastInChain(latest[subAccount.ID])))
+	}
+
+	return response, nil
+}
+
+// newSubAccountBalanceAt builds the balance of a sub-account from its latest transaction, if any
+func newSubAccountBalanceAt(subAccount *models.SubAccount, at time.Time, transaction *models.Transaction) *SubAccountBalanceAtResponse {
+	response := &SubAccountBalanceAtResponse{
+		SubAccountID: subAccount.ID,
+		TradingID:    subAccount.TradingID,
+		Name:         subAccount.Name,
+		Symbol:       subAccount.Symbol,
+		At:           at.Format(time.RFC3339),
+		Balance:      decimal.Zero,
+	}
+	if transaction != nil {
+		transactionAt := transaction.Timestamp.Format(time.RFC3339)
+		response.Balance = transaction.ClosingBalance
+		response.TransactionID = &transaction.ID
+		response.TransactionAt = &transactionAt
+	}
+	return response
+}
+
+// lastInChain returns the last of transactions that share a timestamp: the one no other transaction
+// chains from. Transactions posted in one database transaction carry the same timestamp, so their
+// order is only known from their closing balances.
+func lastInChain(transactions []*models.Transaction) *models.Transaction {
+	for _, candidate := range transactions {
+		followed := false
+		for _, other := range transactions {
+			if other != candidate && candidate.ClosingBalance.Add(signedAmount(other)).Equal(other.ClosingBalance) {
+				followed = true
+				break
+			}
+		}
+		if !followed {
+			return candidate
+		}
+	}
+
+	if len(transactions) == 0 {
+		return nil
+	}
+	return transactions[len(transactions)-1]
+}
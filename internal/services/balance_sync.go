@@ -0,0 +1,318 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"tiris-backend/internal/exchange"
+	"tiris-backend/internal/models"
+	"tiris-backend/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BalanceSyncService compares the sub-account balances of real tradings with the balances of their
+// exchange accounts. Differences are only corrected once approved, with adjustment transactions.
+type BalanceSyncService struct {
+	repos                  *repositories.Repositories
+	db                     *gorm.DB
+	exchangeBindingService ExchangeBindingService
+}
+
+// NewBalanceSyncService creates a new balance sync service
+func NewBalanceSyncService(repos *repositories.Repositories, db *gorm.DB, exchangeBindingService ExchangeBindingService) *BalanceSyncService {
+	return &BalanceSyncService{
+		repos:                  repos,
+		db:                     db,
+		exchangeBindingService: exchangeBindingService,
+	}
+}
+
+// BalanceSyncDiff is a sub-account whose balance differs from the balance of its symbol on the exchange
+type BalanceSyncDiff struct {
+	SubAccountID    uuid.UUID       `json:"sub_account_id"`
+	Symbol          string          `json:"symbol" example:"BTC"`
+	LedgerBalance   decimal.Decimal `json:"ledger_balance" swaggertype:"string" example:"0.50000000"`
+	ExchangeBalance decimal.Decimal `json:"exchange_balance" swaggertype:"string" example:"0.60000000"`
+	// Difference is the exchange balance minus the ledger balance
+	Difference decimal.Decimal `json:"difference" swaggertype:"string" example:"0.10000000"`
+	// AdjustmentTransactionID is the adjustment posted for the difference, if any
+	AdjustmentTransactionID *uuid.UUID `json:"adjustment_transaction_id,omitempty"`
+}
+
+// BalanceSyncAsset is an exchange balance no sub-account of the trading holds
+type BalanceSyncAsset struct {
+	Asset   string          `json:"asset" example:"ETH"`
+	Balance decimal.Decimal `json:"balance" swaggertype:"string" example:"2.00000000"`
+}
+
+// BalanceSyncReport compares the sub-accounts of a trading with its exchange account
+type BalanceSyncReport struct {
+	TradingID         uuid.UUID          `json:"trading_id"`
+	CheckedAt         string             `json:"checked_at"`
+	AccountsChecked   int                `json:"accounts_checked"`
+	AccountsWithDiff  int                `json:"accounts_with_diff"`
+	AdjustmentsPosted int                `json:"adjustments_posted"`
+	Diffs             []*BalanceSyncDiff `json:"diffs"`
+	// UnmatchedAssets are exchange balances of symbols without a sub-account
+	UnmatchedAssets []BalanceSyncAsset `json:"unmatched_assets"`
+	// SharedSymbols are held by several sub-accounts; their exchange balance cannot be mapped to one
+	// of them, so they are neither compared nor adjusted
+	SharedSymbols []string `json:"shared_symbols"`
+	// BorrowingSubAccounts borrow funds or hold a short position in margin mode. Exchange balances do
+	// not include the debt, so these sub-accounts are neither compared nor adjusted.
+	BorrowingSubAccounts []uuid.UUID `json:"borrowing_sub_accounts"`
+}
+
+// BalanceSyncApproval approves the adjustment of a sub-account as shown by a preview
+type BalanceSyncApproval struct {
+	SubAccountID    uuid.UUID       `json:"sub_account_id" binding:"required" example:"a1b2c3d4-e5f6-7890-abcd-ef1234567890"`
+	LedgerBalance   decimal.Decimal `json:"ledger_balance" swaggertype:"string" example:"0.5"`
+	ExchangeBalance decimal.Decimal `json:"exchange_balance" swaggertype:"string" example:"0.6"`
+}
+
+// ApplyBalanceSyncRequest lists the approved adjustments of a balance sync
+type ApplyBalanceSyncRequest struct {
+	Approvals []BalanceSyncApproval `json:"approvals" binding:"required,min=1,dive"`
+}
+
+// PreviewSync compares the sub-accounts of a real trading of the user with its exchange account
+func (s *BalanceSyncService) PreviewSync(ctx context.Context, userID, tradingID uuid.UUID) (*BalanceSyncReport, error) {
+	trading, err := s.getSyncTrading(ctx, userID, tradingID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.previewTrading(ctx, trading)
+}
+
+// ApplySync posts an adjustment transaction for each approved difference, so the balance of the
+// sub-account becomes its exchange balance. The exchange is read again and every approval must match
+// the current difference: if the ledger or the exchange changed since the preview, nothing is posted.
+func (s *BalanceSyncService) ApplySync(ctx context.Context, userID, tradingID uuid.UUID, req *ApplyBalanceSyncRequest) (*BalanceSyncReport, error) {
+	if len(req.Approvals) == 0 {
+		return nil, fmt.Errorf("no adjustments approved")
+	}
+	approved := make(map[uuid.UUID]bool, len(req.Approvals))
+	for _, approval := range req.Approvals {
+		if approved[approval.SubAccountID] {
+			return nil, fmt.Errorf("sub-account %s is approved twice", approval.SubAccountID)
+		}
+		approved[approval.SubAccountID] = true
+	}
+
+	trading, err := s.getSyncTrading(ctx, userID, tradingID)
+	if err != nil {
+		return nil, err
+	}
+	preview, err := s.previewTrading(ctx, trading)
+	if err != nil {
+		return nil, err
+	}
+	diffs := make(map[uuid.UUID]*BalanceSyncDiff, len(preview.Diffs))
+	for _, diff := range preview.Diffs {
+		diffs[diff.SubAccountID] = diff
+	}
+	for _, approval := range req.Approvals {
+		diff, ok := diffs[approval.SubAccountID]
+		if !ok {
+			return nil, fmt.Errorf("sub-account %s has no difference to adjust", approval.SubAccountID)
+		}
+		if !approval.LedgerBalance.Equal(diff.LedgerBalance) || !approval.ExchangeBalance.Equal(diff.ExchangeBalance) {
+			return nil, fmt.Errorf("balance of sub-account %s changed since the preview", approval.SubAccountID)
+		}
+	}
+
+	report := &BalanceSyncReport{
+		TradingID:            tradingID,
+		AccountsChecked:      preview.AccountsChecked,
+		Diffs:                []*BalanceSyncDiff{},
+		UnmatchedAssets:      preview.UnmatchedAssets,
+		SharedSymbols:        preview.SharedSymbols,
+		BorrowingSubAccounts: preview.BorrowingSubAccounts,
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repos := repositories.NewRepositories(tx)
+
+		for _, approval := range req.Approvals {
+			diff := diffs[approval.SubAccountID]
+
+			// The sub-account is locked so its balance cannot change between the check and the adjustment
+			var subAccount models.SubAccount
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", approval.SubAccountID).
+				First(&subAccount).Error
+			if err != nil {
+				return fmt.Errorf("failed to lock sub-account: %w", err)
+			}
+
+			if !subAccount.Balance.Equal(diff.LedgerBalance) {
+				return fmt.Errorf("balance of sub-account %s changed since the preview", approval.SubAccountID)
+			}
+			if diff.ExchangeBalance.LessThan(subAccount.LockedBalance) {
+				return fmt.Errorf("exchange balance of sub-account %s is below its locked balance", approval.SubAccountID)
+			}
+
+			direction := "credit"
+			if diff.Difference.IsNegative() {
+				direction = "debit"
+			}
+			info := map[string]interface{}{
+				"ledger_balance":   diff.LedgerBalance.StringFixed(MaxDecimalPlaces),
+				"exchange_balance": diff.ExchangeBalance.StringFixed(MaxDecimalPlaces),
+				"difference":       diff.Difference.StringFixed(MaxDecimalPlaces),
+				"source":           "exchange_sync",
+			}
+
+			transactionID, err := repos.SubAccount.UpdateBalance(
+				ctx, subAccount.ID, diff.ExchangeBalance, diff.Difference.Abs(), direction, AdjustmentReason, info)
+			if err != nil {
+				return fmt.Errorf("failed to post adjustment: %w", err)
+			}
+
+			adjusted := *diff
+			adjusted.AdjustmentTransactionID = transactionID
+			report.Diffs = append(report.Diffs, &adjusted)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.AccountsWithDiff = len(report.Diffs)
+	report.AdjustmentsPosted = len(report.Diffs)
+	report.CheckedAt = time.Now().Format("2006-01-02T15:04:05Z07:00")
+	return report, nil
+}
+
+// previewTrading reads the exchange balances of a trading and compares them with its sub-accounts.
+// An exchange account bound to several tradings holds the balances of all of them, which cannot be
+// mapped to the sub-accounts of one trading, so such a trading is refused.
+func (s *BalanceSyncService) previewTrading(ctx context.Context, trading *models.Trading) (*BalanceSyncReport, error) {
+	boundTradings, err := s.repos.Trading.GetByExchangeBinding(ctx, trading.ExchangeBindingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tradings of exchange binding: %w", err)
+	}
+	if len(boundTradings) > 1 {
+		return nil, fmt.Errorf("exchange binding is shared by %d tradings", len(boundTradings))
+	}
+
+	balances, err := s.exchangeBindingService.GetExchangeBalances(ctx, trading.ExchangeBindingID)
+	if err != nil {
+		return nil, err
+	}
+
+	subAccounts, err := s.repos.SubAccount.GetByTradingID(ctx, trading.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sub-accounts: %w", err)
+	}
+
+	report := diffExchangeBalances(subAccounts, balances)
+	report.TradingID = trading.ID
+	report.CheckedAt = time.Now().Format("2006-01-02T15:04:05Z07:00")
+	return report, nil
+}
+
+// activeRealTradings returns the tradings the scheduled sync checks
+func (s *BalanceSyncService) activeRealTradings(ctx context.Context) ([]*models.Trading, error) {
+	var tradings []*models.Trading
+	err := s.db.WithContext(ctx).
+		Where("type = ? AND status = ?", models.TradingTypeReal, models.TradingStatusActive).
+		Order("id").
+		Find(&tradings).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tradings: %w", err)
+	}
+	return tradings, nil
+}
+
+// getSyncTrading returns a real trading of the user; simulated tradings have no exchange account
+func (s *BalanceSyncService) getSyncTrading(ctx context.Context, userID, tradingID uuid.UUID) (*models.Trading, error) {
+	trading, err := s.repos.Trading.GetByID(ctx, tradingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trading: %w", err)
+	}
+	if trading == nil || trading.UserID != userID {
+		return nil, fmt.Errorf("trading not found")
+	}
+	if trading.Type != models.TradingTypeReal {
+		return nil, fmt.Errorf("balance sync requires a real trading")
+	}
+	return trading, nil
+}
+
+// diffExchangeBalances maps exchange balances to the sub-accounts holding their symbol and reports the
+// sub-accounts whose balance differs. Exchanges only report non-zero balances, so a sub-account whose
+// symbol is missing on the exchange has an exchange balance of zero. Sub-accounts that borrow or are
+// below zero are skipped: the exchange reports what they hold, not what they owe.
+func diffExchangeBalances(subAccounts []*models.SubAccount, balances []exchange.Balance) *BalanceSyncReport {
+	report := &BalanceSyncReport{
+		Diffs:                []*BalanceSyncDiff{},
+		UnmatchedAssets:      []BalanceSyncAsset{},
+		SharedSymbols:        []string{},
+		BorrowingSubAccounts: []uuid.UUID{},
+	}
+
+	exchangeBalances := make(map[string]decimal.Decimal, len(balances))
+	for _, balance := range balances {
+		asset := strings.ToUpper(balance.Asset)
+		exchangeBalances[asset] = exchangeBalances[asset].Add(balance.Total())
+	}
+
+	holders := make(map[string][]*models.SubAccount)
+	for _, subAccount := range subAccounts {
+		symbol := strings.ToUpper(subAccount.Symbol)
+		holders[symbol] = append(holders[symbol], subAccount)
+	}
+
+	for _, subAccount := range subAccounts {
+		symbol := strings.ToUpper(subAccount.Symbol)
+		if len(holders[symbol]) > 1 {
+			continue
+		}
+		if subAccount.Borrowed.IsPositive() || subAccount.Balance.IsNegative() {
+			report.BorrowingSubAccounts = append(report.BorrowingSubAccounts, subAccount.ID)
+			continue
+		}
+
+		report.AccountsChecked++
+		exchangeBalance := exchangeBalances[symbol].Round(MaxDecimalPlaces)
+		if exchangeBalance.Equal(subAccount.Balance) {
+			continue
+		}
+		report.Diffs = append(report.Diffs, &BalanceSyncDiff{
+			SubAccountID:    subAccount.ID,
+			Symbol:          subAccount.Symbol,
+			LedgerBalance:   subAccount.Balance,
+			ExchangeBalance: exchangeBalance,
+			Difference:      exchangeBalance.Sub(subAccount.Balance),
+		})
+	}
+	report.AccountsWithDiff = len(report.Diffs)
+
+	for symbol, accounts := range holders {
+		if len(accounts) > 1 {
+			report.SharedSymbols = append(report.SharedSymbols, symbol)
+		}
+	}
+	sort.Strings(report.SharedSymbols)
+
+	for asset, balance := range exchangeBalances {
+		if _, held := holders[asset]; !held && !balance.IsZero() {
+			report.UnmatchedAssets = append(report.UnmatchedAssets, BalanceSyncAsset{Asset: asset, Balance: balance})
+		}
+	}
+	sort.Slice(report.UnmatchedAssets, func(i, j int) bool {
+		return report.UnmatchedAssets[i].Asset < report.UnmatchedAssets[j].Asset
+	})
+
+	return report
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// BalanceSyncJob periodically compares the active real tradings with their exchange accounts and logs
+// the differences found. It never adjusts balances; adjustments must be approved through the API.
+type BalanceSyncJob struct {
+	*periodicJob
+	service *BalanceSyncService
+}
+
+// NewBalanceSyncJob creates a new balance sync job
+func NewBalanceSyncJob(service *BalanceSyncService, interval time.Duration) *BalanceSyncJob {
+	j := &BalanceSyncJob{service: service}
+	j.periodicJob = newPeriodicJob(interval, j.run)
+	return j
+}
+
+// run compares every active real trading once; a trading whose exchange cannot be read is skipped
+func (j *BalanceSyncJob) run() {
+	ctx := context.Background()
+
+	tradings, err := j.service.activeRealTradings(ctx)
+	if err != nil {
+		log.Printf("Exchange balance sync failed: %v", err)
+		return
+	}
+
+	withDiff := 0
+	for _, trading := range tradings {
+		report, err := j.service.previewTrading(ctx, trading)
+		if err != nil {
+			log.Printf("Exchange balance sync of trading %s failed: %v", trading.ID, err)
+			continue
+		}
+
+		if len(report.Diffs) > 0 {
+			withDiff++
+		}
+		for _, diff := range report.Diffs {
+			log.Printf("Exchange balance differs in sub-account %s (%s) of trading %s: ledger %s, exchange %s, difference %s",
+				diff.SubAccountID, diff.Symbol, trading.ID, diff.LedgerBalance.StringFixed(MaxDecimalPlaces),
+				diff.ExchangeBalance.StringFixed(MaxDecimalPlaces), diff.Difference.StringFixed(MaxDecimalPlaces))
+		}
+	}
+
+	log.Printf("Exchange balance sync checked %d tradings: %d with differences", len(tradings), withDiff)
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"testing"
+
+	"tiris-backend/internal/exchange"
+	"tiris-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffExchangeBalances(t *testing.T) {
+	d := decimal.RequireFromString
+
+	t.Run("balances_in_sync", func(t *testing.T) {
+		subAccounts := []*models.SubAccount{{ID: uuid.New(), Symbol: "BTC", Balance: d("0.6")}}
+		balances := []exchange.Balance{{Asset: "BTC", Free: d("0.5"), Locked: d("0.1")}}
+
+		report := diffExchangeBalances(subAccounts, balances)
+
+		assert.Equal(t, 1, report.AccountsChecked)
+		assert.Empty(t, report.Diffs)
+		assert.Empty(t, report.UnmatchedAssets)
+	})
+
+	t.Run("drifted_and_missing_balances", func(t *testing.T) {
+		btcAccount := &models.SubAccount{ID: uuid.New(), Symbol: "BTC", Balance: d("0.5")}
+		usdtAccount := &models.SubAccount{ID: uuid.New(), Symbol: "usdt", Balance: d("100")}
+		balances := []exchange.Balance{
+			{Asset: "BTC", Free: d("0.55")},
+			{Asset: "ETH", Free: d("2")},
+		}
+
+		report := diffExchangeBalances([]*models.SubAccount{btcAccount, usdtAccount}, balances)
+
+		assert.Equal(t, 2, report.AccountsChecked)
+		assert.Equal(t, 2, report.AccountsWithDiff)
+		require.Len(t, report.Diffs, 2)
+		assert.Equal(t, btcAccount.ID, report.Diffs[0].SubAccountID)
+		assert.True(t, report.Diffs[0].Difference.Equal(d("0.05")), report.Diffs[0].Difference.String())
+
+		// USDT was withdrawn on the exchange UI: the exchange no longer reports it
+		assert.Equal(t, usdtAccount.ID, report.Diffs[1].SubAccountID)
+		assert.True(t, report.Diffs[1].ExchangeBalance.IsZero())
+		assert.True(t, report.Diffs[1].Difference.Equal(d("-100")))
+
+		require.Len(t, report.UnmatchedAssets, 1)
+		assert.Equal(t, "ETH", report.UnmatchedAssets[0].Asset)
+	})
+
+	t.Run("shared_symbol_is_not_compared", func(t *testing.T) {
+		subAccounts := []*models.SubAccount{
+			{ID: uuid.New(), Symbol: "USDT", Balance: d("100")},
+			{ID: uuid.New(), Symbol: "USDT", Balance: d("50")},
+		}
+		balances := []exchange.Balance{{Asset: "USDT", Free: d("120")}}
+
+		report := diffExchangeBalances(subAccounts, balances)
+
+		assert.Equal(t, 0, report.AccountsChecked)
+		assert.Empty(t, report.Diffs)
+		assert.Empty(t, report.UnmatchedAssets)
+		assert.Equal(t, []string{"USDT"}, report.SharedSymbols)
+	})
+
+	t.Run("borrowing_account_is_not_compared", func(t *testing.T) {
+		// Short of 0.5 BTC against 1 BTC borrowed: the exchange only reports the 0.5 BTC still held
+		btcAccount := &models.SubAccount{ID: uuid.New(), Symbol: "BTC", Balance: d("-0.5"), Borrowed: d("1")}
+		ethAccount := &models.SubAccount{ID: uuid.New(), Symbol: "ETH", Balance: d("2"), Borrowed: d("0.5")}
+		balances := []exchange.Balance{{Asset: "BTC", Free: d("0.5")}, {Asset: "ETH", Free: d("2.5")}}
+
+		report := diffExchangeBalances([]*models.SubAccount{btcAccount, ethAccount}, balances)
+
+		assert.Equal(t, 0, report.AccountsChecked)
+		assert.Empty(t, report.Diffs)
+		assert.Empty(t, report.UnmatchedAssets)
+		assert.Equal(t, []uuid.UUID{btcAccount.ID, ethAccount.ID}, report.BorrowingSubAccounts)
+	})
+
+	t.Run("exchange_precision_is_rounded", func(t *testing.T) {
+		subAccounts := []*models.SubAccount{{ID: uuid.New(), Symbol: "ETH", Balance: d("1.23456789")}}
+		balances := []exchange.Balance{{Asset: "ETH", Free: d("1.234567891")}}
+
+		report := diffExchangeBalances(subAccounts, balances)
+
+		assert.Empty(t, report.Diffs)
+	})
+}
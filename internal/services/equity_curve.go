@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"tiris-backend/internal/models"
+	"tiris-backend/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Equity curve resolutions
+var equityCurveIntervals = map[string]time.Duration{
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+	"1w": 7 * 24 * time.Hour,
+}
+
+// MaxEquityCurvePoints limits the number of buckets of an equity curve
+const MaxEquityCurvePoints = 1000
+
+// bucketOrigin is the start of the first bucket, a Monday, so weekly buckets start on Mondays.
+// It matches the default origin of TimescaleDB's time_bucket.
+var bucketOrigin = time.Date(2000, 1, 3, 0, 0, 0, 0, time.UTC)
+
+// EquityCurveRequest represents an equity curve query
+type EquityCurveRequest struct {
+	// Interval is the bucket width: 1h, 1d or 1w; 1d by default
+	Interval string `form:"interval" example:"1d"`
+	// From defaults to 30 intervals before To, and To to now
+	From *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	// SubAccountID restricts the curve to one sub-account of the trading
+	SubAccountID *uuid.UUID `form:"sub_account_id"`
+	// Quote values the sub-accounts in this currency to compute the equity of the trading
+	Quote string `form:"quote" example:"USDT"`
+}
+
+// BalancePoint represents the balance of a sub-account at the end of a bucket
+type BalancePoint struct {
+	Time    string          `json:"time"`
+	Balance decimal.Decimal `json:"balance" swaggertype:"string" example:"1250.75"`
+}
+
+// SubAccountBalanceCurve represents the balances of a sub-account over time
+type SubAccountBalanceCurve struct {
+	SubAccountID uuid.UUID       `json:"sub_account_id"`
+	Name         string          `json:"name"`
+	Symbol       string          `json:"symbol"`
+	Points       []*BalancePoint `json:"points"`
+}
+
+// EquityPoint represents the equity of a trading in the quote currency at the end of a bucket
+type EquityPoint struct {
+	Time   string          `json:"time"`
+	Equity decimal.Decimal `json:"equity" swaggertype:"string" example:"10000.00000000"`
+	// UnpricedSymbols lists the symbols with a balance but no trade price yet, left out of the equity
+	UnpricedSymbols []string `json:"unpriced_symbols,omitempty"`
+}
+
+// EquityCurveResponse represents the equity curve of a trading. Each point is labelled with the start
+// of its bucket and holds the balance at the end of the bucket; empty buckets carry the balance forward.
+type EquityCurveResponse struct {
+	TradingID   uuid.UUID                 `json:"trading_id"`
+	Interval    string                    `json:"interval" example:"1d"`
+	From        string                    `json:"from"`
+	To          string                    `json:"to"`
+	QuoteSymbol string                    `json:"quote_symbol,omitempty" example:"USDT"`
+	Equity      []*EquityPoint            `json:"equity,omitempty"`
+	SubAccounts []*SubAccountBalanceCurve `json:"sub_accounts"`
+}
+
+// GetEquityCurve returns the balances of the sub-accounts of a trading per time bucket and, with a
+// quote currency, the equity of the trading valued at the last trade price of each symbol
+func (s *TradingService) GetEquityCurve(ctx context.Context, userID, tradingID uuid.UUID, req *EquityCurveRequest) (*EquityCurveResponse, error) {
+	interval := req.Interval
+	if interval == "" {
+		interval = "1d"
+	}
+	width, ok := equityCurveIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("invalid interval: must be one of 1h, 1d, 1w")
+	}
+
+	to := time.Now()
+	if req.To != nil {
+		to = *req.To
+	}
+	from := to.Add(-30 * width)
+	if req.From != nil {
+		from = *req.From
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+	buckets := bucketStarts(from, to, width)
+	if len(buckets) > MaxEquityCurvePoints {
+		return nil, fmt.Errorf("too many points: at most %d buckets can be returned", MaxEquityCurvePoints)
+	}
+
+	trading, err := s.repos.Trading.GetByID(ctx, tradingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trading: %w", err)
+	}
+	if trading == nil || trading.UserID != userID {
+		return nil, fmt.Errorf("trading not found")
+	}
+
+	subAccounts, err := s.repos.SubAccount.GetByTradingID(ctx, tradingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sub-accounts: %w", err)
+	}
+	if req.SubAccountID != nil {
+		var selected []*models.SubAccount
+		for _, subAccount := range subAccounts {
+			if subAccount.ID == *req.SubAccountID {
+				selected = append(selected, subAccount)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, fmt.Errorf("sub-account not found")
+		}
+		subAccounts = selected
+	}
+
+	changes, err := s.repos.Transaction.GetBalanceChangesByTradingID(ctx, tradingID, width, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance changes: %w", err)
+	}
+
+	response := &EquityCurveResponse{
+		TradingID:   tradingID,
+		Interval:    interval,
+		From:        from.Format(time.RFC3339),
+		To:          to.Format(time.RFC3339),
+		SubAccounts: balanceCurves(subAccounts, changes, buckets),
+	}
+
+	if req.Quote != "" {
+		prices, err := s.repos.Transaction.GetBucketPricesByTradingID(ctx, tradingID, req.Quote, width, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get prices: %w", err)
+		}
+		response.QuoteSymbol = req.Quote
+		response.Equity = equityCurve(response.SubAccounts, priceSeries(req.Quote, prices, buckets), buckets)
+	}
+
+	return response, nil
+}
+
+// bucketStarts returns the start of every bucket of the given width that overlaps [from, to]
+func bucketStarts(from, to time.Time, width time.Duration) []time.Time {
+	var starts []time.Time
+	for start := bucketStart(from, width); !start.After(to); start = start.Add(width) {
+		starts = append(starts, start)
+		if len(starts) > MaxEquityCurvePoints {
+			break
+		}
+	}
+	return starts
+}
+
+// bucketStart returns the start of the bucket of the given width that contains t
+func bucketStart(t time.Time, width time.Duration) time.Time {
+	offset := t.Sub(bucketOrigin)
+	start := offset.Truncate(width)
+	if start > offset {
+		start -= width
+	}
+	return bucketOrigin.Add(start)
+}
+
+// bucketBefore orders buckets by start, with the nil bucket before the requested range first
+func bucketBefore(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b != nil
+	}
+	return a.Before(*b)
+}
+
+// balanceCurves accumulates the balance changes of each sub-account into its balance at the end of
+// every bucket, carrying the balance forward across buckets without transactions
+func balanceCurves(subAccounts []*models.SubAccount, changes []*repositories.BalanceChange, buckets []time.Time) []*SubAccountBalanceCurve {
+	bySubAccount := make(map[uuid.UUID][]*repositories.BalanceChange)
+	for _, change := range changes {
+		bySubAccount[change.SubAccountID] = append(bySubAccount[change.SubAccountID], change)
+	}
+
+	curves := make([]*SubAccountBalanceCurve, 0, len(subAccounts))
+	for _, subAccount := range subAccounts {
+		pending := bySubAccount[subAccount.ID]
+		sort.SliceStable(pending, func(i, j int) bool { return bucketBefore(pending[i].Bucket, pending[j].Bucket) })
+
+		curve := &SubAccountBalanceCurve{
+			SubAccountID: subAccount.ID,
+			Name:         subAccount.Name,
+			Symbol:       subAccount.Symbol,
+			Points:       make([]*BalancePoint, 0, len(buckets)),
+		}
+		balance := decimal.Zero
+		for _, bucket := range buckets {
+			for len(pending) > 0 && (pending[0].Bucket == nil || !pending[0].Bucket.After(bucket)) {
+				balance = balance.Add(pending[0].Change)
+				pending = pending[1:]
+			}
+			curve.Points = append(curve.Points, &BalancePoint{Time: bucket.Format(time.RFC3339), Balance: balance})
+		}
+		curves = append(curves, curve)
+	}
+	return curves
+}
+
+// equityCurve values the balance curves in the quote currency at the last trade price of each
+// symbol at the end of every bucket
+func equityCurve(curves []*SubAccountBalanceCurve, prices []map[string]decimal.Decimal, buckets []time.Time) []*EquityPoint {
+	points := make([]*EquityPoint, 0, len(buckets))
+	for i, bucket := range buckets {
+		point := &EquityPoint{Time: bucket.Format(time.RFC3339), Equity: decimal.Zero}
+		unpriced := make(map[string]bool)
+		for _, curve := range curves {
+			balance := curve.Points[i].Balance
+			price, ok := prices[i][curve.Symbol]
+			if !ok {
+				if !balance.IsZero() {
+					unpriced[curve.Symbol] = true
+				}
+				continue
+			}
+			point.Equity = point.Equity.Add(balance.Mul(price).Round(MaxDecimalPlaces))
+		}
+		for symbol := range unpriced {
+			point.UnpricedSymbols = append(point.UnpricedSymbols, symbol)
+		}
+		sort.Strings(point.UnpricedSymbols)
+		points = append(points, point)
+	}
+	return points
+}
+
+// priceSeries returns the last known price of every symbol in the quote currency at the end of each
+// bucket, carrying prices forward across buckets without trades. The quote currency is priced at 1.
+func priceSeries(quoteSymbol string, prices []*repositories.BucketPrice, buckets []time.Time) []map[string]decimal.Decimal {
+	bySymbol := make(map[string][]*repositories.BucketPrice)
+	for _, price := range prices {
+		bySymbol[price.Symbol] = append(bySymbol[price.Symbol], price)
+	}
+	for _, symbolPrices := range bySymbol {
+		sort.SliceStable(symbolPrices, func(i, j int) bool { return bucketBefore(symbolPrices[i].Bucket, symbolPrices[j].Bucket) })
+	}
+
+	series := make([]map[string]decimal.Decimal, 0, len(buckets))
+	current := map[string]decimal.Decimal{quoteSymbol: decimal.NewFromInt(1)}
+	for _, bucket := range buckets {
+		for symbol, pending := range bySymbol {
+			for len(pending) > 0 && (pending[0].Bucket == nil || !pending[0].Bucket.After(bucket)) {
+				current[symbol] = pending[0].Price
+				pending = pending[1:]
+			}
+			bySymbol[symbol] = pending
+		}
+
+		snapshot := make(map[string]decimal.Decimal, len(current))
+		for symbol, price := range current {
+			snapshot[symbol] = price
+		}
+		series = append(series, snapshot)
+	}
+	return series
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"tiris-backend/internal/exchange"
 	"tiris-backend/internal/models"
 	"tiris-backend/internal/repositories"
 
@@ -20,17 +21,24 @@ type ExchangeBindingService interface {
 	UpdateExchangeBinding(ctx context.Context, id uuid.UUID, request *models.UpdateExchangeBindingRequest) (*models.ExchangeBinding, error)
 	DeleteExchangeBinding(ctx context.Context, id uuid.UUID) error
 	ValidateExchangeBindingAccess(ctx context.Context, userID uuid.UUID, bindingID uuid.UUID) (bool, error)
+	GetExchangeBindingCredentials(ctx context.Context, id uuid.UUID) (apiKey, apiSecret string, err error)
+	GetExchangeBalances(ctx context.Context, id uuid.UUID) ([]exchange.Balance, error)
 }
 
 // exchangeBindingService implements ExchangeBindingService
 type exchangeBindingService struct {
-	repo repositories.ExchangeBindingRepository
+	repo        repositories.ExchangeBindingRepository
+	credentials *models.ExchangeBindingManager
+	connectors  *exchange.Registry
 }
 
-// NewExchangeBindingService creates a new exchange binding service
-func NewExchangeBindingService(repo repositories.ExchangeBindingRepository) ExchangeBindingService {
+// NewExchangeBindingService creates a new exchange binding service. API credentials are encrypted
+// with the given manager before they are stored and verified with the connector of their exchange.
+func NewExchangeBindingService(repo repositories.ExchangeBindingRepository, credentials *models.ExchangeBindingManager, connectors *exchange.Registry) ExchangeBindingService {
 	return &exchangeBindingService{
-		repo: repo,
+		repo:        repo,
+		credentials: credentials,
+		connectors:  connectors,
 	}
 }
 
@@ -53,7 +61,7 @@ func (s *exchangeBindingService) CreateExchangeBinding(ctx context.Context, requ
 	// For private bindings, check for duplicate API credentials
 	if request.Type == "private" && request.APIKey != "" {
 		// Check for duplicate API key
-		existingByAPIKey, err := s.repo.GetByAPIKey(ctx, request.APIKey, request.UserID)
+		existingByAPIKey, err := s.repo.GetByAPIKey(ctx, s.credentials.HashCredential(request.APIKey), request.UserID)
 		if err != nil && err != models.ErrExchangeBindingNotFound {
 			return nil, fmt.Errorf("failed to check existing API key: %w", err)
 		}
@@ -62,7 +70,7 @@ func (s *exchangeBindingService) CreateExchangeBinding(ctx context.Context, requ
 		}
 
 		// Check for duplicate API secret
-		existingByAPISecret, err := s.repo.GetByAPISecret(ctx, request.APISecret, request.UserID)
+		existingByAPISecret, err := s.repo.GetByAPISecret(ctx, s.credentials.HashCredential(request.APISecret), request.UserID)
 		if err != nil && err != models.ErrExchangeBindingNotFound {
 			return nil, fmt.Errorf("failed to check existing API secret: %w", err)
 		}
@@ -73,7 +81,22 @@ func (s *exchangeBindingService) CreateExchangeBinding(ctx context.Context, requ
 
 	// Create the exchange binding
 	binding := request.ToExchangeBinding()
-	
+
+	// Bindings to real exchanges are created even if their credentials fail the check; the binding
+	// then carries the error status and the reason in its info
+	if needsVerification(binding) {
+		verification, err := s.verifyCredentials(ctx, binding.Exchange, request.APIKey, request.APISecret)
+		if err != nil {
+			return nil, err
+		}
+		binding.Status = verification.Status()
+		binding.Info = withVerification(binding.Info, verification)
+	}
+
+	if err := s.credentials.SetAPICredentials(binding, request.APIKey, request.APISecret); err != nil {
+		return nil, fmt.Errorf("failed to encrypt API credentials: %w", err)
+	}
+
 	// Generate ID if not set
 	if binding.ID == uuid.Nil {
 		binding.ID = uuid.New()
@@ -129,6 +152,19 @@ func (s *exchangeBindingService) UpdateExchangeBinding(ctx context.Context, id u
 		return s.GetExchangeBinding(ctx, id)
 	}
 
+	// New credentials and the reactivation of a binding are verified against the exchange
+	credentialsChanged := request.APIKey != nil || request.APISecret != nil
+	activated := request.Status != nil && *request.Status == models.ExchangeBindingStatusActive
+	if credentialsChanged || activated {
+		if err := s.verifyUpdate(ctx, id, request, updates); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.credentials.EncryptUpdates(updates); err != nil {
+		return nil, fmt.Errorf("failed to encrypt API credentials: %w", err)
+	}
+
 	// Perform update
 	if err := s.repo.Update(ctx, id, updates); err != nil {
 		return nil, fmt.Errorf("failed to update exchange binding: %w", err)
@@ -168,4 +204,112 @@ func (s *exchangeBindingService) ValidateExchangeBindingAccess(ctx context.Conte
 	}
 
 	return false, nil
-}
\ No newline at end of file
+}
+
+// GetExchangeBindingCredentials decrypts the API credentials of an exchange binding. It is meant for
+// internal consumers that connect to the exchange and must never back an API response.
+func (s *exchangeBindingService) GetExchangeBindingCredentials(ctx context.Context, id uuid.UUID) (apiKey, apiSecret string, err error) {
+	binding, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return "", "", err
+	}
+
+	apiKey, apiSecret, err = s.credentials.GetAPICredentials(binding)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decrypt API credentials: %w", err)
+	}
+
+	return apiKey, apiSecret, nil
+}
+
+// GetExchangeBalances reads the balances of the exchange account of a binding with its credentials
+func (s *exchangeBindingService) GetExchangeBalances(ctx context.Context, id uuid.UUID) ([]exchange.Balance, error) {
+	binding, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !needsVerification(binding) {
+		return nil, fmt.Errorf("exchange binding has no exchange account")
+	}
+
+	connector, err := s.connectors.Get(binding.Exchange)
+	if err != nil {
+		return nil, fmt.Errorf("exchange %s is not supported", binding.Exchange)
+	}
+	apiKey, apiSecret, err := s.credentials.GetAPICredentials(binding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt API credentials: %w", err)
+	}
+
+	balances, err := connector.GetBalances(ctx, exchange.Credentials{APIKey: apiKey, APISecret: apiSecret})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch exchange balances: %w", err)
+	}
+	return balances, nil
+}
+
+// verifyUpdate verifies the credentials a binding has after an update and records the result in the
+// update map. A failed verification sets the error status; a successful one reactivates the binding
+// unless the update sets another status.
+func (s *exchangeBindingService) verifyUpdate(ctx context.Context, id uuid.UUID, request *models.UpdateExchangeBindingRequest, updates map[string]interface{}) error {
+	binding, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !needsVerification(binding) {
+		return nil
+	}
+
+	apiKey, apiSecret, err := s.credentials.GetAPICredentials(binding)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt API credentials: %w", err)
+	}
+	if request.APIKey != nil {
+		apiKey = *request.APIKey
+	}
+	if request.APISecret != nil {
+		apiSecret = *request.APISecret
+	}
+
+	verification, err := s.verifyCredentials(ctx, binding.Exchange, apiKey, apiSecret)
+	if err != nil {
+		return err
+	}
+
+	info := binding.Info
+	if request.Info != nil {
+		info = request.Info
+	}
+	updates["info"] = withVerification(info, verification)
+	if !verification.Verified || request.Status == nil {
+		updates["status"] = verification.Status()
+	}
+
+	return nil
+}
+
+// verifyCredentials checks API credentials with the connector of the exchange
+func (s *exchangeBindingService) verifyCredentials(ctx context.Context, exchangeName, apiKey, apiSecret string) (*exchange.Verification, error) {
+	connector, err := s.connectors.Get(exchangeName)
+	if err != nil {
+		return nil, fmt.Errorf("exchange %s is not supported", exchangeName)
+	}
+
+	return exchange.Verify(ctx, connector, exchange.Credentials{APIKey: apiKey, APISecret: apiSecret}), nil
+}
+
+// needsVerification returns true for bindings with credentials for a real exchange. Public and
+// virtual bindings do not connect to an exchange.
+func needsVerification(binding *models.ExchangeBinding) bool {
+	return binding.IsPrivate() && !binding.IsVirtual()
+}
+
+// withVerification returns a copy of the binding info with the verification result
+func withVerification(info models.JSON, verification *exchange.Verification) models.JSON {
+	result := make(models.JSON, len(info)+1)
+	for key, value := range info {
+		result[key] = value
+	}
+	result["verification"] = verification.ToInfo()
+	return result
+}
@@ -5,9 +5,12 @@ import (
 	"errors"
 	"testing"
 
+	"tiris-backend/internal/exchange"
 	"tiris-backend/internal/models"
+	"tiris-backend/pkg/security"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -65,30 +68,41 @@ func (m *MockExchangeBindingRepository) GetByNameAndUser(ctx context.Context, na
 	return args.Get(0).(*models.ExchangeBinding), args.Error(1)
 }
 
-func (m *MockExchangeBindingRepository) GetByAPIKey(ctx context.Context, apiKey string, userID *uuid.UUID) (*models.ExchangeBinding, error) {
-	args := m.Called(ctx, apiKey, userID)
+func (m *MockExchangeBindingRepository) GetByAPIKey(ctx context.Context, apiKeyHash string, userID *uuid.UUID) (*models.ExchangeBinding, error) {
+	args := m.Called(ctx, apiKeyHash, userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.ExchangeBinding), args.Error(1)
 }
 
-func (m *MockExchangeBindingRepository) GetByAPISecret(ctx context.Context, apiSecret string, userID *uuid.UUID) (*models.ExchangeBinding, error) {
-	args := m.Called(ctx, apiSecret, userID)
+func (m *MockExchangeBindingRepository) GetByAPISecret(ctx context.Context, apiSecretHash string, userID *uuid.UUID) (*models.ExchangeBinding, error) {
+	args := m.Called(ctx, apiSecretHash, userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.ExchangeBinding), args.Error(1)
 }
 
+func newTestExchangeBindingManager(t *testing.T) *models.ExchangeBindingManager {
+	keyring, err := security.NewKeyring(map[int]string{1: "test-master-key-exchange-bindings-32-chars"}, 1)
+	require.NoError(t, err)
+	return models.NewExchangeBindingManager(keyring, "test-signing-key-exchange-bindings-32-chars")
+}
+
+func newTestConnectors() *exchange.Registry {
+	return exchange.NewFakeRegistry(exchange.NewFakeConnector())
+}
+
 // TestExchangeBindingService_CreateExchangeBinding tests the CreateExchangeBinding functionality
 func TestExchangeBindingService_CreateExchangeBinding(t *testing.T) {
 	ctx := context.Background()
+	credentials := newTestExchangeBindingManager(t)
 	userID := uuid.New()
 
 	t.Run("create_private_binding_success", func(t *testing.T) {
 		mockRepo := &MockExchangeBindingRepository{}
-		service := NewExchangeBindingService(mockRepo)
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
 
 		request := &models.CreateExchangeBindingRequest{
 			UserID:    &userID,
@@ -101,8 +115,8 @@ func TestExchangeBindingService_CreateExchangeBinding(t *testing.T) {
 
 		// Mock repository calls
 		mockRepo.On("GetByNameAndUser", ctx, "My Binance", &userID).Return(nil, models.ErrExchangeBindingNotFound)
-		mockRepo.On("GetByAPIKey", ctx, "test_api_key", &userID).Return(nil, models.ErrExchangeBindingNotFound)
-		mockRepo.On("GetByAPISecret", ctx, "test_api_secret", &userID).Return(nil, models.ErrExchangeBindingNotFound)
+		mockRepo.On("GetByAPIKey", ctx, credentials.HashCredential("test_api_key"), &userID).Return(nil, models.ErrExchangeBindingNotFound)
+		mockRepo.On("GetByAPISecret", ctx, credentials.HashCredential("test_api_secret"), &userID).Return(nil, models.ErrExchangeBindingNotFound)
 		mockRepo.On("Create", ctx, mock.AnythingOfType("*models.ExchangeBinding")).Return(nil)
 
 		binding, err := service.CreateExchangeBinding(ctx, request)
@@ -113,12 +127,96 @@ func TestExchangeBindingService_CreateExchangeBinding(t *testing.T) {
 		assert.Equal(t, "binance", binding.Exchange)
 		assert.Equal(t, "private", binding.Type)
 
+		// Credentials are stored encrypted
+		assert.NotEqual(t, "test_api_key", binding.APIKey)
+		assert.NotEqual(t, "test_api_secret", binding.APISecret)
+		assert.Equal(t, credentials.HashCredential("test_api_key"), binding.APIKeyHash)
+		assert.Equal(t, credentials.HashCredential("test_api_secret"), binding.APISecretHash)
+		assert.Equal(t, "test..._key", binding.GetMaskedAPIKey())
+
+		apiKey, apiSecret, err := credentials.GetAPICredentials(binding)
+		require.NoError(t, err)
+		assert.Equal(t, "test_api_key", apiKey)
+		assert.Equal(t, "test_api_secret", apiSecret)
+
+		// The credentials were verified against the exchange
+		assert.Equal(t, models.ExchangeBindingStatusActive, binding.Status)
+		verification, ok := binding.Info["verification"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, true, verification["verified"])
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("create_binding_rejected_credentials_sets_error_status", func(t *testing.T) {
+		mockRepo := &MockExchangeBindingRepository{}
+		fake := exchange.NewFakeConnector()
+		fake.RejectAPIKey("revoked_api_key")
+		service := NewExchangeBindingService(mockRepo, credentials, exchange.NewFakeRegistry(fake))
+
+		request := &models.CreateExchangeBindingRequest{
+			UserID:    &userID,
+			Name:      "Revoked Kraken",
+			Exchange:  "kraken",
+			Type:      "private",
+			APIKey:    "revoked_api_key",
+			APISecret: "revoked_api_secret",
+			Info:      models.JSON{"label": "main"},
+		}
+
+		mockRepo.On("GetByNameAndUser", ctx, "Revoked Kraken", &userID).Return(nil, models.ErrExchangeBindingNotFound)
+		mockRepo.On("GetByAPIKey", ctx, credentials.HashCredential("revoked_api_key"), &userID).Return(nil, models.ErrExchangeBindingNotFound)
+		mockRepo.On("GetByAPISecret", ctx, credentials.HashCredential("revoked_api_secret"), &userID).Return(nil, models.ErrExchangeBindingNotFound)
+		mockRepo.On("Create", ctx, mock.MatchedBy(func(binding *models.ExchangeBinding) bool {
+			return binding.Status == models.ExchangeBindingStatusError
+		})).Return(nil)
+
+		binding, err := service.CreateExchangeBinding(ctx, request)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.ExchangeBindingStatusError, binding.Status)
+		assert.Equal(t, "main", binding.Info["label"])
+		verification, ok := binding.Info["verification"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, false, verification["verified"])
+		assert.Contains(t, verification["error"], "invalid API credentials")
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("create_binding_exchange_unreachable_sets_error_status", func(t *testing.T) {
+		mockRepo := &MockExchangeBindingRepository{}
+		fake := exchange.NewFakeConnector()
+		fake.SetUnreachable(true)
+		service := NewExchangeBindingService(mockRepo, credentials, exchange.NewFakeRegistry(fake))
+
+		request := &models.CreateExchangeBindingRequest{
+			UserID:    &userID,
+			Name:      "My Gate",
+			Exchange:  "gate",
+			Type:      "private",
+			APIKey:    "gate_api_key",
+			APISecret: "gate_api_secret",
+		}
+
+		mockRepo.On("GetByNameAndUser", ctx, "My Gate", &userID).Return(nil, models.ErrExchangeBindingNotFound)
+		mockRepo.On("GetByAPIKey", ctx, credentials.HashCredential("gate_api_key"), &userID).Return(nil, models.ErrExchangeBindingNotFound)
+		mockRepo.On("GetByAPISecret", ctx, credentials.HashCredential("gate_api_secret"), &userID).Return(nil, models.ErrExchangeBindingNotFound)
+		mockRepo.On("Create", ctx, mock.AnythingOfType("*models.ExchangeBinding")).Return(nil)
+
+		binding, err := service.CreateExchangeBinding(ctx, request)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.ExchangeBindingStatusError, binding.Status)
+		verification := binding.Info["verification"].(map[string]interface{})
+		assert.Contains(t, verification["error"], "exchange unreachable")
+
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("create_public_binding_success", func(t *testing.T) {
 		mockRepo := &MockExchangeBindingRepository{}
-		service := NewExchangeBindingService(mockRepo)
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
 
 		request := &models.CreateExchangeBindingRequest{
 			UserID:    nil,
@@ -143,9 +241,34 @@ func TestExchangeBindingService_CreateExchangeBinding(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("create_binding_api_key_in_use_error", func(t *testing.T) {
+		mockRepo := &MockExchangeBindingRepository{}
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
+
+		request := &models.CreateExchangeBindingRequest{
+			UserID:    &userID,
+			Name:      "Second Binance",
+			Exchange:  "binance",
+			Type:      "private",
+			APIKey:    "test_api_key",
+			APISecret: "other_api_secret",
+		}
+
+		mockRepo.On("GetByNameAndUser", ctx, "Second Binance", &userID).Return(nil, models.ErrExchangeBindingNotFound)
+		mockRepo.On("GetByAPIKey", ctx, credentials.HashCredential("test_api_key"), &userID).Return(&models.ExchangeBinding{ID: uuid.New()}, nil)
+
+		binding, err := service.CreateExchangeBinding(ctx, request)
+
+		require.Error(t, err)
+		assert.Nil(t, binding)
+		assert.Equal(t, "API key already in use", err.Error())
+
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("create_binding_name_exists_error", func(t *testing.T) {
 		mockRepo := &MockExchangeBindingRepository{}
-		service := NewExchangeBindingService(mockRepo)
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
 
 		request := &models.CreateExchangeBindingRequest{
 			UserID:    &userID,
@@ -174,7 +297,7 @@ func TestExchangeBindingService_CreateExchangeBinding(t *testing.T) {
 
 	t.Run("create_binding_invalid_request", func(t *testing.T) {
 		mockRepo := &MockExchangeBindingRepository{}
-		service := NewExchangeBindingService(mockRepo)
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
 
 		request := &models.CreateExchangeBindingRequest{
 			UserID:    &userID,
@@ -199,11 +322,12 @@ func TestExchangeBindingService_CreateExchangeBinding(t *testing.T) {
 // TestExchangeBindingService_GetExchangeBinding tests the GetExchangeBinding functionality
 func TestExchangeBindingService_GetExchangeBinding(t *testing.T) {
 	ctx := context.Background()
+	credentials := newTestExchangeBindingManager(t)
 	bindingID := uuid.New()
 
 	t.Run("get_binding_success", func(t *testing.T) {
 		mockRepo := &MockExchangeBindingRepository{}
-		service := NewExchangeBindingService(mockRepo)
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
 
 		expectedBinding := &models.ExchangeBinding{
 			ID:       bindingID,
@@ -224,7 +348,7 @@ func TestExchangeBindingService_GetExchangeBinding(t *testing.T) {
 
 	t.Run("get_binding_not_found", func(t *testing.T) {
 		mockRepo := &MockExchangeBindingRepository{}
-		service := NewExchangeBindingService(mockRepo)
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
 
 		mockRepo.On("GetByID", ctx, bindingID).Return(nil, models.ErrExchangeBindingNotFound)
 
@@ -241,11 +365,12 @@ func TestExchangeBindingService_GetExchangeBinding(t *testing.T) {
 // TestExchangeBindingService_GetUserExchangeBindings tests getting user's bindings
 func TestExchangeBindingService_GetUserExchangeBindings(t *testing.T) {
 	ctx := context.Background()
+	credentials := newTestExchangeBindingManager(t)
 	userID := uuid.New()
 
 	t.Run("get_user_bindings_success", func(t *testing.T) {
 		mockRepo := &MockExchangeBindingRepository{}
-		service := NewExchangeBindingService(mockRepo)
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
 
 		expectedBindings := []*models.ExchangeBinding{
 			{ID: uuid.New(), Name: "Binding 1"},
@@ -274,7 +399,7 @@ func TestExchangeBindingService_GetUserExchangeBindings(t *testing.T) {
 
 	t.Run("get_user_bindings_empty", func(t *testing.T) {
 		mockRepo := &MockExchangeBindingRepository{}
-		service := NewExchangeBindingService(mockRepo)
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
 
 		expectedPagination := &models.PaginationResult{
 			Total:       0,
@@ -300,10 +425,11 @@ func TestExchangeBindingService_GetUserExchangeBindings(t *testing.T) {
 // TestExchangeBindingService_GetPublicExchangeBindings tests getting public bindings
 func TestExchangeBindingService_GetPublicExchangeBindings(t *testing.T) {
 	ctx := context.Background()
+	credentials := newTestExchangeBindingManager(t)
 
 	t.Run("get_all_public_bindings", func(t *testing.T) {
 		mockRepo := &MockExchangeBindingRepository{}
-		service := NewExchangeBindingService(mockRepo)
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
 
 		expectedBindings := []*models.ExchangeBinding{
 			{ID: uuid.New(), Name: "Public Binance", Type: "public"},
@@ -322,7 +448,7 @@ func TestExchangeBindingService_GetPublicExchangeBindings(t *testing.T) {
 
 	t.Run("get_public_bindings_by_exchange", func(t *testing.T) {
 		mockRepo := &MockExchangeBindingRepository{}
-		service := NewExchangeBindingService(mockRepo)
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
 
 		expectedBindings := []*models.ExchangeBinding{
 			{ID: uuid.New(), Name: "Public Binance", Exchange: "binance", Type: "public"},
@@ -342,11 +468,12 @@ func TestExchangeBindingService_GetPublicExchangeBindings(t *testing.T) {
 // TestExchangeBindingService_UpdateExchangeBinding tests updating bindings
 func TestExchangeBindingService_UpdateExchangeBinding(t *testing.T) {
 	ctx := context.Background()
+	credentials := newTestExchangeBindingManager(t)
 	bindingID := uuid.New()
 
 	t.Run("update_binding_success", func(t *testing.T) {
 		mockRepo := &MockExchangeBindingRepository{}
-		service := NewExchangeBindingService(mockRepo)
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
 
 		newName := "Updated Name"
 		request := &models.UpdateExchangeBindingRequest{
@@ -369,9 +496,88 @@ func TestExchangeBindingService_UpdateExchangeBinding(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("update_binding_credentials_encrypted", func(t *testing.T) {
+		mockRepo := &MockExchangeBindingRepository{}
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
+
+		newAPIKey := "new_api_key_12345"
+		request := &models.UpdateExchangeBindingRequest{
+			APIKey: &newAPIKey,
+		}
+
+		mockRepo.On("Update", ctx, bindingID, mock.MatchedBy(func(updates map[string]interface{}) bool {
+			_, hasSecret := updates["api_secret"]
+			return updates["api_key"] != newAPIKey &&
+				updates["api_key_hash"] == credentials.HashCredential(newAPIKey) &&
+				updates["masked_api_key"] == "new_...2345" &&
+				!hasSecret
+		})).Return(nil)
+		mockRepo.On("GetByID", ctx, bindingID).Return(&models.ExchangeBinding{ID: bindingID}, nil)
+
+		_, err := service.UpdateExchangeBinding(ctx, bindingID, request)
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("update_binding_credentials_reverified", func(t *testing.T) {
+		mockRepo := &MockExchangeBindingRepository{}
+		fake := exchange.NewFakeConnector()
+		fake.RejectAPIKey("revoked_api_key")
+		service := NewExchangeBindingService(mockRepo, credentials, exchange.NewFakeRegistry(fake))
+
+		existing := &models.ExchangeBinding{ID: bindingID, Exchange: "coinbase", Type: "private", Status: models.ExchangeBindingStatusActive}
+		require.NoError(t, credentials.SetAPICredentials(existing, "old_api_key", "old_api_secret"))
+
+		newAPIKey := "revoked_api_key"
+		request := &models.UpdateExchangeBindingRequest{
+			APIKey: &newAPIKey,
+		}
+
+		mockRepo.On("GetByID", ctx, bindingID).Return(existing, nil)
+		mockRepo.On("Update", ctx, bindingID, mock.MatchedBy(func(updates map[string]interface{}) bool {
+			info, ok := updates["info"].(models.JSON)
+			if !ok {
+				return false
+			}
+			verification := info["verification"].(map[string]interface{})
+			return updates["status"] == models.ExchangeBindingStatusError && verification["verified"] == false
+		})).Return(nil)
+
+		_, err := service.UpdateExchangeBinding(ctx, bindingID, request)
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("update_binding_reactivation_verified", func(t *testing.T) {
+		mockRepo := &MockExchangeBindingRepository{}
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
+
+		existing := &models.ExchangeBinding{ID: bindingID, Exchange: "binance", Type: "private", Status: models.ExchangeBindingStatusError}
+		require.NoError(t, credentials.SetAPICredentials(existing, "fixed_api_key", "fixed_api_secret"))
+
+		status := models.ExchangeBindingStatusActive
+		request := &models.UpdateExchangeBindingRequest{
+			Status: &status,
+		}
+
+		mockRepo.On("GetByID", ctx, bindingID).Return(existing, nil)
+		mockRepo.On("Update", ctx, bindingID, mock.MatchedBy(func(updates map[string]interface{}) bool {
+			info, ok := updates["info"].(models.JSON)
+			return ok && updates["status"] == models.ExchangeBindingStatusActive &&
+				info["verification"].(map[string]interface{})["verified"] == true
+		})).Return(nil)
+
+		_, err := service.UpdateExchangeBinding(ctx, bindingID, request)
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("update_binding_not_found", func(t *testing.T) {
 		mockRepo := &MockExchangeBindingRepository{}
-		service := NewExchangeBindingService(mockRepo)
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
 
 		newName := "Updated Name"
 		request := &models.UpdateExchangeBindingRequest{
@@ -393,11 +599,12 @@ func TestExchangeBindingService_UpdateExchangeBinding(t *testing.T) {
 // TestExchangeBindingService_DeleteExchangeBinding tests deleting bindings
 func TestExchangeBindingService_DeleteExchangeBinding(t *testing.T) {
 	ctx := context.Background()
+	credentials := newTestExchangeBindingManager(t)
 	bindingID := uuid.New()
 
 	t.Run("delete_binding_success", func(t *testing.T) {
 		mockRepo := &MockExchangeBindingRepository{}
-		service := NewExchangeBindingService(mockRepo)
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
 
 		mockRepo.On("Delete", ctx, bindingID).Return(nil)
 
@@ -410,7 +617,7 @@ func TestExchangeBindingService_DeleteExchangeBinding(t *testing.T) {
 
 	t.Run("delete_binding_not_found", func(t *testing.T) {
 		mockRepo := &MockExchangeBindingRepository{}
-		service := NewExchangeBindingService(mockRepo)
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
 
 		mockRepo.On("Delete", ctx, bindingID).Return(models.ErrExchangeBindingNotFound)
 
@@ -424,7 +631,7 @@ func TestExchangeBindingService_DeleteExchangeBinding(t *testing.T) {
 
 	t.Run("delete_binding_in_use", func(t *testing.T) {
 		mockRepo := &MockExchangeBindingRepository{}
-		service := NewExchangeBindingService(mockRepo)
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
 
 		mockRepo.On("Delete", ctx, bindingID).Return(errors.New("foreign key constraint violation"))
 
@@ -440,13 +647,14 @@ func TestExchangeBindingService_DeleteExchangeBinding(t *testing.T) {
 // TestExchangeBindingService_ValidateExchangeBindingAccess tests access validation
 func TestExchangeBindingService_ValidateExchangeBindingAccess(t *testing.T) {
 	ctx := context.Background()
+	credentials := newTestExchangeBindingManager(t)
 	userID := uuid.New()
 	otherUserID := uuid.New()
 	bindingID := uuid.New()
 
 	t.Run("access_own_private_binding", func(t *testing.T) {
 		mockRepo := &MockExchangeBindingRepository{}
-		service := NewExchangeBindingService(mockRepo)
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
 
 		binding := &models.ExchangeBinding{
 			ID:     bindingID,
@@ -466,7 +674,7 @@ func TestExchangeBindingService_ValidateExchangeBindingAccess(t *testing.T) {
 
 	t.Run("access_public_binding", func(t *testing.T) {
 		mockRepo := &MockExchangeBindingRepository{}
-		service := NewExchangeBindingService(mockRepo)
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
 
 		binding := &models.ExchangeBinding{
 			ID:     bindingID,
@@ -486,7 +694,7 @@ func TestExchangeBindingService_ValidateExchangeBindingAccess(t *testing.T) {
 
 	t.Run("no_access_other_user_binding", func(t *testing.T) {
 		mockRepo := &MockExchangeBindingRepository{}
-		service := NewExchangeBindingService(mockRepo)
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
 
 		binding := &models.ExchangeBinding{
 			ID:     bindingID,
@@ -506,7 +714,7 @@ func TestExchangeBindingService_ValidateExchangeBindingAccess(t *testing.T) {
 
 	t.Run("binding_not_found", func(t *testing.T) {
 		mockRepo := &MockExchangeBindingRepository{}
-		service := NewExchangeBindingService(mockRepo)
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
 
 		mockRepo.On("GetByID", ctx, bindingID).Return(nil, models.ErrExchangeBindingNotFound)
 
@@ -518,4 +726,105 @@ func TestExchangeBindingService_ValidateExchangeBindingAccess(t *testing.T) {
 
 		mockRepo.AssertExpectations(t)
 	})
-}
\ No newline at end of file
+}
+
+// TestExchangeBindingService_GetExchangeBindingCredentials tests decrypting credentials for internal use
+func TestExchangeBindingService_GetExchangeBindingCredentials(t *testing.T) {
+	ctx := context.Background()
+	credentials := newTestExchangeBindingManager(t)
+	bindingID := uuid.New()
+
+	t.Run("decrypt_credentials_success", func(t *testing.T) {
+		mockRepo := &MockExchangeBindingRepository{}
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
+
+		binding := &models.ExchangeBinding{ID: bindingID, Type: "private"}
+		require.NoError(t, credentials.SetAPICredentials(binding, "test_api_key", "test_api_secret"))
+
+		mockRepo.On("GetByID", ctx, bindingID).Return(binding, nil)
+
+		apiKey, apiSecret, err := service.GetExchangeBindingCredentials(ctx, bindingID)
+
+		require.NoError(t, err)
+		assert.Equal(t, "test_api_key", apiKey)
+		assert.Equal(t, "test_api_secret", apiSecret)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("decrypt_with_other_key_fails", func(t *testing.T) {
+		mockRepo := &MockExchangeBindingRepository{}
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
+
+		otherKeyring, err := security.NewKeyring(map[int]string{1: "other-master-key-exchange-bindings-32-chars"}, 1)
+		require.NoError(t, err)
+		otherCredentials := models.NewExchangeBindingManager(otherKeyring, "test-signing-key-exchange-bindings-32-chars")
+		binding := &models.ExchangeBinding{ID: bindingID, Type: "private"}
+		require.NoError(t, otherCredentials.SetAPICredentials(binding, "test_api_key", "test_api_secret"))
+
+		mockRepo.On("GetByID", ctx, bindingID).Return(binding, nil)
+
+		apiKey, apiSecret, err := service.GetExchangeBindingCredentials(ctx, bindingID)
+
+		require.Error(t, err)
+		assert.Empty(t, apiKey)
+		assert.Empty(t, apiSecret)
+		assert.Contains(t, err.Error(), "failed to decrypt API credentials")
+	})
+}
+
+// TestExchangeBindingService_GetExchangeBalances tests reading balances from the exchange of a binding
+func TestExchangeBindingService_GetExchangeBalances(t *testing.T) {
+	ctx := context.Background()
+	credentials := newTestExchangeBindingManager(t)
+	bindingID := uuid.New()
+
+	t.Run("balances_of_private_binding", func(t *testing.T) {
+		mockRepo := &MockExchangeBindingRepository{}
+		fake := exchange.NewFakeConnector()
+		fake.SetBalances([]exchange.Balance{{Asset: "BTC", Free: decimal.NewFromInt(1)}})
+		service := NewExchangeBindingService(mockRepo, credentials, exchange.NewFakeRegistry(fake))
+
+		binding := &models.ExchangeBinding{ID: bindingID, Exchange: "binance", Type: "private"}
+		require.NoError(t, credentials.SetAPICredentials(binding, "test_api_key", "test_api_secret"))
+		mockRepo.On("GetByID", ctx, bindingID).Return(binding, nil)
+
+		balances, err := service.GetExchangeBalances(ctx, bindingID)
+
+		require.NoError(t, err)
+		require.Len(t, balances, 1)
+		assert.Equal(t, "BTC", balances[0].Asset)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejected_credentials", func(t *testing.T) {
+		mockRepo := &MockExchangeBindingRepository{}
+		fake := exchange.NewFakeConnector()
+		fake.RejectAPIKey("test_api_key")
+		service := NewExchangeBindingService(mockRepo, credentials, exchange.NewFakeRegistry(fake))
+
+		binding := &models.ExchangeBinding{ID: bindingID, Exchange: "binance", Type: "private"}
+		require.NoError(t, credentials.SetAPICredentials(binding, "test_api_key", "test_api_secret"))
+		mockRepo.On("GetByID", ctx, bindingID).Return(binding, nil)
+
+		balances, err := service.GetExchangeBalances(ctx, bindingID)
+
+		require.Error(t, err)
+		assert.Nil(t, balances)
+		assert.ErrorIs(t, err, exchange.ErrInvalidCredentials)
+		assert.Contains(t, err.Error(), "failed to fetch exchange balances")
+	})
+
+	t.Run("virtual_binding", func(t *testing.T) {
+		mockRepo := &MockExchangeBindingRepository{}
+		service := NewExchangeBindingService(mockRepo, credentials, newTestConnectors())
+
+		mockRepo.On("GetByID", ctx, bindingID).Return(&models.ExchangeBinding{ID: bindingID, Exchange: "virtual", Type: "public"}, nil)
+
+		balances, err := service.GetExchangeBalances(ctx, bindingID)
+
+		require.Error(t, err)
+		assert.Nil(t, balances)
+		assert.Equal(t, "exchange binding has no exchange account", err.Error())
+	})
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"tiris-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// FeeReason is the transaction reason of fees charged to a separate fee account
+const FeeReason = "fee"
+
+// resolveFeeAccount returns the sub-account charged with the fee of a trade, or nil when the fee is
+// settled with the currency account as part of the trade. The fee account may be the stock or currency
+// account of the trade itself, in which case the same record is returned so balances stay chained.
+// Any other fee account must belong to the trading.
+func (p *TradingLogProcessor) resolveFeeAccount(ctx context.Context, tradingID uuid.UUID, tradingInfo *TradingLogInfo, stockAccount, currencyAccount *models.SubAccount) (*models.SubAccount, error) {
+	if tradingInfo.FeeAccountID == nil {
+		return nil, nil
+	}
+
+	var feeAccount *models.SubAccount
+	switch *tradingInfo.FeeAccountID {
+	case stockAccount.ID:
+		feeAccount = stockAccount
+	case currencyAccount.ID:
+		feeAccount = currencyAccount
+	default:
+		account, err := p.getUserSubAccount(ctx, stockAccount.UserID, *tradingInfo.FeeAccountID, "fee account")
+		if err != nil {
+			return nil, err
+		}
+		if account.TradingID != tradingID {
+			return nil, fmt.Errorf("fee account must belong to the trading")
+		}
+		feeAccount = account
+	}
+
+	if feeAccount.Symbol != tradingInfo.FeeAsset {
+		return nil, fmt.Errorf("fee account holds %s, not fee asset %s", feeAccount.Symbol, tradingInfo.FeeAsset)
+	}
+
+	return feeAccount, nil
+}
+
+// checkFeeBalance verifies that the fee account can pay the fee
+func checkFeeBalance(available, fee decimal.Decimal) error {
+	if available.LessThan(fee) {
+		return fmt.Errorf("insufficient balance in fee account: required %s, available %s",
+			fee.StringFixed(MaxDecimalPlaces), available.StringFixed(MaxDecimalPlaces))
+	}
+	return nil
+}
+
+// feeInCurrency values the fee of a trade in its currency. Fees charged in the stock are valued at the
+// trade price; fees charged in a third asset have no price in the trade and are left out.
+func feeInCurrency(tradingInfo *TradingLogInfo, feeAccount, stockAccount, currencyAccount *models.SubAccount) decimal.Decimal {
+	switch feeAccount {
+	case nil, currencyAccount:
+		return tradingInfo.Fee
+	case stockAccount:
+		return tradingInfo.Fee.Mul(tradingInfo.Price).Round(MaxDecimalPlaces)
+	default:
+		return decimal.Zero
+	}
+}
+
+// chargeFee debits the fee from the fee account as a separate fee transaction. Accounts already
+// updated by the trade are not reported again.
+func (p *TradingLogProcessor) chargeFee(ctx context.Context, tradingInfo *TradingLogInfo, feeAccount *models.SubAccount, updatedAccounts []*models.SubAccount, tradingLogInfo map[string]interface{}) ([]*models.Transaction, []*models.SubAccount, error) {
+	if feeAccount == nil || !tradingInfo.Fee.IsPositive() {
+		return nil, nil, nil
+	}
+
+	var transactions []*models.Transaction
+
+	newBalance := feeAccount.Balance.Sub(tradingInfo.Fee)
+	transactionID, err := p.repos.SubAccount.UpdateBalance(ctx, feeAccount.ID, newBalance, tradingInfo.Fee, "debit", FeeReason, tradingLogInfo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to update fee account balance: %w", err)
+	}
+
+	// Get the created fee transaction
+	if transactionID != nil {
+		transaction, err := p.repos.Transaction.GetByID(ctx, *transactionID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get fee transaction: %w", err)
+		}
+		if transaction != nil {
+			transaction.QuoteSymbol = &tradingInfo.FeeAsset
+			transactions = append(transactions, transaction)
+		}
+	}
+
+	feeAccount.Balance = newBalance
+
+	for _, account := range updatedAccounts {
+		if account == feeAccount {
+			return transactions, nil, nil
+		}
+	}
+	return transactions, []*models.SubAccount{feeAccount}, nil
+}
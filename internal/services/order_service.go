@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tiris-backend/internal/models"
+	"tiris-backend/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// OrderService handles order query business logic. Orders are created and advanced by the order
+// events of the trading bots.
+type OrderService struct {
+	repos *repositories.Repositories
+}
+
+// NewOrderService creates a new order service
+func NewOrderService(repos *repositories.Repositories) *OrderService {
+	return &OrderService{
+		repos: repos,
+	}
+}
+
+// OrderResponse represents order information in responses
+type OrderResponse struct {
+	ID           uuid.UUID              `json:"id"`
+	UserID       uuid.UUID              `json:"user_id"`
+	TradingID    uuid.UUID              `json:"trading_id"`
+	SubAccountID uuid.UUID              `json:"sub_account_id"`
+	OrderID      string                 `json:"order_id" example:"8389765493"`
+	Symbol       string                 `json:"symbol" example:"ETH/USDT"`
+	Side         string                 `json:"side" example:"buy"`
+	Type         string                 `json:"type" example:"limit"`
+	Amount       decimal.Decimal        `json:"amount" swaggertype:"string" example:"1.5"`
+	Price        *decimal.Decimal       `json:"price,omitempty" swaggertype:"string" example:"3000"`
+	FilledAmount decimal.Decimal        `json:"filled_amount" swaggertype:"string" example:"0.5"`
+	AvgPrice     *decimal.Decimal       `json:"avg_price,omitempty" swaggertype:"string" example:"2999.5"`
+	Status       string                 `json:"status" example:"partially_filled"`
+	Info         map[string]interface{} `json:"info"`
+	CreatedAt    string                 `json:"created_at"`
+	UpdatedAt    string                 `json:"updated_at"`
+}
+
+// OrderQueryRequest represents order query parameters
+type OrderQueryRequest struct {
+	Status    *string    `form:"status" binding:"omitempty,oneof=open partially_filled filled cancelled failed"`
+	Side      *string    `form:"side" binding:"omitempty,oneof=buy sell"`
+	Symbol    *string    `form:"symbol"`
+	StartDate *time.Time `form:"start_date" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndDate   *time.Time `form:"end_date" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit     int        `form:"limit" binding:"omitempty,min=1,max=1000"`
+	Offset    int        `form:"offset" binding:"omitempty,min=0"`
+}
+
+// OrderQueryResponse represents paginated order results
+type OrderQueryResponse struct {
+	Orders  []*OrderResponse `json:"orders"`
+	Total   int64            `json:"total"`
+	Limit   int              `json:"limit"`
+	Offset  int              `json:"offset"`
+	HasMore bool             `json:"has_more"`
+}
+
+// GetUserOrders retrieves the orders of a user with filtering
+func (s *OrderService) GetUserOrders(ctx context.Context, userID uuid.UUID, req *OrderQueryRequest) (*OrderQueryResponse, error) {
+	filters, err := orderFilters(req)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, total, err := s.repos.Order.GetByUserID(ctx, userID, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user orders: %w", err)
+	}
+
+	return s.convertToOrderQueryResponse(orders, total, req), nil
+}
+
+// GetTradingOrders retrieves the orders of a trading with filtering
+func (s *OrderService) GetTradingOrders(ctx context.Context, userID, tradingID uuid.UUID, req *OrderQueryRequest) (*OrderQueryResponse, error) {
+	// Verify user owns the trading
+	trading, err := s.repos.Trading.GetByID(ctx, tradingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify trading: %w", err)
+	}
+	if trading == nil || trading.UserID != userID {
+		return nil, fmt.Errorf("trading not found")
+	}
+
+	filters, err := orderFilters(req)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, total, err := s.repos.Order.GetByTradingID(ctx, tradingID, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trading orders: %w", err)
+	}
+
+	return s.convertToOrderQueryResponse(orders, total, req), nil
+}
+
+// orderFilters validates an order query and converts it to repository filters
+func orderFilters(req *OrderQueryRequest) (repositories.OrderFilters, error) {
+	// Set default pagination
+	if req.Limit == 0 {
+		req.Limit = 100
+	}
+
+	if req.StartDate != nil && req.EndDate != nil && req.StartDate.After(*req.EndDate) {
+		return repositories.OrderFilters{}, fmt.Errorf("start date cannot be after end date")
+	}
+
+	return repositories.OrderFilters{
+		Status:    req.Status,
+		Side:      req.Side,
+		Symbol:    req.Symbol,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Limit:     req.Limit,
+		Offset:    req.Offset,
+	}, nil
+}
+
+func (s *OrderService) convertToOrderQueryResponse(orders []*models.Order, total int64, req *OrderQueryRequest) *OrderQueryResponse {
+	responses := make([]*OrderResponse, 0, len(orders))
+	for _, order := range orders {
+		responses = append(responses, s.convertToOrderResponse(order))
+	}
+
+	return &OrderQueryResponse{
+		Orders:  responses,
+		Total:   total,
+		Limit:   req.Limit,
+		Offset:  req.Offset,
+		HasMore: int64(req.Offset+req.Limit) < total,
+	}
+}
+
+func (s *OrderService) convertToOrderResponse(order *models.Order) *OrderResponse {
+	var info map[string]interface{}
+	if len(order.Info) > 0 {
+		info = order.Info
+	} else {
+		info = make(map[string]interface{})
+	}
+
+	return &OrderResponse{
+		ID:           order.ID,
+		UserID:       order.UserID,
+		TradingID:    order.TradingID,
+		SubAccountID: order.SubAccountID,
+		OrderID:      order.OrderID,
+		Symbol:       order.Symbol,
+		Side:         order.Side,
+		Type:         order.Type,
+		Amount:       order.Amount,
+		Price:        order.Price,
+		FilledAmount: order.FilledAmount,
+		AvgPrice:     order.AvgPrice,
+		Status:       order.Status,
+		Info:         info,
+		CreatedAt:    order.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:    order.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
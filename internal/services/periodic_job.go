@@ -0,0 +1,39 @@
+package services
+
+import "time"
+
+// periodicJob calls run at every tick of its interval until it is stopped
+type periodicJob struct {
+	run    func()
+	ticker *time.Ticker
+	done   chan bool
+}
+
+// newPeriodicJob creates a job that calls run at every interval once started
+func newPeriodicJob(interval time.Duration, run func()) *periodicJob {
+	return &periodicJob{
+		run:    run,
+		ticker: time.NewTicker(interval),
+		done:   make(chan bool),
+	}
+}
+
+// Start begins the loop of the job
+func (j *periodicJob) Start() {
+	go func() {
+		for {
+			select {
+			case <-j.ticker.C:
+				j.run()
+			case <-j.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the loop of the job
+func (j *periodicJob) Stop() {
+	j.ticker.Stop()
+	j.done <- true
+}
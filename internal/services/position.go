@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"tiris-backend/internal/models"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// averageCostAfterBuy returns the average cost per unit of a position after buying volume units
+// for cost (price * volume + fee). Fees are part of the cost basis.
+func averageCostAfterBuy(quantity, avgCost, volume, cost decimal.Decimal) decimal.Decimal {
+	newQuantity := quantity.Add(volume)
+	if !newQuantity.IsPositive() {
+		return avgCost
+	}
+	return quantity.Mul(avgCost).Add(cost).DivRound(newQuantity, MaxDecimalPlaces)
+}
+
+// averageCostAfterBuyReversal undoes averageCostAfterBuy for a buy that is being reversed.
+// Quantity is the position size before the reversal.
+func averageCostAfterBuyReversal(quantity, avgCost, volume, cost decimal.Decimal) decimal.Decimal {
+	remaining := quantity.Sub(volume)
+	if !remaining.IsPositive() {
+		// Nothing left to value; keep the last known cost so a new buy starts from scratch anyway
+		return avgCost
+	}
+	restored := quantity.Mul(avgCost).Sub(cost).DivRound(remaining, MaxDecimalPlaces)
+	if restored.IsNegative() {
+		return decimal.Zero
+	}
+	return restored
+}
+
+// averageCostAfterSellReversal undoes positionAfterSell for a sale of volume units at price that is
+// being reversed. Before is the position size before the sale, avgCost the current average cost and
+// pnl the profit the sale realized net of fee, the fee valued in the currency.
+func averageCostAfterSellReversal(before, avgCost, price, volume, pnl, fee decimal.Decimal) decimal.Decimal {
+	closed := decimal.Max(decimal.Min(volume, before), decimal.Zero)
+	opened := volume.Sub(closed)
+	if !opened.IsPositive() || before.IsZero() {
+		// Selling out of a long position kept its average cost; a flat position has none to restore
+		return avgCost
+	}
+	if before.IsPositive() {
+		// The sale closed the long position and opened a short at the price: the cost of the closed
+		// units is what their realized profit was measured against
+		return price.Sub(pnl.Add(fee).DivRound(closed, MaxDecimalPlaces))
+	}
+	// The sale added to a short position
+	short := before.Neg()
+	return short.Add(opened).Mul(avgCost).Sub(opened.Mul(price)).DivRound(short, MaxDecimalPlaces)
+}
+
+// realizedPnL returns the profit of selling volume units at price against the average cost, net of fees
+func realizedPnL(avgCost, price, volume, fee decimal.Decimal) decimal.Decimal {
+	return price.Sub(avgCost).Mul(volume).Sub(fee).Round(MaxDecimalPlaces)
+}
+
+// positionAfterBuy returns the average cost of a position after buying acquired units for cost and,
+// when the position is short (negative quantity), the profit realized by buying it back. Covered units
+// realize the difference between the average entry price of the short and the cost per unit; units
+// bought beyond the short open a long position at that cost.
+func positionAfterBuy(quantity, avgCost, acquired, cost decimal.Decimal) (decimal.Decimal, *decimal.Decimal) {
+	if !quantity.IsNegative() || !acquired.IsPositive() {
+		return averageCostAfterBuy(quantity, avgCost, acquired, cost), nil
+	}
+
+	unitCost := cost.DivRound(acquired, MaxDecimalPlaces)
+	covered := decimal.Min(acquired, quantity.Neg())
+	pnl := avgCost.Sub(unitCost).Mul(covered).Round(MaxDecimalPlaces)
+	if acquired.GreaterThan(covered) {
+		return unitCost, &pnl
+	}
+	return avgCost, &pnl
+}
+
+// positionAfterSell returns the average cost of a position after selling volume units at price and the
+// profit realized on the units sold out of a long position, net of the fee. Units sold beyond the
+// quantity held open or add to a short position, whose average cost is its average entry price.
+func positionAfterSell(quantity, avgCost, price, volume, fee decimal.Decimal) (decimal.Decimal, decimal.Decimal) {
+	closed := decimal.Max(decimal.Min(volume, quantity), decimal.Zero)
+	pnl := realizedPnL(avgCost, price, closed, fee)
+
+	opened := volume.Sub(closed)
+	if !opened.IsPositive() {
+		// Selling does not change the average cost of what is left
+		return avgCost, pnl
+	}
+	if !quantity.IsNegative() {
+		return price, pnl
+	}
+	short := quantity.Neg()
+	return short.Mul(avgCost).Add(opened.Mul(price)).DivRound(short.Add(opened), MaxDecimalPlaces), pnl
+}
+
+// savePosition persists the cost basis of a sub-account and the realized PnL of the given transactions
+func (p *TradingLogProcessor) savePosition(ctx context.Context, tx *gorm.DB, account *models.SubAccount, transactions []*models.Transaction) error {
+	err := tx.WithContext(ctx).Model(&models.SubAccount{}).
+		Where("id = ?", account.ID).
+		Updates(map[string]interface{}{
+			"avg_cost":     account.AvgCost,
+			"realized_pnl": account.RealizedPnL,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update position of sub-account %s: %w", account.ID, err)
+	}
+
+	for _, transaction := range transactions {
+		if transaction.RealizedPnL == nil {
+			continue
+		}
+		err := tx.WithContext(ctx).Model(&models.Transaction{}).
+			Where("id = ?", transaction.ID).
+			Update("realized_pnl", *transaction.RealizedPnL).Error
+		if err != nil {
+			return fmt.Errorf("failed to record realized PnL on transaction %s: %w", transaction.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAverageCostAfterBuy(t *testing.T) {
+	d := decimal.RequireFromString
+
+	t.Run("first_buy_includes_fee", func(t *testing.T) {
+		// 2 ETH at 3000 with a 12 USDT fee
+		avgCost := averageCostAfterBuy(decimal.Zero, decimal.Zero, d("2"), d("6012"))
+		assert.True(t, avgCost.Equal(d("3006")), avgCost.String())
+	})
+
+	t.Run("weighted_with_existing_position", func(t *testing.T) {
+		// 2 ETH at 3006 plus 1 ETH for 3300
+		avgCost := averageCostAfterBuy(d("2"), d("3006"), d("1"), d("3300"))
+		assert.True(t, avgCost.Equal(d("3104")), avgCost.String())
+	})
+
+	t.Run("stale_cost_ignored_for_empty_position", func(t *testing.T) {
+		avgCost := averageCostAfterBuy(decimal.Zero, d("2500"), d("4"), d("8000"))
+		assert.True(t, avgCost.Equal(d("2000")), avgCost.String())
+	})
+
+	t.Run("rounded_to_ledger_precision", func(t *testing.T) {
+		avgCost := averageCostAfterBuy(decimal.Zero, decimal.Zero, d("3"), d("10"))
+		assert.Equal(t, "3.33333333", avgCost.String())
+	})
+}
+
+func TestAverageCostAfterBuyReversal(t *testing.T) {
+	d := decimal.RequireFromString
+
+	t.Run("restores_previous_cost", func(t *testing.T) {
+		avgCost := averageCostAfterBuyReversal(d("3"), d("3104"), d("1"), d("3300"))
+		assert.True(t, avgCost.Equal(d("3006")), avgCost.String())
+	})
+
+	t.Run("keeps_cost_when_position_emptied", func(t *testing.T) {
+		avgCost := averageCostAfterBuyReversal(d("2"), d("3006"), d("2"), d("6012"))
+		assert.True(t, avgCost.Equal(d("3006")), avgCost.String())
+	})
+}
+
+func TestAverageCostAfterSellReversal(t *testing.T) {
+	d := decimal.RequireFromString
+
+	t.Run("flip_to_short_restores_long_cost", func(t *testing.T) {
+		// Sold 3 ETH at 150 out of 2 ETH at 100: realized 100 and opened a short at 150
+		avgCost := averageCostAfterSellReversal(d("2"), d("150"), d("150"), d("3"), d("100"), decimal.Zero)
+		assert.True(t, avgCost.Equal(d("100")), avgCost.String())
+	})
+
+	t.Run("flip_with_fee_restores_long_cost", func(t *testing.T) {
+		avgCost := averageCostAfterSellReversal(d("2"), d("150"), d("150"), d("3"), d("95"), d("5"))
+		assert.True(t, avgCost.Equal(d("100")), avgCost.String())
+	})
+
+	t.Run("added_to_short_restores_entry_price", func(t *testing.T) {
+		// Sold 2 ETH at 120 on top of a short of 2 ETH at 150: short of 4 ETH at 135
+		avgCost := averageCostAfterSellReversal(d("-2"), d("135"), d("120"), d("2"), decimal.Zero, decimal.Zero)
+		assert.True(t, avgCost.Equal(d("150")), avgCost.String())
+	})
+
+	t.Run("sale_out_of_long_keeps_cost", func(t *testing.T) {
+		avgCost := averageCostAfterSellReversal(d("3"), d("100"), d("150"), d("1"), d("50"), decimal.Zero)
+		assert.True(t, avgCost.Equal(d("100")), avgCost.String())
+	})
+}
+
+func TestRealizedPnL(t *testing.T) {
+	d := decimal.RequireFromString
+
+	t.Run("profit_net_of_fee", func(t *testing.T) {
+		pnl := realizedPnL(d("2000"), d("3000"), d("1.5"), d("9"))
+		assert.True(t, pnl.Equal(d("1491")), pnl.String())
+	})
+
+	t.Run("loss", func(t *testing.T) {
+		pnl := realizedPnL(d("3006"), d("2800.5"), d("2"), d("5.6"))
+		assert.True(t, pnl.Equal(d("-416.6")), pnl.String())
+	})
+}
+
+func TestPositionAfterSell(t *testing.T) {
+	d := decimal.RequireFromString
+
+	t.Run("sale_within_position", func(t *testing.T) {
+		avgCost, pnl := positionAfterSell(d("2"), d("2000"), d("3000"), d("1.5"), d("9"))
+		assert.True(t, avgCost.Equal(d("2000")), avgCost.String())
+		assert.True(t, pnl.Equal(d("1491")), pnl.String())
+	})
+
+	t.Run("sale_beyond_position_opens_short", func(t *testing.T) {
+		// 1 ETH held at 2000, 3 sold at 3000: 1 closes the long, 2 are sold short at 3000
+		avgCost, pnl := positionAfterSell(d("1"), d("2000"), d("3000"), d("3"), d("9"))
+		assert.True(t, avgCost.Equal(d("3000")), avgCost.String())
+		assert.True(t, pnl.Equal(d("991")), pnl.String())
+	})
+
+	t.Run("short_adds_to_short_position", func(t *testing.T) {
+		// Short 2 at 3000, 1 more sold at 2700
+		avgCost, pnl := positionAfterSell(d("-2"), d("3000"), d("2700"), d("1"), d("3"))
+		assert.True(t, avgCost.Equal(d("2900")), avgCost.String())
+		assert.True(t, pnl.Equal(d("-3")), pnl.String())
+	})
+}
+
+func TestPositionAfterBuy(t *testing.T) {
+	d := decimal.RequireFromString
+
+	t.Run("long_position_has_no_realized_pnl", func(t *testing.T) {
+		avgCost, pnl := positionAfterBuy(d("2"), d("3006"), d("1"), d("3300"))
+		assert.True(t, avgCost.Equal(d("3104")), avgCost.String())
+		assert.Nil(t, pnl)
+	})
+
+	t.Run("buy_back_part_of_short", func(t *testing.T) {
+		// Short 2 at 3000, 1 bought back for 2806 including the fee
+		avgCost, pnl := positionAfterBuy(d("-2"), d("3000"), d("1"), d("2806"))
+		assert.True(t, avgCost.Equal(d("3000")), avgCost.String())
+		if assert.NotNil(t, pnl) {
+			assert.True(t, pnl.Equal(d("194")), pnl.String())
+		}
+	})
+
+	t.Run("buy_beyond_short_opens_long", func(t *testing.T) {
+		// Short 1 at 3000, 3 bought for 8400: 1 covers the short, 2 open a long at 2800
+		avgCost, pnl := positionAfterBuy(d("-1"), d("3000"), d("3"), d("8400"))
+		assert.True(t, avgCost.Equal(d("2800")), avgCost.String())
+		if assert.NotNil(t, pnl) {
+			assert.True(t, pnl.Equal(d("200")), pnl.String())
+		}
+	})
+}
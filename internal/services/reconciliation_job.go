@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// ReconciliationJob periodically reconciles all sub-accounts and logs the drift found
+type ReconciliationJob struct {
+	*periodicJob
+	service *ReconciliationService
+	adjust  bool
+}
+
+// NewReconciliationJob creates a new reconciliation job; with adjust, drifts are corrected
+func NewReconciliationJob(service *ReconciliationService, interval time.Duration, adjust bool) *ReconciliationJob {
+	j := &ReconciliationJob{
+		service: service,
+		adjust:  adjust,
+	}
+	j.periodicJob = newPeriodicJob(interval, j.run)
+	return j
+}
+
+// run reconciles all sub-accounts once
+func (j *ReconciliationJob) run() {
+	report, err := j.service.Reconcile(context.Background(), &ReconciliationRequest{Adjust: j.adjust})
+	if err != nil {
+		log.Printf("Ledger reconciliation failed: %v", err)
+		return
+	}
+
+	log.Printf("Ledger reconciliation checked %d sub-accounts: %d with drift, %d with chain breaks, %d adjustments posted",
+		report.AccountsChecked, report.AccountsWithDrift, report.AccountsWithChainBreaks, report.AdjustmentsPosted)
+	for _, drift := range report.Drifts {
+		log.Printf("Ledger drift in sub-account %s (%s): recorded %s, computed %s, drift %s, %d chain breaks",
+			drift.SubAccountID, drift.Symbol, drift.RecordedBalance.StringFixed(MaxDecimalPlaces),
+			drift.ComputedBalance.StringFixed(MaxDecimalPlaces), drift.Drift.StringFixed(MaxDecimalPlaces), len(drift.ChainBreaks))
+	}
+}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"tiris-backend/internal/models"
+	"tiris-backend/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AdjustmentReason is the transaction reason of corrections posted by the ledger reconciliation
+const AdjustmentReason = "adjustment"
+
+// ReconciliationService verifies sub-account balances against their transactions
+type ReconciliationService struct {
+	repos *repositories.Repositories
+	db    *gorm.DB
+}
+
+// NewReconciliationService creates a new reconciliation service
+func NewReconciliationService(repos *repositories.Repositories, db *gorm.DB) *ReconciliationService {
+	return &ReconciliationService{
+		repos: repos,
+		db:    db,
+	}
+}
+
+// ReconciliationRequest selects the sub-accounts to reconcile; all sub-accounts are reconciled by default
+type ReconciliationRequest struct {
+	TradingID    *uuid.UUID `json:"trading_id,omitempty" example:"453f0347-3959-49de-8e3f-1cf7c8e0827c"`
+	SubAccountID *uuid.UUID `json:"sub_account_id,omitempty" example:"a1b2c3d4-e5f6-7890-abcd-ef1234567890"`
+	// Adjust posts an adjustment transaction for each drifting sub-account so its transactions sum up to its balance
+	Adjust bool `json:"adjust" example:"false"`
+}
+
+// ChainBreak is a transaction whose closing balance does not follow from the one before it
+type ChainBreak struct {
+	TransactionID          uuid.UUID       `json:"transaction_id"`
+	Timestamp              string          `json:"timestamp"`
+	ExpectedClosingBalance decimal.Decimal `json:"expected_closing_balance" swaggertype:"string" example:"1000.00000000"`
+	ClosingBalance         decimal.Decimal `json:"closing_balance" swaggertype:"string" example:"1200.00000000"`
+}
+
+// SubAccountDrift reports a sub-account whose ledger is inconsistent
+type SubAccountDrift struct {
+	SubAccountID     uuid.UUID       `json:"sub_account_id"`
+	UserID           uuid.UUID       `json:"user_id"`
+	TradingID        uuid.UUID       `json:"trading_id"`
+	Symbol           string          `json:"symbol"`
+	TransactionCount int             `json:"transaction_count"`
+	RecordedBalance  decimal.Decimal `json:"recorded_balance" swaggertype:"string" example:"1200.00000000"`
+	ComputedBalance  decimal.Decimal `json:"computed_balance" swaggertype:"string" example:"1000.00000000"`
+	// Drift is the recorded balance minus the balance computed from the transactions
+	Drift       decimal.Decimal `json:"drift" swaggertype:"string" example:"200.00000000"`
+	ChainBreaks []ChainBreak    `json:"chain_breaks"`
+	// AdjustmentTransactionID is the correcting transaction posted for the drift, if any
+	AdjustmentTransactionID *uuid.UUID `json:"adjustment_transaction_id,omitempty"`
+}
+
+// ReconciliationReport is the result of a reconciliation run
+type ReconciliationReport struct {
+	StartedAt               string             `json:"started_at"`
+	CompletedAt             string             `json:"completed_at"`
+	AccountsChecked         int                `json:"accounts_checked"`
+	AccountsWithDrift       int                `json:"accounts_with_drift"`
+	AccountsWithChainBreaks int                `json:"accounts_with_chain_breaks"`
+	AdjustmentsPosted       int                `json:"adjustments_posted"`
+	Drifts                  []*SubAccountDrift `json:"drifts"`
+}
+
+// Reconcile recomputes the balance of the selected sub-accounts from their transactions and reports
+// every sub-account whose balance differs from the sum of its transactions or whose closing balances
+// do not chain. With req.Adjust, the balance is kept and an adjustment transaction is posted for the
+// difference; chain breaks are historical and are only reported.
+func (s *ReconciliationService) Reconcile(ctx context.Context, req *ReconciliationRequest) (*ReconciliationReport, error) {
+	startedAt := time.Now()
+
+	query := s.db.WithContext(ctx).Model(&models.SubAccount{})
+	if req.TradingID != nil {
+		query = query.Where("trading_id = ?", *req.TradingID)
+	}
+	if req.SubAccountID != nil {
+		query = query.Where("id = ?", *req.SubAccountID)
+	}
+
+	var subAccountIDs []uuid.UUID
+	if err := query.Order("id").Pluck("id", &subAccountIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list sub-accounts: %w", err)
+	}
+	if req.SubAccountID != nil && len(subAccountIDs) == 0 {
+		return nil, fmt.Errorf("sub-account not found")
+	}
+
+	report := &ReconciliationReport{
+		StartedAt: startedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Drifts:    []*SubAccountDrift{},
+	}
+
+	for _, subAccountID := range subAccountIDs {
+		drift, err := s.reconcileSubAccount(ctx, subAccountID, req.Adjust)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reconcile sub-account %s: %w", subAccountID, err)
+		}
+
+		report.AccountsChecked++
+		if drift == nil {
+			continue
+		}
+		if !drift.Drift.IsZero() {
+			report.AccountsWithDrift++
+		}
+		if len(drift.ChainBreaks) > 0 {
+			report.AccountsWithChainBreaks++
+		}
+		if drift.AdjustmentTransactionID != nil {
+			report.AdjustmentsPosted++
+		}
+		report.Drifts = append(report.Drifts, drift)
+	}
+
+	report.CompletedAt = time.Now().Format("2006-01-02T15:04:05Z07:00")
+	return report, nil
+}
+
+// reconcileSubAccount reconciles one sub-account and returns nil when its ledger is consistent.
+// The sub-account is locked while it is checked, so the balance cannot change between the check
+// and the adjustment.
+func (s *ReconciliationService) reconcileSubAccount(ctx context.Context, subAccountID uuid.UUID, adjust bool) (*SubAccountDrift, error) {
+	var drift *SubAccountDrift
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var subAccount models.SubAccount
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", subAccountID).
+			First(&subAccount).Error
+		if err != nil {
+			return fmt.Errorf("failed to lock sub-account: %w", err)
+		}
+
+		var transactions []*models.Transaction
+		err = tx.Where("sub_account_id = ?", subAccountID).
+			Order("timestamp ASC, id ASC").
+			Find(&transactions).Error
+		if err != nil {
+			return fmt.Errorf("failed to get transactions: %w", err)
+		}
+
+		drift = reconcileLedger(&subAccount, transactions)
+		if drift == nil || !adjust || drift.Drift.IsZero() {
+			return nil
+		}
+
+		// Post the difference so the transactions sum up to the balance again
+		direction := "credit"
+		if drift.Drift.IsNegative() {
+			direction = "debit"
+		}
+		info := map[string]interface{}{
+			"recorded_balance": drift.RecordedBalance.StringFixed(MaxDecimalPlaces),
+			"computed_balance": drift.ComputedBalance.StringFixed(MaxDecimalPlaces),
+			"drift":            drift.Drift.StringFixed(MaxDecimalPlaces),
+			"source":           "reconciliation",
+		}
+
+		transactionID, err := repositories.NewRepositories(tx).SubAccount.UpdateBalance(
+			ctx, subAccountID, subAccount.Balance, drift.Drift.Abs(), direction, AdjustmentReason, info)
+		if err != nil {
+			return fmt.Errorf("failed to post adjustment: %w", err)
+		}
+		drift.AdjustmentTransactionID = transactionID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return drift, nil
+}
+
+// reconcileLedger compares a sub-account with its transactions, ordered by timestamp, and returns
+// nil when the balance equals the sum of the transactions and every closing balance chains.
+func reconcileLedger(subAccount *models.SubAccount, transactions []*models.Transaction) *SubAccountDrift {
+	computed := decimal.Zero
+	for _, transaction := range transactions {
+		computed = computed.Add(signedAmount(transaction))
+	}
+
+	chainBreaks := findChainBreaks(transactions)
+	drift := subAccount.Balance.Sub(computed)
+	if drift.IsZero() && len(chainBreaks) == 0 {
+		return nil
+	}
+
+	return &SubAccountDrift{
+		SubAccountID:     subAccount.ID,
+		UserID:           subAccount.UserID,
+		TradingID:        subAccount.TradingID,
+		Symbol:           subAccount.Symbol,
+		TransactionCount: len(transactions),
+		RecordedBalance:  subAccount.Balance,
+		ComputedBalance:  computed,
+		Drift:            drift,
+		ChainBreaks:      chainBreaks,
+	}
+}
+
+// findChainBreaks checks that each closing balance equals the previous closing balance plus the
+// signed amount, starting from zero.
+func findChainBreaks(transactions []*models.Transaction) []ChainBreak {
+	chainBreaks := []ChainBreak{}
+	previous := decimal.Zero
+
+	for _, transaction := range orderChain(previous, transactions) {
+		expected := previous.Add(signedAmount(transaction))
+		if !expected.Equal(transaction.ClosingBalance) {
+			chainBreaks = append(chainBreaks, ChainBreak{
+				TransactionID:          transaction.ID,
+				Timestamp:              transaction.Timestamp.Format("2006-01-02T15:04:05Z07:00"),
+				ExpectedClosingBalance: expected,
+				ClosingBalance:         transaction.ClosingBalance,
+			})
+		}
+		previous = transaction.ClosingBalance
+	}
+
+	return chainBreaks
+}
+
+// orderChain orders transactions sorted by timestamp in the order they were posted, starting from
+// the given opening balance. Transactions posted in the same database transaction share a timestamp,
+// so within a timestamp the transaction that chains from the previous closing balance is taken first.
+func orderChain(previous decimal.Decimal, transactions []*models.Transaction) []*models.Transaction {
+	ordered := make([]*models.Transaction, 0, len(transactions))
+
+	for start := 0; start < len(transactions); {
+		end := start
+		for end < len(transactions) && transactions[end].Timestamp.Equal(transactions[start].Timestamp) {
+			end++
+		}
+
+		group := make([]*models.Transaction, end-start)
+		copy(group, transactions[start:end])
+		sort.SliceStable(group, func(i, j int) bool { return group[i].ID.String() < group[j].ID.String() })
+
+		for len(group) > 0 {
+			next := 0
+			for i, transaction := range group {
+				if previous.Add(signedAmount(transaction)).Equal(transaction.ClosingBalance) {
+					next = i
+					break
+				}
+			}
+
+			transaction := group[next]
+			group = append(group[:next], group[next+1:]...)
+			ordered = append(ordered, transaction)
+			previous = transaction.ClosingBalance
+		}
+
+		start = end
+	}
+
+	return ordered
+}
+
+// signedAmount returns the amount of a transaction, negative for debits
+func signedAmount(transaction *models.Transaction) decimal.Decimal {
+	if transaction.Direction == "debit" {
+		return transaction.Amount.Neg()
+	}
+	return transaction.Amount
+}
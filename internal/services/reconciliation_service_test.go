@@ -0,0 +1,93 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"tiris-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileLedger(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	transaction := func(offset time.Duration, direction string, amount, closing int64) *models.Transaction {
+		return &models.Transaction{
+			ID:             uuid.New(),
+			Timestamp:      start.Add(offset),
+			Direction:      direction,
+			Amount:         decimal.NewFromInt(amount),
+			ClosingBalance: decimal.NewFromInt(closing),
+		}
+	}
+
+	t.Run("consistent_ledger", func(t *testing.T) {
+		subAccount := &models.SubAccount{ID: uuid.New(), Balance: decimal.NewFromInt(700)}
+		transactions := []*models.Transaction{
+			transaction(0, "credit", 1000, 1000),
+			transaction(time.Minute, "debit", 300, 700),
+		}
+
+		assert.Nil(t, reconcileLedger(subAccount, transactions))
+	})
+
+	t.Run("balance_changed_without_transaction", func(t *testing.T) {
+		// A direct balance update set the balance to 1200 without recording a transaction
+		subAccount := &models.SubAccount{ID: uuid.New(), Symbol: "USDT", Balance: decimal.NewFromInt(1200)}
+		transactions := []*models.Transaction{
+			transaction(0, "credit", 1000, 1000),
+		}
+
+		drift := reconcileLedger(subAccount, transactions)
+
+		require.NotNil(t, drift)
+		assert.Equal(t, subAccount.ID, drift.SubAccountID)
+		assert.Equal(t, 1, drift.TransactionCount)
+		assert.True(t, drift.ComputedBalance.Equal(decimal.NewFromInt(1000)))
+		assert.True(t, drift.Drift.Equal(decimal.NewFromInt(200)), drift.Drift.String())
+		assert.Empty(t, drift.ChainBreaks)
+	})
+
+	t.Run("closing_balance_does_not_chain", func(t *testing.T) {
+		// A balance event recorded an amount of 30 but moved the balance by 50
+		broken := transaction(time.Minute, "credit", 30, 150)
+		subAccount := &models.SubAccount{ID: uuid.New(), Balance: decimal.NewFromInt(150)}
+		transactions := []*models.Transaction{
+			transaction(0, "credit", 100, 100),
+			broken,
+		}
+
+		drift := reconcileLedger(subAccount, transactions)
+
+		require.NotNil(t, drift)
+		assert.True(t, drift.Drift.Equal(decimal.NewFromInt(20)), drift.Drift.String())
+		require.Len(t, drift.ChainBreaks, 1)
+		assert.Equal(t, broken.ID, drift.ChainBreaks[0].TransactionID)
+		assert.True(t, drift.ChainBreaks[0].ExpectedClosingBalance.Equal(decimal.NewFromInt(130)))
+		assert.True(t, drift.ChainBreaks[0].ClosingBalance.Equal(decimal.NewFromInt(150)))
+	})
+
+	t.Run("same_timestamp_in_any_order", func(t *testing.T) {
+		// A trade and its fee are posted in one database transaction and share a timestamp
+		subAccount := &models.SubAccount{ID: uuid.New(), Balance: decimal.NewFromInt(3988)}
+		transactions := []*models.Transaction{
+			transaction(0, "credit", 10000, 10000),
+			transaction(time.Minute, "debit", 12, 3988),
+			transaction(time.Minute, "debit", 6000, 4000),
+		}
+
+		assert.Nil(t, reconcileLedger(subAccount, transactions))
+	})
+
+	t.Run("no_transactions", func(t *testing.T) {
+		assert.Nil(t, reconcileLedger(&models.SubAccount{ID: uuid.New()}, nil))
+
+		drift := reconcileLedger(&models.SubAccount{ID: uuid.New(), Balance: decimal.NewFromInt(5)}, nil)
+		require.NotNil(t, drift)
+		assert.True(t, drift.Drift.Equal(decimal.NewFromInt(5)))
+	})
+}
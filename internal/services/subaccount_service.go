@@ -2,12 +2,14 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"tiris-backend/internal/models"
 	"tiris-backend/internal/repositories"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // SubAccountService handles sub-account business logic
@@ -24,39 +26,49 @@ func NewSubAccountService(repos *repositories.Repositories) *SubAccountService {
 
 // SubAccountResponse represents sub-account information in responses
 type SubAccountResponse struct {
-	ID         uuid.UUID              `json:"id"`
-	UserID     uuid.UUID              `json:"user_id"`
-	TradingID  uuid.UUID              `json:"trading_id"`
-	Name       string                 `json:"name"`
-	Symbol     string                 `json:"symbol"`
-	Balance    float64                `json:"balance"`
-	Info       map[string]interface{} `json:"info"`
-	CreatedAt  string                 `json:"created_at"`
-	UpdatedAt  string                 `json:"updated_at"`
+	ID               uuid.UUID              `json:"id"`
+	UserID           uuid.UUID              `json:"user_id"`
+	TradingID        uuid.UUID              `json:"trading_id"`
+	Name             string                 `json:"name"`
+	Symbol           string                 `json:"symbol"`
+	Balance          decimal.Decimal        `json:"balance" swaggertype:"string" example:"1250.75"`
+	LockedBalance    decimal.Decimal        `json:"locked_balance" swaggertype:"string" example:"250.00"`
+	AvailableBalance decimal.Decimal        `json:"available_balance" swaggertype:"string" example:"1000.75"`
+	AvgCost          decimal.Decimal        `json:"avg_cost" swaggertype:"string" example:"3006.00"`
+	RealizedPnL      decimal.Decimal        `json:"realized_pnl" swaggertype:"string" example:"120.50"`
+	Borrowed         decimal.Decimal        `json:"borrowed" swaggertype:"string" example:"0.00"`
+	Info             map[string]interface{} `json:"info"`
+	CreatedAt        string                 `json:"created_at"`
+	UpdatedAt        string                 `json:"updated_at"`
 }
 
 // CreateSubAccountRequest represents sub-account creation request
 type CreateSubAccountRequest struct {
-	TradingID  uuid.UUID `json:"trading_id" binding:"required" example:"453f0347-3959-49de-8e3f-1cf7c8e0827c"`
-	Name       string    `json:"name" binding:"required,min=1,max=100" example:"BTC Trading Account"`
-	Symbol     string    `json:"symbol" binding:"required,min=1,max=20" example:"BTC/USDT"`
+	TradingID uuid.UUID `json:"trading_id" binding:"required" example:"453f0347-3959-49de-8e3f-1cf7c8e0827c"`
+	Name      string    `json:"name" binding:"required,min=1,max=100" example:"BTC Trading Account"`
+	Symbol    string    `json:"symbol" binding:"required,min=1,max=20" example:"BTC/USDT"`
 }
 
 // UpdateSubAccountRequest represents sub-account update request
 type UpdateSubAccountRequest struct {
-	Name    *string  `json:"name,omitempty" binding:"omitempty,min=1,max=100" example:"ETH Trading Account"`
-	Symbol  *string  `json:"symbol,omitempty" binding:"omitempty,min=1,max=20" example:"ETH/USD"`
-	Balance *float64 `json:"balance,omitempty" binding:"omitempty,min=0" example:"1250.75"`
+	Name    *string          `json:"name,omitempty" binding:"omitempty,min=1,max=100" example:"ETH Trading Account"`
+	Symbol  *string          `json:"symbol,omitempty" binding:"omitempty,min=1,max=20" example:"ETH/USD"`
+	Balance *decimal.Decimal `json:"balance,omitempty" swaggertype:"string" example:"1250.75"`
 }
 
 // UpdateBalanceRequest represents balance update request
 type UpdateBalanceRequest struct {
-	Amount    float64                `json:"amount" binding:"required" example:"500.25"`
+	Amount    decimal.Decimal        `json:"amount" swaggertype:"string" example:"500.25"`
 	Direction string                 `json:"direction" binding:"required,oneof=credit debit" example:"credit"`
 	Reason    string                 `json:"reason" binding:"required,min=1,max=255" example:"Successful BTC/USDT trade profit"`
 	Info      map[string]interface{} `json:"info,omitempty"`
 }
 
+// LockBalanceRequest represents a request to lock or unlock part of a sub-account balance
+type LockBalanceRequest struct {
+	Amount decimal.Decimal `json:"amount" swaggertype:"string" example:"250.00"`
+}
+
 // CreateSubAccount creates a new sub-account
 func (s *SubAccountService) CreateSubAccount(ctx context.Context, userID uuid.UUID, req *CreateSubAccountRequest) (*SubAccountResponse, error) {
 	// Verify user owns the trading
@@ -70,20 +82,20 @@ func (s *SubAccountService) CreateSubAccount(ctx context.Context, userID uuid.UU
 
 	// Create info map with metadata
 	infoMap := map[string]interface{}{
-		"created_by":    "api",
-		"api_version":   "v1",
+		"created_by":   "api",
+		"api_version":  "v1",
 		"trading_type": trading.Type,
 	}
 
 	// Create sub-account model
 	subAccount := &models.SubAccount{
-		ID:         uuid.New(),
-		UserID:     userID,
+		ID:        uuid.New(),
+		UserID:    userID,
 		TradingID: req.TradingID,
-		Name:       req.Name,
-		Symbol:     req.Symbol,
-		Balance:    0.0, // Start with zero balance
-		Info:       models.JSON(infoMap),
+		Name:      req.Name,
+		Symbol:    req.Symbol,
+		Balance:   decimal.Zero, // Start with zero balance
+		Info:      models.JSON(infoMap),
 	}
 
 	// Save to database - let database constraints handle uniqueness validation
@@ -159,33 +171,73 @@ func (s *SubAccountService) UpdateSubAccount(ctx context.Context, userID, subAcc
 	}
 
 	// Update fields if provided - let database constraints handle uniqueness validation
+	updates := map[string]interface{}{}
 	if req.Name != nil {
-		subAccount.Name = *req.Name
+		updates["name"] = *req.Name
 	}
 
 	if req.Symbol != nil {
-		subAccount.Symbol = *req.Symbol
+		updates["symbol"] = *req.Symbol
 	}
 
-	// Note: Direct balance updates should use UpdateBalance method for proper logging
+	// A new balance is posted as an adjustment so the ledger records the change
+	var balanceChange decimal.Decimal
 	if req.Balance != nil {
-		subAccount.Balance = *req.Balance
+		if req.Balance.IsNegative() {
+			return nil, fmt.Errorf("balance must not be negative")
+		}
+		if req.Balance.LessThan(subAccount.LockedBalance) {
+			return nil, fmt.Errorf("balance must not be below locked balance")
+		}
+
+		trading, err := s.repos.Trading.GetByID(ctx, subAccount.TradingID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get trading: %w", err)
+		}
+		if trading == nil {
+			return nil, fmt.Errorf("sub-account not found")
+		}
+		if trading.IsReal() {
+			return nil, fmt.Errorf("manual balance updates are not allowed for real tradings")
+		}
+		balanceChange = req.Balance.Sub(subAccount.Balance)
 	}
 
-	// Save updated sub-account
-	if err := s.repos.SubAccount.Update(ctx, subAccount); err != nil {
-		// Check for specific constraint violations and provide user-friendly messages
-		if constraintMsg := getSpecificConstraintViolation(err); constraintMsg != "" {
-			return nil, fmt.Errorf(constraintMsg)
+	if len(updates) > 0 {
+		if err := s.repos.SubAccount.Update(ctx, subAccountID, updates); err != nil {
+			// Check for specific constraint violations and provide user-friendly messages
+			if constraintMsg := getSpecificConstraintViolation(err); constraintMsg != "" {
+				return nil, fmt.Errorf(constraintMsg)
+			}
+			return nil, fmt.Errorf("failed to update sub-account: %w", err)
 		}
-		return nil, fmt.Errorf("failed to update sub-account: %w", err)
 	}
 
-	return s.convertToSubAccountResponse(subAccount), nil
+	if !balanceChange.IsZero() {
+		direction := "credit"
+		if balanceChange.IsNegative() {
+			direction = "debit"
+		}
+		info := map[string]interface{}{
+			"previous_balance": subAccount.Balance.StringFixed(MaxDecimalPlaces),
+			"source":           "sub_account_update",
+		}
+		_, err := s.repos.SubAccount.UpdateBalance(ctx, subAccountID, *req.Balance, balanceChange.Abs(), direction, AdjustmentReason, info)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update balance: %w", err)
+		}
+	}
+
+	// Return updated sub-account
+	return s.GetSubAccount(ctx, userID, subAccountID)
 }
 
 // UpdateBalance updates sub-account balance with proper logging
 func (s *SubAccountService) UpdateBalance(ctx context.Context, userID, subAccountID uuid.UUID, req *UpdateBalanceRequest) (*SubAccountResponse, error) {
+	if !req.Amount.IsPositive() {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+
 	// Verify sub-account belongs to user
 	subAccount, err := s.repos.SubAccount.GetByID(ctx, subAccountID)
 	if err != nil {
@@ -199,16 +251,29 @@ func (s *SubAccountService) UpdateBalance(ctx context.Context, userID, subAccoun
 		return nil, fmt.Errorf("sub-account not found")
 	}
 
+	// The balances of a real trading follow the exchange and change only through trading logs
+	trading, err := s.repos.Trading.GetByID(ctx, subAccount.TradingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trading: %w", err)
+	}
+	if trading == nil {
+		return nil, fmt.Errorf("sub-account not found")
+	}
+	if trading.IsReal() {
+		return nil, fmt.Errorf("manual balance updates are not allowed for real tradings")
+	}
+
 	// Calculate new balance
-	var newBalance float64
+	var newBalance decimal.Decimal
 	switch req.Direction {
 	case "credit":
-		newBalance = subAccount.Balance + req.Amount
+		newBalance = subAccount.Balance.Add(req.Amount)
 	case "debit":
-		newBalance = subAccount.Balance - req.Amount
-		if newBalance < 0 {
+		// Locked funds cannot be spent
+		if req.Amount.GreaterThan(subAccount.AvailableBalance()) {
 			return nil, fmt.Errorf("insufficient balance")
 		}
+		newBalance = subAccount.Balance.Sub(req.Amount)
 	default:
 		return nil, fmt.Errorf("invalid direction")
 	}
@@ -223,6 +288,48 @@ func (s *SubAccountService) UpdateBalance(ctx context.Context, userID, subAccoun
 	return s.GetSubAccount(ctx, userID, subAccountID)
 }
 
+// LockBalance locks part of the available balance of a sub-account, e.g. for an open order
+func (s *SubAccountService) LockBalance(ctx context.Context, userID, subAccountID uuid.UUID, req *LockBalanceRequest) (*SubAccountResponse, error) {
+	return s.adjustLockedBalance(ctx, userID, subAccountID, req, s.repos.SubAccount.LockBalance)
+}
+
+// UnlockBalance releases part of the locked balance of a sub-account
+func (s *SubAccountService) UnlockBalance(ctx context.Context, userID, subAccountID uuid.UUID, req *LockBalanceRequest) (*SubAccountResponse, error) {
+	return s.adjustLockedBalance(ctx, userID, subAccountID, req, s.repos.SubAccount.UnlockBalance)
+}
+
+// adjustLockedBalance verifies the request and sub-account ownership and applies a lock or unlock
+func (s *SubAccountService) adjustLockedBalance(ctx context.Context, userID, subAccountID uuid.UUID, req *LockBalanceRequest,
+	adjust func(ctx context.Context, subAccountID uuid.UUID, amount decimal.Decimal) (*models.SubAccount, error)) (*SubAccountResponse, error) {
+	if !req.Amount.IsPositive() {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+
+	// Verify sub-account belongs to user
+	subAccount, err := s.repos.SubAccount.GetByID(ctx, subAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sub-account: %w", err)
+	}
+	if subAccount == nil || subAccount.UserID != userID {
+		return nil, fmt.Errorf("sub-account not found")
+	}
+
+	subAccount, err = adjust(ctx, subAccountID, req.Amount)
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrInsufficientAvailable):
+			return nil, fmt.Errorf("insufficient available balance")
+		case errors.Is(err, repositories.ErrInsufficientLocked):
+			return nil, fmt.Errorf("insufficient locked balance")
+		case errors.Is(err, repositories.ErrSubAccountNotFound):
+			return nil, fmt.Errorf("sub-account not found")
+		}
+		return nil, fmt.Errorf("failed to update locked balance: %w", err)
+	}
+
+	return s.convertToSubAccountResponse(subAccount), nil
+}
+
 // DeleteSubAccount deletes a sub-account (soft delete)
 func (s *SubAccountService) DeleteSubAccount(ctx context.Context, userID, subAccountID uuid.UUID) error {
 	// Get existing sub-account to verify ownership
@@ -240,7 +347,7 @@ func (s *SubAccountService) DeleteSubAccount(ctx context.Context, userID, subAcc
 	}
 
 	// Check if sub-account has balance
-	if subAccount.Balance > 0 {
+	if subAccount.Balance.IsPositive() {
 		return fmt.Errorf("cannot delete sub-account with positive balance")
 	}
 
@@ -277,14 +384,19 @@ func (s *SubAccountService) convertToSubAccountResponse(subAccount *models.SubAc
 	}
 
 	return &SubAccountResponse{
-		ID:         subAccount.ID,
-		UserID:     subAccount.UserID,
-		TradingID: subAccount.TradingID,
-		Name:       subAccount.Name,
-		Symbol:     subAccount.Symbol,
-		Balance:    subAccount.Balance,
-		Info:       info,
-		CreatedAt:  subAccount.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:  subAccount.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:               subAccount.ID,
+		UserID:           subAccount.UserID,
+		TradingID:        subAccount.TradingID,
+		Name:             subAccount.Name,
+		Symbol:           subAccount.Symbol,
+		Balance:          subAccount.Balance,
+		LockedBalance:    subAccount.LockedBalance,
+		AvailableBalance: subAccount.AvailableBalance(),
+		AvgCost:          subAccount.AvgCost,
+		RealizedPnL:      subAccount.RealizedPnL,
+		Borrowed:         subAccount.Borrowed,
+		Info:             info,
+		CreatedAt:        subAccount.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:        subAccount.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
 }
@@ -3,6 +3,7 @@ package test
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -104,7 +105,7 @@ func TestAuthService_InitiateLogin(t *testing.T) {
 	}
 
 	// Create service
-	authService := services.NewAuthService(repos, mockJWTManager, mockOAuthManager)
+	authService := services.NewAuthService(repos, mockJWTManager, mockOAuthManager, newTestKeyring(t))
 
 	// Test successful login initiation
 	t.Run("successful_google_login", func(t *testing.T) {
@@ -202,7 +203,7 @@ func TestAuthService_HandleCallback(t *testing.T) {
 	}
 
 	// Create service
-	authService := services.NewAuthService(repos, mockJWTManager, mockOAuthManager)
+	authService := services.NewAuthService(repos, mockJWTManager, mockOAuthManager, newTestKeyring(t))
 
 	// Test successful callback for new user
 	t.Run("successful_callback_new_user", func(t *testing.T) {
@@ -253,9 +254,11 @@ func TestAuthService_HandleCallback(t *testing.T) {
 		mockUserRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.User")).
 			Return(nil).Once()
 
-		// Create OAuth token
-		mockOAuthTokenRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.OAuthToken")).
-			Return(nil).Once()
+		// Create OAuth token, encrypted with the active key
+		mockOAuthTokenRepo.On("Create", mock.Anything, mock.MatchedBy(func(token *models.OAuthToken) bool {
+			return strings.HasPrefix(token.AccessToken, "v1:") && token.AccessToken != "access_token_123" &&
+				token.RefreshToken != nil && strings.HasPrefix(*token.RefreshToken, "v1:") && *token.RefreshToken != "refresh_token_456"
+		})).Return(nil).Once()
 
 		// Generate JWT tokens
 		mockJWTManager.On("GenerateTokenPair", mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("string"), "test@example.com", "user").
@@ -460,7 +463,7 @@ func TestAuthService_RefreshToken(t *testing.T) {
 	}
 
 	// Create service
-	authService := services.NewAuthService(repos, mockJWTManager, &MockOAuthManager{})
+	authService := services.NewAuthService(repos, mockJWTManager, &MockOAuthManager{}, newTestKeyring(t))
 
 	// Test successful token refresh
 	t.Run("successful_refresh", func(t *testing.T) {
@@ -592,7 +595,7 @@ func TestAuthService_RefreshToken(t *testing.T) {
 // TestAuthService_Logout tests the Logout functionality
 func TestAuthService_Logout(t *testing.T) {
 	// Create service
-	authService := services.NewAuthService(&repositories.Repositories{}, &MockJWTManager{}, &MockOAuthManager{})
+	authService := services.NewAuthService(&repositories.Repositories{}, &MockJWTManager{}, &MockOAuthManager{}, newTestKeyring(t))
 
 	// Test successful logout
 	t.Run("successful_logout", func(t *testing.T) {
@@ -639,7 +642,7 @@ func TestAuthService_Performance(t *testing.T) {
 	}
 
 	// Create service
-	authService := services.NewAuthService(repos, mockJWTManager, mockOAuthManager)
+	authService := services.NewAuthService(repos, mockJWTManager, mockOAuthManager, newTestKeyring(t))
 
 	t.Run("initiate_login_performance", func(t *testing.T) {
 		request := &services.LoginRequest{
@@ -0,0 +1,149 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"tiris-backend/internal/exchange"
+	"tiris-backend/internal/models"
+	"tiris-backend/internal/repositories"
+	"tiris-backend/internal/services"
+	"tiris-backend/test/mocks"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBalanceSyncService tests previewing balance syncs and the approvals checked before adjusting
+func TestBalanceSyncService(t *testing.T) {
+	mockTradingRepo := &mocks.MockTradingRepository{}
+	mockSubAccountRepo := &mocks.MockSubAccountRepository{}
+	mockExchangeBindingRepo := &mocks.MockExchangeBindingRepository{}
+	repos := &repositories.Repositories{
+		Trading:         mockTradingRepo,
+		SubAccount:      mockSubAccountRepo,
+		ExchangeBinding: mockExchangeBindingRepo,
+	}
+
+	fake := exchange.NewFakeConnector()
+	fake.SetBalances([]exchange.Balance{
+		{Asset: "BTC", Free: decimal.RequireFromString("0.5"), Locked: decimal.RequireFromString("0.1")},
+		{Asset: "USDT", Free: decimal.NewFromInt(1000)},
+	})
+	credentials := newTestExchangeBindingManager(t)
+	exchangeBindingService := services.NewExchangeBindingService(repos.ExchangeBinding, credentials, exchange.NewFakeRegistry(fake))
+	// Rejected approvals return before the adjustment transaction
+	balanceSyncService := services.NewBalanceSyncService(repos, nil, exchangeBindingService)
+
+	userID := uuid.New()
+	binding := &models.ExchangeBinding{ID: uuid.New(), UserID: &userID, Exchange: "binance", Type: "private"}
+	require.NoError(t, credentials.SetAPICredentials(binding, "test_api_key", "test_api_secret"))
+	trading := &models.Trading{ID: uuid.New(), UserID: userID, ExchangeBindingID: binding.ID, Type: models.TradingTypeReal}
+	btcAccount := &models.SubAccount{ID: uuid.New(), UserID: userID, TradingID: trading.ID, Symbol: "BTC", Balance: decimal.RequireFromString("0.5")}
+	usdtAccount := &models.SubAccount{ID: uuid.New(), UserID: userID, TradingID: trading.ID, Symbol: "USDT", Balance: decimal.NewFromInt(1000)}
+
+	expectPreview := func() {
+		mockTradingRepo.On("GetByID", mock.Anything, trading.ID).Return(trading, nil).Once()
+		mockTradingRepo.On("GetByExchangeBinding", mock.Anything, binding.ID).Return([]*models.Trading{trading}, nil).Once()
+		mockExchangeBindingRepo.On("GetByID", mock.Anything, binding.ID).Return(binding, nil).Once()
+		mockSubAccountRepo.On("GetByTradingID", mock.Anything, trading.ID).
+			Return([]*models.SubAccount{btcAccount, usdtAccount}, nil).Once()
+	}
+
+	t.Run("preview", func(t *testing.T) {
+		expectPreview()
+
+		report, err := balanceSyncService.PreviewSync(context.Background(), userID, trading.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, trading.ID, report.TradingID)
+		assert.Equal(t, 2, report.AccountsChecked)
+		require.Len(t, report.Diffs, 1)
+		assert.Equal(t, btcAccount.ID, report.Diffs[0].SubAccountID)
+		assert.True(t, report.Diffs[0].ExchangeBalance.Equal(decimal.RequireFromString("0.6")))
+		assert.True(t, report.Diffs[0].Difference.Equal(decimal.RequireFromString("0.1")))
+		assert.Equal(t, 0, report.AdjustmentsPosted)
+		mockTradingRepo.AssertExpectations(t)
+		mockExchangeBindingRepo.AssertExpectations(t)
+		mockSubAccountRepo.AssertExpectations(t)
+	})
+
+	t.Run("simulation_trading", func(t *testing.T) {
+		simulation := &models.Trading{ID: uuid.New(), UserID: userID, Type: models.TradingTypeSimulation}
+		mockTradingRepo.On("GetByID", mock.Anything, simulation.ID).Return(simulation, nil).Once()
+
+		report, err := balanceSyncService.PreviewSync(context.Background(), userID, simulation.ID)
+
+		require.Error(t, err)
+		assert.Nil(t, report)
+		assert.Equal(t, "balance sync requires a real trading", err.Error())
+	})
+
+	t.Run("shared_exchange_binding", func(t *testing.T) {
+		other := &models.Trading{ID: uuid.New(), UserID: userID, ExchangeBindingID: binding.ID, Type: models.TradingTypeReal}
+		mockTradingRepo.On("GetByID", mock.Anything, trading.ID).Return(trading, nil).Once()
+		// The exchange account is not read
+		mockTradingRepo.On("GetByExchangeBinding", mock.Anything, binding.ID).Return([]*models.Trading{other, trading}, nil).Once()
+
+		report, err := balanceSyncService.PreviewSync(context.Background(), userID, trading.ID)
+
+		require.Error(t, err)
+		assert.Nil(t, report)
+		assert.Equal(t, "exchange binding is shared by 2 tradings", err.Error())
+		mockTradingRepo.AssertExpectations(t)
+	})
+
+	t.Run("trading_of_another_user", func(t *testing.T) {
+		mockTradingRepo.On("GetByID", mock.Anything, trading.ID).Return(trading, nil).Once()
+
+		report, err := balanceSyncService.PreviewSync(context.Background(), uuid.New(), trading.ID)
+
+		require.Error(t, err)
+		assert.Nil(t, report)
+		assert.Equal(t, "trading not found", err.Error())
+	})
+
+	t.Run("apply_stale_approval", func(t *testing.T) {
+		expectPreview()
+		request := &services.ApplyBalanceSyncRequest{Approvals: []services.BalanceSyncApproval{{
+			SubAccountID:    btcAccount.ID,
+			LedgerBalance:   decimal.RequireFromString("0.5"),
+			ExchangeBalance: decimal.RequireFromString("0.7"),
+		}}}
+
+		report, err := balanceSyncService.ApplySync(context.Background(), userID, trading.ID, request)
+
+		require.Error(t, err)
+		assert.Nil(t, report)
+		assert.Equal(t, "balance of sub-account "+btcAccount.ID.String()+" changed since the preview", err.Error())
+	})
+
+	t.Run("apply_account_in_sync", func(t *testing.T) {
+		expectPreview()
+		request := &services.ApplyBalanceSyncRequest{Approvals: []services.BalanceSyncApproval{{
+			SubAccountID:    usdtAccount.ID,
+			LedgerBalance:   decimal.NewFromInt(1000),
+			ExchangeBalance: decimal.NewFromInt(1000),
+		}}}
+
+		report, err := balanceSyncService.ApplySync(context.Background(), userID, trading.ID, request)
+
+		require.Error(t, err)
+		assert.Nil(t, report)
+		assert.Equal(t, "sub-account "+usdtAccount.ID.String()+" has no difference to adjust", err.Error())
+	})
+
+	t.Run("apply_duplicate_approval", func(t *testing.T) {
+		approval := services.BalanceSyncApproval{SubAccountID: btcAccount.ID}
+		request := &services.ApplyBalanceSyncRequest{Approvals: []services.BalanceSyncApproval{approval, approval}}
+
+		report, err := balanceSyncService.ApplySync(context.Background(), userID, trading.ID, request)
+
+		require.Error(t, err)
+		assert.Nil(t, report)
+		assert.Equal(t, "sub-account "+btcAccount.ID.String()+" is approved twice", err.Error())
+	})
+}
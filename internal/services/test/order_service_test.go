@@ -0,0 +1,139 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"tiris-backend/internal/models"
+	"tiris-backend/internal/repositories"
+	"tiris-backend/internal/services"
+	"tiris-backend/test/mocks"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOrderService_GetUserOrders tests the GetUserOrders functionality
+func TestOrderService_GetUserOrders(t *testing.T) {
+	mockOrderRepo := &mocks.MockOrderRepository{}
+	repos := &repositories.Repositories{
+		Trading: &mocks.MockTradingRepository{},
+		Order:   mockOrderRepo,
+	}
+	orderService := services.NewOrderService(repos)
+
+	userID := uuid.New()
+
+	t.Run("successful_retrieval_default", func(t *testing.T) {
+		avgPrice := decimal.NewFromInt(2999)
+		orders := []*models.Order{
+			{
+				ID:           uuid.New(),
+				UserID:       userID,
+				OrderID:      "8389765493",
+				Symbol:       "ETH/USDT",
+				Side:         "buy",
+				Type:         "market",
+				Amount:       decimal.NewFromInt(2),
+				FilledAmount: decimal.NewFromInt(1),
+				AvgPrice:     &avgPrice,
+				Status:       models.OrderStatusPartiallyFilled,
+			},
+		}
+
+		mockOrderRepo.On("GetByUserID", mock.Anything, userID, repositories.OrderFilters{Limit: 100}).
+			Return(orders, int64(1), nil).Once()
+
+		result, err := orderService.GetUserOrders(context.Background(), userID, &services.OrderQueryRequest{})
+
+		require.NoError(t, err)
+		require.Len(t, result.Orders, 1)
+		assert.Equal(t, "8389765493", result.Orders[0].OrderID)
+		assert.Equal(t, models.OrderStatusPartiallyFilled, result.Orders[0].Status)
+		assert.True(t, result.Orders[0].FilledAmount.Equal(decimal.NewFromInt(1)))
+		assert.True(t, result.Orders[0].AvgPrice.Equal(avgPrice))
+		assert.NotNil(t, result.Orders[0].Info)
+		assert.Equal(t, int64(1), result.Total)
+		assert.Equal(t, 100, result.Limit)
+		assert.False(t, result.HasMore)
+		mockOrderRepo.AssertExpectations(t)
+	})
+
+	t.Run("successful_retrieval_filtered", func(t *testing.T) {
+		status := models.OrderStatusFilled
+		side := "sell"
+
+		mockOrderRepo.On("GetByUserID", mock.Anything, userID, repositories.OrderFilters{Status: &status, Side: &side, Limit: 10, Offset: 10}).
+			Return([]*models.Order{}, int64(25), nil).Once()
+
+		result, err := orderService.GetUserOrders(context.Background(), userID, &services.OrderQueryRequest{
+			Status: &status,
+			Side:   &side,
+			Limit:  10,
+			Offset: 10,
+		})
+
+		require.NoError(t, err)
+		assert.Empty(t, result.Orders)
+		assert.True(t, result.HasMore)
+		mockOrderRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid_date_range", func(t *testing.T) {
+		startDate := time.Now()
+		endDate := startDate.Add(-time.Hour)
+
+		result, err := orderService.GetUserOrders(context.Background(), userID, &services.OrderQueryRequest{
+			StartDate: &startDate,
+			EndDate:   &endDate,
+		})
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "start date cannot be after end date", err.Error())
+	})
+}
+
+// TestOrderService_GetTradingOrders tests the GetTradingOrders functionality
+func TestOrderService_GetTradingOrders(t *testing.T) {
+	mockTradingRepo := &mocks.MockTradingRepository{}
+	mockOrderRepo := &mocks.MockOrderRepository{}
+	repos := &repositories.Repositories{
+		Trading: mockTradingRepo,
+		Order:   mockOrderRepo,
+	}
+	orderService := services.NewOrderService(repos)
+
+	userID := uuid.New()
+	tradingID := uuid.New()
+
+	t.Run("successful_retrieval", func(t *testing.T) {
+		mockTradingRepo.On("GetByID", mock.Anything, tradingID).
+			Return(&models.Trading{ID: tradingID, UserID: userID}, nil).Once()
+		mockOrderRepo.On("GetByTradingID", mock.Anything, tradingID, repositories.OrderFilters{Limit: 100}).
+			Return([]*models.Order{{ID: uuid.New(), UserID: userID, TradingID: tradingID, Status: models.OrderStatusOpen}}, int64(1), nil).Once()
+
+		result, err := orderService.GetTradingOrders(context.Background(), userID, tradingID, &services.OrderQueryRequest{})
+
+		require.NoError(t, err)
+		require.Len(t, result.Orders, 1)
+		assert.Equal(t, tradingID, result.Orders[0].TradingID)
+		mockTradingRepo.AssertExpectations(t)
+		mockOrderRepo.AssertExpectations(t)
+	})
+
+	t.Run("trading_of_other_user", func(t *testing.T) {
+		mockTradingRepo.On("GetByID", mock.Anything, tradingID).
+			Return(&models.Trading{ID: tradingID, UserID: uuid.New()}, nil).Once()
+
+		result, err := orderService.GetTradingOrders(context.Background(), userID, tradingID, &services.OrderQueryRequest{})
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "trading not found", err.Error())
+	})
+}
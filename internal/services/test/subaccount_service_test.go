@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"tiris-backend/internal/models"
 	"tiris-backend/internal/repositories"
@@ -13,6 +14,7 @@ import (
 	"tiris-backend/test/mocks"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -72,7 +74,7 @@ func TestSubAccountService_CreateSubAccount(t *testing.T) {
 		assert.Equal(t, tradingID, result.TradingID)
 		assert.Equal(t, request.Name, result.Name)
 		assert.Equal(t, request.Symbol, result.Symbol)
-		assert.Equal(t, 0.0, result.Balance) // New accounts start with zero balance
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(0), result.Balance) // New accounts start with zero balance
 
 		// Verify mock expectations
 		mockTradingRepo.AssertExpectations(t)
@@ -352,11 +354,12 @@ func TestSubAccountService_GetSubAccount(t *testing.T) {
 func TestSubAccountService_UpdateSubAccount(t *testing.T) {
 	// Create mocks
 	mockSubAccountRepo := &mocks.MockSubAccountRepository{}
+	mockTradingRepo := &mocks.MockTradingRepository{}
 
 	// Create repositories with mocks
 	repos := &repositories.Repositories{
 		User:            &mocks.MockUserRepository{},
-		Trading:        &mocks.MockTradingRepository{},
+		Trading:        mockTradingRepo,
 		SubAccount:      mockSubAccountRepo,
 		Transaction:     &mocks.MockTransactionRepository{},
 		TradingLog:      &mocks.MockTradingLogRepository{},
@@ -376,7 +379,10 @@ func TestSubAccountService_UpdateSubAccount(t *testing.T) {
 	testSubAccount.ID = subAccountID
 	testSubAccount.Name = "original-name"
 	testSubAccount.Symbol = "USDT"
-	testSubAccount.Balance = 1000.0
+	testSubAccount.Balance = decimal.NewFromInt(1000)
+
+	mockTradingRepo.On("GetByID", mock.Anything, tradingID).
+		Return(&models.Trading{ID: tradingID, UserID: userID, Type: models.TradingTypeSimulation}, nil)
 
 	// Test successful name update
 	t.Run("successful_name_update", func(t *testing.T) {
@@ -385,11 +391,16 @@ func TestSubAccountService_UpdateSubAccount(t *testing.T) {
 			Name: &newName,
 		}
 
+		updatedSubAccount := *testSubAccount
+		updatedSubAccount.Name = newName
+
 		// Setup mock expectations
 		mockSubAccountRepo.On("GetByID", mock.Anything, subAccountID).
 			Return(testSubAccount, nil).Once()
-		mockSubAccountRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.SubAccount")).
+		mockSubAccountRepo.On("Update", mock.Anything, subAccountID, map[string]interface{}{"name": newName}).
 			Return(nil).Once()
+		mockSubAccountRepo.On("GetByID", mock.Anything, subAccountID).
+			Return(&updatedSubAccount, nil).Once()
 
 		// Execute test
 		result, err := subAccountService.UpdateSubAccount(context.Background(), userID, subAccountID, request)
@@ -414,7 +425,7 @@ func TestSubAccountService_UpdateSubAccount(t *testing.T) {
 		mockSubAccountRepo.On("GetByID", mock.Anything, subAccountID).
 			Return(testSubAccount, nil).Once()
 		// Database returns unique constraint error with specific constraint name
-		mockSubAccountRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.SubAccount")).
+		mockSubAccountRepo.On("Update", mock.Anything, subAccountID, map[string]interface{}{"name": conflictingName}).
 			Return(fmt.Errorf("duplicate key value violates unique constraint \"sub_accounts_trading_name_active_unique\"")).Once()
 
 		// Execute test
@@ -429,20 +440,29 @@ func TestSubAccountService_UpdateSubAccount(t *testing.T) {
 		mockSubAccountRepo.AssertExpectations(t)
 	})
 
-	// Test symbol and balance update
+	// Test symbol and balance update - the balance is posted as an adjustment
 	t.Run("successful_symbol_balance_update", func(t *testing.T) {
 		newSymbol := "BTC"
-		newBalance := 2500.0
+		newBalance := decimal.NewFromInt(2500)
 		request := &services.UpdateSubAccountRequest{
 			Symbol:  &newSymbol,
 			Balance: &newBalance,
 		}
 
+		updatedSubAccount := *testSubAccount
+		updatedSubAccount.Symbol = newSymbol
+		updatedSubAccount.Balance = newBalance
+		transactionID := uuid.New()
+
 		// Setup mock expectations
 		mockSubAccountRepo.On("GetByID", mock.Anything, subAccountID).
 			Return(testSubAccount, nil).Once()
-		mockSubAccountRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.SubAccount")).
+		mockSubAccountRepo.On("Update", mock.Anything, subAccountID, map[string]interface{}{"symbol": newSymbol}).
 			Return(nil).Once()
+		mockSubAccountRepo.On("UpdateBalance", mock.Anything, subAccountID, helpers.DecimalArg(newBalance), helpers.DecimalArg(decimal.NewFromInt(1500)), "credit", services.AdjustmentReason, mock.Anything).
+			Return(&transactionID, nil).Once()
+		mockSubAccountRepo.On("GetByID", mock.Anything, subAccountID).
+			Return(&updatedSubAccount, nil).Once()
 
 		// Execute test
 		result, err := subAccountService.UpdateSubAccount(context.Background(), userID, subAccountID, request)
@@ -456,17 +476,47 @@ func TestSubAccountService_UpdateSubAccount(t *testing.T) {
 		// Verify mock expectations
 		mockSubAccountRepo.AssertExpectations(t)
 	})
+
+	// Test balance update of a real trading's sub-account
+	t.Run("real_trading_balance_refused", func(t *testing.T) {
+		realTradingID := uuid.New()
+		realSubAccount := subAccountFactory.WithUserAndTrading(userID, realTradingID)
+		realSubAccount.Balance = decimal.NewFromInt(1000)
+		newBalance := decimal.NewFromInt(2500)
+		request := &services.UpdateSubAccountRequest{
+			Balance: &newBalance,
+		}
+
+		// Setup mock expectations
+		mockSubAccountRepo.On("GetByID", mock.Anything, realSubAccount.ID).
+			Return(realSubAccount, nil).Once()
+		mockTradingRepo.On("GetByID", mock.Anything, realTradingID).
+			Return(&models.Trading{ID: realTradingID, UserID: userID, Type: models.TradingTypeReal}, nil).Once()
+
+		// Execute test
+		result, err := subAccountService.UpdateSubAccount(context.Background(), userID, realSubAccount.ID, request)
+
+		// Verify results
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "manual balance updates are not allowed for real tradings", err.Error())
+
+		// Verify mock expectations - nothing is written
+		mockSubAccountRepo.AssertExpectations(t)
+		mockSubAccountRepo.AssertNotCalled(t, "UpdateBalance", mock.Anything, realSubAccount.ID, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
 }
 
 // TestSubAccountService_UpdateBalance tests the UpdateBalance functionality
 func TestSubAccountService_UpdateBalance(t *testing.T) {
 	// Create mocks
 	mockSubAccountRepo := &mocks.MockSubAccountRepository{}
+	mockTradingRepo := &mocks.MockTradingRepository{}
 
 	// Create repositories with mocks
 	repos := &repositories.Repositories{
 		User:            &mocks.MockUserRepository{},
-		Trading:        &mocks.MockTradingRepository{},
+		Trading:        mockTradingRepo,
 		SubAccount:      mockSubAccountRepo,
 		Transaction:     &mocks.MockTransactionRepository{},
 		TradingLog:      &mocks.MockTradingLogRepository{},
@@ -484,24 +534,28 @@ func TestSubAccountService_UpdateBalance(t *testing.T) {
 	subAccountFactory := helpers.NewSubAccountFactory()
 	testSubAccount := subAccountFactory.WithUserAndTrading(userID, tradingID)
 	testSubAccount.ID = subAccountID
-	testSubAccount.Balance = 1000.0
+	testSubAccount.Balance = decimal.NewFromInt(1000)
+
+	// Only the sub-accounts of simulated tradings can be updated manually
+	mockTradingRepo.On("GetByID", mock.Anything, tradingID).
+		Return(&models.Trading{ID: tradingID, UserID: userID, Type: models.TradingTypeSimulation}, nil)
 
 	// Test successful credit update
 	t.Run("successful_credit", func(t *testing.T) {
 		request := &services.UpdateBalanceRequest{
-			Amount:    500.0,
+			Amount:    decimal.NewFromInt(500),
 			Direction: "credit",
 			Reason:    "deposit",
 			Info:      map[string]interface{}{"method": "bank_transfer"},
 		}
 
 		transactionID := uuid.New()
-		expectedNewBalance := 1500.0
+		expectedNewBalance := decimal.NewFromInt(1500)
 
 		// Setup mock expectations
 		mockSubAccountRepo.On("GetByID", mock.Anything, subAccountID).
 			Return(testSubAccount, nil).Times(2) // Called twice: once in UpdateBalance, once in GetSubAccount
-		mockSubAccountRepo.On("UpdateBalance", mock.Anything, subAccountID, expectedNewBalance, request.Amount, request.Direction, request.Reason, request.Info).
+		mockSubAccountRepo.On("UpdateBalance", mock.Anything, subAccountID, helpers.DecimalArg(expectedNewBalance), helpers.DecimalArg(request.Amount), request.Direction, request.Reason, request.Info).
 			Return(&transactionID, nil).Once()
 
 		// Execute test
@@ -518,19 +572,19 @@ func TestSubAccountService_UpdateBalance(t *testing.T) {
 	// Test successful debit update
 	t.Run("successful_debit", func(t *testing.T) {
 		request := &services.UpdateBalanceRequest{
-			Amount:    300.0,
+			Amount:    decimal.NewFromInt(300),
 			Direction: "debit",
 			Reason:    "withdrawal",
 			Info:      map[string]interface{}{"address": "0x123..."},
 		}
 
 		transactionID := uuid.New()
-		expectedNewBalance := 700.0
+		expectedNewBalance := decimal.NewFromInt(700)
 
 		// Setup mock expectations
 		mockSubAccountRepo.On("GetByID", mock.Anything, subAccountID).
 			Return(testSubAccount, nil).Times(2)
-		mockSubAccountRepo.On("UpdateBalance", mock.Anything, subAccountID, expectedNewBalance, request.Amount, request.Direction, request.Reason, request.Info).
+		mockSubAccountRepo.On("UpdateBalance", mock.Anything, subAccountID, helpers.DecimalArg(expectedNewBalance), helpers.DecimalArg(request.Amount), request.Direction, request.Reason, request.Info).
 			Return(&transactionID, nil).Once()
 
 		// Execute test
@@ -547,7 +601,7 @@ func TestSubAccountService_UpdateBalance(t *testing.T) {
 	// Test insufficient balance
 	t.Run("insufficient_balance", func(t *testing.T) {
 		request := &services.UpdateBalanceRequest{
-			Amount:    1500.0, // More than current balance (1000.0)
+			Amount:    decimal.NewFromInt(1500), // More than current balance (1000.0)
 			Direction: "debit",
 			Reason:    "withdrawal",
 		}
@@ -568,10 +622,38 @@ func TestSubAccountService_UpdateBalance(t *testing.T) {
 		mockSubAccountRepo.AssertExpectations(t)
 	})
 
+	// Test that locked funds cannot be debited
+	t.Run("insufficient_available_balance", func(t *testing.T) {
+		lockedSubAccount := *testSubAccount
+		lockedSubAccount.ID = uuid.New()
+		lockedSubAccount.LockedBalance = decimal.NewFromInt(800)
+
+		request := &services.UpdateBalanceRequest{
+			Amount:    decimal.NewFromInt(300), // Less than balance (1000) but more than available (200)
+			Direction: "debit",
+			Reason:    "withdrawal",
+		}
+
+		// Setup mock expectations
+		mockSubAccountRepo.On("GetByID", mock.Anything, lockedSubAccount.ID).
+			Return(&lockedSubAccount, nil).Once()
+
+		// Execute test
+		result, err := subAccountService.UpdateBalance(context.Background(), userID, lockedSubAccount.ID, request)
+
+		// Verify results
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "insufficient balance", err.Error())
+
+		// Verify mock expectations
+		mockSubAccountRepo.AssertExpectations(t)
+	})
+
 	// Test invalid direction
 	t.Run("invalid_direction", func(t *testing.T) {
 		request := &services.UpdateBalanceRequest{
-			Amount:    500.0,
+			Amount:    decimal.NewFromInt(500),
 			Direction: "invalid",
 			Reason:    "test",
 		}
@@ -591,6 +673,244 @@ func TestSubAccountService_UpdateBalance(t *testing.T) {
 		// Verify mock expectations
 		mockSubAccountRepo.AssertExpectations(t)
 	})
+
+	// Test that the balances of a real trading cannot be updated manually
+	t.Run("real_trading_rejected", func(t *testing.T) {
+		realTradingID := uuid.New()
+		realSubAccount := subAccountFactory.WithUserAndTrading(userID, realTradingID)
+
+		request := &services.UpdateBalanceRequest{
+			Amount:    decimal.NewFromInt(500),
+			Direction: "credit",
+			Reason:    "deposit",
+		}
+
+		// Setup mock expectations
+		mockSubAccountRepo.On("GetByID", mock.Anything, realSubAccount.ID).
+			Return(realSubAccount, nil).Once()
+		mockTradingRepo.On("GetByID", mock.Anything, realTradingID).
+			Return(&models.Trading{ID: realTradingID, UserID: userID, Type: models.TradingTypeReal}, nil).Once()
+
+		// Execute test
+		result, err := subAccountService.UpdateBalance(context.Background(), userID, realSubAccount.ID, request)
+
+		// Verify results
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "manual balance updates are not allowed for real tradings", err.Error())
+
+		// Verify mock expectations
+		mockSubAccountRepo.AssertExpectations(t)
+		mockTradingRepo.AssertExpectations(t)
+		mockSubAccountRepo.AssertNotCalled(t, "UpdateBalance", mock.Anything, realSubAccount.ID, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestSubAccountService_LockBalance tests the LockBalance and UnlockBalance functionality
+func TestSubAccountService_LockBalance(t *testing.T) {
+	userID := uuid.New()
+	tradingID := uuid.New()
+	subAccountFactory := helpers.NewSubAccountFactory()
+
+	newService := func() (*services.SubAccountService, *mocks.MockSubAccountRepository, *models.SubAccount) {
+		mockSubAccountRepo := &mocks.MockSubAccountRepository{}
+		repos := &repositories.Repositories{
+			SubAccount: mockSubAccountRepo,
+		}
+
+		subAccount := subAccountFactory.WithUserAndTrading(userID, tradingID)
+		subAccount.Balance = decimal.NewFromInt(1000)
+		subAccount.LockedBalance = decimal.NewFromInt(200)
+
+		return services.NewSubAccountService(repos), mockSubAccountRepo, subAccount
+	}
+
+	t.Run("successful_lock", func(t *testing.T) {
+		subAccountService, mockSubAccountRepo, subAccount := newService()
+		request := &services.LockBalanceRequest{Amount: decimal.NewFromInt(300)}
+
+		locked := *subAccount
+		locked.LockedBalance = decimal.NewFromInt(500)
+
+		mockSubAccountRepo.On("GetByID", mock.Anything, subAccount.ID).Return(subAccount, nil).Once()
+		mockSubAccountRepo.On("LockBalance", mock.Anything, subAccount.ID, helpers.DecimalArg(request.Amount)).
+			Return(&locked, nil).Once()
+
+		result, err := subAccountService.LockBalance(context.Background(), userID, subAccount.ID, request)
+
+		require.NoError(t, err)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(1000), result.Balance)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(500), result.LockedBalance)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(500), result.AvailableBalance)
+		mockSubAccountRepo.AssertExpectations(t)
+	})
+
+	t.Run("lock_more_than_available", func(t *testing.T) {
+		subAccountService, mockSubAccountRepo, subAccount := newService()
+		request := &services.LockBalanceRequest{Amount: decimal.NewFromInt(900)}
+
+		mockSubAccountRepo.On("GetByID", mock.Anything, subAccount.ID).Return(subAccount, nil).Once()
+		mockSubAccountRepo.On("LockBalance", mock.Anything, subAccount.ID, helpers.DecimalArg(request.Amount)).
+			Return(nil, repositories.ErrInsufficientAvailable).Once()
+
+		result, err := subAccountService.LockBalance(context.Background(), userID, subAccount.ID, request)
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "insufficient available balance", err.Error())
+		mockSubAccountRepo.AssertExpectations(t)
+	})
+
+	t.Run("successful_unlock", func(t *testing.T) {
+		subAccountService, mockSubAccountRepo, subAccount := newService()
+		request := &services.LockBalanceRequest{Amount: decimal.NewFromInt(200)}
+
+		unlocked := *subAccount
+		unlocked.LockedBalance = decimal.Zero
+
+		mockSubAccountRepo.On("GetByID", mock.Anything, subAccount.ID).Return(subAccount, nil).Once()
+		mockSubAccountRepo.On("UnlockBalance", mock.Anything, subAccount.ID, helpers.DecimalArg(request.Amount)).
+			Return(&unlocked, nil).Once()
+
+		result, err := subAccountService.UnlockBalance(context.Background(), userID, subAccount.ID, request)
+
+		require.NoError(t, err)
+		helpers.AssertDecimalEqual(t, decimal.Zero, result.LockedBalance)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(1000), result.AvailableBalance)
+		mockSubAccountRepo.AssertExpectations(t)
+	})
+
+	t.Run("unlock_more_than_locked", func(t *testing.T) {
+		subAccountService, mockSubAccountRepo, subAccount := newService()
+		request := &services.LockBalanceRequest{Amount: decimal.NewFromInt(300)}
+
+		mockSubAccountRepo.On("GetByID", mock.Anything, subAccount.ID).Return(subAccount, nil).Once()
+		mockSubAccountRepo.On("UnlockBalance", mock.Anything, subAccount.ID, helpers.DecimalArg(request.Amount)).
+			Return(nil, repositories.ErrInsufficientLocked).Once()
+
+		result, err := subAccountService.UnlockBalance(context.Background(), userID, subAccount.ID, request)
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "insufficient locked balance", err.Error())
+		mockSubAccountRepo.AssertExpectations(t)
+	})
+
+	t.Run("amount_must_be_positive", func(t *testing.T) {
+		subAccountService, mockSubAccountRepo, subAccount := newService()
+		request := &services.LockBalanceRequest{Amount: decimal.Zero}
+
+		result, err := subAccountService.LockBalance(context.Background(), userID, subAccount.ID, request)
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "amount must be positive", err.Error())
+		mockSubAccountRepo.AssertNotCalled(t, "LockBalance", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("subaccount_wrong_user", func(t *testing.T) {
+		subAccountService, mockSubAccountRepo, subAccount := newService()
+		request := &services.LockBalanceRequest{Amount: decimal.NewFromInt(100)}
+
+		mockSubAccountRepo.On("GetByID", mock.Anything, subAccount.ID).Return(subAccount, nil).Once()
+
+		result, err := subAccountService.LockBalance(context.Background(), uuid.New(), subAccount.ID, request)
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "sub-account not found", err.Error())
+		mockSubAccountRepo.AssertExpectations(t)
+	})
+}
+
+// TestSubAccountService_GetBalanceAt tests point-in-time balance queries
+func TestSubAccountService_GetBalanceAt(t *testing.T) {
+	userID := uuid.New()
+	subAccountFactory := helpers.NewSubAccountFactory()
+	at := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+
+	newService := func() (*services.SubAccountService, *mocks.MockSubAccountRepository, *mocks.MockTransactionRepository, *models.SubAccount) {
+		mockSubAccountRepo := &mocks.MockSubAccountRepository{}
+		mockTransactionRepo := &mocks.MockTransactionRepository{}
+		repos := &repositories.Repositories{
+			SubAccount:  mockSubAccountRepo,
+			Transaction: mockTransactionRepo,
+		}
+
+		subAccount := subAccountFactory.WithUserAndTrading(userID, uuid.New())
+		subAccount.Balance = decimal.NewFromInt(5000)
+
+		return services.NewSubAccountService(repos), mockSubAccountRepo, mockTransactionRepo, subAccount
+	}
+
+	t.Run("latest_closing_balance", func(t *testing.T) {
+		subAccountService, mockSubAccountRepo, mockTransactionRepo, subAccount := newService()
+		transaction := &models.Transaction{
+			ID:             uuid.New(),
+			SubAccountID:   subAccount.ID,
+			Timestamp:      at.Add(-time.Hour),
+			Direction:      "credit",
+			Amount:         decimal.NewFromInt(1000),
+			ClosingBalance: decimal.NewFromInt(3000),
+		}
+
+		mockSubAccountRepo.On("GetByID", mock.Anything, subAccount.ID).Return(subAccount, nil).Once()
+		mockTransactionRepo.On("GetLatestBySubAccountIDAt", mock.Anything, subAccount.ID, at).
+			Return([]*models.Transaction{transaction}, nil).Once()
+
+		result, err := subAccountService.GetBalanceAt(context.Background(), userID, subAccount.ID, at)
+
+		require.NoError(t, err)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(3000), result.Balance)
+		require.NotNil(t, result.TransactionID)
+		assert.Equal(t, transaction.ID, *result.TransactionID)
+		assert.Equal(t, "2025-01-31T23:59:59Z", result.At)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("transactions_sharing_a_timestamp", func(t *testing.T) {
+		subAccountService, mockSubAccountRepo, mockTransactionRepo, subAccount := newService()
+		// A trade debit and its fee were posted together; the fee closes the chain
+		fee := &models.Transaction{ID: uuid.New(), Timestamp: at, Direction: "debit", Amount: decimal.NewFromInt(12), ClosingBalance: decimal.NewFromInt(3988)}
+		trade := &models.Transaction{ID: uuid.New(), Timestamp: at, Direction: "debit", Amount: decimal.NewFromInt(6000), ClosingBalance: decimal.NewFromInt(4000)}
+
+		mockSubAccountRepo.On("GetByID", mock.Anything, subAccount.ID).Return(subAccount, nil).Once()
+		mockTransactionRepo.On("GetLatestBySubAccountIDAt", mock.Anything, subAccount.ID, at).
+			Return([]*models.Transaction{trade, fee}, nil).Once()
+
+		result, err := subAccountService.GetBalanceAt(context.Background(), userID, subAccount.ID, at)
+
+		require.NoError(t, err)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(3988), result.Balance)
+		assert.Equal(t, fee.ID, *result.TransactionID)
+	})
+
+	t.Run("no_transactions_yet", func(t *testing.T) {
+		subAccountService, mockSubAccountRepo, mockTransactionRepo, subAccount := newService()
+
+		mockSubAccountRepo.On("GetByID", mock.Anything, subAccount.ID).Return(subAccount, nil).Once()
+		mockTransactionRepo.On("GetLatestBySubAccountIDAt", mock.Anything, subAccount.ID, at).
+			Return([]*models.Transaction{}, nil).Once()
+
+		result, err := subAccountService.GetBalanceAt(context.Background(), userID, subAccount.ID, at)
+
+		require.NoError(t, err)
+		helpers.AssertDecimalEqual(t, decimal.Zero, result.Balance)
+		assert.Nil(t, result.TransactionID)
+	})
+
+	t.Run("subaccount_wrong_user", func(t *testing.T) {
+		subAccountService, mockSubAccountRepo, mockTransactionRepo, subAccount := newService()
+
+		mockSubAccountRepo.On("GetByID", mock.Anything, subAccount.ID).Return(subAccount, nil).Once()
+
+		result, err := subAccountService.GetBalanceAt(context.Background(), uuid.New(), subAccount.ID, at)
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "sub-account not found", err.Error())
+		mockTransactionRepo.AssertNotCalled(t, "GetLatestBySubAccountIDAt", mock.Anything, mock.Anything, mock.Anything)
+	})
 }
 
 // TestSubAccountService_DeleteSubAccount tests the DeleteSubAccount functionality
@@ -619,7 +939,7 @@ func TestSubAccountService_DeleteSubAccount(t *testing.T) {
 	subAccountFactory := helpers.NewSubAccountFactory()
 	testSubAccount := subAccountFactory.WithUserAndTrading(userID, tradingID)
 	testSubAccount.ID = subAccountID
-	testSubAccount.Balance = 0.0 // Zero balance for successful deletion
+	testSubAccount.Balance = decimal.NewFromInt(0) // Zero balance for successful deletion
 
 	// Test successful deletion
 	t.Run("successful_deletion", func(t *testing.T) {
@@ -643,7 +963,7 @@ func TestSubAccountService_DeleteSubAccount(t *testing.T) {
 	t.Run("deletion_with_balance", func(t *testing.T) {
 		subAccountWithBalance := subAccountFactory.WithUserAndTrading(userID, tradingID)
 		subAccountWithBalance.ID = subAccountID
-		subAccountWithBalance.Balance = 100.0 // Positive balance
+		subAccountWithBalance.Balance = decimal.NewFromInt(100) // Positive balance
 
 		// Setup mock expectations
 		mockSubAccountRepo.On("GetByID", mock.Anything, subAccountID).
@@ -682,7 +1002,7 @@ func TestSubAccountService_DeleteSubAccount(t *testing.T) {
 		differentUserID := uuid.New()
 		wrongUserSubAccount := subAccountFactory.WithUserAndTrading(differentUserID, tradingID)
 		wrongUserSubAccount.ID = subAccountID
-		wrongUserSubAccount.Balance = 0.0
+		wrongUserSubAccount.Balance = decimal.NewFromInt(0)
 
 		// Setup mock expectations
 		mockSubAccountRepo.On("GetByID", mock.Anything, subAccountID).
@@ -210,7 +210,9 @@ func TestTradingLogService_EventTime(t *testing.T) {
 	})
 
 	t.Run("event_time_future_timestamp", func(t *testing.T) {
-		// Test with future timestamp (should be allowed)
+		// Test with future timestamp (allowed for simulated tradings)
+		backtestTrading := *testTrading
+		backtestTrading.Type = models.TradingTypeBacktest
 		futureTime := time.Now().UTC().Add(24 * time.Hour)
 		request := &services.CreateTradingLogRequest{
 			TradingID: tradingID,
@@ -223,7 +225,7 @@ func TestTradingLogService_EventTime(t *testing.T) {
 
 		// Setup mock expectations
 		mockTradingRepo.On("GetByID", mock.Anything, tradingID).
-			Return(testTrading, nil).Once()
+			Return(&backtestTrading, nil).Once()
 		
 		var capturedTradingLog *models.TradingLog
 		mockTradingLogRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.TradingLog")).
@@ -245,6 +247,34 @@ func TestTradingLogService_EventTime(t *testing.T) {
 		mockTradingRepo.AssertExpectations(t)
 		mockTradingLogRepo.AssertExpectations(t)
 	})
+
+	t.Run("event_time_future_timestamp_real_trading", func(t *testing.T) {
+		// A real trading cannot log events that have not happened yet
+		futureTime := time.Now().UTC().Add(24 * time.Hour)
+		request := &services.CreateTradingLogRequest{
+			TradingID: tradingID,
+			Type:      "custom",
+			Source:    "manual",
+			Message:   "Test with future timestamp",
+			EventTime: &futureTime,
+			Info:      map[string]interface{}{"test": "future_value"},
+		}
+
+		// Setup mock expectations
+		mockTradingRepo.On("GetByID", mock.Anything, tradingID).
+			Return(testTrading, nil).Once()
+
+		// Execute test
+		result, err := tradingLogService.CreateTradingLog(context.Background(), userID, request)
+
+		// Verify results
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "event time cannot be in the future for a real trading")
+
+		// Verify mock expectations
+		mockTradingRepo.AssertExpectations(t)
+	})
 }
 
 // TestTradingLogService_EventTimeResponse tests that event_time is properly included in responses
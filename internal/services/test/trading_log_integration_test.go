@@ -10,6 +10,7 @@ import (
 	"tiris-backend/test/config"
 	"tiris-backend/test/helpers"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -53,14 +54,14 @@ func TestTradingLogService_Integration_EndToEnd(t *testing.T) {
 		// ETH account (stock)
 		ethAccount := subAccountFactory.WithUserAndTrading(testUser.ID, testTrading.ID)
 		ethAccount.Symbol = "ETH"
-		ethAccount.Balance = 0.0 // Starting with 0 ETH
+		ethAccount.Balance = decimal.NewFromInt(0) // Starting with 0 ETH
 		err = repos.SubAccount.Create(context.Background(), ethAccount)
 		require.NoError(t, err)
 
 		// USDT account (currency)
 		usdtAccount := subAccountFactory.WithUserAndTrading(testUser.ID, testTrading.ID)
 		usdtAccount.Symbol = "USDT"
-		usdtAccount.Balance = 10000.0 // Starting with 10,000 USDT
+		usdtAccount.Balance = decimal.NewFromInt(10000) // Starting with 10,000 USDT
 		err = repos.SubAccount.Create(context.Background(), usdtAccount)
 		require.NoError(t, err)
 
@@ -138,14 +139,14 @@ func TestTradingLogService_Integration_EndToEnd(t *testing.T) {
 		// ETH account (stock) - has ETH to sell
 		ethAccount := subAccountFactory.WithUserAndTrading(testUser.ID, testTrading.ID)
 		ethAccount.Symbol = "ETH"
-		ethAccount.Balance = 5.0 // Starting with 5 ETH
+		ethAccount.Balance = decimal.NewFromInt(5) // Starting with 5 ETH
 		err = repos.SubAccount.Create(context.Background(), ethAccount)
 		require.NoError(t, err)
 
 		// USDT account (currency)
 		usdtAccount := subAccountFactory.WithUserAndTrading(testUser.ID, testTrading.ID)
 		usdtAccount.Symbol = "USDT"
-		usdtAccount.Balance = 1000.0 // Starting with 1,000 USDT
+		usdtAccount.Balance = decimal.NewFromInt(1000) // Starting with 1,000 USDT
 		err = repos.SubAccount.Create(context.Background(), usdtAccount)
 		require.NoError(t, err)
 
@@ -222,14 +223,14 @@ func TestTradingLogService_Integration_EndToEnd(t *testing.T) {
 		// ETH account (stock) - has ETH to sell via stop-loss
 		ethAccount := subAccountFactory.WithUserAndTrading(testUser.ID, testTrading.ID)
 		ethAccount.Symbol = "ETH"
-		ethAccount.Balance = 3.0 // Starting with 3 ETH
+		ethAccount.Balance = decimal.NewFromInt(3) // Starting with 3 ETH
 		err = repos.SubAccount.Create(context.Background(), ethAccount)
 		require.NoError(t, err)
 
 		// USDT account (currency)
 		usdtAccount := subAccountFactory.WithUserAndTrading(testUser.ID, testTrading.ID)
 		usdtAccount.Symbol = "USDT"
-		usdtAccount.Balance = 500.0 // Starting with 500 USDT
+		usdtAccount.Balance = decimal.NewFromInt(500) // Starting with 500 USDT
 		err = repos.SubAccount.Create(context.Background(), usdtAccount)
 		require.NoError(t, err)
 
@@ -321,14 +322,14 @@ func TestTradingLogService_Integration_ErrorHandling(t *testing.T) {
 		// ETH account
 		ethAccount := subAccountFactory.WithUserAndTrading(testUser.ID, testTrading.ID)
 		ethAccount.Symbol = "ETH"
-		ethAccount.Balance = 0.0
+		ethAccount.Balance = decimal.NewFromInt(0)
 		err = repos.SubAccount.Create(context.Background(), ethAccount)
 		require.NoError(t, err)
 
 		// USDT account with insufficient balance
 		usdtAccount := subAccountFactory.WithUserAndTrading(testUser.ID, testTrading.ID)
 		usdtAccount.Symbol = "USDT"
-		usdtAccount.Balance = 100.0 // Insufficient for trade
+		usdtAccount.Balance = decimal.NewFromInt(100) // Insufficient for trade
 		err = repos.SubAccount.Create(context.Background(), usdtAccount)
 		require.NoError(t, err)
 
@@ -398,14 +399,14 @@ func TestTradingLogService_Integration_ErrorHandling(t *testing.T) {
 		subAccountFactory := helpers.NewSubAccountFactory()
 		otherUserAccount := subAccountFactory.WithUserAndTrading(otherUser.ID, testTrading.ID)
 		otherUserAccount.Symbol = "ETH"
-		otherUserAccount.Balance = 10.0
+		otherUserAccount.Balance = decimal.NewFromInt(10)
 		err = repos.SubAccount.Create(context.Background(), otherUserAccount)
 		require.NoError(t, err)
 
 		// Create account for test user
 		testUserAccount := subAccountFactory.WithUserAndTrading(testUser.ID, testTrading.ID)
 		testUserAccount.Symbol = "USDT"
-		testUserAccount.Balance = 10000.0
+		testUserAccount.Balance = decimal.NewFromInt(10000)
 		err = repos.SubAccount.Create(context.Background(), testUserAccount)
 		require.NoError(t, err)
 
@@ -477,14 +478,14 @@ func TestTradingLogService_Integration_ConcurrentTransactions(t *testing.T) {
 		// ETH account
 		ethAccount := subAccountFactory.WithUserAndTrading(testUser.ID, testTrading.ID)
 		ethAccount.Symbol = "ETH"
-		ethAccount.Balance = 10.0 // Has ETH to sell
+		ethAccount.Balance = decimal.NewFromInt(10) // Has ETH to sell
 		err = repos.SubAccount.Create(context.Background(), ethAccount)
 		require.NoError(t, err)
 
 		// USDT account
 		usdtAccount := subAccountFactory.WithUserAndTrading(testUser.ID, testTrading.ID)
 		usdtAccount.Symbol = "USDT"
-		usdtAccount.Balance = 1000.0
+		usdtAccount.Balance = decimal.NewFromInt(1000)
 		err = repos.SubAccount.Create(context.Background(), usdtAccount)
 		require.NoError(t, err)
 
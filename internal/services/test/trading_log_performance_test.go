@@ -12,6 +12,7 @@ import (
 	"tiris-backend/test/config"
 	"tiris-backend/test/helpers"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -46,14 +47,14 @@ func TestTradingLogService_Performance_HighVolume(t *testing.T) {
 		// ETH account with large balance
 		ethAccount := subAccountFactory.WithUserAndTrading(testUser.ID, testTrading.ID)
 		ethAccount.Symbol = "ETH"
-		ethAccount.Balance = 10000.0 // Large starting balance
+		ethAccount.Balance = decimal.NewFromInt(10000) // Large starting balance
 		err = repos.SubAccount.Create(context.Background(), ethAccount)
 		require.NoError(t, err)
 
 		// USDT account with large balance
 		usdtAccount := subAccountFactory.WithUserAndTrading(testUser.ID, testTrading.ID)
 		usdtAccount.Symbol = "USDT"
-		usdtAccount.Balance = 100000000.0 // 100M USDT
+		usdtAccount.Balance = decimal.NewFromInt(100000000) // 100M USDT
 		err = repos.SubAccount.Create(context.Background(), usdtAccount)
 		require.NoError(t, err)
 
@@ -120,14 +121,14 @@ func TestTradingLogService_Performance_HighVolume(t *testing.T) {
 			// ETH account
 			ethAccount := subAccountFactory.WithUserAndTrading(testUser.ID, testTrading.ID)
 			ethAccount.Symbol = fmt.Sprintf("ETH_%d", i)
-			ethAccount.Balance = 1000.0
+			ethAccount.Balance = decimal.NewFromInt(1000)
 			err = repos.SubAccount.Create(context.Background(), ethAccount)
 			require.NoError(t, err)
 
 			// USDT account
 			usdtAccount := subAccountFactory.WithUserAndTrading(testUser.ID, testTrading.ID)
 			usdtAccount.Symbol = fmt.Sprintf("USDT_%d", i)
-			usdtAccount.Balance = 10000000.0 // 10M USDT each
+			usdtAccount.Balance = decimal.NewFromInt(10000000) // 10M USDT each
 			err = repos.SubAccount.Create(context.Background(), usdtAccount)
 			require.NoError(t, err)
 		}
@@ -224,13 +225,13 @@ func TestTradingLogService_Performance_StressTest(t *testing.T) {
 
 		ethAccount := subAccountFactory.WithUserAndTrading(testUser.ID, testTrading.ID)
 		ethAccount.Symbol = "ETH"
-		ethAccount.Balance = 50000.0
+		ethAccount.Balance = decimal.NewFromInt(50000)
 		err = repos.SubAccount.Create(context.Background(), ethAccount)
 		require.NoError(t, err)
 
 		usdtAccount := subAccountFactory.WithUserAndTrading(testUser.ID, testTrading.ID)
 		usdtAccount.Symbol = "USDT"
-		usdtAccount.Balance = 1000000000.0 // 1B USDT
+		usdtAccount.Balance = decimal.NewFromInt(1000000000) // 1B USDT
 		err = repos.SubAccount.Create(context.Background(), usdtAccount)
 		require.NoError(t, err)
 
@@ -307,13 +308,13 @@ func TestTradingLogService_Performance_StressTest(t *testing.T) {
 
 		ethAccount := subAccountFactory.WithUserAndTrading(testUser.ID, testTrading.ID)
 		ethAccount.Symbol = "ETH"
-		ethAccount.Balance = 100000.0
+		ethAccount.Balance = decimal.NewFromInt(100000)
 		err = repos.SubAccount.Create(context.Background(), ethAccount)
 		require.NoError(t, err)
 
 		usdtAccount := subAccountFactory.WithUserAndTrading(testUser.ID, testTrading.ID)
 		usdtAccount.Symbol = "USDT"
-		usdtAccount.Balance = 100000000.0
+		usdtAccount.Balance = decimal.NewFromInt(100000000)
 		err = repos.SubAccount.Create(context.Background(), usdtAccount)
 		require.NoError(t, err)
 
@@ -394,13 +395,13 @@ func TestTradingLogService_Performance_Benchmarks(t *testing.T) {
 
 	ethAccount := subAccountFactory.WithUserAndTrading(testUser.ID, testTrading.ID)
 	ethAccount.Symbol = "ETH"
-	ethAccount.Balance = 1000000.0
+	ethAccount.Balance = decimal.NewFromInt(1000000)
 	err = repos.SubAccount.Create(context.Background(), ethAccount)
 	require.NoError(t, err)
 
 	usdtAccount := subAccountFactory.WithUserAndTrading(testUser.ID, testTrading.ID)
 	usdtAccount.Symbol = "USDT"
-	usdtAccount.Balance = 10000000000.0 // 10B USDT
+	usdtAccount.Balance = decimal.NewFromInt(10000000000) // 10B USDT
 	err = repos.SubAccount.Create(context.Background(), usdtAccount)
 	require.NoError(t, err)
 
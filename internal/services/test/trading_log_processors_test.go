@@ -8,9 +8,11 @@ import (
 	"tiris-backend/internal/models"
 	"tiris-backend/internal/repositories"
 	"tiris-backend/internal/services"
+	"tiris-backend/test/helpers"
 	"tiris-backend/test/mocks"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -36,6 +38,7 @@ func TestTradingLogProcessor_ProcessLongPosition(t *testing.T) {
 
 	// Create test data
 	userID := uuid.New()
+	tradingID := uuid.New()
 	stockAccountID := uuid.New()
 	currencyAccountID := uuid.New()
 
@@ -45,23 +48,23 @@ func TestTradingLogProcessor_ProcessLongPosition(t *testing.T) {
 			ID:      stockAccountID,
 			UserID:  userID,
 			Symbol:  "ETH",
-			Balance: 0.0, // Starting with 0 ETH
+			Balance: decimal.NewFromInt(0), // Starting with 0 ETH
 		}
 
 		currencyAccount := &models.SubAccount{
 			ID:      currencyAccountID,
 			UserID:  userID,
 			Symbol:  "USDT",
-			Balance: 10000.0, // Starting with 10,000 USDT
+			Balance: decimal.NewFromInt(10000), // Starting with 10,000 USDT
 		}
 
 		// Create trading info for long position
 		tradingInfo := &services.TradingLogInfo{
 			StockAccountID:    stockAccountID,
 			CurrencyAccountID: currencyAccountID,
-			Price:             3000.0,
-			Volume:            2.0,
-			Fee:               12.0,
+			Price:             decimal.NewFromInt(3000),
+			Volume:            decimal.NewFromInt(2),
+			Fee:               decimal.NewFromInt(12),
 			Stock:             "ETH",
 			Currency:          "USDT",
 		}
@@ -69,35 +72,38 @@ func TestTradingLogProcessor_ProcessLongPosition(t *testing.T) {
 		// Mock balance updates
 		stockTransactionID := uuid.New()
 		currencyTransactionID := uuid.New()
-		mockSubAccountRepo.On("UpdateBalance", mock.Anything, stockAccountID, 2.0, 2.0, "credit", "long", mock.Anything).Return(&stockTransactionID, nil)
-		mockSubAccountRepo.On("UpdateBalance", mock.Anything, currencyAccountID, 3988.0, 6012.0, "debit", "long", mock.Anything).Return(&currencyTransactionID, nil)
+		mockSubAccountRepo.On("UpdateBalance", mock.Anything, stockAccountID, helpers.DecimalArg(decimal.NewFromInt(2)), helpers.DecimalArg(decimal.NewFromInt(2)), "credit", "long", mock.Anything).Return(&stockTransactionID, nil)
+		mockSubAccountRepo.On("UpdateBalance", mock.Anything, currencyAccountID, helpers.DecimalArg(decimal.NewFromInt(3988)), helpers.DecimalArg(decimal.NewFromInt(6012)), "debit", "long", mock.Anything).Return(&currencyTransactionID, nil)
 
 		// Mock transaction retrieval
 		stockTransaction := &models.Transaction{
 			ID:             stockTransactionID,
 			UserID:         userID,
 			SubAccountID:   stockAccountID,
-			Amount:         2.0,
+			Amount:         decimal.NewFromInt(2),
 			Direction:      "credit",
 			Reason:         "long",
-			ClosingBalance: 2.0,
+			ClosingBalance: decimal.NewFromInt(2),
 		}
 		currencyTransaction := &models.Transaction{
 			ID:             currencyTransactionID,
 			UserID:         userID,
 			SubAccountID:   currencyAccountID,
-			Amount:         6012.0,
+			Amount:         decimal.NewFromInt(6012),
 			Direction:      "debit",
 			Reason:         "long",
-			ClosingBalance: 3988.0,
+			ClosingBalance: decimal.NewFromInt(3988),
 		}
 		mockTransactionRepo.On("GetByID", mock.Anything, stockTransactionID).Return(stockTransaction, nil)
 		mockTransactionRepo.On("GetByID", mock.Anything, currencyTransactionID).Return(currencyTransaction, nil)
+		mockTransactionRepo.On("SetPrice", mock.Anything, stockTransactionID, mock.Anything, "USDT").Return(nil)
+		mockTransactionRepo.On("SetPrice", mock.Anything, currencyTransactionID, mock.Anything, "USDT").Return(nil)
 
 		// Test the processing logic directly
 		tradingLogInfoMap := map[string]interface{}{"test": "data"}
 		transactions, accounts, err := processor.ProcessLongPosition(
 			context.Background(),
+			tradingID,
 			tradingInfo,
 			stockAccount,
 			currencyAccount,
@@ -114,10 +120,13 @@ func TestTradingLogProcessor_ProcessLongPosition(t *testing.T) {
 		assert.Len(t, accounts, 2)
 
 		// Verify stock account balance update
-		assert.Equal(t, 2.0, accounts[0].Balance)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(2), accounts[0].Balance)
+
+		// Verify average cost includes the fee: (3000 * 2 + 12) / 2
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(3006), accounts[0].AvgCost)
 
 		// Verify currency account balance update
-		assert.Equal(t, 3988.0, accounts[1].Balance)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(3988), accounts[1].Balance)
 
 		// Verify mock expectations
 		mockSubAccountRepo.AssertExpectations(t)
@@ -134,22 +143,22 @@ func TestTradingLogProcessor_ProcessLongPosition(t *testing.T) {
 			ID:      stockAccountID,
 			UserID:  userID,
 			Symbol:  "ETH",
-			Balance: 0.0,
+			Balance: decimal.NewFromInt(0),
 		}
 
 		currencyAccount := &models.SubAccount{
 			ID:      currencyAccountID,
 			UserID:  userID,
 			Symbol:  "USDT",
-			Balance: 1000.0, // Insufficient for 6012 USDT cost
+			Balance: decimal.NewFromInt(1000), // Insufficient for 6012 USDT cost
 		}
 
 		tradingInfo := &services.TradingLogInfo{
 			StockAccountID:    stockAccountID,
 			CurrencyAccountID: currencyAccountID,
-			Price:             3000.0,
-			Volume:            2.0,
-			Fee:               12.0,
+			Price:             decimal.NewFromInt(3000),
+			Volume:            decimal.NewFromInt(2),
+			Fee:               decimal.NewFromInt(12),
 			Stock:             "ETH",
 			Currency:          "USDT",
 		}
@@ -158,6 +167,7 @@ func TestTradingLogProcessor_ProcessLongPosition(t *testing.T) {
 		tradingLogInfoMap := map[string]interface{}{"test": "data"}
 		transactions, accounts, err := processor.ProcessLongPosition(
 			context.Background(),
+			tradingID,
 			tradingInfo,
 			stockAccount,
 			currencyAccount,
@@ -171,6 +181,53 @@ func TestTradingLogProcessor_ProcessLongPosition(t *testing.T) {
 		assert.Contains(t, err.Error(), "insufficient balance")
 		assert.Contains(t, err.Error(), "required 6012.00000000, available 1000.00000000")
 	})
+
+	t.Run("locked_currency_not_available_for_long_position", func(t *testing.T) {
+		// Reset mocks
+		mockSubAccountRepo := &mocks.MockSubAccountRepository{}
+		repos.SubAccount = mockSubAccountRepo
+
+		stockAccount := &models.SubAccount{
+			ID:      stockAccountID,
+			UserID:  userID,
+			Symbol:  "ETH",
+			Balance: decimal.NewFromInt(0),
+		}
+
+		// Enough balance for the 6012 USDT cost, but 5000 USDT are locked by open orders
+		currencyAccount := &models.SubAccount{
+			ID:            currencyAccountID,
+			UserID:        userID,
+			Symbol:        "USDT",
+			Balance:       decimal.NewFromInt(10000),
+			LockedBalance: decimal.NewFromInt(5000),
+		}
+
+		tradingInfo := &services.TradingLogInfo{
+			StockAccountID:    stockAccountID,
+			CurrencyAccountID: currencyAccountID,
+			Price:             decimal.NewFromInt(3000),
+			Volume:            decimal.NewFromInt(2),
+			Fee:               decimal.NewFromInt(12),
+			Stock:             "ETH",
+			Currency:          "USDT",
+		}
+
+		transactions, accounts, err := processor.ProcessLongPosition(
+			context.Background(),
+			tradingID,
+			tradingInfo,
+			stockAccount,
+			currencyAccount,
+			map[string]interface{}{"test": "data"},
+		)
+
+		require.Error(t, err)
+		assert.Nil(t, transactions)
+		assert.Nil(t, accounts)
+		assert.Contains(t, err.Error(), "required 6012.00000000, available 5000.00000000")
+		mockSubAccountRepo.AssertNotCalled(t, "UpdateBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
 }
 
 // TestTradingLogProcessor_ProcessShortPosition tests short position business logic
@@ -192,6 +249,7 @@ func TestTradingLogProcessor_ProcessShortPosition(t *testing.T) {
 	processor := services.NewTradingLogProcessor(repos)
 
 	userID := uuid.New()
+	tradingID := uuid.New()
 	stockAccountID := uuid.New()
 	currencyAccountID := uuid.New()
 
@@ -201,22 +259,23 @@ func TestTradingLogProcessor_ProcessShortPosition(t *testing.T) {
 			ID:      stockAccountID,
 			UserID:  userID,
 			Symbol:  "ETH",
-			Balance: 5.0, // Starting with 5 ETH
+			Balance: decimal.NewFromInt(5), // Starting with 5 ETH
+			AvgCost: decimal.NewFromInt(2000), // Bought at 2,000 USDT per ETH
 		}
 
 		currencyAccount := &models.SubAccount{
 			ID:      currencyAccountID,
 			UserID:  userID,
 			Symbol:  "USDT",
-			Balance: 1000.0, // Starting with 1,000 USDT
+			Balance: decimal.NewFromInt(1000), // Starting with 1,000 USDT
 		}
 
 		tradingInfo := &services.TradingLogInfo{
 			StockAccountID:    stockAccountID,
 			CurrencyAccountID: currencyAccountID,
-			Price:             3000.0,
-			Volume:            1.5,
-			Fee:               9.0,
+			Price:             decimal.NewFromInt(3000),
+			Volume:            decimal.RequireFromString("1.5"),
+			Fee:               decimal.NewFromInt(9),
 			Stock:             "ETH",
 			Currency:          "USDT",
 		}
@@ -224,34 +283,37 @@ func TestTradingLogProcessor_ProcessShortPosition(t *testing.T) {
 		// Mock balance updates for short position
 		stockTransactionID := uuid.New()
 		currencyTransactionID := uuid.New()
-		mockSubAccountRepo.On("UpdateBalance", mock.Anything, stockAccountID, 3.5, 1.5, "debit", "short", mock.Anything).Return(&stockTransactionID, nil)
-		mockSubAccountRepo.On("UpdateBalance", mock.Anything, currencyAccountID, 5491.0, 4491.0, "credit", "short", mock.Anything).Return(&currencyTransactionID, nil)
+		mockSubAccountRepo.On("UpdateBalance", mock.Anything, stockAccountID, helpers.DecimalArg(decimal.RequireFromString("3.5")), helpers.DecimalArg(decimal.RequireFromString("1.5")), "debit", "short", mock.Anything).Return(&stockTransactionID, nil)
+		mockSubAccountRepo.On("UpdateBalance", mock.Anything, currencyAccountID, helpers.DecimalArg(decimal.NewFromInt(5491)), helpers.DecimalArg(decimal.NewFromInt(4491)), "credit", "short", mock.Anything).Return(&currencyTransactionID, nil)
 
 		// Mock transaction retrieval
 		stockTransaction := &models.Transaction{
 			ID:             stockTransactionID,
 			UserID:         userID,
 			SubAccountID:   stockAccountID,
-			Amount:         1.5,
+			Amount:         decimal.RequireFromString("1.5"),
 			Direction:      "debit",
 			Reason:         "short",
-			ClosingBalance: 3.5,
+			ClosingBalance: decimal.RequireFromString("3.5"),
 		}
 		currencyTransaction := &models.Transaction{
 			ID:             currencyTransactionID,
 			UserID:         userID,
 			SubAccountID:   currencyAccountID,
-			Amount:         4491.0,
+			Amount:         decimal.NewFromInt(4491),
 			Direction:      "credit",
 			Reason:         "short",
-			ClosingBalance: 5491.0,
+			ClosingBalance: decimal.NewFromInt(5491),
 		}
 		mockTransactionRepo.On("GetByID", mock.Anything, stockTransactionID).Return(stockTransaction, nil)
 		mockTransactionRepo.On("GetByID", mock.Anything, currencyTransactionID).Return(currencyTransaction, nil)
+		mockTransactionRepo.On("SetPrice", mock.Anything, stockTransactionID, mock.Anything, "USDT").Return(nil)
+		mockTransactionRepo.On("SetPrice", mock.Anything, currencyTransactionID, mock.Anything, "USDT").Return(nil)
 
 		tradingLogInfoMap := map[string]interface{}{"test": "data"}
 		transactions, accounts, err := processor.ProcessShortPosition(
 			context.Background(),
+			tradingID,
 			tradingInfo,
 			stockAccount,
 			currencyAccount,
@@ -269,10 +331,17 @@ func TestTradingLogProcessor_ProcessShortPosition(t *testing.T) {
 		assert.Len(t, accounts, 2)
 
 		// Verify stock account was debited (sold ETH)
-		assert.Equal(t, 3.5, accounts[0].Balance) // 5.0 - 1.5
+		helpers.AssertDecimalEqual(t, decimal.RequireFromString("3.5"), accounts[0].Balance) // 5.0 - 1.5
 
 		// Verify currency account was credited (received USDT)
-		assert.Equal(t, 5491.0, accounts[1].Balance) // 1000 + 4491
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(5491), accounts[1].Balance) // 1000 + 4491
+
+		// Verify realized PnL: (3000 - 2000) * 1.5 - 9, average cost unchanged
+		require.NotNil(t, transactions[0].RealizedPnL)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(1491), *transactions[0].RealizedPnL)
+		assert.Nil(t, transactions[1].RealizedPnL)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(1491), accounts[0].RealizedPnL)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(2000), accounts[0].AvgCost)
 
 		// Verify mock expectations
 		mockSubAccountRepo.AssertExpectations(t)
@@ -289,22 +358,22 @@ func TestTradingLogProcessor_ProcessShortPosition(t *testing.T) {
 			ID:      stockAccountID,
 			UserID:  userID,
 			Symbol:  "ETH",
-			Balance: 0.5, // Only 0.5 ETH available
+			Balance: decimal.RequireFromString("0.5"), // Only 0.5 ETH available
 		}
 
 		currencyAccount := &models.SubAccount{
 			ID:      currencyAccountID,
 			UserID:  userID,
 			Symbol:  "USDT",
-			Balance: 1000.0,
+			Balance: decimal.NewFromInt(1000),
 		}
 
 		tradingInfo := &services.TradingLogInfo{
 			StockAccountID:    stockAccountID,
 			CurrencyAccountID: currencyAccountID,
-			Price:             3000.0,
-			Volume:            1.5, // Trying to sell 1.5 ETH
-			Fee:               9.0,
+			Price:             decimal.NewFromInt(3000),
+			Volume:            decimal.RequireFromString("1.5"), // Trying to sell 1.5 ETH
+			Fee:               decimal.NewFromInt(9),
 			Stock:             "ETH",
 			Currency:          "USDT",
 		}
@@ -313,6 +382,7 @@ func TestTradingLogProcessor_ProcessShortPosition(t *testing.T) {
 		tradingLogInfoMap := map[string]interface{}{"test": "data"}
 		transactions, accounts, err := processor.ProcessShortPosition(
 			context.Background(),
+			tradingID,
 			tradingInfo,
 			stockAccount,
 			currencyAccount,
@@ -327,45 +397,162 @@ func TestTradingLogProcessor_ProcessShortPosition(t *testing.T) {
 		assert.Contains(t, err.Error(), "insufficient balance")
 		assert.Contains(t, err.Error(), "required 1.50000000, available 0.50000000")
 	})
+
+	t.Run("locked_stock_not_available_for_short", func(t *testing.T) {
+		// Reset mocks
+		mockSubAccountRepo := &mocks.MockSubAccountRepository{}
+		repos.SubAccount = mockSubAccountRepo
+
+		// 2 ETH held, 1 ETH locked by an open sell order
+		stockAccount := &models.SubAccount{
+			ID:            stockAccountID,
+			UserID:        userID,
+			Symbol:        "ETH",
+			Balance:       decimal.NewFromInt(2),
+			LockedBalance: decimal.NewFromInt(1),
+		}
+
+		currencyAccount := &models.SubAccount{
+			ID:      currencyAccountID,
+			UserID:  userID,
+			Symbol:  "USDT",
+			Balance: decimal.NewFromInt(1000),
+		}
+
+		tradingInfo := &services.TradingLogInfo{
+			StockAccountID:    stockAccountID,
+			CurrencyAccountID: currencyAccountID,
+			Price:             decimal.NewFromInt(3000),
+			Volume:            decimal.RequireFromString("1.5"),
+			Fee:               decimal.NewFromInt(9),
+			Stock:             "ETH",
+			Currency:          "USDT",
+		}
+
+		transactions, accounts, err := processor.ProcessShortPosition(
+			context.Background(),
+			tradingID,
+			tradingInfo,
+			stockAccount,
+			currencyAccount,
+			map[string]interface{}{"test": "data"},
+			"short",
+		)
+
+		require.Error(t, err)
+		assert.Nil(t, transactions)
+		assert.Nil(t, accounts)
+		assert.Contains(t, err.Error(), "required 1.50000000, available 1.00000000")
+	})
+
+	t.Run("borrowed_stock_sold_short", func(t *testing.T) {
+		// Reset mocks
+		mockSubAccountRepo := &mocks.MockSubAccountRepository{}
+		mockTransactionRepo := &mocks.MockTransactionRepository{}
+		repos.SubAccount = mockSubAccountRepo
+		repos.Transaction = mockTransactionRepo
+
+		// 0.5 ETH held at 2000 and 2 ETH borrowed in margin mode
+		stockAccount := &models.SubAccount{
+			ID:       stockAccountID,
+			UserID:   userID,
+			Symbol:   "ETH",
+			Balance:  decimal.RequireFromString("0.5"),
+			AvgCost:  decimal.NewFromInt(2000),
+			Borrowed: decimal.NewFromInt(2),
+		}
+
+		currencyAccount := &models.SubAccount{
+			ID:      currencyAccountID,
+			UserID:  userID,
+			Symbol:  "USDT",
+			Balance: decimal.NewFromInt(1000),
+		}
+
+		tradingInfo := &services.TradingLogInfo{
+			StockAccountID:    stockAccountID,
+			CurrencyAccountID: currencyAccountID,
+			Price:             decimal.NewFromInt(3000),
+			Volume:            decimal.RequireFromString("1.5"),
+			Fee:               decimal.NewFromInt(9),
+			Stock:             "ETH",
+			Currency:          "USDT",
+		}
+
+		stockTransactionID := uuid.New()
+		currencyTransactionID := uuid.New()
+		mockSubAccountRepo.On("UpdateBalance", mock.Anything, stockAccountID, helpers.DecimalArg(decimal.NewFromInt(-1)), helpers.DecimalArg(decimal.RequireFromString("1.5")), "debit", "short", mock.Anything).Return(&stockTransactionID, nil)
+		mockSubAccountRepo.On("UpdateBalance", mock.Anything, currencyAccountID, helpers.DecimalArg(decimal.NewFromInt(5491)), helpers.DecimalArg(decimal.NewFromInt(4491)), "credit", "short", mock.Anything).Return(&currencyTransactionID, nil)
+		mockTransactionRepo.On("GetByID", mock.Anything, stockTransactionID).Return(&models.Transaction{ID: stockTransactionID, SubAccountID: stockAccountID}, nil)
+		mockTransactionRepo.On("GetByID", mock.Anything, currencyTransactionID).Return(&models.Transaction{ID: currencyTransactionID, SubAccountID: currencyAccountID}, nil)
+		mockTransactionRepo.On("SetPrice", mock.Anything, stockTransactionID, mock.Anything, "USDT").Return(nil)
+		mockTransactionRepo.On("SetPrice", mock.Anything, currencyTransactionID, mock.Anything, "USDT").Return(nil)
+
+		transactions, accounts, err := processor.ProcessShortPosition(
+			context.Background(),
+			tradingID,
+			tradingInfo,
+			stockAccount,
+			currencyAccount,
+			map[string]interface{}{"test": "data"},
+			"short",
+		)
+
+		require.NoError(t, err)
+		require.Len(t, transactions, 2)
+		require.Len(t, accounts, 2)
+
+		// 1 ETH is now sold short at 3000
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(-1), accounts[0].Balance)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(3000), accounts[0].AvgCost)
+
+		// Only the 0.5 ETH held realize a profit: (3000 - 2000) * 0.5 - 9
+		require.NotNil(t, transactions[0].RealizedPnL)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(491), *transactions[0].RealizedPnL)
+
+		mockSubAccountRepo.AssertExpectations(t)
+		mockTransactionRepo.AssertExpectations(t)
+	})
 }
 
 // TestTradingLogProcessor_FinancialCalculations tests precision and accuracy
 func TestTradingLogProcessor_FinancialCalculations(t *testing.T) {
 	t.Run("long_position_cost_calculation", func(t *testing.T) {
 		// Test calculation: price * volume + fee
-		price := 3000.12345678
-		volume := 2.87654321
-		fee := 15.99
+		price := decimal.RequireFromString("3000.12345678")
+		volume := decimal.RequireFromString("2.87654321")
+		fee := decimal.RequireFromString("15.99")
 
-		expectedTotal := price*volume + fee // 8628.034967... + 15.99
+		expectedTotal := decimal.RequireFromString("8645.9747587622374638")
 
-		// Verify the calculation matches expected result
-		actualTotal := price*volume + fee
-		assert.InDelta(t, expectedTotal, actualTotal, 0.00000001, "Long position cost calculation should be accurate")
+		// Verify the calculation is exact
+		actualTotal := price.Mul(volume).Add(fee)
+		helpers.AssertDecimalEqual(t, expectedTotal, actualTotal)
+		assert.Equal(t, "8645.97475876", actualTotal.Round(services.MaxDecimalPlaces).String())
 	})
 
 	t.Run("short_position_proceeds_calculation", func(t *testing.T) {
 		// Test calculation: price * volume - fee
-		price := 2800.50
-		volume := 1.25
-		fee := 8.75
+		price := decimal.RequireFromString("2800.50")
+		volume := decimal.RequireFromString("1.25")
+		fee := decimal.RequireFromString("8.75")
 
-		expectedProceeds := price*volume - fee // 3500.625 - 8.75 = 3491.875
+		expectedProceeds := decimal.RequireFromString("3491.875") // 3500.625 - 8.75
 
-		// Verify the calculation matches expected result
-		actualProceeds := price*volume - fee
-		assert.InDelta(t, expectedProceeds, actualProceeds, 0.00000001, "Short position proceeds calculation should be accurate")
+		// Verify the calculation is exact
+		actualProceeds := price.Mul(volume).Sub(fee)
+		helpers.AssertDecimalEqual(t, expectedProceeds, actualProceeds)
 	})
 
 	t.Run("zero_fee_calculation", func(t *testing.T) {
-		price := 1500.0
-		volume := 3.0
-		fee := 0.0
+		price := decimal.NewFromInt(1500)
+		volume := decimal.NewFromInt(3)
+		fee := decimal.Zero
 
-		expectedTotal := price * volume // 4500.0
-		actualTotal := price*volume + fee
+		expectedTotal := price.Mul(volume) // 4500
+		actualTotal := price.Mul(volume).Add(fee)
 
-		assert.Equal(t, expectedTotal, actualTotal, "Zero fee calculation should work correctly")
+		helpers.AssertDecimalEqual(t, expectedTotal, actualTotal)
 	})
 }
 
@@ -414,7 +601,7 @@ func TestTradingLogProcessor_ProcessWithdraw(t *testing.T) {
 	accountID := uuid.New()
 	sourceAccount := &models.SubAccount{
 		ID:      accountID,
-		Balance: 1000.0, // Starting balance
+		Balance: decimal.NewFromInt(1000), // Starting balance
 		Symbol:  "BTC",
 	}
 
@@ -422,14 +609,14 @@ func TestTradingLogProcessor_ProcessWithdraw(t *testing.T) {
 		// Create trading info for withdraw
 		tradingInfo := &services.TradingLogInfo{
 			StockAccountID: accountID,
-			Volume:         300.0, // Withdraw amount
+			Volume:         decimal.NewFromInt(300), // Withdraw amount
 			Stock:          "BTC", // Currency
 		}
 
 		// Mock the balance update
 		transactionID := uuid.New()
-		expectedNewBalance := sourceAccount.Balance - tradingInfo.Volume // 700.0
-		mockSubAccountRepo.On("UpdateBalance", mock.Anything, accountID, expectedNewBalance, tradingInfo.Volume, "debit", "withdraw", mock.Anything).Return(&transactionID, nil)
+		expectedNewBalance := sourceAccount.Balance.Sub(tradingInfo.Volume) // 700.0
+		mockSubAccountRepo.On("UpdateBalance", mock.Anything, accountID, helpers.DecimalArg(expectedNewBalance), helpers.DecimalArg(tradingInfo.Volume), "debit", "withdraw", mock.Anything).Return(&transactionID, nil)
 
 		// Create expected transaction
 		expectedTransaction := &models.Transaction{
@@ -442,7 +629,7 @@ func TestTradingLogProcessor_ProcessWithdraw(t *testing.T) {
 		}
 
 		// Add price and quote symbol
-		price := 1.0
+		price := decimal.NewFromInt(1)
 		expectedTransaction.Price = &price
 		expectedTransaction.QuoteSymbol = &tradingInfo.Stock
 
@@ -460,15 +647,15 @@ func TestTradingLogProcessor_ProcessWithdraw(t *testing.T) {
 
 		// Check transaction
 		assert.Equal(t, transactionID, transactions[0].ID)
-		assert.Equal(t, tradingInfo.Volume, transactions[0].Amount)
+		helpers.AssertDecimalEqual(t, tradingInfo.Volume, transactions[0].Amount)
 		assert.Equal(t, "debit", transactions[0].Direction)
-		assert.Equal(t, expectedNewBalance, transactions[0].ClosingBalance)
+		helpers.AssertDecimalEqual(t, expectedNewBalance, transactions[0].ClosingBalance)
 		assert.Equal(t, "withdraw", transactions[0].Reason)
-		assert.Equal(t, 1.0, *transactions[0].Price)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(1), *transactions[0].Price)
 		assert.Equal(t, "BTC", *transactions[0].QuoteSymbol)
 
 		// Check updated account
-		assert.Equal(t, expectedNewBalance, accounts[0].Balance)
+		helpers.AssertDecimalEqual(t, expectedNewBalance, accounts[0].Balance)
 
 		mockSubAccountRepo.AssertExpectations(t)
 		mockTransactionRepo.AssertExpectations(t)
@@ -478,13 +665,13 @@ func TestTradingLogProcessor_ProcessWithdraw(t *testing.T) {
 		// Create fresh account to avoid state pollution from previous test
 		freshSourceAccount := &models.SubAccount{
 			ID:      accountID,
-			Balance: 1000.0, // Starting balance
+			Balance: decimal.NewFromInt(1000), // Starting balance
 			Symbol:  "BTC",
 		}
 
 		tradingInfo := &services.TradingLogInfo{
 			StockAccountID: accountID,
-			Volume:         1500.0, // More than available balance
+			Volume:         decimal.NewFromInt(1500), // More than available balance
 			Stock:          "BTC",
 		}
 
@@ -498,6 +685,26 @@ func TestTradingLogProcessor_ProcessWithdraw(t *testing.T) {
 		assert.Contains(t, err.Error(), "available 1000.00000000")
 	})
 
+	t.Run("locked_balance_not_available_for_withdraw", func(t *testing.T) {
+		lockedSourceAccount := &models.SubAccount{
+			ID:            accountID,
+			Balance:       decimal.NewFromInt(1000),
+			LockedBalance: decimal.NewFromInt(600),
+			Symbol:        "BTC",
+		}
+
+		tradingInfo := &services.TradingLogInfo{
+			StockAccountID: accountID,
+			Volume:         decimal.NewFromInt(500),
+			Stock:          "BTC",
+		}
+
+		_, _, err := processor.ProcessWithdraw(context.Background(), tradingInfo, lockedSourceAccount, map[string]interface{}{"test": "data"})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "required 500.00000000, available 400.00000000")
+	})
+
 	t.Run("withdraw_balance_update_failure", func(t *testing.T) {
 		// Create separate mocks for this test
 		mockSubAccountRepoFail := &mocks.MockSubAccountRepository{}
@@ -517,7 +724,7 @@ func TestTradingLogProcessor_ProcessWithdraw(t *testing.T) {
 
 		tradingInfo := &services.TradingLogInfo{
 			StockAccountID: accountID,
-			Volume:         500.0, // Valid withdraw amount
+			Volume:         decimal.NewFromInt(500), // Valid withdraw amount
 			Stock:          "BTC",
 		}
 
@@ -552,25 +759,25 @@ func TestTradingLogProcessor_ProcessWithdraw(t *testing.T) {
 
 		tradingInfo := &services.TradingLogInfo{
 			StockAccountID: accountID,
-			Volume:         0.0, // Zero withdraw amount
+			Volume:         decimal.NewFromInt(0), // Zero withdraw amount
 			Stock:          "BTC",
 		}
 
 		// Should succeed with zero amount - balance remains the same
 		transactionID := uuid.New()
 		expectedNewBalance := sourceAccount.Balance // Should remain the same
-		mockSubAccountRepoZero.On("UpdateBalance", mock.Anything, accountID, expectedNewBalance, float64(0), "debit", "withdraw", mock.Anything).Return(&transactionID, nil)
+		mockSubAccountRepoZero.On("UpdateBalance", mock.Anything, accountID, helpers.DecimalArg(expectedNewBalance), helpers.DecimalArg(decimal.Zero), "debit", "withdraw", mock.Anything).Return(&transactionID, nil)
 
 		expectedTransaction := &models.Transaction{
 			ID:             transactionID,
 			SubAccountID:   accountID,
-			Amount:         0.0,
+			Amount:         decimal.NewFromInt(0),
 			Direction:      "debit",
 			ClosingBalance: expectedNewBalance,
 			Reason:         "withdraw",
 		}
 
-		price := 1.0
+		price := decimal.NewFromInt(1)
 		expectedTransaction.Price = &price
 		expectedTransaction.QuoteSymbol = &tradingInfo.Stock
 
@@ -584,8 +791,8 @@ func TestTradingLogProcessor_ProcessWithdraw(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Len(t, transactions, 1)
 		assert.Len(t, accounts, 1)
-		assert.Equal(t, float64(0), transactions[0].Amount)
-		assert.Equal(t, expectedNewBalance, accounts[0].Balance)
+		assert.True(t, transactions[0].Amount.IsZero())
+		helpers.AssertDecimalEqual(t, expectedNewBalance, accounts[0].Balance)
 
 		mockSubAccountRepoZero.AssertExpectations(t)
 		mockTransactionRepoZero.AssertExpectations(t)
@@ -617,8 +824,8 @@ func TestTradingLogProcessor_ProcessWithdraw(t *testing.T) {
 		}
 
 		transactionID := uuid.New()
-		expectedNewBalance := 0.0 // All balance withdrawn
-		mockSubAccountRepoExact.On("UpdateBalance", mock.Anything, accountID, expectedNewBalance, exactBalance, "debit", "withdraw", mock.Anything).Return(&transactionID, nil)
+		expectedNewBalance := decimal.Zero // All balance withdrawn
+		mockSubAccountRepoExact.On("UpdateBalance", mock.Anything, accountID, helpers.DecimalArg(expectedNewBalance), helpers.DecimalArg(exactBalance), "debit", "withdraw", mock.Anything).Return(&transactionID, nil)
 
 		expectedTransaction := &models.Transaction{
 			ID:             transactionID,
@@ -629,7 +836,7 @@ func TestTradingLogProcessor_ProcessWithdraw(t *testing.T) {
 			Reason:         "withdraw",
 		}
 
-		price := 1.0
+		price := decimal.NewFromInt(1)
 		expectedTransaction.Price = &price
 		expectedTransaction.QuoteSymbol = &tradingInfo.Stock
 
@@ -642,8 +849,8 @@ func TestTradingLogProcessor_ProcessWithdraw(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Len(t, transactions, 1)
 		assert.Len(t, accounts, 1)
-		assert.Equal(t, exactBalance, transactions[0].Amount)
-		assert.Equal(t, 0.0, accounts[0].Balance)
+		helpers.AssertDecimalEqual(t, exactBalance, transactions[0].Amount)
+		assert.True(t, accounts[0].Balance.IsZero())
 
 		mockSubAccountRepoExact.AssertExpectations(t)
 		mockTransactionRepoExact.AssertExpectations(t)
@@ -669,20 +876,20 @@ func TestTradingLogProcessor_ProcessWithdraw(t *testing.T) {
 		// Create account with sufficient balance for the test
 		accountWithBalance := &models.SubAccount{
 			ID:      accountID,
-			Balance: 1000.0, // Sufficient balance
+			Balance: decimal.NewFromInt(1000), // Sufficient balance
 			Symbol:  "BTC",
 		}
 
 		tradingInfo := &services.TradingLogInfo{
 			StockAccountID: accountID,
-			Volume:         200.0,
+			Volume:         decimal.NewFromInt(200),
 			Stock:          "BTC",
 		}
 
 		// Mock successful balance update but failed transaction retrieval
 		transactionID := uuid.New()
-		expectedNewBalance := accountWithBalance.Balance - tradingInfo.Volume
-		mockSubAccountRepoTxFail.On("UpdateBalance", mock.Anything, accountID, expectedNewBalance, tradingInfo.Volume, "debit", "withdraw", mock.Anything).Return(&transactionID, nil)
+		expectedNewBalance := accountWithBalance.Balance.Sub(tradingInfo.Volume)
+		mockSubAccountRepoTxFail.On("UpdateBalance", mock.Anything, accountID, helpers.DecimalArg(expectedNewBalance), helpers.DecimalArg(tradingInfo.Volume), "debit", "withdraw", mock.Anything).Return(&transactionID, nil)
 		mockTransactionRepoTxFail.On("GetByID", mock.Anything, transactionID).Return(nil, fmt.Errorf("transaction not found"))
 
 		tradingLogInfo := map[string]interface{}{"test": "tx_retrieval_failure"}
@@ -703,13 +910,13 @@ func TestTradingLogProcessor_ProcessWithdraw(t *testing.T) {
 		// Test case where withdraw amount is just slightly over the available balance
 		freshSourceAccount := &models.SubAccount{
 			ID:      accountID,
-			Balance: 999.99999999, // Almost 1000
+			Balance: decimal.RequireFromString("999.99999999"), // Almost 1000
 			Symbol:  "BTC",
 		}
 
 		tradingInfo := &services.TradingLogInfo{
 			StockAccountID: accountID,
-			Volume:         1000.0, // Slightly more than available
+			Volume:         decimal.NewFromInt(1000), // Slightly more than available
 			Stock:          "BTC",
 		}
 
@@ -743,12 +950,12 @@ func TestTradingLogProcessor_ProcessWithdraw(t *testing.T) {
 		// Create account with sufficient balance for precision testing
 		precisionAccount := &models.SubAccount{
 			ID:      accountID,
-			Balance: 1.0, // Sufficient balance for precision test
+			Balance: decimal.NewFromInt(1), // Sufficient balance for precision test
 			Symbol:  "BTC",
 		}
 
 		// Test with high precision amounts
-		precisionAmount := 0.12345678
+		precisionAmount := decimal.RequireFromString("0.12345678")
 		tradingInfo := &services.TradingLogInfo{
 			StockAccountID: accountID,
 			Volume:         precisionAmount,
@@ -756,8 +963,8 @@ func TestTradingLogProcessor_ProcessWithdraw(t *testing.T) {
 		}
 
 		transactionID := uuid.New()
-		expectedNewBalance := precisionAccount.Balance - precisionAmount
-		mockSubAccountRepoPrecision.On("UpdateBalance", mock.Anything, accountID, expectedNewBalance, precisionAmount, "debit", "withdraw", mock.Anything).Return(&transactionID, nil)
+		expectedNewBalance := precisionAccount.Balance.Sub(precisionAmount) // exactly 0.87654322
+		mockSubAccountRepoPrecision.On("UpdateBalance", mock.Anything, accountID, helpers.DecimalArg(expectedNewBalance), helpers.DecimalArg(precisionAmount), "debit", "withdraw", mock.Anything).Return(&transactionID, nil)
 
 		expectedTransaction := &models.Transaction{
 			ID:             transactionID,
@@ -768,7 +975,7 @@ func TestTradingLogProcessor_ProcessWithdraw(t *testing.T) {
 			Reason:         "withdraw",
 		}
 
-		price := 1.0
+		price := decimal.NewFromInt(1)
 		expectedTransaction.Price = &price
 		expectedTransaction.QuoteSymbol = &tradingInfo.Stock
 
@@ -781,10 +988,259 @@ func TestTradingLogProcessor_ProcessWithdraw(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Len(t, transactions, 1)
 		assert.Len(t, accounts, 1)
-		assert.Equal(t, precisionAmount, transactions[0].Amount)
-		assert.InDelta(t, expectedNewBalance, accounts[0].Balance, 0.00000001)
+		helpers.AssertDecimalEqual(t, precisionAmount, transactions[0].Amount)
+		helpers.AssertDecimalEqual(t, decimal.RequireFromString("0.87654322"), accounts[0].Balance)
 
 		mockSubAccountRepoPrecision.AssertExpectations(t)
 		mockTransactionRepoPrecision.AssertExpectations(t)
 	})
 }
+
+// TestTradingLogProcessor_ProcessTransfer tests transfer processing between sub-accounts
+func TestTradingLogProcessor_ProcessTransfer(t *testing.T) {
+	tradingID := uuid.New()
+	sourceID := uuid.New()
+	targetID := uuid.New()
+
+	newAccounts := func() (*models.SubAccount, *models.SubAccount) {
+		source := &models.SubAccount{ID: sourceID, TradingID: tradingID, Symbol: "USDT", Balance: decimal.NewFromInt(1000)}
+		target := &models.SubAccount{ID: targetID, TradingID: tradingID, Symbol: "USDT", Balance: decimal.NewFromInt(200)}
+		return source, target
+	}
+	tradingInfo := &services.TradingLogInfo{
+		StockAccountID:    sourceID,
+		CurrencyAccountID: targetID,
+		Volume:            helpers.Dec("250.5"),
+		Stock:             "USDT",
+	}
+	tradingLogInfo := map[string]interface{}{"test": "data"}
+
+	t.Run("successful_transfer", func(t *testing.T) {
+		mockSubAccountRepo := &mocks.MockSubAccountRepository{}
+		mockTransactionRepo := &mocks.MockTransactionRepository{}
+		processor := services.NewTradingLogProcessor(&repositories.Repositories{
+			SubAccount:  mockSubAccountRepo,
+			Transaction: mockTransactionRepo,
+		})
+		source, target := newAccounts()
+
+		debitID := uuid.New()
+		creditID := uuid.New()
+		mockSubAccountRepo.On("UpdateBalance", mock.Anything, sourceID, helpers.DecimalArg(helpers.Dec("749.5")), helpers.DecimalArg(helpers.Dec("250.5")), "debit", "transfer", mock.Anything).
+			Return(&debitID, nil).Once()
+		mockSubAccountRepo.On("UpdateBalance", mock.Anything, targetID, helpers.DecimalArg(helpers.Dec("450.5")), helpers.DecimalArg(helpers.Dec("250.5")), "credit", "transfer", mock.Anything).
+			Return(&creditID, nil).Once()
+		mockTransactionRepo.On("GetByID", mock.Anything, debitID).
+			Return(&models.Transaction{ID: debitID, SubAccountID: sourceID, Direction: "debit", Reason: "transfer", Amount: helpers.Dec("250.5")}, nil).Once()
+		mockTransactionRepo.On("GetByID", mock.Anything, creditID).
+			Return(&models.Transaction{ID: creditID, SubAccountID: targetID, Direction: "credit", Reason: "transfer", Amount: helpers.Dec("250.5")}, nil).Once()
+
+		transactions, accounts, err := processor.ProcessTransfer(context.Background(), tradingID, tradingInfo, source, target, tradingLogInfo)
+
+		require.NoError(t, err)
+		require.Len(t, transactions, 2)
+		require.Len(t, accounts, 2)
+		assert.Equal(t, "debit", transactions[0].Direction)
+		assert.Equal(t, "credit", transactions[1].Direction)
+		assert.Equal(t, "USDT", *transactions[1].QuoteSymbol)
+		helpers.AssertDecimalEqual(t, helpers.Dec("749.5"), accounts[0].Balance)
+		helpers.AssertDecimalEqual(t, helpers.Dec("450.5"), accounts[1].Balance)
+
+		mockSubAccountRepo.AssertExpectations(t)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("insufficient_balance", func(t *testing.T) {
+		processor := services.NewTradingLogProcessor(&repositories.Repositories{})
+		source, target := newAccounts()
+		source.Balance = decimal.NewFromInt(100)
+
+		_, _, err := processor.ProcessTransfer(context.Background(), tradingID, tradingInfo, source, target, tradingLogInfo)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "insufficient balance in source account: required 250.50000000, available 100.00000000")
+	})
+
+	t.Run("different_symbol", func(t *testing.T) {
+		processor := services.NewTradingLogProcessor(&repositories.Repositories{})
+		source, target := newAccounts()
+		target.Symbol = "BTC"
+
+		_, _, err := processor.ProcessTransfer(context.Background(), tradingID, tradingInfo, source, target, tradingLogInfo)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "transfer accounts must both hold USDT")
+	})
+
+	t.Run("account_of_other_trading", func(t *testing.T) {
+		processor := services.NewTradingLogProcessor(&repositories.Repositories{})
+		source, target := newAccounts()
+		target.TradingID = uuid.New()
+
+		_, _, err := processor.ProcessTransfer(context.Background(), tradingID, tradingInfo, source, target, tradingLogInfo)
+
+		require.Error(t, err)
+		assert.Equal(t, "transfer accounts must belong to the trading", err.Error())
+	})
+}
+
+// TestTradingLogProcessor_FeeAccount tests fees charged to a separate fee account
+func TestTradingLogProcessor_FeeAccount(t *testing.T) {
+	userID := uuid.New()
+	tradingID := uuid.New()
+	stockAccountID := uuid.New()
+	currencyAccountID := uuid.New()
+	feeAccountID := uuid.New()
+	tradingLogInfo := map[string]interface{}{"test": "data"}
+
+	t.Run("long_with_fee_in_third_asset", func(t *testing.T) {
+		mockSubAccountRepo := &mocks.MockSubAccountRepository{}
+		mockTransactionRepo := &mocks.MockTransactionRepository{}
+		processor := services.NewTradingLogProcessor(&repositories.Repositories{
+			SubAccount:  mockSubAccountRepo,
+			Transaction: mockTransactionRepo,
+		})
+
+		stockAccount := &models.SubAccount{ID: stockAccountID, UserID: userID, Symbol: "ETH", Balance: decimal.Zero}
+		currencyAccount := &models.SubAccount{ID: currencyAccountID, UserID: userID, Symbol: "USDT", Balance: decimal.NewFromInt(10000)}
+		feeAccount := &models.SubAccount{ID: feeAccountID, UserID: userID, TradingID: tradingID, Symbol: "BNB", Balance: decimal.NewFromInt(1)}
+		tradingInfo := &services.TradingLogInfo{
+			StockAccountID:    stockAccountID,
+			CurrencyAccountID: currencyAccountID,
+			Price:             decimal.NewFromInt(3000),
+			Volume:            decimal.NewFromInt(2),
+			Fee:               helpers.Dec("0.015"),
+			Stock:             "ETH",
+			Currency:          "USDT",
+			FeeAccountID:      &feeAccountID,
+			FeeAsset:          "BNB",
+		}
+
+		stockTransactionID := uuid.New()
+		currencyTransactionID := uuid.New()
+		feeTransactionID := uuid.New()
+		mockSubAccountRepo.On("GetByID", mock.Anything, feeAccountID).Return(feeAccount, nil).Once()
+		mockSubAccountRepo.On("UpdateBalance", mock.Anything, stockAccountID, helpers.DecimalArg(decimal.NewFromInt(2)), helpers.DecimalArg(decimal.NewFromInt(2)), "credit", "long", mock.Anything).Return(&stockTransactionID, nil).Once()
+		mockSubAccountRepo.On("UpdateBalance", mock.Anything, currencyAccountID, helpers.DecimalArg(decimal.NewFromInt(4000)), helpers.DecimalArg(decimal.NewFromInt(6000)), "debit", "long", mock.Anything).Return(&currencyTransactionID, nil).Once()
+		mockSubAccountRepo.On("UpdateBalance", mock.Anything, feeAccountID, helpers.DecimalArg(helpers.Dec("0.985")), helpers.DecimalArg(helpers.Dec("0.015")), "debit", "fee", mock.Anything).Return(&feeTransactionID, nil).Once()
+		mockTransactionRepo.On("GetByID", mock.Anything, stockTransactionID).Return(&models.Transaction{ID: stockTransactionID, Reason: "long"}, nil).Once()
+		mockTransactionRepo.On("GetByID", mock.Anything, currencyTransactionID).Return(&models.Transaction{ID: currencyTransactionID, Reason: "long"}, nil).Once()
+		mockTransactionRepo.On("SetPrice", mock.Anything, stockTransactionID, mock.Anything, "USDT").Return(nil).Once()
+		mockTransactionRepo.On("SetPrice", mock.Anything, currencyTransactionID, mock.Anything, "USDT").Return(nil).Once()
+		mockTransactionRepo.On("GetByID", mock.Anything, feeTransactionID).Return(&models.Transaction{ID: feeTransactionID, Reason: "fee"}, nil).Once()
+
+		transactions, accounts, err := processor.ProcessLongPosition(context.Background(), tradingID, tradingInfo, stockAccount, currencyAccount, tradingLogInfo)
+
+		require.NoError(t, err)
+		require.Len(t, transactions, 3)
+		require.Len(t, accounts, 3)
+		assert.Equal(t, "fee", transactions[2].Reason)
+		assert.Equal(t, "BNB", *transactions[2].QuoteSymbol)
+		// A fee in a third asset is not part of the cost basis
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(3000), accounts[0].AvgCost)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(4000), accounts[1].Balance)
+		helpers.AssertDecimalEqual(t, helpers.Dec("0.985"), accounts[2].Balance)
+
+		mockSubAccountRepo.AssertExpectations(t)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("long_with_insufficient_fee_balance", func(t *testing.T) {
+		mockSubAccountRepo := &mocks.MockSubAccountRepository{}
+		processor := services.NewTradingLogProcessor(&repositories.Repositories{SubAccount: mockSubAccountRepo})
+
+		stockAccount := &models.SubAccount{ID: stockAccountID, UserID: userID, Symbol: "ETH", Balance: decimal.Zero}
+		currencyAccount := &models.SubAccount{ID: currencyAccountID, UserID: userID, Symbol: "USDT", Balance: decimal.NewFromInt(10000)}
+		feeAccount := &models.SubAccount{ID: feeAccountID, UserID: userID, TradingID: tradingID, Symbol: "BNB", Balance: helpers.Dec("0.01")}
+		tradingInfo := &services.TradingLogInfo{
+			StockAccountID:    stockAccountID,
+			CurrencyAccountID: currencyAccountID,
+			Price:             decimal.NewFromInt(3000),
+			Volume:            decimal.NewFromInt(2),
+			Fee:               helpers.Dec("0.015"),
+			FeeAccountID:      &feeAccountID,
+			FeeAsset:          "BNB",
+		}
+		mockSubAccountRepo.On("GetByID", mock.Anything, feeAccountID).Return(feeAccount, nil).Once()
+
+		_, _, err := processor.ProcessLongPosition(context.Background(), tradingID, tradingInfo, stockAccount, currencyAccount, tradingLogInfo)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "insufficient balance in fee account: required 0.01500000, available 0.01000000")
+		// Nothing is written when the fee cannot be paid
+		mockSubAccountRepo.AssertNotCalled(t, "UpdateBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fee_account_of_another_trading", func(t *testing.T) {
+		mockSubAccountRepo := &mocks.MockSubAccountRepository{}
+		processor := services.NewTradingLogProcessor(&repositories.Repositories{SubAccount: mockSubAccountRepo})
+
+		stockAccount := &models.SubAccount{ID: stockAccountID, UserID: userID, TradingID: tradingID, Symbol: "ETH", Balance: decimal.Zero}
+		currencyAccount := &models.SubAccount{ID: currencyAccountID, UserID: userID, TradingID: tradingID, Symbol: "USDT", Balance: decimal.NewFromInt(10000)}
+		feeAccount := &models.SubAccount{ID: feeAccountID, UserID: userID, TradingID: uuid.New(), Symbol: "BNB", Balance: decimal.NewFromInt(1)}
+		tradingInfo := &services.TradingLogInfo{
+			StockAccountID:    stockAccountID,
+			CurrencyAccountID: currencyAccountID,
+			Price:             decimal.NewFromInt(3000),
+			Volume:            decimal.NewFromInt(2),
+			Fee:               helpers.Dec("0.015"),
+			FeeAccountID:      &feeAccountID,
+			FeeAsset:          "BNB",
+		}
+		mockSubAccountRepo.On("GetByID", mock.Anything, feeAccountID).Return(feeAccount, nil).Once()
+
+		_, _, err := processor.ProcessLongPosition(context.Background(), tradingID, tradingInfo, stockAccount, currencyAccount, tradingLogInfo)
+
+		require.Error(t, err)
+		assert.Equal(t, "fee account must belong to the trading", err.Error())
+		mockSubAccountRepo.AssertNotCalled(t, "UpdateBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("short_with_fee_in_stock", func(t *testing.T) {
+		mockSubAccountRepo := &mocks.MockSubAccountRepository{}
+		mockTransactionRepo := &mocks.MockTransactionRepository{}
+		processor := services.NewTradingLogProcessor(&repositories.Repositories{
+			SubAccount:  mockSubAccountRepo,
+			Transaction: mockTransactionRepo,
+		})
+
+		stockAccount := &models.SubAccount{ID: stockAccountID, UserID: userID, Symbol: "ETH", Balance: decimal.NewFromInt(2), AvgCost: decimal.NewFromInt(2000)}
+		currencyAccount := &models.SubAccount{ID: currencyAccountID, UserID: userID, Symbol: "USDT", Balance: decimal.Zero}
+		tradingInfo := &services.TradingLogInfo{
+			StockAccountID:    stockAccountID,
+			CurrencyAccountID: currencyAccountID,
+			Price:             decimal.NewFromInt(3000),
+			Volume:            decimal.NewFromInt(1),
+			Fee:               helpers.Dec("0.001"),
+			Stock:             "ETH",
+			Currency:          "USDT",
+			FeeAccountID:      &stockAccountID,
+			FeeAsset:          "ETH",
+		}
+
+		stockTransactionID := uuid.New()
+		currencyTransactionID := uuid.New()
+		feeTransactionID := uuid.New()
+		mockSubAccountRepo.On("UpdateBalance", mock.Anything, stockAccountID, helpers.DecimalArg(decimal.NewFromInt(1)), helpers.DecimalArg(decimal.NewFromInt(1)), "debit", "short", mock.Anything).Return(&stockTransactionID, nil).Once()
+		mockSubAccountRepo.On("UpdateBalance", mock.Anything, currencyAccountID, helpers.DecimalArg(decimal.NewFromInt(3000)), helpers.DecimalArg(decimal.NewFromInt(3000)), "credit", "short", mock.Anything).Return(&currencyTransactionID, nil).Once()
+		mockSubAccountRepo.On("UpdateBalance", mock.Anything, stockAccountID, helpers.DecimalArg(helpers.Dec("0.999")), helpers.DecimalArg(helpers.Dec("0.001")), "debit", "fee", mock.Anything).Return(&feeTransactionID, nil).Once()
+		mockTransactionRepo.On("GetByID", mock.Anything, stockTransactionID).Return(&models.Transaction{ID: stockTransactionID, Reason: "short"}, nil).Once()
+		mockTransactionRepo.On("GetByID", mock.Anything, currencyTransactionID).Return(&models.Transaction{ID: currencyTransactionID, Reason: "short"}, nil).Once()
+		mockTransactionRepo.On("SetPrice", mock.Anything, stockTransactionID, mock.Anything, "USDT").Return(nil).Once()
+		mockTransactionRepo.On("SetPrice", mock.Anything, currencyTransactionID, mock.Anything, "USDT").Return(nil).Once()
+		mockTransactionRepo.On("GetByID", mock.Anything, feeTransactionID).Return(&models.Transaction{ID: feeTransactionID, Reason: "fee"}, nil).Once()
+
+		transactions, accounts, err := processor.ProcessShortPosition(context.Background(), tradingID, tradingInfo, stockAccount, currencyAccount, tradingLogInfo, "short")
+
+		require.NoError(t, err)
+		require.Len(t, transactions, 3)
+		// The stock account pays the fee but is reported once
+		require.Len(t, accounts, 2)
+		helpers.AssertDecimalEqual(t, helpers.Dec("0.999"), accounts[0].Balance)
+		// Fee in the stock is valued at the trade price: (3000 - 2000) * 1 - 0.001 * 3000
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(997), *transactions[0].RealizedPnL)
+
+		mockSubAccountRepo.AssertExpectations(t)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+}
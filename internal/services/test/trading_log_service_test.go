@@ -2,6 +2,7 @@ package test
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -271,6 +272,85 @@ func TestTradingLogService_CreateTradingLog(t *testing.T) {
 	})
 }
 
+// TestTradingLogService_BatchCreateTradingLogs tests batch request validation
+func TestTradingLogService_BatchCreateTradingLogs(t *testing.T) {
+	repos := &repositories.Repositories{
+		Trading:    &mocks.MockTradingRepository{},
+		SubAccount: &mocks.MockSubAccountRepository{},
+		TradingLog: &mocks.MockTradingLogRepository{},
+	}
+	tradingLogService := services.NewTradingLogService(repos, &gorm.DB{})
+	userID := uuid.New()
+
+	t.Run("empty_batch", func(t *testing.T) {
+		result, err := tradingLogService.BatchCreateTradingLogs(context.Background(), userID, &services.BatchCreateTradingLogsRequest{})
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "batch must contain at least one trading log", err.Error())
+	})
+
+	t.Run("batch_too_large", func(t *testing.T) {
+		logs := make([]services.CreateTradingLogRequest, services.MaxTradingLogBatchSize+1)
+		for i := range logs {
+			logs[i] = services.CreateTradingLogRequest{
+				TradingID: uuid.New(),
+				Type:      "custom",
+				Source:    "bot",
+				Message:   "fill",
+			}
+		}
+
+		result, err := tradingLogService.BatchCreateTradingLogs(context.Background(), userID, &services.BatchCreateTradingLogsRequest{Logs: logs})
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "batch cannot contain more than 500 trading logs")
+	})
+}
+
+// TestTradingLogService_CreateTradingLogIdempotent tests idempotency key handling
+func TestTradingLogService_CreateTradingLogIdempotent(t *testing.T) {
+	mockIdempotencyKeyRepo := &mocks.MockIdempotencyKeyRepository{}
+	repos := &repositories.Repositories{
+		Trading:        &mocks.MockTradingRepository{},
+		TradingLog:     &mocks.MockTradingLogRepository{},
+		IdempotencyKey: mockIdempotencyKeyRepo,
+	}
+	tradingLogService := services.NewTradingLogService(repos, &gorm.DB{})
+	userID := uuid.New()
+	request := &services.CreateTradingLogRequest{
+		TradingID: uuid.New(),
+		Type:      "custom",
+		Source:    "bot",
+		Message:   "fill",
+	}
+
+	t.Run("key_reused_with_different_request", func(t *testing.T) {
+		mockIdempotencyKeyRepo.On("GetByUserIDAndKey", mock.Anything, userID, "fill-1").
+			Return(&models.IdempotencyKey{UserID: userID, Key: "fill-1", RequestHash: "other"}, nil).Once()
+
+		result, replayed, err := tradingLogService.CreateTradingLogIdempotent(context.Background(), userID, "fill-1", request)
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.False(t, replayed)
+		assert.Equal(t, "idempotency key already used with a different request", err.Error())
+		mockIdempotencyKeyRepo.AssertExpectations(t)
+	})
+
+	t.Run("key_too_long", func(t *testing.T) {
+		key := strings.Repeat("k", services.MaxIdempotencyKeyLength+1)
+
+		result, replayed, err := tradingLogService.CreateTradingLogIdempotent(context.Background(), userID, key, request)
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.False(t, replayed)
+		assert.Contains(t, err.Error(), "idempotency key must be between 1 and 255 characters")
+	})
+}
+
 // TestTradingLogService_GetUserTradingLogs tests the GetUserTradingLogs functionality
 func TestTradingLogService_GetUserTradingLogs(t *testing.T) {
 	// Create mocks
@@ -778,6 +858,29 @@ func TestTradingLogService_DeleteTradingLog(t *testing.T) {
 		mockTradingLogRepo.AssertExpectations(t)
 	})
 
+	// Test deletion of an amended log (should fail, its transactions are already reversed)
+	t.Run("deletion_amended_log_failed", func(t *testing.T) {
+		correctionID := uuid.New()
+		testTradingLog := tradingLogFactory.WithUserID(userID)
+		testTradingLog.ID = tradingLogID
+		testTradingLog.Source = "manual"
+		testTradingLog.AmendedBy = &correctionID
+
+		// Setup mock expectations
+		mockTradingLogRepo.On("GetByID", mock.Anything, tradingLogID).
+			Return(testTradingLog, nil).Once()
+
+		// Execute test
+		err := tradingLogService.DeleteTradingLog(context.Background(), userID, tradingLogID)
+
+		// Verify results
+		require.Error(t, err)
+		assert.Equal(t, "cannot delete an amended trading log", err.Error())
+
+		// Verify mock expectations
+		mockTradingLogRepo.AssertExpectations(t)
+	})
+
 	// Test trading log not found
 	t.Run("trading_log_not_found", func(t *testing.T) {
 		// Setup mock expectations
@@ -0,0 +1,85 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"tiris-backend/internal/models"
+	"tiris-backend/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rebateTradingLogType is a custom business logic type used to exercise the registry
+type rebateTradingLogType struct{}
+
+func (rebateTradingLogType) Type() string {
+	return "rebate"
+}
+
+func (rebateTradingLogType) ValidateInfo(v *services.TradingLogValidator, info map[string]interface{}) (*services.TradingLogInfo, error) {
+	accountID, ok := info["account_id"].(string)
+	if !ok {
+		return nil, &services.ValidationError{Field: "account_id", Message: "is required", Type: "rebate"}
+	}
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, &services.ValidationError{Field: "account_id", Message: "must be a valid UUID", Type: "rebate"}
+	}
+	return &services.TradingLogInfo{StockAccountID: id}, nil
+}
+
+func (rebateTradingLogType) Process(ctx context.Context, p *services.TradingLogProcessor, input *services.TradingLogTypeInput) ([]*models.Transaction, []*models.SubAccount, error) {
+	return nil, nil, nil
+}
+
+// TestTradingLogTypeRegistry tests registration and lookup of business logic types
+func TestTradingLogTypeRegistry(t *testing.T) {
+	t.Run("default_types", func(t *testing.T) {
+		registry := services.NewDefaultTradingLogTypeRegistry()
+
+		assert.Equal(t, []string{"borrow", "deposit", "long", "repay", "short", "stop_loss", "transfer", "withdraw"}, registry.Types())
+
+		handler, exists := registry.Lookup("stop_loss")
+		require.True(t, exists)
+		assert.Equal(t, "stop_loss", handler.Type())
+
+		_, exists = registry.Lookup("trade")
+		assert.False(t, exists)
+	})
+
+	t.Run("duplicate_type", func(t *testing.T) {
+		registry := services.NewDefaultTradingLogTypeRegistry()
+
+		require.NoError(t, registry.Register(rebateTradingLogType{}))
+		err := registry.Register(rebateTradingLogType{})
+
+		require.Error(t, err)
+		assert.Equal(t, "trading log type rebate is already registered", err.Error())
+	})
+
+	t.Run("custom_type_validation", func(t *testing.T) {
+		registry := services.NewDefaultTradingLogTypeRegistry()
+		require.NoError(t, registry.Register(rebateTradingLogType{}))
+		validator := services.NewTradingLogValidatorWithTypes(registry)
+		accountID := uuid.New()
+
+		tradingInfo, err := validator.ValidateInfoStructure(map[string]interface{}{"account_id": accountID.String()}, "rebate")
+		require.NoError(t, err)
+		require.NotNil(t, tradingInfo)
+		assert.Equal(t, accountID, tradingInfo.StockAccountID)
+
+		_, err = validator.ValidateInfoStructure(map[string]interface{}{}, "rebate")
+		require.Error(t, err)
+		validationErr, ok := err.(*services.ValidationError)
+		require.True(t, ok)
+		assert.Equal(t, "account_id", validationErr.Field)
+
+		// Without registration the type is treated as a plain log
+		tradingInfo, err = services.NewTradingLogValidator().ValidateInfoStructure(map[string]interface{}{}, "rebate")
+		require.NoError(t, err)
+		assert.Nil(t, tradingInfo)
+	})
+}
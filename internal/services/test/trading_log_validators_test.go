@@ -4,8 +4,10 @@ import (
 	"testing"
 
 	"tiris-backend/internal/services"
+	"tiris-backend/test/helpers"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -65,11 +67,11 @@ func TestTradingLogValidator_ValidateInfoStructure(t *testing.T) {
 
 		require.NoError(t, err)
 		require.NotNil(t, tradingInfo)
-		assert.Equal(t, 3000.0, tradingInfo.Price)
-		assert.Equal(t, 2.5, tradingInfo.Volume)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(3000), tradingInfo.Price)
+		helpers.AssertDecimalEqual(t, decimal.RequireFromString("2.5"), tradingInfo.Volume)
 		assert.Equal(t, "ETH", tradingInfo.Stock)
 		assert.Equal(t, "USDT", tradingInfo.Currency)
-		assert.Equal(t, 12.5, tradingInfo.Fee)
+		helpers.AssertDecimalEqual(t, decimal.RequireFromString("12.5"), tradingInfo.Fee)
 	})
 
 	t.Run("non_business_logic_type", func(t *testing.T) {
@@ -336,9 +338,9 @@ func TestTradingLogValidator_ValidateInfoStructure(t *testing.T) {
 
 		require.NoError(t, err)
 		require.NotNil(t, tradingInfo)
-		assert.Equal(t, 3000.0, tradingInfo.Price)
-		assert.Equal(t, float64(2.5), tradingInfo.Volume)
-		assert.Equal(t, 12.0, tradingInfo.Fee)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(3000), tradingInfo.Price)
+		helpers.AssertDecimalEqual(t, decimal.RequireFromString("2.5"), tradingInfo.Volume)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(12), tradingInfo.Fee)
 	})
 
 	t.Run("valid_deposit_info", func(t *testing.T) {
@@ -353,11 +355,11 @@ func TestTradingLogValidator_ValidateInfoStructure(t *testing.T) {
 
 		require.NoError(t, err)
 		assert.NotNil(t, tradingInfo)
-		assert.Equal(t, accountID, tradingInfo.StockAccountID) // Account ID maps to StockAccountID
-		assert.Equal(t, 1000.50, tradingInfo.Volume)           // Amount maps to Volume
-		assert.Equal(t, "USDT", tradingInfo.Stock)             // Currency maps to Stock
-		assert.Equal(t, 1.0, tradingInfo.Price)                // Default price
-		assert.Equal(t, 0.0, tradingInfo.Fee)                  // No fees for deposits
+		assert.Equal(t, accountID, tradingInfo.StockAccountID)                                 // Account ID maps to StockAccountID
+		helpers.AssertDecimalEqual(t, decimal.RequireFromString("1000.5"), tradingInfo.Volume) // Amount maps to Volume
+		assert.Equal(t, "USDT", tradingInfo.Stock)                                             // Currency maps to Stock
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(1), tradingInfo.Price)                // Default price
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(0), tradingInfo.Fee)                  // No fees for deposits
 	})
 
 	t.Run("valid_withdraw_info", func(t *testing.T) {
@@ -372,11 +374,87 @@ func TestTradingLogValidator_ValidateInfoStructure(t *testing.T) {
 
 		require.NoError(t, err)
 		assert.NotNil(t, tradingInfo)
-		assert.Equal(t, accountID, tradingInfo.StockAccountID) // Account ID maps to StockAccountID
-		assert.Equal(t, 500.25, tradingInfo.Volume)            // Amount maps to Volume
-		assert.Equal(t, "BTC", tradingInfo.Stock)              // Currency maps to Stock
-		assert.Equal(t, 1.0, tradingInfo.Price)                // Default price
-		assert.Equal(t, 0.0, tradingInfo.Fee)                  // No fees for withdrawals
+		assert.Equal(t, accountID, tradingInfo.StockAccountID)                                 // Account ID maps to StockAccountID
+		helpers.AssertDecimalEqual(t, decimal.RequireFromString("500.25"), tradingInfo.Volume) // Amount maps to Volume
+		assert.Equal(t, "BTC", tradingInfo.Stock)                                              // Currency maps to Stock
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(1), tradingInfo.Price)                // Default price
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(0), tradingInfo.Fee)                  // No fees for withdrawals
+	})
+
+	t.Run("fee_account_info", func(t *testing.T) {
+		feeAccountID := uuid.New()
+		info := map[string]interface{}{
+			"stock_account_id":    uuid.New().String(),
+			"currency_account_id": uuid.New().String(),
+			"price":               3000.0,
+			"volume":              2.0,
+			"stock":               "ETH",
+			"currency":            "USDT",
+			"fee":                 0.015,
+			"fee_account_id":      feeAccountID.String(),
+			"fee_asset":           "BNB",
+		}
+
+		tradingInfo, err := validator.ValidateInfoStructure(info, "long")
+
+		require.NoError(t, err)
+		require.NotNil(t, tradingInfo.FeeAccountID)
+		assert.Equal(t, feeAccountID, *tradingInfo.FeeAccountID)
+		assert.Equal(t, "BNB", tradingInfo.FeeAsset)
+
+		delete(info, "fee_asset")
+		_, err = validator.ValidateInfoStructure(info, "long")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "fee_asset")
+
+		delete(info, "fee_account_id")
+		info["fee_asset"] = "BNB"
+		_, err = validator.ValidateInfoStructure(info, "long")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "fee_account_id")
+	})
+
+	t.Run("valid_transfer_info", func(t *testing.T) {
+		fromAccountID := uuid.New()
+		toAccountID := uuid.New()
+		info := map[string]interface{}{
+			"from_account_id": fromAccountID.String(),
+			"to_account_id":   toAccountID.String(),
+			"amount":          "500.25",
+			"currency":        "USDT",
+		}
+
+		tradingInfo, err := validator.ValidateInfoStructure(info, "transfer")
+
+		require.NoError(t, err)
+		require.NotNil(t, tradingInfo)
+		assert.Equal(t, fromAccountID, tradingInfo.StockAccountID)  // From maps to StockAccountID
+		assert.Equal(t, toAccountID, tradingInfo.CurrencyAccountID) // To maps to CurrencyAccountID
+		helpers.AssertDecimalEqual(t, decimal.RequireFromString("500.25"), tradingInfo.Volume)
+		assert.Equal(t, "USDT", tradingInfo.Stock)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(0), tradingInfo.Fee)
+	})
+
+	t.Run("transfer_validation_errors", func(t *testing.T) {
+		accountID := uuid.New().String()
+		testCases := []struct {
+			name          string
+			info          map[string]interface{}
+			expectedError string
+		}{
+			{"missing_from_account_id", map[string]interface{}{"to_account_id": accountID, "amount": 10.0, "currency": "USDT"}, "from_account_id"},
+			{"invalid_to_account_id", map[string]interface{}{"from_account_id": accountID, "to_account_id": "invalid-uuid", "amount": 10.0, "currency": "USDT"}, "to_account_id"},
+			{"zero_amount", map[string]interface{}{"from_account_id": accountID, "to_account_id": uuid.New().String(), "amount": 0.0, "currency": "USDT"}, "amount"},
+			{"same_account", map[string]interface{}{"from_account_id": accountID, "to_account_id": accountID, "amount": 10.0, "currency": "USDT"}, "must be different"},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := validator.ValidateInfoStructure(tc.info, "transfer")
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+			})
+		}
 	})
 
 	t.Run("deposit_withdraw_validation_errors", func(t *testing.T) {
@@ -440,21 +518,21 @@ func TestTradingLogValidator_ValidateDecimalPrecision(t *testing.T) {
 
 	testCases := []struct {
 		name        string
-		value       float64
+		value       string
 		maxDecimals int
 		expectError bool
 	}{
-		{"valid_2_decimals", 123.45, 2, false},
-		{"valid_8_decimals", 123.12345678, 8, false},
-		{"valid_no_decimals", 123.0, 8, false},
-		{"valid_trailing_zeros", 123.450000, 8, false},
-		{"invalid_too_many_decimals", 123.123456789, 8, true},
-		{"invalid_3_decimals_max_2", 123.456, 2, true},
+		{"valid_2_decimals", "123.45", 2, false},
+		{"valid_8_decimals", "123.12345678", 8, false},
+		{"valid_no_decimals", "123", 8, false},
+		{"valid_trailing_zeros", "123.450000", 8, false},
+		{"invalid_too_many_decimals", "123.123456789", 8, true},
+		{"invalid_3_decimals_max_2", "123.456", 2, true},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			err := validator.ValidateDecimalPrecision(tc.value, "test_field", tc.maxDecimals)
+			err := validator.ValidateDecimalPrecision(decimal.RequireFromString(tc.value), "test_field", tc.maxDecimals)
 
 			if tc.expectError {
 				require.Error(t, err)
@@ -4,20 +4,36 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
+	"tiris-backend/internal/exchange"
 	"tiris-backend/internal/models"
 	"tiris-backend/internal/repositories"
 	"tiris-backend/internal/services"
+	"tiris-backend/pkg/security"
 	"tiris-backend/test/config"
 	"tiris-backend/test/helpers"
 	"tiris-backend/test/mocks"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
+func newTestKeyring(t *testing.T) *security.Keyring {
+	testConfig := config.GetProfileConfig(config.ProfileQuick)
+	keyring, err := security.NewKeyring(map[int]string{1: testConfig.Security.MasterKey}, 1)
+	require.NoError(t, err)
+	return keyring
+}
+
+func newTestExchangeBindingManager(t *testing.T) *models.ExchangeBindingManager {
+	testConfig := config.GetProfileConfig(config.ProfileQuick)
+	return models.NewExchangeBindingManager(newTestKeyring(t), testConfig.Security.SigningKey)
+}
+
 // TestTradingService_CreateTrading tests the CreateTrading functionality
 func TestTradingService_CreateTrading(t *testing.T) {
 	// Setup test config
@@ -40,7 +56,7 @@ func TestTradingService_CreateTrading(t *testing.T) {
 	}
 
 	// Create exchange binding service and trading service
-	exchangeBindingService := services.NewExchangeBindingService(repos.ExchangeBinding)
+	exchangeBindingService := services.NewExchangeBindingService(repos.ExchangeBinding, newTestExchangeBindingManager(t), exchange.NewFakeRegistry(exchange.NewFakeConnector()))
 	tradingService := services.NewTradingService(repos, exchangeBindingService)
 
 	// Create test data
@@ -194,6 +210,130 @@ func TestTradingService_CreateTrading(t *testing.T) {
 		mockTradingRepo.AssertExpectations(t)
 		mockExchangeBindingRepo.AssertExpectations(t)
 	})
+
+	// Test simulation trading on a virtual exchange binding
+	t.Run("simulation_on_virtual_binding", func(t *testing.T) {
+		request := &services.CreateTradingRequest{
+			Name:              "paper-trading",
+			Type:              "simulation",
+			ExchangeBindingID: uuid.New(),
+		}
+
+		// Access validation and the binding check both load the binding
+		mockExchangeBindingRepo.On("GetByID", mock.Anything, request.ExchangeBindingID).
+			Return(&models.ExchangeBinding{
+				ID:       request.ExchangeBindingID,
+				UserID:   &userID,
+				Exchange: "virtual",
+				Type:     "private",
+			}, nil).Twice()
+		mockTradingRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Trading")).
+			Return(nil).Once()
+
+		// Execute test
+		result, err := tradingService.CreateTrading(context.Background(), userID, request)
+
+		// Verify results
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		assert.Equal(t, "simulation", result.Type)
+
+		// Verify mock expectations
+		mockTradingRepo.AssertExpectations(t)
+		mockExchangeBindingRepo.AssertExpectations(t)
+	})
+
+	// Test backtest trading on a real exchange binding
+	t.Run("backtest_on_real_binding", func(t *testing.T) {
+		request := &services.CreateTradingRequest{
+			Name:              "backtest",
+			Type:              "backtest",
+			ExchangeBindingID: uuid.New(),
+		}
+
+		mockExchangeBindingRepo.On("GetByID", mock.Anything, request.ExchangeBindingID).
+			Return(&models.ExchangeBinding{
+				ID:       request.ExchangeBindingID,
+				UserID:   &userID,
+				Exchange: "binance",
+				Type:     "private",
+			}, nil).Twice()
+
+		// Execute test
+		result, err := tradingService.CreateTrading(context.Background(), userID, request)
+
+		// Verify results
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "simulation and backtest tradings must use a virtual exchange binding", err.Error())
+
+		// Verify mock expectations
+		mockExchangeBindingRepo.AssertExpectations(t)
+	})
+
+	// Test simulation trading with virtual exchange fees and slippage
+	t.Run("simulation_with_virtual_exchange_settings", func(t *testing.T) {
+		request := &services.CreateTradingRequest{
+			Name:              "paper-trading-fees",
+			Type:              "simulation",
+			ExchangeBindingID: uuid.New(),
+			VirtualExchange: &services.VirtualExchangeConfig{
+				FeeModel:      services.VirtualFeeModelPercentage,
+				MakerFeeRate:  decimal.RequireFromString("0.001"),
+				TakerFeeRate:  decimal.RequireFromString("0.002"),
+				SlippageModel: services.VirtualSlippageModelFixed,
+				SlippageBps:   decimal.NewFromInt(5),
+			},
+		}
+
+		mockExchangeBindingRepo.On("GetByID", mock.Anything, request.ExchangeBindingID).
+			Return(&models.ExchangeBinding{
+				ID:       request.ExchangeBindingID,
+				UserID:   &userID,
+				Exchange: "virtual",
+				Type:     "private",
+			}, nil).Twice()
+		mockTradingRepo.On("Create", mock.Anything, mock.MatchedBy(func(trading *models.Trading) bool {
+			settings, ok := trading.Info[services.VirtualExchangeInfoKey].(map[string]interface{})
+			return ok && settings["fee_model"] == "percentage" && settings["taker_fee_rate"] == "0.002"
+		})).Return(nil).Once()
+
+		// Execute test
+		result, err := tradingService.CreateTrading(context.Background(), userID, request)
+
+		// Verify results
+		require.NoError(t, err)
+		require.NotNil(t, result)
+
+		// Verify mock expectations
+		mockTradingRepo.AssertExpectations(t)
+		mockExchangeBindingRepo.AssertExpectations(t)
+	})
+
+	// Test virtual exchange settings on a real trading
+	t.Run("real_with_virtual_exchange_settings", func(t *testing.T) {
+		request := &services.CreateTradingRequest{
+			Name:              "binance-fees",
+			Type:              "real",
+			ExchangeBindingID: uuid.New(),
+			VirtualExchange:   &services.VirtualExchangeConfig{FeeModel: services.VirtualFeeModelNone},
+		}
+
+		mockExchangeBindingRepo.On("GetByID", mock.Anything, request.ExchangeBindingID).
+			Return(&models.ExchangeBinding{
+				ID:     request.ExchangeBindingID,
+				UserID: &userID,
+				Type:   "private",
+			}, nil).Once()
+
+		// Execute test
+		result, err := tradingService.CreateTrading(context.Background(), userID, request)
+
+		// Verify results
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "virtual exchange settings require a simulation or backtest trading", err.Error())
+	})
 }
 
 // TestTradingService_GetUserTradings tests the GetUserTradings functionality
@@ -201,13 +341,14 @@ func TestTradingService_GetUserTradings(t *testing.T) {
 	// Create mocks
 	mockTradingRepo := &mocks.MockTradingRepository{}
 	mockExchangeBindingRepo := &mocks.MockExchangeBindingRepository{}
+	mockSubAccountRepo := &mocks.MockSubAccountRepository{}
 
 	// Create repositories with mocks
 	repos := &repositories.Repositories{
 		User:            &mocks.MockUserRepository{},
 		Trading:         mockTradingRepo,
 		ExchangeBinding: mockExchangeBindingRepo,
-		SubAccount:      &mocks.MockSubAccountRepository{},
+		SubAccount:      mockSubAccountRepo,
 		Transaction:     &mocks.MockTransactionRepository{},
 		TradingLog:      &mocks.MockTradingLogRepository{},
 		OAuthToken:      &mocks.MockOAuthTokenRepository{},
@@ -215,7 +356,7 @@ func TestTradingService_GetUserTradings(t *testing.T) {
 	}
 
 	// Create exchange binding service and trading service
-	exchangeBindingService := services.NewExchangeBindingService(repos.ExchangeBinding)
+	exchangeBindingService := services.NewExchangeBindingService(repos.ExchangeBinding, newTestExchangeBindingManager(t), exchange.NewFakeRegistry(exchange.NewFakeConnector()))
 	tradingService := services.NewTradingService(repos, exchangeBindingService)
 
 	// Create test data
@@ -232,9 +373,20 @@ func TestTradingService_GetUserTradings(t *testing.T) {
 		testTradings[0].Name = "binance-main"
 		testTradings[1].Name = "okx-trading"
 
+		// Sub-accounts holding realized PnL, two of them in the first trading
+		subAccountFactory := helpers.NewSubAccountFactory()
+		testSubAccounts := []*models.SubAccount{
+			subAccountFactory.WithUserAndTrading(userID, testTradings[0].ID),
+			subAccountFactory.WithUserAndTrading(userID, testTradings[0].ID),
+		}
+		testSubAccounts[0].RealizedPnL = decimal.RequireFromString("120.5")
+		testSubAccounts[1].RealizedPnL = decimal.RequireFromString("-20.25")
+
 		// Setup mock expectations
 		mockTradingRepo.On("GetByUserID", mock.Anything, userID).
 			Return(testTradings, nil).Once()
+		mockSubAccountRepo.On("GetByUserID", mock.Anything, userID, (*uuid.UUID)(nil)).
+			Return(testSubAccounts, nil).Once()
 
 		// Execute test
 		result, err := tradingService.GetUserTradings(context.Background(), userID)
@@ -244,6 +396,8 @@ func TestTradingService_GetUserTradings(t *testing.T) {
 		require.Len(t, result, 2)
 		assert.Equal(t, "binance-main", result[0].Name)
 		assert.Equal(t, "okx-trading", result[1].Name)
+		helpers.AssertDecimalEqual(t, decimal.RequireFromString("100.25"), result[0].RealizedPnL)
+		assert.True(t, result[1].RealizedPnL.IsZero())
 
 		// Verify exchange binding information is included
 		for _, trading := range result {
@@ -262,6 +416,8 @@ func TestTradingService_GetUserTradings(t *testing.T) {
 		// Setup mock expectations
 		mockTradingRepo.On("GetByUserID", mock.Anything, userID).
 			Return([]*models.Trading{}, nil).Once()
+		mockSubAccountRepo.On("GetByUserID", mock.Anything, userID, (*uuid.UUID)(nil)).
+			Return([]*models.SubAccount{}, nil).Once()
 
 		// Execute test
 		result, err := tradingService.GetUserTradings(context.Background(), userID)
@@ -281,13 +437,14 @@ func TestTradingService_GetTrading(t *testing.T) {
 	// Create mocks
 	mockTradingRepo := &mocks.MockTradingRepository{}
 	mockExchangeBindingRepo := &mocks.MockExchangeBindingRepository{}
+	mockSubAccountRepo := &mocks.MockSubAccountRepository{}
 
 	// Create repositories with mocks
 	repos := &repositories.Repositories{
 		User:            &mocks.MockUserRepository{},
 		Trading:         mockTradingRepo,
 		ExchangeBinding: mockExchangeBindingRepo,
-		SubAccount:      &mocks.MockSubAccountRepository{},
+		SubAccount:      mockSubAccountRepo,
 		Transaction:     &mocks.MockTransactionRepository{},
 		TradingLog:      &mocks.MockTradingLogRepository{},
 		OAuthToken:      &mocks.MockOAuthTokenRepository{},
@@ -295,7 +452,7 @@ func TestTradingService_GetTrading(t *testing.T) {
 	}
 
 	// Create exchange binding service and trading service
-	exchangeBindingService := services.NewExchangeBindingService(repos.ExchangeBinding)
+	exchangeBindingService := services.NewExchangeBindingService(repos.ExchangeBinding, newTestExchangeBindingManager(t), exchange.NewFakeRegistry(exchange.NewFakeConnector()))
 	tradingService := services.NewTradingService(repos, exchangeBindingService)
 
 	// Create test data
@@ -307,9 +464,14 @@ func TestTradingService_GetTrading(t *testing.T) {
 
 	// Test successful retrieval
 	t.Run("successful_retrieval", func(t *testing.T) {
+		stockAccount := helpers.NewSubAccountFactory().WithUserAndTrading(userID, tradingID)
+		stockAccount.RealizedPnL = decimal.RequireFromString("42.5")
+
 		// Setup mock expectations
 		mockTradingRepo.On("GetByID", mock.Anything, tradingID).
 			Return(testTrading, nil).Once()
+		mockSubAccountRepo.On("GetByUserID", mock.Anything, userID, &tradingID).
+			Return([]*models.SubAccount{stockAccount}, nil).Once()
 
 		// Execute test
 		result, err := tradingService.GetTrading(context.Background(), userID, tradingID)
@@ -319,6 +481,7 @@ func TestTradingService_GetTrading(t *testing.T) {
 		require.NotNil(t, result)
 		assert.Equal(t, tradingID, result.ID)
 		assert.Equal(t, userID, result.UserID)
+		helpers.AssertDecimalEqual(t, decimal.RequireFromString("42.5"), result.RealizedPnL)
 
 		// Verify mock expectations
 		mockTradingRepo.AssertExpectations(t)
@@ -387,7 +550,7 @@ func TestTradingService_UpdateTrading(t *testing.T) {
 	}
 
 	// Create exchange binding service and trading service
-	exchangeBindingService := services.NewExchangeBindingService(repos.ExchangeBinding)
+	exchangeBindingService := services.NewExchangeBindingService(repos.ExchangeBinding, newTestExchangeBindingManager(t), exchange.NewFakeRegistry(exchange.NewFakeConnector()))
 	tradingService := services.NewTradingService(repos, exchangeBindingService)
 
 	// Create test data
@@ -527,7 +690,7 @@ func TestTradingService_UpdateTrading(t *testing.T) {
 			OAuthToken:      &mocks.MockOAuthTokenRepository{},
 			EventProcessing: &mocks.MockEventProcessingRepository{},
 		}
-		freshExchangeBindingService := services.NewExchangeBindingService(freshRepos.ExchangeBinding)
+		freshExchangeBindingService := services.NewExchangeBindingService(freshRepos.ExchangeBinding, newTestExchangeBindingManager(t), exchange.NewFakeRegistry(exchange.NewFakeConnector()))
 		freshTradingService := services.NewTradingService(freshRepos, freshExchangeBindingService)
 
 		conflictingBindingID := uuid.New()
@@ -579,7 +742,7 @@ func TestTradingService_UpdateTrading(t *testing.T) {
 			OAuthToken:      &mocks.MockOAuthTokenRepository{},
 			EventProcessing: &mocks.MockEventProcessingRepository{},
 		}
-		freshExchangeBindingService := services.NewExchangeBindingService(freshRepos.ExchangeBinding)
+		freshExchangeBindingService := services.NewExchangeBindingService(freshRepos.ExchangeBinding, newTestExchangeBindingManager(t), exchange.NewFakeRegistry(exchange.NewFakeConnector()))
 		freshTradingService := services.NewTradingService(freshRepos, freshExchangeBindingService)
 
 		conflictingBindingID := uuid.New()
@@ -636,7 +799,7 @@ func TestTradingService_DeleteTrading(t *testing.T) {
 	}
 
 	// Create exchange binding service and trading service
-	exchangeBindingService := services.NewExchangeBindingService(repos.ExchangeBinding)
+	exchangeBindingService := services.NewExchangeBindingService(repos.ExchangeBinding, newTestExchangeBindingManager(t), exchange.NewFakeRegistry(exchange.NewFakeConnector()))
 	tradingService := services.NewTradingService(repos, exchangeBindingService)
 
 	// Create test data
@@ -732,7 +895,7 @@ func TestTradingService_GetTradingByID(t *testing.T) {
 	}
 
 	// Create exchange binding service and trading service
-	exchangeBindingService := services.NewExchangeBindingService(repos.ExchangeBinding)
+	exchangeBindingService := services.NewExchangeBindingService(repos.ExchangeBinding, newTestExchangeBindingManager(t), exchange.NewFakeRegistry(exchange.NewFakeConnector()))
 	tradingService := services.NewTradingService(repos, exchangeBindingService)
 
 	// Create test data
@@ -780,6 +943,417 @@ func TestTradingService_GetTradingByID(t *testing.T) {
 	})
 }
 
+// TestTradingService_GetTradingBalanceAt tests trading-wide point-in-time balance queries
+func TestTradingService_GetTradingBalanceAt(t *testing.T) {
+	userID := uuid.New()
+	tradingID := uuid.New()
+	at := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+
+	mockTradingRepo := &mocks.MockTradingRepository{}
+	mockSubAccountRepo := &mocks.MockSubAccountRepository{}
+	mockTransactionRepo := &mocks.MockTransactionRepository{}
+	repos := &repositories.Repositories{
+		Trading:     mockTradingRepo,
+		SubAccount:  mockSubAccountRepo,
+		Transaction: mockTransactionRepo,
+	}
+	tradingService := services.NewTradingService(repos, nil)
+
+	t.Run("balances_of_all_sub_accounts", func(t *testing.T) {
+		stockAccount := &models.SubAccount{ID: uuid.New(), UserID: userID, TradingID: tradingID, Symbol: "ETH", CreatedAt: at.AddDate(0, -1, 0)}
+		currencyAccount := &models.SubAccount{ID: uuid.New(), UserID: userID, TradingID: tradingID, Symbol: "USDT", CreatedAt: at.AddDate(0, -1, 0)}
+		emptyAccount := &models.SubAccount{ID: uuid.New(), UserID: userID, TradingID: tradingID, Symbol: "BTC", CreatedAt: at.AddDate(0, -1, 0)}
+		laterAccount := &models.SubAccount{ID: uuid.New(), UserID: userID, TradingID: tradingID, Symbol: "SOL", CreatedAt: at.AddDate(0, 0, 1)}
+
+		stockTransaction := &models.Transaction{ID: uuid.New(), SubAccountID: stockAccount.ID, Timestamp: at.Add(-time.Hour), Direction: "credit", Amount: decimal.NewFromInt(2), ClosingBalance: decimal.NewFromInt(2)}
+		currencyTransaction := &models.Transaction{ID: uuid.New(), SubAccountID: currencyAccount.ID, Timestamp: at.Add(-time.Hour), Direction: "debit", Amount: decimal.NewFromInt(6012), ClosingBalance: decimal.NewFromInt(3988)}
+
+		mockTradingRepo.On("GetByID", mock.Anything, tradingID).Return(&models.Trading{ID: tradingID, UserID: userID}, nil).Once()
+		mockSubAccountRepo.On("GetByTradingID", mock.Anything, tradingID).
+			Return([]*models.SubAccount{stockAccount, currencyAccount, emptyAccount, laterAccount}, nil).Once()
+		mockTransactionRepo.On("GetLatestByTradingIDAt", mock.Anything, tradingID, at).
+			Return([]*models.Transaction{stockTransaction, currencyTransaction}, nil).Once()
+
+		result, err := tradingService.GetTradingBalanceAt(context.Background(), userID, tradingID, at)
+
+		require.NoError(t, err)
+		assert.Equal(t, tradingID, result.TradingID)
+		require.Len(t, result.SubAccounts, 3)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(2), result.SubAccounts[0].Balance)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(3988), result.SubAccounts[1].Balance)
+		helpers.AssertDecimalEqual(t, decimal.Zero, result.SubAccounts[2].Balance)
+		assert.Nil(t, result.SubAccounts[2].TransactionID)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("trading_wrong_user", func(t *testing.T) {
+		otherTradingID := uuid.New()
+		mockTradingRepo.On("GetByID", mock.Anything, otherTradingID).Return(&models.Trading{ID: otherTradingID, UserID: uuid.New()}, nil).Once()
+
+		result, err := tradingService.GetTradingBalanceAt(context.Background(), userID, otherTradingID, at)
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "trading not found", err.Error())
+	})
+}
+
+// TestTradingService_GetTradingValuation tests valuing a trading in a quote currency
+func TestTradingService_GetTradingValuation(t *testing.T) {
+	userID := uuid.New()
+	tradingID := uuid.New()
+	tradedAt := time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	mockTradingRepo := &mocks.MockTradingRepository{}
+	mockSubAccountRepo := &mocks.MockSubAccountRepository{}
+	mockTransactionRepo := &mocks.MockTransactionRepository{}
+	repos := &repositories.Repositories{
+		Trading:     mockTradingRepo,
+		SubAccount:  mockSubAccountRepo,
+		Transaction: mockTransactionRepo,
+	}
+	tradingService := services.NewTradingService(repos, nil)
+
+	subAccounts := []*models.SubAccount{
+		{ID: uuid.New(), UserID: userID, TradingID: tradingID, Symbol: "USDT", Balance: decimal.NewFromInt(3988)},
+		{ID: uuid.New(), UserID: userID, TradingID: tradingID, Symbol: "ETH", Balance: decimal.NewFromInt(2)},
+		{ID: uuid.New(), UserID: userID, TradingID: tradingID, Symbol: "ETH", Balance: helpers.Dec("0.5")},
+		{ID: uuid.New(), UserID: userID, TradingID: tradingID, Symbol: "BTC", Balance: helpers.Dec("0.1")},
+	}
+	ethPrice := &repositories.SymbolPrice{Symbol: "ETH", Price: decimal.NewFromInt(3000), Timestamp: tradedAt, TransactionID: uuid.New()}
+
+	t.Run("latest_trade_prices", func(t *testing.T) {
+		mockTradingRepo.On("GetByID", mock.Anything, tradingID).Return(&models.Trading{ID: tradingID, UserID: userID}, nil).Once()
+		mockSubAccountRepo.On("GetByTradingID", mock.Anything, tradingID).Return(subAccounts, nil).Once()
+		mockTransactionRepo.On("GetLatestPricesByTradingID", mock.Anything, tradingID, "USDT").
+			Return([]*repositories.SymbolPrice{ethPrice}, nil).Once()
+
+		result, err := tradingService.GetTradingValuation(context.Background(), userID, tradingID, "USDT", nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, "USDT", result.QuoteSymbol)
+		require.Len(t, result.Assets, 3)
+
+		btc, eth, usdt := result.Assets[0], result.Assets[1], result.Assets[2]
+		assert.Equal(t, "BTC", btc.Symbol)
+		assert.Nil(t, btc.Value)
+		assert.Equal(t, "ETH", eth.Symbol)
+		helpers.AssertDecimalEqual(t, helpers.Dec("2.5"), eth.Balance)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(7500), *eth.Value)
+		assert.Equal(t, services.PriceSourceTransaction, eth.PriceSource)
+		assert.Equal(t, &ethPrice.TransactionID, eth.TransactionID)
+		assert.Equal(t, services.PriceSourceQuote, usdt.PriceSource)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(3988), *usdt.Value)
+
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(11488), result.TotalEquity)
+		assert.Equal(t, []string{"BTC"}, result.UnpricedSymbols)
+		assert.Equal(t, tradedAt.Format(time.RFC3339), result.PricesAt)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("supplied_prices_take_precedence", func(t *testing.T) {
+		mockTradingRepo.On("GetByID", mock.Anything, tradingID).Return(&models.Trading{ID: tradingID, UserID: userID}, nil).Once()
+		mockSubAccountRepo.On("GetByTradingID", mock.Anything, tradingID).Return(subAccounts, nil).Once()
+		mockTransactionRepo.On("GetLatestPricesByTradingID", mock.Anything, tradingID, "USDT").
+			Return([]*repositories.SymbolPrice{ethPrice}, nil).Once()
+
+		prices := map[string]decimal.Decimal{"ETH": decimal.NewFromInt(3200), "BTC": decimal.NewFromInt(60000)}
+		result, err := tradingService.GetTradingValuation(context.Background(), userID, tradingID, "USDT", prices)
+
+		require.NoError(t, err)
+		require.Len(t, result.Assets, 3)
+		assert.Equal(t, services.PriceSourceSupplied, result.Assets[0].PriceSource)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(6000), *result.Assets[0].Value)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(8000), *result.Assets[1].Value)
+		assert.Nil(t, result.Assets[1].TransactionID)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(17988), result.TotalEquity)
+		assert.Empty(t, result.UnpricedSymbols)
+	})
+
+	t.Run("invalid_parameters", func(t *testing.T) {
+		_, err := tradingService.GetTradingValuation(context.Background(), userID, tradingID, "", nil)
+		require.Error(t, err)
+		assert.Equal(t, "quote currency is required", err.Error())
+
+		_, err = tradingService.GetTradingValuation(context.Background(), userID, tradingID, "USDT",
+			map[string]decimal.Decimal{"ETH": decimal.Zero})
+		require.Error(t, err)
+		assert.Equal(t, "price of ETH must be positive", err.Error())
+	})
+
+	t.Run("trading_wrong_user", func(t *testing.T) {
+		otherTradingID := uuid.New()
+		mockTradingRepo.On("GetByID", mock.Anything, otherTradingID).Return(&models.Trading{ID: otherTradingID, UserID: uuid.New()}, nil).Once()
+
+		result, err := tradingService.GetTradingValuation(context.Background(), userID, otherTradingID, "USDT", nil)
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "trading not found", err.Error())
+	})
+}
+
+// TestTradingService_GetEquityCurve tests equity curves built from bucketed balance changes
+func TestTradingService_GetEquityCurve(t *testing.T) {
+	userID := uuid.New()
+	tradingID := uuid.New()
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	from := day(6)
+	to := day(9).Add(12 * time.Hour)
+
+	mockTradingRepo := &mocks.MockTradingRepository{}
+	mockSubAccountRepo := &mocks.MockSubAccountRepository{}
+	mockTransactionRepo := &mocks.MockTransactionRepository{}
+	repos := &repositories.Repositories{
+		Trading:     mockTradingRepo,
+		SubAccount:  mockSubAccountRepo,
+		Transaction: mockTransactionRepo,
+	}
+	tradingService := services.NewTradingService(repos, nil)
+
+	currencyAccount := &models.SubAccount{ID: uuid.New(), UserID: userID, TradingID: tradingID, Name: "USDT", Symbol: "USDT"}
+	stockAccount := &models.SubAccount{ID: uuid.New(), UserID: userID, TradingID: tradingID, Name: "ETH", Symbol: "ETH"}
+	bucket := func(d int) *time.Time { b := day(d); return &b }
+	changes := []*repositories.BalanceChange{
+		{SubAccountID: currencyAccount.ID, Bucket: nil, Change: decimal.NewFromInt(10000)},
+		{SubAccountID: currencyAccount.ID, Bucket: bucket(7), Change: decimal.NewFromInt(-6012)},
+		{SubAccountID: stockAccount.ID, Bucket: bucket(7), Change: decimal.NewFromInt(2)},
+		{SubAccountID: stockAccount.ID, Bucket: bucket(9), Change: decimal.NewFromInt(-1)},
+	}
+
+	t.Run("balances_carried_forward_and_valued", func(t *testing.T) {
+		mockTradingRepo.On("GetByID", mock.Anything, tradingID).Return(&models.Trading{ID: tradingID, UserID: userID}, nil).Once()
+		mockSubAccountRepo.On("GetByTradingID", mock.Anything, tradingID).
+			Return([]*models.SubAccount{currencyAccount, stockAccount}, nil).Once()
+		mockTransactionRepo.On("GetBalanceChangesByTradingID", mock.Anything, tradingID, 24*time.Hour, from, to).
+			Return(changes, nil).Once()
+		mockTransactionRepo.On("GetBucketPricesByTradingID", mock.Anything, tradingID, "USDT", 24*time.Hour, from, to).
+			Return([]*repositories.BucketPrice{
+				{Symbol: "ETH", Bucket: bucket(8), Price: decimal.NewFromInt(3050)},
+				{Symbol: "ETH", Bucket: bucket(9), Price: decimal.NewFromInt(3100)},
+			}, nil).Once()
+
+		result, err := tradingService.GetEquityCurve(context.Background(), userID, tradingID,
+			&services.EquityCurveRequest{Interval: "1d", From: &from, To: &to, Quote: "USDT"})
+
+		require.NoError(t, err)
+		require.Len(t, result.SubAccounts, 2)
+		currencyCurve, stockCurve := result.SubAccounts[0], result.SubAccounts[1]
+		require.Len(t, currencyCurve.Points, 4)
+		assert.Equal(t, "2025-01-06T00:00:00Z", currencyCurve.Points[0].Time)
+		assert.Equal(t, "2025-01-09T00:00:00Z", currencyCurve.Points[3].Time)
+		for i, expected := range []int64{10000, 3988, 3988, 3988} {
+			helpers.AssertDecimalEqual(t, decimal.NewFromInt(expected), currencyCurve.Points[i].Balance)
+		}
+		for i, expected := range []int64{0, 2, 2, 1} {
+			helpers.AssertDecimalEqual(t, decimal.NewFromInt(expected), stockCurve.Points[i].Balance)
+		}
+
+		require.Len(t, result.Equity, 4)
+		for i, expected := range []int64{10000, 3988, 10088, 7088} {
+			helpers.AssertDecimalEqual(t, decimal.NewFromInt(expected), result.Equity[i].Equity)
+		}
+		assert.Equal(t, []string{"ETH"}, result.Equity[1].UnpricedSymbols)
+		assert.Empty(t, result.Equity[2].UnpricedSymbols)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("single_sub_account_without_quote", func(t *testing.T) {
+		mockTradingRepo.On("GetByID", mock.Anything, tradingID).Return(&models.Trading{ID: tradingID, UserID: userID}, nil).Once()
+		mockSubAccountRepo.On("GetByTradingID", mock.Anything, tradingID).
+			Return([]*models.SubAccount{currencyAccount, stockAccount}, nil).Once()
+		mockTransactionRepo.On("GetBalanceChangesByTradingID", mock.Anything, tradingID, time.Hour, day(9), to).
+			Return(changes, nil).Once()
+
+		from := day(9)
+		result, err := tradingService.GetEquityCurve(context.Background(), userID, tradingID,
+			&services.EquityCurveRequest{Interval: "1h", From: &from, To: &to, SubAccountID: &stockAccount.ID})
+
+		require.NoError(t, err)
+		assert.Empty(t, result.Equity)
+		require.Len(t, result.SubAccounts, 1)
+		assert.Equal(t, stockAccount.ID, result.SubAccounts[0].SubAccountID)
+		assert.Len(t, result.SubAccounts[0].Points, 13)
+	})
+
+	t.Run("invalid_parameters", func(t *testing.T) {
+		_, err := tradingService.GetEquityCurve(context.Background(), userID, tradingID, &services.EquityCurveRequest{Interval: "5m"})
+		require.Error(t, err)
+		assert.Equal(t, "invalid interval: must be one of 1h, 1d, 1w", err.Error())
+
+		_, err = tradingService.GetEquityCurve(context.Background(), userID, tradingID, &services.EquityCurveRequest{From: &to, To: &from})
+		require.Error(t, err)
+		assert.Equal(t, "from must be before to", err.Error())
+
+		early := from.AddDate(-1, 0, 0)
+		_, err = tradingService.GetEquityCurve(context.Background(), userID, tradingID, &services.EquityCurveRequest{Interval: "1h", From: &early, To: &to})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "too many points")
+	})
+}
+
+// TestTradingService_GetTradingPerformance tests performance statistics and their caching
+func TestTradingService_GetTradingPerformance(t *testing.T) {
+	userID := uuid.New()
+	tradingID := uuid.New()
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	bucket := func(d int) *time.Time { b := day(d); return &b }
+	from := day(6)
+	to := day(8).Add(12 * time.Hour)
+
+	mockTradingRepo := &mocks.MockTradingRepository{}
+	mockSubAccountRepo := &mocks.MockSubAccountRepository{}
+	mockTransactionRepo := &mocks.MockTransactionRepository{}
+	repos := &repositories.Repositories{
+		Trading:     mockTradingRepo,
+		SubAccount:  mockSubAccountRepo,
+		Transaction: mockTransactionRepo,
+	}
+	tradingService := services.NewTradingService(repos, nil)
+
+	currencyAccount := &models.SubAccount{ID: uuid.New(), UserID: userID, TradingID: tradingID, Symbol: "USDT"}
+	stockAccount := &models.SubAccount{ID: uuid.New(), UserID: userID, TradingID: tradingID, Symbol: "ETH"}
+	changes := []*repositories.BalanceChange{
+		{SubAccountID: currencyAccount.ID, Reason: "deposit", Change: decimal.NewFromInt(10000)},
+		{SubAccountID: currencyAccount.ID, Bucket: bucket(6), Reason: "long", Change: decimal.NewFromInt(-6000)},
+		{SubAccountID: stockAccount.ID, Bucket: bucket(6), Reason: "long", Change: decimal.NewFromInt(2)},
+		{SubAccountID: stockAccount.ID, Bucket: bucket(7), Reason: "short", Change: decimal.NewFromInt(-2)},
+		{SubAccountID: currencyAccount.ID, Bucket: bucket(7), Reason: "short", Change: decimal.NewFromInt(6400)},
+		{SubAccountID: currencyAccount.ID, Bucket: bucket(8), Reason: "deposit", Change: decimal.NewFromInt(1000)},
+	}
+	prices := []*repositories.BucketPrice{
+		{Symbol: "ETH", Bucket: bucket(6), Price: decimal.NewFromInt(3000)},
+		{Symbol: "ETH", Bucket: bucket(7), Price: decimal.NewFromInt(3200)},
+	}
+	win := decimal.NewFromInt(400)
+	loss := decimal.NewFromInt(-100)
+	transactions := []*models.Transaction{
+		{SubAccountID: stockAccount.ID, Timestamp: day(8), Reason: "short", Direction: "debit", ClosingBalance: decimal.Zero, RealizedPnL: &loss},
+		{SubAccountID: stockAccount.ID, Timestamp: day(7).Add(time.Hour), Reason: "long", Direction: "credit", ClosingBalance: decimal.NewFromInt(1)},
+		{SubAccountID: currencyAccount.ID, Timestamp: day(7), Reason: "short", Direction: "credit", ClosingBalance: decimal.NewFromInt(10400)},
+		{SubAccountID: stockAccount.ID, Timestamp: day(7), Reason: "short", Direction: "debit", ClosingBalance: decimal.Zero, RealizedPnL: &win},
+		{SubAccountID: currencyAccount.ID, Timestamp: day(6), Reason: "long", Direction: "debit", ClosingBalance: decimal.NewFromInt(4000)},
+		{SubAccountID: stockAccount.ID, Timestamp: day(6), Reason: "long", Direction: "credit", ClosingBalance: decimal.NewFromInt(2)},
+	}
+
+	t.Run("statistics_from_equity_and_round_trips", func(t *testing.T) {
+		req := &services.PerformanceRequest{From: &from, To: &to, Quote: "USDT"}
+		mockTradingRepo.On("GetByID", mock.Anything, tradingID).Return(&models.Trading{ID: tradingID, UserID: userID}, nil).Twice()
+		mockSubAccountRepo.On("GetByTradingID", mock.Anything, tradingID).
+			Return([]*models.SubAccount{currencyAccount, stockAccount}, nil).Once()
+		mockTransactionRepo.On("GetBalanceChangesByTradingID", mock.Anything, tradingID, 24*time.Hour, from, to).Return(changes, nil).Once()
+		mockTransactionRepo.On("GetBucketPricesByTradingID", mock.Anything, tradingID, "USDT", 24*time.Hour, from, to).Return(prices, nil).Once()
+		mockTransactionRepo.On("GetByTradingID", mock.Anything, tradingID, mock.Anything).Return(transactions, int64(len(transactions)), nil).Once()
+
+		result, err := tradingService.GetTradingPerformance(context.Background(), userID, tradingID, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "1d", result.Interval)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(10000), result.StartingEquity)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(11400), result.EndingEquity)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(1000), result.NetDeposits)
+		helpers.AssertDecimalEqual(t, helpers.Dec("0.04"), result.TotalReturn)
+		helpers.AssertDecimalEqual(t, decimal.Zero, result.MaxDrawdown)
+		require.NotNil(t, result.SharpeRatio)
+		assert.True(t, result.SharpeRatio.IsPositive())
+		assert.Nil(t, result.SortinoRatio)
+
+		assert.Equal(t, 2, result.RoundTrips)
+		assert.Equal(t, 1, result.WinningTrips)
+		assert.Equal(t, 1, result.LosingTrips)
+		helpers.AssertDecimalEqual(t, helpers.Dec("0.5"), *result.WinRate)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(400), *result.AverageWin)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(-100), *result.AverageLoss)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(4), *result.ProfitFactor)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(300), result.RealizedPnL)
+
+		// The second query is served from the cache without reading the ledger again
+		cached, err := tradingService.GetTradingPerformance(context.Background(), userID, tradingID, req)
+		require.NoError(t, err)
+		assert.Same(t, result, cached)
+		mockTransactionRepo.AssertExpectations(t)
+		mockTradingRepo.AssertExpectations(t)
+	})
+
+	t.Run("reversed_trades_and_flows", func(t *testing.T) {
+		reversedTradingID := uuid.New()
+		buyID, deletedSellID, sellID := uuid.New().String(), uuid.New().String(), uuid.New().String()
+		currencyAccount := &models.SubAccount{ID: uuid.New(), UserID: userID, TradingID: reversedTradingID, Symbol: "USDT"}
+		stockAccount := &models.SubAccount{ID: uuid.New(), UserID: userID, TradingID: reversedTradingID, Symbol: "ETH"}
+		// A sell and a deposit of day 7 are deleted, and a balance adjustment is posted on day 8
+		changes := []*repositories.BalanceChange{
+			{SubAccountID: currencyAccount.ID, Reason: "deposit", Change: decimal.NewFromInt(10000)},
+			{SubAccountID: currencyAccount.ID, Bucket: bucket(6), Reason: "long", Change: decimal.NewFromInt(-6000)},
+			{SubAccountID: stockAccount.ID, Bucket: bucket(6), Reason: "long", Change: decimal.NewFromInt(2)},
+			{SubAccountID: stockAccount.ID, Bucket: bucket(7), Reason: "short", Change: decimal.NewFromInt(-2)},
+			{SubAccountID: currencyAccount.ID, Bucket: bucket(7), Reason: "short", Change: decimal.NewFromInt(6400)},
+			{SubAccountID: stockAccount.ID, Bucket: bucket(7), Reason: "reversal", ReversedReason: "short", Change: decimal.NewFromInt(2)},
+			{SubAccountID: currencyAccount.ID, Bucket: bucket(7), Reason: "reversal", ReversedReason: "short", Change: decimal.NewFromInt(-6400)},
+			{SubAccountID: currencyAccount.ID, Bucket: bucket(7), Reason: "deposit", Change: decimal.NewFromInt(1000)},
+			{SubAccountID: currencyAccount.ID, Bucket: bucket(8), Reason: "reversal", ReversedReason: "deposit", Change: decimal.NewFromInt(-1000)},
+			{SubAccountID: currencyAccount.ID, Bucket: bucket(8), Reason: "adjustment", Change: decimal.NewFromInt(50)},
+			{SubAccountID: stockAccount.ID, Bucket: bucket(8), Reason: "short", Change: decimal.NewFromInt(-2)},
+			{SubAccountID: currencyAccount.ID, Bucket: bucket(8), Reason: "short", Change: decimal.NewFromInt(6200)},
+		}
+		prices := []*repositories.BucketPrice{
+			{Symbol: "ETH", Bucket: bucket(6), Price: decimal.NewFromInt(3000)},
+			{Symbol: "ETH", Bucket: bucket(7), Price: decimal.NewFromInt(3200)},
+			{Symbol: "ETH", Bucket: bucket(8), Price: decimal.NewFromInt(3100)},
+		}
+		deletedWin := decimal.NewFromInt(400)
+		win := decimal.NewFromInt(200)
+		transactions := []*models.Transaction{
+			{SubAccountID: stockAccount.ID, Timestamp: day(8), Reason: "short", Direction: "debit", ClosingBalance: decimal.Zero, RealizedPnL: &win,
+				Info: models.JSON{"id": sellID}},
+			{SubAccountID: stockAccount.ID, Timestamp: day(7).Add(time.Hour), Reason: "reversal", Direction: "credit", ClosingBalance: decimal.NewFromInt(2),
+				Info: models.JSON{"reversal_of": deletedSellID, "reversed_reason": "short"}},
+			{SubAccountID: stockAccount.ID, Timestamp: day(7), Reason: "short", Direction: "debit", ClosingBalance: decimal.Zero, RealizedPnL: &deletedWin,
+				Info: models.JSON{"id": deletedSellID}},
+			{SubAccountID: stockAccount.ID, Timestamp: day(6), Reason: "long", Direction: "credit", ClosingBalance: decimal.NewFromInt(2),
+				Info: models.JSON{"id": buyID}},
+		}
+
+		req := &services.PerformanceRequest{From: &from, To: &to, Quote: "USDT"}
+		mockTradingRepo.On("GetByID", mock.Anything, reversedTradingID).Return(&models.Trading{ID: reversedTradingID, UserID: userID}, nil).Once()
+		mockSubAccountRepo.On("GetByTradingID", mock.Anything, reversedTradingID).
+			Return([]*models.SubAccount{currencyAccount, stockAccount}, nil).Once()
+		mockTransactionRepo.On("GetBalanceChangesByTradingID", mock.Anything, reversedTradingID, 24*time.Hour, from, to).Return(changes, nil).Once()
+		mockTransactionRepo.On("GetBucketPricesByTradingID", mock.Anything, reversedTradingID, "USDT", 24*time.Hour, from, to).Return(prices, nil).Once()
+		mockTransactionRepo.On("GetByTradingID", mock.Anything, reversedTradingID, mock.Anything).Return(transactions, int64(len(transactions)), nil).Once()
+
+		result, err := tradingService.GetTradingPerformance(context.Background(), userID, reversedTradingID, req)
+
+		require.NoError(t, err)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(10250), result.EndingEquity)
+		// The deleted deposit and the adjustment are flows, not returns
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(50), result.NetDeposits)
+		assert.InDelta(t, 1.04*11200/11400-1, result.TotalReturn.InexactFloat64(), 1e-8)
+
+		// Only the sell that was not deleted closes a round trip
+		assert.Equal(t, 1, result.RoundTrips)
+		assert.Equal(t, 1, result.WinningTrips)
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(200), result.RealizedPnL)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("quote_required", func(t *testing.T) {
+		_, err := tradingService.GetTradingPerformance(context.Background(), userID, tradingID, &services.PerformanceRequest{})
+		require.Error(t, err)
+		assert.Equal(t, "quote currency is required", err.Error())
+	})
+
+	t.Run("trading_wrong_user", func(t *testing.T) {
+		otherTradingID := uuid.New()
+		mockTradingRepo.On("GetByID", mock.Anything, otherTradingID).Return(&models.Trading{ID: otherTradingID, UserID: uuid.New()}, nil).Once()
+
+		result, err := tradingService.GetTradingPerformance(context.Background(), userID, otherTradingID, &services.PerformanceRequest{Quote: "USDT"})
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "trading not found", err.Error())
+	})
+}
+
 // Performance test for trading operations
 func TestTradingService_Performance(t *testing.T) {
 	if testing.Short() {
@@ -789,13 +1363,14 @@ func TestTradingService_Performance(t *testing.T) {
 	// Create mocks
 	mockTradingRepo := &mocks.MockTradingRepository{}
 	mockExchangeBindingRepo := &mocks.MockExchangeBindingRepository{}
+	mockSubAccountRepo := &mocks.MockSubAccountRepository{}
 
 	// Create repositories with mocks
 	repos := &repositories.Repositories{
 		User:            &mocks.MockUserRepository{},
 		Trading:         mockTradingRepo,
 		ExchangeBinding: mockExchangeBindingRepo,
-		SubAccount:      &mocks.MockSubAccountRepository{},
+		SubAccount:      mockSubAccountRepo,
 		Transaction:     &mocks.MockTransactionRepository{},
 		TradingLog:      &mocks.MockTradingLogRepository{},
 		OAuthToken:      &mocks.MockOAuthTokenRepository{},
@@ -803,7 +1378,7 @@ func TestTradingService_Performance(t *testing.T) {
 	}
 
 	// Create exchange binding service and trading service
-	exchangeBindingService := services.NewExchangeBindingService(repos.ExchangeBinding)
+	exchangeBindingService := services.NewExchangeBindingService(repos.ExchangeBinding, newTestExchangeBindingManager(t), exchange.NewFakeRegistry(exchange.NewFakeConnector()))
 	tradingService := services.NewTradingService(repos, exchangeBindingService)
 
 	// Create test data
@@ -819,6 +1394,8 @@ func TestTradingService_Performance(t *testing.T) {
 		// Setup mock for multiple calls
 		mockTradingRepo.On("GetByUserID", mock.Anything, userID).
 			Return(testTradings, nil).Times(100)
+		mockSubAccountRepo.On("GetByUserID", mock.Anything, userID, (*uuid.UUID)(nil)).
+			Return([]*models.SubAccount{}, nil).Times(100)
 
 		timer := helpers.NewPerformanceTimer()
 		timer.Start()
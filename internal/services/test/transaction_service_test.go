@@ -13,6 +13,7 @@ import (
 	"tiris-backend/test/mocks"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -84,8 +85,8 @@ func TestTransactionService_GetUserTransactions(t *testing.T) {
 	t.Run("successful_retrieval_filtered", func(t *testing.T) {
 		direction := "credit"
 		reason := "deposit"
-		minAmount := 100.0
-		maxAmount := 1000.0
+		minAmount := decimal.NewFromInt(100)
+		maxAmount := decimal.NewFromInt(1000)
 		startDate := time.Now().Add(-24 * time.Hour)
 		endDate := time.Now()
 
@@ -157,8 +158,8 @@ func TestTransactionService_GetUserTransactions(t *testing.T) {
 
 	// Test invalid amount range
 	t.Run("invalid_amount_range", func(t *testing.T) {
-		minAmount := 1000.0
-		maxAmount := 100.0 // Max less than min
+		minAmount := decimal.NewFromInt(1000)
+		maxAmount := decimal.NewFromInt(100) // Max less than min
 
 		request := &services.TransactionQueryRequest{
 			MinAmount: &minAmount,
@@ -12,6 +12,7 @@ import (
 	"tiris-backend/test/mocks"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -256,9 +257,9 @@ func TestUserService_GetUserStats(t *testing.T) {
 		{ID: uuid.New(), UserID: userID},
 	}
 	testSubAccounts := []*models.SubAccount{
-		{ID: uuid.New(), UserID: userID, Balance: 1000.0},
-		{ID: uuid.New(), UserID: userID, Balance: 2000.0},
-		{ID: uuid.New(), UserID: userID, Balance: 3000.0},
+		{ID: uuid.New(), UserID: userID, Balance: decimal.NewFromInt(1000)},
+		{ID: uuid.New(), UserID: userID, Balance: decimal.NewFromInt(2000)},
+		{ID: uuid.New(), UserID: userID, Balance: decimal.NewFromInt(3000)},
 	}
 
 	// Test successful stats calculation
@@ -278,13 +279,36 @@ func TestUserService_GetUserStats(t *testing.T) {
 
 		assert.Equal(t, 2, stats["total_tradings"])
 		assert.Equal(t, 3, stats["total_subaccounts"])
-		assert.Equal(t, 6000.0, stats["total_balance"]) // 1000 + 2000 + 3000
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(6000), stats["total_balance"].(decimal.Decimal)) // 1000 + 2000 + 3000
 		assert.Equal(t, 2, stats["active_tradings"])
 
 		// Verify mock expectations
 		mockExchRepo.AssertExpectations(t)
 		mockSubRepo.AssertExpectations(t)
 	})
+
+	// Test that simulated tradings are left out of the total balance
+	t.Run("simulated_tradings_excluded_from_total_balance", func(t *testing.T) {
+		realTrading := &models.Trading{ID: uuid.New(), UserID: userID, Type: models.TradingTypeReal}
+		simulationTrading := &models.Trading{ID: uuid.New(), UserID: userID, Type: models.TradingTypeSimulation}
+		backtestTrading := &models.Trading{ID: uuid.New(), UserID: userID, Type: models.TradingTypeBacktest}
+
+		mockExchRepo.On("GetByUserID", mock.Anything, userID).
+			Return([]*models.Trading{realTrading, simulationTrading, backtestTrading}, nil).Once()
+		mockSubRepo.On("GetByUserID", mock.Anything, userID, mock.Anything).
+			Return([]*models.SubAccount{
+				{ID: uuid.New(), UserID: userID, TradingID: realTrading.ID, Balance: decimal.NewFromInt(1000)},
+				{ID: uuid.New(), UserID: userID, TradingID: simulationTrading.ID, Balance: decimal.NewFromInt(50000)},
+				{ID: uuid.New(), UserID: userID, TradingID: backtestTrading.ID, Balance: decimal.NewFromInt(70000)},
+			}, nil).Once()
+
+		stats, err := userService.GetUserStats(context.Background(), userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, stats["total_tradings"])
+		assert.Equal(t, 3, stats["total_subaccounts"])
+		helpers.AssertDecimalEqual(t, decimal.NewFromInt(1000), stats["total_balance"].(decimal.Decimal))
+	})
 }
 
 // TestUserService_DisableUser tests the DisableUser functionality
@@ -0,0 +1,269 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"tiris-backend/internal/models"
+	"tiris-backend/internal/repositories"
+	"tiris-backend/internal/services"
+	"tiris-backend/test/mocks"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVirtualExchangeService_PlaceOrder tests the PlaceOrder functionality
+func TestVirtualExchangeService_PlaceOrder(t *testing.T) {
+	mockTradingRepo := &mocks.MockTradingRepository{}
+	mockSubAccountRepo := &mocks.MockSubAccountRepository{}
+	mockOrderRepo := &mocks.MockOrderRepository{}
+	repos := &repositories.Repositories{
+		Trading:    mockTradingRepo,
+		SubAccount: mockSubAccountRepo,
+		Order:      mockOrderRepo,
+	}
+	// Placing orders does not open a transaction
+	virtualExchangeService := services.NewVirtualExchangeService(repos, nil)
+
+	userID := uuid.New()
+	trading := &models.Trading{ID: uuid.New(), UserID: userID, Type: models.TradingTypeSimulation}
+	ethAccount := &models.SubAccount{ID: uuid.New(), UserID: userID, TradingID: trading.ID, Symbol: "ETH"}
+	usdtAccount := &models.SubAccount{ID: uuid.New(), UserID: userID, TradingID: trading.ID, Symbol: "USDT"}
+	limit := decimal.NewFromInt(3000)
+
+	t.Run("successful_limit_order", func(t *testing.T) {
+		request := &services.PlaceVirtualOrderRequest{
+			StockAccountID:    ethAccount.ID,
+			CurrencyAccountID: usdtAccount.ID,
+			Side:              "buy",
+			Type:              "limit",
+			Amount:            decimal.RequireFromString("0.5"),
+			Price:             &limit,
+			ClientOrderID:     "bot-42-0001",
+		}
+
+		mockTradingRepo.On("GetByID", mock.Anything, trading.ID).Return(trading, nil).Once()
+		mockSubAccountRepo.On("GetByID", mock.Anything, ethAccount.ID).Return(ethAccount, nil).Once()
+		mockSubAccountRepo.On("GetByID", mock.Anything, usdtAccount.ID).Return(usdtAccount, nil).Once()
+		mockOrderRepo.On("GetByTradingIDAndOrderID", mock.Anything, trading.ID, "bot-42-0001").Return(nil, nil).Once()
+		mockOrderRepo.On("Create", mock.Anything, mock.MatchedBy(func(order *models.Order) bool {
+			return order.SubAccountID == ethAccount.ID && order.Status == models.OrderStatusOpen &&
+				order.Info["currency_account_id"] == usdtAccount.ID.String()
+		})).Return(nil).Once()
+
+		result, err := virtualExchangeService.PlaceOrder(context.Background(), userID, trading.ID, request)
+
+		require.NoError(t, err)
+		assert.Equal(t, "bot-42-0001", result.OrderID)
+		assert.Equal(t, "ETH/USDT", result.Symbol)
+		assert.Equal(t, models.OrderStatusOpen, result.Status)
+		assert.Equal(t, models.ExchangeVirtual, result.Info["exchange"])
+		mockTradingRepo.AssertExpectations(t)
+		mockSubAccountRepo.AssertExpectations(t)
+		mockOrderRepo.AssertExpectations(t)
+	})
+
+	t.Run("duplicate_client_order_id", func(t *testing.T) {
+		request := &services.PlaceVirtualOrderRequest{
+			StockAccountID:    ethAccount.ID,
+			CurrencyAccountID: usdtAccount.ID,
+			Side:              "sell",
+			Type:              "market",
+			Amount:            decimal.NewFromInt(1),
+			ClientOrderID:     "bot-42-0001",
+		}
+
+		mockTradingRepo.On("GetByID", mock.Anything, trading.ID).Return(trading, nil).Once()
+		mockSubAccountRepo.On("GetByID", mock.Anything, ethAccount.ID).Return(ethAccount, nil).Once()
+		mockSubAccountRepo.On("GetByID", mock.Anything, usdtAccount.ID).Return(usdtAccount, nil).Once()
+		mockOrderRepo.On("GetByTradingIDAndOrderID", mock.Anything, trading.ID, "bot-42-0001").
+			Return(&models.Order{ID: uuid.New(), OrderID: "bot-42-0001"}, nil).Once()
+
+		result, err := virtualExchangeService.PlaceOrder(context.Background(), userID, trading.ID, request)
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "order already exists", err.Error())
+		mockOrderRepo.AssertExpectations(t)
+	})
+
+	t.Run("real_trading", func(t *testing.T) {
+		realTrading := &models.Trading{ID: uuid.New(), UserID: userID, Type: models.TradingTypeReal}
+		mockTradingRepo.On("GetByID", mock.Anything, realTrading.ID).Return(realTrading, nil).Once()
+
+		result, err := virtualExchangeService.PlaceOrder(context.Background(), userID, realTrading.ID, &services.PlaceVirtualOrderRequest{})
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "virtual exchange requires a simulation or backtest trading", err.Error())
+	})
+
+	t.Run("limit_order_without_price", func(t *testing.T) {
+		request := &services.PlaceVirtualOrderRequest{
+			StockAccountID:    ethAccount.ID,
+			CurrencyAccountID: usdtAccount.ID,
+			Side:              "buy",
+			Type:              "limit",
+			Amount:            decimal.NewFromInt(1),
+		}
+		mockTradingRepo.On("GetByID", mock.Anything, trading.ID).Return(trading, nil).Once()
+
+		result, err := virtualExchangeService.PlaceOrder(context.Background(), userID, trading.ID, request)
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "limit orders require a positive price", err.Error())
+	})
+
+	t.Run("account_of_another_trading", func(t *testing.T) {
+		otherAccount := &models.SubAccount{ID: uuid.New(), UserID: userID, TradingID: uuid.New(), Symbol: "USDT"}
+		request := &services.PlaceVirtualOrderRequest{
+			StockAccountID:    ethAccount.ID,
+			CurrencyAccountID: otherAccount.ID,
+			Side:              "buy",
+			Type:              "market",
+			Amount:            decimal.NewFromInt(1),
+		}
+
+		mockTradingRepo.On("GetByID", mock.Anything, trading.ID).Return(trading, nil).Once()
+		mockSubAccountRepo.On("GetByID", mock.Anything, ethAccount.ID).Return(ethAccount, nil).Once()
+		mockSubAccountRepo.On("GetByID", mock.Anything, otherAccount.ID).Return(otherAccount, nil).Once()
+
+		result, err := virtualExchangeService.PlaceOrder(context.Background(), userID, trading.ID, request)
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "currency account not found", err.Error())
+		mockSubAccountRepo.AssertExpectations(t)
+	})
+}
+
+// TestVirtualExchangeService_CancelOrder tests the CancelOrder functionality
+func TestVirtualExchangeService_CancelOrder(t *testing.T) {
+	mockTradingRepo := &mocks.MockTradingRepository{}
+	mockOrderRepo := &mocks.MockOrderRepository{}
+	repos := &repositories.Repositories{
+		Trading: mockTradingRepo,
+		Order:   mockOrderRepo,
+	}
+	virtualExchangeService := services.NewVirtualExchangeService(repos, nil)
+
+	userID := uuid.New()
+	trading := &models.Trading{ID: uuid.New(), UserID: userID, Type: models.TradingTypeBacktest}
+
+	t.Run("successful_cancellation", func(t *testing.T) {
+		order := &models.Order{ID: uuid.New(), TradingID: trading.ID, OrderID: "bot-42-0002", Status: models.OrderStatusPartiallyFilled}
+
+		mockTradingRepo.On("GetByID", mock.Anything, trading.ID).Return(trading, nil).Once()
+		mockOrderRepo.On("GetByTradingIDAndOrderID", mock.Anything, trading.ID, "bot-42-0002").Return(order, nil).Once()
+		cancelled := *order
+		cancelled.Status = models.OrderStatusCancelled
+		mockOrderRepo.On("Cancel", mock.Anything, order.ID).Return(&cancelled, nil).Once()
+
+		result, err := virtualExchangeService.CancelOrder(context.Background(), userID, trading.ID, "bot-42-0002")
+
+		require.NoError(t, err)
+		assert.Equal(t, models.OrderStatusCancelled, result.Status)
+		mockOrderRepo.AssertExpectations(t)
+	})
+
+	t.Run("filled_order", func(t *testing.T) {
+		order := &models.Order{ID: uuid.New(), TradingID: trading.ID, OrderID: "bot-42-0003", Status: models.OrderStatusFilled}
+
+		mockTradingRepo.On("GetByID", mock.Anything, trading.ID).Return(trading, nil).Once()
+		mockOrderRepo.On("GetByTradingIDAndOrderID", mock.Anything, trading.ID, "bot-42-0003").Return(order, nil).Once()
+
+		result, err := virtualExchangeService.CancelOrder(context.Background(), userID, trading.ID, "bot-42-0003")
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "order is filled and cannot be cancelled", err.Error())
+		mockOrderRepo.AssertExpectations(t)
+	})
+
+	t.Run("filled_by_concurrent_tick", func(t *testing.T) {
+		order := &models.Order{ID: uuid.New(), TradingID: trading.ID, OrderID: "bot-42-0004", Status: models.OrderStatusOpen}
+		filled := *order
+		filled.Status = models.OrderStatusFilled
+
+		// A price tick fills the order between the read and the cancellation
+		mockTradingRepo.On("GetByID", mock.Anything, trading.ID).Return(trading, nil).Once()
+		mockOrderRepo.On("GetByTradingIDAndOrderID", mock.Anything, trading.ID, "bot-42-0004").Return(order, nil).Once()
+		mockOrderRepo.On("Cancel", mock.Anything, order.ID).Return(nil, nil).Once()
+		mockOrderRepo.On("GetByTradingIDAndOrderID", mock.Anything, trading.ID, "bot-42-0004").Return(&filled, nil).Once()
+
+		result, err := virtualExchangeService.CancelOrder(context.Background(), userID, trading.ID, "bot-42-0004")
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "order is filled and cannot be cancelled", err.Error())
+		mockOrderRepo.AssertExpectations(t)
+	})
+}
+
+// TestVirtualExchangeService_PushPrice tests the validation of price ticks
+func TestVirtualExchangeService_PushPrice(t *testing.T) {
+	mockTradingRepo := &mocks.MockTradingRepository{}
+	repos := &repositories.Repositories{
+		Trading: mockTradingRepo,
+		Order:   &mocks.MockOrderRepository{},
+	}
+	// Rejected ticks return before the matching transaction
+	virtualExchangeService := services.NewVirtualExchangeService(repos, nil)
+
+	userID := uuid.New()
+
+	t.Run("real_trading", func(t *testing.T) {
+		trading := &models.Trading{ID: uuid.New(), UserID: userID, Type: models.TradingTypeReal}
+		mockTradingRepo.On("GetByID", mock.Anything, trading.ID).Return(trading, nil).Once()
+
+		result, err := virtualExchangeService.PushPrice(context.Background(), userID, trading.ID,
+			&services.PriceTickRequest{Symbol: "ETH/USDT", Price: decimal.NewFromInt(3000)})
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "virtual exchange requires a simulation or backtest trading", err.Error())
+	})
+
+	t.Run("trading_of_another_user", func(t *testing.T) {
+		trading := &models.Trading{ID: uuid.New(), UserID: uuid.New(), Type: models.TradingTypeSimulation}
+		mockTradingRepo.On("GetByID", mock.Anything, trading.ID).Return(trading, nil).Once()
+
+		result, err := virtualExchangeService.PushPrice(context.Background(), userID, trading.ID,
+			&services.PriceTickRequest{Symbol: "ETH/USDT", Price: decimal.NewFromInt(3000)})
+
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "trading not found", err.Error())
+	})
+
+	t.Run("invalid_tick", func(t *testing.T) {
+		trading := &models.Trading{ID: uuid.New(), UserID: userID, Type: models.TradingTypeSimulation}
+		negative := decimal.NewFromInt(-1)
+		tests := []struct {
+			name    string
+			request *services.PriceTickRequest
+			err     string
+		}{
+			{"zero_price", &services.PriceTickRequest{Symbol: "ETH/USDT"}, "price must be positive"},
+			{"price_precision", &services.PriceTickRequest{Symbol: "ETH/USDT", Price: decimal.RequireFromString("0.000000001")}, "price must have at most 8 decimal places"},
+			{"negative_volume", &services.PriceTickRequest{Symbol: "ETH/USDT", Price: decimal.NewFromInt(3000), Volume: &negative}, "volume cannot be negative"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockTradingRepo.On("GetByID", mock.Anything, trading.ID).Return(trading, nil).Once()
+
+				result, err := virtualExchangeService.PushPrice(context.Background(), userID, trading.ID, tt.request)
+
+				require.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, tt.err, err.Error())
+			})
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"tiris-backend/internal/models"
+	"tiris-backend/internal/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AmendTradingLogRequest represents a correction of a trading log
+// @Description Corrected info of a trading log. The type, trading, sub-account and source of the original log are kept.
+type AmendTradingLogRequest struct {
+	Info      map[string]interface{} `json:"info" binding:"required" description:"Corrected type-specific info, with the same structure as when creating a trading log of the original type"`
+	Message   string                 `json:"message,omitempty" example:"ETH long position opened (corrected price)" description:"Message of the correcting trading log. Defaults to the message of the original log"`
+	EventTime *time.Time             `json:"event_time,omitempty" example:"2024-01-15T10:30:00Z" description:"Event time of the correcting trading log. Defaults to the event time of the original log"`
+}
+
+// AmendTradingLog corrects a trading log without deleting it. For business logic types the
+// transactions of the original log are compensated with reversal transactions, then the corrected
+// info is processed as a new trading log, all in one database transaction. The two logs are linked
+// through amends and amended_by so the history stays auditable. Bot logs can be amended as well.
+func (s *TradingLogService) AmendTradingLog(ctx context.Context, userID, tradingLogID uuid.UUID, req *AmendTradingLogRequest) (*TradingLogResponse, error) {
+	var result *ProcessingResult
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// All reads and writes of the amendment go through its transaction
+		processor := s.processor.withRepositories(repositories.NewRepositories(tx))
+
+		// Lock the original so it cannot be amended twice concurrently
+		var original models.TradingLog
+		err := tx.WithContext(ctx).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", tradingLogID).
+			First(&original).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("trading log not found")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get trading log: %w", err)
+		}
+		if original.UserID != userID {
+			return fmt.Errorf("trading log not found")
+		}
+		if original.AmendedBy != nil {
+			return fmt.Errorf("trading log already amended")
+		}
+
+		var reversal *ProcessingResult
+		var placedAt *time.Time
+		if processor.validator.isBusinessLogicType(original.Type) {
+			if placedAt, err = transactionsPlacedAt(ctx, tx, &original); err != nil {
+				return err
+			}
+			if reversal, err = processor.reverseTransactions(ctx, tx, &original); err != nil {
+				return fmt.Errorf("failed to reverse trading log: %w", err)
+			}
+		}
+
+		corrected := &CreateTradingLogRequest{
+			TradingID:     original.TradingID,
+			SubAccountID:  original.SubAccountID,
+			TransactionID: original.TransactionID,
+			EventTime:     original.EventTime,
+			Type:          original.Type,
+			Source:        original.Source,
+			Message:       original.Message,
+			Info:          req.Info,
+		}
+		if req.EventTime != nil {
+			corrected.EventTime = req.EventTime
+		}
+		if req.Message != "" {
+			corrected.Message = req.Message
+		}
+
+		// The reversal of a backdated original takes effect at its event time, so a replay from there
+		// never counts the original and its correction together. A correction placed at the same
+		// time is replayed with the reversal, the reversal first.
+		if reversal != nil && len(reversal.CreatedTransactions) > 0 && backdatedTo(&original, placedAt) {
+			if corrected.EventTime != nil && corrected.EventTime.Equal(*placedAt) {
+				corrected.backdateWith = reversal.CreatedTransactions
+			} else if err := processor.backdateTransactions(ctx, tx, reversal.CreatedTransactions, *placedAt); err != nil {
+				return err
+			}
+		}
+
+		result, err = processor.ProcessTradingLog(ctx, tx, userID, corrected)
+		if err != nil {
+			return err
+		}
+
+		// Link the original and its correction
+		correction := result.TradingLogRecord
+		if err := tx.WithContext(ctx).Model(&models.TradingLog{}).Where("id = ?", correction.ID).Update("amends", original.ID).Error; err != nil {
+			return fmt.Errorf("failed to link amended trading log: %w", err)
+		}
+		if err := tx.WithContext(ctx).Model(&models.TradingLog{}).Where("id = ?", original.ID).Update("amended_by", correction.ID).Error; err != nil {
+			return fmt.Errorf("failed to link amended trading log: %w", err)
+		}
+		correction.Amends = &original.ID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.convertProcessingResult(result), nil
+}
+
+// backdatedTo reports whether the transactions of a trading log, placed at placedAt, were moved to its event time
+func backdatedTo(tradingLog *models.TradingLog, placedAt *time.Time) bool {
+	return placedAt != nil && tradingLog.EventTime != nil && placedAt.Equal(*tradingLog.EventTime) &&
+		tradingLog.EventTime.Before(tradingLog.Timestamp)
+}
+
+// transactionsPlacedAt returns the time of the earliest transaction of a trading log in the ledger, its
+// event time when it was backdated, or nil when the log has no transactions
+func transactionsPlacedAt(ctx context.Context, tx *gorm.DB, tradingLog *models.TradingLog) (*time.Time, error) {
+	var placedAt sql.NullTime
+	err := tx.WithContext(ctx).Model(&models.Transaction{}).
+		Select("MIN(timestamp)").
+		Where("trading_id = ? AND info->>'id' = ?", tradingLog.TradingID, tradingLog.ID.String()).
+		Row().Scan(&placedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trading log transactions: %w", err)
+	}
+	if !placedAt.Valid {
+		return nil, nil
+	}
+	return &placedAt.Time, nil
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tiris-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// backdatingEntry describes the new closing balance of a transaction of a backdated trade or of a
+// transaction posted after it
+type backdatingEntry struct {
+	Transaction    *models.Transaction
+	ClosingBalance decimal.Decimal
+}
+
+// backdateTransactions places the transactions created for a trading log at its past event time and
+// recomputes the closing balances of the later transactions of the affected sub-accounts. The
+// sub-account balances already include the trade and are not changed. A trade that would have taken
+// a balance below zero, or below minus the borrowed amount of a margin account, at any point since its
+// event time is refused. It must be called within a database transaction.
+func (p *TradingLogProcessor) backdateTransactions(ctx context.Context, tx *gorm.DB, created []*models.Transaction, eventTime time.Time) error {
+	createdIDs := make([]uuid.UUID, 0, len(created))
+	bySubAccount := make(map[uuid.UUID][]*models.Transaction)
+	var accountOrder []uuid.UUID
+	for _, transaction := range created {
+		createdIDs = append(createdIDs, transaction.ID)
+		if _, exists := bySubAccount[transaction.SubAccountID]; !exists {
+			accountOrder = append(accountOrder, transaction.SubAccountID)
+		}
+		bySubAccount[transaction.SubAccountID] = append(bySubAccount[transaction.SubAccountID], transaction)
+	}
+
+	for _, subAccountID := range accountOrder {
+		// A margin account may go down to minus what it currently borrows
+		var account models.SubAccount
+		if err := tx.WithContext(ctx).Select("borrowed").Where("id = ?", subAccountID).First(&account).Error; err != nil {
+			return fmt.Errorf("failed to get sub-account %s: %w", subAccountID, err)
+		}
+
+		// The balance at the event time is the closing balance of the last transaction before it
+		var previous []*models.Transaction
+		err := tx.WithContext(ctx).
+			Where("sub_account_id = ? AND id NOT IN ?", subAccountID, createdIDs).
+			Where("timestamp = (?)", tx.Model(&models.Transaction{}).
+				Select("MAX(timestamp)").
+				Where("sub_account_id = ? AND timestamp <= ? AND id NOT IN ?", subAccountID, eventTime, createdIDs)).
+			Order("id").
+			Find(&previous).Error
+		if err != nil {
+			return fmt.Errorf("failed to get transactions before event time: %w", err)
+		}
+		opening := decimal.Zero
+		if last := lastInChain(previous); last != nil {
+			opening = last.ClosingBalance
+		}
+
+		var later []*models.Transaction
+		err = tx.WithContext(ctx).
+			Where("sub_account_id = ? AND timestamp > ? AND id NOT IN ?", subAccountID, eventTime, createdIDs).
+			Order("timestamp ASC, id ASC").
+			Find(&later).Error
+		if err != nil {
+			return fmt.Errorf("failed to get transactions after event time: %w", err)
+		}
+
+		entries, err := planBackdating(subAccountID, opening, account.Borrowed, bySubAccount[subAccountID], orderChain(opening, later), eventTime)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries[:len(bySubAccount[subAccountID])] {
+			if err := moveTransaction(ctx, tx, entry.Transaction, eventTime, entry.ClosingBalance); err != nil {
+				return err
+			}
+		}
+		for _, entry := range entries[len(bySubAccount[subAccountID]):] {
+			err := tx.WithContext(ctx).Model(&models.Transaction{}).
+				Where("id = ? AND timestamp = ?", entry.Transaction.ID, entry.Transaction.Timestamp).
+				Update("closing_balance", entry.ClosingBalance).Error
+			if err != nil {
+				return fmt.Errorf("failed to update closing balance of transaction %s: %w", entry.Transaction.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// moveTransaction places a transaction at a new timestamp with a new closing balance. The timestamp
+// is part of the primary key and the time partition of the transactions table, so the transaction is
+// removed and inserted again under its original ID rather than updated.
+func moveTransaction(ctx context.Context, tx *gorm.DB, transaction *models.Transaction, timestamp time.Time, closingBalance decimal.Decimal) error {
+	var stored models.Transaction
+	if err := tx.WithContext(ctx).Where("id = ?", transaction.ID).First(&stored).Error; err != nil {
+		return fmt.Errorf("failed to get transaction %s: %w", transaction.ID, err)
+	}
+
+	if err := tx.WithContext(ctx).Where("id = ? AND timestamp = ?", stored.ID, stored.Timestamp).Delete(&models.Transaction{}).Error; err != nil {
+		return fmt.Errorf("failed to move transaction %s: %w", transaction.ID, err)
+	}
+	stored.Timestamp = timestamp
+	stored.ClosingBalance = closingBalance
+	if err := tx.WithContext(ctx).Omit(clause.Associations).Create(&stored).Error; err != nil {
+		return fmt.Errorf("failed to move transaction %s: %w", transaction.ID, err)
+	}
+
+	transaction.Timestamp = timestamp
+	transaction.ClosingBalance = closingBalance
+	return nil
+}
+
+// planBackdating computes the closing balances of the backdated transactions of a sub-account, placed
+// after the opening balance at the event time, and of the later transactions, in chain order. Only
+// later transactions whose closing balance changes are returned after the backdated ones. A balance
+// that would fall below minus the borrowed amount, zero outside margin, is refused.
+func planBackdating(subAccountID uuid.UUID, opening, borrowed decimal.Decimal, backdated, later []*models.Transaction, eventTime time.Time) ([]backdatingEntry, error) {
+	balance := opening
+	entries := make([]backdatingEntry, 0, len(backdated)+len(later))
+
+	for _, transaction := range backdated {
+		balance = balance.Add(signedAmount(transaction))
+		if err := checkBackdatedBalance(subAccountID, balance, borrowed, eventTime); err != nil {
+			return nil, err
+		}
+		entries = append(entries, backdatingEntry{Transaction: transaction, ClosingBalance: balance})
+	}
+
+	for _, transaction := range later {
+		balance = balance.Add(signedAmount(transaction))
+		if err := checkBackdatedBalance(subAccountID, balance, borrowed, transaction.Timestamp); err != nil {
+			return nil, err
+		}
+		if !balance.Equal(transaction.ClosingBalance) {
+			entries = append(entries, backdatingEntry{Transaction: transaction, ClosingBalance: balance})
+		}
+	}
+
+	return entries, nil
+}
+
+// checkBackdatedBalance refuses a replayed balance below minus the borrowed amount of the sub-account
+func checkBackdatedBalance(subAccountID uuid.UUID, balance, borrowed decimal.Decimal, at time.Time) error {
+	if !balance.Add(borrowed).IsNegative() {
+		return nil
+	}
+	if borrowed.IsZero() {
+		return fmt.Errorf("backdated trading log would make the balance of sub-account %s negative at %s: %s",
+			subAccountID, at.Format(time.RFC3339), balance.StringFixed(MaxDecimalPlaces))
+	}
+	return fmt.Errorf("backdated trading log would make the balance of sub-account %s fall below minus its borrowed %s at %s: %s",
+		subAccountID, borrowed.StringFixed(MaxDecimalPlaces), at.Format(time.RFC3339), balance.StringFixed(MaxDecimalPlaces))
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"tiris-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanBackdating(t *testing.T) {
+	accountID := uuid.New()
+	eventTime := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	t.Run("recompute_later_closing_balances", func(t *testing.T) {
+		// 1000 USDT at the event time, then a deposit of 500 and a withdraw of 200
+		later := []*models.Transaction{
+			{ID: uuid.New(), SubAccountID: accountID, Timestamp: eventTime.Add(time.Hour), Direction: "credit", Amount: decimal.NewFromInt(500), ClosingBalance: decimal.NewFromInt(1500)},
+			{ID: uuid.New(), SubAccountID: accountID, Timestamp: eventTime.Add(2 * time.Hour), Direction: "debit", Amount: decimal.NewFromInt(200), ClosingBalance: decimal.NewFromInt(1300)},
+		}
+		// A buy paying 300 entered late
+		backdated := []*models.Transaction{
+			{ID: uuid.New(), SubAccountID: accountID, Direction: "debit", Amount: decimal.NewFromInt(300), ClosingBalance: decimal.NewFromInt(1000)},
+		}
+
+		entries, err := planBackdating(accountID, decimal.NewFromInt(1000), decimal.Zero, backdated, later, eventTime)
+
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		assert.Equal(t, backdated[0].ID, entries[0].Transaction.ID)
+		assert.True(t, entries[0].ClosingBalance.Equal(decimal.NewFromInt(700)))
+		assert.Equal(t, later[0].ID, entries[1].Transaction.ID)
+		assert.True(t, entries[1].ClosingBalance.Equal(decimal.NewFromInt(1200)))
+		assert.Equal(t, later[1].ID, entries[2].Transaction.ID)
+		assert.True(t, entries[2].ClosingBalance.Equal(decimal.NewFromInt(1000)))
+	})
+
+	t.Run("no_later_transactions", func(t *testing.T) {
+		backdated := []*models.Transaction{
+			{ID: uuid.New(), SubAccountID: accountID, Direction: "credit", Amount: decimal.NewFromInt(2), ClosingBalance: decimal.NewFromInt(2)},
+		}
+
+		entries, err := planBackdating(accountID, decimal.Zero, decimal.Zero, backdated, nil, eventTime)
+
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.True(t, entries[0].ClosingBalance.Equal(decimal.NewFromInt(2)))
+	})
+
+	t.Run("refuse_negative_balance_at_event_time", func(t *testing.T) {
+		// Only 100 USDT were available at the event time, although 1000 are available now
+		backdated := []*models.Transaction{
+			{ID: uuid.New(), SubAccountID: accountID, Direction: "debit", Amount: decimal.NewFromInt(300), ClosingBalance: decimal.NewFromInt(700)},
+		}
+
+		entries, err := planBackdating(accountID, decimal.NewFromInt(100), decimal.Zero, backdated, nil, eventTime)
+
+		require.Error(t, err)
+		assert.Nil(t, entries)
+		assert.Contains(t, err.Error(), "backdated trading log would make the balance of sub-account")
+		assert.Contains(t, err.Error(), "negative at 2024-01-10T12:00:00Z: -200.00000000")
+	})
+
+	t.Run("refuse_negative_later_balance", func(t *testing.T) {
+		// 200 of the 300 USDT held at the event time were withdrawn later; paying 300 at the event
+		// time leaves nothing for that withdraw
+		later := []*models.Transaction{
+			{ID: uuid.New(), SubAccountID: accountID, Timestamp: eventTime.Add(time.Hour), Direction: "debit", Amount: decimal.NewFromInt(200), ClosingBalance: decimal.NewFromInt(100)},
+		}
+		backdated := []*models.Transaction{
+			{ID: uuid.New(), SubAccountID: accountID, Direction: "debit", Amount: decimal.NewFromInt(300), ClosingBalance: decimal.Zero},
+		}
+
+		_, err := planBackdating(accountID, decimal.NewFromInt(300), decimal.Zero, backdated, later, eventTime)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "negative at 2024-01-10T13:00:00Z: -200.00000000")
+	})
+
+	t.Run("margin_account_may_go_below_zero", func(t *testing.T) {
+		// A short sale of 2 ETH with 3 ETH borrowed, entered after the fill
+		backdated := []*models.Transaction{
+			{ID: uuid.New(), SubAccountID: accountID, Direction: "debit", Reason: "short", Amount: decimal.NewFromInt(2), ClosingBalance: decimal.NewFromInt(-2)},
+		}
+
+		entries, err := planBackdating(accountID, decimal.Zero, decimal.NewFromInt(3), backdated, nil, eventTime)
+
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.True(t, entries[0].ClosingBalance.Equal(decimal.NewFromInt(-2)))
+	})
+
+	t.Run("refuse_balance_below_borrowed", func(t *testing.T) {
+		backdated := []*models.Transaction{
+			{ID: uuid.New(), SubAccountID: accountID, Direction: "debit", Reason: "short", Amount: decimal.NewFromInt(4), ClosingBalance: decimal.NewFromInt(-4)},
+		}
+
+		_, err := planBackdating(accountID, decimal.Zero, decimal.NewFromInt(3), backdated, nil, eventTime)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "backdated trading log would make the balance of sub-account")
+		assert.Contains(t, err.Error(), "fall below minus its borrowed 3.00000000 at 2024-01-10T12:00:00Z: -4.00000000")
+	})
+
+	t.Run("skip_unchanged_later_transactions", func(t *testing.T) {
+		// A backdated transfer in and out of the account leaves the later balances as they are
+		later := []*models.Transaction{
+			{ID: uuid.New(), SubAccountID: accountID, Timestamp: eventTime.Add(time.Hour), Direction: "credit", Amount: decimal.NewFromInt(50), ClosingBalance: decimal.NewFromInt(150)},
+		}
+		backdated := []*models.Transaction{
+			{ID: uuid.New(), SubAccountID: accountID, Direction: "credit", Amount: decimal.NewFromInt(10), ClosingBalance: decimal.NewFromInt(160)},
+			{ID: uuid.New(), SubAccountID: accountID, Direction: "debit", Amount: decimal.NewFromInt(10), ClosingBalance: decimal.NewFromInt(150)},
+		}
+
+		entries, err := planBackdating(accountID, decimal.NewFromInt(100), decimal.Zero, backdated, later, eventTime)
+
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.True(t, entries[0].ClosingBalance.Equal(decimal.NewFromInt(110)))
+		assert.True(t, entries[1].ClosingBalance.Equal(decimal.NewFromInt(100)))
+	})
+}
+
+func TestPlacedAtEventTime(t *testing.T) {
+	timestamp := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	past := timestamp.Add(-time.Hour)
+	future := timestamp.Add(time.Minute)
+
+	assert.True(t, placedAtEventTime(&CreateTradingLogRequest{EventTime: &past}, timestamp))
+	assert.False(t, placedAtEventTime(&CreateTradingLogRequest{EventTime: &future}, timestamp))
+	assert.False(t, placedAtEventTime(&CreateTradingLogRequest{}, timestamp))
+	assert.False(t, placedAtEventTime(&CreateTradingLogRequest{EventTime: &past, virtualFill: true}, timestamp))
+}
+
+func TestOrderChain(t *testing.T) {
+	accountID := uuid.New()
+	timestamp := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	// A buy and its fee posted in one database transaction share a timestamp
+	payment := &models.Transaction{ID: uuid.New(), SubAccountID: accountID, Timestamp: timestamp, Direction: "debit", Amount: decimal.NewFromInt(300), ClosingBalance: decimal.NewFromInt(700)}
+	fee := &models.Transaction{ID: uuid.New(), SubAccountID: accountID, Timestamp: timestamp, Direction: "debit", Amount: decimal.NewFromInt(1), ClosingBalance: decimal.NewFromInt(699)}
+	deposit := &models.Transaction{ID: uuid.New(), SubAccountID: accountID, Timestamp: timestamp.Add(time.Hour), Direction: "credit", Amount: decimal.NewFromInt(1), ClosingBalance: decimal.NewFromInt(700)}
+
+	ordered := orderChain(decimal.NewFromInt(1000), []*models.Transaction{fee, payment, deposit})
+
+	require.Len(t, ordered, 3)
+	assert.Equal(t, payment.ID, ordered[0].ID)
+	assert.Equal(t, fee.ID, ordered[1].ID)
+	assert.Equal(t, deposit.ID, ordered[2].ID)
+}
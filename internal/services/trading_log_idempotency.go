@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"tiris-backend/internal/models"
+	"tiris-backend/internal/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxIdempotencyKeyLength is the maximum length of an Idempotency-Key header value
+const MaxIdempotencyKeyLength = 255
+
+// CreateTradingLogIdempotent creates a trading log at most once per user and idempotency key.
+// A retry with the same key and request returns the original response with replayed set to true;
+// a retry with the same key and a different request is rejected.
+func (s *TradingLogService) CreateTradingLogIdempotent(ctx context.Context, userID uuid.UUID, key string, req *CreateTradingLogRequest) (response *TradingLogResponse, replayed bool, err error) {
+	if len(key) == 0 || len(key) > MaxIdempotencyKeyLength {
+		return nil, false, fmt.Errorf("idempotency key must be between 1 and %d characters", MaxIdempotencyKeyLength)
+	}
+
+	requestHash, err := hashTradingLogRequest(req)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Replay the stored response if the key was already used
+	existing, err := s.repos.IdempotencyKey.GetByUserIDAndKey(ctx, userID, key)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	if existing != nil {
+		response, err := replayIdempotencyKey(existing, requestHash)
+		return response, err == nil, err
+	}
+
+	// Process the trading log and record the key in the same database transaction, so a
+	// concurrent request with the same key waits on the unique constraint and is rolled back
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		txRepos := repositories.NewRepositories(tx)
+
+		result, err := s.processor.withRepositories(txRepos).ProcessTradingLog(ctx, tx, userID, req)
+		if err != nil {
+			return fmt.Errorf("failed to process trading log: %w", err)
+		}
+		response = s.convertProcessingResult(result)
+
+		storedResponse, err := toJSONMap(response)
+		if err != nil {
+			return err
+		}
+
+		return txRepos.IdempotencyKey.Create(ctx, &models.IdempotencyKey{
+			ID:          uuid.New(),
+			UserID:      userID,
+			Key:         key,
+			RequestHash: requestHash,
+			Response:    storedResponse,
+		})
+	})
+
+	if err != nil {
+		if !isUniqueConstraintViolation(err) {
+			return nil, false, err
+		}
+
+		// Another request with the same key won the race
+		existing, getErr := s.repos.IdempotencyKey.GetByUserIDAndKey(ctx, userID, key)
+		if getErr != nil || existing == nil {
+			return nil, false, fmt.Errorf("failed to record idempotency key: %w", err)
+		}
+		response, err := replayIdempotencyKey(existing, requestHash)
+		return response, err == nil, err
+	}
+
+	return response, false, nil
+}
+
+// replayIdempotencyKey returns the stored response for a key if the request matches the original one
+func replayIdempotencyKey(key *models.IdempotencyKey, requestHash string) (*TradingLogResponse, error) {
+	if key.RequestHash != requestHash {
+		return nil, fmt.Errorf("idempotency key already used with a different request")
+	}
+
+	responseJSON, err := json.Marshal(key.Response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal stored response: %w", err)
+	}
+
+	var response TradingLogResponse
+	if err := json.Unmarshal(responseJSON, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal stored response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// hashTradingLogRequest returns the SHA-256 of the canonical JSON encoding of a request.
+// Map keys are encoded in sorted order, so the hash does not depend on the field order sent by the client.
+func hashTradingLogRequest(req *CreateTradingLogRequest) (string, error) {
+	requestJSON, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	sum := sha256.Sum256(requestJSON)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// toJSONMap converts a value to a JSON object for storage in a jsonb column
+func toJSONMap(value interface{}) (models.JSON, error) {
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	var result models.JSON
+	if err := json.Unmarshal(valueJSON, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"tiris-backend/internal/models"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashTradingLogRequest(t *testing.T) {
+	tradingID := uuid.New()
+	decode := func(body string) *CreateTradingLogRequest {
+		var req CreateTradingLogRequest
+		require.NoError(t, binding.JSON.BindBody([]byte(body), &req))
+		return &req
+	}
+
+	original := decode(`{"trading_id":"` + tradingID.String() + `","type":"long","source":"bot","message":"buy","info":{"price":3000.5,"volume":2}}`)
+	reordered := decode(`{"message":"buy","source":"bot","type":"long","info":{"volume":2,"price":3000.5},"trading_id":"` + tradingID.String() + `"}`)
+	changed := decode(`{"trading_id":"` + tradingID.String() + `","type":"long","source":"bot","message":"buy","info":{"price":3000.5,"volume":3}}`)
+
+	originalHash, err := hashTradingLogRequest(original)
+	require.NoError(t, err)
+	reorderedHash, err := hashTradingLogRequest(reordered)
+	require.NoError(t, err)
+	changedHash, err := hashTradingLogRequest(changed)
+	require.NoError(t, err)
+
+	assert.Len(t, originalHash, 64)
+	assert.Equal(t, originalHash, reorderedHash)
+	assert.NotEqual(t, originalHash, changedHash)
+}
+
+func TestReplayIdempotencyKey(t *testing.T) {
+	original := &TradingLogResponse{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		TradingID: uuid.New(),
+		Timestamp: "2025-08-01T10:00:00Z",
+		Type:      "long",
+		Source:    "bot",
+		Message:   "buy",
+		Info:      map[string]interface{}{"processed_transactions": float64(2)},
+	}
+	stored, err := toJSONMap(original)
+	require.NoError(t, err)
+	key := &models.IdempotencyKey{Key: "fill-42", RequestHash: "abc", Response: stored}
+
+	t.Run("same_request", func(t *testing.T) {
+		response, err := replayIdempotencyKey(key, "abc")
+
+		require.NoError(t, err)
+		expected, _ := json.Marshal(original)
+		actual, _ := json.Marshal(response)
+		assert.JSONEq(t, string(expected), string(actual))
+	})
+
+	t.Run("different_request", func(t *testing.T) {
+		response, err := replayIdempotencyKey(key, "def")
+
+		require.Error(t, err)
+		assert.Nil(t, response)
+		assert.Equal(t, "idempotency key already used with a different request", err.Error())
+	})
+}
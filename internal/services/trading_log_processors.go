@@ -10,20 +10,44 @@ import (
 	"tiris-backend/internal/repositories"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
+// TransferReason is the transaction reason of transfers between sub-accounts. Transfers move funds
+// inside a trading and are not external cash flows like deposit and withdraw.
+const TransferReason = "transfer"
+
 // TradingLogProcessor handles business logic processing for trading logs
 type TradingLogProcessor struct {
 	repos     *repositories.Repositories
 	validator *TradingLogValidator
 }
 
-// NewTradingLogProcessor creates a new trading log processor
+// NewTradingLogProcessor creates a new trading log processor for the built-in business logic types
 func NewTradingLogProcessor(repos *repositories.Repositories) *TradingLogProcessor {
+	return NewTradingLogProcessorWithTypes(repos, NewDefaultTradingLogTypeRegistry())
+}
+
+// NewTradingLogProcessorWithTypes creates a new trading log processor for the types in the given registry
+func NewTradingLogProcessorWithTypes(repos *repositories.Repositories, types *TradingLogTypeRegistry) *TradingLogProcessor {
+	return &TradingLogProcessor{
+		repos:     repos,
+		validator: NewTradingLogValidatorWithTypes(types),
+	}
+}
+
+// Types returns the registry of business logic types handled by the processor
+func (p *TradingLogProcessor) Types() *TradingLogTypeRegistry {
+	return p.validator.types
+}
+
+// withRepositories returns a processor for the same types that uses the given repositories,
+// e.g. repositories bound to a database transaction
+func (p *TradingLogProcessor) withRepositories(repos *repositories.Repositories) *TradingLogProcessor {
 	return &TradingLogProcessor{
 		repos:     repos,
-		validator: NewTradingLogValidator(),
+		validator: p.validator,
 	}
 }
 
@@ -52,11 +76,12 @@ func (p *TradingLogProcessor) ProcessTradingLog(ctx context.Context, db *gorm.DB
 		return p.createSimpleTradingLog(ctx, db, userID, req)
 	}
 
-	// Process business logic type within a database transaction
+	// Process business logic type within a database transaction. Balance, price and position
+	// writes go through repositories bound to it, so a refused trade leaves nothing behind.
 	var result *ProcessingResult
 	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var txErr error
-		result, txErr = p.processBusinessLogicType(ctx, tx, userID, req, tradingInfo)
+		result, txErr = p.withRepositories(repositories.NewRepositories(tx)).processBusinessLogicType(ctx, tx, userID, req, tradingInfo)
 		return txErr
 	})
 
@@ -67,8 +92,13 @@ func (p *TradingLogProcessor) ProcessTradingLog(ctx context.Context, db *gorm.DB
 	return result, nil
 }
 
-// processBusinessLogicType handles long, short, and stop_loss trading log types
+// processBusinessLogicType creates a trading log of a registered business logic type and applies its financial effects
 func (p *TradingLogProcessor) processBusinessLogicType(ctx context.Context, tx *gorm.DB, userID uuid.UUID, req *CreateTradingLogRequest, tradingInfo *TradingLogInfo) (*ProcessingResult, error) {
+	handler, exists := p.validator.types.Lookup(req.Type)
+	if !exists {
+		return nil, fmt.Errorf("unsupported business logic type: %s", req.Type)
+	}
+
 	// Verify trading ownership
 	trading, err := p.repos.Trading.GetByID(ctx, req.TradingID)
 	if err != nil {
@@ -77,26 +107,8 @@ func (p *TradingLogProcessor) processBusinessLogicType(ctx context.Context, tx *
 	if trading == nil || trading.UserID != userID {
 		return nil, fmt.Errorf("trading not found")
 	}
-
-	// Verify and get sub-accounts
-	stockAccount, err := p.repos.SubAccount.GetByID(ctx, tradingInfo.StockAccountID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get stock account: %w", err)
-	}
-	if stockAccount == nil || stockAccount.UserID != userID {
-		return nil, fmt.Errorf("stock account not found")
-	}
-
-	var currencyAccount *models.SubAccount
-	// For deposit/withdraw, currencyAccount is not needed
-	if req.Type != "deposit" && req.Type != "withdraw" {
-		currencyAccount, err = p.repos.SubAccount.GetByID(ctx, tradingInfo.CurrencyAccountID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get currency account: %w", err)
-		}
-		if currencyAccount == nil || currencyAccount.UserID != userID {
-			return nil, fmt.Errorf("currency account not found")
-		}
+	if err := checkEventTime(trading, req.EventTime); err != nil {
+		return nil, err
 	}
 
 	// Create the trading log record first
@@ -128,49 +140,25 @@ func (p *TradingLogProcessor) processBusinessLogicType(ctx context.Context, tx *
 		return nil, fmt.Errorf("failed to unmarshal trading log info: %w", err)
 	}
 
-	// Process based on type
-	var createdTransactions []*models.Transaction
-	var updatedSubAccounts []*models.SubAccount
-
-	switch req.Type {
-	case "long":
-		transactions, accounts, err := p.ProcessLongPosition(ctx, tradingInfo, stockAccount, currencyAccount, tradingLogInfo)
-		if err != nil {
-			return nil, fmt.Errorf("failed to process long position: %w", err)
-		}
-		createdTransactions = transactions
-		updatedSubAccounts = accounts
-
-	case "short", "stop_loss":
-		reason := "short"
-		if req.Type == "stop_loss" {
-			reason = "stop_loss"
-		}
-		transactions, accounts, err := p.ProcessShortPosition(ctx, tradingInfo, stockAccount, currencyAccount, tradingLogInfo, reason)
-		if err != nil {
-			return nil, fmt.Errorf("failed to process %s position: %w", req.Type, err)
-		}
-		createdTransactions = transactions
-		updatedSubAccounts = accounts
-
-	case "deposit":
-		transactions, accounts, err := p.ProcessDeposit(ctx, tx, tradingInfo, stockAccount, tradingLogInfo)
-		if err != nil {
-			return nil, fmt.Errorf("failed to process deposit: %w", err)
-		}
-		createdTransactions = transactions
-		updatedSubAccounts = accounts
+	// Apply the financial effects of the type
+	createdTransactions, updatedSubAccounts, err := handler.Process(ctx, p, &TradingLogTypeInput{
+		Tx:             tx,
+		UserID:         userID,
+		Trading:        trading,
+		Request:        req,
+		Info:           tradingInfo,
+		TradingLogInfo: tradingLogInfo,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	case "withdraw":
-		transactions, accounts, err := p.ProcessWithdraw(ctx, tradingInfo, stockAccount, tradingLogInfo)
-		if err != nil {
-			return nil, fmt.Errorf("failed to process withdraw: %w", err)
+	// A trade entered late is placed at its event time in the ledger
+	if placedAtEventTime(req, tradingLogRecord.Timestamp) && len(createdTransactions) > 0 {
+		backdated := append(append([]*models.Transaction{}, req.backdateWith...), createdTransactions...)
+		if err := p.backdateTransactions(ctx, tx, backdated, *req.EventTime); err != nil {
+			return nil, err
 		}
-		createdTransactions = transactions
-		updatedSubAccounts = accounts
-
-	default:
-		return nil, fmt.Errorf("unsupported business logic type: %s", req.Type)
 	}
 
 	return &ProcessingResult{
@@ -181,46 +169,90 @@ func (p *TradingLogProcessor) processBusinessLogicType(ctx context.Context, tx *
 }
 
 // ProcessLongPosition handles long position business logic
-func (p *TradingLogProcessor) ProcessLongPosition(ctx context.Context, tradingInfo *TradingLogInfo, stockAccount, currencyAccount *models.SubAccount, tradingLogInfo map[string]interface{}) ([]*models.Transaction, []*models.SubAccount, error) {
+func (p *TradingLogProcessor) ProcessLongPosition(ctx context.Context, tradingID uuid.UUID, tradingInfo *TradingLogInfo, stockAccount, currencyAccount *models.SubAccount, tradingLogInfo map[string]interface{}) ([]*models.Transaction, []*models.SubAccount, error) {
 	var transactions []*models.Transaction
 	var updatedAccounts []*models.SubAccount
 
-	// Calculate amounts
-	totalCost := tradingInfo.Price*tradingInfo.Volume + tradingInfo.Fee
+	feeAccount, err := p.resolveFeeAccount(ctx, tradingID, tradingInfo, stockAccount, currencyAccount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Calculate amounts, rounded to the precision stored in the ledger. Without a fee account
+	// the fee is paid from the currency account as part of the trade.
+	totalCost := tradingInfo.Price.Mul(tradingInfo.Volume).Round(MaxDecimalPlaces)
+	if feeAccount == nil {
+		totalCost = totalCost.Add(tradingInfo.Fee)
+	}
 
 	// Check if currency account has sufficient balance for debit
-	if currencyAccount.Balance < totalCost {
-		return nil, nil, fmt.Errorf("insufficient balance in currency account: required %.8f, available %.8f",
-			totalCost, currencyAccount.Balance)
+	requiredCurrency := totalCost
+	if feeAccount == currencyAccount {
+		requiredCurrency = requiredCurrency.Add(tradingInfo.Fee)
+	}
+	if currencyAccount.SpendableBalance().LessThan(requiredCurrency) {
+		return nil, nil, fmt.Errorf("insufficient balance in currency account: required %s, available %s",
+			requiredCurrency.StringFixed(MaxDecimalPlaces), currencyAccount.SpendableBalance().StringFixed(MaxDecimalPlaces))
+	}
+
+	// A fee charged in the bought asset is taken from the volume received
+	if feeAccount != nil && feeAccount != currencyAccount {
+		available := feeAccount.SpendableBalance()
+		if feeAccount == stockAccount {
+			available = available.Add(tradingInfo.Volume)
+		}
+		if err := checkFeeBalance(available, tradingInfo.Fee); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	// Transaction 1: Credit stock account with volume
-	newStockBalance := stockAccount.Balance + tradingInfo.Volume
+	newStockBalance := stockAccount.Balance.Add(tradingInfo.Volume)
 	stockTransactionID, err := p.repos.SubAccount.UpdateBalance(ctx, stockAccount.ID, newStockBalance, tradingInfo.Volume, "credit", "long", tradingLogInfo)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to update stock account balance: %w", err)
 	}
 
 	// Get the created stock transaction
+	var boughtTransaction *models.Transaction
 	if stockTransactionID != nil {
 		stockTransaction, err := p.repos.Transaction.GetByID(ctx, *stockTransactionID)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to get stock transaction: %w", err)
 		}
 		if stockTransaction != nil {
-			// Set additional fields for trading transactions
-			stockTransaction.Price = &tradingInfo.Price
-			stockTransaction.QuoteSymbol = &tradingInfo.Currency
+			if err := p.recordTradePrice(ctx, stockTransaction, tradingInfo); err != nil {
+				return nil, nil, err
+			}
 			transactions = append(transactions, stockTransaction)
+			boughtTransaction = stockTransaction
 		}
 	}
 
-	// Update stock account record
+	// Update stock account record, folding the purchase into the position's average cost.
+	// Fees paid in the currency are part of the cost; fees paid in the stock reduce the quantity received.
+	costBasis := totalCost
+	acquired := tradingInfo.Volume
+	switch feeAccount {
+	case currencyAccount:
+		costBasis = costBasis.Add(tradingInfo.Fee)
+	case stockAccount:
+		acquired = acquired.Sub(tradingInfo.Fee)
+	}
+	avgCost, coverPnL := positionAfterBuy(stockAccount.Balance, stockAccount.AvgCost, acquired, costBasis)
+	stockAccount.AvgCost = avgCost
+	if coverPnL != nil {
+		// Buying back a short realizes its profit
+		stockAccount.RealizedPnL = stockAccount.RealizedPnL.Add(*coverPnL)
+		if boughtTransaction != nil {
+			boughtTransaction.RealizedPnL = coverPnL
+		}
+	}
 	stockAccount.Balance = newStockBalance
 	updatedAccounts = append(updatedAccounts, stockAccount)
 
 	// Transaction 2: Debit currency account with total cost
-	newCurrencyBalance := currencyAccount.Balance - totalCost
+	newCurrencyBalance := currencyAccount.Balance.Sub(totalCost)
 	currencyTransactionID, err := p.repos.SubAccount.UpdateBalance(ctx, currencyAccount.ID, newCurrencyBalance, totalCost, "debit", "long", tradingLogInfo)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to update currency account balance: %w", err)
@@ -233,9 +265,9 @@ func (p *TradingLogProcessor) ProcessLongPosition(ctx context.Context, tradingIn
 			return nil, nil, fmt.Errorf("failed to get currency transaction: %w", err)
 		}
 		if currencyTransaction != nil {
-			// Set additional fields for trading transactions
-			currencyTransaction.Price = &tradingInfo.Price
-			currencyTransaction.QuoteSymbol = &tradingInfo.Currency
+			if err := p.recordTradePrice(ctx, currencyTransaction, tradingInfo); err != nil {
+				return nil, nil, err
+			}
 			transactions = append(transactions, currencyTransaction)
 		}
 	}
@@ -244,25 +276,62 @@ func (p *TradingLogProcessor) ProcessLongPosition(ctx context.Context, tradingIn
 	currencyAccount.Balance = newCurrencyBalance
 	updatedAccounts = append(updatedAccounts, currencyAccount)
 
+	// Transaction 3: Debit the fee account with the fee
+	feeTransactions, feeAccounts, err := p.chargeFee(ctx, tradingInfo, feeAccount, updatedAccounts, tradingLogInfo)
+	if err != nil {
+		return nil, nil, err
+	}
+	transactions = append(transactions, feeTransactions...)
+	updatedAccounts = append(updatedAccounts, feeAccounts...)
+
 	return transactions, updatedAccounts, nil
 }
 
 // ProcessShortPosition handles short and stop_loss position business logic
-func (p *TradingLogProcessor) ProcessShortPosition(ctx context.Context, tradingInfo *TradingLogInfo, stockAccount, currencyAccount *models.SubAccount, tradingLogInfo map[string]interface{}, reason string) ([]*models.Transaction, []*models.SubAccount, error) {
+func (p *TradingLogProcessor) ProcessShortPosition(ctx context.Context, tradingID uuid.UUID, tradingInfo *TradingLogInfo, stockAccount, currencyAccount *models.SubAccount, tradingLogInfo map[string]interface{}, reason string) ([]*models.Transaction, []*models.SubAccount, error) {
 	var transactions []*models.Transaction
 	var updatedAccounts []*models.SubAccount
 
-	// Calculate amounts
-	netProceeds := tradingInfo.Price*tradingInfo.Volume - tradingInfo.Fee
+	feeAccount, err := p.resolveFeeAccount(ctx, tradingID, tradingInfo, stockAccount, currencyAccount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Calculate amounts, rounded to the precision stored in the ledger. Without a fee account
+	// the fee is deducted from the proceeds credited to the currency account.
+	netProceeds := tradingInfo.Price.Mul(tradingInfo.Volume).Round(MaxDecimalPlaces)
+	if feeAccount == nil {
+		netProceeds = netProceeds.Sub(tradingInfo.Fee)
+	}
 
 	// Check if stock account has sufficient balance for debit
-	if stockAccount.Balance < tradingInfo.Volume {
-		return nil, nil, fmt.Errorf("insufficient balance in stock account: required %.8f, available %.8f",
-			tradingInfo.Volume, stockAccount.Balance)
+	requiredStock := tradingInfo.Volume
+	if feeAccount == stockAccount {
+		requiredStock = requiredStock.Add(tradingInfo.Fee)
+	}
+	if stockAccount.SpendableBalance().LessThan(requiredStock) {
+		return nil, nil, fmt.Errorf("insufficient balance in stock account: required %s, available %s",
+			requiredStock.StringFixed(MaxDecimalPlaces), stockAccount.SpendableBalance().StringFixed(MaxDecimalPlaces))
 	}
 
+	// A fee charged in the currency can be paid from the proceeds
+	if feeAccount != nil && feeAccount != stockAccount {
+		available := feeAccount.SpendableBalance()
+		if feeAccount == currencyAccount {
+			available = available.Add(netProceeds)
+		}
+		if err := checkFeeBalance(available, tradingInfo.Fee); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Profit of the sale against the position's average cost, net of the fee valued in the currency.
+	// Units sold short beyond the position realize nothing yet.
+	avgCost, pnl := positionAfterSell(stockAccount.Balance, stockAccount.AvgCost, tradingInfo.Price, tradingInfo.Volume,
+		feeInCurrency(tradingInfo, feeAccount, stockAccount, currencyAccount))
+
 	// Transaction 1: Debit stock account with volume
-	newStockBalance := stockAccount.Balance - tradingInfo.Volume
+	newStockBalance := stockAccount.Balance.Sub(tradingInfo.Volume)
 	stockTransactionID, err := p.repos.SubAccount.UpdateBalance(ctx, stockAccount.ID, newStockBalance, tradingInfo.Volume, "debit", reason, tradingLogInfo)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to update stock account balance: %w", err)
@@ -275,19 +344,23 @@ func (p *TradingLogProcessor) ProcessShortPosition(ctx context.Context, tradingI
 			return nil, nil, fmt.Errorf("failed to get stock transaction: %w", err)
 		}
 		if stockTransaction != nil {
-			// Set additional fields for trading transactions
-			stockTransaction.Price = &tradingInfo.Price
-			stockTransaction.QuoteSymbol = &tradingInfo.Currency
+			if err := p.recordTradePrice(ctx, stockTransaction, tradingInfo); err != nil {
+				return nil, nil, err
+			}
+			stockTransaction.RealizedPnL = &pnl
 			transactions = append(transactions, stockTransaction)
 		}
 	}
 
-	// Update stock account record
+	// Update stock account record; selling does not change the average cost of what is left,
+	// selling short sets the average entry price of the short position
 	stockAccount.Balance = newStockBalance
+	stockAccount.AvgCost = avgCost
+	stockAccount.RealizedPnL = stockAccount.RealizedPnL.Add(pnl)
 	updatedAccounts = append(updatedAccounts, stockAccount)
 
 	// Transaction 2: Credit currency account with net proceeds
-	newCurrencyBalance := currencyAccount.Balance + netProceeds
+	newCurrencyBalance := currencyAccount.Balance.Add(netProceeds)
 	currencyTransactionID, err := p.repos.SubAccount.UpdateBalance(ctx, currencyAccount.ID, newCurrencyBalance, netProceeds, "credit", reason, tradingLogInfo)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to update currency account balance: %w", err)
@@ -300,9 +373,9 @@ func (p *TradingLogProcessor) ProcessShortPosition(ctx context.Context, tradingI
 			return nil, nil, fmt.Errorf("failed to get currency transaction: %w", err)
 		}
 		if currencyTransaction != nil {
-			// Set additional fields for trading transactions
-			currencyTransaction.Price = &tradingInfo.Price
-			currencyTransaction.QuoteSymbol = &tradingInfo.Currency
+			if err := p.recordTradePrice(ctx, currencyTransaction, tradingInfo); err != nil {
+				return nil, nil, err
+			}
 			transactions = append(transactions, currencyTransaction)
 		}
 	}
@@ -311,9 +384,27 @@ func (p *TradingLogProcessor) ProcessShortPosition(ctx context.Context, tradingI
 	currencyAccount.Balance = newCurrencyBalance
 	updatedAccounts = append(updatedAccounts, currencyAccount)
 
+	// Transaction 3: Debit the fee account with the fee
+	feeTransactions, feeAccounts, err := p.chargeFee(ctx, tradingInfo, feeAccount, updatedAccounts, tradingLogInfo)
+	if err != nil {
+		return nil, nil, err
+	}
+	transactions = append(transactions, feeTransactions...)
+	updatedAccounts = append(updatedAccounts, feeAccounts...)
+
 	return transactions, updatedAccounts, nil
 }
 
+// recordTradePrice stores the trade price and its quote currency on a transaction of a trade
+func (p *TradingLogProcessor) recordTradePrice(ctx context.Context, transaction *models.Transaction, tradingInfo *TradingLogInfo) error {
+	if err := p.repos.Transaction.SetPrice(ctx, transaction.ID, tradingInfo.Price, tradingInfo.Currency); err != nil {
+		return fmt.Errorf("failed to record trade price: %w", err)
+	}
+	transaction.Price = &tradingInfo.Price
+	transaction.QuoteSymbol = &tradingInfo.Currency
+	return nil
+}
+
 // ProcessDeposit handles deposit business logic
 func (p *TradingLogProcessor) ProcessDeposit(ctx context.Context, tx *gorm.DB, tradingInfo *TradingLogInfo, targetAccount *models.SubAccount, tradingLogInfo map[string]interface{}) ([]*models.Transaction, []*models.SubAccount, error) {
 	var transactions []*models.Transaction
@@ -321,7 +412,7 @@ func (p *TradingLogProcessor) ProcessDeposit(ctx context.Context, tx *gorm.DB, t
 
 	// Calculate new balance after deposit
 	depositAmount := tradingInfo.Volume // Amount is stored in Volume field
-	newBalance := targetAccount.Balance + depositAmount
+	newBalance := targetAccount.Balance.Add(depositAmount)
 
 	// Convert info to JSON for database function
 	infoJSON, err := json.Marshal(tradingLogInfo)
@@ -354,7 +445,7 @@ func (p *TradingLogProcessor) ProcessDeposit(ctx context.Context, tx *gorm.DB, t
 		}
 		if transaction != nil {
 			// Set additional fields for deposit transactions
-			price := 1.0 // Fixed price for deposits
+			price := decimal.NewFromInt(1) // Fixed price for deposits
 			transaction.Price = &price
 			transaction.QuoteSymbol = &tradingInfo.Stock // Currency is stored in Stock field
 			transactions = append(transactions, transaction)
@@ -375,14 +466,15 @@ func (p *TradingLogProcessor) ProcessWithdraw(ctx context.Context, tradingInfo *
 
 	withdrawAmount := tradingInfo.Volume // Amount is stored in Volume field
 
-	// Check if source account has sufficient balance for withdrawal
-	if sourceAccount.Balance < withdrawAmount {
-		return nil, nil, fmt.Errorf("insufficient balance in source account: required %.8f, available %.8f",
-			withdrawAmount, sourceAccount.Balance)
+	// Check if source account has sufficient balance for withdrawal. Unlike a trade, a withdrawal cannot
+	// use borrowed funds: what a margin account borrows stays on the exchange until it is repaid.
+	if sourceAccount.AvailableBalance().LessThan(withdrawAmount) {
+		return nil, nil, fmt.Errorf("insufficient balance in source account: required %s, available %s",
+			withdrawAmount.StringFixed(MaxDecimalPlaces), sourceAccount.AvailableBalance().StringFixed(MaxDecimalPlaces))
 	}
 
 	// Calculate new balance after withdrawal
-	newBalance := sourceAccount.Balance - withdrawAmount
+	newBalance := sourceAccount.Balance.Sub(withdrawAmount)
 
 	// Create debit transaction for the withdrawal
 	transactionID, err := p.repos.SubAccount.UpdateBalance(ctx, sourceAccount.ID, newBalance, withdrawAmount, "debit", "withdraw", tradingLogInfo)
@@ -398,7 +490,7 @@ func (p *TradingLogProcessor) ProcessWithdraw(ctx context.Context, tradingInfo *
 		}
 		if transaction != nil {
 			// Set additional fields for withdraw transactions
-			price := 1.0 // Fixed price for withdrawals
+			price := decimal.NewFromInt(1) // Fixed price for withdrawals
 			transaction.Price = &price
 			transaction.QuoteSymbol = &tradingInfo.Stock // Currency is stored in Stock field
 			transactions = append(transactions, transaction)
@@ -412,6 +504,105 @@ func (p *TradingLogProcessor) ProcessWithdraw(ctx context.Context, tradingInfo *
 	return transactions, updatedAccounts, nil
 }
 
+// ProcessTransfer handles transfer business logic. Both sub-accounts must belong to the trading and hold the transferred symbol.
+func (p *TradingLogProcessor) ProcessTransfer(ctx context.Context, tradingID uuid.UUID, tradingInfo *TradingLogInfo, sourceAccount, targetAccount *models.SubAccount, tradingLogInfo map[string]interface{}) ([]*models.Transaction, []*models.SubAccount, error) {
+	var transactions []*models.Transaction
+	var updatedAccounts []*models.SubAccount
+
+	transferAmount := tradingInfo.Volume // Amount is stored in Volume field
+	symbol := tradingInfo.Stock          // Symbol is stored in Stock field
+
+	if sourceAccount.TradingID != tradingID || targetAccount.TradingID != tradingID {
+		return nil, nil, fmt.Errorf("transfer accounts must belong to the trading")
+	}
+	if sourceAccount.Symbol != symbol || targetAccount.Symbol != symbol {
+		return nil, nil, fmt.Errorf("transfer accounts must both hold %s: source holds %s, target holds %s",
+			symbol, sourceAccount.Symbol, targetAccount.Symbol)
+	}
+
+	// Check if source account has sufficient balance for the transfer. Borrowed funds stay in the
+	// sub-account that borrowed them, so only the available balance can be moved.
+	if sourceAccount.AvailableBalance().LessThan(transferAmount) {
+		return nil, nil, fmt.Errorf("insufficient balance in source account: required %s, available %s",
+			transferAmount.StringFixed(MaxDecimalPlaces), sourceAccount.AvailableBalance().StringFixed(MaxDecimalPlaces))
+	}
+
+	price := decimal.NewFromInt(1) // Transfers move funds at face value
+
+	// Transaction 1: Debit source account
+	newSourceBalance := sourceAccount.Balance.Sub(transferAmount)
+	sourceTransactionID, err := p.repos.SubAccount.UpdateBalance(ctx, sourceAccount.ID, newSourceBalance, transferAmount, "debit", TransferReason, tradingLogInfo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to update source account balance: %w", err)
+	}
+
+	// Get the created source transaction
+	if sourceTransactionID != nil {
+		sourceTransaction, err := p.repos.Transaction.GetByID(ctx, *sourceTransactionID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get source transaction: %w", err)
+		}
+		if sourceTransaction != nil {
+			sourceTransaction.Price = &price
+			sourceTransaction.QuoteSymbol = &symbol
+			transactions = append(transactions, sourceTransaction)
+		}
+	}
+
+	// Update source account record
+	sourceAccount.Balance = newSourceBalance
+	updatedAccounts = append(updatedAccounts, sourceAccount)
+
+	// Transaction 2: Credit target account
+	newTargetBalance := targetAccount.Balance.Add(transferAmount)
+	targetTransactionID, err := p.repos.SubAccount.UpdateBalance(ctx, targetAccount.ID, newTargetBalance, transferAmount, "credit", TransferReason, tradingLogInfo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to update target account balance: %w", err)
+	}
+
+	// Get the created target transaction
+	if targetTransactionID != nil {
+		targetTransaction, err := p.repos.Transaction.GetByID(ctx, *targetTransactionID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get target transaction: %w", err)
+		}
+		if targetTransaction != nil {
+			targetTransaction.Price = &price
+			targetTransaction.QuoteSymbol = &symbol
+			transactions = append(transactions, targetTransaction)
+		}
+	}
+
+	// Update target account record
+	targetAccount.Balance = newTargetBalance
+	updatedAccounts = append(updatedAccounts, targetAccount)
+
+	return transactions, updatedAccounts, nil
+}
+
+// placedAtEventTime reports whether the transactions of a trading log created at timestamp are placed
+// at its earlier event time. Fills of the virtual exchange are not: a backtest replays historical ticks
+// against balances funded now, so its fills follow the ledger in the order they happen and keep the
+// tick time only as the event time of their log.
+func placedAtEventTime(req *CreateTradingLogRequest, timestamp time.Time) bool {
+	return req.EventTime != nil && req.EventTime.Before(timestamp) && !req.virtualFill
+}
+
+// maxEventTimeSkew is how far ahead of the server clock the event time of a real trading log may be
+const maxEventTimeSkew = 5 * time.Minute
+
+// checkEventTime refuses event times in the future for real tradings. Simulation and backtest
+// tradings replay or invent their own timeline and accept any event time.
+func checkEventTime(trading *models.Trading, eventTime *time.Time) error {
+	if eventTime == nil || trading.IsSimulated() {
+		return nil
+	}
+	if eventTime.After(time.Now().Add(maxEventTimeSkew)) {
+		return fmt.Errorf("event time cannot be in the future for a real trading")
+	}
+	return nil
+}
+
 // createSimpleTradingLog creates a trading log without business logic processing
 func (p *TradingLogProcessor) createSimpleTradingLog(ctx context.Context, db *gorm.DB, userID uuid.UUID, req *CreateTradingLogRequest) (*ProcessingResult, error) {
 	// Verify trading ownership
@@ -422,6 +613,9 @@ func (p *TradingLogProcessor) createSimpleTradingLog(ctx context.Context, db *go
 	if trading == nil || trading.UserID != userID {
 		return nil, fmt.Errorf("trading not found")
 	}
+	if err := checkEventTime(trading, req.EventTime); err != nil {
+		return nil, err
+	}
 
 	// Verify sub-account ownership if provided
 	if req.SubAccountID != nil {
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"tiris-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReversalReason is the transaction reason used for compensating transactions
+const ReversalReason = "reversal"
+
+// reversalEntry describes a single compensating transaction for an original transaction
+type reversalEntry struct {
+	Original   *models.Transaction
+	Direction  string
+	Amount     decimal.Decimal
+	NewBalance decimal.Decimal
+}
+
+// ReverseTradingLog posts compensating transactions for every transaction created by a
+// business logic trading log and removes the log. It must be called within a database transaction.
+func (p *TradingLogProcessor) ReverseTradingLog(ctx context.Context, tx *gorm.DB, tradingLog *models.TradingLog) (*ProcessingResult, error) {
+	result, err := p.reverseTransactions(ctx, tx, tradingLog)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove the trading log itself; a log already removed must not have been reversed twice
+	deleted := tx.WithContext(ctx).Delete(&models.TradingLog{}, "id = ?", tradingLog.ID)
+	if deleted.Error != nil {
+		return nil, fmt.Errorf("failed to delete trading log: %w", deleted.Error)
+	}
+	if deleted.RowsAffected == 0 {
+		return nil, fmt.Errorf("trading log %s was already deleted", tradingLog.ID)
+	}
+
+	return result, nil
+}
+
+// reverseTransactions posts compensating transactions for every transaction created by a business
+// logic trading log and restores the positions of the affected sub-accounts. The log itself is kept.
+func (p *TradingLogProcessor) reverseTransactions(ctx context.Context, tx *gorm.DB, tradingLog *models.TradingLog) (*ProcessingResult, error) {
+	// Borrowing moves no funds; reversing it restores the borrowed amount
+	if tradingLog.Type == "borrow" || tradingLog.Type == "repay" {
+		account, err := p.reverseBorrowing(ctx, tx, tradingLog)
+		if err != nil {
+			return nil, err
+		}
+		return &ProcessingResult{
+			UpdatedSubAccounts: []*models.SubAccount{account},
+			TradingLogRecord:   tradingLog,
+		}, nil
+	}
+
+	// Find the transactions created for this trading log
+	var originals []*models.Transaction
+	err := tx.WithContext(ctx).
+		Where("trading_id = ? AND info->>'id' = ?", tradingLog.TradingID, tradingLog.ID.String()).
+		Order("timestamp ASC").
+		Find(&originals).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trading log transactions: %w", err)
+	}
+
+	// Lock the affected sub-accounts so the balances cannot change underneath us
+	accounts := make(map[uuid.UUID]*models.SubAccount)
+	balances := make(map[uuid.UUID]decimal.Decimal)
+	locked := make(map[uuid.UUID]decimal.Decimal)
+	borrowed := make(map[uuid.UUID]decimal.Decimal)
+	var accountOrder []uuid.UUID
+	for _, original := range originals {
+		if _, exists := accounts[original.SubAccountID]; exists {
+			continue
+		}
+		var account models.SubAccount
+		err := tx.WithContext(ctx).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", original.SubAccountID).
+			First(&account).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock sub-account %s: %w", original.SubAccountID, err)
+		}
+		accounts[account.ID] = &account
+		balances[account.ID] = account.Balance
+		locked[account.ID] = account.LockedBalance
+		borrowed[account.ID] = account.Borrowed
+		accountOrder = append(accountOrder, account.ID)
+	}
+
+	entries, err := planReversal(originals, balances, locked, borrowed)
+	if err != nil {
+		return nil, err
+	}
+
+	// Undo the effect of the trade on the stock account's position
+	if err := p.reversePosition(tradingLog, originals, accounts); err != nil {
+		return nil, err
+	}
+
+	// Convert trading log to JSON so the compensating transactions keep a link to it
+	tradingLogJSON, err := json.Marshal(tradingLog)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal trading log: %w", err)
+	}
+
+	var tradingLogInfo map[string]interface{}
+	if err := json.Unmarshal(tradingLogJSON, &tradingLogInfo); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal trading log info: %w", err)
+	}
+
+	var createdTransactions []*models.Transaction
+	for _, entry := range entries {
+		info := map[string]interface{}{
+			"reversal_of":             tradingLog.ID.String(),
+			"reversed_transaction_id": entry.Original.ID.String(),
+			"reversed_reason":         entry.Original.Reason,
+			"trading_log":             tradingLogInfo,
+		}
+
+		transaction, err := p.updateBalanceInTx(ctx, tx, entry.Original.SubAccountID, entry.NewBalance, entry.Amount, entry.Direction, ReversalReason, info)
+		if err != nil {
+			return nil, fmt.Errorf("failed to post reversal transaction: %w", err)
+		}
+		transaction.Price = entry.Original.Price
+		transaction.QuoteSymbol = entry.Original.QuoteSymbol
+		createdTransactions = append(createdTransactions, transaction)
+
+		accounts[entry.Original.SubAccountID].Balance = entry.NewBalance
+	}
+
+	for _, accountID := range accountOrder {
+		if err := p.savePosition(ctx, tx, accounts[accountID], nil); err != nil {
+			return nil, err
+		}
+	}
+
+	var updatedSubAccounts []*models.SubAccount
+	for _, accountID := range accountOrder {
+		updatedSubAccounts = append(updatedSubAccounts, accounts[accountID])
+	}
+
+	return &ProcessingResult{
+		CreatedTransactions: createdTransactions,
+		UpdatedSubAccounts:  updatedSubAccounts,
+		TradingLogRecord:    tradingLog,
+	}, nil
+}
+
+// reversePosition restores the average cost and realized PnL of the accounts touched by a trade.
+// It must run before the balances of the accounts are updated.
+func (p *TradingLogProcessor) reversePosition(tradingLog *models.TradingLog, originals []*models.Transaction, accounts map[uuid.UUID]*models.SubAccount) error {
+	// Realized profit of a sale is taken back from the account that booked it
+	for _, original := range originals {
+		if original.RealizedPnL != nil {
+			account := accounts[original.SubAccountID]
+			account.RealizedPnL = account.RealizedPnL.Sub(*original.RealizedPnL)
+		}
+	}
+
+	if tradingLog.Type != "long" && tradingLog.Type != "short" && tradingLog.Type != "stop_loss" {
+		return nil
+	}
+
+	tradingInfo, err := p.validator.ValidateInfoStructure(tradingLog.Info, tradingLog.Type)
+	if err != nil {
+		return fmt.Errorf("failed to read trading log info: %w", err)
+	}
+	stockAccount, exists := accounts[tradingInfo.StockAccountID]
+	if !exists {
+		return nil
+	}
+
+	if tradingLog.Type != "long" {
+		// A sale changes the average cost only when it opens or adds to a short position. The position
+		// before the sale is the current one plus what the sale took from the stock account, fee included.
+		before := stockAccount.Balance
+		var pnl decimal.Decimal
+		for _, original := range originals {
+			if original.SubAccountID != tradingInfo.StockAccountID {
+				continue
+			}
+			if original.Direction == "debit" {
+				before = before.Add(original.Amount)
+			} else {
+				before = before.Sub(original.Amount)
+			}
+			if original.Reason == tradingLog.Type && original.RealizedPnL != nil {
+				pnl = *original.RealizedPnL
+			}
+		}
+
+		var feeAccount *models.SubAccount
+		if tradingInfo.FeeAccountID != nil {
+			feeAccount = accounts[*tradingInfo.FeeAccountID]
+		}
+		fee := feeInCurrency(tradingInfo, feeAccount, stockAccount, accounts[tradingInfo.CurrencyAccountID])
+		stockAccount.AvgCost = averageCostAfterSellReversal(before, stockAccount.AvgCost, tradingInfo.Price, tradingInfo.Volume, pnl, fee)
+		return nil
+	}
+
+	// A buy is taken out of the average cost using the quantity received and the amount paid,
+	// including fees charged to the stock or currency account
+	var volume, cost decimal.Decimal
+	for _, original := range originals {
+		switch {
+		case original.SubAccountID == tradingInfo.StockAccountID && original.Direction == "credit":
+			volume = volume.Add(original.Amount)
+		case original.SubAccountID == tradingInfo.StockAccountID && original.Direction == "debit":
+			volume = volume.Sub(original.Amount)
+		case original.SubAccountID == tradingInfo.CurrencyAccountID && original.Direction == "debit":
+			cost = cost.Add(original.Amount)
+		}
+	}
+	stockAccount.AvgCost = averageCostAfterBuyReversal(stockAccount.Balance, stockAccount.AvgCost, volume, cost)
+
+	return nil
+}
+
+// planReversal computes the compensating transactions for the given original transactions.
+// Balances are the current balances of the affected sub-accounts, locked their locked balances and
+// borrowed their borrowed amounts; a reversal that would take back more than an account can spend,
+// its available balance plus what it borrows in margin mode, is refused.
+func planReversal(originals []*models.Transaction, balances, locked, borrowed map[uuid.UUID]decimal.Decimal) ([]reversalEntry, error) {
+	running := make(map[uuid.UUID]decimal.Decimal, len(balances))
+	for accountID, balance := range balances {
+		running[accountID] = balance
+	}
+
+	var entries []reversalEntry
+	for _, original := range originals {
+		balance, exists := running[original.SubAccountID]
+		if !exists {
+			return nil, fmt.Errorf("sub-account %s not found", original.SubAccountID)
+		}
+
+		var direction string
+		var newBalance decimal.Decimal
+		switch original.Direction {
+		case "credit":
+			direction = "debit"
+			newBalance = balance.Sub(original.Amount)
+			if available := balance.Sub(locked[original.SubAccountID]).Add(borrowed[original.SubAccountID]); available.LessThan(original.Amount) {
+				return nil, fmt.Errorf("insufficient balance to reverse trading log in sub-account %s: required %s, available %s",
+					original.SubAccountID, original.Amount.StringFixed(MaxDecimalPlaces), available.StringFixed(MaxDecimalPlaces))
+			}
+		case "debit":
+			direction = "credit"
+			newBalance = balance.Add(original.Amount)
+		default:
+			return nil, fmt.Errorf("invalid direction %q on transaction %s", original.Direction, original.ID)
+		}
+
+		running[original.SubAccountID] = newBalance
+		entries = append(entries, reversalEntry{
+			Original:   original,
+			Direction:  direction,
+			Amount:     original.Amount,
+			NewBalance: newBalance,
+		})
+	}
+
+	return entries, nil
+}
+
+// updateBalanceInTx updates a sub-account balance and records the transaction using the given database transaction
+func (p *TradingLogProcessor) updateBalanceInTx(ctx context.Context, tx *gorm.DB, subAccountID uuid.UUID, newBalance, amount decimal.Decimal, direction, reason string, info map[string]interface{}) (*models.Transaction, error) {
+	infoJSON, err := json.Marshal(info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal transaction info: %w", err)
+	}
+
+	var transactionIDStr string
+	err = tx.WithContext(ctx).Raw(
+		"SELECT update_sub_account_balance(?, ?, ?, ?, ?, ?::jsonb)",
+		subAccountID, newBalance, amount, direction, reason, string(infoJSON),
+	).Row().Scan(&transactionIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update sub-account balance: %w", err)
+	}
+
+	transactionID, err := uuid.Parse(transactionIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse transaction ID: %w", err)
+	}
+
+	var transaction models.Transaction
+	if err := tx.WithContext(ctx).Where("id = ?", transactionID).First(&transaction).Error; err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	return &transaction, nil
+}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"testing"
+
+	"tiris-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanReversal(t *testing.T) {
+	stockAccountID := uuid.New()
+	currencyAccountID := uuid.New()
+
+	// Transactions created by a long position: +2 ETH, -6012 USDT
+	longTransactions := []*models.Transaction{
+		{ID: uuid.New(), SubAccountID: stockAccountID, Direction: "credit", Reason: "long", Amount: decimal.NewFromInt(2)},
+		{ID: uuid.New(), SubAccountID: currencyAccountID, Direction: "debit", Reason: "long", Amount: decimal.NewFromInt(6012)},
+	}
+
+	t.Run("reverse_long_position", func(t *testing.T) {
+		balances := map[uuid.UUID]decimal.Decimal{
+			stockAccountID:    decimal.NewFromInt(2),
+			currencyAccountID: decimal.NewFromInt(3988),
+		}
+
+		entries, err := planReversal(longTransactions, balances, nil, nil)
+
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+
+		assert.Equal(t, "debit", entries[0].Direction)
+		assert.True(t, entries[0].Amount.Equal(decimal.NewFromInt(2)))
+		assert.True(t, entries[0].NewBalance.Equal(decimal.NewFromInt(0)))
+
+		assert.Equal(t, "credit", entries[1].Direction)
+		assert.True(t, entries[1].Amount.Equal(decimal.NewFromInt(6012)))
+		assert.True(t, entries[1].NewBalance.Equal(decimal.NewFromInt(10000)))
+
+		// Input balances must not be modified
+		assert.True(t, balances[stockAccountID].Equal(decimal.NewFromInt(2)))
+	})
+
+	t.Run("refuse_when_balance_already_spent", func(t *testing.T) {
+		// A later short already sold 1.5 of the 2 ETH bought
+		balances := map[uuid.UUID]decimal.Decimal{
+			stockAccountID:    decimal.RequireFromString("0.5"),
+			currencyAccountID: decimal.NewFromInt(8488),
+		}
+
+		entries, err := planReversal(longTransactions, balances, nil, nil)
+
+		require.Error(t, err)
+		assert.Nil(t, entries)
+		assert.Contains(t, err.Error(), "insufficient balance to reverse trading log")
+		assert.Contains(t, err.Error(), "required 2.00000000, available 0.50000000")
+	})
+
+	t.Run("running_balance_per_account", func(t *testing.T) {
+		// Two credits to the same account must both fit in the current balance
+		originals := []*models.Transaction{
+			{ID: uuid.New(), SubAccountID: currencyAccountID, Direction: "credit", Reason: "deposit", Amount: decimal.NewFromInt(600)},
+			{ID: uuid.New(), SubAccountID: currencyAccountID, Direction: "credit", Reason: "deposit", Amount: decimal.NewFromInt(600)},
+		}
+		balances := map[uuid.UUID]decimal.Decimal{currencyAccountID: decimal.NewFromInt(1000)}
+
+		_, err := planReversal(originals, balances, nil, nil)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "required 600.00000000, available 400.00000000")
+	})
+
+	t.Run("locked_balance_is_not_available", func(t *testing.T) {
+		// 1.5 of the 2 ETH bought are locked by an open order
+		balances := map[uuid.UUID]decimal.Decimal{
+			stockAccountID:    decimal.NewFromInt(2),
+			currencyAccountID: decimal.NewFromInt(3988),
+		}
+		locked := map[uuid.UUID]decimal.Decimal{stockAccountID: decimal.RequireFromString("1.5")}
+
+		entries, err := planReversal(longTransactions, balances, locked, nil)
+
+		require.Error(t, err)
+		assert.Nil(t, entries)
+		assert.Contains(t, err.Error(), "required 2.00000000, available 0.50000000")
+	})
+
+	t.Run("borrowed_balance_is_available", func(t *testing.T) {
+		// The margin account spent the 2 ETH bought and borrows 1 ETH
+		balances := map[uuid.UUID]decimal.Decimal{
+			stockAccountID:    decimal.NewFromInt(1),
+			currencyAccountID: decimal.NewFromInt(3988),
+		}
+		borrowed := map[uuid.UUID]decimal.Decimal{stockAccountID: decimal.NewFromInt(1)}
+
+		entries, err := planReversal(longTransactions, balances, nil, borrowed)
+
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.True(t, entries[0].NewBalance.Equal(decimal.NewFromInt(-1)), entries[0].NewBalance.String())
+	})
+
+	t.Run("no_transactions", func(t *testing.T) {
+		entries, err := planReversal(nil, map[uuid.UUID]decimal.Decimal{}, nil, nil)
+
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
+
+func TestReversePosition(t *testing.T) {
+	processor := NewTradingLogProcessor(nil)
+	stockAccountID := uuid.New()
+	currencyAccountID := uuid.New()
+
+	t.Run("long_restores_average_cost", func(t *testing.T) {
+		// Position of 3 ETH at 3104 after buying 1 ETH for 3300 on top of 2 ETH at 3006
+		accounts := map[uuid.UUID]*models.SubAccount{
+			stockAccountID:    {ID: stockAccountID, Balance: decimal.NewFromInt(3), AvgCost: decimal.NewFromInt(3104)},
+			currencyAccountID: {ID: currencyAccountID, Balance: decimal.NewFromInt(700)},
+		}
+		tradingLog := &models.TradingLog{
+			ID:   uuid.New(),
+			Type: "long",
+			Info: models.JSON{
+				"stock_account_id":    stockAccountID.String(),
+				"currency_account_id": currencyAccountID.String(),
+				"price":               3290.0,
+				"volume":              1.0,
+				"fee":                 10.0,
+				"stock":               "ETH",
+				"currency":            "USDT",
+			},
+		}
+		originals := []*models.Transaction{
+			{ID: uuid.New(), SubAccountID: stockAccountID, Direction: "credit", Reason: "long", Amount: decimal.NewFromInt(1)},
+			{ID: uuid.New(), SubAccountID: currencyAccountID, Direction: "debit", Reason: "long", Amount: decimal.NewFromInt(3300)},
+		}
+
+		err := processor.reversePosition(tradingLog, originals, accounts)
+
+		require.NoError(t, err)
+		assert.True(t, accounts[stockAccountID].AvgCost.Equal(decimal.NewFromInt(3006)), accounts[stockAccountID].AvgCost.String())
+	})
+
+	t.Run("long_with_fee_in_stock_restores_average_cost", func(t *testing.T) {
+		// Bought 2 ETH for 6000 with a 0.5 ETH fee on top of 1 ETH at 2000: 2.5 ETH at 3200
+		accounts := map[uuid.UUID]*models.SubAccount{
+			stockAccountID:    {ID: stockAccountID, Balance: decimal.RequireFromString("2.5"), AvgCost: decimal.NewFromInt(3200)},
+			currencyAccountID: {ID: currencyAccountID, Balance: decimal.NewFromInt(4000)},
+		}
+		tradingLog := &models.TradingLog{
+			ID:   uuid.New(),
+			Type: "long",
+			Info: models.JSON{
+				"stock_account_id":    stockAccountID.String(),
+				"currency_account_id": currencyAccountID.String(),
+				"price":               3000.0,
+				"volume":              2.0,
+				"fee":                 0.5,
+				"stock":               "ETH",
+				"currency":            "USDT",
+				"fee_account_id":      stockAccountID.String(),
+				"fee_asset":           "ETH",
+			},
+		}
+		originals := []*models.Transaction{
+			{ID: uuid.New(), SubAccountID: stockAccountID, Direction: "credit", Reason: "long", Amount: decimal.NewFromInt(2)},
+			{ID: uuid.New(), SubAccountID: currencyAccountID, Direction: "debit", Reason: "long", Amount: decimal.NewFromInt(6000)},
+			{ID: uuid.New(), SubAccountID: stockAccountID, Direction: "debit", Reason: "fee", Amount: decimal.RequireFromString("0.5")},
+		}
+
+		err := processor.reversePosition(tradingLog, originals, accounts)
+
+		require.NoError(t, err)
+		assert.True(t, accounts[stockAccountID].AvgCost.Equal(decimal.NewFromInt(2000)), accounts[stockAccountID].AvgCost.String())
+	})
+
+	t.Run("short_restores_average_cost", func(t *testing.T) {
+		// Short of 4 ETH at 135 after selling 2 ETH at 120 on top of a short of 2 ETH at 150
+		accounts := map[uuid.UUID]*models.SubAccount{
+			stockAccountID:    {ID: stockAccountID, Balance: decimal.NewFromInt(-4), AvgCost: decimal.NewFromInt(135)},
+			currencyAccountID: {ID: currencyAccountID, Balance: decimal.NewFromInt(540)},
+		}
+		tradingLog := &models.TradingLog{
+			ID:   uuid.New(),
+			Type: "short",
+			Info: models.JSON{
+				"stock_account_id":    stockAccountID.String(),
+				"currency_account_id": currencyAccountID.String(),
+				"price":               120.0,
+				"volume":              2.0,
+				"fee":                 0.0,
+				"stock":               "ETH",
+				"currency":            "USDT",
+			},
+		}
+		pnl := decimal.Zero
+		originals := []*models.Transaction{
+			{ID: uuid.New(), SubAccountID: stockAccountID, Direction: "debit", Reason: "short", Amount: decimal.NewFromInt(2), RealizedPnL: &pnl},
+			{ID: uuid.New(), SubAccountID: currencyAccountID, Direction: "credit", Reason: "short", Amount: decimal.NewFromInt(240)},
+		}
+
+		err := processor.reversePosition(tradingLog, originals, accounts)
+
+		require.NoError(t, err)
+		assert.True(t, accounts[stockAccountID].AvgCost.Equal(decimal.NewFromInt(150)), accounts[stockAccountID].AvgCost.String())
+	})
+
+	t.Run("short_with_fee_in_stock_restores_average_cost", func(t *testing.T) {
+		// Sold 3 ETH at 150 with a 0.1 ETH fee out of 2 ETH at 100: realized 85 and opened a short of 1.1 at 150
+		pnl := decimal.NewFromInt(85)
+		accounts := map[uuid.UUID]*models.SubAccount{
+			stockAccountID:    {ID: stockAccountID, Balance: decimal.RequireFromString("-1.1"), AvgCost: decimal.NewFromInt(150), RealizedPnL: pnl},
+			currencyAccountID: {ID: currencyAccountID, Balance: decimal.NewFromInt(450)},
+		}
+		tradingLog := &models.TradingLog{
+			ID:   uuid.New(),
+			Type: "short",
+			Info: models.JSON{
+				"stock_account_id":    stockAccountID.String(),
+				"currency_account_id": currencyAccountID.String(),
+				"price":               150.0,
+				"volume":              3.0,
+				"fee":                 0.1,
+				"stock":               "ETH",
+				"currency":            "USDT",
+				"fee_account_id":      stockAccountID.String(),
+				"fee_asset":           "ETH",
+			},
+		}
+		originals := []*models.Transaction{
+			{ID: uuid.New(), SubAccountID: stockAccountID, Direction: "debit", Reason: "short", Amount: decimal.NewFromInt(3), RealizedPnL: &pnl},
+			{ID: uuid.New(), SubAccountID: currencyAccountID, Direction: "credit", Reason: "short", Amount: decimal.NewFromInt(450)},
+			{ID: uuid.New(), SubAccountID: stockAccountID, Direction: "debit", Reason: "fee", Amount: decimal.RequireFromString("0.1")},
+		}
+
+		err := processor.reversePosition(tradingLog, originals, accounts)
+
+		require.NoError(t, err)
+		assert.True(t, accounts[stockAccountID].AvgCost.Equal(decimal.NewFromInt(100)), accounts[stockAccountID].AvgCost.String())
+		assert.True(t, accounts[stockAccountID].RealizedPnL.IsZero(), accounts[stockAccountID].RealizedPnL.String())
+	})
+
+	t.Run("short_takes_back_realized_pnl", func(t *testing.T) {
+		pnl := decimal.NewFromInt(1491)
+		accounts := map[uuid.UUID]*models.SubAccount{
+			stockAccountID:    {ID: stockAccountID, Balance: decimal.RequireFromString("3.5"), AvgCost: decimal.NewFromInt(2000), RealizedPnL: decimal.NewFromInt(1500)},
+			currencyAccountID: {ID: currencyAccountID, Balance: decimal.NewFromInt(5491)},
+		}
+		originals := []*models.Transaction{
+			{ID: uuid.New(), SubAccountID: stockAccountID, Direction: "debit", Reason: "short", Amount: decimal.RequireFromString("1.5"), RealizedPnL: &pnl},
+			{ID: uuid.New(), SubAccountID: currencyAccountID, Direction: "credit", Reason: "short", Amount: decimal.NewFromInt(4491)},
+		}
+
+		tradingLog := &models.TradingLog{
+			ID:   uuid.New(),
+			Type: "short",
+			Info: models.JSON{
+				"stock_account_id":    stockAccountID.String(),
+				"currency_account_id": currencyAccountID.String(),
+				"price":               2994.0,
+				"volume":              1.5,
+				"fee":                 0.0,
+				"stock":               "ETH",
+				"currency":            "USDT",
+			},
+		}
+
+		err := processor.reversePosition(tradingLog, originals, accounts)
+
+		require.NoError(t, err)
+		assert.True(t, accounts[stockAccountID].RealizedPnL.Equal(decimal.NewFromInt(9)), accounts[stockAccountID].RealizedPnL.String())
+		assert.True(t, accounts[stockAccountID].AvgCost.Equal(decimal.NewFromInt(2000)))
+	})
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // TradingLogService handles trading log business logic
@@ -41,22 +43,32 @@ type TradingLogResponse struct {
 	Source        string                 `json:"source"`
 	Message       string                 `json:"message"`
 	Info          map[string]interface{} `json:"info"`
+	Amends        *uuid.UUID             `json:"amends,omitempty"`
+	AmendedBy     *uuid.UUID             `json:"amended_by,omitempty"`
 }
 
 // CreateTradingLogRequest represents trading log creation request
 // @Description Request for creating a new trading log entry. The 'info' field structure depends on the 'type' value:
 // @Description - For types 'long', 'short', 'stop_loss': Must use TradingLogInfo structure
-// @Description - For types 'deposit', 'withdraw': Must use DepositWithdrawInfo structure  
+// @Description - For types 'deposit', 'withdraw', 'borrow', 'repay': Must use DepositWithdrawInfo structure  
+// @Description - For type 'transfer': Must use TransferInfo structure
 // @Description - For other types: Can use any object structure
 type CreateTradingLogRequest struct {
 	TradingID     uuid.UUID              `json:"trading_id" binding:"required" example:"453f0347-3959-49de-8e3f-1cf7c8e0827c" description:"ID of the trading where the trading activity occurred"`
 	SubAccountID  *uuid.UUID             `json:"sub_account_id,omitempty" example:"b4e006d0-1069-4ef4-b33f-7690af4929f4" description:"Optional sub-account ID (used for some trading log types)"`
 	TransactionID *uuid.UUID             `json:"transaction_id,omitempty" example:"1a098613-e738-447d-b921-74c3594df3a5" description:"Optional transaction ID for linking to specific transactions"`
-	EventTime     *time.Time             `json:"event_time,omitempty" example:"2024-01-15T10:30:00Z" description:"Logical timestamp when the trading event occurred. If not provided, defaults to NULL. For live trading, this should match current time. For backtesting, this represents the historical time when the event logically occurred."`
-	Type          string                 `json:"type" binding:"required,min=1,max=50" example:"long" enums:"long,short,stop_loss,deposit,withdraw,trade_execution,api_call,system_event,error,custom" description:"Type of trading log entry. Business logic types (long, short, stop_loss, deposit, withdraw) require specific 'info' field structures and trigger automatic financial calculations"`
+	EventTime     *time.Time             `json:"event_time,omitempty" example:"2024-01-15T10:30:00Z" description:"Logical timestamp when the trading event occurred. If not provided, defaults to NULL. For live trading, this should match current time. For backtesting, this represents the historical time when the event logically occurred. Transactions of a business logic log with a past event time are placed at that time."`
+	Type          string                 `json:"type" binding:"required,min=1,max=50" example:"long" enums:"long,short,stop_loss,deposit,withdraw,transfer,borrow,repay,trade_execution,api_call,system_event,error,custom" description:"Type of trading log entry. Business logic types (long, short, stop_loss, deposit, withdraw, transfer, borrow, repay) require specific 'info' field structures and trigger automatic financial calculations"`
 	Source        string                 `json:"source" binding:"required,oneof=manual bot" example:"bot" description:"Source of the trading log entry"`
 	Message       string                 `json:"message" binding:"required,min=1" example:"Successfully executed BUY order for 0.5 BTC at $42,500" description:"Human-readable description of the trading activity"`
-	Info          map[string]interface{} `json:"info,omitempty" description:"Type-specific structured data. Required structure depends on the 'type' field: long/short/stop_loss: Use TradingLogInfo schema, deposit/withdraw: Use DepositWithdrawInfo schema, other types: Any object structure"`
+	Info          map[string]interface{} `json:"info,omitempty" description:"Type-specific structured data. Required structure depends on the 'type' field: long/short/stop_loss: Use TradingLogInfo schema, deposit/withdraw/borrow/repay: Use DepositWithdrawInfo schema, transfer: Use TransferInfo schema, other types: Any object structure"`
+
+	// virtualFill marks the fills of the virtual exchange, whose event time is the time of the price
+	// tick that filled them; it cannot be set through the API
+	virtualFill bool
+	// backdateWith are transactions placed at the event time just before those of the log, in the
+	// same replay: an amendment places the reversal of the original before its correction this way
+	backdateWith []*models.Transaction
 }
 
 // CreateLongTradingLogExample shows example structure for long trading log requests
@@ -79,6 +91,32 @@ type CreateDepositTradingLogExample struct {
 	Info       DepositWithdrawInfo    `json:"info"`
 }
 
+// MaxTradingLogBatchSize is the maximum number of trading logs accepted in a single batch
+const MaxTradingLogBatchSize = 500
+
+// BatchCreateTradingLogsRequest represents a batch trading log creation request
+// @Description Request for creating several trading log entries at once. Entries are processed in order within a single database transaction.
+type BatchCreateTradingLogsRequest struct {
+	Logs            []CreateTradingLogRequest `json:"logs" binding:"required,min=1,max=500,dive" description:"Trading log entries, processed in the given order"`
+	ContinueOnError bool                      `json:"continue_on_error" example:"false" description:"When false (default) the whole batch is rolled back if any entry fails. When true failed entries are skipped and reported while the others are committed"`
+}
+
+// BatchTradingLogResult represents the outcome of a single entry in a batch
+type BatchTradingLogResult struct {
+	Index      int                 `json:"index" example:"0"`
+	Success    bool                `json:"success" example:"true"`
+	TradingLog *TradingLogResponse `json:"trading_log,omitempty"`
+	Error      string              `json:"error,omitempty"`
+}
+
+// BatchCreateTradingLogsResponse represents the results of a batch trading log creation
+type BatchCreateTradingLogsResponse struct {
+	Results   []*BatchTradingLogResult `json:"results"`
+	Total     int                      `json:"total" example:"10"`
+	Succeeded int                      `json:"succeeded" example:"10"`
+	Failed    int                      `json:"failed" example:"0"`
+}
+
 // TradingLogQueryRequest represents trading log query parameters
 type TradingLogQueryRequest struct {
 	Type      *string    `form:"type" example:"trade_execution"`
@@ -106,6 +144,11 @@ func (s *TradingLogService) CreateTradingLog(ctx context.Context, userID uuid.UU
 		return nil, fmt.Errorf("failed to process trading log: %w", err)
 	}
 
+	return s.convertProcessingResult(result), nil
+}
+
+// convertProcessingResult converts the result of processing a trading log to response format
+func (s *TradingLogService) convertProcessingResult(result *ProcessingResult) *TradingLogResponse {
 	// Convert the created trading log to response format
 	response := s.convertToTradingLogResponse(result.TradingLogRecord)
 
@@ -126,6 +169,72 @@ func (s *TradingLogService) CreateTradingLog(ctx context.Context, userID uuid.UU
 		response.Info["transaction_ids"] = transactionIDs
 	}
 
+	return response
+}
+
+// BatchCreateTradingLogs processes a batch of trading logs in order within a single database transaction.
+// By default the whole batch is rolled back when any entry fails. With ContinueOnError each entry runs
+// in its own savepoint, so failed entries are rolled back individually and the rest are committed.
+func (s *TradingLogService) BatchCreateTradingLogs(ctx context.Context, userID uuid.UUID, req *BatchCreateTradingLogsRequest) (*BatchCreateTradingLogsResponse, error) {
+	if len(req.Logs) == 0 {
+		return nil, fmt.Errorf("batch must contain at least one trading log")
+	}
+	if len(req.Logs) > MaxTradingLogBatchSize {
+		return nil, fmt.Errorf("batch cannot contain more than %d trading logs", MaxTradingLogBatchSize)
+	}
+
+	response := &BatchCreateTradingLogsResponse{
+		Results: make([]*BatchTradingLogResult, 0, len(req.Logs)),
+		Total:   len(req.Logs),
+	}
+
+	// Set when an entry fails and the whole batch is rolled back
+	var entryErr error
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// All reads and writes of the batch go through the batch transaction
+		processor := s.processor.withRepositories(repositories.NewRepositories(tx))
+
+		for i := range req.Logs {
+			var result *ProcessingResult
+			itemErr := tx.Transaction(func(itemTx *gorm.DB) error {
+				var err error
+				result, err = processor.ProcessTradingLog(ctx, itemTx, userID, &req.Logs[i])
+				return err
+			})
+
+			if itemErr != nil {
+				if !req.ContinueOnError {
+					entryErr = fmt.Errorf("batch entry %d failed: %w", i, itemErr)
+					return entryErr
+				}
+				response.Results = append(response.Results, &BatchTradingLogResult{
+					Index:   i,
+					Success: false,
+					Error:   itemErr.Error(),
+				})
+				response.Failed++
+				continue
+			}
+
+			response.Results = append(response.Results, &BatchTradingLogResult{
+				Index:      i,
+				Success:    true,
+				TradingLog: s.convertProcessingResult(result),
+			})
+			response.Succeeded++
+		}
+
+		return nil
+	})
+
+	if entryErr != nil {
+		return nil, entryErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to process trading log batch: %w", err)
+	}
+
 	return response, nil
 }
 
@@ -348,7 +457,8 @@ func (s *TradingLogService) GetTradingLogsByTimeRange(ctx context.Context, userI
 	}, nil
 }
 
-// DeleteTradingLog deletes a trading log (soft delete)
+// DeleteTradingLog deletes a trading log. For business logic types the financial effects
+// are reversed with compensating transactions in the same database transaction.
 func (s *TradingLogService) DeleteTradingLog(ctx context.Context, userID, tradingLogID uuid.UUID) error {
 	// Get existing trading log to verify ownership
 	tradingLog, err := s.repos.TradingLog.GetByID(ctx, tradingLogID)
@@ -358,7 +468,46 @@ func (s *TradingLogService) DeleteTradingLog(ctx context.Context, userID, tradin
 	if tradingLog == nil {
 		return fmt.Errorf("trading log not found")
 	}
+	if err := checkDeletable(tradingLog, userID); err != nil {
+		return err
+	}
+
+	// Reverse balances and transactions for business logic types
+	if s.processor.validator.isBusinessLogicType(tradingLog.Type) {
+		return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			// Lock the log and check it again, so a concurrent delete or amendment cannot reverse it twice
+			var locked models.TradingLog
+			err := tx.WithContext(ctx).
+				Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", tradingLogID).
+				First(&locked).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("trading log not found")
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get trading log: %w", err)
+			}
+			if err := checkDeletable(&locked, userID); err != nil {
+				return err
+			}
+
+			if _, err := s.processor.withRepositories(repositories.NewRepositories(tx)).ReverseTradingLog(ctx, tx, &locked); err != nil {
+				return fmt.Errorf("failed to reverse trading log: %w", err)
+			}
+			return nil
+		})
+	}
+
+	// Delete the trading log
+	if err := s.repos.TradingLog.Delete(ctx, tradingLogID); err != nil {
+		return fmt.Errorf("failed to delete trading log: %w", err)
+	}
 
+	return nil
+}
+
+// checkDeletable refuses to delete a trading log of another user, a bot log or an amended log
+func checkDeletable(tradingLog *models.TradingLog, userID uuid.UUID) error {
 	// Check if trading log belongs to the user
 	if tradingLog.UserID != userID {
 		return fmt.Errorf("trading log not found")
@@ -369,11 +518,10 @@ func (s *TradingLogService) DeleteTradingLog(ctx context.Context, userID, tradin
 		return fmt.Errorf("cannot delete bot-generated trading logs")
 	}
 
-	// Delete the trading log
-	if err := s.repos.TradingLog.Delete(ctx, tradingLogID); err != nil {
-		return fmt.Errorf("failed to delete trading log: %w", err)
+	// The transactions of an amended log have already been reversed
+	if tradingLog.AmendedBy != nil {
+		return fmt.Errorf("cannot delete an amended trading log")
 	}
-
 	return nil
 }
 
@@ -472,5 +620,7 @@ func (s *TradingLogService) convertToTradingLogResponse(tradingLog *models.Tradi
 		Source:        tradingLog.Source,
 		Message:       tradingLog.Message,
 		Info:          info,
+		Amends:        tradingLog.Amends,
+		AmendedBy:     tradingLog.AmendedBy,
 	}
 }
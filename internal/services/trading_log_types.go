@@ -0,0 +1,281 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"tiris-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TradingLogTypeHandler implements a business logic trading log type. Registering a handler makes
+// logs of its type validated against its info schema and processed into ledger transactions.
+type TradingLogTypeHandler interface {
+	// Type returns the trading log type handled, e.g. "long"
+	Type() string
+
+	// ValidateInfo validates the type specific info field and returns the parsed structure
+	ValidateInfo(v *TradingLogValidator, info map[string]interface{}) (*TradingLogInfo, error)
+
+	// Process applies the financial effects of the trading log within input.Tx and returns the
+	// created transactions and the updated sub-accounts
+	Process(ctx context.Context, p *TradingLogProcessor, input *TradingLogTypeInput) ([]*models.Transaction, []*models.SubAccount, error)
+}
+
+// TradingLogTypeInput carries what a trading log type needs to apply its financial effects
+type TradingLogTypeInput struct {
+	Tx      *gorm.DB
+	UserID  uuid.UUID
+	Trading *models.Trading
+	Request *CreateTradingLogRequest
+	Info    *TradingLogInfo
+	// TradingLogInfo is the trading log record stored in the info of the created transactions
+	TradingLogInfo map[string]interface{}
+}
+
+// TradingLogTypeRegistry holds the business logic trading log types
+type TradingLogTypeRegistry struct {
+	mu       sync.RWMutex
+	handlers map[string]TradingLogTypeHandler
+}
+
+// NewTradingLogTypeRegistry creates an empty trading log type registry
+func NewTradingLogTypeRegistry() *TradingLogTypeRegistry {
+	return &TradingLogTypeRegistry{
+		handlers: make(map[string]TradingLogTypeHandler),
+	}
+}
+
+// NewDefaultTradingLogTypeRegistry creates a registry with the built-in business logic types
+func NewDefaultTradingLogTypeRegistry() *TradingLogTypeRegistry {
+	registry := NewTradingLogTypeRegistry()
+	for _, handler := range []TradingLogTypeHandler{
+		longTradingLogType{},
+		shortTradingLogType{logType: "short"},
+		shortTradingLogType{logType: "stop_loss"},
+		depositTradingLogType{},
+		withdrawTradingLogType{},
+		transferTradingLogType{},
+		borrowTradingLogType{},
+		repayTradingLogType{},
+	} {
+		if err := registry.Register(handler); err != nil {
+			panic(err)
+		}
+	}
+	return registry
+}
+
+// Register adds a trading log type to the registry
+func (r *TradingLogTypeRegistry) Register(handler TradingLogTypeHandler) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	logType := handler.Type()
+	if logType == "" {
+		return fmt.Errorf("trading log type cannot be empty")
+	}
+	if _, exists := r.handlers[logType]; exists {
+		return fmt.Errorf("trading log type %s is already registered", logType)
+	}
+
+	r.handlers[logType] = handler
+	return nil
+}
+
+// Lookup returns the handler of a trading log type
+func (r *TradingLogTypeRegistry) Lookup(logType string) (TradingLogTypeHandler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	handler, exists := r.handlers[logType]
+	return handler, exists
+}
+
+// Types returns the registered trading log types in alphabetical order
+func (r *TradingLogTypeRegistry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.handlers))
+	for logType := range r.handlers {
+		types = append(types, logType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// getUserSubAccount loads a sub-account and verifies that it belongs to the user
+func (p *TradingLogProcessor) getUserSubAccount(ctx context.Context, userID, subAccountID uuid.UUID, name string) (*models.SubAccount, error) {
+	subAccount, err := p.repos.SubAccount.GetByID(ctx, subAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", name, err)
+	}
+	if subAccount == nil || subAccount.UserID != userID {
+		return nil, fmt.Errorf("%s not found", name)
+	}
+	return subAccount, nil
+}
+
+// getTradeAccounts loads the stock and currency accounts of a trade
+func (p *TradingLogProcessor) getTradeAccounts(ctx context.Context, input *TradingLogTypeInput) (*models.SubAccount, *models.SubAccount, error) {
+	stockAccount, err := p.getUserSubAccount(ctx, input.UserID, input.Info.StockAccountID, "stock account")
+	if err != nil {
+		return nil, nil, err
+	}
+	currencyAccount, err := p.getUserSubAccount(ctx, input.UserID, input.Info.CurrencyAccountID, "currency account")
+	if err != nil {
+		return nil, nil, err
+	}
+	return stockAccount, currencyAccount, nil
+}
+
+// longTradingLogType buys stock with currency
+type longTradingLogType struct{}
+
+func (longTradingLogType) Type() string {
+	return "long"
+}
+
+func (longTradingLogType) ValidateInfo(v *TradingLogValidator, info map[string]interface{}) (*TradingLogInfo, error) {
+	return v.validateTradeInfo(info, "long")
+}
+
+func (longTradingLogType) Process(ctx context.Context, p *TradingLogProcessor, input *TradingLogTypeInput) ([]*models.Transaction, []*models.SubAccount, error) {
+	stockAccount, currencyAccount, err := p.getTradeAccounts(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := p.checkMarginLevel(ctx, input, stockAccount, currencyAccount, false); err != nil {
+		return nil, nil, err
+	}
+
+	transactions, accounts, err := p.ProcessLongPosition(ctx, input.Trading.ID, input.Info, stockAccount, currencyAccount, input.TradingLogInfo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to process long position: %w", err)
+	}
+
+	// Persist the position of the stock account
+	if err := p.savePosition(ctx, input.Tx, stockAccount, transactions); err != nil {
+		return nil, nil, err
+	}
+
+	return transactions, accounts, nil
+}
+
+// shortTradingLogType sells stock for currency; stop_loss is a short with its own reason
+type shortTradingLogType struct {
+	logType string
+}
+
+func (t shortTradingLogType) Type() string {
+	return t.logType
+}
+
+func (t shortTradingLogType) ValidateInfo(v *TradingLogValidator, info map[string]interface{}) (*TradingLogInfo, error) {
+	return v.validateTradeInfo(info, t.logType)
+}
+
+func (t shortTradingLogType) Process(ctx context.Context, p *TradingLogProcessor, input *TradingLogTypeInput) ([]*models.Transaction, []*models.SubAccount, error) {
+	stockAccount, currencyAccount, err := p.getTradeAccounts(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := p.checkMarginLevel(ctx, input, stockAccount, currencyAccount, true); err != nil {
+		return nil, nil, err
+	}
+
+	transactions, accounts, err := p.ProcessShortPosition(ctx, input.Trading.ID, input.Info, stockAccount, currencyAccount, input.TradingLogInfo, t.logType)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to process %s position: %w", t.logType, err)
+	}
+
+	// Persist the position of the stock account
+	if err := p.savePosition(ctx, input.Tx, stockAccount, transactions); err != nil {
+		return nil, nil, err
+	}
+
+	return transactions, accounts, nil
+}
+
+// depositTradingLogType adds funds to a sub-account
+type depositTradingLogType struct{}
+
+func (depositTradingLogType) Type() string {
+	return "deposit"
+}
+
+func (depositTradingLogType) ValidateInfo(v *TradingLogValidator, info map[string]interface{}) (*TradingLogInfo, error) {
+	return v.validateDepositWithdrawInfo(info, "deposit")
+}
+
+func (depositTradingLogType) Process(ctx context.Context, p *TradingLogProcessor, input *TradingLogTypeInput) ([]*models.Transaction, []*models.SubAccount, error) {
+	targetAccount, err := p.getUserSubAccount(ctx, input.UserID, input.Info.StockAccountID, "stock account")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	transactions, accounts, err := p.ProcessDeposit(ctx, input.Tx, input.Info, targetAccount, input.TradingLogInfo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to process deposit: %w", err)
+	}
+	return transactions, accounts, nil
+}
+
+// withdrawTradingLogType takes funds out of a sub-account
+type withdrawTradingLogType struct{}
+
+func (withdrawTradingLogType) Type() string {
+	return "withdraw"
+}
+
+func (withdrawTradingLogType) ValidateInfo(v *TradingLogValidator, info map[string]interface{}) (*TradingLogInfo, error) {
+	return v.validateDepositWithdrawInfo(info, "withdraw")
+}
+
+func (withdrawTradingLogType) Process(ctx context.Context, p *TradingLogProcessor, input *TradingLogTypeInput) ([]*models.Transaction, []*models.SubAccount, error) {
+	sourceAccount, err := p.getUserSubAccount(ctx, input.UserID, input.Info.StockAccountID, "stock account")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	transactions, accounts, err := p.ProcessWithdraw(ctx, input.Info, sourceAccount, input.TradingLogInfo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to process withdraw: %w", err)
+	}
+	return transactions, accounts, nil
+}
+
+// transferTradingLogType moves funds between two sub-accounts of the same trading
+type transferTradingLogType struct{}
+
+func (transferTradingLogType) Type() string {
+	return "transfer"
+}
+
+func (transferTradingLogType) ValidateInfo(v *TradingLogValidator, info map[string]interface{}) (*TradingLogInfo, error) {
+	return v.validateTransferInfo(info, "transfer")
+}
+
+func (transferTradingLogType) Process(ctx context.Context, p *TradingLogProcessor, input *TradingLogTypeInput) ([]*models.Transaction, []*models.SubAccount, error) {
+	sourceAccount, err := p.getUserSubAccount(ctx, input.UserID, input.Info.StockAccountID, "source account")
+	if err != nil {
+		return nil, nil, err
+	}
+	targetAccount, err := p.getUserSubAccount(ctx, input.UserID, input.Info.CurrencyAccountID, "target account")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	transactions, accounts, err := p.ProcessTransfer(ctx, input.Request.TradingID, input.Info, sourceAccount, targetAccount, input.TradingLogInfo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to process transfer: %w", err)
+	}
+	return transactions, accounts, nil
+}
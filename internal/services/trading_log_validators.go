@@ -1,30 +1,47 @@
 package services
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"reflect"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
+// MaxDecimalPlaces is the precision of the decimal(20,8) columns that store financial values
+const MaxDecimalPlaces = 8
+
 // TradingLogInfo represents the structured info field for long, short, and stop_loss trading logs
 // @Description Required info structure for long, short, and stop_loss trading log types
 type TradingLogInfo struct {
-	StockAccountID    uuid.UUID `json:"stock_account_id" binding:"required" example:"eth-account-uuid" description:"Sub-account ID for the asset (e.g., ETH account)"`
-	CurrencyAccountID uuid.UUID `json:"currency_account_id" binding:"required" example:"usdt-account-uuid" description:"Sub-account ID for the currency (e.g., USDT account)"`
-	Price             float64   `json:"price" binding:"required,gt=0" example:"3000.00" description:"Price per unit (must be positive)"`
-	Volume            float64   `json:"volume" binding:"required,gt=0" example:"2.0" description:"Quantity traded (must be positive)"`
-	Stock             string    `json:"stock" binding:"required,min=1,max=20" example:"ETH" description:"Asset symbol for the trading pair"`
-	Currency          string    `json:"currency" binding:"required,min=1,max=20" example:"USDT" description:"Currency symbol for the trading pair"`
-	Fee               float64   `json:"fee" binding:"gte=0" example:"12.00" description:"Trading fee (must be non-negative)"`
+	StockAccountID    uuid.UUID       `json:"stock_account_id" binding:"required" example:"eth-account-uuid" description:"Sub-account ID for the asset (e.g., ETH account)"`
+	CurrencyAccountID uuid.UUID       `json:"currency_account_id" binding:"required" example:"usdt-account-uuid" description:"Sub-account ID for the currency (e.g., USDT account)"`
+	Price             decimal.Decimal `json:"price" binding:"required" swaggertype:"string" example:"3000.00" description:"Price per unit (must be positive)"`
+	Volume            decimal.Decimal `json:"volume" binding:"required" swaggertype:"string" example:"2.0" description:"Quantity traded (must be positive)"`
+	Stock             string          `json:"stock" binding:"required,min=1,max=20" example:"ETH" description:"Asset symbol for the trading pair"`
+	Currency          string          `json:"currency" binding:"required,min=1,max=20" example:"USDT" description:"Currency symbol for the trading pair"`
+	Fee               decimal.Decimal `json:"fee" swaggertype:"string" example:"12.00" description:"Trading fee (must be non-negative)"`
+	FeeAccountID      *uuid.UUID      `json:"fee_account_id,omitempty" example:"bnb-account-uuid" description:"Optional sub-account the fee is charged to as a separate fee transaction. Defaults to the currency account as part of the trade"`
+	FeeAsset          string          `json:"fee_asset,omitempty" example:"BNB" description:"Symbol the fee is charged in. Required with fee_account_id"`
 }
 
 // DepositWithdrawInfo represents the structured info field for deposit and withdraw trading logs
 // @Description Required info structure for deposit and withdraw trading log types
 type DepositWithdrawInfo struct {
-	AccountID uuid.UUID `json:"account_id" binding:"required" example:"usdt-account-uuid" description:"Target sub-account ID for the deposit/withdraw operation"`
-	Amount    float64   `json:"amount" binding:"required,gt=0" example:"1000.00" description:"Amount to deposit or withdraw (must be positive)"`
-	Currency  string    `json:"currency" binding:"required,min=1,max=20" example:"USDT" description:"Currency symbol for the operation"`
+	AccountID uuid.UUID       `json:"account_id" binding:"required" example:"usdt-account-uuid" description:"Target sub-account ID for the deposit/withdraw operation"`
+	Amount    decimal.Decimal `json:"amount" binding:"required" swaggertype:"string" example:"1000.00" description:"Amount to deposit or withdraw (must be positive)"`
+	Currency  string          `json:"currency" binding:"required,min=1,max=20" example:"USDT" description:"Currency symbol for the operation"`
+}
+
+// TransferInfo represents the structured info field for transfer trading logs
+// @Description Required info structure for the transfer trading log type
+type TransferInfo struct {
+	FromAccountID uuid.UUID       `json:"from_account_id" binding:"required" example:"reserve-usdt-account-uuid" description:"Sub-account ID the funds are taken from"`
+	ToAccountID   uuid.UUID       `json:"to_account_id" binding:"required" example:"active-usdt-account-uuid" description:"Sub-account ID the funds are moved to"`
+	Amount        decimal.Decimal `json:"amount" binding:"required" swaggertype:"string" example:"500.00" description:"Amount to transfer (must be positive)"`
+	Currency      string          `json:"currency" binding:"required,min=1,max=20" example:"USDT" description:"Symbol held by both sub-accounts"`
 }
 
 // ValidationError represents a trading log validation error
@@ -39,11 +56,18 @@ func (e *ValidationError) Error() string {
 }
 
 // TradingLogValidator handles validation of trading log data
-type TradingLogValidator struct{}
+type TradingLogValidator struct {
+	types *TradingLogTypeRegistry
+}
 
-// NewTradingLogValidator creates a new trading log validator
+// NewTradingLogValidator creates a new trading log validator for the built-in business logic types
 func NewTradingLogValidator() *TradingLogValidator {
-	return &TradingLogValidator{}
+	return NewTradingLogValidatorWithTypes(NewDefaultTradingLogTypeRegistry())
+}
+
+// NewTradingLogValidatorWithTypes creates a new trading log validator for the types in the given registry
+func NewTradingLogValidatorWithTypes(types *TradingLogTypeRegistry) *TradingLogValidator {
+	return &TradingLogValidator{types: types}
 }
 
 // ValidateType validates if the trading log type is supported for business logic processing
@@ -65,16 +89,17 @@ func (v *TradingLogValidator) ValidateType(logType string) error {
 // ValidateInfoStructure validates the info field structure for trading operations
 func (v *TradingLogValidator) ValidateInfoStructure(info map[string]interface{}, logType string) (*TradingLogInfo, error) {
 	// Check if this is a trading operation type that requires structured info
-	if !v.isBusinessLogicType(logType) {
+	handler, exists := v.types.Lookup(logType)
+	if !exists {
 		// For non-business logic types, we don't validate the structure
 		return nil, nil
 	}
 
-	// Handle deposit and withdraw types with simplified validation
-	if logType == "deposit" || logType == "withdraw" {
-		return v.validateDepositWithdrawInfo(info, logType)
-	}
+	return handler.ValidateInfo(v, info)
+}
 
+// validateTradeInfo validates info structure for long, short and stop_loss operations
+func (v *TradingLogValidator) validateTradeInfo(info map[string]interface{}, logType string) (*TradingLogInfo, error) {
 	// Extract and validate required fields
 	tradingInfo := &TradingLogInfo{}
 
@@ -134,7 +159,10 @@ func (v *TradingLogValidator) ValidateInfoStructure(info map[string]interface{},
 
 	// Validate price
 	if priceRaw, exists := info["price"]; exists {
-		if price, ok := v.extractFloat64(priceRaw); ok && price > 0 {
+		if price, ok := v.extractDecimal(priceRaw); ok && price.IsPositive() {
+			if err := v.ValidateDecimalPrecision(price, "price", MaxDecimalPlaces); err != nil {
+				return nil, err
+			}
 			tradingInfo.Price = price
 		} else {
 			return nil, &ValidationError{
@@ -153,7 +181,10 @@ func (v *TradingLogValidator) ValidateInfoStructure(info map[string]interface{},
 
 	// Validate volume
 	if volumeRaw, exists := info["volume"]; exists {
-		if volume, ok := v.extractFloat64(volumeRaw); ok && volume > 0 {
+		if volume, ok := v.extractDecimal(volumeRaw); ok && volume.IsPositive() {
+			if err := v.ValidateDecimalPrecision(volume, "volume", MaxDecimalPlaces); err != nil {
+				return nil, err
+			}
 			tradingInfo.Volume = volume
 		} else {
 			return nil, &ValidationError{
@@ -210,7 +241,10 @@ func (v *TradingLogValidator) ValidateInfoStructure(info map[string]interface{},
 
 	// Validate fee
 	if feeRaw, exists := info["fee"]; exists {
-		if fee, ok := v.extractFloat64(feeRaw); ok && fee >= 0 {
+		if fee, ok := v.extractDecimal(feeRaw); ok && !fee.IsNegative() {
+			if err := v.ValidateDecimalPrecision(fee, "fee", MaxDecimalPlaces); err != nil {
+				return nil, err
+			}
 			tradingInfo.Fee = fee
 		} else {
 			return nil, &ValidationError{
@@ -227,6 +261,54 @@ func (v *TradingLogValidator) ValidateInfoStructure(info map[string]interface{},
 		}
 	}
 
+	// Validate optional fee_account_id and fee_asset, which must be given together
+	if feeAccountIDRaw, exists := info["fee_account_id"]; exists && feeAccountIDRaw != nil {
+		feeAccountIDStr, ok := feeAccountIDRaw.(string)
+		if !ok {
+			return nil, &ValidationError{
+				Field:   "fee_account_id",
+				Message: "must be a string UUID",
+				Type:    logType,
+			}
+		}
+		feeAccountID, err := uuid.Parse(feeAccountIDStr)
+		if err != nil {
+			return nil, &ValidationError{
+				Field:   "fee_account_id",
+				Message: "must be a valid UUID",
+				Type:    logType,
+			}
+		}
+		tradingInfo.FeeAccountID = &feeAccountID
+	}
+
+	if feeAssetRaw, exists := info["fee_asset"]; exists && feeAssetRaw != nil {
+		if feeAsset, ok := feeAssetRaw.(string); ok && len(feeAsset) > 0 && len(feeAsset) <= 20 {
+			tradingInfo.FeeAsset = feeAsset
+		} else {
+			return nil, &ValidationError{
+				Field:   "fee_asset",
+				Message: "must be a non-empty string with maximum 20 characters",
+				Type:    logType,
+			}
+		}
+	}
+
+	if tradingInfo.FeeAccountID != nil && tradingInfo.FeeAsset == "" {
+		return nil, &ValidationError{
+			Field:   "fee_asset",
+			Message: "is required when fee_account_id is set",
+			Type:    logType,
+		}
+	}
+	if tradingInfo.FeeAccountID == nil && tradingInfo.FeeAsset != "" {
+		return nil, &ValidationError{
+			Field:   "fee_account_id",
+			Message: "is required when fee_asset is set",
+			Type:    logType,
+		}
+	}
+
 	// Additional validation: ensure stock and currency accounts are different
 	if tradingInfo.StockAccountID == tradingInfo.CurrencyAccountID {
 		return nil, &ValidationError{
@@ -275,7 +357,10 @@ func (v *TradingLogValidator) validateDepositWithdrawInfo(info map[string]interf
 
 	// Validate amount (maps to Volume for unified interface)
 	if amountRaw, exists := info["amount"]; exists {
-		if amount, ok := v.extractFloat64(amountRaw); ok && amount > 0 {
+		if amount, ok := v.extractDecimal(amountRaw); ok && amount.IsPositive() {
+			if err := v.ValidateDecimalPrecision(amount, "amount", MaxDecimalPlaces); err != nil {
+				return nil, err
+			}
 			tradingInfo.Volume = amount
 		} else {
 			return nil, &ValidationError{
@@ -312,85 +397,156 @@ func (v *TradingLogValidator) validateDepositWithdrawInfo(info map[string]interf
 	}
 
 	// Set default values for unused fields
-	tradingInfo.Price = 1.0                  // Not used in deposit/withdraw
-	tradingInfo.Fee = 0.0                    // No fees for deposit/withdraw
-	tradingInfo.Currency = ""                // Not used in deposit/withdraw
-	tradingInfo.CurrencyAccountID = uuid.Nil // Not used in deposit/withdraw
+	tradingInfo.Price = decimal.NewFromInt(1) // Not used in deposit/withdraw
+	tradingInfo.Fee = decimal.Zero            // No fees for deposit/withdraw
+	tradingInfo.Currency = ""                 // Not used in deposit/withdraw
+	tradingInfo.CurrencyAccountID = uuid.Nil  // Not used in deposit/withdraw
 
 	return tradingInfo, nil
 }
 
-// ValidateDecimalPrecision ensures financial values have appropriate precision
-func (v *TradingLogValidator) ValidateDecimalPrecision(value float64, fieldName string, maxDecimals int) error {
-	// For financial validation, use a more practical approach
-	// Convert with the maximum precision we care about plus one to detect excess
-	formatStr := fmt.Sprintf("%%.%df", maxDecimals+3)
-	valueStr := fmt.Sprintf(formatStr, value)
-
-	// Find decimal point
-	decimalIndex := -1
-	for i, char := range valueStr {
-		if char == '.' {
-			decimalIndex = i
-			break
+// validateTransferInfo validates info structure for transfers between sub-accounts
+func (v *TradingLogValidator) validateTransferInfo(info map[string]interface{}, logType string) (*TradingLogInfo, error) {
+	// Transfers map onto the unified interface as from = StockAccountID, to = CurrencyAccountID
+	tradingInfo := &TradingLogInfo{}
+
+	accountFields := []struct {
+		field  string
+		target *uuid.UUID
+	}{
+		{"from_account_id", &tradingInfo.StockAccountID},
+		{"to_account_id", &tradingInfo.CurrencyAccountID},
+	}
+	for _, accountField := range accountFields {
+		accountIDRaw, exists := info[accountField.field]
+		if !exists {
+			return nil, &ValidationError{
+				Field:   accountField.field,
+				Message: "is required",
+				Type:    logType,
+			}
+		}
+		accountIDStr, ok := accountIDRaw.(string)
+		if !ok {
+			return nil, &ValidationError{
+				Field:   accountField.field,
+				Message: "must be a string UUID",
+				Type:    logType,
+			}
+		}
+		accountID, err := uuid.Parse(accountIDStr)
+		if err != nil {
+			return nil, &ValidationError{
+				Field:   accountField.field,
+				Message: "must be a valid UUID",
+				Type:    logType,
+			}
 		}
+		*accountField.target = accountID
 	}
 
-	if decimalIndex != -1 && decimalIndex < len(valueStr)-1 {
-		// Get the decimal part
-		decimalPart := valueStr[decimalIndex+1:]
-
-		// Remove trailing zeros
-		decimalPart = trimTrailingZeros(decimalPart)
-
-		// Count significant decimal places
-		decimals := len(decimalPart)
+	// Validate amount (maps to Volume for unified interface)
+	if amountRaw, exists := info["amount"]; exists {
+		if amount, ok := v.extractDecimal(amountRaw); ok && amount.IsPositive() {
+			if err := v.ValidateDecimalPrecision(amount, "amount", MaxDecimalPlaces); err != nil {
+				return nil, err
+			}
+			tradingInfo.Volume = amount
+		} else {
+			return nil, &ValidationError{
+				Field:   "amount",
+				Message: "must be a positive number",
+				Type:    logType,
+			}
+		}
+	} else {
+		return nil, &ValidationError{
+			Field:   "amount",
+			Message: "is required",
+			Type:    logType,
+		}
+	}
 
-		if decimals > maxDecimals {
-			return &ValidationError{
-				Field:   fieldName,
-				Message: fmt.Sprintf("must have at most %d decimal places", maxDecimals),
-				Type:    "precision",
+	// Validate currency
+	if currencyRaw, exists := info["currency"]; exists {
+		if currency, ok := currencyRaw.(string); ok && len(currency) > 0 && len(currency) <= 20 {
+			tradingInfo.Stock = currency // Use Stock field for the transferred symbol
+		} else {
+			return nil, &ValidationError{
+				Field:   "currency",
+				Message: "must be a non-empty string with maximum 20 characters",
+				Type:    logType,
 			}
 		}
+	} else {
+		return nil, &ValidationError{
+			Field:   "currency",
+			Message: "is required",
+			Type:    logType,
+		}
 	}
 
-	return nil
+	if tradingInfo.StockAccountID == tradingInfo.CurrencyAccountID {
+		return nil, &ValidationError{
+			Field:   "accounts",
+			Message: "from_account_id and to_account_id must be different",
+			Type:    logType,
+		}
+	}
+
+	// Transfers move funds at face value without fees
+	tradingInfo.Price = decimal.NewFromInt(1)
+	tradingInfo.Fee = decimal.Zero
+
+	return tradingInfo, nil
 }
 
-// trimTrailingZeros removes trailing zeros from a string
-func trimTrailingZeros(s string) string {
-	// Remove trailing zeros
-	for len(s) > 0 && s[len(s)-1] == '0' {
-		s = s[:len(s)-1]
+// ValidateDecimalPrecision ensures financial values have appropriate precision
+func (v *TradingLogValidator) ValidateDecimalPrecision(value decimal.Decimal, fieldName string, maxDecimals int) error {
+	// Truncating to the allowed number of places must not change the value
+	if !value.Equal(value.Truncate(int32(maxDecimals))) {
+		return &ValidationError{
+			Field:   fieldName,
+			Message: fmt.Sprintf("must have at most %d decimal places", maxDecimals),
+			Type:    "precision",
+		}
 	}
-	return s
+
+	return nil
 }
 
 // isBusinessLogicType checks if the type requires business logic processing
 func (v *TradingLogValidator) isBusinessLogicType(logType string) bool {
-	businessLogicTypes := map[string]bool{
-		"long":      true,
-		"short":     true,
-		"stop_loss": true,
-		"deposit":   true,
-		"withdraw":  true,
-	}
-	return businessLogicTypes[logType]
+	_, exists := v.types.Lookup(logType)
+	return exists
 }
 
-// extractFloat64 safely extracts float64 from interface{} with support for int and float types
-func (v *TradingLogValidator) extractFloat64(value interface{}) (float64, bool) {
+// extractDecimal safely extracts an exact decimal from interface{} with support for
+// numeric strings, json.Number, int and float types
+func (v *TradingLogValidator) extractDecimal(value interface{}) (decimal.Decimal, bool) {
+	switch typed := value.(type) {
+	case decimal.Decimal:
+		return typed, true
+	case string:
+		d, err := decimal.NewFromString(typed)
+		return d, err == nil
+	case json.Number:
+		d, err := decimal.NewFromString(typed.String())
+		return d, err == nil
+	}
+
 	val := reflect.ValueOf(value)
 
 	switch val.Kind() {
-	case reflect.Float32, reflect.Float64:
-		return val.Float(), true
+	case reflect.Float32:
+		return decimal.NewFromFloat32(float32(val.Float())), true
+	case reflect.Float64:
+		return decimal.NewFromFloat(val.Float()), true
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return float64(val.Int()), true
+		return decimal.NewFromInt(val.Int()), true
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return float64(val.Uint()), true
+		return decimal.NewFromBigInt(new(big.Int).SetUint64(val.Uint()), 0), true
 	default:
-		return 0, false
+		return decimal.Zero, false
 	}
 }
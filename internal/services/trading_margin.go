@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"tiris-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultCollateralRatio is the collateral ratio of a trading in margin mode unless configured
+var DefaultCollateralRatio = decimal.RequireFromString("1.5")
+
+// borrowTradingLogType records an amount borrowed from the exchange in margin mode. Borrowing does not
+// move funds: it lets the sub-account balance go down to minus the borrowed amount.
+type borrowTradingLogType struct{}
+
+func (borrowTradingLogType) Type() string {
+	return "borrow"
+}
+
+func (borrowTradingLogType) ValidateInfo(v *TradingLogValidator, info map[string]interface{}) (*TradingLogInfo, error) {
+	return v.validateDepositWithdrawInfo(info, "borrow")
+}
+
+func (borrowTradingLogType) Process(ctx context.Context, p *TradingLogProcessor, input *TradingLogTypeInput) ([]*models.Transaction, []*models.SubAccount, error) {
+	if input.Trading == nil || !input.Trading.MarginMode {
+		return nil, nil, fmt.Errorf("margin mode is not enabled for this trading")
+	}
+
+	account, err := p.lockBorrowingAccount(ctx, input.Tx, input.UserID, input.Info.StockAccountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	account.Borrowed = account.Borrowed.Add(input.Info.Volume)
+	if err := p.saveBorrowed(ctx, input.Tx, account); err != nil {
+		return nil, nil, err
+	}
+	return nil, []*models.SubAccount{account}, nil
+}
+
+// repayTradingLogType records an amount repaid to the exchange. Only what the balance no longer needs
+// can be repaid: the balance must stay at or above minus the remaining borrowed amount.
+type repayTradingLogType struct{}
+
+func (repayTradingLogType) Type() string {
+	return "repay"
+}
+
+func (repayTradingLogType) ValidateInfo(v *TradingLogValidator, info map[string]interface{}) (*TradingLogInfo, error) {
+	return v.validateDepositWithdrawInfo(info, "repay")
+}
+
+func (repayTradingLogType) Process(ctx context.Context, p *TradingLogProcessor, input *TradingLogTypeInput) ([]*models.Transaction, []*models.SubAccount, error) {
+	account, err := p.lockBorrowingAccount(ctx, input.Tx, input.UserID, input.Info.StockAccountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkReduceBorrowed(account, input.Info.Volume, "repay"); err != nil {
+		return nil, nil, err
+	}
+
+	account.Borrowed = account.Borrowed.Sub(input.Info.Volume)
+	if err := p.saveBorrowed(ctx, input.Tx, account); err != nil {
+		return nil, nil, err
+	}
+	return nil, []*models.SubAccount{account}, nil
+}
+
+// lockBorrowingAccount loads a sub-account of the user for update of its borrowed amount
+func (p *TradingLogProcessor) lockBorrowingAccount(ctx context.Context, tx *gorm.DB, userID, subAccountID uuid.UUID) (*models.SubAccount, error) {
+	var account models.SubAccount
+	err := tx.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", subAccountID).
+		First(&account).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("sub-account not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sub-account: %w", err)
+	}
+	if account.UserID != userID {
+		return nil, fmt.Errorf("sub-account not found")
+	}
+	return &account, nil
+}
+
+// saveBorrowed persists the borrowed amount of a sub-account
+func (p *TradingLogProcessor) saveBorrowed(ctx context.Context, tx *gorm.DB, account *models.SubAccount) error {
+	err := tx.WithContext(ctx).Model(&models.SubAccount{}).
+		Where("id = ?", account.ID).
+		Update("borrowed", account.Borrowed).Error
+	if err != nil {
+		return fmt.Errorf("failed to update borrowed amount of sub-account %s: %w", account.ID, err)
+	}
+	return nil
+}
+
+// checkReduceBorrowed verifies that the borrowed amount of an account can be reduced by amount
+func checkReduceBorrowed(account *models.SubAccount, amount decimal.Decimal, operation string) error {
+	if amount.GreaterThan(account.Borrowed) {
+		return fmt.Errorf("cannot %s %s: only %s borrowed", operation,
+			amount.StringFixed(MaxDecimalPlaces), account.Borrowed.StringFixed(MaxDecimalPlaces))
+	}
+	if shortfall := account.SpendableBalance().Sub(amount); shortfall.IsNegative() {
+		return fmt.Errorf("cannot %s %s: the balance still uses %s of the borrowed amount", operation,
+			amount.StringFixed(MaxDecimalPlaces), shortfall.Neg().StringFixed(MaxDecimalPlaces))
+	}
+	return nil
+}
+
+// reverseBorrowing undoes the change of the borrowed amount made by a borrow or repay log
+func (p *TradingLogProcessor) reverseBorrowing(ctx context.Context, tx *gorm.DB, tradingLog *models.TradingLog) (*models.SubAccount, error) {
+	tradingInfo, err := p.validator.ValidateInfoStructure(tradingLog.Info, tradingLog.Type)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trading log info: %w", err)
+	}
+
+	account, err := p.lockBorrowingAccount(ctx, tx, tradingLog.UserID, tradingInfo.StockAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tradingLog.Type == "borrow" {
+		if err := checkReduceBorrowed(account, tradingInfo.Volume, "reverse borrowing of"); err != nil {
+			return nil, fmt.Errorf("insufficient balance to reverse trading log in sub-account %s: %w", account.ID, err)
+		}
+		account.Borrowed = account.Borrowed.Sub(tradingInfo.Volume)
+	} else {
+		account.Borrowed = account.Borrowed.Add(tradingInfo.Volume)
+	}
+
+	if err := p.saveBorrowed(ctx, tx, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// checkMarginLevel refuses a trade of a trading in margin mode that would bring the margin level below
+// the collateral ratio of the trading. Balances are valued in the currency of the trade, the stock at
+// the trade price and other symbols at their latest trade price.
+func (p *TradingLogProcessor) checkMarginLevel(ctx context.Context, input *TradingLogTypeInput, stockAccount, currencyAccount *models.SubAccount, sell bool) error {
+	if input.Trading == nil || !input.Trading.MarginMode {
+		return nil
+	}
+
+	accounts, err := p.repos.SubAccount.GetByTradingID(ctx, input.Trading.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get sub-accounts: %w", err)
+	}
+
+	latestPrices, err := p.repos.Transaction.GetLatestPricesByTradingID(ctx, input.Trading.ID, currencyAccount.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to get prices: %w", err)
+	}
+	prices := make(map[string]decimal.Decimal, len(latestPrices)+2)
+	for _, price := range latestPrices {
+		prices[price.Symbol] = price.Price
+	}
+	prices[stockAccount.Symbol] = input.Info.Price
+	prices[currencyAccount.Symbol] = decimal.NewFromInt(1)
+
+	level, err := marginLevel(accounts, tradeBalanceChanges(input.Info, sell), prices)
+	if err != nil {
+		return err
+	}
+	if level != nil && level.LessThan(input.Trading.CollateralRatio) {
+		return fmt.Errorf("margin level %s would fall below the collateral ratio %s",
+			level.StringFixed(4), input.Trading.CollateralRatio.String())
+	}
+	return nil
+}
+
+// tradeBalanceChanges returns the change of each sub-account balance a trade will make
+func tradeBalanceChanges(tradingInfo *TradingLogInfo, sell bool) map[uuid.UUID]decimal.Decimal {
+	changes := make(map[uuid.UUID]decimal.Decimal)
+	value := tradingInfo.Price.Mul(tradingInfo.Volume).Round(MaxDecimalPlaces)
+	if sell {
+		changes[tradingInfo.StockAccountID] = changes[tradingInfo.StockAccountID].Sub(tradingInfo.Volume)
+		changes[tradingInfo.CurrencyAccountID] = changes[tradingInfo.CurrencyAccountID].Add(value)
+	} else {
+		changes[tradingInfo.StockAccountID] = changes[tradingInfo.StockAccountID].Add(tradingInfo.Volume)
+		changes[tradingInfo.CurrencyAccountID] = changes[tradingInfo.CurrencyAccountID].Sub(value)
+	}
+
+	feeAccountID := tradingInfo.CurrencyAccountID
+	if tradingInfo.FeeAccountID != nil {
+		feeAccountID = *tradingInfo.FeeAccountID
+	}
+	changes[feeAccountID] = changes[feeAccountID].Sub(tradingInfo.Fee)
+	return changes
+}
+
+// marginLevel returns the value of the positive balances divided by the value of the negative balances
+// of the sub-accounts once the changes are applied, or nil when no balance is negative. Positive
+// balances without a price are not counted as collateral; a negative balance without a price cannot be
+// valued and is refused.
+func marginLevel(accounts []*models.SubAccount, changes map[uuid.UUID]decimal.Decimal, prices map[string]decimal.Decimal) (*decimal.Decimal, error) {
+	collateral := decimal.Zero
+	liabilities := decimal.Zero
+	for _, account := range accounts {
+		balance := account.Balance.Add(changes[account.ID])
+		price, priced := prices[account.Symbol]
+		switch {
+		case balance.IsPositive() && priced:
+			collateral = collateral.Add(balance.Mul(price))
+		case balance.IsNegative() && !priced:
+			return nil, fmt.Errorf("cannot value the negative balance of sub-account %s: no price for %s", account.ID, account.Symbol)
+		case balance.IsNegative():
+			liabilities = liabilities.Add(balance.Neg().Mul(price))
+		}
+	}
+
+	if !liabilities.IsPositive() {
+		return nil, nil
+	}
+	level := collateral.DivRound(liabilities, MaxDecimalPlaces)
+	return &level, nil
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"testing"
+
+	"tiris-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarginLevel(t *testing.T) {
+	d := decimal.RequireFromString
+	ethAccount := &models.SubAccount{ID: uuid.New(), Symbol: "ETH", Balance: d("1")}
+	usdtAccount := &models.SubAccount{ID: uuid.New(), Symbol: "USDT", Balance: d("1000")}
+	bnbAccount := &models.SubAccount{ID: uuid.New(), Symbol: "BNB", Balance: d("5")}
+	accounts := []*models.SubAccount{ethAccount, usdtAccount, bnbAccount}
+	prices := map[string]decimal.Decimal{"ETH": d("3000"), "USDT": d("1")}
+
+	t.Run("no_negative_balance", func(t *testing.T) {
+		level, err := marginLevel(accounts, nil, prices)
+
+		require.NoError(t, err)
+		assert.Nil(t, level)
+	})
+
+	t.Run("short_sale", func(t *testing.T) {
+		// Selling 3 ETH at 3000 with 1 held leaves -2 ETH against 10000 USDT; unpriced BNB is not collateral
+		info := &TradingLogInfo{StockAccountID: ethAccount.ID, CurrencyAccountID: usdtAccount.ID, Price: d("3000"), Volume: d("3"), Fee: d("0")}
+
+		level, err := marginLevel(accounts, tradeBalanceChanges(info, true), prices)
+
+		require.NoError(t, err)
+		require.NotNil(t, level)
+		assert.True(t, level.Equal(d("1.66666667")), level.String())
+	})
+
+	t.Run("fee_charged_to_fee_account", func(t *testing.T) {
+		feeAccountID := usdtAccount.ID
+		info := &TradingLogInfo{StockAccountID: ethAccount.ID, CurrencyAccountID: usdtAccount.ID, FeeAccountID: &feeAccountID, Price: d("3000"), Volume: d("3"), Fee: d("1000")}
+
+		level, err := marginLevel(accounts, tradeBalanceChanges(info, true), prices)
+
+		require.NoError(t, err)
+		require.NotNil(t, level)
+		assert.True(t, level.Equal(d("1.5")), level.String())
+	})
+
+	t.Run("unpriced_negative_balance", func(t *testing.T) {
+		changes := map[uuid.UUID]decimal.Decimal{bnbAccount.ID: d("-6")}
+
+		level, err := marginLevel(accounts, changes, prices)
+
+		require.Error(t, err)
+		assert.Nil(t, level)
+		assert.Contains(t, err.Error(), "no price for BNB")
+	})
+}
+
+func TestCheckReduceBorrowed(t *testing.T) {
+	d := decimal.RequireFromString
+
+	t.Run("repay_unused_borrowing", func(t *testing.T) {
+		account := &models.SubAccount{Balance: d("0.5"), Borrowed: d("2")}
+		assert.NoError(t, checkReduceBorrowed(account, d("2"), "repay"))
+	})
+
+	t.Run("repay_more_than_borrowed", func(t *testing.T) {
+		account := &models.SubAccount{Balance: d("5"), Borrowed: d("2")}
+
+		err := checkReduceBorrowed(account, d("3"), "repay")
+
+		require.Error(t, err)
+		assert.Equal(t, "cannot repay 3.00000000: only 2.00000000 borrowed", err.Error())
+	})
+
+	t.Run("repay_while_short", func(t *testing.T) {
+		// 1.5 ETH are still sold short
+		account := &models.SubAccount{Balance: d("-1.5"), Borrowed: d("2")}
+
+		err := checkReduceBorrowed(account, d("1"), "repay")
+
+		require.Error(t, err)
+		assert.Equal(t, "cannot repay 1.00000000: the balance still uses 0.50000000 of the borrowed amount", err.Error())
+	})
+}
@@ -0,0 +1,387 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"tiris-backend/internal/models"
+	"tiris-backend/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// PerformanceCacheTTL is how long computed performance statistics are served from the cache
+const PerformanceCacheTTL = 5 * time.Minute
+
+// PerformanceRequest represents a performance statistics query
+type PerformanceRequest struct {
+	// From defaults to the creation of the trading, and To to now
+	From *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	// Quote is the currency the equity of the trading is valued in
+	Quote string `form:"quote" example:"USDT"`
+}
+
+// TradingPerformanceResponse represents the performance statistics of a trading. Returns are
+// time-weighted: deposits, withdrawals and balance adjustments move the equity but are not counted
+// as profit or loss.
+// Ratios are nil when there is not enough data to compute them.
+type TradingPerformanceResponse struct {
+	TradingID   uuid.UUID `json:"trading_id"`
+	QuoteSymbol string    `json:"quote_symbol" example:"USDT"`
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	// Interval is the resolution of the equity series the return statistics are computed from
+	Interval       string          `json:"interval" example:"1d"`
+	StartingEquity decimal.Decimal `json:"starting_equity" swaggertype:"string" example:"10000.00000000"`
+	EndingEquity   decimal.Decimal `json:"ending_equity" swaggertype:"string" example:"11250.00000000"`
+	NetDeposits    decimal.Decimal `json:"net_deposits" swaggertype:"string" example:"0.00000000"`
+	TotalReturn    decimal.Decimal `json:"total_return" swaggertype:"string" example:"0.12500000"`
+	MaxDrawdown    decimal.Decimal `json:"max_drawdown" swaggertype:"string" example:"0.04200000"`
+	// SharpeRatio and SortinoRatio are annualized with a risk-free rate of zero
+	SharpeRatio  *decimal.Decimal `json:"sharpe_ratio" swaggertype:"string" example:"1.85000000"`
+	SortinoRatio *decimal.Decimal `json:"sortino_ratio" swaggertype:"string" example:"2.60000000"`
+	RealizedPnL  decimal.Decimal  `json:"realized_pnl" swaggertype:"string" example:"1250.00000000"`
+	// RoundTrips counts the positions that were opened and closed back to a zero balance
+	RoundTrips   int              `json:"round_trips" example:"12"`
+	WinningTrips int              `json:"winning_trips" example:"8"`
+	LosingTrips  int              `json:"losing_trips" example:"4"`
+	WinRate      *decimal.Decimal `json:"win_rate" swaggertype:"string" example:"0.66666667"`
+	AverageWin   *decimal.Decimal `json:"average_win" swaggertype:"string" example:"250.00000000"`
+	AverageLoss  *decimal.Decimal `json:"average_loss" swaggertype:"string" example:"-187.50000000"`
+	ProfitFactor *decimal.Decimal `json:"profit_factor" swaggertype:"string" example:"2.66666667"`
+	// UnpricedSymbols lists the symbols held at the end without a trade price, left out of the equity
+	UnpricedSymbols []string `json:"unpriced_symbols,omitempty"`
+	CalculatedAt    string   `json:"calculated_at"`
+}
+
+// GetTradingPerformance returns the performance statistics of a trading between from and to. The
+// statistics are cached for PerformanceCacheTTL.
+func (s *TradingService) GetTradingPerformance(ctx context.Context, userID, tradingID uuid.UUID, req *PerformanceRequest) (*TradingPerformanceResponse, error) {
+	if req.Quote == "" {
+		return nil, fmt.Errorf("quote currency is required")
+	}
+
+	trading, err := s.repos.Trading.GetByID(ctx, tradingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trading: %w", err)
+	}
+	if trading == nil || trading.UserID != userID {
+		return nil, fmt.Errorf("trading not found")
+	}
+
+	now := time.Now()
+	cacheKey := performanceCacheKey(tradingID, req)
+	if cached := s.performanceCache.get(cacheKey, now); cached != nil {
+		return cached, nil
+	}
+
+	to := now
+	if req.To != nil {
+		to = *req.To
+	}
+	from := trading.CreatedAt
+	if req.From != nil {
+		from = *req.From
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+
+	response, err := s.computePerformance(ctx, tradingID, req.Quote, from, to)
+	if err != nil {
+		return nil, err
+	}
+	response.CalculatedAt = now.Format(time.RFC3339)
+
+	s.performanceCache.set(cacheKey, response, now)
+	return response, nil
+}
+
+// computePerformance computes the statistics of a trading from its equity series and trades
+func (s *TradingService) computePerformance(ctx context.Context, tradingID uuid.UUID, quoteSymbol string, from, to time.Time) (*TradingPerformanceResponse, error) {
+	interval, width, periodsPerYear := "1d", 24*time.Hour, 365.0
+	if len(bucketStarts(from, to, width)) > MaxEquityCurvePoints {
+		interval, width, periodsPerYear = "1w", 7*24*time.Hour, 52.0
+	}
+	// The bucket before from holds the opening equity
+	buckets := append([]time.Time{bucketStart(from, width).Add(-width)}, bucketStarts(from, to, width)...)
+
+	subAccounts, err := s.repos.SubAccount.GetByTradingID(ctx, tradingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sub-accounts: %w", err)
+	}
+	changes, err := s.repos.Transaction.GetBalanceChangesByTradingID(ctx, tradingID, width, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance changes: %w", err)
+	}
+	prices, err := s.repos.Transaction.GetBucketPricesByTradingID(ctx, tradingID, quoteSymbol, width, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prices: %w", err)
+	}
+	transactions, _, err := s.repos.Transaction.GetByTradingID(ctx, tradingID, repositories.TransactionFilters{
+		StartDate: &from,
+		EndDate:   &to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	var flowChanges []*repositories.BalanceChange
+	for _, change := range changes {
+		if isFlow(change) {
+			flowChanges = append(flowChanges, change)
+		}
+	}
+
+	series := priceSeries(quoteSymbol, prices, buckets)
+	equity := equityCurve(balanceCurves(subAccounts, changes, buckets), series, buckets)
+	flows := flowsPerBucket(balanceCurves(subAccounts, flowChanges, buckets), series)
+
+	response := &TradingPerformanceResponse{
+		TradingID:       tradingID,
+		QuoteSymbol:     quoteSymbol,
+		From:            from.Format(time.RFC3339),
+		To:              to.Format(time.RFC3339),
+		Interval:        interval,
+		StartingEquity:  equity[0].Equity,
+		EndingEquity:    equity[len(equity)-1].Equity,
+		NetDeposits:     decimal.Zero,
+		UnpricedSymbols: equity[len(equity)-1].UnpricedSymbols,
+	}
+	for _, flow := range flows {
+		response.NetDeposits = response.NetDeposits.Add(flow)
+	}
+
+	applyReturnStatistics(response, equity, flows, periodsPerYear)
+	applyRoundTripStatistics(response, transactions, quoteSymbol)
+	return response, nil
+}
+
+// flowReasons are the transaction reasons that move funds into or out of a trading instead of trading them
+var flowReasons = map[string]bool{"deposit": true, "withdraw": true, AdjustmentReason: true}
+
+// isFlow reports whether a balance change moves funds into or out of the trading. Taking back a
+// deposit or a withdrawal is a flow as well.
+func isFlow(change *repositories.BalanceChange) bool {
+	if change.Reason == ReversalReason {
+		return flowReasons[change.ReversedReason]
+	}
+	return flowReasons[change.Reason]
+}
+
+// flowsPerBucket values the deposits and withdrawals of each bucket at the prices of that bucket
+func flowsPerBucket(flowCurves []*SubAccountBalanceCurve, prices []map[string]decimal.Decimal) []decimal.Decimal {
+	flows := make([]decimal.Decimal, len(prices))
+	for i := range prices {
+		flows[i] = decimal.Zero
+		if i == 0 {
+			continue
+		}
+		for _, curve := range flowCurves {
+			price, ok := prices[i][curve.Symbol]
+			if !ok {
+				continue
+			}
+			moved := curve.Points[i].Balance.Sub(curve.Points[i-1].Balance)
+			flows[i] = flows[i].Add(moved.Mul(price).Round(MaxDecimalPlaces))
+		}
+	}
+	return flows
+}
+
+// applyReturnStatistics computes the time-weighted return, the maximum drawdown and the annualized
+// Sharpe and Sortino ratios from the equity at the end of each bucket, net of the flows into it
+func applyReturnStatistics(response *TradingPerformanceResponse, equity []*EquityPoint, flows []decimal.Decimal, periodsPerYear float64) {
+	var returns []float64
+	index, peak, maxDrawdown := 1.0, 1.0, 0.0
+	for i := 1; i < len(equity); i++ {
+		previous := equity[i-1].Equity
+		if !previous.IsPositive() {
+			continue
+		}
+		r := equity[i].Equity.Sub(flows[i]).Div(previous).InexactFloat64() - 1
+		returns = append(returns, r)
+
+		index *= 1 + r
+		peak = math.Max(peak, index)
+		maxDrawdown = math.Max(maxDrawdown, (peak-index)/peak)
+	}
+
+	response.TotalReturn = decimal.NewFromFloat(index - 1).Round(MaxDecimalPlaces)
+	response.MaxDrawdown = decimal.NewFromFloat(maxDrawdown).Round(MaxDecimalPlaces)
+	if len(returns) < 2 {
+		return
+	}
+
+	mean, downside := 0.0, 0.0
+	for _, r := range returns {
+		mean += r
+		if r < 0 {
+			downside += r * r
+		}
+	}
+	mean /= float64(len(returns))
+	downside = math.Sqrt(downside / float64(len(returns)))
+
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	deviation := math.Sqrt(variance / float64(len(returns)-1))
+
+	annualization := math.Sqrt(periodsPerYear)
+	if deviation > 0 {
+		sharpe := decimal.NewFromFloat(mean / deviation * annualization).Round(MaxDecimalPlaces)
+		response.SharpeRatio = &sharpe
+	}
+	if downside > 0 {
+		sortino := decimal.NewFromFloat(mean / downside * annualization).Round(MaxDecimalPlaces)
+		response.SortinoRatio = &sortino
+	}
+}
+
+// applyRoundTripStatistics computes the trade statistics from the transactions of long, short and
+// stop_loss logs that were not reversed. A round trip starts with a buy and ends with the sell that brings the position back
+// to zero; its profit is the realized PnL, net of fees, of its sells.
+func applyRoundTripStatistics(response *TradingPerformanceResponse, transactions []*models.Transaction, quoteSymbol string) {
+	// Transactions are returned newest first
+	ordered := make([]*models.Transaction, len(transactions))
+	copy(ordered, transactions)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Timestamp.Before(ordered[j].Timestamp) })
+
+	// The trades of deleted and amended logs were taken back by reversals
+	reversed := make(map[string]bool)
+	for _, transaction := range ordered {
+		if logID, ok := transaction.Info["reversal_of"].(string); ok && transaction.Reason == ReversalReason {
+			reversed[logID] = true
+		}
+	}
+
+	var trips []decimal.Decimal
+	open := make(map[uuid.UUID]decimal.Decimal)
+	response.RealizedPnL = decimal.Zero
+	for _, transaction := range ordered {
+		if transaction.Reason != "long" && transaction.Reason != "short" && transaction.Reason != "stop_loss" {
+			continue
+		}
+		if logID, ok := transaction.Info["id"].(string); ok && reversed[logID] {
+			continue
+		}
+		if transaction.QuoteSymbol != nil && *transaction.QuoteSymbol != quoteSymbol {
+			continue
+		}
+
+		if transaction.Reason == "long" && transaction.Direction == "credit" {
+			// A buy of the stock opens or adds to a position
+			if _, ok := open[transaction.SubAccountID]; !ok {
+				open[transaction.SubAccountID] = decimal.Zero
+			}
+			continue
+		}
+		if transaction.RealizedPnL == nil {
+			continue
+		}
+
+		// A sell of the stock
+		pnl := open[transaction.SubAccountID].Add(*transaction.RealizedPnL)
+		response.RealizedPnL = response.RealizedPnL.Add(*transaction.RealizedPnL)
+		if transaction.ClosingBalance.IsPositive() {
+			open[transaction.SubAccountID] = pnl
+			continue
+		}
+		trips = append(trips, pnl)
+		delete(open, transaction.SubAccountID)
+	}
+
+	grossProfit, grossLoss := decimal.Zero, decimal.Zero
+	for _, pnl := range trips {
+		switch {
+		case pnl.IsPositive():
+			response.WinningTrips++
+			grossProfit = grossProfit.Add(pnl)
+		case pnl.IsNegative():
+			response.LosingTrips++
+			grossLoss = grossLoss.Add(pnl)
+		}
+	}
+	response.RoundTrips = len(trips)
+
+	if response.RoundTrips > 0 {
+		winRate := decimal.NewFromInt(int64(response.WinningTrips)).DivRound(decimal.NewFromInt(int64(response.RoundTrips)), MaxDecimalPlaces)
+		response.WinRate = &winRate
+	}
+	if response.WinningTrips > 0 {
+		averageWin := grossProfit.DivRound(decimal.NewFromInt(int64(response.WinningTrips)), MaxDecimalPlaces)
+		response.AverageWin = &averageWin
+	}
+	if response.LosingTrips > 0 {
+		averageLoss := grossLoss.DivRound(decimal.NewFromInt(int64(response.LosingTrips)), MaxDecimalPlaces)
+		response.AverageLoss = &averageLoss
+		profitFactor := grossProfit.DivRound(grossLoss.Abs(), MaxDecimalPlaces)
+		response.ProfitFactor = &profitFactor
+	}
+}
+
+// performanceCache holds computed performance statistics until they expire
+type performanceCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[string]*performanceCacheEntry
+}
+
+type performanceCacheEntry struct {
+	response  *TradingPerformanceResponse
+	expiresAt time.Time
+}
+
+// newPerformanceCache creates a performance cache whose entries expire after ttl
+func newPerformanceCache(ttl time.Duration) *performanceCache {
+	return &performanceCache{
+		ttl:     ttl,
+		entries: make(map[string]*performanceCacheEntry),
+	}
+}
+
+// get returns the cached statistics for key, or nil when there are none or they expired
+func (c *performanceCache) get(key string, now time.Time) *TradingPerformanceResponse {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		return nil
+	}
+	return entry.response
+}
+
+// set caches the statistics for key and drops expired entries
+func (c *performanceCache) set(key string, response *TradingPerformanceResponse, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = &performanceCacheEntry{response: response, expiresAt: now.Add(c.ttl)}
+}
+
+// performanceCacheKey identifies a performance query. Open ends are kept open in the key so repeated
+// queries up to now share an entry.
+func performanceCacheKey(tradingID uuid.UUID, req *PerformanceRequest) string {
+	from, to := "", ""
+	if req.From != nil {
+		from = req.From.UTC().Format(time.RFC3339Nano)
+	}
+	if req.To != nil {
+		to = req.To.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("%s|%s|%s|%s", tradingID, req.Quote, from, to)
+}
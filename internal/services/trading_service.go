@@ -8,13 +8,14 @@ import (
 	"tiris-backend/internal/repositories"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
-
 // TradingService handles trading business logic
 type TradingService struct {
 	repos                  *repositories.Repositories
 	exchangeBindingService ExchangeBindingService
+	performanceCache       *performanceCache
 }
 
 // NewTradingService creates a new trading service
@@ -22,20 +23,24 @@ func NewTradingService(repos *repositories.Repositories, exchangeBindingService
 	return &TradingService{
 		repos:                  repos,
 		exchangeBindingService: exchangeBindingService,
+		performanceCache:       newPerformanceCache(PerformanceCacheTTL),
 	}
 }
 
 // TradingResponse represents trading information in responses
 type TradingResponse struct {
-	ID              uuid.UUID               `json:"id"`
-	UserID          uuid.UUID               `json:"user_id"`
-	Name            string                  `json:"name"`
-	Type            string                  `json:"type"`
-	ExchangeBinding *ExchangeBindingInfo    `json:"exchange_binding,omitempty"`
-	Status          string                  `json:"status"`
-	Info            map[string]interface{}  `json:"info"`
-	CreatedAt       string                  `json:"created_at"`
-	UpdatedAt       string                  `json:"updated_at"`
+	ID              uuid.UUID              `json:"id"`
+	UserID          uuid.UUID              `json:"user_id"`
+	Name            string                 `json:"name"`
+	Type            string                 `json:"type"`
+	ExchangeBinding *ExchangeBindingInfo   `json:"exchange_binding,omitempty"`
+	Status          string                 `json:"status"`
+	RealizedPnL     decimal.Decimal        `json:"realized_pnl" swaggertype:"string" example:"120.50"`
+	MarginMode      bool                   `json:"margin_mode"`
+	CollateralRatio decimal.Decimal        `json:"collateral_ratio" swaggertype:"string" example:"1.5"`
+	Info            map[string]interface{} `json:"info"`
+	CreatedAt       string                 `json:"created_at"`
+	UpdatedAt       string                 `json:"updated_at"`
 }
 
 // ExchangeBindingInfo represents exchange binding information in responses
@@ -50,15 +55,24 @@ type ExchangeBindingInfo struct {
 // CreateTradingRequest represents trading creation request
 type CreateTradingRequest struct {
 	Name              string    `json:"name" binding:"required,min=1,max=100" example:"My Trading Account"`
-	Type              string    `json:"type" binding:"required" example:"real"`
+	Type              string    `json:"type" binding:"required,oneof=real simulation backtest" example:"real"`
 	ExchangeBindingID uuid.UUID `json:"exchange_binding_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// MarginMode lets sub-accounts borrow and sell short
+	MarginMode bool `json:"margin_mode,omitempty" example:"false"`
+	// CollateralRatio is the minimum margin level in margin mode, 1.5 by default
+	CollateralRatio *decimal.Decimal `json:"collateral_ratio,omitempty" swaggertype:"string" example:"1.5"`
+	// VirtualExchange configures the fees and slippage of the virtual exchange (simulation and backtest only)
+	VirtualExchange *VirtualExchangeConfig `json:"virtual_exchange,omitempty"`
 }
 
 // UpdateTradingRequest represents trading update request
 type UpdateTradingRequest struct {
-	Name              *string    `json:"name,omitempty" binding:"omitempty,min=1,max=100" example:"My Updated Trading Account"`
-	ExchangeBindingID *uuid.UUID `json:"exchange_binding_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Status            *string    `json:"status,omitempty" binding:"omitempty,oneof=active inactive" example:"active"`
+	Name              *string                `json:"name,omitempty" binding:"omitempty,min=1,max=100" example:"My Updated Trading Account"`
+	ExchangeBindingID *uuid.UUID             `json:"exchange_binding_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Status            *string                `json:"status,omitempty" binding:"omitempty,oneof=active inactive" example:"active"`
+	MarginMode        *bool                  `json:"margin_mode,omitempty" example:"true"`
+	CollateralRatio   *decimal.Decimal       `json:"collateral_ratio,omitempty" swaggertype:"string" example:"1.5"`
+	VirtualExchange   *VirtualExchangeConfig `json:"virtual_exchange,omitempty"`
 }
 
 // CreateTrading creates a new trading configuration
@@ -71,12 +85,29 @@ func (s *TradingService) CreateTrading(ctx context.Context, userID uuid.UUID, re
 	if !hasAccess {
 		return nil, fmt.Errorf("access denied to exchange binding")
 	}
+	if err := s.checkTradingBinding(ctx, req.Type, req.ExchangeBindingID); err != nil {
+		return nil, err
+	}
+
+	collateralRatio := DefaultCollateralRatio
+	if req.CollateralRatio != nil {
+		if err := validateCollateralRatio(*req.CollateralRatio); err != nil {
+			return nil, err
+		}
+		collateralRatio = *req.CollateralRatio
+	}
 
 	// Create info map with metadata
 	infoMap := map[string]interface{}{
 		"created_by":  "api",
 		"api_version": "v1",
 	}
+	if req.VirtualExchange != nil {
+		if err := validateVirtualExchangeConfig(req.Type, req.VirtualExchange); err != nil {
+			return nil, err
+		}
+		infoMap[VirtualExchangeInfoKey] = req.VirtualExchange.ToInfo()
+	}
 
 	// Create trading model
 	trading := &models.Trading{
@@ -86,6 +117,8 @@ func (s *TradingService) CreateTrading(ctx context.Context, userID uuid.UUID, re
 		Type:              req.Type,
 		ExchangeBindingID: req.ExchangeBindingID,
 		Status:            "active", // Default to active
+		MarginMode:        req.MarginMode,
+		CollateralRatio:   collateralRatio,
 		Info:              models.JSON(infoMap),
 	}
 
@@ -108,12 +141,18 @@ func (s *TradingService) GetUserTradings(ctx context.Context, userID uuid.UUID)
 		return nil, fmt.Errorf("failed to get user tradings: %w", err)
 	}
 
+	realizedPnL, err := s.realizedPnLByTrading(ctx, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	var responses []*TradingResponse
 	for _, trading := range tradings {
 		resp, err := s.convertToTradingResponse(ctx, trading)
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert trading response: %w", err)
 		}
+		resp.RealizedPnL = realizedPnL[trading.ID]
 		responses = append(responses, resp)
 	}
 
@@ -135,7 +174,18 @@ func (s *TradingService) GetTrading(ctx context.Context, userID, tradingID uuid.
 		return nil, fmt.Errorf("trading not found")
 	}
 
-	return s.convertToTradingResponse(ctx, trading)
+	resp, err := s.convertToTradingResponse(ctx, trading)
+	if err != nil {
+		return nil, err
+	}
+
+	realizedPnL, err := s.realizedPnLByTrading(ctx, userID, &tradingID)
+	if err != nil {
+		return nil, err
+	}
+	resp.RealizedPnL = realizedPnL[tradingID]
+
+	return resp, nil
 }
 
 // UpdateTrading updates an existing trading
@@ -168,6 +218,9 @@ func (s *TradingService) UpdateTrading(ctx context.Context, userID, tradingID uu
 		if !hasAccess {
 			return nil, fmt.Errorf("access denied to exchange binding")
 		}
+		if err := s.checkTradingBinding(ctx, trading.Type, *req.ExchangeBindingID); err != nil {
+			return nil, err
+		}
 		trading.ExchangeBindingID = *req.ExchangeBindingID
 	}
 
@@ -175,6 +228,34 @@ func (s *TradingService) UpdateTrading(ctx context.Context, userID, tradingID uu
 		trading.Status = *req.Status
 	}
 
+	if req.CollateralRatio != nil {
+		if err := validateCollateralRatio(*req.CollateralRatio); err != nil {
+			return nil, err
+		}
+		trading.CollateralRatio = *req.CollateralRatio
+	}
+
+	if req.MarginMode != nil {
+		if trading.MarginMode && !*req.MarginMode {
+			if err := s.checkNoBorrowing(ctx, tradingID); err != nil {
+				return nil, err
+			}
+		}
+		trading.MarginMode = *req.MarginMode
+	}
+
+	if req.VirtualExchange != nil {
+		if err := validateVirtualExchangeConfig(trading.Type, req.VirtualExchange); err != nil {
+			return nil, err
+		}
+		info := models.JSON{}
+		for key, value := range trading.Info {
+			info[key] = value
+		}
+		info[VirtualExchangeInfoKey] = req.VirtualExchange.ToInfo()
+		trading.Info = info
+	}
+
 	// Save updated trading
 	if err := s.repos.Trading.Update(ctx, trading); err != nil {
 		// Check for specific constraint violations and provide user-friendly messages
@@ -242,6 +323,71 @@ func (s *TradingService) GetTradingByID(ctx context.Context, tradingID uuid.UUID
 	return s.convertToTradingResponse(ctx, trading)
 }
 
+// validateCollateralRatio checks that a collateral ratio keeps positions covered by their collateral
+func validateCollateralRatio(ratio decimal.Decimal) error {
+	if ratio.LessThan(decimal.NewFromInt(1)) {
+		return fmt.Errorf("collateral ratio must be at least 1")
+	}
+	return nil
+}
+
+// validateVirtualExchangeConfig checks the virtual exchange configuration of a trading of the given type
+func validateVirtualExchangeConfig(tradingType string, config *VirtualExchangeConfig) error {
+	if !models.IsSimulatedTradingType(tradingType) {
+		return fmt.Errorf("virtual exchange settings require a simulation or backtest trading")
+	}
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid virtual exchange configuration: %w", err)
+	}
+	return nil
+}
+
+// checkTradingBinding makes sure simulation and backtest tradings run on a virtual exchange binding
+func (s *TradingService) checkTradingBinding(ctx context.Context, tradingType string, bindingID uuid.UUID) error {
+	if !models.IsSimulatedTradingType(tradingType) {
+		return nil
+	}
+
+	binding, err := s.exchangeBindingService.GetExchangeBinding(ctx, bindingID)
+	if err != nil {
+		return fmt.Errorf("failed to get exchange binding: %w", err)
+	}
+	if !binding.IsVirtual() {
+		return fmt.Errorf("simulation and backtest tradings must use a virtual exchange binding")
+	}
+
+	return nil
+}
+
+// checkNoBorrowing refuses to leave margin mode while a sub-account of the trading borrows or is short
+func (s *TradingService) checkNoBorrowing(ctx context.Context, tradingID uuid.UUID) error {
+	subAccounts, err := s.repos.SubAccount.GetByTradingID(ctx, tradingID)
+	if err != nil {
+		return fmt.Errorf("failed to check sub-accounts: %w", err)
+	}
+	for _, subAccount := range subAccounts {
+		if subAccount.Borrowed.IsPositive() || subAccount.Balance.IsNegative() {
+			return fmt.Errorf("cannot disable margin mode with borrowed or negative balances")
+		}
+	}
+	return nil
+}
+
+// realizedPnLByTrading sums the realized PnL of the user's sub-accounts per trading
+func (s *TradingService) realizedPnLByTrading(ctx context.Context, userID uuid.UUID, tradingID *uuid.UUID) (map[uuid.UUID]decimal.Decimal, error) {
+	subAccounts, err := s.repos.SubAccount.GetByUserID(ctx, userID, tradingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sub-accounts: %w", err)
+	}
+
+	totals := make(map[uuid.UUID]decimal.Decimal)
+	for _, subAccount := range subAccounts {
+		totals[subAccount.TradingID] = totals[subAccount.TradingID].Add(subAccount.RealizedPnL)
+	}
+
+	return totals, nil
+}
+
 // convertToTradingResponse converts a trading model to response format
 func (s *TradingService) convertToTradingResponse(ctx context.Context, trading *models.Trading) (*TradingResponse, error) {
 	var info map[string]interface{}
@@ -252,14 +398,16 @@ func (s *TradingService) convertToTradingResponse(ctx context.Context, trading *
 	}
 
 	resp := &TradingResponse{
-		ID:        trading.ID,
-		UserID:    trading.UserID,
-		Name:      trading.Name,
-		Type:      trading.Type,
-		Status:    trading.Status,
-		Info:      info,
-		CreatedAt: trading.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: trading.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:              trading.ID,
+		UserID:          trading.UserID,
+		Name:            trading.Name,
+		Type:            trading.Type,
+		Status:          trading.Status,
+		MarginMode:      trading.MarginMode,
+		CollateralRatio: trading.CollateralRatio,
+		Info:            info,
+		CreatedAt:       trading.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:       trading.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
 
 	// Include exchange binding information if available (check if ID is not zero)
@@ -278,4 +426,4 @@ func (s *TradingService) convertToTradingResponse(ctx context.Context, trading *
 	}
 
 	return resp, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"tiris-backend/internal/models"
+	"tiris-backend/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Price sources of an asset valuation
+const (
+	PriceSourceQuote       = "quote"
+	PriceSourceSupplied    = "supplied"
+	PriceSourceTransaction = "transaction"
+)
+
+// AssetValuation represents the value of the sub-accounts of a trading holding one symbol
+type AssetValuation struct {
+	Symbol  string          `json:"symbol" example:"ETH"`
+	Balance decimal.Decimal `json:"balance" swaggertype:"string" example:"2.50000000"`
+	// Price, Value and PriceSource are empty when no price is known for the symbol
+	Price       *decimal.Decimal `json:"price,omitempty" swaggertype:"string" example:"3000.00000000"`
+	Value       *decimal.Decimal `json:"value,omitempty" swaggertype:"string" example:"7500.00000000"`
+	PriceSource string           `json:"price_source,omitempty" example:"transaction"`
+	PriceAt     *string          `json:"price_at,omitempty"`
+	// TransactionID is the trade the price was taken from, for transaction prices
+	TransactionID *uuid.UUID `json:"transaction_id,omitempty"`
+}
+
+// TradingValuationResponse represents the value of a trading in a quote currency
+type TradingValuationResponse struct {
+	TradingID   uuid.UUID         `json:"trading_id"`
+	QuoteSymbol string            `json:"quote_symbol" example:"USDT"`
+	Assets      []*AssetValuation `json:"assets"`
+	TotalEquity decimal.Decimal   `json:"total_equity" swaggertype:"string" example:"10000.00000000"`
+	// PricesAt is the time of the oldest price used
+	PricesAt string `json:"prices_at"`
+	// UnpricedSymbols lists the symbols with a balance that could not be valued and are left out of the total equity
+	UnpricedSymbols []string `json:"unpriced_symbols"`
+}
+
+// GetTradingValuation values every sub-account of a trading in the quote currency. Supplied prices
+// take precedence; other symbols are valued at their most recent trade price against the quote
+// currency, and the quote currency itself at 1.
+func (s *TradingService) GetTradingValuation(ctx context.Context, userID, tradingID uuid.UUID, quoteSymbol string, prices map[string]decimal.Decimal) (*TradingValuationResponse, error) {
+	if quoteSymbol == "" {
+		return nil, fmt.Errorf("quote currency is required")
+	}
+	for symbol, price := range prices {
+		if !price.IsPositive() {
+			return nil, fmt.Errorf("price of %s must be positive", symbol)
+		}
+	}
+
+	trading, err := s.repos.Trading.GetByID(ctx, tradingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trading: %w", err)
+	}
+	if trading == nil || trading.UserID != userID {
+		return nil, fmt.Errorf("trading not found")
+	}
+
+	subAccounts, err := s.repos.SubAccount.GetByTradingID(ctx, tradingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sub-accounts: %w", err)
+	}
+
+	latestPrices, err := s.repos.Transaction.GetLatestPricesByTradingID(ctx, tradingID, quoteSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prices: %w", err)
+	}
+
+	return valueTrading(tradingID, quoteSymbol, subAccounts, prices, latestPrices, time.Now()), nil
+}
+
+// valueTrading builds the valuation of a trading's sub-accounts from the prices known at now
+func valueTrading(tradingID uuid.UUID, quoteSymbol string, subAccounts []*models.SubAccount, supplied map[string]decimal.Decimal, latestPrices []*repositories.SymbolPrice, now time.Time) *TradingValuationResponse {
+	traded := make(map[string]*repositories.SymbolPrice)
+	for _, price := range latestPrices {
+		traded[price.Symbol] = price
+	}
+
+	assets := make(map[string]*AssetValuation)
+	for _, subAccount := range subAccounts {
+		asset, ok := assets[subAccount.Symbol]
+		if !ok {
+			asset = &AssetValuation{Symbol: subAccount.Symbol, Balance: decimal.Zero}
+			assets[subAccount.Symbol] = asset
+		}
+		asset.Balance = asset.Balance.Add(subAccount.Balance)
+	}
+
+	response := &TradingValuationResponse{
+		TradingID:       tradingID,
+		QuoteSymbol:     quoteSymbol,
+		Assets:          []*AssetValuation{},
+		TotalEquity:     decimal.Zero,
+		UnpricedSymbols: []string{},
+	}
+
+	var pricesAt *time.Time
+	for _, asset := range assets {
+		var price decimal.Decimal
+		var priceAt time.Time
+
+		if suppliedPrice, ok := supplied[asset.Symbol]; ok {
+			price, priceAt, asset.PriceSource = suppliedPrice, now, PriceSourceSupplied
+		} else if asset.Symbol == quoteSymbol {
+			price, priceAt, asset.PriceSource = decimal.NewFromInt(1), now, PriceSourceQuote
+		} else if tradePrice, ok := traded[asset.Symbol]; ok {
+			price, priceAt, asset.PriceSource = tradePrice.Price, tradePrice.Timestamp, PriceSourceTransaction
+			asset.TransactionID = &tradePrice.TransactionID
+		} else {
+			if !asset.Balance.IsZero() {
+				response.UnpricedSymbols = append(response.UnpricedSymbols, asset.Symbol)
+			}
+			response.Assets = append(response.Assets, asset)
+			continue
+		}
+
+		value := asset.Balance.Mul(price).Round(MaxDecimalPlaces)
+		formatted := priceAt.Format(time.RFC3339)
+		asset.Price = &price
+		asset.Value = &value
+		asset.PriceAt = &formatted
+		response.TotalEquity = response.TotalEquity.Add(value)
+		if pricesAt == nil || priceAt.Before(*pricesAt) {
+			pricesAt = &priceAt
+		}
+		response.Assets = append(response.Assets, asset)
+	}
+
+	sort.Slice(response.Assets, func(i, j int) bool { return response.Assets[i].Symbol < response.Assets[j].Symbol })
+	sort.Strings(response.UnpricedSymbols)
+
+	if pricesAt == nil {
+		pricesAt = &now
+	}
+	response.PricesAt = pricesAt.Format(time.RFC3339)
+	return response
+}
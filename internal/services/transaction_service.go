@@ -9,6 +9,7 @@ import (
 	"tiris-backend/internal/repositories"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // TransactionService handles transaction query business logic
@@ -32,23 +33,24 @@ type TransactionResponse struct {
 	Timestamp      string                 `json:"timestamp"`
 	Direction      string                 `json:"direction"`
 	Reason         string                 `json:"reason"`
-	Amount         float64                `json:"amount"`
-	ClosingBalance float64                `json:"closing_balance"`
-	Price          *float64               `json:"price,omitempty"`
+	Amount         decimal.Decimal        `json:"amount" swaggertype:"string"`
+	ClosingBalance decimal.Decimal        `json:"closing_balance" swaggertype:"string"`
+	Price          *decimal.Decimal       `json:"price,omitempty" swaggertype:"string"`
 	QuoteSymbol    *string                `json:"quote_symbol,omitempty"`
+	RealizedPnL    *decimal.Decimal       `json:"realized_pnl,omitempty" swaggertype:"string"`
 	Info           map[string]interface{} `json:"info"`
 }
 
 // TransactionQueryRequest represents transaction query parameters
 type TransactionQueryRequest struct {
-	Direction *string    `form:"direction" binding:"omitempty,oneof=debit credit"`
-	Reason    *string    `form:"reason"`
-	StartDate *time.Time `form:"start_date" time_format:"2006-01-02T15:04:05Z07:00"`
-	EndDate   *time.Time `form:"end_date" time_format:"2006-01-02T15:04:05Z07:00"`
-	MinAmount *float64   `form:"min_amount" binding:"omitempty,min=0"`
-	MaxAmount *float64   `form:"max_amount" binding:"omitempty,min=0"`
-	Limit     int        `form:"limit" binding:"omitempty,min=1,max=1000"`
-	Offset    int        `form:"offset" binding:"omitempty,min=0"`
+	Direction *string          `form:"direction" binding:"omitempty,oneof=debit credit"`
+	Reason    *string          `form:"reason"`
+	StartDate *time.Time       `form:"start_date" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndDate   *time.Time       `form:"end_date" time_format:"2006-01-02T15:04:05Z07:00"`
+	MinAmount *decimal.Decimal `form:"min_amount"`
+	MaxAmount *decimal.Decimal `form:"max_amount"`
+	Limit     int              `form:"limit" binding:"omitempty,min=1,max=1000"`
+	Offset    int              `form:"offset" binding:"omitempty,min=0"`
 }
 
 // TransactionQueryResponse represents paginated transaction results
@@ -73,8 +75,8 @@ func (s *TransactionService) GetUserTransactions(ctx context.Context, userID uui
 	}
 
 	// Validate amount range
-	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
-		return nil, fmt.Errorf("min amount cannot be greater than max amount")
+	if err := validateAmountRange(req.MinAmount, req.MaxAmount); err != nil {
+		return nil, err
 	}
 
 	// Create filters
@@ -130,8 +132,8 @@ func (s *TransactionService) GetSubAccountTransactions(ctx context.Context, user
 	if req.StartDate != nil && req.EndDate != nil && req.StartDate.After(*req.EndDate) {
 		return nil, fmt.Errorf("start date cannot be after end date")
 	}
-	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
-		return nil, fmt.Errorf("min amount cannot be greater than max amount")
+	if err := validateAmountRange(req.MinAmount, req.MaxAmount); err != nil {
+		return nil, err
 	}
 
 	// Create filters
@@ -187,8 +189,8 @@ func (s *TransactionService) GetTradingTransactions(ctx context.Context, userID,
 	if req.StartDate != nil && req.EndDate != nil && req.StartDate.After(*req.EndDate) {
 		return nil, fmt.Errorf("start date cannot be after end date")
 	}
-	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
-		return nil, fmt.Errorf("min amount cannot be greater than max amount")
+	if err := validateAmountRange(req.MinAmount, req.MaxAmount); err != nil {
+		return nil, err
 	}
 
 	// Create filters
@@ -255,8 +257,8 @@ func (s *TransactionService) GetTransactionsByTimeRange(ctx context.Context, use
 	}
 
 	// Validate amount range
-	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
-		return nil, fmt.Errorf("min amount cannot be greater than max amount")
+	if err := validateAmountRange(req.MinAmount, req.MaxAmount); err != nil {
+		return nil, err
 	}
 
 	// Create filters (override date filters with provided time range)
@@ -314,8 +316,8 @@ func (s *TransactionService) ListAllTransactions(ctx context.Context, req *Trans
 	if req.StartDate != nil && req.EndDate != nil && req.StartDate.After(*req.EndDate) {
 		return nil, fmt.Errorf("start date cannot be after end date")
 	}
-	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
-		return nil, fmt.Errorf("min amount cannot be greater than max amount")
+	if err := validateAmountRange(req.MinAmount, req.MaxAmount); err != nil {
+		return nil, err
 	}
 
 	// For admin queries, we'll use a time range approach to get all transactions
@@ -397,6 +399,18 @@ func (s *TransactionService) convertToTransactionResponse(transaction *models.Tr
 		ClosingBalance: transaction.ClosingBalance,
 		Price:          transaction.Price,
 		QuoteSymbol:    transaction.QuoteSymbol,
+		RealizedPnL:    transaction.RealizedPnL,
 		Info:           info,
 	}
 }
+
+// validateAmountRange validates optional min/max amount filters
+func validateAmountRange(minAmount, maxAmount *decimal.Decimal) error {
+	if (minAmount != nil && minAmount.IsNegative()) || (maxAmount != nil && maxAmount.IsNegative()) {
+		return fmt.Errorf("amount filters cannot be negative")
+	}
+	if minAmount != nil && maxAmount != nil && minAmount.GreaterThan(*maxAmount) {
+		return fmt.Errorf("min amount cannot be greater than max amount")
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"tiris-backend/internal/repositories"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // UserService handles user business logic
@@ -167,10 +168,21 @@ func (s *UserService) GetUserStats(ctx context.Context, userID uuid.UUID) (map[s
 		return nil, fmt.Errorf("failed to get user sub-accounts: %w", err)
 	}
 
-	// Calculate total balance across all sub-accounts
-	var totalBalance float64
+	// Simulation and backtest tradings do not hold real money
+	simulatedTradings := make(map[uuid.UUID]bool)
+	for _, trading := range tradings {
+		if trading.IsSimulated() {
+			simulatedTradings[trading.ID] = true
+		}
+	}
+
+	// Calculate total balance across the sub-accounts of real tradings
+	totalBalance := decimal.Zero
 	for _, subAccount := range subAccounts {
-		totalBalance += subAccount.Balance
+		if simulatedTradings[subAccount.TradingID] {
+			continue
+		}
+		totalBalance = totalBalance.Add(subAccount.Balance)
 	}
 
 	// Get recent transaction count (last 30 days)
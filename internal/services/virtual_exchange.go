@@ -0,0 +1,398 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"tiris-backend/internal/models"
+	"tiris-backend/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// VirtualExchangeService runs the paper trading exchange of simulation and backtest tradings. Orders
+// rest until a price tick of their symbol is pushed for the trading; the tick fills them with the fee
+// and slippage models of the trading, and every fill moves the balances through a long (buy) or short
+// (sell) trading log.
+type VirtualExchangeService struct {
+	repos     *repositories.Repositories
+	db        *gorm.DB
+	processor *TradingLogProcessor
+	orders    *OrderService
+}
+
+// NewVirtualExchangeService creates a new virtual exchange service
+func NewVirtualExchangeService(repos *repositories.Repositories, db *gorm.DB) *VirtualExchangeService {
+	return &VirtualExchangeService{
+		repos:     repos,
+		db:        db,
+		processor: NewTradingLogProcessor(repos),
+		orders:    NewOrderService(repos),
+	}
+}
+
+// PlaceVirtualOrderRequest represents an order placed on the virtual exchange. The order trades the
+// symbol of the stock account against the symbol of the currency account.
+type PlaceVirtualOrderRequest struct {
+	StockAccountID    uuid.UUID        `json:"stock_account_id" binding:"required" example:"b4e006d0-1069-4ef4-b33f-7690af4929f4"`
+	CurrencyAccountID uuid.UUID        `json:"currency_account_id" binding:"required" example:"0e5a8c1f-3f4c-4c43-9f8f-6c2b5e1f2a10"`
+	Side              string           `json:"side" binding:"required,oneof=buy sell" example:"buy"`
+	Type              string           `json:"type" binding:"required,oneof=market limit" example:"limit"`
+	Amount            decimal.Decimal  `json:"amount" swaggertype:"string" example:"0.5"`
+	Price             *decimal.Decimal `json:"price,omitempty" swaggertype:"string" example:"3000"`
+	// ClientOrderID is used as the order ID when given, so a bot can retry without placing the order twice
+	ClientOrderID string `json:"client_order_id,omitempty" binding:"omitempty,max=255" example:"bot-42-0001"`
+}
+
+// PriceTickRequest represents a price of the feed of a trading
+type PriceTickRequest struct {
+	Symbol string          `json:"symbol" binding:"required,max=50" example:"ETH/USDT"`
+	Price  decimal.Decimal `json:"price" swaggertype:"string" example:"3000.5"`
+	// Volume traded at the tick; when given, the fills of the tick never exceed it
+	Volume *decimal.Decimal `json:"volume,omitempty" swaggertype:"string" example:"12.5"`
+	// Timestamp of the tick, used as the event time of its fills (e.g. the historical time in a backtest)
+	Timestamp *time.Time `json:"timestamp,omitempty" example:"2024-01-15T10:30:00Z"`
+}
+
+// PriceTickResponse lists the orders a price tick filled or rejected
+type PriceTickResponse struct {
+	Symbol string           `json:"symbol" example:"ETH/USDT"`
+	Price  decimal.Decimal  `json:"price" swaggertype:"string" example:"3000.5"`
+	Orders []*OrderResponse `json:"orders"`
+}
+
+// PlaceOrder places a market or limit order on the virtual exchange. The order is open until the next
+// price tick of its symbol; funds are checked when it fills.
+func (s *VirtualExchangeService) PlaceOrder(ctx context.Context, userID, tradingID uuid.UUID, req *PlaceVirtualOrderRequest) (*OrderResponse, error) {
+	if _, err := s.getVirtualTrading(ctx, userID, tradingID); err != nil {
+		return nil, err
+	}
+	if err := validateVirtualOrder(req); err != nil {
+		return nil, err
+	}
+
+	stockAccount, err := s.getTradingSubAccount(ctx, userID, tradingID, req.StockAccountID, "stock account")
+	if err != nil {
+		return nil, err
+	}
+	currencyAccount, err := s.getTradingSubAccount(ctx, userID, tradingID, req.CurrencyAccountID, "currency account")
+	if err != nil {
+		return nil, err
+	}
+	if stockAccount.ID == currencyAccount.ID {
+		return nil, fmt.Errorf("stock and currency accounts must be different")
+	}
+
+	orderID := req.ClientOrderID
+	if orderID == "" {
+		orderID = uuid.New().String()
+	}
+	existing, err := s.repos.Order.GetByTradingIDAndOrderID(ctx, tradingID, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check order: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("order already exists")
+	}
+
+	order := &models.Order{
+		ID:           uuid.New(),
+		UserID:       userID,
+		TradingID:    tradingID,
+		SubAccountID: stockAccount.ID,
+		OrderID:      orderID,
+		Symbol:       stockAccount.Symbol + "/" + currencyAccount.Symbol,
+		Side:         req.Side,
+		Type:         req.Type,
+		Amount:       req.Amount,
+		Price:        req.Price,
+		Status:       models.OrderStatusOpen,
+		Info: models.JSON{
+			"exchange":            models.ExchangeVirtual,
+			"stock_account_id":    stockAccount.ID.String(),
+			"currency_account_id": currencyAccount.ID.String(),
+		},
+	}
+	if err := s.repos.Order.Create(ctx, order); err != nil {
+		return nil, fmt.Errorf("failed to create order: %w", err)
+	}
+
+	return s.orders.convertToOrderResponse(order), nil
+}
+
+// CancelOrder cancels an open or partially filled order of the virtual exchange
+func (s *VirtualExchangeService) CancelOrder(ctx context.Context, userID, tradingID uuid.UUID, orderID string) (*OrderResponse, error) {
+	if _, err := s.getVirtualTrading(ctx, userID, tradingID); err != nil {
+		return nil, err
+	}
+
+	order, err := s.repos.Order.GetByTradingIDAndOrderID(ctx, tradingID, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if order == nil {
+		return nil, fmt.Errorf("order not found")
+	}
+	if !order.CanTransitionTo(models.OrderStatusCancelled) {
+		return nil, fmt.Errorf("order is %s and cannot be cancelled", order.Status)
+	}
+
+	// Only the status is written, and only while the order is still open: a price tick may have filled
+	// it since it was read
+	cancelled, err := s.repos.Order.Cancel(ctx, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update order: %w", err)
+	}
+	if cancelled == nil {
+		current, err := s.repos.Order.GetByTradingIDAndOrderID(ctx, tradingID, orderID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get order: %w", err)
+		}
+		if current == nil {
+			return nil, fmt.Errorf("order not found")
+		}
+		return nil, fmt.Errorf("order is %s and cannot be cancelled", current.Status)
+	}
+
+	return s.orders.convertToOrderResponse(cancelled), nil
+}
+
+// PushPrice matches the open orders of a trading for the symbol of a price tick, oldest first. An order
+// whose fill the ledger rejects, e.g. for insufficient balance, fails (or is cancelled when already
+// partially filled) without affecting the other orders of the tick.
+func (s *VirtualExchangeService) PushPrice(ctx context.Context, userID, tradingID uuid.UUID, req *PriceTickRequest) (*PriceTickResponse, error) {
+	trading, err := s.getVirtualTrading(ctx, userID, tradingID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePriceTick(req); err != nil {
+		return nil, err
+	}
+
+	config, err := virtualExchangeConfigOf(trading)
+	if err != nil {
+		return nil, err
+	}
+	matcher := newVirtualMatcher(config)
+
+	response := &PriceTickResponse{
+		Symbol: req.Symbol,
+		Price:  req.Price,
+		Orders: make([]*OrderResponse, 0),
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The orders stay locked until their fills are written
+		repos := repositories.NewRepositories(tx)
+		processor := s.processor.withRepositories(repos)
+
+		orders, err := repos.Order.GetOpenForUpdate(ctx, tradingID, req.Symbol)
+		if err != nil {
+			return fmt.Errorf("failed to get open orders: %w", err)
+		}
+
+		liquidity := req.Volume
+		for _, order := range orders {
+			fill := matcher.match(order, req.Price, req.Volume, liquidity)
+
+			// From its first tick on, what is left of an order rests on the book
+			if !isRestingVirtualOrder(order) {
+				markVirtualOrderResting(order)
+				if fill == nil {
+					if err := repos.Order.Update(ctx, order); err != nil {
+						return fmt.Errorf("failed to update order: %w", err)
+					}
+				}
+			}
+			if fill == nil {
+				continue
+			}
+
+			filled, err := s.applyFill(ctx, tx, repos, processor, userID, order, fill, req.Timestamp)
+			if err != nil {
+				return err
+			}
+			if filled && liquidity != nil {
+				remaining := liquidity.Sub(fill.Amount)
+				liquidity = &remaining
+			}
+			response.Orders = append(response.Orders, s.orders.convertToOrderResponse(order))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to match orders: %w", err)
+	}
+
+	return response, nil
+}
+
+// applyFill adds a fill to an order and processes its trading log in a savepoint of the tick
+// transaction. It returns false when the ledger rejected the fill and the order was closed instead.
+func (s *VirtualExchangeService) applyFill(ctx context.Context, tx *gorm.DB, repos *repositories.Repositories, processor *TradingLogProcessor,
+	userID uuid.UUID, order *models.Order, fill *virtualFill, eventTime *time.Time) (bool, error) {
+	before := *order
+
+	fillErr := tx.Transaction(func(fillTx *gorm.DB) error {
+		if err := order.ApplyFill(fill.Amount, fill.Price); err != nil {
+			return err
+		}
+		req, err := virtualFillTradingLogRequest(order, fill, eventTime)
+		if err != nil {
+			return err
+		}
+		if _, err := processor.ProcessTradingLog(ctx, fillTx, userID, req); err != nil {
+			return err
+		}
+		return repos.Order.Update(ctx, order)
+	})
+	if fillErr == nil {
+		return true, nil
+	}
+
+	// The savepoint is rolled back: close the order as it was before the fill
+	*order = before
+	status := models.OrderStatusFailed
+	if order.Status == models.OrderStatusPartiallyFilled {
+		status = models.OrderStatusCancelled
+	}
+	if err := order.TransitionTo(status); err != nil {
+		return false, err
+	}
+	info := models.JSON{}
+	for key, value := range order.Info {
+		info[key] = value
+	}
+	info["error"] = fillErr.Error()
+	order.Info = info
+
+	if err := repos.Order.Update(ctx, order); err != nil {
+		return false, fmt.Errorf("failed to update order: %w", err)
+	}
+	return false, nil
+}
+
+// virtualFillTradingLogRequest builds the long or short trading log request of a fill
+func virtualFillTradingLogRequest(order *models.Order, fill *virtualFill, eventTime *time.Time) (*CreateTradingLogRequest, error) {
+	logType := "long"
+	if order.Side == "sell" {
+		logType = "short"
+	}
+
+	stockAccountID, _ := order.Info["stock_account_id"].(string)
+	currencyAccountID, _ := order.Info["currency_account_id"].(string)
+	if stockAccountID == "" || currencyAccountID == "" {
+		return nil, fmt.Errorf("order %s has no stock and currency accounts", order.OrderID)
+	}
+
+	stock, currency, _ := strings.Cut(order.Symbol, "/")
+	liquidity := "taker"
+	if fill.Maker {
+		liquidity = "maker"
+	}
+
+	return &CreateTradingLogRequest{
+		TradingID:    order.TradingID,
+		SubAccountID: &order.SubAccountID,
+		EventTime:    eventTime,
+		Type:         logType,
+		Source:       "bot",
+		Message: fmt.Sprintf("Virtual order %s filled: %s %s %s at %s",
+			order.OrderID, order.Side, fill.Amount, stock, fill.Price),
+		Info: map[string]interface{}{
+			"stock_account_id":    stockAccountID,
+			"currency_account_id": currencyAccountID,
+			"price":               fill.Price.String(),
+			"volume":              fill.Amount.String(),
+			"stock":               stock,
+			"currency":            currency,
+			"fee":                 fill.Fee.String(),
+			"order_id":            order.OrderID,
+			"liquidity":           liquidity,
+		},
+		virtualFill: true,
+	}, nil
+}
+
+// getVirtualTrading returns a simulation or backtest trading of the user
+func (s *VirtualExchangeService) getVirtualTrading(ctx context.Context, userID, tradingID uuid.UUID) (*models.Trading, error) {
+	trading, err := s.repos.Trading.GetByID(ctx, tradingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trading: %w", err)
+	}
+	if trading == nil || trading.UserID != userID {
+		return nil, fmt.Errorf("trading not found")
+	}
+	if !trading.IsSimulated() {
+		return nil, fmt.Errorf("virtual exchange requires a simulation or backtest trading")
+	}
+	return trading, nil
+}
+
+// getTradingSubAccount returns a sub-account of the user in the trading
+func (s *VirtualExchangeService) getTradingSubAccount(ctx context.Context, userID, tradingID, subAccountID uuid.UUID, name string) (*models.SubAccount, error) {
+	account, err := s.repos.SubAccount.GetByID(ctx, subAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", name, err)
+	}
+	if account == nil || account.UserID != userID || account.TradingID != tradingID {
+		return nil, fmt.Errorf("%s not found", name)
+	}
+	return account, nil
+}
+
+// validateVirtualOrder checks the type, amount and price of an order
+func validateVirtualOrder(req *PlaceVirtualOrderRequest) error {
+	if req.Side != "buy" && req.Side != "sell" {
+		return fmt.Errorf("order side must be buy or sell")
+	}
+	if !req.Amount.IsPositive() {
+		return fmt.Errorf("amount must be positive")
+	}
+	if !req.Amount.Equal(req.Amount.Truncate(MaxDecimalPlaces)) {
+		return fmt.Errorf("amount must have at most %d decimal places", MaxDecimalPlaces)
+	}
+
+	switch req.Type {
+	case VirtualOrderTypeMarket:
+		if req.Price != nil {
+			return fmt.Errorf("market orders cannot have a price")
+		}
+	case VirtualOrderTypeLimit:
+		if req.Price == nil || !req.Price.IsPositive() {
+			return fmt.Errorf("limit orders require a positive price")
+		}
+		if !req.Price.Equal(req.Price.Truncate(MaxDecimalPlaces)) {
+			return fmt.Errorf("price must have at most %d decimal places", MaxDecimalPlaces)
+		}
+	default:
+		return fmt.Errorf("order type must be market or limit")
+	}
+	return nil
+}
+
+// validatePriceTick checks the price and volume of a tick
+func validatePriceTick(req *PriceTickRequest) error {
+	if req.Symbol == "" {
+		return fmt.Errorf("symbol is required")
+	}
+	if !req.Price.IsPositive() {
+		return fmt.Errorf("price must be positive")
+	}
+	if !req.Price.Equal(req.Price.Truncate(MaxDecimalPlaces)) {
+		return fmt.Errorf("price must have at most %d decimal places", MaxDecimalPlaces)
+	}
+	if req.Volume != nil {
+		if req.Volume.IsNegative() {
+			return fmt.Errorf("volume cannot be negative")
+		}
+		if !req.Volume.Equal(req.Volume.Truncate(MaxDecimalPlaces)) {
+			return fmt.Errorf("volume must have at most %d decimal places", MaxDecimalPlaces)
+		}
+	}
+	return nil
+}
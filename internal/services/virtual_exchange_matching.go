@@ -0,0 +1,297 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"tiris-backend/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// VirtualExchangeInfoKey is the key of the virtual exchange configuration in the info of a trading
+const VirtualExchangeInfoKey = "virtual_exchange"
+
+// virtualOrderRestingKey is set in the info of an order once a price tick of its symbol was pushed
+const virtualOrderRestingKey = "resting"
+
+// Order types accepted by the virtual exchange
+const (
+	VirtualOrderTypeMarket = "market"
+	VirtualOrderTypeLimit  = "limit"
+)
+
+// Fee models of the virtual exchange
+const (
+	VirtualFeeModelNone       = "none"
+	VirtualFeeModelPercentage = "percentage"
+	VirtualFeeModelFixed      = "fixed"
+)
+
+// Slippage models of the virtual exchange
+const (
+	VirtualSlippageModelNone   = "none"
+	VirtualSlippageModelFixed  = "fixed"
+	VirtualSlippageModelVolume = "volume"
+)
+
+var basisPoints = decimal.NewFromInt(10000)
+
+// VirtualExchangeConfig configures the fees and slippage of the virtual exchange for a trading. Without
+// a configuration fills are free and execute at the tick price.
+type VirtualExchangeConfig struct {
+	// FeeModel is none, percentage (maker and taker rates of the traded value) or fixed (per fill)
+	FeeModel     string          `json:"fee_model" binding:"omitempty,oneof=none percentage fixed" example:"percentage"`
+	MakerFeeRate decimal.Decimal `json:"maker_fee_rate" swaggertype:"string" example:"0.001"`
+	TakerFeeRate decimal.Decimal `json:"taker_fee_rate" swaggertype:"string" example:"0.002"`
+	FixedFee     decimal.Decimal `json:"fixed_fee" swaggertype:"string" example:"0"`
+	// SlippageModel is none, fixed (basis points) or volume (basis points per tick volume consumed)
+	SlippageModel string          `json:"slippage_model" binding:"omitempty,oneof=none fixed volume" example:"fixed"`
+	SlippageBps   decimal.Decimal `json:"slippage_bps" swaggertype:"string" example:"5"`
+}
+
+// Validate checks that the models are known and their parameters are not negative
+func (c *VirtualExchangeConfig) Validate() error {
+	switch c.FeeModel {
+	case "", VirtualFeeModelNone, VirtualFeeModelPercentage, VirtualFeeModelFixed:
+	default:
+		return fmt.Errorf("unknown fee model: %s", c.FeeModel)
+	}
+	switch c.SlippageModel {
+	case "", VirtualSlippageModelNone, VirtualSlippageModelFixed, VirtualSlippageModelVolume:
+	default:
+		return fmt.Errorf("unknown slippage model: %s", c.SlippageModel)
+	}
+
+	if c.MakerFeeRate.IsNegative() || c.TakerFeeRate.IsNegative() || c.FixedFee.IsNegative() {
+		return fmt.Errorf("fees cannot be negative")
+	}
+	if c.MakerFeeRate.GreaterThanOrEqual(decimal.NewFromInt(1)) || c.TakerFeeRate.GreaterThanOrEqual(decimal.NewFromInt(1)) {
+		return fmt.Errorf("fee rates must be below 1")
+	}
+	if !c.FixedFee.Equal(c.FixedFee.Truncate(MaxDecimalPlaces)) {
+		return fmt.Errorf("fixed fee must have at most %d decimal places", MaxDecimalPlaces)
+	}
+	if c.SlippageBps.IsNegative() || c.SlippageBps.GreaterThanOrEqual(basisPoints) {
+		return fmt.Errorf("slippage must be between 0 and 10000 basis points")
+	}
+	return nil
+}
+
+// ToInfo returns the configuration as stored in the info of a trading
+func (c *VirtualExchangeConfig) ToInfo() map[string]interface{} {
+	return map[string]interface{}{
+		"fee_model":      c.FeeModel,
+		"maker_fee_rate": c.MakerFeeRate.String(),
+		"taker_fee_rate": c.TakerFeeRate.String(),
+		"fixed_fee":      c.FixedFee.String(),
+		"slippage_model": c.SlippageModel,
+		"slippage_bps":   c.SlippageBps.String(),
+	}
+}
+
+// virtualExchangeConfigOf reads the virtual exchange configuration from the info of a trading
+func virtualExchangeConfigOf(trading *models.Trading) (*VirtualExchangeConfig, error) {
+	config := &VirtualExchangeConfig{}
+	raw, ok := trading.Info[VirtualExchangeInfoKey]
+	if !ok || raw == nil {
+		return config, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid virtual exchange configuration: %w", err)
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid virtual exchange configuration: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid virtual exchange configuration: %w", err)
+	}
+	return config, nil
+}
+
+// FeeModel computes the fee of a fill in the currency of the trade
+type FeeModel interface {
+	Fee(price, amount decimal.Decimal, maker bool) decimal.Decimal
+}
+
+// SlippageModel moves the tick price against the taker of a fill. Volume is the volume of the tick,
+// nil when the price feed does not report it.
+type SlippageModel interface {
+	Price(side string, price, amount decimal.Decimal, volume *decimal.Decimal) decimal.Decimal
+}
+
+type noFee struct{}
+
+func (noFee) Fee(price, amount decimal.Decimal, maker bool) decimal.Decimal {
+	return decimal.Zero
+}
+
+// percentageFee charges a rate of the traded value; resting limit orders pay the maker rate
+type percentageFee struct {
+	makerRate decimal.Decimal
+	takerRate decimal.Decimal
+}
+
+func (f percentageFee) Fee(price, amount decimal.Decimal, maker bool) decimal.Decimal {
+	rate := f.takerRate
+	if maker {
+		rate = f.makerRate
+	}
+	return price.Mul(amount).Mul(rate).Round(MaxDecimalPlaces)
+}
+
+// fixedFee charges the same amount for every fill
+type fixedFee struct {
+	amount decimal.Decimal
+}
+
+func (f fixedFee) Fee(price, amount decimal.Decimal, maker bool) decimal.Decimal {
+	return f.amount
+}
+
+type noSlippage struct{}
+
+func (noSlippage) Price(side string, price, amount decimal.Decimal, volume *decimal.Decimal) decimal.Decimal {
+	return price
+}
+
+// fixedSlippage moves the price by a fixed number of basis points
+type fixedSlippage struct {
+	bps decimal.Decimal
+}
+
+func (s fixedSlippage) Price(side string, price, amount decimal.Decimal, volume *decimal.Decimal) decimal.Decimal {
+	return slipPrice(side, price, s.bps)
+}
+
+// volumeSlippage moves the price by the basis points scaled with the share of the tick volume the fill
+// takes: a fill of the whole tick volume slips by the full basis points. Ticks without volume do not slip.
+type volumeSlippage struct {
+	bps decimal.Decimal
+}
+
+func (s volumeSlippage) Price(side string, price, amount decimal.Decimal, volume *decimal.Decimal) decimal.Decimal {
+	if volume == nil || !volume.IsPositive() {
+		return price
+	}
+	return slipPrice(side, price, s.bps.Mul(amount).Div(*volume))
+}
+
+// slipPrice raises the price of a buy and lowers the price of a sell by bps basis points
+func slipPrice(side string, price, bps decimal.Decimal) decimal.Decimal {
+	slippage := price.Mul(bps).Div(basisPoints)
+	if side == "sell" {
+		return price.Sub(slippage).Round(MaxDecimalPlaces)
+	}
+	return price.Add(slippage).Round(MaxDecimalPlaces)
+}
+
+// feeModel returns the fee model of the configuration
+func (c *VirtualExchangeConfig) feeModel() FeeModel {
+	switch c.FeeModel {
+	case VirtualFeeModelPercentage:
+		return percentageFee{makerRate: c.MakerFeeRate, takerRate: c.TakerFeeRate}
+	case VirtualFeeModelFixed:
+		return fixedFee{amount: c.FixedFee}
+	default:
+		return noFee{}
+	}
+}
+
+// slippageModel returns the slippage model of the configuration
+func (c *VirtualExchangeConfig) slippageModel() SlippageModel {
+	switch c.SlippageModel {
+	case VirtualSlippageModelFixed:
+		return fixedSlippage{bps: c.SlippageBps}
+	case VirtualSlippageModelVolume:
+		return volumeSlippage{bps: c.SlippageBps}
+	default:
+		return noSlippage{}
+	}
+}
+
+// virtualFill is the execution of (part of) an order at a price tick
+type virtualFill struct {
+	Amount decimal.Decimal
+	Price  decimal.Decimal
+	Fee    decimal.Decimal
+	Maker  bool
+}
+
+// virtualMatcher matches the open orders of a trading against the ticks of its price feed
+type virtualMatcher struct {
+	fees     FeeModel
+	slippage SlippageModel
+}
+
+// newVirtualMatcher creates a matcher with the fee and slippage models of the configuration
+func newVirtualMatcher(config *VirtualExchangeConfig) *virtualMatcher {
+	return &virtualMatcher{
+		fees:     config.feeModel(),
+		slippage: config.slippageModel(),
+	}
+}
+
+// match returns the fill of an open order at a tick of price and volume, or nil if the order does not
+// execute. Market orders take the tick price with slippage; limit orders execute once the price reaches
+// their limit and never at a worse price than the limit. A limit order pays the maker rate once it
+// rests, and the taker rate when it already crosses the first tick after it was placed. Liquidity is
+// what is left of the tick volume for this order, nil when the volume is unknown; the fill never exceeds it.
+func (m *virtualMatcher) match(order *models.Order, price decimal.Decimal, volume, liquidity *decimal.Decimal) *virtualFill {
+	amount := order.Amount.Sub(order.FilledAmount)
+	if liquidity != nil && liquidity.LessThan(amount) {
+		amount = *liquidity
+	}
+	if !amount.IsPositive() {
+		return nil
+	}
+
+	fillPrice := m.slippage.Price(order.Side, price, amount, volume)
+	maker := false
+	if order.Type == VirtualOrderTypeLimit {
+		if order.Price == nil {
+			return nil
+		}
+		limit := *order.Price
+		if order.Side == "buy" {
+			if price.GreaterThan(limit) {
+				return nil
+			}
+			fillPrice = decimal.Min(fillPrice, limit)
+		} else {
+			if price.LessThan(limit) {
+				return nil
+			}
+			fillPrice = decimal.Max(fillPrice, limit)
+		}
+		maker = isRestingVirtualOrder(order)
+	}
+	if !fillPrice.IsPositive() {
+		return nil
+	}
+
+	return &virtualFill{
+		Amount: amount,
+		Price:  fillPrice,
+		Fee:    m.fees.Fee(fillPrice, amount, maker),
+		Maker:  maker,
+	}
+}
+
+// isRestingVirtualOrder reports whether an order has seen a price tick of its symbol
+func isRestingVirtualOrder(order *models.Order) bool {
+	resting, _ := order.Info[virtualOrderRestingKey].(bool)
+	return resting
+}
+
+// markVirtualOrderResting records in the info of an order that it has seen a price tick
+func markVirtualOrderResting(order *models.Order) {
+	info := models.JSON{}
+	for key, value := range order.Info {
+		info[key] = value
+	}
+	info[virtualOrderRestingKey] = true
+	order.Info = info
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"tiris-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestVirtualOrder(side, orderType string, amount string, price *decimal.Decimal) *models.Order {
+	return &models.Order{
+		ID:           uuid.New(),
+		TradingID:    uuid.New(),
+		SubAccountID: uuid.New(),
+		OrderID:      "virtual-1",
+		Symbol:       "ETH/USDT",
+		Side:         side,
+		Type:         orderType,
+		Amount:       decimal.RequireFromString(amount),
+		Price:        price,
+		Status:       models.OrderStatusOpen,
+		Info: models.JSON{
+			"stock_account_id":    uuid.New().String(),
+			"currency_account_id": uuid.New().String(),
+		},
+	}
+}
+
+func TestVirtualMatcher(t *testing.T) {
+	d := decimal.RequireFromString
+	limit := d("3000")
+
+	t.Run("market_buy_with_fixed_slippage", func(t *testing.T) {
+		matcher := newVirtualMatcher(&VirtualExchangeConfig{
+			FeeModel: VirtualFeeModelPercentage, MakerFeeRate: d("0.001"), TakerFeeRate: d("0.002"),
+			SlippageModel: VirtualSlippageModelFixed, SlippageBps: d("10"),
+		})
+
+		fill := matcher.match(newTestVirtualOrder("buy", VirtualOrderTypeMarket, "2", nil), d("3000"), nil, nil)
+
+		require.NotNil(t, fill)
+		assert.True(t, fill.Amount.Equal(d("2")))
+		assert.True(t, fill.Price.Equal(d("3003")), fill.Price.String())
+		assert.True(t, fill.Fee.Equal(d("12.012")), fill.Fee.String())
+		assert.False(t, fill.Maker)
+	})
+
+	t.Run("market_sell_slips_down", func(t *testing.T) {
+		matcher := newVirtualMatcher(&VirtualExchangeConfig{SlippageModel: VirtualSlippageModelFixed, SlippageBps: d("10")})
+
+		fill := matcher.match(newTestVirtualOrder("sell", VirtualOrderTypeMarket, "2", nil), d("3000"), nil, nil)
+
+		require.NotNil(t, fill)
+		assert.True(t, fill.Price.Equal(d("2997")), fill.Price.String())
+		assert.True(t, fill.Fee.IsZero())
+	})
+
+	t.Run("limit_buy_not_reached", func(t *testing.T) {
+		matcher := newVirtualMatcher(&VirtualExchangeConfig{})
+
+		fill := matcher.match(newTestVirtualOrder("buy", VirtualOrderTypeLimit, "1", &limit), d("3000.01"), nil, nil)
+
+		assert.Nil(t, fill)
+	})
+
+	t.Run("limit_buy_never_fills_above_limit", func(t *testing.T) {
+		matcher := newVirtualMatcher(&VirtualExchangeConfig{
+			FeeModel: VirtualFeeModelPercentage, MakerFeeRate: d("0.001"), TakerFeeRate: d("0.002"),
+			SlippageModel: VirtualSlippageModelFixed, SlippageBps: d("100"),
+		})
+
+		order := newTestVirtualOrder("buy", VirtualOrderTypeLimit, "1", &limit)
+		markVirtualOrderResting(order)
+
+		fill := matcher.match(order, d("2990"), nil, nil)
+
+		require.NotNil(t, fill)
+		assert.True(t, fill.Price.Equal(limit), fill.Price.String())
+		assert.True(t, fill.Fee.Equal(d("3")), fill.Fee.String())
+		assert.True(t, fill.Maker)
+	})
+
+	t.Run("limit_crossing_first_tick_pays_taker", func(t *testing.T) {
+		matcher := newVirtualMatcher(&VirtualExchangeConfig{
+			FeeModel: VirtualFeeModelPercentage, MakerFeeRate: d("0.001"), TakerFeeRate: d("0.002"),
+		})
+		order := newTestVirtualOrder("buy", VirtualOrderTypeLimit, "1", &limit)
+
+		// The order never rested: it takes the liquidity of the tick it was placed before
+		fill := matcher.match(order, d("2990"), nil, nil)
+
+		require.NotNil(t, fill)
+		assert.True(t, fill.Price.Equal(d("2990")), fill.Price.String())
+		assert.True(t, fill.Fee.Equal(d("5.98")), fill.Fee.String())
+		assert.False(t, fill.Maker)
+		assert.False(t, isRestingVirtualOrder(order))
+
+		markVirtualOrderResting(order)
+		fill = matcher.match(order, d("2990"), nil, nil)
+		require.NotNil(t, fill)
+		assert.True(t, fill.Fee.Equal(d("2.99")), fill.Fee.String())
+		assert.True(t, fill.Maker)
+	})
+
+	t.Run("limit_sell_fills_at_better_price", func(t *testing.T) {
+		matcher := newVirtualMatcher(&VirtualExchangeConfig{})
+
+		assert.Nil(t, matcher.match(newTestVirtualOrder("sell", VirtualOrderTypeLimit, "1", &limit), d("2999"), nil, nil))
+
+		fill := matcher.match(newTestVirtualOrder("sell", VirtualOrderTypeLimit, "1", &limit), d("3010"), nil, nil)
+		require.NotNil(t, fill)
+		assert.True(t, fill.Price.Equal(d("3010")), fill.Price.String())
+	})
+
+	t.Run("partial_fill_by_liquidity", func(t *testing.T) {
+		matcher := newVirtualMatcher(&VirtualExchangeConfig{FeeModel: VirtualFeeModelFixed, FixedFee: d("1.5")})
+		order := newTestVirtualOrder("buy", VirtualOrderTypeMarket, "5", nil)
+		order.FilledAmount = d("1")
+		volume := d("10")
+		liquidity := d("2.5")
+
+		fill := matcher.match(order, d("3000"), &volume, &liquidity)
+
+		require.NotNil(t, fill)
+		assert.True(t, fill.Amount.Equal(d("2.5")), fill.Amount.String())
+		assert.True(t, fill.Fee.Equal(d("1.5")))
+
+		exhausted := decimal.Zero
+		assert.Nil(t, matcher.match(order, d("3000"), &volume, &exhausted))
+	})
+
+	t.Run("volume_slippage", func(t *testing.T) {
+		matcher := newVirtualMatcher(&VirtualExchangeConfig{SlippageModel: VirtualSlippageModelVolume, SlippageBps: d("100")})
+		volume := d("10")
+
+		// Taking a quarter of the tick volume slips by a quarter of 100 basis points
+		fill := matcher.match(newTestVirtualOrder("buy", VirtualOrderTypeMarket, "2.5", nil), d("3000"), &volume, nil)
+		require.NotNil(t, fill)
+		assert.True(t, fill.Price.Equal(d("3007.5")), fill.Price.String())
+
+		// Ticks without volume do not slip
+		fill = matcher.match(newTestVirtualOrder("buy", VirtualOrderTypeMarket, "2.5", nil), d("3000"), nil, nil)
+		require.NotNil(t, fill)
+		assert.True(t, fill.Price.Equal(d("3000")), fill.Price.String())
+	})
+}
+
+func TestVirtualExchangeConfig(t *testing.T) {
+	d := decimal.RequireFromString
+
+	t.Run("validate", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			config VirtualExchangeConfig
+			err    string
+		}{
+			{"defaults", VirtualExchangeConfig{}, ""},
+			{"unknown_fee_model", VirtualExchangeConfig{FeeModel: "tiered"}, "unknown fee model: tiered"},
+			{"unknown_slippage_model", VirtualExchangeConfig{SlippageModel: "random"}, "unknown slippage model: random"},
+			{"negative_fee", VirtualExchangeConfig{FeeModel: VirtualFeeModelFixed, FixedFee: d("-1")}, "fees cannot be negative"},
+			{"fee_rate_of_one", VirtualExchangeConfig{FeeModel: VirtualFeeModelPercentage, TakerFeeRate: d("1")}, "fee rates must be below 1"},
+			{"fixed_fee_precision", VirtualExchangeConfig{FeeModel: VirtualFeeModelFixed, FixedFee: d("0.000000001")}, "fixed fee must have at most 8 decimal places"},
+			{"slippage_out_of_range", VirtualExchangeConfig{SlippageModel: VirtualSlippageModelFixed, SlippageBps: d("10000")}, "slippage must be between 0 and 10000 basis points"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := tt.config.Validate()
+				if tt.err == "" {
+					assert.NoError(t, err)
+				} else {
+					assert.EqualError(t, err, tt.err)
+				}
+			})
+		}
+	})
+
+	t.Run("read_from_trading_info", func(t *testing.T) {
+		config := &VirtualExchangeConfig{FeeModel: VirtualFeeModelPercentage, MakerFeeRate: d("0.001"), TakerFeeRate: d("0.002"),
+			SlippageModel: VirtualSlippageModelFixed, SlippageBps: d("5")}
+		trading := &models.Trading{Info: models.JSON{VirtualExchangeInfoKey: config.ToInfo()}}
+
+		read, err := virtualExchangeConfigOf(trading)
+
+		require.NoError(t, err)
+		assert.Equal(t, VirtualFeeModelPercentage, read.FeeModel)
+		assert.True(t, read.TakerFeeRate.Equal(d("0.002")))
+		assert.True(t, read.SlippageBps.Equal(d("5")))
+	})
+
+	t.Run("missing_configuration", func(t *testing.T) {
+		read, err := virtualExchangeConfigOf(&models.Trading{Info: models.JSON{}})
+
+		require.NoError(t, err)
+		assert.IsType(t, noFee{}, read.feeModel())
+		assert.IsType(t, noSlippage{}, read.slippageModel())
+	})
+
+	t.Run("invalid_configuration", func(t *testing.T) {
+		trading := &models.Trading{Info: models.JSON{VirtualExchangeInfoKey: map[string]interface{}{"fee_model": "tiered"}}}
+
+		_, err := virtualExchangeConfigOf(trading)
+
+		assert.EqualError(t, err, "invalid virtual exchange configuration: unknown fee model: tiered")
+	})
+}
+
+func TestVirtualFillTradingLogRequest(t *testing.T) {
+	d := decimal.RequireFromString
+	eventTime := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	t.Run("buy_is_long", func(t *testing.T) {
+		order := newTestVirtualOrder("buy", VirtualOrderTypeMarket, "2", nil)
+		fill := &virtualFill{Amount: d("2"), Price: d("3003"), Fee: d("12.012")}
+
+		req, err := virtualFillTradingLogRequest(order, fill, &eventTime)
+
+		require.NoError(t, err)
+		assert.Equal(t, "long", req.Type)
+		assert.Equal(t, &eventTime, req.EventTime)
+		assert.Equal(t, "taker", req.Info["liquidity"])
+		// The tick time is kept on the log without moving the fill in the ledger
+		assert.False(t, placedAtEventTime(req, eventTime.Add(time.Hour)))
+
+		info, err := NewTradingLogValidator().ValidateInfoStructure(req.Info, req.Type)
+		require.NoError(t, err)
+		assert.Equal(t, "ETH", info.Stock)
+		assert.Equal(t, "USDT", info.Currency)
+		assert.True(t, info.Volume.Equal(d("2")))
+		assert.True(t, info.Fee.Equal(d("12.012")))
+	})
+
+	t.Run("sell_is_short", func(t *testing.T) {
+		order := newTestVirtualOrder("sell", VirtualOrderTypeLimit, "1", nil)
+
+		req, err := virtualFillTradingLogRequest(order, &virtualFill{Amount: d("1"), Price: d("3000"), Fee: decimal.Zero, Maker: true}, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, "short", req.Type)
+		assert.Equal(t, "maker", req.Info["liquidity"])
+	})
+
+	t.Run("order_without_accounts", func(t *testing.T) {
+		order := newTestVirtualOrder("buy", VirtualOrderTypeMarket, "1", nil)
+		order.Info = models.JSON{}
+
+		_, err := virtualFillTradingLogRequest(order, &virtualFill{Amount: d("1"), Price: d("3000")}, nil)
+
+		assert.EqualError(t, err, "order virtual-1 has no stock and currency accounts")
+	})
+}
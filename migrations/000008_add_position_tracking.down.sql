@@ -0,0 +1,6 @@
+-- Remove position and cost-basis tracking columns
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS realized_pnl;
+
+ALTER TABLE sub_accounts DROP COLUMN IF EXISTS realized_pnl;
+ALTER TABLE sub_accounts DROP COLUMN IF EXISTS avg_cost;
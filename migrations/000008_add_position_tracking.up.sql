@@ -0,0 +1,13 @@
+-- Add position and cost-basis tracking
+-- Stock sub-accounts keep the average entry price of the position they hold and the
+-- cumulative profit realized when selling it; the selling transaction records its own PnL
+
+ALTER TABLE sub_accounts ADD COLUMN avg_cost DECIMAL(20,8) NOT NULL DEFAULT 0;
+ALTER TABLE sub_accounts ADD COLUMN realized_pnl DECIMAL(20,8) NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN sub_accounts.avg_cost IS 'Average cost per unit of the held position, fees included, in the quote currency';
+COMMENT ON COLUMN sub_accounts.realized_pnl IS 'Cumulative realized profit and loss of the position, net of fees, in the quote currency';
+
+ALTER TABLE transactions ADD COLUMN realized_pnl DECIMAL(20,8);
+
+COMMENT ON COLUMN transactions.realized_pnl IS 'Realized profit and loss, net of fees, of a position-reducing transaction (NULL otherwise)';
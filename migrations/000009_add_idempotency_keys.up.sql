@@ -0,0 +1,17 @@
+-- Add idempotency_keys table for safe retries of trading log creation over HTTP
+-- A key is scoped to the user that sent it and remembers the request hash and the response returned
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    response JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT idempotency_keys_user_key_unique UNIQUE (user_id, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys(created_at);
+
+COMMENT ON COLUMN idempotency_keys.request_hash IS 'SHA-256 of the request body; a key reused with a different body is rejected';
+COMMENT ON COLUMN idempotency_keys.response IS 'Response returned for the original request, replayed on retries';
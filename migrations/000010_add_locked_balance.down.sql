@@ -0,0 +1,4 @@
+-- Remove locked balance tracking
+
+ALTER TABLE sub_accounts DROP CONSTRAINT IF EXISTS sub_accounts_locked_balance_non_negative;
+ALTER TABLE sub_accounts DROP COLUMN IF EXISTS locked_balance;
@@ -0,0 +1,10 @@
+-- Add locked balance tracking
+-- Part of a sub-account balance can be locked, e.g. by open orders on the exchange;
+-- only the available balance (balance - locked_balance) can be spent
+
+ALTER TABLE sub_accounts ADD COLUMN locked_balance DECIMAL(20,8) NOT NULL DEFAULT 0;
+
+ALTER TABLE sub_accounts ADD CONSTRAINT sub_accounts_locked_balance_non_negative
+    CHECK (locked_balance >= 0);
+
+COMMENT ON COLUMN sub_accounts.locked_balance IS 'Part of the balance reserved and not available for spending, e.g. by open orders';
@@ -0,0 +1,5 @@
+-- Remove trading log amendment links
+
+DROP INDEX IF EXISTS idx_trading_logs_amends;
+ALTER TABLE trading_logs DROP COLUMN IF EXISTS amended_by;
+ALTER TABLE trading_logs DROP COLUMN IF EXISTS amends;
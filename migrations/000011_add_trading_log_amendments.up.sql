@@ -0,0 +1,11 @@
+-- Link amended trading logs to their corrections
+-- An amended log keeps its original transactions, which are compensated by reversal transactions;
+-- the correcting log posts the corrected transactions and points back to the log it amends
+
+ALTER TABLE trading_logs ADD COLUMN amends UUID;
+ALTER TABLE trading_logs ADD COLUMN amended_by UUID;
+
+CREATE INDEX IF NOT EXISTS idx_trading_logs_amends ON trading_logs(amends) WHERE amends IS NOT NULL;
+
+COMMENT ON COLUMN trading_logs.amends IS 'Trading log corrected by this log (NULL unless this log is an amendment)';
+COMMENT ON COLUMN trading_logs.amended_by IS 'Trading log that corrected this log; its transactions have been reversed';
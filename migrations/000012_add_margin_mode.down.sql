@@ -0,0 +1,8 @@
+-- Remove margin trading
+
+ALTER TABLE sub_accounts DROP CONSTRAINT IF EXISTS sub_accounts_borrowed_non_negative;
+ALTER TABLE sub_accounts DROP COLUMN IF EXISTS borrowed;
+
+ALTER TABLE tradings DROP CONSTRAINT IF EXISTS tradings_collateral_ratio_min;
+ALTER TABLE tradings DROP COLUMN IF EXISTS collateral_ratio;
+ALTER TABLE tradings DROP COLUMN IF EXISTS margin_mode;
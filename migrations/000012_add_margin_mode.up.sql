@@ -0,0 +1,19 @@
+-- Add margin trading
+-- In margin mode a sub-account may be sold below zero, down to minus its borrowed amount; a negative
+-- balance is an open short. Trades are refused when the value of the positive balances of the trading
+-- would fall below collateral_ratio times the value of its negative balances.
+
+ALTER TABLE tradings ADD COLUMN margin_mode BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE tradings ADD COLUMN collateral_ratio DECIMAL(20,8) NOT NULL DEFAULT 1.5;
+
+ALTER TABLE tradings ADD CONSTRAINT tradings_collateral_ratio_min
+    CHECK (collateral_ratio >= 1);
+
+ALTER TABLE sub_accounts ADD COLUMN borrowed DECIMAL(20,8) NOT NULL DEFAULT 0;
+
+ALTER TABLE sub_accounts ADD CONSTRAINT sub_accounts_borrowed_non_negative
+    CHECK (borrowed >= 0);
+
+COMMENT ON COLUMN tradings.margin_mode IS 'Whether sub-accounts may borrow and go negative';
+COMMENT ON COLUMN tradings.collateral_ratio IS 'Minimum ratio of the value of positive balances to the value of negative balances in margin mode';
+COMMENT ON COLUMN sub_accounts.borrowed IS 'Amount borrowed from the exchange; the balance may go down to minus this amount';
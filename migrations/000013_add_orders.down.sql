@@ -0,0 +1,8 @@
+-- Remove orders table
+
+DROP TRIGGER IF EXISTS update_orders_updated_at ON orders;
+DROP INDEX IF EXISTS idx_orders_status;
+DROP INDEX IF EXISTS idx_orders_sub_account_id;
+DROP INDEX IF EXISTS idx_orders_trading_created_at;
+DROP INDEX IF EXISTS idx_orders_user_created_at;
+DROP TABLE IF EXISTS orders;
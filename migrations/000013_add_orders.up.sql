@@ -0,0 +1,36 @@
+-- Add orders table tracking the lifecycle of exchange orders reported through order events
+-- An order is keyed by the exchange order ID within its trading; fills are aggregated into the
+-- filled amount and the average price, and the status follows the allowed lifecycle transitions
+
+CREATE TABLE IF NOT EXISTS orders (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    trading_id UUID NOT NULL REFERENCES tradings(id) ON DELETE CASCADE,
+    sub_account_id UUID NOT NULL REFERENCES sub_accounts(id) ON DELETE CASCADE,
+    order_id VARCHAR(255) NOT NULL,
+    symbol VARCHAR(50) NOT NULL,
+    side VARCHAR(10) NOT NULL CHECK (side IN ('buy', 'sell')),
+    type VARCHAR(20) NOT NULL,
+    amount DECIMAL(20,8) NOT NULL CHECK (amount > 0),
+    price DECIMAL(20,8),
+    filled_amount DECIMAL(20,8) NOT NULL DEFAULT 0 CHECK (filled_amount >= 0 AND filled_amount <= amount),
+    avg_price DECIMAL(20,8),
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'partially_filled', 'filled', 'cancelled', 'failed')),
+    info JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT orders_trading_order_unique UNIQUE (trading_id, order_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_user_created_at ON orders(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_orders_trading_created_at ON orders(trading_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_orders_sub_account_id ON orders(sub_account_id);
+CREATE INDEX IF NOT EXISTS idx_orders_status ON orders(status);
+
+CREATE OR REPLACE TRIGGER update_orders_updated_at BEFORE UPDATE ON orders
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON COLUMN orders.order_id IS 'Order ID assigned by the exchange, unique within the trading';
+COMMENT ON COLUMN orders.price IS 'Limit price of the order (NULL for market orders)';
+COMMENT ON COLUMN orders.filled_amount IS 'Sum of the amounts of the fills received so far';
+COMMENT ON COLUMN orders.avg_price IS 'Average fill price weighted by the filled amounts (NULL until the first fill)';
@@ -0,0 +1,10 @@
+-- Restore the virtual trading type
+
+ALTER TABLE tradings DROP CONSTRAINT IF EXISTS tradings_type_valid;
+
+UPDATE tradings SET type = 'virtual' WHERE type = 'simulation';
+
+ALTER TABLE tradings ADD CONSTRAINT tradings_type_valid
+    CHECK (type IN ('real', 'virtual', 'backtest'));
+
+COMMENT ON COLUMN tradings.type IS NULL;
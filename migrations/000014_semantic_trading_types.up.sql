@@ -0,0 +1,13 @@
+-- Semantic trading types
+-- A trading is real, simulation or backtest. Simulation and backtest tradings run on a virtual
+-- exchange binding, accept any event time and are left out of real-money aggregates. The former
+-- virtual type becomes simulation.
+
+ALTER TABLE tradings DROP CONSTRAINT IF EXISTS tradings_type_valid;
+
+UPDATE tradings SET type = 'simulation' WHERE type = 'virtual';
+
+ALTER TABLE tradings ADD CONSTRAINT tradings_type_valid
+    CHECK (type IN ('real', 'simulation', 'backtest'));
+
+COMMENT ON COLUMN tradings.type IS 'Trading type: real trades real money, simulation and backtest run on a virtual exchange';
@@ -0,0 +1,13 @@
+-- Revert encrypted exchange binding credentials
+-- Run `migrate decrypt-credentials` first, otherwise api_key and api_secret keep their ciphertexts.
+
+DROP INDEX IF EXISTS idx_exchange_bindings_api_secret_hash;
+DROP INDEX IF EXISTS idx_exchange_bindings_api_key_hash;
+
+COMMENT ON COLUMN exchange_bindings.api_key IS NULL;
+COMMENT ON COLUMN exchange_bindings.api_secret IS NULL;
+
+ALTER TABLE exchange_bindings
+    DROP COLUMN IF EXISTS masked_api_key,
+    DROP COLUMN IF EXISTS api_secret_hash,
+    DROP COLUMN IF EXISTS api_key_hash;
@@ -0,0 +1,20 @@
+-- Encrypted exchange binding credentials
+-- api_key and api_secret hold AES-256-GCM ciphertexts. Duplicate checks look credentials up by
+-- their keyed hashes and responses show the stored mask, so neither needs a decryption.
+-- Existing rows are encrypted in place by `migrate up` right after this migration; a row is
+-- encrypted once its hashes are set.
+
+ALTER TABLE exchange_bindings
+    ADD COLUMN IF NOT EXISTS api_key_hash VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS api_secret_hash VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS masked_api_key VARCHAR(20) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_exchange_bindings_api_key_hash
+    ON exchange_bindings(user_id, api_key_hash) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_exchange_bindings_api_secret_hash
+    ON exchange_bindings(user_id, api_secret_hash) WHERE deleted_at IS NULL;
+
+COMMENT ON COLUMN exchange_bindings.api_key IS 'Encrypted API key';
+COMMENT ON COLUMN exchange_bindings.api_secret IS 'Encrypted API secret';
+COMMENT ON COLUMN exchange_bindings.api_key_hash IS 'Keyed hash of the API key for duplicate checks';
+COMMENT ON COLUMN exchange_bindings.api_secret_hash IS 'Keyed hash of the API secret for duplicate checks';
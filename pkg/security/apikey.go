@@ -208,7 +208,31 @@ func (akm *APIKeyManager) MaskAPIKey(apiKey string) string {
 
 // HashAPIKey creates a secure hash of the API key for comparison
 func (akm *APIKeyManager) HashAPIKey(apiKey string) string {
-	hash := sha256.Sum256([]byte(apiKey + string(akm.signingKey)))
+	return hashWithKey(apiKey, akm.signingKey)
+}
+
+// CredentialHasher creates keyed hashes of credentials for lookups without decrypting them. It only
+// depends on the signing key, so the hashes stay stable when the master key is rotated.
+type CredentialHasher struct {
+	signingKey []byte
+}
+
+// NewCredentialHasher creates a new credential hasher. Its hashes match HashAPIKey of an API key
+// manager with the same signing key.
+func NewCredentialHasher(signingKey string) *CredentialHasher {
+	signingKeyHash := sha256.Sum256([]byte(signingKey))
+	return &CredentialHasher{
+		signingKey: signingKeyHash[:],
+	}
+}
+
+// Hash creates a secure hash of a credential
+func (ch *CredentialHasher) Hash(credential string) string {
+	return hashWithKey(credential, ch.signingKey)
+}
+
+func hashWithKey(value string, key []byte) string {
+	hash := sha256.Sum256([]byte(value + string(key)))
 	return hex.EncodeToString(hash[:])
 }
 
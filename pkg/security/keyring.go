@@ -0,0 +1,149 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnknownKeyID = errors.New("unknown key id")
+)
+
+// LegacyKeyID is the key id of ciphertexts written before keys were versioned. They carry no key id
+// and were encrypted with the first master key.
+const LegacyKeyID = 1
+
+// Keyring encrypts data with the active one of several versioned master keys. Every ciphertext is
+// prefixed with the id of its key ("v<id>:"), so the master key can be rotated while ciphertexts of
+// the retired keys still decrypt.
+type Keyring struct {
+	keys     map[int]*EncryptionManager
+	activeID int
+}
+
+// NewKeyring creates a keyring of the given master keys by key id. New data is encrypted with the
+// key of activeID.
+func NewKeyring(masterKeys map[int]string, activeID int) (*Keyring, error) {
+	if len(masterKeys) == 0 {
+		return nil, errors.New("at least one master key is required")
+	}
+
+	keys := make(map[int]*EncryptionManager, len(masterKeys))
+	for id, masterKey := range masterKeys {
+		if id <= 0 {
+			return nil, fmt.Errorf("invalid key id %d: key ids must be positive", id)
+		}
+		em, err := NewEncryptionManager(masterKey)
+		if err != nil {
+			return nil, fmt.Errorf("master key %d: %w", id, err)
+		}
+		keys[id] = em
+	}
+
+	if _, ok := keys[activeID]; !ok {
+		return nil, fmt.Errorf("%w: active key %d is not in the keyring", ErrUnknownKeyID, activeID)
+	}
+
+	return &Keyring{
+		keys:     keys,
+		activeID: activeID,
+	}, nil
+}
+
+// ActiveKeyID returns the id of the key new data is encrypted with
+func (k *Keyring) ActiveKeyID() int {
+	return k.activeID
+}
+
+// KeyIDs returns the ids of all keys in the keyring in ascending order
+func (k *Keyring) KeyIDs() []int {
+	ids := make([]int, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Encrypt encrypts plaintext with the active key and prefixes the ciphertext with its key id
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	ciphertext, err := k.keys[k.activeID].Encrypt(plaintext)
+	if err != nil {
+		return "", err
+	}
+
+	return CiphertextPrefix(k.activeID) + ciphertext, nil
+}
+
+// Decrypt decrypts a ciphertext with the key it was encrypted with
+func (k *Keyring) Decrypt(ciphertext string) (string, error) {
+	if ciphertext == "" {
+		return "", nil
+	}
+
+	id, data := splitKeyID(ciphertext)
+	em, ok := k.keys[id]
+	if !ok {
+		return "", fmt.Errorf("%w: %d", ErrUnknownKeyID, id)
+	}
+
+	return em.Decrypt(data)
+}
+
+// Reencrypt decrypts a ciphertext and encrypts it again with the active key. Ciphertexts of the
+// active key are returned unchanged.
+func (k *Keyring) Reencrypt(ciphertext string) (string, error) {
+	if ciphertext == "" || k.IsActive(ciphertext) {
+		return ciphertext, nil
+	}
+
+	plaintext, err := k.Decrypt(ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	return k.Encrypt(plaintext)
+}
+
+// IsActive returns true if the ciphertext was encrypted with the active key
+func (k *Keyring) IsActive(ciphertext string) bool {
+	return strings.HasPrefix(ciphertext, CiphertextPrefix(k.activeID))
+}
+
+// CiphertextPrefix returns the prefix of the ciphertexts encrypted with the given key id
+func CiphertextPrefix(keyID int) string {
+	return "v" + strconv.Itoa(keyID) + ":"
+}
+
+// HasKeyID returns true if the value is a ciphertext carrying a key id
+func HasKeyID(value string) bool {
+	id, data := splitKeyID(value)
+	return id != LegacyKeyID || data != value
+}
+
+// splitKeyID splits a ciphertext into its key id and the encrypted data. Ciphertexts without a key
+// id belong to the legacy key.
+func splitKeyID(ciphertext string) (int, string) {
+	if !strings.HasPrefix(ciphertext, "v") {
+		return LegacyKeyID, ciphertext
+	}
+
+	// Base64 never contains ':', so a "v<digits>:" prefix cannot be part of a legacy ciphertext
+	idPart, data, found := strings.Cut(ciphertext[1:], ":")
+	if !found {
+		return LegacyKeyID, ciphertext
+	}
+	id, err := strconv.Atoi(idPart)
+	if err != nil || id <= 0 || strconv.Itoa(id) != idPart {
+		return LegacyKeyID, ciphertext
+	}
+
+	return id, data
+}
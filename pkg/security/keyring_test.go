@@ -0,0 +1,175 @@
+package security
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const (
+	testKeyV1 = "test-master-key-version-1-32-chars-minimum"
+	testKeyV2 = "test-master-key-version-2-32-chars-minimum"
+)
+
+func TestNewKeyring(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     map[int]string
+		activeID int
+		wantErr  bool
+	}{
+		{
+			name:     "single key",
+			keys:     map[int]string{1: testKeyV1},
+			activeID: 1,
+			wantErr:  false,
+		},
+		{
+			name:     "rotated keys",
+			keys:     map[int]string{1: testKeyV1, 2: testKeyV2},
+			activeID: 2,
+			wantErr:  false,
+		},
+		{
+			name:     "no keys",
+			keys:     map[int]string{},
+			activeID: 1,
+			wantErr:  true,
+		},
+		{
+			name:     "unknown active key",
+			keys:     map[int]string{1: testKeyV1},
+			activeID: 2,
+			wantErr:  true,
+		},
+		{
+			name:     "short key",
+			keys:     map[int]string{1: "short"},
+			activeID: 1,
+			wantErr:  true,
+		},
+		{
+			name:     "invalid key id",
+			keys:     map[int]string{0: testKeyV1},
+			activeID: 0,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyring(tt.keys, tt.activeID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewKeyring() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	oldKeyring, err := NewKeyring(map[int]string{1: testKeyV1}, 1)
+	if err != nil {
+		t.Fatalf("Failed to create keyring: %v", err)
+	}
+	newKeyring, err := NewKeyring(map[int]string{1: testKeyV1, 2: testKeyV2}, 2)
+	if err != nil {
+		t.Fatalf("Failed to create keyring: %v", err)
+	}
+
+	oldCiphertext, err := oldKeyring.Encrypt("api-secret")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if !strings.HasPrefix(oldCiphertext, "v1:") {
+		t.Errorf("Encrypt() = %q, want key id prefix v1:", oldCiphertext)
+	}
+
+	// Old versions still decrypt after the rotation
+	decrypted, err := newKeyring.Decrypt(oldCiphertext)
+	if err != nil || decrypted != "api-secret" {
+		t.Errorf("Decrypt() = %q, %v, want api-secret", decrypted, err)
+	}
+	if newKeyring.IsActive(oldCiphertext) {
+		t.Errorf("IsActive() = true for a ciphertext of a retired key")
+	}
+
+	// New writes use the active key
+	rotated, err := newKeyring.Reencrypt(oldCiphertext)
+	if err != nil {
+		t.Fatalf("Reencrypt() error = %v", err)
+	}
+	if !strings.HasPrefix(rotated, "v2:") || !newKeyring.IsActive(rotated) {
+		t.Errorf("Reencrypt() = %q, want key id prefix v2:", rotated)
+	}
+	unchanged, err := newKeyring.Reencrypt(rotated)
+	if err != nil || unchanged != rotated {
+		t.Errorf("Reencrypt() of an active ciphertext = %q, %v, want it unchanged", unchanged, err)
+	}
+
+	// The old keyring does not know the new key
+	if _, err := oldKeyring.Decrypt(rotated); !errors.Is(err, ErrUnknownKeyID) {
+		t.Errorf("Decrypt() error = %v, want ErrUnknownKeyID", err)
+	}
+}
+
+func TestKeyring_DecryptLegacyCiphertext(t *testing.T) {
+	em, err := NewEncryptionManager(testKeyV1)
+	if err != nil {
+		t.Fatalf("Failed to create encryption manager: %v", err)
+	}
+	legacy, err := em.Encrypt("api-key")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	keyring, err := NewKeyring(map[int]string{1: testKeyV1, 2: testKeyV2}, 2)
+	if err != nil {
+		t.Fatalf("Failed to create keyring: %v", err)
+	}
+
+	if HasKeyID(legacy) {
+		t.Errorf("HasKeyID() = true for a legacy ciphertext")
+	}
+	decrypted, err := keyring.Decrypt(legacy)
+	if err != nil || decrypted != "api-key" {
+		t.Errorf("Decrypt() = %q, %v, want api-key", decrypted, err)
+	}
+}
+
+func TestHasKeyID(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"v1:c2VjcmV0", true},
+		{"v12:c2VjcmV0", true},
+		{"c2VjcmV0", false},
+		{"v01:c2VjcmV0", false},
+		{"vx:c2VjcmV0", false},
+		{"ya29.a0AfH6SMBx", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := HasKeyID(tt.value); got != tt.want {
+				t.Errorf("HasKeyID(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCredentialHasher(t *testing.T) {
+	apiKeyManager, err := NewAPIKeyManager(testKeyV1, "test-signing-key-32-chars-minimum")
+	if err != nil {
+		t.Fatalf("Failed to create API key manager: %v", err)
+	}
+	hasher := NewCredentialHasher("test-signing-key-32-chars-minimum")
+
+	if got, want := hasher.Hash("api-key"), apiKeyManager.HashAPIKey("api-key"); got != want {
+		t.Errorf("Hash() = %q, want %q", got, want)
+	}
+	if hasher.Hash("api-key") == NewCredentialHasher("other-signing-key-32-chars-minimum").Hash("api-key") {
+		t.Errorf("Hash() does not depend on the signing key")
+	}
+}
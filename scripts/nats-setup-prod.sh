@@ -161,7 +161,7 @@ create_production_consumer() {
 log "📈 Setting up Trading Events stream..."
 create_production_stream \
     "TRADING_EVENTS" \
-    "trading.orders.*,trading.balance.*,trading.signals,trading.errors" \
+    "trading.orders.*,trading.balance.*,trading.prices.*,trading.signals,trading.errors" \
     "limits" \
     "72h" \
     "file" \
@@ -235,6 +235,15 @@ create_production_consumer \
     "5" \
     "60s"
 
+# Price processing consumers - virtual exchange price feeds
+create_production_consumer \
+    "TRADING_EVENTS" \
+    "price-processor" \
+    "trading.prices.*" \
+    "price-processors" \
+    "3" \
+    "30s"
+
 # Error processing consumers - immediate processing
 create_production_consumer \
     "TRADING_EVENTS" \
@@ -85,7 +85,7 @@ create_consumer_if_not_exists() {
 echo "Setting up Trading Events stream..."
 create_stream_if_not_exists \
     "TRADING_EVENTS" \
-    "trading.orders.*,trading.balance.*,trading.signals,trading.errors" \
+    "trading.orders.*,trading.balance.*,trading.prices.*,trading.signals,trading.errors" \
     "interest" \
     "24h" \
     "file"
@@ -115,6 +115,14 @@ create_consumer_if_not_exists \
     "trading.balance.*" \
     "balance-processors"
 
+# Create consumers for price events
+echo "Setting up Price Event consumers..."
+create_consumer_if_not_exists \
+    "TRADING_EVENTS" \
+    "price-processor" \
+    "trading.prices.*" \
+    "price-processors"
+
 # Create consumers for error events
 echo "Setting up Error Event consumers..."
 create_consumer_if_not_exists \
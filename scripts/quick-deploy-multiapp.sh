@@ -129,6 +129,8 @@ setup_environment() {
     DB_PASSWORD=$(openssl rand -base64 16 | tr -d '/+=' | cut -c1-12)
     JWT_SECRET=$(openssl rand -base64 32 | tr -d '/+=' | cut -c1-32)
     REFRESH_SECRET=$(openssl rand -base64 32 | tr -d '/+=' | cut -c1-32)
+    MASTER_KEY=$(openssl rand -base64 48 | tr -d '/+=' | cut -c1-40)
+    SIGNING_KEY=$(openssl rand -base64 48 | tr -d '/+=' | cut -c1-40)
     
     # Create environment file
     cp .env.simple.template .env.simple
@@ -137,6 +139,8 @@ setup_environment() {
     sed -i.bak "s|change_me_in_production|$DB_PASSWORD|g" .env.simple
     sed -i.bak "s|change_me_very_strong_jwt_secret_32_chars_minimum|$JWT_SECRET|g" .env.simple
     sed -i.bak "s|change_me_very_strong_refresh_secret_32_chars_minimum|$REFRESH_SECRET|g" .env.simple
+    sed -i.bak "s|change_me_very_strong_master_key_32_chars_minimum|$MASTER_KEY|g" .env.simple
+    sed -i.bak "s|change_me_very_strong_signing_key_32_chars_minimum|$SIGNING_KEY|g" .env.simple
     
     # Clean up backup file
     rm -f .env.simple.bak
@@ -169,6 +169,7 @@ setup_test_env() {
     export REFRESH_SECRET="integration-test-refresh-secret-32-chars"
     export MASTER_KEY="integration-test-master-key-32-chars-minimum"
     export SIGNING_KEY="integration-test-signing-key-32-chars-minimum"
+    export EXCHANGE_CONNECTORS=fake  # Verify exchange bindings offline
     export LOG_LEVEL=error  # Reduce log noise during tests
     
     print_success "Test environment variables set"
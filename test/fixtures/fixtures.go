@@ -6,6 +6,7 @@ import (
 	"tiris-backend/internal/models"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // FixedTime is a consistent time for tests
@@ -155,7 +156,7 @@ var SubAccountFixtures = struct {
 		TradingID: TradingFixtures.BinanceTrading.ID,
 		Name:       "spot",
 		Symbol:     "USDT",
-		Balance:    1000.0,
+		Balance:    decimal.NewFromInt(1000),
 		Info:       models.JSON{"type": "spot"},
 		CreatedAt:  time.Now().Add(-6 * time.Hour),
 		UpdatedAt:  time.Now().Add(-30 * time.Minute),
@@ -166,7 +167,7 @@ var SubAccountFixtures = struct {
 		TradingID: TradingFixtures.BinanceTrading.ID,
 		Name:       "futures",
 		Symbol:     "USDT",
-		Balance:    5000.0,
+		Balance:    decimal.NewFromInt(5000),
 		Info:       models.JSON{"type": "futures"},
 		CreatedAt:  time.Now().Add(-4 * time.Hour),
 		UpdatedAt:  time.Now().Add(-15 * time.Minute),
@@ -177,7 +178,7 @@ var SubAccountFixtures = struct {
 		TradingID: TradingFixtures.OKXTrading.ID,
 		Name:       "margin",
 		Symbol:     "USDT",
-		Balance:    2000.0,
+		Balance:    decimal.NewFromInt(2000),
 		Info:       models.JSON{"type": "margin"},
 		CreatedAt:  time.Now().Add(-2 * time.Hour),
 		UpdatedAt:  time.Now().Add(-5 * time.Minute),
@@ -198,8 +199,8 @@ var TransactionFixtures = struct {
 		Timestamp:      time.Now().Add(-2 * time.Hour),
 		Direction:      "credit",
 		Reason:         "deposit",
-		Amount:         1000.0,
-		ClosingBalance: 1000.0,
+		Amount:         decimal.NewFromInt(1000),
+		ClosingBalance: decimal.NewFromInt(1000),
 		Info:           models.JSON{"txid": "0x123456789abcdef"},
 	},
 	WithdrawTransaction: &models.Transaction{
@@ -210,8 +211,8 @@ var TransactionFixtures = struct {
 		Timestamp:      time.Now().Add(-1 * time.Hour),
 		Direction:      "debit",
 		Reason:         "withdrawal",
-		Amount:         100.0,
-		ClosingBalance: 900.0,
+		Amount:         decimal.NewFromInt(100),
+		ClosingBalance: decimal.NewFromInt(900),
 		Info:           models.JSON{"address": "0xabcdef123456789"},
 	},
 	TradeTransaction: &models.Transaction{
@@ -222,8 +223,8 @@ var TransactionFixtures = struct {
 		Timestamp:      time.Now().Add(-30 * time.Minute),
 		Direction:      "credit",
 		Reason:         "trade_profit",
-		Amount:         50.0,
-		ClosingBalance: 5050.0,
+		Amount:         decimal.NewFromInt(50),
+		ClosingBalance: decimal.NewFromInt(5050),
 		Info:           models.JSON{"trade_id": "12345", "symbol": "BTCUSDT"},
 	},
 }
@@ -322,7 +323,7 @@ func CreateTrading(userID uuid.UUID) *models.Trading {
 		UserID:            userID,
 		ExchangeBindingID: ExchangeBindingFixtures.PublicBinding.ID,
 		Name:              "test_trading_" + uuid.New().String()[:8],
-		Type:              "virtual",
+		Type:              "simulation",
 		Status:            "active",
 		Info:              models.JSON{},
 		CreatedAt:         time.Now(),
@@ -338,7 +339,7 @@ func CreateSubAccount(userID, tradingID uuid.UUID) *models.SubAccount {
 		TradingID: tradingID,
 		Name:       "test_subaccount_" + uuid.New().String()[:8],
 		Symbol:     "USDT",
-		Balance:    1000.0,
+		Balance:    decimal.NewFromInt(1000),
 		Info:       models.JSON{},
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
@@ -8,6 +8,7 @@ import (
 	"tiris-backend/internal/models"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // TestDataFactory provides methods to create test data dynamically
@@ -289,7 +290,7 @@ func (f *SubAccountFactory) Build() *models.SubAccount {
 		TradingID: uuid.New(), // Will be overridden
 		Name:       fmt.Sprintf("account_%d", id),
 		Symbol:     "USDT",
-		Balance:    1000.0 + float64(rand.Intn(9000)), // Random balance between 1000-10000
+		Balance:    decimal.NewFromInt(1000 + int64(rand.Intn(9000))), // Random balance between 1000-10000
 		Info:       models.JSON(map[string]interface{}{"type": "spot"}),
 		CreatedAt:  time.Now().Add(-time.Duration(rand.Intn(24)) * time.Hour),
 		UpdatedAt:  time.Now().Add(-time.Duration(rand.Intn(6)) * time.Hour),
@@ -321,7 +322,7 @@ func (f *SubAccountFactory) WithUserAndTrading(userID, tradingID uuid.UUID) *mod
 // WithBalance sets the balance
 func (f *SubAccountFactory) WithBalance(balance float64) *models.SubAccount {
 	account := f.Build()
-	account.Balance = balance
+	account.Balance = decimal.NewFromFloat(balance)
 	return account
 }
 
@@ -357,7 +358,7 @@ func (f *SubAccountFactory) FuturesAccount() *models.SubAccount {
 	account := f.Build()
 	account.Name = "futures"
 	account.Symbol = "USDT"
-	account.Balance = 5000.0 + float64(rand.Intn(15000)) // Higher balance for futures
+	account.Balance = decimal.NewFromInt(5000 + int64(rand.Intn(15000))) // Higher balance for futures
 	futuresInfo := map[string]interface{}{
 		"type":     "futures",
 		"leverage": 10,
@@ -381,7 +382,7 @@ func NewTransactionFactory() *TransactionFactory {
 
 // Build creates a basic transaction with default values
 func (f *TransactionFactory) Build() *models.Transaction {
-	amount := 100.0 + float64(rand.Intn(900)) // Random amount between 100-1000
+	amount := decimal.NewFromInt(100 + int64(rand.Intn(900))) // Random amount between 100-1000
 	return &models.Transaction{
 		ID:             uuid.New(),
 		UserID:         uuid.New(), // Will be overridden
@@ -420,7 +421,7 @@ func (f *TransactionFactory) WithSubAccountID(subAccountID uuid.UUID) *models.Tr
 // WithAmount sets the transaction amount
 func (f *TransactionFactory) WithAmount(amount float64) *models.Transaction {
 	tx := f.Build()
-	tx.Amount = amount
+	tx.Amount = decimal.NewFromFloat(amount)
 	return tx
 }
 
@@ -443,8 +444,8 @@ func (f *TransactionFactory) DepositTransaction(amount float64, closingBalance f
 	tx := f.Build()
 	tx.Direction = "credit"
 	tx.Reason = "deposit"
-	tx.Amount = amount
-	tx.ClosingBalance = closingBalance
+	tx.Amount = decimal.NewFromFloat(amount)
+	tx.ClosingBalance = decimal.NewFromFloat(closingBalance)
 	depositInfo := map[string]interface{}{
 		"txid":   fmt.Sprintf("0x%x", rand.Int63()),
 		"method": "bank_transfer",
@@ -458,8 +459,8 @@ func (f *TransactionFactory) WithdrawTransaction(amount float64, closingBalance
 	tx := f.Build()
 	tx.Direction = "debit"
 	tx.Reason = "withdrawal"
-	tx.Amount = amount
-	tx.ClosingBalance = closingBalance
+	tx.Amount = decimal.NewFromFloat(amount)
+	tx.ClosingBalance = decimal.NewFromFloat(closingBalance)
 	withdrawInfo := map[string]interface{}{
 		"address": fmt.Sprintf("0x%x", rand.Int63()),
 		"fee":     amount * 0.001, // 0.1% fee
@@ -473,8 +474,8 @@ func (f *TransactionFactory) TradeTransaction(amount float64, closingBalance flo
 	tx := f.Build()
 	tx.Direction = "credit" // Could be debit for losses
 	tx.Reason = "trade_profit"
-	tx.Amount = amount
-	tx.ClosingBalance = closingBalance
+	tx.Amount = decimal.NewFromFloat(amount)
+	tx.ClosingBalance = decimal.NewFromFloat(closingBalance)
 	tradeInfo := map[string]interface{}{
 		"trade_id": fmt.Sprintf("T%d", rand.Int63()),
 		"symbol":   "BTCUSDT",
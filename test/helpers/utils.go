@@ -16,7 +16,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -157,6 +159,25 @@ func RandomFloat(min, max float64) float64 {
 	return min + rand.Float64()*(max-min)
 }
 
+// Dec parses a decimal literal, panicking on invalid input
+func Dec(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+// DecimalArg returns a mock argument matcher that compares decimals by value
+// rather than by internal representation
+func DecimalArg(expected decimal.Decimal) interface{} {
+	return mock.MatchedBy(func(actual decimal.Decimal) bool {
+		return actual.Equal(expected)
+	})
+}
+
+// AssertDecimalEqual asserts that two decimals are numerically equal
+func AssertDecimalEqual(t *testing.T, expected, actual decimal.Decimal) {
+	t.Helper()
+	assert.Truef(t, expected.Equal(actual), "expected %s, got %s", expected.String(), actual.String())
+}
+
 // RandomBool generates a random boolean
 func RandomBool() bool {
 	return rand.Intn(2) == 1
@@ -10,6 +10,7 @@ import (
 	"tiris-backend/pkg/auth"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/mock"
 	"golang.org/x/oauth2"
 )
@@ -164,16 +165,16 @@ func (m *MockExchangeBindingRepository) GetByNameAndUser(ctx context.Context, na
 	return args.Get(0).(*models.ExchangeBinding), args.Error(1)
 }
 
-func (m *MockExchangeBindingRepository) GetByAPIKey(ctx context.Context, apiKey string, userID *uuid.UUID) (*models.ExchangeBinding, error) {
-	args := m.Called(ctx, apiKey, userID)
+func (m *MockExchangeBindingRepository) GetByAPIKey(ctx context.Context, apiKeyHash string, userID *uuid.UUID) (*models.ExchangeBinding, error) {
+	args := m.Called(ctx, apiKeyHash, userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.ExchangeBinding), args.Error(1)
 }
 
-func (m *MockExchangeBindingRepository) GetByAPISecret(ctx context.Context, apiSecret string, userID *uuid.UUID) (*models.ExchangeBinding, error) {
-	args := m.Called(ctx, apiSecret, userID)
+func (m *MockExchangeBindingRepository) GetByAPISecret(ctx context.Context, apiSecretHash string, userID *uuid.UUID) (*models.ExchangeBinding, error) {
+	args := m.Called(ctx, apiSecretHash, userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -208,12 +209,12 @@ func (m *MockSubAccountRepository) GetByTradingID(ctx context.Context, tradingID
 	return args.Get(0).([]*models.SubAccount), args.Error(1)
 }
 
-func (m *MockSubAccountRepository) Update(ctx context.Context, subAccount *models.SubAccount) error {
-	args := m.Called(ctx, subAccount)
+func (m *MockSubAccountRepository) Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error {
+	args := m.Called(ctx, id, updates)
 	return args.Error(0)
 }
 
-func (m *MockSubAccountRepository) UpdateBalance(ctx context.Context, subAccountID uuid.UUID, newBalance float64, amount float64, direction, reason string, info interface{}) (*uuid.UUID, error) {
+func (m *MockSubAccountRepository) UpdateBalance(ctx context.Context, subAccountID uuid.UUID, newBalance, amount decimal.Decimal, direction, reason string, info interface{}) (*uuid.UUID, error) {
 	args := m.Called(ctx, subAccountID, newBalance, amount, direction, reason, info)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -221,6 +222,22 @@ func (m *MockSubAccountRepository) UpdateBalance(ctx context.Context, subAccount
 	return args.Get(0).(*uuid.UUID), args.Error(1)
 }
 
+func (m *MockSubAccountRepository) LockBalance(ctx context.Context, subAccountID uuid.UUID, amount decimal.Decimal) (*models.SubAccount, error) {
+	args := m.Called(ctx, subAccountID, amount)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SubAccount), args.Error(1)
+}
+
+func (m *MockSubAccountRepository) UnlockBalance(ctx context.Context, subAccountID uuid.UUID, amount decimal.Decimal) (*models.SubAccount, error) {
+	args := m.Called(ctx, subAccountID, amount)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SubAccount), args.Error(1)
+}
+
 func (m *MockSubAccountRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -269,6 +286,45 @@ func (m *MockTransactionRepository) GetByTimeRange(ctx context.Context, startTim
 	return args.Get(0).([]*models.Transaction), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockTransactionRepository) GetLatestBySubAccountIDAt(ctx context.Context, subAccountID uuid.UUID, at time.Time) ([]*models.Transaction, error) {
+	args := m.Called(ctx, subAccountID, at)
+	return args.Get(0).([]*models.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) GetLatestByTradingIDAt(ctx context.Context, tradingID uuid.UUID, at time.Time) ([]*models.Transaction, error) {
+	args := m.Called(ctx, tradingID, at)
+	return args.Get(0).([]*models.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) SetPrice(ctx context.Context, id uuid.UUID, price decimal.Decimal, quoteSymbol string) error {
+	args := m.Called(ctx, id, price, quoteSymbol)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) GetLatestPricesByTradingID(ctx context.Context, tradingID uuid.UUID, quoteSymbol string) ([]*repositories.SymbolPrice, error) {
+	args := m.Called(ctx, tradingID, quoteSymbol)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repositories.SymbolPrice), args.Error(1)
+}
+
+func (m *MockTransactionRepository) GetBalanceChangesByTradingID(ctx context.Context, tradingID uuid.UUID, width time.Duration, from, to time.Time) ([]*repositories.BalanceChange, error) {
+	args := m.Called(ctx, tradingID, width, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repositories.BalanceChange), args.Error(1)
+}
+
+func (m *MockTransactionRepository) GetBucketPricesByTradingID(ctx context.Context, tradingID uuid.UUID, quoteSymbol string, width time.Duration, from, to time.Time) ([]*repositories.BucketPrice, error) {
+	args := m.Called(ctx, tradingID, quoteSymbol, width, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repositories.BucketPrice), args.Error(1)
+}
+
 // MockTradingLogRepository is a mock implementation of TradingLogRepository
 type MockTradingLogRepository struct {
 	mock.Mock
@@ -401,6 +457,70 @@ func (m *MockEventProcessingRepository) DeleteOldEvents(ctx context.Context, old
 	return args.Error(0)
 }
 
+// MockIdempotencyKeyRepository is a mock implementation of IdempotencyKeyRepository
+type MockIdempotencyKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyKeyRepository) Create(ctx context.Context, key *models.IdempotencyKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyKeyRepository) GetByUserIDAndKey(ctx context.Context, userID uuid.UUID, key string) (*models.IdempotencyKey, error) {
+	args := m.Called(ctx, userID, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.IdempotencyKey), args.Error(1)
+}
+
+// MockOrderRepository is a mock implementation of OrderRepository
+type MockOrderRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrderRepository) Create(ctx context.Context, order *models.Order) error {
+	args := m.Called(ctx, order)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) GetByTradingIDAndOrderID(ctx context.Context, tradingID uuid.UUID, orderID string) (*models.Order, error) {
+	args := m.Called(ctx, tradingID, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) Update(ctx context.Context, order *models.Order) error {
+	args := m.Called(ctx, order)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) Cancel(ctx context.Context, id uuid.UUID) (*models.Order, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetByUserID(ctx context.Context, userID uuid.UUID, filters repositories.OrderFilters) ([]*models.Order, int64, error) {
+	args := m.Called(ctx, userID, filters)
+	return args.Get(0).([]*models.Order), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockOrderRepository) GetByTradingID(ctx context.Context, tradingID uuid.UUID, filters repositories.OrderFilters) ([]*models.Order, int64, error) {
+	args := m.Called(ctx, tradingID, filters)
+	return args.Get(0).([]*models.Order), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockOrderRepository) GetOpenForUpdate(ctx context.Context, tradingID uuid.UUID, symbol string) ([]*models.Order, error) {
+	args := m.Called(ctx, tradingID, symbol)
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
 // MockJWTManager is a mock implementation of JWTManagerInterface
 type MockJWTManager struct {
 	mock.Mock